@@ -15,18 +15,35 @@ import (
 	utilflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/logs"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/abortrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/auditforwarder"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/auditpolicy"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/capabilities"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certregenerationcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/completion"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/configfile"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/diagnose"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/fastinstaller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/insecurereadyz"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
 	operatorcmd "github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/prune"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/recoveryapiserver"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/regeneratecertificates"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/render"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/resourcegraph"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/status"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitforcanary"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitformaintenancewindow"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfornodegates"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitforpreflight"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitforresume"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitwhileexcluded"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
 	"github.com/openshift/library-go/pkg/operator/staticpod/certsyncpod"
-	"github.com/openshift/library-go/pkg/operator/staticpod/installerpod"
-	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
 	"github.com/openshift/library-go/pkg/operator/staticpod/startupmonitor"
 
 	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
@@ -66,20 +83,39 @@ func NewOperatorCommand(ctx context.Context) *cobra.Command {
 
 	cmd.AddCommand(operatorcmd.NewOperator())
 	cmd.AddCommand(render.NewRenderCommand())
-	cmd.AddCommand(installerpod.NewInstaller())
-	cmd.AddCommand(prune.NewPrune())
+	cmd.AddCommand(completion.MarkPathFlags(configfile.WithConfigFile(installer.NewInstaller())))
+	cmd.AddCommand(completion.MarkPathFlags(configfile.WithConfigFile(fastinstaller.NewFastInstaller())))
+	cmd.AddCommand(completion.MarkPathFlags(configfile.WithConfigFile(prune.NewPrune())))
 	cmd.AddCommand(resourcegraph.NewResourceChainCommand())
 	cmd.AddCommand(certsyncpod.NewCertSyncControllerCommand(operator.CertConfigMaps, operator.CertSecrets))
 	cmd.AddCommand(certregenerationcontroller.NewCertRegenerationControllerCommand(ctx))
 	cmd.AddCommand(insecurereadyz.NewInsecureReadyzCommand())
+	cmd.AddCommand(auditforwarder.NewAuditForwarderCommand())
 	cmd.AddCommand(checkendpoints.NewCheckEndpointsCommand())
-	cmd.AddCommand(startupmonitor.NewCommand(startupmonitorreadiness.New(), func(config *rest.Config) (operatorclientv1.KubeAPIServerInterface, error) {
+	cmd.AddCommand(waitforcanary.NewWaitForCanaryCommand())
+	cmd.AddCommand(waitforresume.NewWaitForResumeCommand())
+	cmd.AddCommand(waitfornodegates.NewWaitForNodeGatesCommand())
+	cmd.AddCommand(waitformaintenancewindow.NewWaitForMaintenanceWindowCommand())
+	cmd.AddCommand(waitforpreflight.NewWaitForPreflightCommand())
+	cmd.AddCommand(waitwhileexcluded.NewWaitWhileExcludedCommand())
+	cmd.AddCommand(abortrollout.NewAbortRolloutCommand())
+	cmd.AddCommand(auditpolicy.NewAuditPolicyCommand())
+	cmd.AddCommand(status.NewStatusCommand())
+	cmd.AddCommand(diagnose.NewDiagnoseCommand())
+	cmd.AddCommand(regeneratecertificates.NewRegenerateCertificatesCommand())
+	cmd.AddCommand(recoveryapiserver.NewRecoveryApiserverCommand())
+	readinessChecker := startupmonitorreadiness.New()
+	startupMonitorCmd := startupmonitor.NewCommand(readinessChecker, func(config *rest.Config) (operatorclientv1.KubeAPIServerInterface, error) {
 		client, err := operatorclientv1.NewForConfig(config)
 		if err != nil {
 			return nil, err
 		}
 		return client.KubeAPIServers(), nil
-	}))
+	})
+	readinessChecker.AddFlags(startupMonitorCmd.Flags())
+	cmd.AddCommand(startupMonitorCmd)
+	cmd.AddCommand(capabilities.NewCapabilitiesCommand())
+	cmd.AddCommand(completion.NewCompletionCommand())
 
 	return cmd
 }
@@ -0,0 +1,312 @@
+package abortrollout
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutpause"
+)
+
+// abortOpts holds how to reach the cluster and how long to wait for running installers.
+type abortOpts struct {
+	kubeconfig string
+	timeout    time.Duration
+	interval   time.Duration
+
+	out io.Writer
+}
+
+// NewAbortRolloutCommand creates the abort-rollout command. It pauses the rollout in the operator config, cancels
+// the installer pods that did not start to install yet, waits for the running installers to finish and reports the
+// revision of every node. Installers that already write to a node are never interrupted, so no node is left with a
+// half installed revision. The rollout continues once rollout.paused is removed from the operator config.
+func NewAbortRolloutCommand() *cobra.Command {
+	opts := abortOpts{
+		timeout:  10 * time.Minute,
+		interval: 5 * time.Second,
+	}
+	cmd := &cobra.Command{
+		Use:   "abort-rollout",
+		Short: "Pause the rollout of new revisions and cancel the pending installer pods",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *abortOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster, defaults to the in-cluster config")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "How long to wait for the pause to be observed and for running installers to finish")
+	fs.DurationVar(&o.interval, "interval", o.interval, "How often the installer pods are checked")
+}
+
+// Validate verifies the inputs.
+func (o *abortOpts) Validate() error {
+	if o.timeout <= 0 || o.interval <= 0 {
+		return fmt.Errorf("--timeout and --interval must be positive")
+	}
+	return nil
+}
+
+// Run aborts the rollout and prints the revision of every node, also when the running installers did not finish in
+// time.
+func (o *abortOpts) Run(ctx context.Context) error {
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	operatorClient, err := operatorclientv1.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := o.pause(ctx, kubeClient); err != nil {
+		return err
+	}
+
+	var pods []corev1.Pod
+	waitErr := wait.PollImmediate(o.interval, o.timeout, func() (bool, error) {
+		podList, err := kubeClient.CoreV1().Pods(operatorclient.TargetNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=installer"})
+		if err != nil {
+			klog.Warningf("Failed to list the installer pods: %v", err)
+			return false, nil
+		}
+		pods = podList.Items
+		installing := false
+		for _, pod := range pods {
+			switch installerState(&pod) {
+			case stateInstalling:
+				installing = true
+			case statePending:
+				// library-go recreates the installer pod, the new one waits for the rollout to be resumed
+				klog.Infof("Cancelling installer pod %s on node %s", pod.Name, pod.Spec.NodeName)
+				if err := kubeClient.CoreV1().Pods(operatorclient.TargetNamespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+					klog.Warningf("Failed to delete installer pod %s: %v", pod.Name, err)
+				}
+				installing = true
+			}
+		}
+		return !installing, nil
+	})
+
+	kubeAPIServer, err := operatorClient.KubeAPIServers().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if err := report(o.out, &kubeAPIServer.Status.StaticPodOperatorStatus, pods); err != nil {
+		return err
+	}
+	if waitErr != nil {
+		return fmt.Errorf("installers still running after %v: %v", o.timeout, waitErr)
+	}
+	return nil
+}
+
+// pause sets rollout.paused in the operator config and waits until the rollout pause controller published it, from
+// then on new installer pods wait for the rollout to be resumed.
+func (o *abortOpts) pause(ctx context.Context, kubeClient kubernetes.Interface) error {
+	configMaps := kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := configMaps.Get(ctx, operatorconfig.ConfigMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			data, _, err := pauseConfig("")
+			if err != nil {
+				return err
+			}
+			_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: operatorconfig.ConfigMapName},
+				Data:       map[string]string{operatorconfig.ConfigKey: data},
+			}, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		data, changed, err := pauseConfig(configMap.Data[operatorconfig.ConfigKey])
+		if err != nil || !changed {
+			return err
+		}
+		configMap = configMap.DeepCopy()
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[operatorconfig.ConfigKey] = data
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to pause the rollout in %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, operatorconfig.ConfigMapName, err)
+	}
+
+	klog.Infof("Waiting for the operator to pause the rollout")
+	return wait.PollImmediate(o.interval, o.timeout, func() (bool, error) {
+		configMap, err := kubeClient.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(ctx, rolloutpause.ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				klog.Warningf("Failed to get configmap %s/%s: %v", operatorclient.TargetNamespace, rolloutpause.ConfigMapName, err)
+			}
+			return false, nil
+		}
+		return configMap.Data[rolloutpause.PausedKey] == "true", nil
+	})
+}
+
+var (
+	rolloutKey = regexp.MustCompile(`^rollout:\s*(#.*)?$`)
+	pausedKey  = regexp.MustCompile(`^(\s+paused:\s*)([^\s#]+)(.*)$`)
+)
+
+// pauseConfig returns the operator config with rollout.paused set and whether it changed. Only the paused line is
+// written, comments and the order of the keys are kept. Invalid configs are rejected rather than overwritten, as are
+// configs whose rollout cannot be edited line by line, e.g. in flow style; their paused has to be set by hand.
+func pauseConfig(data string) (string, bool, error) {
+	config, err := operatorconfig.Decode([]byte(data))
+	if err != nil {
+		return "", false, err
+	}
+	if config.Rollout.Paused {
+		return data, false, nil
+	}
+
+	// nothing but paused may change
+	paused := setPaused(data)
+	config.Rollout.Paused = true
+	if pausedConfig, err := operatorconfig.Decode([]byte(paused)); err != nil || !equality.Semantic.DeepEqual(pausedConfig, config) {
+		return "", false, fmt.Errorf("unable to set rollout.paused in %s without rewriting it, add \"paused: true\" to its rollout and run abort-rollout again", operatorconfig.ConfigKey)
+	}
+	return paused, true, nil
+}
+
+// setPaused sets the paused line in the top-level rollout block of data, adds it to the block or adds the block.
+func setPaused(data string) string {
+	lines := strings.Split(data, "\n")
+	for i, line := range lines {
+		if !rolloutKey.MatchString(line) {
+			continue
+		}
+		// the keys of the block are the lines indented like its first one, deeper lines belong to nested values
+		indent := ""
+		for j := i + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			lineIndent := lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " "))]
+			if len(lineIndent) == 0 {
+				break
+			}
+			if len(indent) == 0 {
+				indent = lineIndent
+			}
+			if lineIndent != indent {
+				continue
+			}
+			if match := pausedKey.FindStringSubmatch(lines[j]); match != nil {
+				lines[j] = match[1] + "true" + match[3]
+				return strings.Join(lines, "\n")
+			}
+		}
+		if len(indent) == 0 {
+			indent = "  "
+		}
+		lines = append(lines[:i+1], append([]string{indent + "paused: true"}, lines[i+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+	if len(data) > 0 && !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	return data + "rollout:\n  paused: true\n"
+}
+
+type podState int
+
+const (
+	stateDone podState = iota
+	// statePending installer pods did not start to install, they are cancelled
+	statePending
+	// stateHeld installer pods wait for the rollout to be resumed
+	stateHeld
+	// stateInstalling installer pods write the revision to the node and must not be interrupted
+	stateInstalling
+)
+
+func installerState(pod *corev1.Pod) podState {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || pod.DeletionTimestamp != nil {
+		return stateDone
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == "installer" && (status.State.Running != nil || status.State.Terminated != nil) {
+			return stateInstalling
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == "wait-for-resume" {
+			return stateHeld
+		}
+	}
+	return statePending
+}
+
+// report prints the current and target revision of every node and whether its installer is held.
+func report(out io.Writer, status *operatorv1.StaticPodOperatorStatus, pods []corev1.Pod) error {
+	podStates := map[string]podState{}
+	for i := range pods {
+		if state := installerState(&pods[i]); state > podStates[pods[i].Spec.NodeName] {
+			podStates[pods[i].Spec.NodeName] = state
+		}
+	}
+	nodeStatuses := append([]operatorv1.NodeStatus{}, status.NodeStatuses...)
+	sort.Slice(nodeStatuses, func(i, j int) bool { return nodeStatuses[i].NodeName < nodeStatuses[j].NodeName })
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "NODE\tCURRENT\tTARGET\tSTATE\n")
+	for _, nodeStatus := range nodeStatuses {
+		state := fmt.Sprintf("waiting for revision %d", status.LatestAvailableRevision)
+		switch {
+		case podStates[nodeStatus.NodeName] == stateInstalling:
+			state = fmt.Sprintf("installing revision %d", nodeStatus.TargetRevision)
+		case podStates[nodeStatus.NodeName] == stateHeld:
+			state = fmt.Sprintf("held, revision %d not installed", nodeStatus.TargetRevision)
+		case nodeStatus.CurrentRevision >= status.LatestAvailableRevision:
+			state = "up to date"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", nodeStatus.NodeName, nodeStatus.CurrentRevision, nodeStatus.TargetRevision, state)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,158 @@
+package abortrollout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestPauseConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectChanged bool
+		expectedData  string
+		expectedError string
+	}{
+		{name: "empty config", expectChanged: true},
+		{name: "other settings", data: "profiling: Disabled\nrollout:\n  strategy: Canary\n", expectChanged: true},
+		{name: "resumed", data: "rollout:\n  paused: false\n", expectChanged: true},
+		{name: "already paused", data: "rollout:\n  paused: true\n"},
+		{name: "invalid config", data: "rollout:\n  pasued: true\n", expectedError: "unknown field"},
+		{
+			name:          "comments kept",
+			data:          "# owned by the platform team\nrollout:\n    # resumed after the incident\n    paused: false # see INC-42\n    strategy: Canary\nprofiling: Disabled\n",
+			expectChanged: true,
+			expectedData:  "# owned by the platform team\nrollout:\n    # resumed after the incident\n    paused: true # see INC-42\n    strategy: Canary\nprofiling: Disabled\n",
+		},
+		{
+			name:          "paused added to rollout",
+			data:          "rollout: # canary first\n    strategy: Canary\nprofiling: Disabled\n",
+			expectChanged: true,
+			expectedData:  "rollout: # canary first\n    paused: true\n    strategy: Canary\nprofiling: Disabled\n",
+		},
+		{
+			name:          "rollout added",
+			data:          "profiling: Disabled # no pprof",
+			expectChanged: true,
+			expectedData:  "profiling: Disabled # no pprof\nrollout:\n  paused: true\n",
+		},
+		{name: "flow style", data: "rollout: {strategy: Canary}\n", expectedError: "without rewriting it"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, changed, err := pauseConfig(test.data)
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != test.expectChanged {
+				t.Errorf("expected changed %v, got %v", test.expectChanged, changed)
+			}
+			if len(test.expectedData) > 0 && data != test.expectedData {
+				t.Errorf("expected %q, got %q", test.expectedData, data)
+			}
+			config, err := operatorconfig.Decode([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !config.Rollout.Paused {
+				t.Errorf("expected the rollout to be paused, got %q", data)
+			}
+			if strings.Contains(test.data, "Canary") && config.Rollout.Strategy != "Canary" {
+				t.Errorf("expected the other settings to be kept, got %q", data)
+			}
+		})
+	}
+}
+
+func TestInstallerState(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected podState
+	}{
+		{
+			name:     "succeeded",
+			pod:      &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+			expected: stateDone,
+		},
+		{
+			name:     "deleted",
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{}}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			expected: stateDone,
+		},
+		{
+			name: "installing",
+			pod: &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "installer", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}}},
+			expected: stateInstalling,
+		},
+		{
+			name: "held",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{InitContainers: []corev1.Container{{Name: "wait-for-resume"}}},
+				Status: corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			expected: stateHeld,
+		},
+		{
+			name: "pending",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{InitContainers: []corev1.Container{{Name: "wait-for-canary"}}},
+				Status: corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			expected: statePending,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := installerState(test.pod); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	status := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-2", CurrentRevision: 4, TargetRevision: 4},
+			{NodeName: "master-0", CurrentRevision: 5},
+			{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5},
+		},
+	}
+	pods := []corev1.Pod{{
+		Spec:   corev1.PodSpec{NodeName: "master-1", InitContainers: []corev1.Container{{Name: "wait-for-resume"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}}
+
+	out := &bytes.Buffer{}
+	if err := report(out, status, pods); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `NODE      CURRENT  TARGET  STATE
+master-0  5        0       up to date
+master-1  4        5       held, revision 5 not installed
+master-2  4        4       waiting for revision 5
+`
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
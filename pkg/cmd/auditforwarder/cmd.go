@@ -0,0 +1,171 @@
+package auditforwarder
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apiserver/pkg/server"
+	"k8s.io/klog/v2"
+)
+
+// forwarderOpts holds the audit log to forward and where to.
+type forwarderOpts struct {
+	auditLog     string
+	positionFile string
+	protocol     string
+	endpoint     string
+	tls          bool
+	caFile       string
+	hostname     string
+
+	// tlsConfig is set when the endpoint is connected with TLS
+	tlsConfig *tls.Config
+}
+
+// NewAuditForwarderCommand creates the audit-forwarder command. It runs as a sidecar of the kube-apiserver static pod
+// and forwards the audit events of the node to a remote endpoint.
+func NewAuditForwarderCommand() *cobra.Command {
+	opts := forwarderOpts{
+		auditLog:     "/var/log/kube-apiserver/audit.log",
+		positionFile: "/var/log/kube-apiserver/audit-forwarder.position",
+		protocol:     "syslog",
+	}
+	cmd := &cobra.Command{
+		Use:   "audit-forwarder",
+		Short: "Forward the kube-apiserver audit log to a remote endpoint",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Complete(); err != nil {
+				klog.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				defer cancel()
+				<-server.SetupSignalHandler()
+				klog.Infof("Received SIGTERM or SIGINT signal, shutting down.")
+			}()
+			if err := opts.Run(ctx); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *forwarderOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.auditLog, "audit-log", o.auditLog, "The audit log to forward, it is followed when it is rotated")
+	fs.StringVar(&o.positionFile, "position-file", o.positionFile, "The file that records how far the audit log was forwarded, forwarding resumes from there after a restart")
+	fs.StringVar(&o.protocol, "protocol", o.protocol, "The protocol of the endpoint, only syslog is supported")
+	fs.StringVar(&o.endpoint, "endpoint", o.endpoint, "The host:port the audit events are sent to")
+	fs.BoolVar(&o.tls, "tls", o.tls, "Connect to the endpoint with TLS")
+	fs.StringVar(&o.caFile, "ca-file", o.caFile, "The CA bundle the certificate of the endpoint is verified with, defaults to the system trust bundle")
+	fs.StringVar(&o.hostname, "hostname", o.hostname, "The host name of the syslog messages, defaults to the host name of the node")
+}
+
+// Validate verifies the inputs.
+func (o *forwarderOpts) Validate() error {
+	if o.protocol != "syslog" {
+		return fmt.Errorf("unsupported protocol %q, only syslog is supported", o.protocol)
+	}
+	if _, _, err := net.SplitHostPort(o.endpoint); err != nil {
+		return fmt.Errorf("invalid endpoint: %v", err)
+	}
+	if len(o.auditLog) == 0 || len(o.positionFile) == 0 {
+		return fmt.Errorf("--audit-log and --position-file are required")
+	}
+	if len(o.caFile) > 0 && !o.tls {
+		return fmt.Errorf("--ca-file requires --tls")
+	}
+	return nil
+}
+
+// Complete fills in missing values before command execution.
+func (o *forwarderOpts) Complete() error {
+	if len(o.hostname) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		o.hostname = hostname
+	}
+	if !o.tls {
+		return nil
+	}
+	host, _, _ := net.SplitHostPort(o.endpoint)
+	o.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if len(o.caFile) > 0 {
+		caBundle, err := ioutil.ReadFile(o.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read ca-file: %v", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caBundle) {
+			return fmt.Errorf("no certificates in ca-file %s", o.caFile)
+		}
+		o.tlsConfig.RootCAs = roots
+	}
+	return nil
+}
+
+// Run forwards the audit events until the context is done. An event is only committed to the position file once the
+// endpoint accepted it, while the endpoint is slow or down the audit log is not read on.
+func (o *forwarderOpts) Run(ctx context.Context) error {
+	t, err := openTailer(o.auditLog, o.positionFile)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+	w := &syslogWriter{endpoint: o.endpoint, tlsConfig: o.tlsConfig, hostname: o.hostname}
+	defer w.close()
+
+	klog.Infof("Forwarding %s to %s", o.auditLog, o.endpoint)
+	for {
+		event, err := t.next(ctx)
+		if err == context.Canceled {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := o.send(ctx, w, event); err != nil {
+			return nil
+		}
+		if err := t.commit(); err != nil {
+			klog.Warningf("Failed to record the position: %v", err)
+		}
+	}
+}
+
+// send writes the event until the endpoint accepts it, with an exponential backoff. It only fails when the context
+// is done.
+func (o *forwarderOpts) send(ctx context.Context, w *syslogWriter, event []byte) error {
+	backoff := time.Second
+	for {
+		err := w.write(event, time.Now())
+		if err == nil {
+			return nil
+		}
+		klog.Warningf("Failed to send an audit event to %s, retrying in %v: %v", o.endpoint, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > time.Minute {
+			backoff = time.Minute
+		}
+	}
+}
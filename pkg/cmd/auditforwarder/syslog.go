@@ -0,0 +1,83 @@
+package auditforwarder
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// syslogPriority is facility 13 (log audit) and severity 6 (informational).
+	syslogPriority = 13*8 + 6
+	syslogAppName  = "kube-apiserver"
+	syslogMsgID    = "audit"
+
+	dialTimeout  = 10 * time.Second
+	writeTimeout = 30 * time.Second
+)
+
+// syslogWriter sends audit events as RFC 5424 syslog messages with octet-counted framing (RFC 6587) over TCP or TLS.
+// It connects on the first write and after a failed one.
+type syslogWriter struct {
+	endpoint  string
+	tlsConfig *tls.Config
+	hostname  string
+
+	conn net.Conn
+}
+
+// write sends the event as a single message with the timestamp now, it returns once the message is written to the
+// connection.
+func (w *syslogWriter) write(event []byte, now time.Time) error {
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+	message := formatSyslog(event, w.hostname, now)
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		w.close()
+		return err
+	}
+	if _, err := w.conn.Write(message); err != nil {
+		w.close()
+		return err
+	}
+	return nil
+}
+
+func (w *syslogWriter) connect() error {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	if w.tlsConfig != nil {
+		conn, err := tls.DialWithDialer(dialer, "tcp", w.endpoint, w.tlsConfig)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+	conn, err := dialer.Dial("tcp", w.endpoint)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *syslogWriter) close() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// formatSyslog returns the framed message of the event: its length, a space and the RFC 5424 message
+// "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG".
+func formatSyslog(event []byte, hostname string, now time.Time) []byte {
+	if len(hostname) == 0 {
+		hostname = "-"
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s - %s - ", syslogPriority, now.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), hostname, syslogAppName, syslogMsgID)
+	return append([]byte(fmt.Sprintf("%d %s", len(header)+len(event), header)), event...)
+}
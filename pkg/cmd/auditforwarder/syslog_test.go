@@ -0,0 +1,76 @@
+package auditforwarder
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFormatSyslog(t *testing.T) {
+	now := time.Date(2021, 10, 16, 12, 0, 0, 123456789, time.UTC)
+	message := formatSyslog([]byte(`{"kind":"Event"}`), "master-0", now)
+
+	expected := `<110>1 2021-10-16T12:00:00.123456Z master-0 kube-apiserver - audit - {"kind":"Event"}`
+	if string(message) != fmt.Sprintf("%d %s", len(expected), expected) {
+		t.Errorf("unexpected message %q", message)
+	}
+}
+
+func TestSyslogWriter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					var length int
+					if _, err := fmt.Fscanf(reader, "%d ", &length); err != nil {
+						return
+					}
+					message := make([]byte, length)
+					if _, err := io.ReadFull(reader, message); err != nil {
+						return
+					}
+					received <- string(message)
+				}
+			}(conn)
+		}
+	}()
+
+	w := &syslogWriter{endpoint: listener.Addr().String(), hostname: "master-0"}
+	defer w.close()
+	now := time.Date(2021, 10, 16, 12, 0, 0, 0, time.UTC)
+	send := func(event string) {
+		t.Helper()
+		if err := w.write([]byte(event), now); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case message := <-received:
+			if expected := "<110>1 2021-10-16T12:00:00.000000Z master-0 kube-apiserver - audit - " + event; message != expected {
+				t.Errorf("expected %q, got %q", expected, message)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for %s", event)
+		}
+	}
+
+	send(`{"event":1}`)
+	send(`{"event":2}`)
+	// a closed connection is reopened
+	w.close()
+	send(`{"event":3}`)
+}
@@ -0,0 +1,217 @@
+package auditforwarder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	// pollInterval is how often the audit log is checked for new events and rotation at its end.
+	pollInterval = time.Second
+	// saveInterval is how often the position is saved while events are committed.
+	saveInterval = time.Second
+)
+
+// tailer reads the lines of a log that is rotated by renaming it and creating a new file, like the audit log of the
+// kube-apiserver. The renamed file is read to its end before the new one is opened, the open file stays readable
+// even if the kube-apiserver deletes it, so no event is lost while the reader falls behind.
+type tailer struct {
+	path         string
+	positionFile string
+
+	file   *os.File
+	reader *bufio.Reader
+	inode  uint64
+	// offset is the end of the last line returned by next, committed the end of the last committed line.
+	offset, committed int64
+	// partial is the beginning of a line that is still being written.
+	partial []byte
+
+	lastSaved time.Time
+}
+
+// openTailer opens the log at the recorded position. Without a position the log is read from its end, with the
+// position of a file that was rotated since, the current file is read from its beginning.
+func openTailer(path, positionFile string) (*tailer, error) {
+	t := &tailer{path: path, positionFile: positionFile}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+
+	inode, offset, err := readPosition(positionFile)
+	if err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Ignoring the position in %s: %v", positionFile, err)
+	}
+	info, statErr := t.file.Stat()
+	if statErr != nil {
+		return nil, statErr
+	}
+	switch {
+	case err != nil:
+		offset = info.Size()
+	case inode != t.inode || offset > info.Size():
+		klog.Infof("%s was rotated since the recorded position, forwarding it from its beginning", path)
+		offset = 0
+	}
+	if _, err := t.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	t.reader.Reset(t.file)
+	t.offset, t.committed = offset, offset
+	return t, nil
+}
+
+func (t *tailer) open() error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	inode, err := inodeOf(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if t.file != nil {
+		t.file.Close()
+	}
+	t.file, t.inode = file, inode
+	t.reader = bufio.NewReaderSize(file, 64*1024)
+	t.offset, t.committed, t.partial = 0, 0, nil
+	return nil
+}
+
+// next returns the next complete line without its newline, it waits for the line to be written. The line of a file
+// that was rotated before it was complete is returned as is.
+func (t *tailer) next(ctx context.Context) ([]byte, error) {
+	for {
+		chunk, err := t.reader.ReadBytes('\n')
+		t.partial = append(t.partial, chunk...)
+		if err == nil {
+			line := t.partial
+			t.partial = nil
+			t.offset += int64(len(line))
+			return bytes.TrimSuffix(line, []byte("\n")), nil
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+
+		rotated, truncated, err := t.changed()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case rotated && len(t.partial) > 0:
+			line := t.partial
+			t.partial = nil
+			t.offset += int64(len(line))
+			return line, nil
+		case rotated:
+			klog.Infof("%s was rotated, forwarding the new file", t.path)
+			if err := t.open(); err != nil {
+				return nil, err
+			}
+			continue
+		case truncated:
+			klog.Warningf("%s was truncated, forwarding it from its beginning", t.path)
+			if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			t.reader.Reset(t.file)
+			t.offset, t.committed, t.partial = 0, 0, nil
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// changed returns whether the path is a new file or the file was truncated. While the path does not exist, between
+// the rename and the creation of the new file, nothing changed yet.
+func (t *tailer) changed() (rotated bool, truncated bool, err error) {
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if inode, ok := inodeOfInfo(info); ok && inode != t.inode {
+		return true, false, nil
+	}
+	return false, info.Size() < t.offset+int64(len(t.partial)), nil
+}
+
+// commit marks the lines returned by next as forwarded. The position is saved at most once per saveInterval.
+func (t *tailer) commit() error {
+	t.committed = t.offset
+	if time.Since(t.lastSaved) < saveInterval {
+		return nil
+	}
+	return t.save()
+}
+
+func (t *tailer) save() error {
+	t.lastSaved = time.Now()
+	tmp := t.positionFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(fmt.Sprintf("%d %d\n", t.inode, t.committed)), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.positionFile)
+}
+
+// close saves the position and closes the log.
+func (t *tailer) close() {
+	if err := t.save(); err != nil {
+		klog.Warningf("Failed to record the position: %v", err)
+	}
+	t.file.Close()
+}
+
+// readPosition returns the inode and offset recorded in the position file.
+func readPosition(positionFile string) (uint64, int64, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(positionFile))
+	if err != nil {
+		return 0, 0, err
+	}
+	var inode uint64
+	var offset int64
+	if _, err := fmt.Sscanf(string(data), "%d %d", &inode, &offset); err != nil {
+		return 0, 0, fmt.Errorf("invalid position %q: %v", string(data), err)
+	}
+	return inode, offset, nil
+}
+
+func inodeOf(file *os.File) (uint64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	inode, ok := inodeOfInfo(info)
+	if !ok {
+		return 0, fmt.Errorf("unable to get the inode of %s", file.Name())
+	}
+	return inode, nil
+}
+
+func inodeOfInfo(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Ino), true
+}
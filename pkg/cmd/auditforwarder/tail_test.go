@@ -0,0 +1,100 @@
+package auditforwarder
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailer(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+	saveInterval = 0
+
+	dir, err := ioutil.TempDir("", "audit-forwarder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	auditLog := filepath.Join(dir, "audit.log")
+	positionFile := filepath.Join(dir, "audit-forwarder.position")
+	appendTo := func(path, data string) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	expectNext := func(tailer *tailer, expected string) {
+		t.Helper()
+		line, err := tailer.next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != expected {
+			t.Fatalf("expected %q, got %q", expected, line)
+		}
+		if err := tailer.commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// without a position the existing events are skipped
+	appendTo(auditLog, "{\"old\":1}\n")
+	tailer, err := openTailer(auditLog, positionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendTo(auditLog, "{\"event\":1}\n{\"event\":")
+	expectNext(tailer, `{"event":1}`)
+
+	// the partial line is completed
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		appendTo(auditLog, "2}\n")
+	}()
+	expectNext(tailer, `{"event":2}`)
+
+	// the rotated file is read to its end before the new file
+	appendTo(auditLog, "{\"event\":3}\n")
+	if err := os.Rename(auditLog, filepath.Join(dir, "audit-2021-10-16T12-00-00.000.log")); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(auditLog, "{\"event\":4}\n")
+	expectNext(tailer, `{"event":3}`)
+	expectNext(tailer, `{"event":4}`)
+	tailer.close()
+
+	// a restart resumes at the recorded position
+	appendTo(auditLog, "{\"event\":5}\n")
+	tailer, err = openTailer(auditLog, positionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectNext(tailer, `{"event":5}`)
+	tailer.close()
+
+	// the file was rotated while the forwarder was down, the new one is read from its beginning
+	if err := os.Rename(auditLog, filepath.Join(dir, "audit-2021-10-16T13-00-00.000.log")); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(auditLog, "{\"event\":6}\n")
+	tailer, err = openTailer(auditLog, positionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tailer.close()
+	expectNext(tailer, `{"event":6}`)
+
+	cancel()
+	if _, err := tailer.next(ctx); err != context.Canceled {
+		t.Fatalf("expected the tailer to stop, got %v", err)
+	}
+}
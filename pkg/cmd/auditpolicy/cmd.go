@@ -0,0 +1,224 @@
+package auditpolicy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditpolicycontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// NewAuditPolicyCommand creates the audit-policy command.
+func NewAuditPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-policy",
+		Short: "Inspect the audit policy of the kube-apiserver",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(newRenderCommand())
+	return cmd
+}
+
+// renderOpts holds where the inputs of the audit policy are read from.
+type renderOpts struct {
+	kubeconfig      string
+	apiserverConfig string
+	operatorConfig  string
+	customRules     string
+	namespaces      []string
+
+	out io.Writer
+	err io.Writer
+}
+
+// newRenderCommand creates the audit-policy render command. It prints the policy.yaml the operator would roll out, so
+// that a change of the audit profile, the custom rules, the intents or the overrides can be reviewed and diffed
+// against the applied policy before it is made. The inputs are read from the cluster unless --apiserver-config is
+// given, then the command works offline and the other inputs default to empty.
+func newRenderCommand() *cobra.Command {
+	opts := renderOpts{}
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the audit policy the operator would generate",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			opts.err = cmd.ErrOrStderr()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *renderOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster the inputs are read from, defaults to the in-cluster config")
+	fs.StringVar(&o.apiserverConfig, "apiserver-config", o.apiserverConfig, "A file with the apiserver.config.openshift.io object to render the policy for, instead of apiserver/cluster. The cluster is not contacted then")
+	fs.StringVar(&o.operatorConfig, "operator-config", o.operatorConfig, fmt.Sprintf("A file with the %s of the operator config, instead of configmap %s/%s", operatorconfig.ConfigKey, operatorclient.GlobalUserSpecifiedConfigNamespace, operatorconfig.ConfigMapName))
+	fs.StringVar(&o.customRules, "custom-rules", o.customRules, "A file with the policy.yaml of the custom rules, instead of the configmap named by auditPolicy.customRulesConfigMap")
+	fs.StringSliceVar(&o.namespaces, "namespaces", o.namespaces, "The namespaces the prefixes of auditPolicy.overrides are expanded to, instead of the namespaces of the cluster")
+}
+
+// Validate verifies the inputs.
+func (o *renderOpts) Validate() error {
+	if len(o.apiserverConfig) > 0 && len(o.kubeconfig) > 0 {
+		return fmt.Errorf("--kubeconfig and --apiserver-config are mutually exclusive")
+	}
+	return nil
+}
+
+// Run prints the rendered policy, the conflicts of the custom rules with the profile are printed as warnings.
+func (o *renderOpts) Run(ctx context.Context) error {
+	var in auditpolicycontroller.PolicyInputs
+	var err error
+	if len(o.apiserverConfig) > 0 {
+		in, err = o.fileInputs()
+	} else {
+		in, err = o.clusterInputs(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	policy, conflicts, err := auditpolicycontroller.RenderPolicy(in)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintf(o.err, "Warning: %s\n", conflict)
+	}
+	_, err = o.out.Write(policy)
+	return err
+}
+
+// fileInputs reads the inputs from the given files, missing ones are empty.
+func (o *renderOpts) fileInputs() (auditpolicycontroller.PolicyInputs, error) {
+	in := auditpolicycontroller.PolicyInputs{Namespaces: o.namespaces}
+	data, err := ioutil.ReadFile(o.apiserverConfig)
+	if err != nil {
+		return in, err
+	}
+	apiserver := &configv1.APIServer{}
+	if err := yaml.Unmarshal(data, apiserver); err != nil {
+		return in, fmt.Errorf("unable to decode %s: %v", o.apiserverConfig, err)
+	}
+	in.Audit = apiserver.Spec.Audit
+	if len(in.Audit.Profile) == 0 {
+		// the default of the API
+		in.Audit.Profile = configv1.DefaultAuditProfileType
+	}
+
+	if len(o.operatorConfig) > 0 {
+		config, err := readOperatorConfig(o.operatorConfig)
+		if err != nil {
+			return in, err
+		}
+		in.Config = config.AuditPolicy
+	}
+	if len(o.customRules) > 0 {
+		if in.CustomRules, err = ioutil.ReadFile(o.customRules); err != nil {
+			return in, err
+		}
+	}
+	return in, nil
+}
+
+// clusterInputs reads the inputs from the cluster, unless they are given as files.
+func (o *renderOpts) clusterInputs(ctx context.Context) (auditpolicycontroller.PolicyInputs, error) {
+	in := auditpolicycontroller.PolicyInputs{Namespaces: o.namespaces}
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return in, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return in, err
+	}
+	configClient, err := configclient.NewForConfig(config)
+	if err != nil {
+		return in, err
+	}
+
+	apiserver, err := configClient.ConfigV1().APIServers().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return in, err
+	}
+	in.Audit = apiserver.Spec.Audit
+
+	operatorConfig := &operatorconfig.KubeAPIServerOperatorConfig{}
+	if len(o.operatorConfig) > 0 {
+		if operatorConfig, err = readOperatorConfig(o.operatorConfig); err != nil {
+			return in, err
+		}
+	} else {
+		cm, err := kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, operatorconfig.ConfigMapName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return in, err
+		default:
+			if operatorConfig, err = operatorconfig.Decode([]byte(cm.Data[operatorconfig.ConfigKey])); err != nil {
+				return in, fmt.Errorf("configmap %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, operatorconfig.ConfigMapName, err)
+			}
+		}
+	}
+	in.Config = operatorConfig.AuditPolicy
+
+	switch name := in.Config.CustomRulesConfigMap; {
+	case len(o.customRules) > 0:
+		if in.CustomRules, err = ioutil.ReadFile(o.customRules); err != nil {
+			return in, err
+		}
+	case len(name) > 0:
+		cm, err := kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return in, fmt.Errorf("custom audit rules: %w", err)
+		}
+		in.CustomRules = []byte(cm.Data["policy.yaml"])
+	}
+
+	if len(o.namespaces) == 0 && len(in.Config.Overrides) > 0 {
+		namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return in, err
+		}
+		for _, namespace := range namespaces.Items {
+			in.Namespaces = append(in.Namespaces, namespace.Name)
+		}
+	}
+	return in, nil
+}
+
+func readOperatorConfig(path string) (*operatorconfig.KubeAPIServerOperatorConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := operatorconfig.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
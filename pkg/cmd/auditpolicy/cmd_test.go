@@ -0,0 +1,87 @@
+package auditpolicy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apiserver/pkg/audit/policy"
+)
+
+func TestRenderFromFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-policy-render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name           string
+		apiserver      string
+		operatorConfig string
+		customRules    string
+		expected       []string
+		expectedError  string
+	}{
+		{
+			name:      "default profile",
+			apiserver: "apiVersion: config.openshift.io/v1\nkind: APIServer\nmetadata:\n  name: cluster\n",
+			expected:  []string{"kind: Policy", "level: Metadata"},
+		},
+		{
+			name:           "custom rules and intents",
+			apiserver:      "spec:\n  audit:\n    profile: WriteRequestBodies\n",
+			operatorConfig: "auditPolicy:\n  customRulesConfigMap: audit-rules\n  intents:\n  - level: RequestResponse\n    users: [alice]\n",
+			customRules:    "rules:\n- level: None\n  users: [system:apiserver]\n",
+			expected:       []string{"system:apiserver", "alice", "level: RequestResponse"},
+		},
+		{
+			name:          "unacknowledged None profile",
+			apiserver:     "spec:\n  audit:\n    profile: None\n",
+			expectedError: "acknowledgeNoneProfile",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &renderOpts{apiserverConfig: write("apiserver.yaml", test.apiserver), out: out, err: &bytes.Buffer{}}
+			if len(test.operatorConfig) > 0 {
+				o.operatorConfig = write("config.yaml", test.operatorConfig)
+			}
+			if len(test.customRules) > 0 {
+				o.customRules = write("policy.yaml", test.customRules)
+			}
+
+			err := o.Run(context.TODO())
+			if len(test.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := policy.LoadPolicyFromBytes(out.Bytes()); err != nil {
+				t.Fatalf("invalid policy: %v", err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected %q in the policy:\n%s", expected, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/encryption/state"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
+)
+
+// encryptionProviders are the encryption types of the apiserver.config.openshift.io/cluster object the encryption
+// controllers of library-go accept, secretbox is disabled there.
+var encryptionProviders = []state.Mode{state.Identity, state.AESCBC}
+
+// Capabilities is what the operator version supports.
+type Capabilities struct {
+	// Version is the version the operator was built from.
+	Version string `json:"version"`
+	// OperatorConfigFields are the paths of the fields of the openshift-config/kube-apiserver-config operator config
+	// that are honored.
+	OperatorConfigFields []string `json:"operatorConfigFields"`
+	// ConfigObservers are the config observers in the order they run.
+	ConfigObservers []string `json:"configObservers"`
+	// CertLifetimes are the default lifetimes of the certificates the operator rotates.
+	CertLifetimes []certrotationcontroller.CertLifetime `json:"certLifetimes"`
+	// Encryption is what encryption of resources in etcd is supported.
+	Encryption EncryptionCapabilities `json:"encryption"`
+}
+
+// EncryptionCapabilities is what encryption of resources in etcd is supported.
+type EncryptionCapabilities struct {
+	// Providers are the supported encryption types.
+	Providers []state.Mode `json:"providers"`
+	// DefaultProvider is the encryption type used when none is set.
+	DefaultProvider state.Mode `json:"defaultProvider"`
+	// Resources are the resources that are encrypted.
+	Resources []string `json:"resources"`
+}
+
+// NewCapabilitiesCommand creates the capabilities command. It prints what this operator version supports as JSON, so
+// that fleet tooling can tell without the release notes: the operator config fields it honors, the config observers
+// compiled in, the default lifetimes of the certificates it rotates and the supported encryption providers.
+func NewCapabilitiesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Print what this operator version supports as JSON",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := Print(os.Stdout); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
+
+// Get returns what this operator version supports.
+func Get() (*Capabilities, error) {
+	lifetimes, err := certrotationcontroller.DefaultLifetimes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the certificate lifetimes: %v", err)
+	}
+	var resources []string
+	for _, gr := range operator.EncryptedResources.EncryptedGRs() {
+		resources = append(resources, gr.String())
+	}
+	return &Capabilities{
+		Version:              version.Get().String(),
+		OperatorConfigFields: operatorconfig.Fields(),
+		ConfigObservers:      configobservercontroller.ObserverNames(),
+		CertLifetimes:        lifetimes,
+		Encryption: EncryptionCapabilities{
+			Providers:       encryptionProviders,
+			DefaultProvider: state.DefaultMode,
+			Resources:       resources,
+		},
+	}, nil
+}
+
+// Print writes what this operator version supports as indented JSON.
+func Print(out io.Writer) error {
+	capabilities, err := Get()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(capabilities, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,52 @@
+package capabilities
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestPrint(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := Print(out); err != nil {
+		t.Fatal(err)
+	}
+	capabilities := &Capabilities{}
+	if err := json.Unmarshal(out.Bytes(), capabilities); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	fields := sets.NewString(capabilities.OperatorConfigFields...)
+	for _, field := range []string{"runtimeConfig", "rollout.nodeOrder.type", "rollout.nodeGates[].conditionType", "serviceAccountSigningKey.secretName"} {
+		if !fields.Has(field) {
+			t.Errorf("expected operator config field %q, got %v", field, capabilities.OperatorConfigFields)
+		}
+	}
+	if fields.Has("resources.limits") {
+		t.Errorf("expected the fields of types of other packages to be left out")
+	}
+
+	if observers := sets.NewString(capabilities.ConfigObservers...); !observers.HasAll("apiserver.ObserveNamedCertificates", "encryption.EncryptionConfigObserver") {
+		t.Errorf("unexpected config observers %v", capabilities.ConfigObservers)
+	}
+
+	var signer bool
+	for _, lifetime := range capabilities.CertLifetimes {
+		if lifetime.Secret == "openshift-kube-apiserver-operator/aggregator-client-signer" {
+			signer = lifetime.Signer
+			if len(lifetime.Validity) == 0 || len(lifetime.Refresh) == 0 {
+				t.Errorf("expected the validity and refresh of %s, got %+v", lifetime.Secret, lifetime)
+			}
+		}
+	}
+	if !signer {
+		t.Errorf("expected the aggregator client signer in %+v", capabilities.CertLifetimes)
+	}
+
+	if encryption := capabilities.Encryption; encryption.DefaultProvider != "identity" ||
+		len(encryption.Providers) != 2 || !sets.NewString(encryption.Resources...).HasAll("secrets", "configmaps") {
+		t.Errorf("unexpected encryption capabilities %+v", encryption)
+	}
+}
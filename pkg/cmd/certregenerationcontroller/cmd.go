@@ -3,6 +3,8 @@ package certregenerationcontroller
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -17,6 +19,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/genericoperatorclient"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/regeneratecertificates"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
@@ -24,10 +27,19 @@ import (
 
 type Options struct {
 	controllerContext *controllercmd.ControllerContext
+
+	// certs limits the controller to the named certificates, see certrotationcontroller.CertRotationController.Select
+	certs []string
+	// dryRun prints the certificates that would be regenerated and exits
+	dryRun bool
+	// standalone runs the controller from outside of the cluster, e.g. from a workstation during a partial outage
+	standalone bool
+
+	out io.Writer
 }
 
 func NewCertRegenerationControllerCommand(ctx context.Context) *cobra.Command {
-	o := &Options{}
+	o := &Options{out: os.Stdout}
 
 	ccc := controllercmd.NewControllerCommandConfig("cert-regeneration-controller", version.Get(), func(ctx context.Context, controllerContext *controllercmd.ControllerContext) error {
 		o.controllerContext = controllerContext
@@ -60,10 +72,34 @@ func NewCertRegenerationControllerCommand(ctx context.Context) *cobra.Command {
 	cmd.Use = "cert-regeneration-controller"
 	cmd.Short = "Start the Cluster Certificate Regeneration Controller"
 
+	cmd.Flags().StringSliceVar(&o.certs, "certs", o.certs, "The certificates to regenerate by the name of their secret, of their signer secret or of their cert rotator, all when empty")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print the certificates that would be regenerated and exit")
+	cmd.Flags().BoolVar(&o.standalone, "standalone", o.standalone, "Run outside of the cluster with the explicit --kubeconfig, without leader election")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if o.standalone && !cmd.Flags().Changed("kubeconfig") {
+			return fmt.Errorf("--standalone requires --kubeconfig")
+		}
+		if o.standalone && !cmd.Flags().Changed("namespace") {
+			// events are recorded in the namespace of the in-cluster controller
+			if err := cmd.Flags().Set("namespace", operatorclient.TargetNamespace); err != nil {
+				return err
+			}
+		}
+		// the in-cluster controllers keep the leader lock, and a dry-run exits right away, which leader election treats
+		// as a failure
+		ccc.DisableLeaderElection = o.standalone || o.dryRun
+		return nil
+	}
+
 	return cmd
 }
 
 func (o *Options) Validate(ctx context.Context) error {
+	for _, cert := range o.certs {
+		if len(cert) == 0 {
+			return fmt.Errorf("--certs must not contain empty names")
+		}
+	}
 	return nil
 }
 
@@ -113,6 +149,24 @@ func (o *Options) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if len(o.certs) > 0 {
+		if err := kubeAPIServerCertRotationController.Select(o.certs); err != nil {
+			return err
+		}
+	}
+
+	if o.dryRun {
+		configInformers.Start(ctx.Done())
+		kubeAPIServerInformersForNamespaces.Start(ctx.Done())
+		dynamicInformers.Start(ctx.Done())
+		kubeAPIServerCertRotationController.WaitForReady(ctx.Done())
+
+		regenerations, err := kubeAPIServerCertRotationController.Plan(time.Now())
+		if err != nil {
+			return err
+		}
+		return regeneratecertificates.PrintRegenerations(o.out, regenerations, true)
+	}
 
 	caBundleController, err := NewCABundleController(
 		kubeClient.CoreV1(),
@@ -8,6 +8,7 @@ import (
 	operatorcontrolplaneclient "github.com/openshift/client-go/operatorcontrolplane/clientset/versioned"
 	operatorcontrolplaneinformers "github.com/openshift/client-go/operatorcontrolplane/informers/externalversions"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/controller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditlogvolume"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -17,12 +18,18 @@ import (
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/server/mux"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 )
 
 func NewCheckEndpointsCommand() *cobra.Command {
+	profiling := true
+	var auditLogDir string
 	config := controllercmd.NewControllerCommandConfig("check-endpoints", version.Get(), func(ctx context.Context, cctx *controllercmd.ControllerContext) error {
+		if !profiling && cctx.Server != nil {
+			disableProfiling(cctx.Server.Handler.NonGoRestfulMux)
+		}
 		podName := os.Getenv("POD_NAME")
 		namespace := os.Getenv("POD_NAMESPACE")
 		kubeClient := kubernetes.NewForConfigOrDie(cctx.ProtoKubeConfig)
@@ -57,6 +64,11 @@ func NewCheckEndpointsCommand() *cobra.Command {
 		}
 		recorder := events.NewRecorder(kubeClient.CoreV1().Events(namespace), "check-endpoint", involvedObjectRef)
 
+		// the audit log volume is reported regardless of the connectivity checks
+		if len(auditLogDir) > 0 {
+			go auditlogvolume.NewMonitor(auditLogDir, namespace, involvedObjectRef.Name, kubeClient.CoreV1()).Run(ctx, 30*time.Second)
+		}
+
 		check := controller.NewPodNetworkConnectivityCheckController(
 			podName,
 			namespace,
@@ -105,5 +117,28 @@ func NewCheckEndpointsCommand() *cobra.Command {
 	cmd := config.NewCommandWithContext(context.Background())
 	cmd.Use = "check-endpoints"
 	cmd.Short = "Checks that a tcp connection can be opened to one or more endpoints."
+	cmd.Flags().BoolVar(&profiling, "profiling", profiling, "Serve the /debug/pprof and /debug/flags endpoints.")
+	cmd.Flags().StringVar(&auditLogDir, "audit-log-dir", auditLogDir, "Report the usage of the volume of this audit log directory, for the operator to degrade before it is full.")
+	cmd.AddCommand(newCheckOnceCommand())
 	return cmd
 }
+
+// profilingPaths are the debug endpoints the generic apiserver serves with profiling enabled.
+var profilingPaths = []string{
+	"/debug/pprof",
+	"/debug/pprof/",
+	"/debug/pprof/profile",
+	"/debug/pprof/symbol",
+	"/debug/pprof/trace",
+	"/debug/flags",
+	"/debug/flags/",
+	"/debug/flags/v",
+}
+
+// disableProfiling removes the debug endpoints from the mux. The controller command always enables profiling on its
+// server, they are removed right after it started.
+func disableProfiling(pathMux *mux.PathRecorderMux) {
+	for _, path := range profilingPaths {
+		pathMux.Unregister(path)
+	}
+}
@@ -0,0 +1,180 @@
+package checkendpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/server/mux"
+	"k8s.io/apiserver/pkg/server/routes"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+)
+
+func TestDisableProfiling(t *testing.T) {
+	pathMux := mux.NewPathRecorderMux("check-endpoints")
+	routes.Profiling{}.Install(pathMux)
+	routes.DebugFlags{}.Install(pathMux, "v", routes.StringFlagPutHandler(func(string) (string, error) { return "", nil }))
+	pathMux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	disableProfiling(pathMux)
+
+	for _, path := range []string{"/debug/pprof", "/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/profile", "/debug/flags/v"} {
+		recorder := httptest.NewRecorder()
+		pathMux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusNotFound, recorder.Code)
+		}
+	}
+	recorder := httptest.NewRecorder()
+	pathMux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("/healthz: expected %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestCheckForTarget(t *testing.T) {
+	testCases := []struct {
+		target              string
+		expectedEndpoint    string
+		expectedAnnotations map[string]string
+		expectedErr         bool
+	}{
+		{target: "etcd-0:2379", expectedEndpoint: "etcd-0:2379"},
+		{target: "[fd00::1]:6443", expectedEndpoint: "[fd00::1]:6443"},
+		{target: "api-int.example.com", expectedEndpoint: "api-int.example.com:"},
+		{
+			target:           "https://10.0.0.1:6443/readyz",
+			expectedEndpoint: "10.0.0.1:6443",
+			expectedAnnotations: map[string]string{
+				v1alpha1helpers.HTTPGetPathAnnotation:   "/readyz",
+				v1alpha1helpers.HTTPGetSchemeAnnotation: "HTTPS",
+			},
+		},
+		{
+			target:           "http://sso.example.com",
+			expectedEndpoint: "sso.example.com:80",
+			expectedAnnotations: map[string]string{
+				v1alpha1helpers.HTTPGetPathAnnotation:   "/",
+				v1alpha1helpers.HTTPGetSchemeAnnotation: "HTTP",
+			},
+		},
+		{target: "tcp://etcd-0:2379", expectedErr: true},
+		{target: ":2379", expectedErr: true},
+		{target: "https:///readyz", expectedErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			check, err := checkForTarget(tc.target, 5*time.Second)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", check)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Spec.TargetEndpoint != tc.expectedEndpoint {
+				t.Errorf("expected endpoint %q, got %q", tc.expectedEndpoint, check.Spec.TargetEndpoint)
+			}
+			expectedAnnotations := map[string]string{v1alpha1helpers.TimeoutAnnotation: "5s"}
+			for k, v := range tc.expectedAnnotations {
+				expectedAnnotations[k] = v
+			}
+			if !reflect.DeepEqual(check.Annotations, expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", expectedAnnotations, check.Annotations)
+			}
+		})
+	}
+}
+
+func TestSelectChecks(t *testing.T) {
+	newCheck := func(name, sourcePod, target string, annotations map[string]string) *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck {
+		return &operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Spec:       operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckSpec{SourcePod: sourcePod, TargetEndpoint: target},
+		}
+	}
+	checks := []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck{
+		newCheck("b-to-etcd", "b", "etcd:2379", nil),
+		newCheck("a-to-etcd", "a", "etcd:2379", nil),
+		newCheck("a-to-lb", "a", "lb:6443", map[string]string{v1alpha1helpers.TimeoutAnnotation: "3s"}),
+		newCheck("a-to-lb-readyz", "a", "lb:6443", map[string]string{v1alpha1helpers.HTTPGetPathAnnotation: "/readyz"}),
+	}
+
+	names := func(checks []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) []string {
+		var names []string
+		for _, check := range checks {
+			names = append(names, check.Name)
+		}
+		return names
+	}
+	selected := selectChecks(checks, "", time.Second)
+	if expected := []string{"a-to-etcd", "a-to-lb", "a-to-lb-readyz"}; !reflect.DeepEqual(names(selected), expected) {
+		t.Errorf("expected %v, got %v", expected, names(selected))
+	}
+	if timeout := selected[0].Annotations[v1alpha1helpers.TimeoutAnnotation]; timeout != "1s" {
+		t.Errorf("expected the default timeout, got %q", timeout)
+	}
+	if timeout := selected[1].Annotations[v1alpha1helpers.TimeoutAnnotation]; timeout != "3s" {
+		t.Errorf("expected the timeout of the check, got %q", timeout)
+	}
+	if checks[0].Annotations != nil {
+		t.Errorf("expected the checks not to be mutated")
+	}
+	if selected := selectChecks(checks, "b", time.Second); !reflect.DeepEqual(names(selected), []string{"b-to-etcd"}) {
+		t.Errorf("expected the checks of b, got %v", names(selected))
+	}
+}
+
+func TestCheckOnceTargets(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/readyz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	out := &bytes.Buffer{}
+	opts := &onceOpts{
+		targets: []string{server.URL + "/readyz", server.URL + "/livez", address},
+		timeout: 5 * time.Second,
+		output:  jsonOutput,
+		out:     out,
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Run(context.TODO()); err == nil || err.Error() != "1 of 3 checks failed" {
+		t.Errorf("expected one failed check, got %v", err)
+	}
+
+	var results []onceResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %s", out.String())
+	}
+	if readyz := results[0]; !readyz.Success || readyz.HTTPStatusCode != http.StatusOK || readyz.TLS == nil || len(readyz.ConnectLatency) == 0 {
+		t.Errorf("unexpected result of /readyz: %+v", readyz)
+	}
+	if livez := results[1]; livez.Success || livez.HTTPStatusCode != http.StatusInternalServerError || livez.Error != "unexpected status code 500" {
+		t.Errorf("unexpected result of /livez: %+v", livez)
+	}
+	if tcp := results[2]; !tcp.Success || tcp.TLS == nil || tcp.TLS.Subject != "O=Acme Co" {
+		t.Errorf("unexpected result of the TCP connection: %+v", tcp)
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
+)
+
+// CheckResult is the result of checking the target of a PodNetworkConnectivityCheck once.
+type CheckResult struct {
+	Name           string
+	TargetEndpoint string
+	// URL is the URL of the HTTP GET request of the check, empty if the check only connects
+	URL     string
+	Latency *trace.LatencyInfo
+	// PeerCertificates are the certificates presented by a TLS target endpoint, the serving certificate first
+	PeerCertificates []*x509.Certificate
+	// TLSCertificateCondition is the TLSCertificateValid condition of the peer certificates, nil without any
+	TLSCertificateCondition *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition
+	Err                     error
+}
+
+// CheckOnce checks the target of the check once with the settings of the check, like the connection checker of the
+// check does periodically, but without updating the status of the check.
+func CheckOnce(ctx context.Context, check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, clientCertGetter CertificatesGetter) CheckResult {
+	if clientCertGetter == nil {
+		clientCertGetter = func() []tls.Certificate { return nil }
+	}
+	c := &connectionChecker{
+		name:             check.Name,
+		settings:         settingsForCheck(check),
+		clientCertGetter: clientCertGetter,
+		metrics:          NewMetricsContext(check.Namespace, check.Name),
+	}
+
+	result := CheckResult{Name: check.Name, TargetEndpoint: check.Spec.TargetEndpoint}
+	if host, ok := isDNSCheck(check); ok {
+		result.Latency, result.Err = c.getDNSResolveLatency(ctx, host)
+		return result
+	}
+	if c.settings.httpGet != nil {
+		result.URL = c.settings.httpGet.url(check.Spec.TargetEndpoint)
+	}
+	result.Latency, result.PeerCertificates, result.Err = c.getTCPConnectLatency(ctx, check.Spec.TargetEndpoint)
+	if len(result.PeerCertificates) > 0 {
+		condition := newPeerCertificatesCondition(result.PeerCertificates, time.Now())
+		result.TLSCertificateCondition = &condition
+	}
+	return result
+}
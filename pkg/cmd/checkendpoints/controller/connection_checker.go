@@ -3,9 +3,12 @@ package controller
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
@@ -22,6 +25,78 @@ const (
 	checkTimeout = 10 * time.Second
 )
 
+// checkSettings are how often and how long a target is checked, and how much of the history of the check is kept.
+type checkSettings struct {
+	period  time.Duration
+	timeout time.Duration
+	// successLogInterval is the minimum time between two successes of the same kind in the status, zero records all
+	successLogInterval time.Duration
+	// network is the network dialed, tcp4 or tcp6 limit the check to the addresses of a single IP family
+	network string
+	// httpGet is the HTTP GET request that follows the TCP connection, nil to only connect
+	httpGet *httpGet
+	retention
+}
+
+// settingsForCheck returns the settings in the annotations of the check, or the defaults for missing or invalid
+// annotations.
+func settingsForCheck(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) checkSettings {
+	return checkSettings{
+		period:             durationAnnotation(check, v1alpha1helpers.IntervalAnnotation, checkPeriod),
+		timeout:            durationAnnotation(check, v1alpha1helpers.TimeoutAnnotation, checkTimeout),
+		successLogInterval: durationAnnotation(check, v1alpha1helpers.SuccessLogIntervalAnnotation, 0),
+		network:            networkForCheck(check),
+		httpGet:            httpGetForCheck(check),
+		retention: retention{
+			maxOutages:    intAnnotation(check, v1alpha1helpers.MaxOutagesAnnotation, maxOutages),
+			maxLogEntries: intAnnotation(check, v1alpha1helpers.MaxLogEntriesAnnotation, maxLogEntries),
+			maxAge:        durationAnnotation(check, v1alpha1helpers.MaxAgeAnnotation, 0),
+			compactionGap: durationAnnotation(check, v1alpha1helpers.OutageCompactionGapAnnotation, 0),
+		},
+	}
+}
+
+// networkForCheck returns the network of the IP family label of the check, or tcp for any family.
+func networkForCheck(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) string {
+	switch family := check.Labels[v1alpha1helpers.IPFamilyLabel]; family {
+	case "":
+		return "tcp"
+	case "IPv4":
+		return "tcp4"
+	case "IPv6":
+		return "tcp6"
+	default:
+		klog.Warningf("Ignoring invalid %s %q of %s", v1alpha1helpers.IPFamilyLabel, family, check.Name)
+		return "tcp"
+	}
+}
+
+func durationAnnotation(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, annotation string, defaultValue time.Duration) time.Duration {
+	value, ok := check.Annotations[annotation]
+	if !ok {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		klog.Warningf("Ignoring invalid %s %q of %s", annotation, value, check.Name)
+		return defaultValue
+	}
+	return duration
+}
+
+func intAnnotation(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, annotation string, defaultValue int) int {
+	value, ok := check.Annotations[annotation]
+	if !ok {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i <= 0 {
+		klog.Warningf("Ignoring invalid %s %q of %s", annotation, value, check.Name)
+		return defaultValue
+	}
+	return i
+}
+
 // ConnectionChecker checks a single connection and updates status when appropriate
 type ConnectionChecker interface {
 	Run(ctx context.Context)
@@ -31,15 +106,16 @@ type ConnectionChecker interface {
 type GetCheckFunc func() *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck
 
 // NewConnectionChecker returns a ConnectionChecker.
-func NewConnectionChecker(name, podName, podNamespace string, getCheck GetCheckFunc, client v1alpha1helpers.PodNetworkConnectivityCheckClient, clientCertGetter CertificatesGetter, recorder Recorder) ConnectionChecker {
+func NewConnectionChecker(name, podName, podNamespace string, settings checkSettings, getCheck GetCheckFunc, client v1alpha1helpers.PodNetworkConnectivityCheckClient, clientCertGetter CertificatesGetter, recorder Recorder) ConnectionChecker {
 	return &connectionChecker{
 		name:             name,
 		podName:          podName,
+		settings:         settings,
 		getCheck:         getCheck,
 		client:           client,
 		clientCertGetter: clientCertGetter,
 		recorder:         recorder,
-		updates:          NewUpdatesManager(checkPeriod, checkTimeout, newUpdatesProcessor(client, name)),
+		updates:          NewUpdatesManager(settings.period, settings.timeout, newUpdatesProcessor(client, name)),
 		stop:             make(chan interface{}),
 		metrics:          NewMetricsContext(podNamespace, name),
 	}
@@ -57,6 +133,7 @@ type CertificatesGetter func() []tls.Certificate
 type connectionChecker struct {
 	name     string
 	podName  string
+	settings checkSettings
 	getCheck GetCheckFunc
 
 	client           v1alpha1helpers.PodNetworkConnectivityCheckClient
@@ -69,7 +146,7 @@ type connectionChecker struct {
 
 // checkConnection checks the connection periodically, updating status as needed
 func (c *connectionChecker) checkConnection(ctx context.Context) {
-	ticker := time.NewTicker(checkPeriod)
+	ticker := time.NewTicker(c.settings.period)
 	defer ticker.Stop()
 	defer klog.V(1).Infof("Stopped connectivity check %s.", c.name)
 	for {
@@ -106,7 +183,7 @@ func (c *connectionChecker) Run(ctx context.Context) {
 	}()
 	go wait.UntilWithContext(ctx2, func(ctx context.Context) {
 		c.checkConnection(ctx2)
-	}, checkPeriod)
+	}, c.settings.period)
 	klog.V(1).Infof("Started connectivity check %s.", c.name)
 	<-ctx2.Done()
 }
@@ -127,31 +204,56 @@ func (c *connectionChecker) updateStatus(ctx context.Context, flush bool) {
 
 // checkEndpoint performs the check and manages the PodNetworkConnectivityCheck.Status changes that result.
 func (c *connectionChecker) checkEndpoint(ctx context.Context, check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) {
-	latencyInfo, err := c.getTCPConnectLatency(ctx, check.Spec.TargetEndpoint)
+	var latencyInfo *trace.LatencyInfo
+	var peerCerts []*x509.Certificate
+	var err error
+	if host, ok := isDNSCheck(check); ok {
+		latencyInfo, err = c.getDNSResolveLatency(ctx, host)
+	} else {
+		latencyInfo, peerCerts, err = c.getTCPConnectLatency(ctx, check.Spec.TargetEndpoint)
+	}
 	statusUpdates, timestamp := manageStatusLogs(check, err, latencyInfo)
+	if len(statusUpdates) > 0 && c.settings.successLogInterval > 0 {
+		statusUpdates = append(statusUpdates, manageStatusSuccessLogInterval(c.settings.successLogInterval))
+	}
 	if len(statusUpdates) > 0 {
 		statusUpdates = append(statusUpdates, manageStatusOutage(c.recorder))
 	}
 	if len(statusUpdates) > 0 {
 		statusUpdates = append(statusUpdates, manageStatusConditions)
 	}
+	if len(statusUpdates) > 0 {
+		statusUpdates = append(statusUpdates, manageStatusRetention(c.settings.retention, time.Now()))
+	}
+	if len(peerCerts) > 0 {
+		description := regexp.MustCompile(".*-to-").ReplaceAllString(check.Name, "")
+		statusUpdates = append(statusUpdates, manageStatusPeerCertificates(c.recorder, description, peerCerts, time.Now()))
+	}
 	c.updates.Add(timestamp, statusUpdates...)
 }
 
-// getTCPConnectLatency connects to a tcp endpoint and collects latency info
-func (c *connectionChecker) getTCPConnectLatency(ctx context.Context, address string) (*trace.LatencyInfo, error) {
+// getTCPConnectLatency connects to a tcp endpoint and collects latency info and the certificates of TLS endpoints
+func (c *connectionChecker) getTCPConnectLatency(ctx context.Context, address string) (*trace.LatencyInfo, []*x509.Certificate, error) {
 	klog.V(4).Infof("Check BEGIN: %v", address)
 	defer klog.V(4).Infof("Check END  : %v", address)
 	ctx, latencyInfo := trace.WithLatencyInfoCapture(ctx)
 
 	// tcp connection
 	dialer := &net.Dialer{
-		Timeout: checkTimeout,
+		Timeout: c.settings.timeout,
 	}
-	tcpConn, err := dialer.DialContext(ctx, "tcp", address)
+	tcpConn, err := dialer.DialContext(ctx, c.settings.network, address)
 	if err != nil {
 		c.metrics.Update(address, latencyInfo, err)
-		return latencyInfo, err
+		return latencyInfo, nil, err
+	}
+
+	if c.settings.httpGet != nil {
+		peerCerts, err := c.getHTTPGetLatency(tcpConn, address, latencyInfo)
+		_ = tcpConn.Close()
+		c.metrics.Update(address, latencyInfo, err)
+		c.metrics.UpdatePeerCertificates(address, peerCerts)
+		return latencyInfo, peerCerts, err
 	}
 
 	// perform tls handshake to avoid spamming the logs of tls endpoints
@@ -162,24 +264,46 @@ func (c *connectionChecker) getTCPConnectLatency(ctx context.Context, address st
 		klog.V(4).Infof("%s: tls error ignored: %v", address, err)
 		_ = tcpConn.Close()
 		c.metrics.Update(address, latencyInfo, nil)
-		return latencyInfo, nil
+		return latencyInfo, nil, nil
 	}
+	peerCerts := tlsConn.ConnectionState().PeerCertificates
 
 	// gracefully close connection (ignore error)
 	_ = tlsConn.Close()
 
 	c.metrics.Update(address, latencyInfo, err)
+	c.metrics.UpdatePeerCertificates(address, peerCerts)
+	return latencyInfo, peerCerts, err
+}
+
+// getDNSResolveLatency resolves the host name of a DNS check and collects latency info
+func (c *connectionChecker) getDNSResolveLatency(ctx context.Context, host string) (*trace.LatencyInfo, error) {
+	klog.V(4).Infof("Check BEGIN: %v", host)
+	defer klog.V(4).Infof("Check END  : %v", host)
+	ctx, cancel := context.WithTimeout(ctx, c.settings.timeout)
+	defer cancel()
+
+	latencyInfo := &trace.LatencyInfo{DNSStart: time.Now()}
+	// ip, ip4 or ip6 for the network of the check
+	_, err := net.DefaultResolver.LookupIP(ctx, strings.Replace(c.settings.network, "tcp", "ip", 1), host)
+	latencyInfo.DNS = time.Since(latencyInfo.DNSStart)
+	c.metrics.Update(host+":", latencyInfo, err)
 	return latencyInfo, err
 }
 
-// isDNSError returns true if the cause of the net operation error is a DNS error
+// isDNSCheck returns the host name if the check only resolves it. The target endpoint of a DNS check has no port.
+func isDNSCheck(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) (string, bool) {
+	host, port, err := net.SplitHostPort(check.Spec.TargetEndpoint)
+	return host, err == nil && len(host) > 0 && len(port) == 0
+}
+
+// isDNSError returns true if the error, or the cause of the net operation error, is a DNS error
 func isDNSError(err error) bool {
 	if opErr, ok := err.(*net.OpError); ok {
-		if _, ok := opErr.Err.(*net.DNSError); ok {
-			return true
-		}
+		err = opErr.Err
 	}
-	return false
+	_, ok := err.(*net.DNSError)
+	return ok
 }
 
 // manageStatusLogs returns status update functions that updates the PodNetworkConnectivityCheck.Status's
@@ -209,11 +333,14 @@ func manageStatusLogs(check *operatorcontrolplanev1alpha1.PodNetworkConnectivity
 			Latency: metav1.Duration{Duration: latency.DNS},
 		}))
 		overallStart = latency.DNSStart
+		if _, ok := isDNSCheck(check); ok {
+			return statusUpdates, overallStart
+		}
 	}
 	if overallStart.IsZero() {
 		overallStart = latency.ConnectStart
 	}
-	if checkErr != nil {
+	if checkErr != nil && !isHTTPGetError(checkErr) {
 		klog.V(2).Infof("%7s | %-15s | %10s | Failed to establish a TCP connection to %s: %v", "Failure", "TCPConnectError", latency.Connect, check.Spec.TargetEndpoint, checkErr)
 		return append(statusUpdates, v1alpha1helpers.AddFailureLogEntry(operatorcontrolplanev1alpha1.LogEntry{
 			Start:   metav1.NewTime(latency.ConnectStart),
@@ -224,17 +351,68 @@ func manageStatusLogs(check *operatorcontrolplanev1alpha1.PodNetworkConnectivity
 		})), overallStart
 	}
 	klog.V(2).Infof("%7s | %-15s | %10s | TCP connection to %v succeeded", "Success", "TCPConnect", latency.Connect, check.Spec.TargetEndpoint)
-	return append(statusUpdates, v1alpha1helpers.AddSuccessLogEntry(operatorcontrolplanev1alpha1.LogEntry{
+	statusUpdates = append(statusUpdates, v1alpha1helpers.AddSuccessLogEntry(operatorcontrolplanev1alpha1.LogEntry{
 		Start:   metav1.NewTime(latency.ConnectStart),
 		Success: true,
 		Reason:  operatorcontrolplanev1alpha1.LogEntryReasonTCPConnect,
 		Message: fmt.Sprintf("%s: tcp connection to %s succeeded", description, check.Spec.TargetEndpoint),
 		Latency: metav1.Duration{Duration: latency.Connect},
+	}))
+	get := httpGetForCheck(check)
+	if get == nil || latency.HTTPGetStart.IsZero() {
+		return statusUpdates, overallStart
+	}
+	url := get.url(check.Spec.TargetEndpoint)
+	if checkErr != nil {
+		klog.V(2).Infof("%7s | %-15s | %10s | HTTP GET %s failed: %v", "Failure", "HTTPGetError", latency.HTTPGet, url, checkErr)
+		return append(statusUpdates, v1alpha1helpers.AddFailureLogEntry(operatorcontrolplanev1alpha1.LogEntry{
+			Start:   metav1.NewTime(latency.HTTPGetStart),
+			Success: false,
+			Reason:  logEntryReasonHTTPGetError,
+			Message: fmt.Sprintf("%s: http get %s failed: %v", description, url, checkErr),
+			Latency: metav1.Duration{Duration: latency.HTTPGet},
+		})), overallStart
+	}
+	klog.V(2).Infof("%7s | %-15s | %10s | HTTP GET %s succeeded", "Success", "HTTPGet", latency.HTTPGet, url)
+	return append(statusUpdates, v1alpha1helpers.AddSuccessLogEntry(operatorcontrolplanev1alpha1.LogEntry{
+		Start:   metav1.NewTime(latency.HTTPGetStart),
+		Success: true,
+		Reason:  logEntryReasonHTTPGet,
+		Message: fmt.Sprintf("%s: http get %s succeeded", description, url),
+		Latency: metav1.Duration{Duration: latency.HTTPGet},
 	})), overallStart
 }
 
 // manageStatusOutage returns a status update function that manages the
 // PodNetworkConnectivityCheck.Status.Outage entries based on Successes/Failures log entries.
+// manageStatusSuccessLogInterval drops the successes that follow the previous success of the same kind within the
+// interval, unless they may end an outage. The metrics have the result of every check, while the status of a
+// reachable target only changes once per interval.
+func manageStatusSuccessLogInterval(interval time.Duration) v1alpha1helpers.UpdateStatusFunc {
+	return func(status *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckStatus) {
+		if len(status.Outages) > 0 && status.Outages[0].End.IsZero() {
+			return
+		}
+		var latestFailure time.Time
+		if len(status.Failures) > 0 {
+			latestFailure = status.Failures[0].Start.Time
+		}
+		// oldest first, so that the successes that were kept before are kept again
+		kept := map[string]time.Time{}
+		var successes []operatorcontrolplanev1alpha1.LogEntry
+		for i := len(status.Successes) - 1; i >= 0; i-- {
+			success := status.Successes[i]
+			previous, ok := kept[success.Reason]
+			if ok && success.Start.Sub(previous) < interval && previous.After(latestFailure) {
+				continue
+			}
+			kept[success.Reason] = success.Start.Time
+			successes = append([]operatorcontrolplanev1alpha1.LogEntry{success}, successes...)
+		}
+		status.Successes = successes
+	}
+}
+
 func manageStatusOutage(recorder Recorder) v1alpha1helpers.UpdateStatusFunc {
 	return func(status *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckStatus) {
 		// This func is kept simple by assuming that only one log entry has been
@@ -290,8 +468,8 @@ func manageStatusOutage(recorder Recorder) v1alpha1helpers.UpdateStatusFunc {
 		default:
 			// no outage in progress
 		}
-		if len(status.Outages) > 20 {
-			status.Outages = status.Outages[:20]
+		if len(status.Outages) > maxOutages {
+			status.Outages = status.Outages[:maxOutages]
 		}
 	}
 }
@@ -310,6 +488,13 @@ func manageStatusConditions(status *operatorcontrolplanev1alpha1.PodNetworkConne
 		}
 		reachableCondition.Status = metav1.ConditionTrue
 		reachableCondition.Reason = "TCPConnectSuccess"
+		switch latestSuccessLogEntry.Reason {
+		case operatorcontrolplanev1alpha1.LogEntryReasonDNSResolve:
+			// only DNS checks end with a resolved host name
+			reachableCondition.Reason = "DNSResolveSuccess"
+		case logEntryReasonHTTPGet:
+			reachableCondition.Reason = "HTTPGetSuccess"
+		}
 		reachableCondition.Message = latestSuccessLogEntry.Message
 	} else {
 		var latestFailureLogEntry operatorcontrolplanev1alpha1.LogEntry
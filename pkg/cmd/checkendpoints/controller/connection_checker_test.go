@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/mergepatch"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
 )
 
@@ -140,6 +141,165 @@ func TestManageStatusLogs(t *testing.T) {
 	}
 }
 
+func TestManageStatusLogsDNSCheck(t *testing.T) {
+	check := &v1alpha1.PodNetworkConnectivityCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-to-target-endpoint"},
+		Spec:       v1alpha1.PodNetworkConnectivityCheckSpec{TargetEndpoint: "host:"},
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected *v1alpha1.PodNetworkConnectivityCheckStatus
+	}{
+		{
+			name:     "DNSResolve",
+			expected: podNetworkConnectivityCheckStatus(withSuccessEntry(dnsResolveEntry(0))),
+		},
+		{
+			name: "DNSError",
+			err:  &net.DNSError{Err: "test error", Name: "host"},
+			expected: podNetworkConnectivityCheckStatus(withFailureEntry(dnsErrorEntry(0,
+				withLogMessage("target-endpoint: failure looking up host host: lookup host: test error"),
+			))),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := podNetworkConnectivityCheckStatus()
+			updateStatusFuncs, timestamp := manageStatusLogs(check, tc.err, &trace.LatencyInfo{DNSStart: testTime(0), DNS: time.Millisecond})
+			for _, updateStatusFunc := range updateStatusFuncs {
+				updateStatusFunc(status)
+			}
+			assert.Equal(t, tc.expected, status)
+			assert.Equal(t, testTime(0), timestamp)
+
+			manageStatusOutage(events.NewInMemoryRecorder(t.Name()))(status)
+			manageStatusConditions(status)
+			if tc.err == nil {
+				assert.Equal(t, "DNSResolveSuccess", status.Conditions[0].Reason)
+			} else {
+				assert.Equal(t, v1alpha1.LogEntryReasonDNSError, status.Conditions[0].Reason)
+			}
+		})
+	}
+}
+
+func TestSettingsForCheck(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		expected    checkSettings
+	}{
+		{
+			name:     "defaults",
+			expected: checkSettings{period: checkPeriod, timeout: checkTimeout, network: "tcp", retention: retention{maxOutages: maxOutages, maxLogEntries: maxLogEntries}},
+		},
+		{
+			name:        "interval and timeout",
+			annotations: map[string]string{v1alpha1helpers.IntervalAnnotation: "30s", v1alpha1helpers.TimeoutAnnotation: "5s"},
+			expected:    checkSettings{period: 30 * time.Second, timeout: 5 * time.Second, network: "tcp", retention: retention{maxOutages: maxOutages, maxLogEntries: maxLogEntries}},
+		},
+		{
+			name:        "invalid",
+			annotations: map[string]string{v1alpha1helpers.IntervalAnnotation: "often", v1alpha1helpers.TimeoutAnnotation: "-1s", v1alpha1helpers.MaxOutagesAnnotation: "none"},
+			expected:    checkSettings{period: checkPeriod, timeout: checkTimeout, network: "tcp", retention: retention{maxOutages: maxOutages, maxLogEntries: maxLogEntries}},
+		},
+		{
+			name: "retention",
+			annotations: map[string]string{
+				v1alpha1helpers.SuccessLogIntervalAnnotation:  "5m",
+				v1alpha1helpers.MaxOutagesAnnotation:          "5",
+				v1alpha1helpers.MaxLogEntriesAnnotation:       "3",
+				v1alpha1helpers.MaxAgeAnnotation:              "168h",
+				v1alpha1helpers.OutageCompactionGapAnnotation: "1m",
+			},
+			expected: checkSettings{period: checkPeriod, timeout: checkTimeout, successLogInterval: 5 * time.Minute, network: "tcp", retention: retention{maxOutages: 5, maxLogEntries: 3, maxAge: 168 * time.Hour, compactionGap: time.Minute}},
+		},
+		{
+			name:     "ip family",
+			labels:   map[string]string{v1alpha1helpers.IPFamilyLabel: "IPv6"},
+			expected: checkSettings{period: checkPeriod, timeout: checkTimeout, network: "tcp6", retention: retention{maxOutages: maxOutages, maxLogEntries: maxLogEntries}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &v1alpha1.PodNetworkConnectivityCheck{ObjectMeta: metav1.ObjectMeta{Name: "check", Annotations: tc.annotations, Labels: tc.labels}}
+			assert.Equal(t, tc.expected, settingsForCheck(check))
+		})
+	}
+}
+
+func TestManageStatusSuccessLogInterval(t *testing.T) {
+	testCases := []struct {
+		name     string
+		initial  *v1alpha1.PodNetworkConnectivityCheckStatus
+		expected *v1alpha1.PodNetworkConnectivityCheckStatus
+	}{
+		{
+			name: "within interval",
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(dnsResolveEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+				withSuccessEntry(dnsResolveEntry(1)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(1)),
+				withSuccessEntry(dnsResolveEntry(1)),
+			),
+		},
+		{
+			name: "after interval",
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(31)),
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(31)),
+				withSuccessEntry(tcpConnectEntry(1)),
+			),
+		},
+		{
+			name: "after failure",
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+				withFailureEntry(tcpConnectErrorEntry(11)),
+				withOutageEntry(11, withEnd(12)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+				withFailureEntry(tcpConnectErrorEntry(11)),
+				withOutageEntry(11, withEnd(12)),
+			),
+		},
+		{
+			name: "ongoing outage",
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+				withOutageEntry(11),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(12)),
+				withSuccessEntry(tcpConnectEntry(1)),
+				withOutageEntry(11),
+			),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := tc.initial.DeepCopy()
+			manageStatusSuccessLogInterval(30 * time.Second)(status)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
 func TestManageStatusOutage(t *testing.T) {
 	//testOpErr := &net.OpError{Op: "connect", Net: "tcp", Err: errors.New("test error")}
 	testCases := []struct {
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
+)
+
+const (
+	// logEntryReasonHTTPGet is the reason of a success of the HTTP GET request of a check
+	logEntryReasonHTTPGet = "HTTPGet"
+	// logEntryReasonHTTPGetError is the reason of a failure of the HTTP GET request of a check whose TCP connection
+	// succeeded
+	logEntryReasonHTTPGetError = "HTTPGetError"
+)
+
+// httpGet is the HTTP GET request that follows the TCP connection to the target of a check.
+type httpGet struct {
+	path string
+	// scheme is http or https
+	scheme string
+	// expectedStatusCodes are the status codes of a successful response, any status code between 200 and 399 if empty
+	expectedStatusCodes []int
+}
+
+// httpGetForCheck returns the HTTP GET request in the annotations of the check, nil if the check has no path.
+func httpGetForCheck(check *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck) *httpGet {
+	path, ok := check.Annotations[v1alpha1helpers.HTTPGetPathAnnotation]
+	if !ok || !strings.HasPrefix(path, "/") {
+		if ok {
+			klog.Warningf("Ignoring invalid %s %q of %s", v1alpha1helpers.HTTPGetPathAnnotation, path, check.Name)
+		}
+		return nil
+	}
+	get := &httpGet{path: path, scheme: "https"}
+	switch scheme := check.Annotations[v1alpha1helpers.HTTPGetSchemeAnnotation]; scheme {
+	case "", "HTTPS":
+	case "HTTP":
+		get.scheme = "http"
+	default:
+		klog.Warningf("Ignoring invalid %s %q of %s", v1alpha1helpers.HTTPGetSchemeAnnotation, scheme, check.Name)
+	}
+	if value, ok := check.Annotations[v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation]; ok {
+		for _, s := range strings.Split(value, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || code < 100 || code > 599 {
+				klog.Warningf("Ignoring invalid %s %q of %s", v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation, value, check.Name)
+				get.expectedStatusCodes = nil
+				break
+			}
+			get.expectedStatusCodes = append(get.expectedStatusCodes, code)
+		}
+	}
+	return get
+}
+
+// url returns the URL of the request to the address.
+func (g *httpGet) url(address string) string {
+	return g.scheme + "://" + address + g.path
+}
+
+// expects returns true if the status code is one of a successful response.
+func (g *httpGet) expects(statusCode int) bool {
+	if len(g.expectedStatusCodes) == 0 {
+		return statusCode >= 200 && statusCode < 400
+	}
+	for _, code := range g.expectedStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// httpGetError is a failure of the HTTP GET request of a check whose TCP connection succeeded.
+type httpGetError struct {
+	err error
+}
+
+func (e *httpGetError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpGetError) Unwrap() error {
+	return e.err
+}
+
+// isHTTPGetError returns true if the TCP connection of the check succeeded but its HTTP GET request failed.
+func isHTTPGetError(err error) bool {
+	var httpErr *httpGetError
+	return errors.As(err, &httpErr)
+}
+
+// getHTTPGetLatency sends the HTTP GET request of the check over the TCP connection, after a TLS handshake for HTTPS,
+// and returns the certificates of the endpoint. Its errors are httpGetErrors.
+func (c *connectionChecker) getHTTPGetLatency(tcpConn net.Conn, address string, latencyInfo *trace.LatencyInfo) ([]*x509.Certificate, error) {
+	latencyInfo.HTTPGetStart = time.Now()
+	defer func() { latencyInfo.HTTPGet = time.Since(latencyInfo.HTTPGetStart) }()
+	_ = tcpConn.SetDeadline(latencyInfo.HTTPGetStart.Add(c.settings.timeout))
+
+	conn := tcpConn
+	var peerCerts []*x509.Certificate
+	if c.settings.httpGet.scheme == "https" {
+		host, _, _ := net.SplitHostPort(address)
+		tlsConn := tls.Client(tcpConn, &tls.Config{Certificates: c.clientCertGetter(), ServerName: host, InsecureSkipVerify: true})
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, &httpGetError{fmt.Errorf("tls handshake failed: %w", err)}
+		}
+		peerCerts = tlsConn.ConnectionState().PeerCertificates
+		conn = tlsConn
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.settings.httpGet.url(address), nil)
+	if err != nil {
+		return peerCerts, &httpGetError{err}
+	}
+	req.Header.Set("User-Agent", "check-endpoints")
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		return peerCerts, &httpGetError{err}
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return peerCerts, &httpGetError{err}
+	}
+	_ = resp.Body.Close()
+	latencyInfo.HTTPStatusCode = resp.StatusCode
+	if !c.settings.httpGet.expects(resp.StatusCode) {
+		return peerCerts, &httpGetError{fmt.Errorf("unexpected status code %d", resp.StatusCode)}
+	}
+	return peerCerts, nil
+}
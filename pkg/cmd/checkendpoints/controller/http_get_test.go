@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
+)
+
+func TestHTTPGetForCheck(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    *httpGet
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:        "defaults",
+			annotations: map[string]string{v1alpha1helpers.HTTPGetPathAnnotation: "/readyz"},
+			expected:    &httpGet{path: "/readyz", scheme: "https"},
+		},
+		{
+			name: "scheme and status codes",
+			annotations: map[string]string{
+				v1alpha1helpers.HTTPGetPathAnnotation:                "/healthz",
+				v1alpha1helpers.HTTPGetSchemeAnnotation:              "HTTP",
+				v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation: "200, 204",
+			},
+			expected: &httpGet{path: "/healthz", scheme: "http", expectedStatusCodes: []int{200, 204}},
+		},
+		{
+			name: "invalid",
+			annotations: map[string]string{
+				v1alpha1helpers.HTTPGetPathAnnotation:                "/healthz",
+				v1alpha1helpers.HTTPGetSchemeAnnotation:              "TCP",
+				v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation: "200,ok",
+			},
+			expected: &httpGet{path: "/healthz", scheme: "https"},
+		},
+		{
+			name:        "relative path",
+			annotations: map[string]string{v1alpha1helpers.HTTPGetPathAnnotation: "healthz"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &v1alpha1.PodNetworkConnectivityCheck{ObjectMeta: metav1.ObjectMeta{Name: "check", Annotations: tc.annotations}}
+			assert.Equal(t, tc.expected, httpGetForCheck(check))
+		})
+	}
+}
+
+func TestGetHTTPGetLatency(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/readyz":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	httpsServer := httptest.NewTLSServer(handler)
+	defer httpsServer.Close()
+
+	testCases := []struct {
+		name          string
+		server        *httptest.Server
+		httpGet       *httpGet
+		expectedError string
+		expectedCerts bool
+	}{
+		{
+			name:    "http",
+			server:  httpServer,
+			httpGet: &httpGet{path: "/readyz", scheme: "http"},
+		},
+		{
+			name:          "https",
+			server:        httpsServer,
+			httpGet:       &httpGet{path: "/readyz", scheme: "https"},
+			expectedCerts: true,
+		},
+		{
+			name:          "unexpected status code",
+			server:        httpsServer,
+			httpGet:       &httpGet{path: "/livez", scheme: "https"},
+			expectedError: "unexpected status code 500",
+			expectedCerts: true,
+		},
+		{
+			name:    "expected status code",
+			server:  httpServer,
+			httpGet: &httpGet{path: "/livez", scheme: "http", expectedStatusCodes: []int{500}},
+		},
+		{
+			name:          "https to http",
+			server:        httpServer,
+			httpGet:       &httpGet{path: "/readyz", scheme: "https"},
+			expectedError: "tls handshake failed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &connectionChecker{
+				settings:         checkSettings{timeout: 5 * time.Second, network: "tcp", httpGet: tc.httpGet},
+				metrics:          NewMetricsContext("test", tc.name),
+				clientCertGetter: func() []tls.Certificate { return nil },
+			}
+			address := strings.TrimPrefix(strings.TrimPrefix(tc.server.URL, "http://"), "https://")
+			latencyInfo, peerCerts, err := c.getTCPConnectLatency(context.Background(), address)
+			if len(tc.expectedError) > 0 {
+				if !isHTTPGetError(err) || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("expected an HTTP GET error %q, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expectedCerts, len(peerCerts) > 0)
+			assert.False(t, latencyInfo.HTTPGetStart.IsZero())
+		})
+	}
+}
+
+func TestManageStatusLogsHTTPGet(t *testing.T) {
+	check := &v1alpha1.PodNetworkConnectivityCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-to-target-endpoint", Annotations: map[string]string{v1alpha1helpers.HTTPGetPathAnnotation: "/readyz"}},
+		Spec:       v1alpha1.PodNetworkConnectivityCheckSpec{TargetEndpoint: "host:port"},
+	}
+	latencyInfo := &trace.LatencyInfo{ConnectStart: testTime(0), Connect: time.Millisecond, HTTPGetStart: testTime(1), HTTPGet: time.Millisecond}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected *v1alpha1.PodNetworkConnectivityCheckStatus
+		reason   string
+	}{
+		{
+			name: "HTTPGet",
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(logEntry(true, 1, logEntryReasonHTTPGet, "target-endpoint: http get https://host:port/readyz succeeded")),
+				withSuccessEntry(tcpConnectEntry(0)),
+			),
+			reason: "HTTPGetSuccess",
+		},
+		{
+			name: "HTTPGetError",
+			err:  &httpGetError{fmt.Errorf("unexpected status code 500")},
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(0)),
+				withFailureEntry(logEntry(false, 1, logEntryReasonHTTPGetError, "target-endpoint: http get https://host:port/readyz failed: unexpected status code 500")),
+			),
+			reason: logEntryReasonHTTPGetError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := podNetworkConnectivityCheckStatus()
+			updateStatusFuncs, timestamp := manageStatusLogs(check, tc.err, latencyInfo)
+			for _, updateStatusFunc := range updateStatusFuncs {
+				updateStatusFunc(status)
+			}
+			assert.Equal(t, tc.expected, status)
+			assert.Equal(t, testTime(0), timestamp)
+
+			manageStatusOutage(events.NewInMemoryRecorder(t.Name()))(status)
+			manageStatusConditions(status)
+			assert.Equal(t, tc.reason, status.Conditions[0].Reason)
+			assert.Equal(t, tc.err != nil, len(status.Outages) == 1)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"crypto/x509"
+	"net"
 	"sync"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
@@ -14,8 +16,20 @@ var (
 	endpointCheckCounter   *metrics.CounterVec
 	tcpConnectLatencyGauge *metrics.GaugeVec
 	dnsResolveLatencyGauge *metrics.GaugeVec
+
+	tcpConnectLatencyHistogram *metrics.HistogramVec
+	dnsResolveLatencyHistogram *metrics.HistogramVec
+	httpGetLatencyHistogram    *metrics.HistogramVec
+
+	peerCertificateExpiryGauge *metrics.GaugeVec
+
+	reachableGauge          *metrics.GaugeVec
+	lastCheckTimestampGauge *metrics.GaugeVec
 )
 
+// latencyBuckets range from sub-millisecond connections within the cluster network to the 10s timeout of a check.
+var latencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
 // RegisterMetrics in the global registry
 func RegisterMetrics() {
 	registerMetrics.Do(func() {
@@ -33,15 +47,55 @@ func RegisterMetrics() {
 			Name: "pod_network_connectivity_check_dns_resolve_latency_gauge",
 			Help: "Report latency of DNS resolve of target endpoint over time.",
 		}, []string{"component", "checkName", "targetEndpoint"})
+
+		tcpConnectLatencyHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+			Name:    "pod_network_connectivity_check_tcp_connect_latency_seconds",
+			Help:    "Latency distribution of successful TCP connects to target endpoint.",
+			Buckets: latencyBuckets,
+		}, []string{"component", "checkName", "targetEndpoint"})
+
+		dnsResolveLatencyHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+			Name:    "pod_network_connectivity_check_dns_resolve_latency_seconds",
+			Help:    "Latency distribution of successful DNS resolves of target endpoint.",
+			Buckets: latencyBuckets,
+		}, []string{"component", "checkName", "targetEndpoint"})
+
+		httpGetLatencyHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+			Name:    "pod_network_connectivity_check_http_get_latency_seconds",
+			Help:    "Latency distribution of successful HTTP GET requests to target endpoint, including the TLS handshake.",
+			Buckets: latencyBuckets,
+		}, []string{"component", "checkName", "targetEndpoint"})
+
+		peerCertificateExpiryGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+			Name: "pod_network_connectivity_check_tls_peer_certificate_expiry_timestamp_seconds",
+			Help: "Report when the first certificate presented by a TLS target endpoint expires, in seconds since the epoch.",
+		}, []string{"component", "checkName", "targetEndpoint"})
+
+		reachableGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+			Name: "pod_network_connectivity_check_reachable",
+			Help: "Report whether the latest check of the target endpoint succeeded (1) or failed (0).",
+		}, []string{"component", "checkName", "targetEndpoint"})
+
+		lastCheckTimestampGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+			Name: "pod_network_connectivity_check_last_check_timestamp_seconds",
+			Help: "Report when the target endpoint was checked last, in seconds since the epoch.",
+		}, []string{"component", "checkName", "targetEndpoint"})
 		legacyregistry.MustRegister(endpointCheckCounter)
 		legacyregistry.MustRegister(tcpConnectLatencyGauge)
 		legacyregistry.MustRegister(dnsResolveLatencyGauge)
+		legacyregistry.MustRegister(tcpConnectLatencyHistogram)
+		legacyregistry.MustRegister(dnsResolveLatencyHistogram)
+		legacyregistry.MustRegister(httpGetLatencyHistogram)
+		legacyregistry.MustRegister(peerCertificateExpiryGauge)
+		legacyregistry.MustRegister(reachableGauge)
+		legacyregistry.MustRegister(lastCheckTimestampGauge)
 	})
 }
 
 // MetricsContext updates connectivity check metrics
 type MetricsContext interface {
 	Update(targetEndpoint string, latency *trace.LatencyInfo, checkErr error)
+	UpdatePeerCertificates(targetEndpoint string, certs []*x509.Certificate)
 }
 
 type metricsContext struct {
@@ -61,12 +115,36 @@ func NewMetricsContext(componentName, checkName string) *metricsContext {
 // Update the pod network connectivity check metrics for the given check results.
 func (m *metricsContext) Update(targetEndpoint string, latency *trace.LatencyInfo, checkErr error) {
 	endpointCheckCounter.With(m.getCounterMetricLabels(targetEndpoint, latency, checkErr)).Inc()
+	reachable := 0.0
+	if checkErr == nil {
+		reachable = 1
+	}
+	reachableGauge.With(m.getMetricLabels(targetEndpoint)).Set(reachable)
+	lastCheckTimestampGauge.With(m.getMetricLabels(targetEndpoint)).SetToCurrentTime()
 	if latency.Connect > 0 {
 		tcpConnectLatencyGauge.With(m.getMetricLabels(targetEndpoint)).Set(float64(latency.Connect.Nanoseconds()))
 	}
 	if latency.DNS > 0 {
 		dnsResolveLatencyGauge.With(m.getMetricLabels(targetEndpoint)).Set(float64(latency.DNS.Nanoseconds()))
 	}
+	// failures are counted above, their latency is mostly the timeout and would hide a gradual degradation
+	if latency.DNS > 0 && !isDNSError(checkErr) {
+		dnsResolveLatencyHistogram.With(m.getMetricLabels(targetEndpoint)).Observe(latency.DNS.Seconds())
+	}
+	if latency.Connect > 0 && (checkErr == nil || isHTTPGetError(checkErr)) {
+		tcpConnectLatencyHistogram.With(m.getMetricLabels(targetEndpoint)).Observe(latency.Connect.Seconds())
+	}
+	if latency.HTTPGet > 0 && checkErr == nil {
+		httpGetLatencyHistogram.With(m.getMetricLabels(targetEndpoint)).Observe(latency.HTTPGet.Seconds())
+	}
+}
+
+// UpdatePeerCertificates updates the expiry of the certificates presented by a TLS target endpoint.
+func (m *metricsContext) UpdatePeerCertificates(targetEndpoint string, certs []*x509.Certificate) {
+	if len(certs) == 0 {
+		return
+	}
+	peerCertificateExpiryGauge.With(m.getMetricLabels(targetEndpoint)).Set(float64(earliestExpiry(certs).Unix()))
 }
 
 func (m *metricsContext) getCounterMetricLabels(targetEndpoint string, latency *trace.LatencyInfo, checkErr error) map[string]string {
@@ -80,7 +158,12 @@ func (m *metricsContext) getCounterMetricLabels(targetEndpoint string, latency *
 	if latency.DNS != 0 {
 		labels["dnsResolve"] = "success"
 	}
-	if checkErr != nil {
+	if _, port, _ := net.SplitHostPort(targetEndpoint); len(port) == 0 {
+		// DNS checks do not connect
+		return labels
+	}
+	// the TCP connection of a failed HTTP GET request succeeded, the reachable gauge has the result of the request
+	if checkErr != nil && !isHTTPGetError(checkErr) {
 		labels["tcpConnect"] = "failure"
 		return labels
 	}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/trace"
+)
+
+func TestLatencyHistograms(t *testing.T) {
+	m := NewMetricsContext("openshift-kube-apiserver", "test-latency-histograms")
+	m.Update("etcd:2379", &trace.LatencyInfo{DNS: 2 * time.Millisecond, Connect: 3 * time.Millisecond}, nil)
+	m.Update("etcd:2379", &trace.LatencyInfo{Connect: 5 * time.Millisecond, HTTPGet: 20 * time.Millisecond}, nil)
+	// the connect of a failed HTTP GET request is observed, the request is not
+	m.Update("etcd:2379", &trace.LatencyInfo{Connect: 4 * time.Millisecond, HTTPGet: 10 * time.Second}, &httpGetError{err: fmt.Errorf("status 500")})
+	// a failed connect is not observed
+	m.Update("etcd:2379", &trace.LatencyInfo{Connect: 10 * time.Second}, fmt.Errorf("i/o timeout"))
+	// neither is a failed lookup
+	m.Update("etcd:2379", &trace.LatencyInfo{DNS: 5 * time.Second}, &net.OpError{Err: &net.DNSError{}})
+
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]uint64{}
+	sums := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "checkName" && label.GetValue() == "test-latency-histograms" && metric.GetHistogram() != nil {
+					counts[family.GetName()] = metric.GetHistogram().GetSampleCount()
+					sums[family.GetName()] = metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+
+	if count := counts["pod_network_connectivity_check_tcp_connect_latency_seconds"]; count != 3 {
+		t.Errorf("expected 3 tcp connect samples, got %d", count)
+	}
+	if sum := sums["pod_network_connectivity_check_tcp_connect_latency_seconds"]; sum < 0.0119 || sum > 0.0121 {
+		t.Errorf("expected 12ms of tcp connects, got %vs", sum)
+	}
+	if count, sum := counts["pod_network_connectivity_check_http_get_latency_seconds"], sums["pod_network_connectivity_check_http_get_latency_seconds"]; count != 1 || sum < 0.0199 || sum > 0.0201 {
+		t.Errorf("expected 1 http get sample of 20ms, got %d of %vs", count, sum)
+	}
+	if count := counts["pod_network_connectivity_check_dns_resolve_latency_seconds"]; count != 1 {
+		t.Errorf("expected 1 dns resolve sample, got %d", count)
+	}
+}
+
+func TestReachableGauge(t *testing.T) {
+	m := NewMetricsContext("openshift-kube-apiserver", "test-reachable-gauge")
+	gauge := func() float64 {
+		families, err := legacyregistry.DefaultGatherer.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, family := range families {
+			if family.GetName() != "pod_network_connectivity_check_reachable" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "checkName" && label.GetValue() == "test-reachable-gauge" {
+						return metric.GetGauge().GetValue()
+					}
+				}
+			}
+		}
+		t.Fatal("pod_network_connectivity_check_reachable not found")
+		return 0
+	}
+
+	m.Update("etcd:2379", &trace.LatencyInfo{Connect: time.Millisecond}, nil)
+	if value := gauge(); value != 1 {
+		t.Errorf("expected reachable, got %v", value)
+	}
+	m.Update("etcd:2379", &trace.LatencyInfo{Connect: 10 * time.Second}, fmt.Errorf("i/o timeout"))
+	if value := gauge(); value != 0 {
+		t.Errorf("expected unreachable, got %v", value)
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+)
+
+// TLSCertificateValid is the condition of a PodNetworkConnectivityCheck of a TLS endpoint that tells whether the
+// certificates presented by the endpoint are valid and not about to expire.
+const TLSCertificateValid operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckConditionType = "TLSCertificateValid"
+
+// manageStatusPeerCertificates returns a status update function that sets the TLSCertificateValid condition from the
+// certificate chain presented by the endpoint. A warning is recorded when the condition turns false.
+func manageStatusPeerCertificates(recorder Recorder, description string, certs []*x509.Certificate, now time.Time) v1alpha1helpers.UpdateStatusFunc {
+	return func(status *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckStatus) {
+		condition := newPeerCertificatesCondition(certs, now)
+		for _, existing := range status.Conditions {
+			if existing.Type == TLSCertificateValid && existing.Status != condition.Status && condition.Status == metav1.ConditionFalse {
+				recorder.Warningf("TLSCertificateInvalid", "%s: %s", description, condition.Message)
+			}
+		}
+		v1alpha1helpers.SetPodNetworkConnectivityCheckCondition(&status.Conditions, condition)
+	}
+}
+
+// newPeerCertificatesCondition checks the validity period of every certificate of the chain, the serving certificate
+// first. A certificate expires soon when less than a fifth of its lifetime remains, certificates that are rotated
+// in time are renewed well before. The chain is not verified, the checks do not know the CA of every endpoint.
+func newPeerCertificatesCondition(certs []*x509.Certificate, now time.Time) operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition {
+	condition := operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition{
+		Type:   TLSCertificateValid,
+		Status: metav1.ConditionTrue,
+		Reason: "CertificateValid",
+	}
+	for i, cert := range certs {
+		name := "serving certificate"
+		if i > 0 {
+			name = "issuer certificate"
+		}
+		lifetime := cert.NotAfter.Sub(cert.NotBefore)
+		switch {
+		case now.Before(cert.NotBefore):
+			condition.Status, condition.Reason = metav1.ConditionFalse, "CertificateNotYetValid"
+			condition.Message = fmt.Sprintf("%s %q is not valid before %s", name, cert.Subject.CommonName, cert.NotBefore.UTC().Format(time.RFC3339))
+		case now.After(cert.NotAfter):
+			condition.Status, condition.Reason = metav1.ConditionFalse, "CertificateExpired"
+			condition.Message = fmt.Sprintf("%s %q expired at %s", name, cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+		case cert.NotAfter.Sub(now) < lifetime/5:
+			condition.Status, condition.Reason = metav1.ConditionFalse, "CertificateExpiringSoon"
+			condition.Message = fmt.Sprintf("%s %q expires at %s", name, cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+		default:
+			continue
+		}
+		return condition
+	}
+	if len(certs) > 0 {
+		condition.Message = fmt.Sprintf("serving certificate %q expires at %s", certs[0].Subject.CommonName, certs[0].NotAfter.UTC().Format(time.RFC3339))
+	}
+	return condition
+}
+
+// earliestExpiry returns when the first certificate of the chain expires.
+func earliestExpiry(certs []*x509.Certificate) time.Time {
+	var earliest time.Time
+	for _, cert := range certs {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest
+}
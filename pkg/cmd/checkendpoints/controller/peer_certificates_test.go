@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewPeerCertificatesCondition(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	cert := func(name string, notBefore, notAfter time.Time) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: name}, NotBefore: notBefore, NotAfter: notAfter}
+	}
+	serving := cert("api.example.com", now.AddDate(0, 0, -10), now.AddDate(0, 0, 20))
+	ca := cert("root-ca", now.AddDate(-1, 0, 0), now.AddDate(9, 0, 0))
+
+	tests := []struct {
+		name            string
+		certs           []*x509.Certificate
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "valid",
+			certs:           []*x509.Certificate{serving, ca},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "CertificateValid",
+			expectedMessage: `serving certificate "api.example.com" expires at 2021-06-21T00:00:00Z`,
+		},
+		{
+			name:            "expiring soon",
+			certs:           []*x509.Certificate{cert("api.example.com", now.AddDate(0, 0, -25), now.AddDate(0, 0, 5)), ca},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CertificateExpiringSoon",
+			expectedMessage: `serving certificate "api.example.com" expires at 2021-06-06T00:00:00Z`,
+		},
+		{
+			name:            "expired",
+			certs:           []*x509.Certificate{cert("api.example.com", now.AddDate(0, 0, -30), now.Add(-time.Hour)), ca},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CertificateExpired",
+			expectedMessage: `serving certificate "api.example.com" expired at 2021-05-31T23:00:00Z`,
+		},
+		{
+			name:            "not yet valid",
+			certs:           []*x509.Certificate{cert("api.example.com", now.Add(time.Hour), now.AddDate(0, 0, 30))},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CertificateNotYetValid",
+			expectedMessage: `serving certificate "api.example.com" is not valid before 2021-06-01T01:00:00Z`,
+		},
+		{
+			name:            "issuer expired",
+			certs:           []*x509.Certificate{serving, cert("intermediate-ca", now.AddDate(-1, 0, 0), now.AddDate(0, 0, -1))},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CertificateExpired",
+			expectedMessage: `issuer certificate "intermediate-ca" expired at 2021-05-31T00:00:00Z`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition := newPeerCertificatesCondition(test.certs, now)
+			if condition.Type != TLSCertificateValid {
+				t.Errorf("expected condition %s, got %s", TLSCertificateValid, condition.Type)
+			}
+			if condition.Status != test.expectedStatus {
+				t.Errorf("expected status %s, got %s", test.expectedStatus, condition.Status)
+			}
+			if condition.Reason != test.expectedReason {
+				t.Errorf("expected reason %s, got %s", test.expectedReason, condition.Reason)
+			}
+			if condition.Message != test.expectedMessage {
+				t.Errorf("expected message %q, got %q", test.expectedMessage, condition.Message)
+			}
+		})
+	}
+}
+
+func TestManageStatusPeerCertificates(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := []*x509.Certificate{{Subject: pkix.Name{CommonName: "api.example.com"}, NotBefore: now.AddDate(0, 0, -30), NotAfter: now.Add(-time.Hour)}}
+
+	status := &operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckStatus{
+		Conditions: []operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition{{Type: TLSCertificateValid, Status: metav1.ConditionTrue}},
+	}
+	recorder := events.NewInMemoryRecorder(t.Name())
+	manageStatusPeerCertificates(recorder, "load-balancer-api-external", expired, now)(status)
+	if len(recorder.Events()) != 1 {
+		t.Fatalf("expected 1 event, got %v", recorder.Events())
+	}
+	// a still expired certificate is not reported again
+	manageStatusPeerCertificates(recorder, "load-balancer-api-external", expired, now)(status)
+	if len(recorder.Events()) != 1 {
+		t.Errorf("expected 1 event, got %v", recorder.Events())
+	}
+	if earliestExpiry(expired) != now.Add(-time.Hour) {
+		t.Errorf("unexpected earliest expiry %v", earliestExpiry(expired))
+	}
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"crypto/tls"
+	"reflect"
 	"time"
 
 	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
@@ -39,6 +40,8 @@ type controller struct {
 	recorder     Recorder
 	// each PodNetworkConnectivityCheck gets its own ConnectionChecker
 	updaters map[string]ConnectionChecker
+	// settings the ConnectionChecker of each PodNetworkConnectivityCheck was started with
+	settings map[string]checkSettings
 }
 
 // Returns a new PodNetworkConnectivityCheckController that performs network connectivity checks
@@ -55,6 +58,7 @@ func NewPodNetworkConnectivityCheckController(podName, podNamespace string,
 		secretLister: secretInformer.Lister(),
 		recorder:     NewBackoffEventRecorder(recorder),
 		updaters:     map[string]ConnectionChecker{},
+		settings:     map[string]checkSettings{},
 	}
 	c.Controller = factory.New().
 		WithSync(c.Sync).
@@ -80,10 +84,17 @@ func (c *controller) Sync(ctx context.Context, syncContext factory.SyncContext)
 		}
 	}
 
-	// create & start status updaters if needed
+	// create & start status updaters if needed, restart them if their interval or timeout changed
 	for _, check := range checks {
+		settings := settingsForCheck(check)
+		if updater := c.updaters[check.Name]; updater != nil && !reflect.DeepEqual(c.settings[check.Name], settings) {
+			klog.V(1).Infof("Restarting connectivity check %s with interval %v and timeout %v.", check.Name, settings.period, settings.timeout)
+			updater.Stop(ctx)
+			delete(c.updaters, check.Name)
+		}
 		if updater := c.updaters[check.Name]; updater == nil {
-			c.updaters[check.Name] = NewConnectionChecker(check.Name, c.podName, c.podNamespace, c.newCheckFunc(check.Name), c, c.getClientCerts(check), c.recorder)
+			c.updaters[check.Name] = NewConnectionChecker(check.Name, c.podName, c.podNamespace, settings, c.newCheckFunc(check.Name), c, c.getClientCerts(check), c.recorder)
+			c.settings[check.Name] = settings
 			go c.updaters[check.Name].Run(ctx)
 		}
 	}
@@ -100,6 +111,7 @@ func (c *controller) Sync(ctx context.Context, syncContext factory.SyncContext)
 		if !keep {
 			updater.Stop(ctx)
 			delete(c.updaters, name)
+			delete(c.settings, name)
 		}
 	}
 
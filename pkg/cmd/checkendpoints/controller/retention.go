@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+)
+
+const (
+	// maxOutages is the number of outages kept by default
+	maxOutages = 20
+	// maxLogEntries is the number of successes and failures kept by default
+	maxLogEntries = 10
+)
+
+// retention limits the history kept in the status of a check. Zero durations disable pruning by age and compaction.
+type retention struct {
+	maxOutages    int
+	maxLogEntries int
+	maxAge        time.Duration
+	compactionGap time.Duration
+}
+
+// manageStatusRetention compacts the outages and prunes the history that is too old or exceeds the limits.
+func manageStatusRetention(r retention, now time.Time) v1alpha1helpers.UpdateStatusFunc {
+	return func(status *operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckStatus) {
+		if r.compactionGap > 0 {
+			status.Outages = compactOutages(status.Outages, r.compactionGap)
+		}
+		if r.maxAge > 0 {
+			cutoff := now.Add(-r.maxAge)
+			var outages []operatorcontrolplanev1alpha1.OutageEntry
+			for _, outage := range status.Outages {
+				if outage.End.IsZero() || outage.End.After(cutoff) {
+					outages = append(outages, outage)
+				}
+			}
+			status.Outages = outages
+			status.Successes = pruneLogEntries(status.Successes, cutoff)
+			status.Failures = pruneLogEntries(status.Failures, cutoff)
+		}
+		if r.maxOutages > 0 && len(status.Outages) > r.maxOutages {
+			status.Outages = status.Outages[:r.maxOutages]
+		}
+		if r.maxLogEntries > 0 && len(status.Successes) > r.maxLogEntries {
+			status.Successes = status.Successes[:r.maxLogEntries]
+		}
+		if r.maxLogEntries > 0 && len(status.Failures) > r.maxLogEntries {
+			status.Failures = status.Failures[:r.maxLogEntries]
+		}
+	}
+}
+
+// compactOutages merges every outage, latest first, into the previous outage if it started less than gap after the
+// previous outage ended. A flapping target then has a single long outage instead of many short ones.
+func compactOutages(outages []operatorcontrolplanev1alpha1.OutageEntry, gap time.Duration) []operatorcontrolplanev1alpha1.OutageEntry {
+	var compacted []operatorcontrolplanev1alpha1.OutageEntry
+	for i := len(outages) - 1; i >= 0; i-- {
+		outage := outages[i]
+		if len(compacted) == 0 {
+			compacted = append(compacted, outage)
+			continue
+		}
+		previous := &compacted[len(compacted)-1]
+		if previous.End.IsZero() || outage.Start.Sub(previous.End.Time) >= gap {
+			compacted = append(compacted, outage)
+			continue
+		}
+		previous.End = outage.End
+		previous.EndLogs = outage.EndLogs
+		if previous.End.IsZero() {
+			previous.Message = fmt.Sprintf("Connectivity outage detected at %v", previous.Start.Format(time.RFC3339Nano))
+		} else {
+			previous.Message = fmt.Sprintf("Connectivity restored after %v", previous.End.Sub(previous.Start.Time))
+		}
+	}
+	// latest first
+	for i, j := 0, len(compacted)-1; i < j; i, j = i+1, j-1 {
+		compacted[i], compacted[j] = compacted[j], compacted[i]
+	}
+	return compacted
+}
+
+func pruneLogEntries(entries []operatorcontrolplanev1alpha1.LogEntry, cutoff time.Time) []operatorcontrolplanev1alpha1.LogEntry {
+	var pruned []operatorcontrolplanev1alpha1.LogEntry
+	for _, entry := range entries {
+		if entry.Start.After(cutoff) {
+			pruned = append(pruned, entry)
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageStatusRetention(t *testing.T) {
+	testCases := []struct {
+		name      string
+		retention retention
+		now       int
+		initial   *v1alpha1.PodNetworkConnectivityCheckStatus
+		expected  *v1alpha1.PodNetworkConnectivityCheckStatus
+	}{
+		{
+			name:      "defaults",
+			retention: retention{maxOutages: maxOutages, maxLogEntries: maxLogEntries},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withOutageEntry(30, withEnd(32)),
+				withOutageEntry(10, withEnd(12)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withOutageEntry(30, withEnd(32)),
+				withOutageEntry(10, withEnd(12)),
+			),
+		},
+		{
+			name:      "max entries",
+			retention: retention{maxOutages: 1, maxLogEntries: 1},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withSuccessEntry(tcpConnectEntry(39)),
+				withFailureEntry(tcpConnectErrorEntry(31)),
+				withFailureEntry(tcpConnectErrorEntry(30)),
+				withOutageEntry(30, withEnd(32)),
+				withOutageEntry(10, withEnd(12)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withFailureEntry(tcpConnectErrorEntry(31)),
+				withOutageEntry(30, withEnd(32)),
+			),
+		},
+		{
+			name:      "max age",
+			retention: retention{maxAge: 30 * time.Second},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withFailureEntry(tcpConnectErrorEntry(10)),
+				withOutageEntry(30, withEnd(32)),
+				withOutageEntry(10, withEnd(12)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withSuccessEntry(tcpConnectEntry(40)),
+				withOutageEntry(30, withEnd(32)),
+			),
+		},
+		{
+			name:      "max age keeps ongoing outage",
+			retention: retention{maxAge: 30 * time.Second},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withFailureEntry(tcpConnectErrorEntry(49)),
+				withOutageEntry(10),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withFailureEntry(tcpConnectErrorEntry(49)),
+				withOutageEntry(10),
+			),
+		},
+		{
+			name:      "compaction",
+			retention: retention{compactionGap: 5 * time.Second},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withOutageEntry(42, withEndLogEntry(tcpConnectErrorEntry(43))),
+				withOutageEntry(30, withEnd(36), withEndLogEntry(tcpConnectEntry(36))),
+				withOutageEntry(20, withEnd(28), withStartLogEntry(tcpConnectErrorEntry(20))),
+				withOutageEntry(10, withEnd(12)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withOutageEntry(42, withEndLogEntry(tcpConnectErrorEntry(43))),
+				withOutageEntry(20, withEnd(36), withStartLogEntry(tcpConnectErrorEntry(20)), withEndLogEntry(tcpConnectEntry(36)), withConnectivityRestoredMessage(20, 36)),
+				withOutageEntry(10, withEnd(12)),
+			),
+		},
+		{
+			name:      "compaction into ongoing outage",
+			retention: retention{compactionGap: 5 * time.Second},
+			now:       50,
+			initial: podNetworkConnectivityCheckStatus(
+				withOutageEntry(40, withEndLogEntry(tcpConnectErrorEntry(41))),
+				withOutageEntry(30, withEnd(38)),
+			),
+			expected: podNetworkConnectivityCheckStatus(
+				withOutageEntry(30, withEndLogEntry(tcpConnectErrorEntry(41)), withOutageDetectedMessage(30)),
+			),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := tc.initial.DeepCopy()
+			manageStatusRetention(tc.retention, testTime(tc.now))(status)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
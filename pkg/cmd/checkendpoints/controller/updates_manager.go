@@ -48,6 +48,12 @@ type updatesManager struct {
 	processor UpdatesProcessor
 }
 
+// batchSize is the number of updates that are processed together, the updates of about 20 seconds of checks. Checks
+// that run less often than that are processed one by one.
+func (u *updatesManager) batchSize() int {
+	return int(20 * time.Second / u.checkPeriod)
+}
+
 type UpdatesProcessor func(context.Context, ...v1alpha1helpers.UpdateStatusFunc) error
 
 // Add an update to the queue. There is a delay equal to the size of the sorting checkTimeout before
@@ -92,7 +98,7 @@ func (u *updatesManager) Add(timestamp time.Time, updates ...v1alpha1helpers.Upd
 func (u *updatesManager) Process(ctx context.Context, flush bool) error {
 	u.lock.Lock()
 	defer u.lock.Unlock()
-	if flush || len(u.processingQueue) > 20 {
+	if flush || len(u.processingQueue) > u.batchSize() {
 		if err := u.processor(ctx, u.processingQueue...); err != nil {
 			return err
 		}
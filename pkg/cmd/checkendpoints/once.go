@@ -0,0 +1,365 @@
+package checkendpoints
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	operatorcontrolplanev1alpha1 "github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	operatorcontrolplaneclient "github.com/openshift/client-go/operatorcontrolplane/clientset/versioned"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/controller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const jsonOutput = "json"
+
+// onceOpts holds which targets to check once and how to print the results.
+type onceOpts struct {
+	kubeconfig string
+	namespace  string
+	sourcePod  string
+	targets    []string
+	timeout    time.Duration
+	output     string
+
+	out io.Writer
+}
+
+// onceResult is the printed result of checking a target once.
+type onceResult struct {
+	Name           string   `json:"name"`
+	TargetEndpoint string   `json:"targetEndpoint"`
+	URL            string   `json:"url,omitempty"`
+	Success        bool     `json:"success"`
+	Error          string   `json:"error,omitempty"`
+	DNSLatency     string   `json:"dnsLatency,omitempty"`
+	ConnectLatency string   `json:"connectLatency,omitempty"`
+	HTTPGetLatency string   `json:"httpGetLatency,omitempty"`
+	HTTPStatusCode int      `json:"httpStatusCode,omitempty"`
+	TLS            *onceTLS `json:"tls,omitempty"`
+}
+
+// onceTLS is the serving certificate of a TLS target and whether the chain is valid and not about to expire.
+type onceTLS struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"notAfter"`
+	Valid    bool      `json:"valid"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message,omitempty"`
+}
+
+func newCheckOnceCommand() *cobra.Command {
+	opts := onceOpts{
+		namespace: operatorclient.TargetNamespace,
+		timeout:   10 * time.Second,
+	}
+	cmd := &cobra.Command{
+		Use:   "once",
+		Short: "Check the targets once, print the results and exit non-zero if any check failed",
+		Long: `Check the targets once, print the results and exit non-zero if any check failed.
+
+The targets are the PodNetworkConnectivityChecks of the namespace, i.e. the targets of the check-endpoints sidecars,
+or the given --targets, which does not need the cluster: host:port connects, host resolves the host name and
+http(s)://host:port/path sends a GET request.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			// failed checks are an expected result, not a crash worth a stack trace
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *onceOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster to read the checks from, defaults to the in-cluster config")
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the checks")
+	fs.StringVar(&o.sourcePod, "source-pod", o.sourcePod, "Only check the targets of the checks of this pod, the targets of all checks when empty")
+	fs.StringSliceVar(&o.targets, "targets", o.targets, "The targets to check instead of the targets of the checks, as host:port, host or http(s)://host:port/path")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "How long a check of a target waits, unless the check sets its own timeout")
+	fs.StringVar(&o.output, "output", o.output, "The output format of the results, a table by default or json")
+}
+
+// Validate verifies the inputs.
+func (o *onceOpts) Validate() error {
+	if o.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if len(o.output) > 0 && o.output != jsonOutput {
+		return fmt.Errorf("--output must be %q, got %q", jsonOutput, o.output)
+	}
+	for _, target := range o.targets {
+		if _, err := checkForTarget(target, o.timeout); err != nil {
+			return fmt.Errorf("invalid --targets: %v", err)
+		}
+	}
+	return nil
+}
+
+// Run checks the targets concurrently and prints the results. It fails if any check failed.
+func (o *onceOpts) Run(ctx context.Context) error {
+	checks, clientCerts, err := o.checks(ctx)
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("no checks found in %s", o.namespace)
+	}
+
+	results := make([]controller.CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = controller.CheckOnce(ctx, checks[i], clientCerts[checks[i].Spec.TLSClientCert.Name])
+		}(i)
+	}
+	wg.Wait()
+
+	printed := make([]onceResult, 0, len(results))
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+		printed = append(printed, newOnceResult(result))
+	}
+	if err := o.print(printed); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
+
+// checks returns the checks of the given targets, or the checks of the namespace with the client certificates they
+// reference. The checks of the namespace are deduplicated by their target unless a source pod is given.
+func (o *onceOpts) checks(ctx context.Context) ([]*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, map[string]controller.CertificatesGetter, error) {
+	clientCerts := map[string]controller.CertificatesGetter{}
+	if len(o.targets) > 0 {
+		var checks []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck
+		for _, target := range o.targets {
+			check, err := checkForTarget(target, o.timeout)
+			if err != nil {
+				return nil, nil, err
+			}
+			checks = append(checks, check)
+		}
+		return checks, clientCerts, nil
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	operatorcontrolplaneClient, err := operatorcontrolplaneclient.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := operatorcontrolplaneClient.ControlplaneV1alpha1().PodNetworkConnectivityChecks(o.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checks []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck
+	for i := range list.Items {
+		checks = append(checks, &list.Items[i])
+	}
+	checks = selectChecks(checks, o.sourcePod, o.timeout)
+	for _, check := range checks {
+		secretName := check.Spec.TLSClientCert.Name
+		if len(secretName) == 0 || clientCerts[secretName] != nil {
+			continue
+		}
+		secret, err := kubeClient.CoreV1().Secrets(o.namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get the client certificate of %s: %v", check.Name, err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to load the client certificate of %s: %v", check.Name, err)
+		}
+		clientCerts[secretName] = func() []tls.Certificate { return []tls.Certificate{cert} }
+	}
+	return checks, clientCerts, nil
+}
+
+// selectChecks returns the checks of the source pod, or one check per target and HTTP GET request if no source pod
+// is given, sorted by name. The timeout applies to the checks that do not set their own.
+func selectChecks(checks []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, sourcePod string, timeout time.Duration) []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck {
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	seen := map[string]bool{}
+	var selected []*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck
+	for _, check := range checks {
+		if len(sourcePod) > 0 && check.Spec.SourcePod != sourcePod {
+			continue
+		}
+		key := check.Spec.TargetEndpoint + check.Annotations[v1alpha1helpers.HTTPGetSchemeAnnotation] + check.Annotations[v1alpha1helpers.HTTPGetPathAnnotation]
+		if len(sourcePod) == 0 && seen[key] {
+			continue
+		}
+		seen[key] = true
+		check = check.DeepCopy()
+		if _, ok := check.Annotations[v1alpha1helpers.TimeoutAnnotation]; !ok {
+			if check.Annotations == nil {
+				check.Annotations = map[string]string{}
+			}
+			check.Annotations[v1alpha1helpers.TimeoutAnnotation] = timeout.String()
+		}
+		selected = append(selected, check)
+	}
+	return selected
+}
+
+// checkForTarget returns a check of the target, host:port connects, host resolves the host name and
+// http(s)://host:port/path sends a GET request.
+func checkForTarget(target string, timeout time.Duration) (*operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck, error) {
+	check := &operatorcontrolplanev1alpha1.PodNetworkConnectivityCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        target,
+			Annotations: map[string]string{v1alpha1helpers.TimeoutAnnotation: timeout.String()},
+		},
+	}
+	switch {
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+		if len(u.Hostname()) == 0 {
+			return nil, fmt.Errorf("%q has no host", target)
+		}
+		port := u.Port()
+		if len(port) == 0 {
+			port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+		}
+		path := u.RequestURI()
+		check.Spec.TargetEndpoint = net.JoinHostPort(u.Hostname(), port)
+		check.Annotations[v1alpha1helpers.HTTPGetPathAnnotation] = path
+		check.Annotations[v1alpha1helpers.HTTPGetSchemeAnnotation] = strings.ToUpper(u.Scheme)
+	case strings.Contains(target, "://"):
+		return nil, fmt.Errorf("%q must be host:port, host or an http or https URL", target)
+	default:
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			// a host name only, which is resolved
+			host, port = target, ""
+		}
+		if len(host) == 0 {
+			return nil, fmt.Errorf("%q has no host", target)
+		}
+		// the target endpoint of a DNS check has no port
+		check.Spec.TargetEndpoint = net.JoinHostPort(host, port)
+	}
+	return check, nil
+}
+
+func newOnceResult(result controller.CheckResult) onceResult {
+	printed := onceResult{
+		Name:           result.Name,
+		TargetEndpoint: result.TargetEndpoint,
+		URL:            result.URL,
+		Success:        result.Err == nil,
+	}
+	if result.Err != nil {
+		printed.Error = result.Err.Error()
+	}
+	if latency := result.Latency; latency != nil {
+		if latency.DNS > 0 {
+			printed.DNSLatency = latency.DNS.String()
+		}
+		if latency.Connect > 0 {
+			printed.ConnectLatency = latency.Connect.String()
+		}
+		if !latency.HTTPGetStart.IsZero() {
+			printed.HTTPGetLatency = latency.HTTPGet.String()
+		}
+		printed.HTTPStatusCode = latency.HTTPStatusCode
+	}
+	if condition := result.TLSCertificateCondition; condition != nil {
+		cert := result.PeerCertificates[0]
+		printed.TLS = &onceTLS{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter.UTC(),
+			Valid:    condition.Status == metav1.ConditionTrue,
+			Reason:   condition.Reason,
+			Message:  condition.Message,
+		}
+	}
+	return printed
+}
+
+// print prints the results as a table or as json.
+func (o *onceOpts) print(results []onceResult) error {
+	if o.output == jsonOutput {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+
+	w := tabwriter.NewWriter(o.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTARGET\tRESULT\tDNS\tCONNECT\tHTTP\tTLS\tMESSAGE")
+	for _, result := range results {
+		status := "Success"
+		message := ""
+		if !result.Success {
+			status, message = "Failure", result.Error
+		}
+		http := orDash(result.HTTPGetLatency)
+		if result.HTTPStatusCode > 0 {
+			http = fmt.Sprintf("%d in %s", result.HTTPStatusCode, result.HTTPGetLatency)
+		}
+		tlsSummary := "-"
+		if result.TLS != nil {
+			tlsSummary = result.TLS.Reason
+			if len(message) == 0 && !result.TLS.Valid {
+				message = result.TLS.Message
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, result.TargetEndpoint, status,
+			orDash(result.DNSLatency), orDash(result.ConnectLatency), http, tlsSummary, message)
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -11,6 +11,52 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
+const (
+	// IntervalAnnotation overrides the time between two checks of the target of a PodNetworkConnectivityCheck, e.g. "30s".
+	IntervalAnnotation = "check-endpoints.openshift.io/interval"
+	// TimeoutAnnotation overrides how long a check of the target of a PodNetworkConnectivityCheck waits, e.g. "5s".
+	TimeoutAnnotation = "check-endpoints.openshift.io/timeout"
+	// SuccessLogIntervalAnnotation is the minimum time between two successes of the same kind in the status of a
+	// PodNetworkConnectivityCheck, e.g. "5m".
+	SuccessLogIntervalAnnotation = "check-endpoints.openshift.io/success-log-interval"
+	// MaxOutagesAnnotation lowers the number of outages kept in the status of a PodNetworkConnectivityCheck.
+	MaxOutagesAnnotation = "check-endpoints.openshift.io/max-outages"
+	// MaxLogEntriesAnnotation lowers the number of successes and failures kept in the status of a PodNetworkConnectivityCheck.
+	MaxLogEntriesAnnotation = "check-endpoints.openshift.io/max-log-entries"
+	// MaxAgeAnnotation prunes the outages that ended and the log entries that started longer ago, e.g. "168h".
+	MaxAgeAnnotation = "check-endpoints.openshift.io/max-age"
+	// OutageCompactionGapAnnotation merges an outage into the previous one if it started less than this after the
+	// previous one ended, e.g. "1m".
+	OutageCompactionGapAnnotation = "check-endpoints.openshift.io/outage-compaction-gap"
+	// HTTPGetPathAnnotation is the path of an HTTP GET request that follows the TCP connection to the target of a
+	// PodNetworkConnectivityCheck, e.g. "/readyz".
+	HTTPGetPathAnnotation = "check-endpoints.openshift.io/http-get-path"
+	// HTTPGetSchemeAnnotation is the scheme of the HTTP GET request, HTTP or HTTPS. Defaults to HTTPS.
+	HTTPGetSchemeAnnotation = "check-endpoints.openshift.io/http-get-scheme"
+	// HTTPGetExpectedStatusCodesAnnotation are the comma separated status codes of a successful response to the HTTP
+	// GET request, e.g. "200,204". Defaults to any status code between 200 and 399.
+	HTTPGetExpectedStatusCodesAnnotation = "check-endpoints.openshift.io/http-get-expected-status-codes"
+)
+
+// IPFamilyLabel is the IP family, IPv4 or IPv6, of the target of a PodNetworkConnectivityCheck. check-endpoints only
+// connects to the addresses of that family of a host name.
+const IPFamilyLabel = "check-endpoints.openshift.io/ip-family"
+
+// SettingsAnnotations are the annotations of a PodNetworkConnectivityCheck that change how check-endpoints checks
+// its target.
+var SettingsAnnotations = []string{
+	IntervalAnnotation,
+	TimeoutAnnotation,
+	SuccessLogIntervalAnnotation,
+	MaxOutagesAnnotation,
+	MaxLogEntriesAnnotation,
+	MaxAgeAnnotation,
+	OutageCompactionGapAnnotation,
+	HTTPGetPathAnnotation,
+	HTTPGetSchemeAnnotation,
+	HTTPGetExpectedStatusCodesAnnotation,
+}
+
 func SetPodNetworkConnectivityCheckCondition(conditions *[]operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition, newCondition operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition) {
 	if conditions == nil {
 		conditions = &[]operatorcontrolplanev1alpha1.PodNetworkConnectivityCheckCondition{}
@@ -11,8 +11,12 @@ import (
 type LatencyInfo struct {
 	DNS          time.Duration
 	Connect      time.Duration
+	HTTPGet      time.Duration
 	DNSStart     time.Time
 	ConnectStart time.Time
+	HTTPGetStart time.Time
+	// HTTPStatusCode is the status code of the response to the HTTP GET request, zero without a response
+	HTTPStatusCode int
 }
 
 func (r *LatencyInfo) dnsStart() {
@@ -0,0 +1,63 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+var shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// NewCompletionCommand creates the completion command, which prints the shell completion script of the commands of
+// the binary.
+func NewCompletionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion " + strings.Join(shells, "|"),
+		Short: "Print the shell completion script of the commands",
+		Long: `Print the shell completion script of the commands, e.g. for the current bash shell:
+
+  source <(cluster-kube-apiserver-operator completion bash)`,
+		ValidArgs: shells,
+		Args:      cobra.ExactValidArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := Generate(cmd.Root(), args[0], cmd.OutOrStdout()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
+
+// Generate writes the completion script of the root command for the shell.
+func Generate(root *cobra.Command, shell string, out io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletion(out)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("unsupported shell %q, supported are %s", shell, strings.Join(shells, ", "))
+	}
+}
+
+// MarkPathFlags marks the flags of the command whose values are paths by their name, so that the shell completes
+// them with files or directories: --kubeconfig and *-file flags take files, *-dir flags take directories.
+func MarkPathFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		switch {
+		case flag.Name == "kubeconfig" || strings.HasSuffix(flag.Name, "-file"):
+			_ = cmd.MarkFlagFilename(flag.Name)
+		case strings.HasSuffix(flag.Name, "-dir"):
+			_ = cmd.MarkFlagDirname(flag.Name)
+		}
+	})
+	return cmd
+}
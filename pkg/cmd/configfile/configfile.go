@@ -0,0 +1,148 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFlagName is the flag of the config file, it cannot be set in the config file itself.
+const configFlagName = "config"
+
+// WithConfigFile adds --config to the command, a YAML or JSON file whose keys are the names of the flags of the
+// command, e.g.
+//
+//	revision: "3"
+//	configmaps:
+//	- kube-apiserver-pod
+//	- config
+//	timeout-duration: 2m
+//
+// The flags are set from the file before the command runs. Flags given on the command line take precedence over the
+// file, so a long invocation can be kept in a file and single flags can still be overridden.
+func WithConfigFile(cmd *cobra.Command) *cobra.Command {
+	var configFile string
+	cmd.Flags().StringVar(&configFile, configFlagName, configFile, "A YAML or JSON file with the values of the flags of this command by flag name, flags on the command line take precedence")
+	_ = cmd.MarkFlagFilename(configFlagName, "yaml", "yml", "json")
+
+	preRunE, preRun := cmd.PreRunE, cmd.PreRun
+	cmd.PreRun = nil
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if len(configFile) > 0 {
+			if err := ApplyFile(cmd.Flags(), configFile); err != nil {
+				return err
+			}
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// ApplyFile sets the flags that were not set on the command line from the config file.
+func ApplyFile(fs *pflag.FlagSet, configFile string) error {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	if err := Apply(fs, data); err != nil {
+		return fmt.Errorf("config file %s: %v", configFile, err)
+	}
+	return nil
+}
+
+// Apply sets the flags that were not set on the command line from the YAML or JSON serialized values by flag name.
+// Unknown flags are rejected so that a typo does not silently result in the default. A list sets a slice flag to
+// its items, any other value is set like on the command line.
+func Apply(fs *pflag.FlagSet, data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to parse: %v", err)
+	}
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("unable to decode: %v", err)
+	}
+
+	// sorted for stable errors
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == configFlagName {
+			return fmt.Errorf("%s cannot be set in the config file", name)
+		}
+		flag := fs.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown flag %q", name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := setFlag(fs, flag, values[name]); err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func setFlag(fs *pflag.FlagSet, flag *pflag.Flag, value interface{}) error {
+	items, isList := value.([]interface{})
+	if !isList {
+		s, err := scalar(value)
+		if err != nil {
+			return err
+		}
+		return fs.Set(flag.Name, s)
+	}
+	if !strings.HasSuffix(flag.Value.Type(), "Slice") && !strings.HasSuffix(flag.Value.Type(), "Array") {
+		return fmt.Errorf("a list is only allowed for list flags, not for %s", flag.Value.Type())
+	}
+	if len(items) == 0 {
+		// the first Set of a slice flag replaces its default, the following ones append
+		return fs.Set(flag.Name, "")
+	}
+	for _, item := range items {
+		s, err := scalar(item)
+		if err != nil {
+			return err
+		}
+		if err := fs.Set(flag.Name, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scalar returns the value as on the command line.
+func scalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("must be a string, a number or a boolean, got %T", value)
+	}
+}
@@ -0,0 +1,138 @@
+package configfile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testOpts struct {
+	revision   string
+	configMaps []string
+	timeout    time.Duration
+	maxEntries int
+	force      bool
+}
+
+func newTestCommand(opts *testOpts, run func()) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "test",
+		Run: func(cmd *cobra.Command, args []string) { run() },
+	}
+	cmd.Flags().StringVar(&opts.revision, "revision", opts.revision, "")
+	cmd.Flags().StringSliceVar(&opts.configMaps, "configmaps", []string{"default"}, "")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout-duration", 2*time.Minute, "")
+	cmd.Flags().IntVar(&opts.maxEntries, "max-entries", opts.maxEntries, "")
+	cmd.Flags().BoolVar(&opts.force, "force", opts.force, "")
+	return WithConfigFile(cmd)
+}
+
+func TestWithConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	yamlConfig := writeConfig("config.yaml", `
+revision: "3"
+configmaps:
+- kube-apiserver-pod
+- config
+timeout-duration: 30s
+max-entries: 5
+force: true
+`)
+	jsonConfig := writeConfig("config.json", `{"revision": 4, "configmaps": "a,b"}`)
+
+	testCases := []struct {
+		name          string
+		args          []string
+		expected      testOpts
+		expectedError string
+	}{
+		{
+			name:     "no config file",
+			args:     []string{"--revision=1"},
+			expected: testOpts{revision: "1", configMaps: []string{"default"}, timeout: 2 * time.Minute},
+		},
+		{
+			name:     "yaml",
+			args:     []string{"--config=" + yamlConfig},
+			expected: testOpts{revision: "3", configMaps: []string{"kube-apiserver-pod", "config"}, timeout: 30 * time.Second, maxEntries: 5, force: true},
+		},
+		{
+			name:     "json",
+			args:     []string{"--config=" + jsonConfig},
+			expected: testOpts{revision: "4", configMaps: []string{"a", "b"}, timeout: 2 * time.Minute},
+		},
+		{
+			name:     "command line takes precedence",
+			args:     []string{"--config=" + yamlConfig, "--revision=7", "--configmaps=other", "--force=false"},
+			expected: testOpts{revision: "7", configMaps: []string{"other"}, timeout: 30 * time.Second, maxEntries: 5},
+		},
+		{
+			name:          "unknown flag",
+			args:          []string{"--config=" + writeConfig("unknown.yaml", "revison: 3\n")},
+			expectedError: `unknown flag "revison"`,
+		},
+		{
+			name:          "list for a scalar flag",
+			args:          []string{"--config=" + writeConfig("list.yaml", "revision: [1, 2]\n")},
+			expectedError: "invalid revision: a list is only allowed for list flags",
+		},
+		{
+			name:          "invalid value",
+			args:          []string{"--config=" + writeConfig("invalid.yaml", "timeout-duration: soon\n")},
+			expectedError: "invalid timeout-duration",
+		},
+		{
+			name:          "nested config",
+			args:          []string{"--config=" + writeConfig("nested.yaml", "config: other.yaml\n")},
+			expectedError: "config cannot be set in the config file",
+		},
+		{
+			name:          "missing config file",
+			args:          []string{"--config=" + filepath.Join(dir, "missing.yaml")},
+			expectedError: "no such file or directory",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &testOpts{}
+			ran := false
+			cmd := newTestCommand(opts, func() { ran = true })
+			cmd.SetArgs(tc.args)
+			cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			err := cmd.Execute()
+			if len(tc.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				if ran {
+					t.Errorf("expected the command not to run")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ran {
+				t.Errorf("expected the command to run")
+			}
+			if !reflect.DeepEqual(*opts, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, *opts)
+			}
+		})
+	}
+}
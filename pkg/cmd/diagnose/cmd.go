@@ -0,0 +1,302 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	operatorcontrolplaneclient "github.com/openshift/client-go/operatorcontrolplane/clientset/versioned"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// redacted replaces the values of the secrets in the bundle
+	redacted = "REDACTED"
+
+	// lastAppliedAnnotation can hold the data of a secret
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// maxOutages is how many of the latest outages of a connectivity check are in the bundle
+	maxOutages = 5
+)
+
+// bundleNamespaces are the namespaces whose pods, logs, configmaps and redacted secrets are collected.
+var bundleNamespaces = []string{
+	operatorclient.OperatorNamespace,
+	operatorclient.TargetNamespace,
+}
+
+// diagnoseOpts holds how to reach the cluster and where to write the bundle.
+type diagnoseOpts struct {
+	kubeconfig string
+	output     string
+	since      time.Duration
+}
+
+// NewDiagnoseCommand creates the diagnose command. It writes a support bundle of the kube-apiserver control plane to a
+// gzipped tarball: the kubeapiserver/cluster object with the node statuses, the configmaps of the operator and the
+// operand namespace (the revisions, the observed config history and the installer history among them), their pods with
+// the logs, the secrets with every value redacted and a summary of the connectivity checks. It is a small, focused
+// alternative to a full must-gather for a support case.
+func NewDiagnoseCommand() *cobra.Command {
+	opts := diagnoseOpts{
+		since: 6 * time.Hour,
+	}
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Write a support bundle of the kube-apiserver control plane with the secrets redacted",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *diagnoseOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster, defaults to the in-cluster config")
+	fs.StringVar(&o.output, "output", o.output, "The file the bundle is written to, defaults to kube-apiserver-diagnose-<time>.tar.gz in the working directory")
+	fs.DurationVar(&o.since, "since", o.since, "How far back the logs of the pods are collected, 0 for all")
+}
+
+// Validate verifies the inputs.
+func (o *diagnoseOpts) Validate() error {
+	if o.since < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+	return nil
+}
+
+// Run collects the bundle. What cannot be collected is listed in errors.txt of the bundle rather than failing it.
+func (o *diagnoseOpts) Run(ctx context.Context) error {
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	operatorClient, err := operatorclientv1.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	checkClient, err := operatorcontrolplaneclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	output := o.output
+	if len(output) == 0 {
+		output = fmt.Sprintf("kube-apiserver-diagnose-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	b := newBundle(file)
+	if kubeAPIServer, err := operatorClient.KubeAPIServers().Get(ctx, "cluster", metav1.GetOptions{}); err != nil {
+		b.failed("kubeapiserver/cluster", err)
+	} else {
+		kubeAPIServer.ManagedFields = nil
+		b.addYAML("kubeapiserver.yaml", kubeAPIServer)
+	}
+	for _, namespace := range bundleNamespaces {
+		collectNamespace(ctx, b, kubeClient, namespace, o.since)
+	}
+	if checks, err := checkClient.ControlplaneV1alpha1().PodNetworkConnectivityChecks(operatorclient.TargetNamespace).List(ctx, metav1.ListOptions{}); err != nil {
+		b.failed("podnetworkconnectivitychecks", err)
+	} else {
+		b.addYAML("connectivity-checks.yaml", summarizeChecks(checks.Items))
+	}
+	if err := b.close(); err != nil {
+		return err
+	}
+	klog.Infof("Wrote %s", output)
+	return nil
+}
+
+// collectNamespace adds the configmaps, the redacted secrets and the pods with their logs of the namespace.
+func collectNamespace(ctx context.Context, b *bundle, kubeClient kubernetes.Interface, namespace string, since time.Duration) {
+	dir := path.Join("namespaces", namespace)
+
+	if configMaps, err := kubeClient.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		b.failed(dir+"/configmaps", err)
+	} else {
+		for i := range configMaps.Items {
+			configMap := &configMaps.Items[i]
+			configMap.ManagedFields = nil
+			b.addYAML(path.Join(dir, "configmaps", configMap.Name+".yaml"), configMap)
+		}
+	}
+
+	if secrets, err := kubeClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		b.failed(dir+"/secrets", err)
+	} else {
+		for i := range secrets.Items {
+			b.addYAML(path.Join(dir, "secrets", secrets.Items[i].Name+".yaml"), redactSecret(&secrets.Items[i]))
+		}
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		b.failed(dir+"/pods", err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		pod.ManagedFields = nil
+		b.addYAML(path.Join(dir, "pods", pod.Name+".yaml"), pod)
+		for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			logOptions := &corev1.PodLogOptions{Container: status.Name}
+			if since > 0 {
+				sinceSeconds := int64(since.Seconds())
+				logOptions.SinceSeconds = &sinceSeconds
+			}
+			b.addLogs(ctx, kubeClient, pod, path.Join(dir, "pods", pod.Name, status.Name+".log"), logOptions)
+			if status.RestartCount > 0 {
+				previous := *logOptions
+				previous.Previous = true
+				b.addLogs(ctx, kubeClient, pod, path.Join(dir, "pods", pod.Name, status.Name+".previous.log"), &previous)
+			}
+		}
+	}
+}
+
+// redactSecret returns the secret with every value of the data replaced and without the annotation that can hold the
+// data. The keys, the type and the other metadata are kept, e.g. the certificate expiry annotations.
+func redactSecret(secret *corev1.Secret) *corev1.Secret {
+	ret := secret.DeepCopy()
+	ret.ManagedFields = nil
+	delete(ret.Annotations, lastAppliedAnnotation)
+	for key, value := range ret.Data {
+		ret.Data[key] = []byte(fmt.Sprintf("%s (%d bytes)", redacted, len(value)))
+	}
+	for key := range ret.StringData {
+		ret.StringData[key] = redacted
+	}
+	return ret
+}
+
+// CheckSummary is the state of a connectivity check.
+type CheckSummary struct {
+	Name       string                                          `json:"name"`
+	Source     string                                          `json:"source"`
+	Target     string                                          `json:"target"`
+	Conditions []v1alpha1.PodNetworkConnectivityCheckCondition `json:"conditions,omitempty"`
+	// outages are the latest outages, the most recent first
+	Outages []v1alpha1.OutageEntry `json:"outages,omitempty"`
+}
+
+// summarizeChecks returns the conditions and the latest outages of the connectivity checks, without the individual
+// successes and failures.
+func summarizeChecks(checks []v1alpha1.PodNetworkConnectivityCheck) []CheckSummary {
+	ret := []CheckSummary{}
+	for _, check := range checks {
+		summary := CheckSummary{
+			Name:       check.Name,
+			Source:     check.Spec.SourcePod,
+			Target:     check.Spec.TargetEndpoint,
+			Conditions: check.Status.Conditions,
+			Outages:    check.Status.Outages,
+		}
+		if len(summary.Outages) > maxOutages {
+			summary.Outages = summary.Outages[:maxOutages]
+		}
+		ret = append(ret, summary)
+	}
+	return ret
+}
+
+// bundle is a gzipped tarball. Errors of adding a file are collected and written to errors.txt on close.
+type bundle struct {
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+	errors     []string
+	now        time.Time
+}
+
+func newBundle(out io.Writer) *bundle {
+	gzipWriter := gzip.NewWriter(out)
+	return &bundle{gzipWriter: gzipWriter, tarWriter: tar.NewWriter(gzipWriter), now: time.Now()}
+}
+
+func (b *bundle) failed(what string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+func (b *bundle) add(name string, data []byte) {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: b.now}
+	if err := b.tarWriter.WriteHeader(header); err != nil {
+		b.failed(name, err)
+		return
+	}
+	if _, err := b.tarWriter.Write(data); err != nil {
+		b.failed(name, err)
+	}
+}
+
+func (b *bundle) addYAML(name string, obj interface{}) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		b.failed(name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+func (b *bundle) addLogs(ctx context.Context, kubeClient kubernetes.Interface, pod *corev1.Pod, name string, logOptions *corev1.PodLogOptions) {
+	stream, err := kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		b.failed(name, err)
+		return
+	}
+	defer stream.Close()
+	data, err := ioutil.ReadAll(stream)
+	if err != nil {
+		b.failed(name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+// close writes errors.txt, if anything failed, and closes the tarball.
+func (b *bundle) close() error {
+	if len(b.errors) > 0 {
+		var data []byte
+		for _, err := range b.errors {
+			data = append(data, err+"\n"...)
+		}
+		b.add("errors.txt", data)
+	}
+	if err := b.tarWriter.Close(); err != nil {
+		return err
+	}
+	return b.gzipWriter.Close()
+}
@@ -0,0 +1,126 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedactSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "serving-cert",
+			Annotations: map[string]string{
+				lastAppliedAnnotation:                  `{"data":{"tls.key":"c2VjcmV0"}}`,
+				"auth.openshift.io/certificate-issuer": "kube-apiserver-lb-signer",
+			},
+		},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSPrivateKeyKey: []byte("secret")},
+		StringData: map[string]string{"token": "secret"},
+	}
+
+	ret := redactSecret(secret)
+	if string(ret.Data[corev1.TLSPrivateKeyKey]) != "REDACTED (6 bytes)" || ret.StringData["token"] != redacted {
+		t.Errorf("expected the values to be redacted, got %v %v", ret.Data, ret.StringData)
+	}
+	if _, ok := ret.Annotations[lastAppliedAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be dropped", lastAppliedAnnotation)
+	}
+	if ret.Annotations["auth.openshift.io/certificate-issuer"] != "kube-apiserver-lb-signer" || ret.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected the other metadata to be kept, got %+v", ret.ObjectMeta)
+	}
+	if string(secret.Data[corev1.TLSPrivateKeyKey]) != "secret" {
+		t.Errorf("expected the input secret to be unchanged")
+	}
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	var outages []v1alpha1.OutageEntry
+	for i := 0; i < maxOutages+2; i++ {
+		outages = append(outages, v1alpha1.OutageEntry{Message: "outage"})
+	}
+	summaries := summarizeChecks([]v1alpha1.PodNetworkConnectivityCheck{{
+		ObjectMeta: metav1.ObjectMeta{Name: "check"},
+		Spec:       v1alpha1.PodNetworkConnectivityCheckSpec{SourcePod: "kube-apiserver-master-0", TargetEndpoint: "10.0.0.1:2379"},
+		Status: v1alpha1.PodNetworkConnectivityCheckStatus{
+			Successes: []v1alpha1.LogEntry{{Success: true}},
+			Outages:   outages,
+		},
+	}})
+	if len(summaries) != 1 || summaries[0].Source != "kube-apiserver-master-0" || summaries[0].Target != "10.0.0.1:2379" || len(summaries[0].Outages) != maxOutages {
+		t.Errorf("unexpected summaries: %+v", summaries)
+	}
+}
+
+func TestCollectNamespace(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "installer-history"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "serving-cert"}, Data: map[string][]byte{"tls.key": []byte("secret")}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-master-0"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kube-apiserver", RestartCount: 1},
+			}},
+		},
+	)
+
+	out := &bytes.Buffer{}
+	b := newBundle(out)
+	collectNamespace(context.Background(), b, kubeClient, "openshift-kube-apiserver", time.Hour)
+	b.failed("podnetworkconnectivitychecks", io.EOF)
+	if err := b.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	gzipReader, err := gzip.NewReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(data)
+	}
+
+	for _, name := range []string{
+		"namespaces/openshift-kube-apiserver/configmaps/installer-history.yaml",
+		"namespaces/openshift-kube-apiserver/secrets/serving-cert.yaml",
+		"namespaces/openshift-kube-apiserver/pods/kube-apiserver-master-0.yaml",
+		"namespaces/openshift-kube-apiserver/pods/kube-apiserver-master-0/kube-apiserver.log",
+		"namespaces/openshift-kube-apiserver/pods/kube-apiserver-master-0/kube-apiserver.previous.log",
+		"errors.txt",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in the bundle, got %d files", name, len(files))
+		}
+	}
+	if secret := files["namespaces/openshift-kube-apiserver/secrets/serving-cert.yaml"]; !strings.Contains(secret, "UkVEQUNURUQgKDYgYnl0ZXMp") {
+		t.Errorf("expected the secret to be redacted:\n%s", secret)
+	}
+	if !strings.Contains(files["errors.txt"], "podnetworkconnectivitychecks: EOF") {
+		t.Errorf("unexpected errors.txt: %s", files["errors.txt"])
+	}
+}
@@ -0,0 +1,181 @@
+package fastinstaller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
+)
+
+// NewFastInstaller creates the fast-installer command. It has the flags of the installer command and installs the
+// revision like it, then it reads back what it wrote to the node: the static pod manifest has to match the copy in the
+// resource dir of the revision and carry its revision label, and the directories of the required configmaps and
+// secrets have to exist. Installer pods run it on the single node fast path, a verification error fails the installer like a failed
+// write does.
+func NewFastInstaller() *cobra.Command {
+	o := installer.NewInstallOptions()
+	fetchWorkers := installer.DefaultFetchWorkers
+	podReady := installer.NewPodReadyOptions()
+	retention := installer.NewRetentionOptions()
+	substitutions := installer.NewSubstitutions()
+	certs := installer.NewCertValidationOptions()
+	status := installer.NewStatusOptions()
+	fetch := installer.NewFetchOptions()
+
+	cmd := &cobra.Command{
+		Use:   "fast-installer",
+		Short: "Install static pod and related resources and verify them on the node",
+		Run: func(cmd *cobra.Command, args []string) {
+			klog.V(1).Info(cmd.Flags())
+			klog.V(1).Info(spew.Sdump(o))
+
+			if err := o.Complete(); err != nil {
+				klog.Exit(err)
+			}
+			if err := o.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			if fetchWorkers < 1 {
+				klog.Exit(fmt.Errorf("--fetch-workers must be at least 1"))
+			}
+			if err := podReady.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			if err := retention.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			if err := substitutions.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			if err := fetch.Validate(); err != nil {
+				klog.Exit(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.TODO(), o.Timeout)
+			defer cancel()
+			err := install(ctx, o, fetch, fetchWorkers, substitutions, certs, retention, status)
+			if err == nil {
+				endWait := status.Phase(installer.PhaseWaitForPodReady)
+				err = podReady.Wait(context.TODO(), o)
+				endWait()
+			}
+			if writeErr := status.Write(o, err); writeErr != nil {
+				klog.Warningf("Failed to write the status: %v", writeErr)
+			}
+			if err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+	cmd.Flags().IntVar(&fetchWorkers, "fetch-workers", fetchWorkers, "How many secrets and configmaps are fetched at the same time")
+	podReady.AddFlags(cmd.Flags())
+	retention.AddFlags(cmd.Flags())
+	substitutions.AddFlags(cmd.Flags())
+	certs.AddFlags(cmd.Flags())
+	status.AddFlags(cmd.Flags())
+	fetch.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// install fetches, checks, writes, verifies and records the revision, recording the phases in the status.
+func install(ctx context.Context, o *installer.InstallOptions, fetch *installer.FetchOptions, fetchWorkers int, substitutions *installer.Substitutions, certs *installer.CertValidationOptions, retention *installer.RetentionOptions, status *installer.StatusOptions) error {
+	endList := status.Phase(installer.PhaseListResources)
+	source := installer.NewTimedContentSource(fetch.ContentSource(ctx, o.KubeClient, o), status)
+	endList()
+	values, err := substitutions.Resolve(ctx, o)
+	if err != nil {
+		return err
+	}
+	if len(values) > 0 {
+		source = installer.NewSubstitutingContentSource(source, values)
+	}
+	resources, err := installer.FetchResources(ctx, o, source, fetchWorkers)
+	if err != nil {
+		return err
+	}
+	endCheck := status.Phase(installer.PhaseCheckCerts)
+	err = certs.Check(ctx, o, resources)
+	endCheck()
+	if err != nil {
+		return err
+	}
+	endRemove := status.Phase(installer.PhaseRemoveRevisions)
+	err = retention.Apply(ctx, o)
+	endRemove()
+	if err != nil {
+		return err
+	}
+	if err := installer.InstallRevision(ctx, o, resources, status); err != nil {
+		return err
+	}
+	if err := Verify(o); err != nil {
+		return fmt.Errorf("failed to verify revision %s: %v", o.Revision, err)
+	}
+	klog.Infof("Verified revision %s", o.Revision)
+	return installer.RecordInstalledRevision(o)
+}
+
+// Verify checks that the installer wrote the static pod manifest and the required resources of the revision.
+// Optional configmaps and secrets are not checked, the installer skips those it does not find.
+func Verify(o *installer.InstallOptions) error {
+	resourceDir := filepath.Join(o.ResourceDir, fmt.Sprintf("%s-%s", o.PodConfigMapNamePrefix, o.Revision))
+	manifestFileName := o.PodConfigMapNamePrefix + ".yaml"
+
+	installed, err := ioutil.ReadFile(filepath.Join(o.PodManifestDir, manifestFileName))
+	if err != nil {
+		return err
+	}
+	revisionCopy, err := ioutil.ReadFile(filepath.Join(resourceDir, manifestFileName))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(installed, revisionCopy) {
+		return fmt.Errorf("the static pod manifest %s differs from the one in %s", filepath.Join(o.PodManifestDir, manifestFileName), resourceDir)
+	}
+	pod, err := resourceread.ReadPodV1(installed)
+	if err != nil {
+		return fmt.Errorf("the static pod manifest does not decode: %v", err)
+	}
+	if revision := pod.Labels["revision"]; revision != o.Revision {
+		return fmt.Errorf("the static pod manifest has revision %q", revision)
+	}
+
+	var dirs []string
+	for _, prefix := range o.ConfigMapNamePrefixes {
+		dirs = append(dirs, filepath.Join(resourceDir, "configmaps", prefix))
+	}
+	for _, prefix := range o.SecretNamePrefixes {
+		dirs = append(dirs, filepath.Join(resourceDir, "secrets", prefix))
+	}
+	if len(o.CertDir) > 0 {
+		for _, name := range o.CertConfigMapNamePrefixes {
+			dirs = append(dirs, filepath.Join(o.CertDir, "configmaps", name))
+		}
+		for _, name := range o.CertSecretNames {
+			dirs = append(dirs, filepath.Join(o.CertDir, "secrets", name))
+		}
+	}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package fastinstaller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-apiserver
+  namespace: openshift-kube-apiserver
+  labels:
+    revision: "7"
+`
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		installed   string
+		skipSecret  bool
+		expectError bool
+	}{
+		{name: "verified", installed: manifest},
+		{name: "manifest differs", installed: manifest + "  annotations: {}\n", expectError: true},
+		{name: "missing secret", installed: manifest, skipSecret: true, expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "fast-installer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			o := &installer.InstallOptions{
+				Revision:               "7",
+				PodConfigMapNamePrefix: "kube-apiserver-pod",
+				ConfigMapNamePrefixes:  []string{"kube-apiserver-pod", "config"},
+				SecretNamePrefixes:     []string{"etcd-client"},
+				ResourceDir:            filepath.Join(dir, "resources"),
+				PodManifestDir:         filepath.Join(dir, "manifests"),
+			}
+			revisionDir := filepath.Join(o.ResourceDir, "kube-apiserver-pod-7")
+			dirs := []string{o.PodManifestDir, filepath.Join(revisionDir, "configmaps", "kube-apiserver-pod"), filepath.Join(revisionDir, "configmaps", "config")}
+			if !test.skipSecret {
+				dirs = append(dirs, filepath.Join(revisionDir, "secrets", "etcd-client"))
+			}
+			for _, d := range dirs {
+				if err := os.MkdirAll(d, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(revisionDir, "kube-apiserver-pod.yaml"), []byte(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(o.PodManifestDir, "kube-apiserver-pod.yaml"), []byte(test.installed), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Verify(o); test.expectError != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
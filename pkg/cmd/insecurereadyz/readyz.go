@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -19,8 +20,14 @@ import (
 
 // readyzOpts holds values to drive the readyz proxy.
 type readyzOpts struct {
-	insecurePort uint16
-	delegate     string
+	insecurePort      uint16
+	delegate          string
+	delegateTokenFile string
+
+	// livezDelegate is the delegate with the path replaced by /livez
+	livezDelegate string
+	// delegateToken authenticates the proxied requests when set
+	delegateToken string
 }
 
 // NewInsecureReadyzCommand creates a insecure-readyz command.
@@ -31,7 +38,7 @@ func NewInsecureReadyzCommand() *cobra.Command {
 	}
 	cmd := &cobra.Command{
 		Use:   "insecure-readyz",
-		Short: "Proxy the /readyz endpoint insecurely on an HTTP port",
+		Short: "Proxy the /readyz and /livez endpoints insecurely on an HTTP port",
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := opts.Validate(); err != nil {
 				klog.Fatal(err)
@@ -52,7 +59,8 @@ func NewInsecureReadyzCommand() *cobra.Command {
 
 func (r *readyzOpts) AddFlags(fs *pflag.FlagSet) {
 	fs.Uint16Var(&r.insecurePort, "insecure-port", r.insecurePort, "Listen on this port")
-	fs.StringVar(&r.delegate, "delegate-url", r.delegate, "The URL the insecure /readyz endpoint proxies to")
+	fs.StringVar(&r.delegate, "delegate-url", r.delegate, "The URL the insecure /readyz endpoint proxies to, /livez is proxied to the same host")
+	fs.StringVar(&r.delegateTokenFile, "delegate-token-file", r.delegateTokenFile, "A file containing the bearer token used to authenticate against the delegate, required when anonymous requests are rejected")
 }
 
 // Validate verifies the inputs.
@@ -71,6 +79,20 @@ func (r *readyzOpts) Validate() error {
 
 // Complete fills in missing values before command execution.
 func (r *readyzOpts) Complete() error {
+	delegateURL, err := url.Parse(r.delegate)
+	if err != nil {
+		return err
+	}
+	delegateURL.Path = "/livez"
+	r.livezDelegate = delegateURL.String()
+
+	if len(r.delegateTokenFile) > 0 {
+		token, err := ioutil.ReadFile(r.delegateTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read delegate-token-file: %v", err)
+		}
+		r.delegateToken = strings.TrimSpace(string(token))
+	}
 	return nil
 }
 
@@ -81,28 +103,8 @@ func (r *readyzOpts) Run() error {
 	}}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) {
-		resp, err := client.Get(r.delegate)
-		if err != nil {
-			http.Error(w, "couldn't contact kube-apiserver", http.StatusInternalServerError)
-			klog.Warningf("Failed to get %q: %v", r.delegate, err)
-			return
-		}
-		defer resp.Body.Close()
-
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			http.Error(w, "failed to read response from kube-apiserver", http.StatusInternalServerError)
-			klog.Warningf("Failed to read the response body: %v", err)
-			return
-		}
-
-		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
-	})
+	mux.HandleFunc("/readyz", r.proxyTo(client, r.delegate))
+	mux.HandleFunc("/livez", r.proxyTo(client, r.livezDelegate))
 
 	shutdownCtx, cancel := context.WithCancel(context.Background())
 	shutdownHandler := server.SetupSignalHandler()
@@ -136,6 +138,41 @@ func (r *readyzOpts) Run() error {
 	return err
 }
 
+// proxyTo returns a handler that forwards the status and body of the delegate url.
+func (r *readyzOpts) proxyTo(client *http.Client, delegate string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		delegateReq, err := http.NewRequest(http.MethodGet, delegate, nil)
+		if err != nil {
+			http.Error(w, "couldn't contact kube-apiserver", http.StatusInternalServerError)
+			klog.Warningf("Failed to create a request for %q: %v", delegate, err)
+			return
+		}
+		if len(r.delegateToken) > 0 {
+			delegateReq.Header.Set("Authorization", "Bearer "+r.delegateToken)
+		}
+		resp, err := client.Do(delegateReq)
+		if err != nil {
+			http.Error(w, "couldn't contact kube-apiserver", http.StatusInternalServerError)
+			klog.Warningf("Failed to get %q: %v", delegate, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "failed to read response from kube-apiserver", http.StatusInternalServerError)
+			klog.Warningf("Failed to read the response body: %v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+	}
+}
+
 func permitAddressReuse(network, addr string, conn syscall.RawConn) error {
 	return conn.Control(func(fd uintptr) {
 		if err := syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, unix.SO_REUSEADDR, 1); err != nil {
@@ -0,0 +1,136 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// ActivateRevision replaces the static pod manifests in the pod manifest dir with the ones in the resource dir of the
+// revision. Like the installer of library-go, each manifest is removed and created anew, so that the kubelet gets a
+// create event from inotify and terminates the pod of the previous revision gracefully. The resource dirs of the
+// previous revisions, manifests included, are left intact. With a lock file, it holds the lock of the installers and the startup monitor while
+// replacing the manifests.
+func ActivateRevision(ctx context.Context, resourceDir, podManifestDir, podPrefix, revision, lockFile string) error {
+	dir := revisionDir(resourceDir, podPrefix, revision)
+	manifests, err := revisionManifests(dir, podPrefix)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("revision %s has no static pod manifest in %s", revision, dir)
+	}
+	if err := os.MkdirAll(podManifestDir, 0755); err != nil {
+		return err
+	}
+
+	if len(lockFile) > 0 {
+		klog.Infof("acquiring an exclusive lock on a %s", lockFile)
+		unlock, err := lockExclusive(ctx, lockFile)
+		if err != nil {
+			// the wording of the installer of library-go, the installer metrics classify the error by it
+			return fmt.Errorf("failed to acquire an exclusive lock on %s, due to %v", lockFile, err)
+		}
+		defer unlock()
+	}
+
+	for _, name := range manifests {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(podManifestDir, name)
+		if err := recreateFile(path, data); err != nil {
+			return err
+		}
+		klog.Infof("Activated static pod manifest %q of revision %s", path, revision)
+	}
+	return nil
+}
+
+// revisionManifests returns the static pod manifests in the resource dir of a revision, named like they are named in
+// the pod manifest dir.
+func revisionManifests(dir, podPrefix string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var manifests []string
+	for _, info := range infos {
+		if info.Mode().IsRegular() && (info.Name() == podPrefix+".yaml" || strings.HasSuffix(info.Name(), "-pod.yaml")) {
+			manifests = append(manifests, info.Name())
+		}
+	}
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// recreateFile removes the file if it exists and creates it with the data.
+func recreateFile(path string, data []byte) error {
+	if err := os.Remove(path); err == nil {
+		klog.Infof("Removed existing static pod manifest %q", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// replaceFile writes the file to a hidden temporary file in its dir and renames it over the file.
+func replaceFile(path string, data []byte) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// lockExclusive takes the flock of the file, which the installer of library-go and the startup monitor take too,
+// until the context is done.
+func lockExclusive(ctx context.Context, path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = wait.PollImmediateUntil(100*time.Millisecond, func() (bool, error) {
+		switch err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err {
+		case nil:
+			return true, nil
+		case syscall.EWOULDBLOCK:
+			return false, nil
+		default:
+			return false, err
+		}
+	}, ctx.Done())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
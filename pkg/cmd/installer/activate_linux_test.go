@@ -0,0 +1,73 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestActivateRevisionRecreatesManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-activate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resourceDir, podManifestDir := filepath.Join(dir, "resources"), filepath.Join(dir, "manifests")
+	for revision, data := range map[string]string{"3": manifest("3", "a"), "4": manifest("4", "b")} {
+		revisionDir := filepath.Join(resourceDir, "kube-apiserver-pod-"+revision)
+		if err := os.MkdirAll(revisionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(revisionDir, "kube-apiserver-pod.yaml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ActivateRevision(context.TODO(), resourceDir, podManifestDir, "kube-apiserver-pod", "3", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the kubelet watches the pod manifest dir with inotify
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fd)
+	if _, err := syscall.InotifyAddWatch(fd, podManifestDir, syscall.IN_CREATE|syscall.IN_DELETE|syscall.IN_MOVED_TO|syscall.IN_MODIFY); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ActivateRevision(context.TODO(), resourceDir, podManifestDir, "kube-apiserver-pod", "4", ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(podManifestDir, "kube-apiserver-pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != manifest("4", "b") {
+		t.Errorf("expected the manifest of revision 4, got %s", string(data))
+	}
+
+	buf := make([]byte, 4096)
+	n, err := syscall.Read(fd, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var masks []uint32
+	for offset := 0; offset < n; {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		name := bytes.TrimRight(buf[offset+syscall.SizeofInotifyEvent:offset+syscall.SizeofInotifyEvent+int(event.Len)], "\x00")
+		if string(name) == "kube-apiserver-pod.yaml" && event.Mask&(syscall.IN_CREATE|syscall.IN_DELETE|syscall.IN_MOVED_TO) != 0 {
+			masks = append(masks, event.Mask)
+		}
+		offset += syscall.SizeofInotifyEvent + int(event.Len)
+	}
+	if len(masks) != 2 || masks[0] != syscall.IN_DELETE || masks[1] != syscall.IN_CREATE {
+		t.Errorf("expected the manifest to be deleted and created, got the inotify events %#x", masks)
+	}
+}
@@ -0,0 +1,173 @@
+package installer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// CertValidationOptions make the installer check the certificates of the cert secrets and configmaps before it
+// writes them to the cert dir.
+type CertValidationOptions struct {
+	SkipCertValidation bool
+	now                func() time.Time
+}
+
+func NewCertValidationOptions() *CertValidationOptions {
+	return &CertValidationOptions{now: time.Now}
+}
+
+func (o *CertValidationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.SkipCertValidation, "skip-cert-validation", o.SkipCertValidation, "Write the cert secrets and configmaps to the cert dir without checking their certificates")
+}
+
+// Check parses the certificates of the cert secrets and configmaps of the install options. In secrets every *.crt key
+// has to hold certificates of which the first is valid now and, when there is a *.key key with the same name, matches
+// its private key. In configmaps every *.crt key is a CA bundle, which has to hold at least one valid certificate,
+// bundles keep expired CAs during a rotation. Broken required secrets and configmaps fail the installer, broken
+// optional ones are only reported. Every broken one is reported with a warning event of the installer. Optional
+// secrets and configmaps that do not exist are skipped.
+func (o *CertValidationOptions) Check(ctx context.Context, install *InstallOptions, fetched *Resources) error {
+	if o.SkipCertValidation || len(install.CertDir) == 0 {
+		return nil
+	}
+	type resource struct {
+		kind, name string
+		optional   bool
+	}
+	var resources []resource
+	for _, name := range install.CertSecretNames {
+		resources = append(resources, resource{kind: "secret", name: name})
+	}
+	for _, name := range install.OptionalCertSecretNamePrefixes {
+		resources = append(resources, resource{kind: "secret", name: name, optional: true})
+	}
+	for _, name := range install.CertConfigMapNamePrefixes {
+		resources = append(resources, resource{kind: "configmap", name: name})
+	}
+	for _, name := range install.OptionalCertConfigMapNamePrefixes {
+		resources = append(resources, resource{kind: "configmap", name: name, optional: true})
+	}
+
+	var failures []string
+	var recorder events.Recorder
+	for _, r := range resources {
+		var problems []string
+		if r.kind == "secret" {
+			secret, ok := fetched.Secrets[r.name]
+			if !ok {
+				continue
+			}
+			problems = o.checkSecret(secret)
+		} else {
+			configMap, ok := fetched.ConfigMaps[r.name]
+			if !ok {
+				continue
+			}
+			problems = o.checkConfigMap(configMap)
+		}
+		if len(problems) > 0 && recorder == nil {
+			recorder = newEventRecorder(ctx, install)
+		}
+		for _, problem := range problems {
+			message := fmt.Sprintf("%s %s/%s: %s", r.kind, install.Namespace, r.name, problem)
+			if r.optional {
+				klog.Warningf("Optional %s", message)
+				recorder.Warningf("OptionalCertificateInvalid", "Installing revision %s with the optional %s", install.Revision, message)
+				continue
+			}
+			recorder.Warningf("CertificateInvalid", "Not installing revision %s: %s", install.Revision, message)
+			failures = append(failures, message)
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("invalid certificates: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (o *CertValidationOptions) checkSecret(secret *corev1.Secret) []string {
+	var problems []string
+	for _, key := range sortedKeys(secret.Data) {
+		if !strings.HasSuffix(key, ".crt") {
+			continue
+		}
+		certs, err := certutil.ParseCertsPEM(secret.Data[key])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s does not hold certificates: %v", key, err))
+			continue
+		}
+		if problem := o.checkValidity(certs[0].NotBefore, certs[0].NotAfter); len(problem) > 0 {
+			problems = append(problems, fmt.Sprintf("the certificate %q of %s %s", certs[0].Subject.CommonName, key, problem))
+		}
+		keyKey := strings.TrimSuffix(key, ".crt") + ".key"
+		if privateKey, ok := secret.Data[keyKey]; ok {
+			if _, err := tls.X509KeyPair(secret.Data[key], privateKey); err != nil {
+				problems = append(problems, fmt.Sprintf("%s does not match %s: %v", key, keyKey, err))
+			}
+		}
+	}
+	return problems
+}
+
+func (o *CertValidationOptions) checkConfigMap(configMap *corev1.ConfigMap) []string {
+	data := map[string][]byte{}
+	for key, value := range configMap.Data {
+		data[key] = []byte(value)
+	}
+	var problems []string
+	for _, key := range sortedKeys(data) {
+		if !strings.HasSuffix(key, ".crt") {
+			continue
+		}
+		certs, err := certutil.ParseCertsPEM(data[key])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s does not hold certificates: %v", key, err))
+			continue
+		}
+		var latest time.Time
+		valid := false
+		for _, cert := range certs {
+			if len(o.checkValidity(cert.NotBefore, cert.NotAfter)) == 0 {
+				valid = true
+			}
+			if cert.NotAfter.After(latest) {
+				latest = cert.NotAfter
+			}
+		}
+		if !valid {
+			problems = append(problems, fmt.Sprintf("none of the %d certificates of %s is valid, the last one expired at %s", len(certs), key, latest.UTC().Format(time.RFC3339)))
+		}
+	}
+	return problems
+}
+
+// checkValidity returns why a certificate is not valid now, empty if it is.
+func (o *CertValidationOptions) checkValidity(notBefore, notAfter time.Time) string {
+	now := o.now()
+	switch {
+	case now.After(notAfter):
+		return fmt.Sprintf("expired at %s", notAfter.UTC().Format(time.RFC3339))
+	case now.Before(notBefore):
+		return fmt.Sprintf("is not valid before %s", notBefore.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+func sortedKeys(data map[string][]byte) []string {
+	keys := sets.NewString()
+	for key := range data {
+		keys.Insert(key)
+	}
+	return keys.List()
+}
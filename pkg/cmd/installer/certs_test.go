@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestCertValidation(t *testing.T) {
+	serving, servingKey, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, otherKey, err := certutil.GenerateSelfSignedCertKey("other", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the generated certificates are valid for a year from an hour ago
+	inAYear, yesterday := time.Now().Add(366*24*time.Hour), time.Now().Add(-24*time.Hour)
+
+	tests := []struct {
+		name           string
+		now            time.Time
+		servingKey     []byte
+		optionalCert   []byte
+		expectedErr    string
+		expectedEvents []string
+	}{
+		{name: "valid", now: time.Now(), servingKey: servingKey, optionalCert: other},
+		{name: "key does not match", now: time.Now(), servingKey: otherKey, optionalCert: other, expectedErr: "secret openshift-kube-apiserver/serving-cert: tls.crt does not match tls.key", expectedEvents: []string{"CertificateInvalid"}},
+		{name: "broken optional secret", now: time.Now(), servingKey: servingKey, optionalCert: []byte("rotated"), expectedEvents: []string{"OptionalCertificateInvalid"}},
+		{name: "expired", now: inAYear, servingKey: servingKey, optionalCert: other, expectedErr: "expired at", expectedEvents: []string{"CertificateInvalid", "OptionalCertificateInvalid", "CertificateInvalid"}},
+		{name: "not yet valid", now: yesterday, servingKey: servingKey, optionalCert: other, expectedErr: "is not valid before", expectedEvents: []string{"CertificateInvalid", "OptionalCertificateInvalid", "CertificateInvalid"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			resources := &Resources{
+				Secrets: map[string]*corev1.Secret{
+					"serving-cert":      {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "serving-cert"}, Data: map[string][]byte{"tls.crt": serving, "tls.key": test.servingKey}},
+					"user-serving-cert": {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "user-serving-cert"}, Data: map[string][]byte{"tls.crt": test.optionalCert}},
+				},
+				ConfigMaps: map[string]*corev1.ConfigMap{
+					"client-ca": {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "client-ca"}, Data: map[string]string{"ca-bundle.crt": string(serving)}},
+				},
+			}
+			install := &InstallOptions{
+				KubeClient:                     client,
+				Revision:                       "3",
+				Namespace:                      "openshift-kube-apiserver",
+				CertDir:                        "/etc/kubernetes/static-pod-resources/kube-apiserver-certs",
+				CertSecretNames:                []string{"serving-cert"},
+				OptionalCertSecretNamePrefixes: []string{"user-serving-cert", "missing"},
+				CertConfigMapNamePrefixes:      []string{"client-ca"},
+			}
+			o := NewCertValidationOptions()
+			o.now = func() time.Time { return test.now }
+
+			err := o.Check(context.TODO(), install, resources)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatal(err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected %q, got %v", test.expectedErr, err)
+			}
+			var reasons []string
+			for _, action := range client.Actions() {
+				if create, ok := action.(clienttesting.CreateAction); ok && action.GetResource().Resource == "events" {
+					reasons = append(reasons, create.GetObject().(*corev1.Event).Reason)
+				}
+			}
+			if strings.Join(reasons, ",") != strings.Join(test.expectedEvents, ",") {
+				t.Errorf("expected the events %v, got %v", test.expectedEvents, reasons)
+			}
+		})
+	}
+}
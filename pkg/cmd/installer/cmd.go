@@ -0,0 +1,454 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const (
+	jsonOutput = "json"
+
+	added   = "Added"
+	removed = "Removed"
+	changed = "Changed"
+
+	// dryRunNodeName stands in for the node name of the downward API, a dry-run does not have to run in a pod
+	dryRunNodeName = "dry-run"
+)
+
+// installerOpts are the options of the installer command.
+type installerOpts struct {
+	*InstallOptions
+
+	dryRun        bool
+	output        string
+	fetchWorkers  int
+	podReady      *PodReadyOptions
+	retention     *RetentionOptions
+	certs         *CertValidationOptions
+	substitutions *Substitutions
+	status        *StatusOptions
+	fetch         *FetchOptions
+	out           io.Writer
+
+	// contentDir and contentArchive hold the secrets and configmaps to install when the kube-apiserver is unreachable
+	contentDir     string
+	contentArchive string
+}
+
+// Report is how a revision differs from the revision installed on the node, by file.
+type Report struct {
+	Revision string `json:"revision"`
+	// CurrentRevision is the revision of the static pod manifest on the node, empty when there is none
+	CurrentRevision string     `json:"currentRevision,omitempty"`
+	Files           []FileDiff `json:"files"`
+}
+
+// FileDiff is a file that the revision adds, removes or changes.
+type FileDiff struct {
+	// Path is relative to the install dirs: manifests/ for the static pod manifest, resources/ for the resource dir of
+	// the revision and certs/ for the cert dir.
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	// Diff is the line diff of a changed file, - for the installed and + for the new lines. The content of secrets is
+	// not shown.
+	Diff string `json:"diff,omitempty"`
+}
+
+// NewInstaller creates the installer command. It has the flags of the installer command of library-go, --dry-run, which
+// writes the revision into a temporary dir and prints how it differs from the revision installed on the node instead
+// of installing it, and --wait-for-pod-ready, which only succeeds once the static pod of the revision is ready. With --content-dir or
+// --content-archive it installs the revision from serialized secrets and configmaps when the kube-apiserver is
+// unreachable, e.g. to lay down a known-good revision during disaster recovery.
+func NewInstaller() *cobra.Command {
+	return NewInstallerWithSubstitutions(NewSubstitutions())
+}
+
+// NewInstallerWithSubstitutions creates the installer command with the template variables of the substitution funcs
+// of an operator that embeds it, in addition to the ones of --substitute.
+func NewInstallerWithSubstitutions(substitutions *Substitutions) *cobra.Command {
+	o := &installerOpts{
+		InstallOptions: NewInstallOptions(),
+		fetchWorkers:   DefaultFetchWorkers,
+		podReady:       NewPodReadyOptions(),
+		retention:      NewRetentionOptions(),
+		certs:          NewCertValidationOptions(),
+		substitutions:  substitutions,
+		status:         NewStatusOptions(),
+		fetch:          NewFetchOptions(),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "installer",
+		Short: "Install static pod and related resources",
+		Run: func(cmd *cobra.Command, args []string) {
+			klog.V(1).Info(cmd.Flags())
+			klog.V(1).Info(spew.Sdump(o))
+
+			o.out = cmd.OutOrStdout()
+			if err := o.Complete(); err != nil {
+				klog.Exit(err)
+			}
+			if err := o.Validate(); err != nil {
+				klog.Exit(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.TODO(), o.Timeout)
+			defer cancel()
+			err := o.Run(ctx)
+			if err == nil {
+				// the wait has its own timeout, the one of the options is for fetching the revision
+				endWait := o.status.Phase(PhaseWaitForPodReady)
+				err = o.podReady.Wait(context.TODO(), o.InstallOptions)
+				endWait()
+			}
+			if writeErr := o.status.Write(o.InstallOptions, err); writeErr != nil {
+				klog.Warningf("Failed to write the status: %v", writeErr)
+			}
+			if err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+	cmd.AddCommand(NewVerifyCommand())
+	cmd.AddCommand(NewRollbackCommand())
+
+	return cmd
+}
+
+func (o *installerOpts) AddFlags(fs *pflag.FlagSet) {
+	o.InstallOptions.AddFlags(fs)
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print how the revision differs from the revision installed on the node instead of installing it")
+	fs.StringVar(&o.output, "output", o.output, "Print the dry-run report as, one of: json")
+	fs.IntVar(&o.fetchWorkers, "fetch-workers", o.fetchWorkers, "How many secrets and configmaps are fetched at the same time")
+	o.podReady.AddFlags(fs)
+	o.retention.AddFlags(fs)
+	o.certs.AddFlags(fs)
+	o.substitutions.AddFlags(fs)
+	o.status.AddFlags(fs)
+	o.fetch.AddFlags(fs)
+	fs.StringVar(&o.contentDir, "content-dir", o.contentDir, "A dir of secrets and configmaps serialized as <namespace>/{secrets,configmaps}/<name>.yaml to install when the kube-apiserver is unreachable")
+	fs.StringVar(&o.contentArchive, "content-archive", o.contentArchive, "A tar.gz archive of a --content-dir to install when the kube-apiserver is unreachable")
+}
+
+func (o *installerOpts) Complete() error {
+	if err := o.InstallOptions.Complete(); err != nil {
+		return err
+	}
+	if o.dryRun && len(o.NodeName) == 0 {
+		o.NodeName = dryRunNodeName
+	}
+	return nil
+}
+
+// Validate verifies the inputs.
+func (o *installerOpts) Validate() error {
+	if err := o.InstallOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.output) > 0 && o.output != jsonOutput {
+		return fmt.Errorf("--output must be %q, got %q", jsonOutput, o.output)
+	}
+	if len(o.output) > 0 && !o.dryRun {
+		return fmt.Errorf("--output is only supported with --dry-run")
+	}
+	if o.fetchWorkers < 1 {
+		return fmt.Errorf("--fetch-workers must be at least 1")
+	}
+	if o.dryRun && o.podReady.WaitForPodReady {
+		return fmt.Errorf("--wait-for-pod-ready is not supported with --dry-run")
+	}
+	if err := o.podReady.Validate(); err != nil {
+		return err
+	}
+	if err := o.retention.Validate(); err != nil {
+		return err
+	}
+	if err := o.substitutions.Validate(); err != nil {
+		return err
+	}
+	if err := o.fetch.Validate(); err != nil {
+		return err
+	}
+	if len(o.contentDir) > 0 && len(o.contentArchive) > 0 {
+		return fmt.Errorf("--content-dir and --content-archive are mutually exclusive")
+	}
+	return nil
+}
+
+// Run installs the revision and records the digests of its files, or prints how it differs from the installed one in
+// dry-run mode. A revision that is installed with the recorded digests is not written again, e.g. when the installer
+// is retried after waiting for the static pod.
+func (o *installerOpts) Run(ctx context.Context) error {
+	if !o.dryRun && isInstalled(o.InstallOptions) {
+		klog.Infof("Revision %s is installed and unchanged, skipping", o.Revision)
+		return nil
+	}
+	source, cleanup, err := o.contentSource(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	source = NewTimedContentSource(source, o.status)
+	values, err := o.substitutions.Resolve(ctx, o.InstallOptions)
+	if err != nil {
+		return err
+	}
+	if len(values) > 0 {
+		source = NewSubstitutingContentSource(source, values)
+	}
+	resources, err := FetchResources(ctx, o.InstallOptions, source, o.fetchWorkers)
+	if err != nil {
+		return err
+	}
+	endCheck := o.status.Phase(PhaseCheckCerts)
+	err = o.certs.Check(ctx, o.InstallOptions, resources)
+	endCheck()
+	if err != nil {
+		return err
+	}
+	if !o.dryRun {
+		endRemove := o.status.Phase(PhaseRemoveRevisions)
+		err = o.retention.Apply(ctx, o.InstallOptions)
+		endRemove()
+		if err != nil {
+			return err
+		}
+		if err := InstallRevision(ctx, o.InstallOptions, resources, o.status); err != nil {
+			return err
+		}
+		return RecordInstalledRevision(o.InstallOptions)
+	}
+
+	dir, err := ioutil.TempDir("", "installer-dry-run")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// the revision is written and activated in the temporary dir, without the events of an installation
+	dryRun := *o.InstallOptions
+	dryRun.ResourceDir = filepath.Join(dir, "resources")
+	dryRun.PodManifestDir = filepath.Join(dir, "manifests")
+	if len(o.CertDir) > 0 {
+		dryRun.CertDir = filepath.Join(dir, "certs")
+	}
+	if err := WriteRevision(&dryRun, resources); err != nil {
+		return err
+	}
+	if err := ActivateRevision(ctx, dryRun.ResourceDir, dryRun.PodManifestDir, dryRun.PodConfigMapNamePrefix, dryRun.Revision, ""); err != nil {
+		return err
+	}
+
+	report, err := newReport(o.InstallOptions, &dryRun)
+	if err != nil {
+		return err
+	}
+	if o.output == jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+	return printReport(o.out, report)
+}
+
+// contentSource returns the kube-apiserver as the source of the secrets and configmaps, or the content dir or archive
+// when one is given and the kube-apiserver does not answer. The installation is offline then.
+func (o *installerOpts) contentSource(ctx context.Context) (ContentSource, func(), error) {
+	noCleanup := func() {}
+	if (len(o.contentDir) == 0 && len(o.contentArchive) == 0) || isAPIReachable(ctx, o.KubeClient, o.Namespace) {
+		defer o.status.Phase(PhaseListResources)()
+		return o.fetch.ContentSource(ctx, o.KubeClient, o.InstallOptions), noCleanup, nil
+	}
+	o.Offline = true
+	if len(o.contentDir) > 0 {
+		klog.Warningf("The kube-apiserver is unreachable, installing revision %s from %s", o.Revision, o.contentDir)
+		return NewDirContentSource(o.contentDir), noCleanup, nil
+	}
+
+	klog.Warningf("The kube-apiserver is unreachable, installing revision %s from %s", o.Revision, o.contentArchive)
+	dir, err := ioutil.TempDir("", "installer-content")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	if err := ExtractContentArchive(o.contentArchive, dir); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return NewDirContentSource(dir), cleanup, nil
+}
+
+// installDir is a dir the installer writes to, on the node and in the dry-run.
+type installDir struct {
+	// prefix is the prefix of the paths of its files in the report
+	prefix            string
+	current, revision string
+	// reportRemovedFiles is false for dirs that the installer only adds to
+	reportRemovedFiles bool
+}
+
+// newReport compares what the dry-run wrote with the manifest, the resource dir of the installed revision and the
+// cert dir of the node. The cert dir is shared by all revisions and the installer only adds to it, so files that are
+// only on the node are not reported as removed.
+func newReport(installed, dryRun *InstallOptions) (*Report, error) {
+	manifestFileName := installed.PodConfigMapNamePrefix + ".yaml"
+	report := &Report{Revision: dryRun.Revision, Files: []FileDiff{}}
+
+	currentManifest, err := ioutil.ReadFile(filepath.Join(installed.PodManifestDir, manifestFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var currentResourceDir string
+	if len(currentManifest) > 0 {
+		pod, err := resourceread.ReadPodV1(currentManifest)
+		if err != nil {
+			return nil, fmt.Errorf("the installed static pod manifest does not decode: %v", err)
+		}
+		report.CurrentRevision = pod.Labels["revision"]
+		currentResourceDir = filepath.Join(installed.ResourceDir, fmt.Sprintf("%s-%s", installed.PodConfigMapNamePrefix, report.CurrentRevision))
+	}
+
+	dirs := []installDir{
+		{prefix: "manifests", current: installed.PodManifestDir, revision: dryRun.PodManifestDir},
+		{prefix: "resources", current: currentResourceDir, revision: filepath.Join(dryRun.ResourceDir, fmt.Sprintf("%s-%s", dryRun.PodConfigMapNamePrefix, dryRun.Revision)), reportRemovedFiles: true},
+	}
+	if len(dryRun.CertDir) > 0 {
+		dirs = append(dirs, installDir{prefix: "certs", current: installed.CertDir, revision: dryRun.CertDir})
+	}
+	for _, dir := range dirs {
+		currentFiles, err := listFiles(dir.current)
+		if err != nil {
+			return nil, err
+		}
+		revisionFiles, err := listFiles(dir.revision)
+		if err != nil {
+			return nil, err
+		}
+		if dir.prefix == "manifests" {
+			// the manifest dir holds the static pods of other components too
+			currentFiles = currentFiles.Intersection(sets.NewString(manifestFileName))
+		}
+		if dir.prefix == "resources" {
+			// the digests are recorded after the installation, the dry-run has none
+			currentFiles.Delete(filepath.Base(installedRevisionFile(installed.ResourceDir, installed.PodConfigMapNamePrefix, report.CurrentRevision)))
+		}
+		for _, file := range currentFiles.Union(revisionFiles).List() {
+			diff := FileDiff{Path: filepath.Join(dir.prefix, file)}
+			switch {
+			case !revisionFiles.Has(file) && !dir.reportRemovedFiles:
+				continue
+			case !revisionFiles.Has(file):
+				diff.Change = removed
+			case !currentFiles.Has(file):
+				diff.Change = added
+			default:
+				current, err := readFile(filepath.Join(dir.current, file), manifestFileName)
+				if err != nil {
+					return nil, err
+				}
+				revision, err := readFile(filepath.Join(dir.revision, file), manifestFileName)
+				if err != nil {
+					return nil, err
+				}
+				if bytes.Equal(current, revision) {
+					continue
+				}
+				diff.Change = changed
+				if !isSecret(file) {
+					diff.Diff = cmp.Diff(strings.Split(string(current), "\n"), strings.Split(string(revision), "\n"))
+				}
+			}
+			report.Files = append(report.Files, diff)
+		}
+	}
+	sort.SliceStable(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+	return report, nil
+}
+
+// listFiles returns the regular files in the dir relative to it, none when the dir does not exist.
+func listFiles(dir string) (sets.String, error) {
+	files := sets.NewString()
+	if len(dir) == 0 {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files.Insert(rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readFile reads a file for the comparison. The installer gives every static pod manifest a new uid, it is dropped
+// from the manifests so that only real changes are reported.
+func readFile(path, manifestFileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil || filepath.Base(path) != manifestFileName {
+		return data, err
+	}
+	pod, err := resourceread.ReadPodV1(data)
+	if err != nil {
+		return data, nil
+	}
+	pod.UID = ""
+	return []byte(resourceread.WritePodV1OrDie(pod)), nil
+}
+
+func isSecret(file string) bool {
+	return strings.HasPrefix(file, "secrets"+string(filepath.Separator))
+}
+
+func printReport(out io.Writer, report *Report) error {
+	current := report.CurrentRevision
+	if len(current) == 0 {
+		current = "none"
+	}
+	if _, err := fmt.Fprintf(out, "Revision %s compared to the installed revision %s: %d files differ\n", report.Revision, current, len(report.Files)); err != nil {
+		return err
+	}
+	for _, file := range report.Files {
+		if _, err := fmt.Fprintf(out, "%s %s\n", file.Change, file.Path); err != nil {
+			return err
+		}
+		if len(file.Diff) > 0 {
+			if _, err := fmt.Fprint(out, file.Diff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package installer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func manifest(revision, uid string) string {
+	return `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-apiserver
+  namespace: openshift-kube-apiserver
+  uid: ` + uid + `
+  labels:
+    revision: "` + revision + `"
+`
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	installed := &InstallOptions{
+		Revision:               "8",
+		PodConfigMapNamePrefix: "kube-apiserver-pod",
+		ResourceDir:            filepath.Join(dir, "node", "resources"),
+		PodManifestDir:         filepath.Join(dir, "node", "manifests"),
+		CertDir:                filepath.Join(dir, "node", "resources", "kube-apiserver-certs"),
+	}
+	dryRun := *installed
+	dryRun.ResourceDir = filepath.Join(dir, "dry-run", "resources")
+	dryRun.PodManifestDir = filepath.Join(dir, "dry-run", "manifests")
+	dryRun.CertDir = filepath.Join(dir, "dry-run", "certs")
+
+	writeFiles(t, filepath.Join(dir, "node"), map[string]string{
+		"manifests/kube-apiserver-pod.yaml":                                        manifest("7", "a"),
+		"manifests/etcd-pod.yaml":                                                  "etcd",
+		"resources/kube-apiserver-pod-7/kube-apiserver-pod.yaml":                   manifest("7", "a"),
+		"resources/kube-apiserver-pod-7/installed-revision-7.json":                 "{}",
+		"resources/kube-apiserver-pod-7/configmaps/config/config.yaml":             "a: 1\nb: 2\n",
+		"resources/kube-apiserver-pod-7/configmaps/oauth-metadata/oauth.json":      "{}",
+		"resources/kube-apiserver-pod-7/secrets/etcd-client/tls.key":               "old key",
+		"resources/kube-apiserver-certs/secrets/aggregator-client/tls.crt":         "crt",
+		"resources/kube-apiserver-certs/configmaps/trusted-ca-bundle/ca.crt":       "ca",
+		"resources/kube-apiserver-certs/configmaps/client-ca/ca-bundle.crt":        "ca",
+		"resources/kube-apiserver-certs/configmaps/unrelated-bundle/ca-bundle.crt": "ca",
+	})
+	writeFiles(t, filepath.Join(dir, "dry-run"), map[string]string{
+		"manifests/kube-apiserver-pod.yaml":                                manifest("7", "b"),
+		"resources/kube-apiserver-pod-8/kube-apiserver-pod.yaml":           manifest("7", "b"),
+		"resources/kube-apiserver-pod-8/configmaps/config/config.yaml":     "a: 1\nb: 3\n",
+		"resources/kube-apiserver-pod-8/secrets/etcd-client/tls.key":       "new key",
+		"resources/kube-apiserver-pod-8/secrets/localhost-serving/tls.crt": "crt",
+		"certs/secrets/aggregator-client/tls.crt":                          "crt",
+		"certs/configmaps/trusted-ca-bundle/ca.crt":                        "new ca",
+		"certs/configmaps/client-ca/ca-bundle.crt":                         "ca",
+	})
+
+	report, err := newReport(installed, &dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Revision != "8" || report.CurrentRevision != "7" {
+		t.Errorf("unexpected revisions %s and %s", report.Revision, report.CurrentRevision)
+	}
+	var changes []string
+	for _, file := range report.Files {
+		changes = append(changes, file.Change+" "+file.Path)
+	}
+	expected := []string{
+		"Changed certs/configmaps/trusted-ca-bundle/ca.crt",
+		"Changed resources/configmaps/config/config.yaml",
+		"Removed resources/configmaps/oauth-metadata/oauth.json",
+		"Changed resources/secrets/etcd-client/tls.key",
+		"Added resources/secrets/localhost-serving/tls.crt",
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Fatalf("expected the uid of the manifests to be ignored and the changes %v, got %v", expected, changes)
+	}
+	for _, file := range report.Files {
+		switch file.Path {
+		case "resources/configmaps/config/config.yaml":
+			if !strings.Contains(file.Diff, `"b: 2"`) || !strings.Contains(file.Diff, `"b: 3"`) {
+				t.Errorf("unexpected diff %s", file.Diff)
+			}
+		case "resources/secrets/etcd-client/tls.key":
+			if len(file.Diff) > 0 {
+				t.Errorf("expected no diff of a secret, got %s", file.Diff)
+			}
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if err := printReport(out, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Revision 8 compared to the installed revision 7: 5 files differ\n") {
+		t.Errorf("unexpected report %s", out.String())
+	}
+}
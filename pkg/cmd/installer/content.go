@@ -0,0 +1,156 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// apiProbeTimeout is how long the installer waits for the kube-apiserver before it installs from the content dir.
+const apiProbeTimeout = 10 * time.Second
+
+// ContentSource provides the secrets and configmaps of a revision. Missing ones are returned as not found errors of
+// the API, so that the installer still skips optional and fails on required ones.
+type ContentSource interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+}
+
+// NewAPIContentSource returns a content source that gets the secrets and configmaps from the kube-apiserver.
+func NewAPIContentSource(client kubernetes.Interface) ContentSource {
+	return apiContentSource{client: client}
+}
+
+type apiContentSource struct {
+	client kubernetes.Interface
+}
+
+func (s apiContentSource) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	return s.client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (s apiContentSource) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	return s.client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// NewDirContentSource returns a content source that reads the secrets and configmaps serialized as yaml or json, as
+// written by oc get -o yaml, from <dir>/<namespace>/secrets/<name>.yaml and <dir>/<namespace>/configmaps/<name>.yaml.
+func NewDirContentSource(dir string) ContentSource {
+	return dirContentSource{dir: dir}
+}
+
+type dirContentSource struct {
+	dir string
+}
+
+func (s dirContentSource) GetSecret(_ context.Context, namespace, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := s.read(namespace, "secrets", name, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (s dirContentSource) GetConfigMap(_ context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := s.read(namespace, "configmaps", name, configMap); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+func (s dirContentSource) read(namespace, resource, name string, into metav1.Object) error {
+	for _, ext := range []string{".yaml", ".json"} {
+		path := filepath.Join(s.dir, namespace, resource, name+ext)
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, into); err != nil {
+			return fmt.Errorf("invalid %s: %v", path, err)
+		}
+		if into.GetName() != name || into.GetNamespace() != namespace {
+			return fmt.Errorf("%s holds %s/%s instead of %s/%s", path, into.GetNamespace(), into.GetName(), namespace, name)
+		}
+		return nil
+	}
+	return apierrors.NewNotFound(corev1.Resource(resource), name)
+}
+
+// ExtractContentArchive extracts a tar.gz archive of a content dir into the dir.
+func ExtractContentArchive(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", archive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", archive, err)
+		}
+		name := filepath.Clean(header.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid %s: %s is outside of the archive", archive, header.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// isAPIReachable returns false when the kube-apiserver does not answer. Any answer, even an error of the API, means
+// that it is reachable.
+func isAPIReachable(ctx context.Context, client kubernetes.Interface, namespace string) bool {
+	ctx, cancel := context.WithTimeout(ctx, apiProbeTimeout)
+	defer cancel()
+	_, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	var status apierrors.APIStatus
+	return err == nil || errors.As(err, &status)
+}
@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestInstallFromContentArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := map[string]string{
+		"openshift-kube-apiserver/configmaps/kube-apiserver-pod-7.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kube-apiserver-pod-7
+  namespace: openshift-kube-apiserver
+data:
+  pod.yaml: |
+` + indent(manifest("REVISION", "a")),
+		"openshift-kube-apiserver/configmaps/config-7.json": `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config-7", "namespace": "openshift-kube-apiserver"}, "data": {"config.yaml": "a: 1"}}`,
+		"openshift-kube-apiserver/secrets/etcd-client-7.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: etcd-client-7
+  namespace: openshift-kube-apiserver
+data:
+  tls.key: a2V5
+`,
+	}
+	archive := filepath.Join(dir, "content.tar.gz")
+	writeArchive(t, archive, content)
+
+	// the kube-apiserver does not answer
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("*", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("dial tcp 10.0.0.1:6443: connect: connection refused")
+	})
+	o := &installerOpts{
+		InstallOptions: &InstallOptions{
+			KubeClient:                    client,
+			Revision:                      "7",
+			NodeName:                      "master-0",
+			Namespace:                     "openshift-kube-apiserver",
+			PodConfigMapNamePrefix:        "kube-apiserver-pod",
+			ConfigMapNamePrefixes:         []string{"kube-apiserver-pod", "config"},
+			OptionalConfigMapNamePrefixes: []string{"oauth-metadata"},
+			SecretNamePrefixes:            []string{"etcd-client"},
+			ResourceDir:                   filepath.Join(dir, "resources"),
+			PodManifestDir:                filepath.Join(dir, "manifests"),
+		},
+		fetchWorkers:   2,
+		podReady:       NewPodReadyOptions(),
+		retention:      NewRetentionOptions(),
+		certs:          NewCertValidationOptions(),
+		substitutions:  NewSubstitutions(),
+		contentArchive: archive,
+	}
+	if err := o.Run(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string]string{
+		"resources/kube-apiserver-pod-7/configmaps/config/config.yaml": "a: 1",
+		"resources/kube-apiserver-pod-7/secrets/etcd-client/tls.key":   "key",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %q in %s, got %q", expected, file, string(data))
+		}
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "manifests", "kube-apiserver-pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"revision":"7"`) {
+		t.Errorf("expected the manifest of revision 7, got %s", string(data))
+	}
+	if !isInstalled(o.InstallOptions) {
+		t.Error("expected the digests of the revision to be recorded")
+	}
+	// the events of the offline installation are discarded instead of being sent to the kube-apiserver
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource == "events" {
+			t.Errorf("expected no events to be sent, got %v", action)
+		}
+	}
+}
+
+func TestDirContentSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"openshift-kube-apiserver/configmaps/config-7.yaml": "metadata:\n  name: config-6\n  namespace: openshift-kube-apiserver\n",
+	})
+	source := NewDirContentSource(dir)
+
+	if _, err := source.GetConfigMap(context.TODO(), "openshift-kube-apiserver", "config-7"); err == nil || !strings.Contains(err.Error(), "instead of openshift-kube-apiserver/config-7") {
+		t.Errorf("expected a renamed configmap to be rejected, got %v", err)
+	}
+	if _, err := source.GetSecret(context.TODO(), "openshift-kube-apiserver", "etcd-client-7"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing secret to be not found, got %v", err)
+	}
+
+	archive := filepath.Join(dir, "content.tar.gz")
+	writeArchive(t, archive, map[string]string{"../escape.yaml": ""})
+	if err := ExtractContentArchive(archive, filepath.Join(dir, "extracted")); err == nil || !strings.Contains(err.Error(), "outside of the archive") {
+		t.Errorf("expected a path outside of the archive to be rejected, got %v", err)
+	}
+}
+
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n    ") + "\n"
+}
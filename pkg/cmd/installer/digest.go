@@ -0,0 +1,157 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const (
+	modified   = "Modified"
+	missing    = "Missing"
+	unexpected = "Unexpected"
+)
+
+// InstalledRevision holds the digests of the files the installer wrote for a revision. It is stored as
+// installed-revision-<revision>.json in the resource dir of the revision, so that it is pruned with it. The cert dir is
+// not part of it, the cert syncer updates the certs in place.
+type InstalledRevision struct {
+	Revision string `json:"revision"`
+	// Files are the sha256 digests of the files in the resource dir of the revision, by path relative to it
+	Files map[string]string `json:"files"`
+	// Manifest is the sha256 digest of the static pod manifest in the pod manifest dir
+	Manifest string `json:"manifest"`
+}
+
+// VerifyReport is how the files of a revision on the node differ from the digests the installer recorded.
+type VerifyReport struct {
+	Revision string `json:"revision"`
+	// ManifestRevision is the revision of the static pod manifest, its digest is only checked when it is the revision
+	ManifestRevision string  `json:"manifestRevision,omitempty"`
+	Drift            []Drift `json:"drift"`
+}
+
+// Drift is a file that was modified, removed or added after the installer wrote the revision.
+type Drift struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+}
+
+func revisionDir(resourceDir, podPrefix, revision string) string {
+	return filepath.Join(resourceDir, fmt.Sprintf("%s-%s", podPrefix, revision))
+}
+
+func installedRevisionFile(resourceDir, podPrefix, revision string) string {
+	return filepath.Join(revisionDir(resourceDir, podPrefix, revision), fmt.Sprintf("installed-revision-%s.json", revision))
+}
+
+// RecordInstalledRevision writes the digests of the revision the installer just wrote.
+func RecordInstalledRevision(o *InstallOptions) error {
+	dir := revisionDir(o.ResourceDir, o.PodConfigMapNamePrefix, o.Revision)
+	files, err := digestFiles(dir, filepath.Base(installedRevisionFile(o.ResourceDir, o.PodConfigMapNamePrefix, o.Revision)))
+	if err != nil {
+		return err
+	}
+	manifest, err := digestFile(filepath.Join(o.PodManifestDir, o.PodConfigMapNamePrefix+".yaml"))
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(InstalledRevision{Revision: o.Revision, Files: files, Manifest: manifest}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(installedRevisionFile(o.ResourceDir, o.PodConfigMapNamePrefix, o.Revision), data, 0644)
+}
+
+// VerifyInstalledRevision recomputes the digests of the files of the revision and compares them with the recorded ones.
+// It returns an error when the revision has no recorded digests.
+func VerifyInstalledRevision(resourceDir, podManifestDir, podPrefix, revision string) (*VerifyReport, error) {
+	recordFile := installedRevisionFile(resourceDir, podPrefix, revision)
+	data, err := ioutil.ReadFile(recordFile)
+	if err != nil {
+		return nil, err
+	}
+	installed := InstalledRevision{}
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", recordFile, err)
+	}
+
+	report := &VerifyReport{Revision: revision, Drift: []Drift{}}
+	files, err := digestFiles(revisionDir(resourceDir, podPrefix, revision), filepath.Base(recordFile))
+	if err != nil {
+		return nil, err
+	}
+	for path, digest := range installed.Files {
+		switch current, ok := files[path]; {
+		case !ok:
+			report.Drift = append(report.Drift, Drift{Path: path, Change: missing})
+		case current != digest:
+			report.Drift = append(report.Drift, Drift{Path: path, Change: modified})
+		}
+	}
+	for path := range files {
+		if _, ok := installed.Files[path]; !ok {
+			report.Drift = append(report.Drift, Drift{Path: path, Change: unexpected})
+		}
+	}
+
+	manifestPath := filepath.Join(podManifestDir, podPrefix+".yaml")
+	manifest, err := ioutil.ReadFile(manifestPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(manifest) > 0 {
+		if pod, err := resourceread.ReadPodV1(manifest); err != nil {
+			report.Drift = append(report.Drift, Drift{Path: manifestPath, Change: modified})
+		} else if report.ManifestRevision = pod.Labels["revision"]; report.ManifestRevision == revision && digest(manifest) != installed.Manifest {
+			report.Drift = append(report.Drift, Drift{Path: manifestPath, Change: modified})
+		}
+	}
+	sort.Slice(report.Drift, func(i, j int) bool { return report.Drift[i].Path < report.Drift[j].Path })
+	return report, nil
+}
+
+// isInstalled returns true when the revision was installed with recorded digests, its static pod manifest is the one
+// in the pod manifest dir and nothing drifted. The installer does not have to write it again then.
+func isInstalled(o *InstallOptions) bool {
+	report, err := VerifyInstalledRevision(o.ResourceDir, o.PodManifestDir, o.PodConfigMapNamePrefix, o.Revision)
+	return err == nil && report.ManifestRevision == o.Revision && len(report.Drift) == 0
+}
+
+// digestFiles returns the digests of the regular files in the dir by path relative to it, without the skipped file.
+func digestFiles(dir, skip string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == skip {
+			return err
+		}
+		files[rel], err = digestFile(path)
+		return err
+	})
+	return files, err
+}
+
+func digestFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return digest(data), nil
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
@@ -0,0 +1,75 @@
+package installer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstalledRevision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installed-revision")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	o := &InstallOptions{
+		Revision:               "5",
+		PodConfigMapNamePrefix: "kube-apiserver-pod",
+		ResourceDir:            filepath.Join(dir, "resources"),
+		PodManifestDir:         filepath.Join(dir, "manifests"),
+	}
+	writeFiles(t, dir, map[string]string{
+		"manifests/kube-apiserver-pod.yaml":                            manifest("5", "a"),
+		"resources/kube-apiserver-pod-5/kube-apiserver-pod.yaml":       manifest("5", "a"),
+		"resources/kube-apiserver-pod-5/configmaps/config/config.yaml": "a: 1\n",
+		"resources/kube-apiserver-pod-5/secrets/etcd-client/tls.key":   "key",
+	})
+	if err := RecordInstalledRevision(o); err != nil {
+		t.Fatal(err)
+	}
+	if !isInstalled(o) {
+		t.Fatal("expected the recorded revision to be installed")
+	}
+
+	// the startup monitor fell back to the previous revision, the installer has to write the manifest again
+	writeFiles(t, dir, map[string]string{"manifests/kube-apiserver-pod.yaml": manifest("4", "b")})
+	if isInstalled(o) {
+		t.Error("expected the revision to be installed again after a fallback")
+	}
+	report, err := VerifyInstalledRevision(o.ResourceDir, o.PodManifestDir, o.PodConfigMapNamePrefix, o.Revision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ManifestRevision != "4" || len(report.Drift) != 0 {
+		t.Errorf("expected no drift of the resources while another revision runs, got %+v", report)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"manifests/kube-apiserver-pod.yaml":                            manifest("5", "c"),
+		"resources/kube-apiserver-pod-5/configmaps/config/config.yaml": "a: 2\n",
+		"resources/kube-apiserver-pod-5/configmaps/extra/extra.yaml":   "",
+	})
+	if err := os.Remove(filepath.Join(o.ResourceDir, "kube-apiserver-pod-5", "secrets", "etcd-client", "tls.key")); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	verify := &verifyOpts{revision: "5", podPrefix: "kube-apiserver-pod", resourceDir: o.ResourceDir, podManifestDir: o.PodManifestDir, out: out}
+	if err := verify.Run(); err == nil {
+		t.Fatal("expected the drift to fail the verification")
+	}
+	expected := []string{
+		"Revision 5, the static pod manifest runs revision 5: 4 files drifted",
+		"Modified " + filepath.Join(o.PodManifestDir, "kube-apiserver-pod.yaml"),
+		"Modified configmaps/config/config.yaml",
+		"Unexpected configmaps/extra/extra.yaml",
+		"Missing secrets/etcd-client/tls.key",
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); !reflect.DeepEqual(expected, lines) {
+		t.Errorf("expected %v, got %v", expected, lines)
+	}
+}
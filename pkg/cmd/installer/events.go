@@ -0,0 +1,105 @@
+package installer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/retry"
+)
+
+// newEventRecorder returns a recorder of events for the controller of the installer pod or the namespace. Getting the
+// controller is retried on connection errors. The events of an offline installation are discarded.
+func newEventRecorder(ctx context.Context, install *InstallOptions) events.Recorder {
+	if install.Offline {
+		eventTarget := &corev1.ObjectReference{Kind: "Namespace", Namespace: install.Namespace, Name: install.Namespace, APIVersion: "v1"}
+		return events.NewRecorder(discardEvents{}, "static-pod-installer", eventTarget)
+	}
+
+	var eventTarget *corev1.ObjectReference
+	err := retry.RetryOnConnectionErrors(ctx, func(context.Context) (bool, error) {
+		var clientErr error
+		eventTarget, clientErr = events.GetControllerReferenceForCurrentPod(install.KubeClient, install.Namespace, nil)
+		if clientErr != nil {
+			return false, clientErr
+		}
+		return true, nil
+	})
+	if err != nil {
+		klog.Warningf("unable to get owner reference (falling back to namespace): %v", err)
+	}
+	return events.NewRecorder(install.KubeClient.CoreV1().Events(install.Namespace), "static-pod-installer", eventTarget)
+}
+
+// discardEvents is an event client without a kube-apiserver. It logs the events it creates and drops them, there are
+// no events to read.
+type discardEvents struct{}
+
+var _ corev1client.EventInterface = discardEvents{}
+
+func (discardEvents) Create(_ context.Context, event *corev1.Event, _ metav1.CreateOptions) (*corev1.Event, error) {
+	klog.Infof("Event(%s): type: %q reason: %q %s", event.InvolvedObject.Name, event.Type, event.Reason, event.Message)
+	return event, nil
+}
+
+func (discardEvents) Update(_ context.Context, event *corev1.Event, _ metav1.UpdateOptions) (*corev1.Event, error) {
+	return event, nil
+}
+
+func (discardEvents) Delete(context.Context, string, metav1.DeleteOptions) error {
+	return nil
+}
+
+func (discardEvents) DeleteCollection(context.Context, metav1.DeleteOptions, metav1.ListOptions) error {
+	return nil
+}
+
+func (discardEvents) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Event, error) {
+	return nil, apierrors.NewNotFound(corev1.Resource("events"), name)
+}
+
+func (discardEvents) List(context.Context, metav1.ListOptions) (*corev1.EventList, error) {
+	return &corev1.EventList{}, nil
+}
+
+func (discardEvents) Watch(context.Context, metav1.ListOptions) (watch.Interface, error) {
+	return watch.NewEmptyWatch(), nil
+}
+
+func (discardEvents) Patch(_ context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*corev1.Event, error) {
+	return nil, apierrors.NewNotFound(corev1.Resource("events"), name)
+}
+
+func (discardEvents) Apply(context.Context, *corev1ac.EventApplyConfiguration, metav1.ApplyOptions) (*corev1.Event, error) {
+	return &corev1.Event{}, nil
+}
+
+func (d discardEvents) CreateWithEventNamespace(event *corev1.Event) (*corev1.Event, error) {
+	return d.Create(context.TODO(), event, metav1.CreateOptions{})
+}
+
+func (discardEvents) UpdateWithEventNamespace(event *corev1.Event) (*corev1.Event, error) {
+	return event, nil
+}
+
+func (discardEvents) PatchWithEventNamespace(event *corev1.Event, _ []byte) (*corev1.Event, error) {
+	return event, nil
+}
+
+func (discardEvents) Search(*runtime.Scheme, runtime.Object) (*corev1.EventList, error) {
+	return &corev1.EventList{}, nil
+}
+
+func (discardEvents) GetFieldSelector(*string, *string, *string, *string) fields.Selector {
+	return fields.Everything()
+}
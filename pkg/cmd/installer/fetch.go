@@ -0,0 +1,126 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/retry"
+)
+
+// DefaultFetchWorkers is how many secrets and configmaps the installer gets at the same time by default.
+const DefaultFetchWorkers = 5
+
+// Resources are the secrets and configmaps of a revision and of the certs by name, as the installer writes them.
+// Optional ones that do not exist are missing.
+type Resources struct {
+	Secrets    map[string]*corev1.Secret
+	ConfigMaps map[string]*corev1.ConfigMap
+}
+
+// FetchResources gets the secrets and configmaps of the revision and of the certs from the content source with up to
+// workers requests at the same time, before anything is written. Getting them one after another takes tens of seconds
+// with many cert configmaps. Every get retries on connection errors. Optional ones that do not exist are left out,
+// missing required ones and other errors of all the resources are returned together.
+func FetchResources(ctx context.Context, o *InstallOptions, source ContentSource, workers int) (*Resources, error) {
+	podConfigMapName := o.nameFor(o.PodConfigMapNamePrefix)
+	secretNames, configMapNames := sets.NewString(), sets.NewString(podConfigMapName)
+	required := sets.NewString("configmap/" + podConfigMapName)
+	for _, prefix := range o.SecretNamePrefixes {
+		required.Insert("secret/" + o.nameFor(prefix))
+	}
+	for _, prefix := range o.ConfigMapNamePrefixes {
+		required.Insert("configmap/" + o.nameFor(prefix))
+	}
+	for _, prefix := range append(append([]string{}, o.SecretNamePrefixes...), o.OptionalSecretNamePrefixes...) {
+		secretNames.Insert(o.nameFor(prefix))
+	}
+	for _, prefix := range append(append([]string{}, o.ConfigMapNamePrefixes...), o.OptionalConfigMapNamePrefixes...) {
+		configMapNames.Insert(o.nameFor(prefix))
+	}
+	if len(o.CertDir) > 0 {
+		for _, name := range o.CertSecretNames {
+			required.Insert("secret/" + name)
+		}
+		for _, name := range o.CertConfigMapNamePrefixes {
+			required.Insert("configmap/" + name)
+		}
+		secretNames.Insert(o.CertSecretNames...)
+		secretNames.Insert(o.OptionalCertSecretNamePrefixes...)
+		configMapNames.Insert(o.CertConfigMapNamePrefixes...)
+		configMapNames.Insert(o.OptionalCertConfigMapNamePrefixes...)
+	}
+
+	resources := &Resources{
+		Secrets:    map[string]*corev1.Secret{},
+		ConfigMaps: map[string]*corev1.ConfigMap{},
+	}
+	type job struct {
+		kind, name string
+	}
+	jobs := make(chan job)
+	var lock sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var secret *corev1.Secret
+				var configMap *corev1.ConfigMap
+				err := retry.RetryOnConnectionErrors(ctx, func(ctx context.Context) (bool, error) {
+					var clientErr error
+					if j.kind == "secret" {
+						secret, clientErr = source.GetSecret(ctx, o.Namespace, j.name)
+					} else {
+						configMap, clientErr = source.GetConfigMap(ctx, o.Namespace, j.name)
+					}
+					if clientErr != nil {
+						klog.Infof("Failed to get %s %s/%s: %v", j.kind, o.Namespace, j.name, clientErr)
+						return false, clientErr
+					}
+					return true, nil
+				})
+
+				lock.Lock()
+				switch {
+				case err == nil && secret != nil:
+					resources.Secrets[j.name] = secret
+				case err == nil:
+					resources.ConfigMaps[j.name] = configMap
+				case apierrors.IsNotFound(err) && !required.Has(j.kind+"/"+j.name):
+				default:
+					errs = append(errs, fmt.Errorf("failed to get %s %s/%s: %w", j.kind, o.Namespace, j.name, err))
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, name := range secretNames.List() {
+		jobs <- job{kind: "secret", name: name}
+	}
+	for _, name := range configMapNames.List() {
+		jobs <- job{kind: "configmap", name: name}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if podConfigMap, ok := resources.ConfigMaps[podConfigMapName]; ok {
+		if _, ok := podConfigMap.Data["pod.yaml"]; !ok {
+			errs = append(errs, fmt.Errorf("required 'pod.yaml' key does not exist in configmap %s/%s", o.Namespace, podConfigMap.Name))
+		}
+	}
+	if len(errs) > 0 {
+		// the prefix of the installer of library-go, the installer metrics find the error by it
+		return nil, fmt.Errorf("failed to copy: %v", utilerrors.NewAggregate(errs))
+	}
+	klog.Infof("Fetched %d secrets and %d configmaps with %d workers", len(resources.Secrets), len(resources.ConfigMaps), workers)
+	return resources, nil
+}
@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestFetchResources(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-3"}, Data: map[string]string{"pod.yaml": "{}"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "config-3"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "etcd-client-3"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "client-ca"}},
+	}
+	newOptions := func(objects ...runtime.Object) *InstallOptions {
+		return &InstallOptions{
+			KubeClient:                        fake.NewSimpleClientset(objects...),
+			Revision:                          "3",
+			Namespace:                         "openshift-kube-apiserver",
+			PodConfigMapNamePrefix:            "kube-apiserver-pod",
+			ConfigMapNamePrefixes:             []string{"kube-apiserver-pod", "config"},
+			OptionalConfigMapNamePrefixes:     []string{"oauth-metadata"},
+			SecretNamePrefixes:                []string{"etcd-client"},
+			CertDir:                           "/etc/kubernetes/static-pod-resources/kube-apiserver-certs",
+			CertConfigMapNamePrefixes:         []string{"client-ca"},
+			OptionalCertConfigMapNamePrefixes: []string{"trusted-ca-bundle"},
+		}
+	}
+
+	o := newOptions(objects...)
+	resources, err := FetchResources(context.TODO(), o, NewAPIContentSource(o.KubeClient), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gets := len(o.KubeClient.(*fake.Clientset).Actions()); gets != 6 {
+		t.Errorf("expected 6 gets, got %d", gets)
+	}
+	for _, name := range []string{"kube-apiserver-pod-3", "config-3", "client-ca"} {
+		if _, ok := resources.ConfigMaps[name]; !ok {
+			t.Errorf("expected configmap %s", name)
+		}
+	}
+	if _, ok := resources.Secrets["etcd-client-3"]; !ok {
+		t.Errorf("expected the secret")
+	}
+	// missing optional resources are left out
+	for _, name := range []string{"oauth-metadata-3", "trusted-ca-bundle"} {
+		if _, ok := resources.ConfigMaps[name]; ok {
+			t.Errorf("expected the optional configmap %s to be missing", name)
+		}
+	}
+
+	// a missing required resource fails
+	o = newOptions(objects[0], objects[1], objects[3])
+	if _, err := FetchResources(context.TODO(), o, NewAPIContentSource(o.KubeClient), 2); err == nil || !strings.Contains(err.Error(), "etcd-client-3") {
+		t.Errorf("expected the missing secret to fail, got %v", err)
+	}
+
+	// errors other than not found are returned together
+	o = newOptions(objects...)
+	o.KubeClient.(*fake.Clientset).PrependReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.GetAction).GetName()
+		if name == "config-3" || name == "etcd-client-3" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: action.GetResource().Resource}, name, nil)
+		}
+		return false, nil, nil
+	})
+	_, err = FetchResources(context.TODO(), o, NewAPIContentSource(o.KubeClient), 2)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to copy: ") || !strings.Contains(err.Error(), "config-3") || !strings.Contains(err.Error(), "etcd-client-3") {
+		t.Errorf("expected the errors of both resources, got %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/config/client"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/staticpod"
+)
+
+// InstallOptions are the options of the installer. The installer is a fork of the one of library-go: it fetches all
+// secrets and configmaps of a revision from a content source before it writes any of them, writes them with the
+// static pod manifests to the resource dir of the revision and activates the manifests in the pod manifest dir last.
+// It has the flags of the installer of library-go, the installer pods of the installer controller run it unchanged.
+type InstallOptions struct {
+	KubeConfig string
+	// KubeClient is the client of the kube-apiserver, for the secrets and configmaps, the events of the installer and
+	// the static pod it waits for
+	KubeClient kubernetes.Interface
+
+	Revision  string
+	NodeName  string
+	Namespace string
+
+	PodConfigMapNamePrefix        string
+	SecretNamePrefixes            []string
+	OptionalSecretNamePrefixes    []string
+	ConfigMapNamePrefixes         []string
+	OptionalConfigMapNamePrefixes []string
+
+	CertSecretNames                   []string
+	OptionalCertSecretNamePrefixes    []string
+	CertConfigMapNamePrefixes         []string
+	OptionalCertConfigMapNamePrefixes []string
+
+	CertDir        string
+	ResourceDir    string
+	PodManifestDir string
+
+	Timeout time.Duration
+
+	// StaticPodManifestsLockFile is the flock that the installers and the startup monitor take while they replace the
+	// static pod manifests
+	StaticPodManifestsLockFile string
+
+	// Offline is set when the revision is installed from a content dir because the kube-apiserver is unreachable. The
+	// events of the installer are discarded then, they would be retried until the installer times out.
+	Offline bool
+}
+
+func NewInstallOptions() *InstallOptions {
+	return &InstallOptions{}
+}
+
+func (o *InstallOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.KubeConfig, "kubeconfig", o.KubeConfig, "kubeconfig file or empty")
+	fs.StringVar(&o.Revision, "revision", o.Revision, "identifier for this particular installation instance.  For example, a counter or a hash")
+	fs.StringVar(&o.Namespace, "namespace", o.Namespace, "namespace to retrieve all resources from and create the static pod in")
+	fs.StringVar(&o.PodConfigMapNamePrefix, "pod", o.PodConfigMapNamePrefix, "name of configmap that contains the pod to be created")
+	fs.StringSliceVar(&o.SecretNamePrefixes, "secrets", o.SecretNamePrefixes, "list of secret names to be included")
+	fs.StringSliceVar(&o.ConfigMapNamePrefixes, "configmaps", o.ConfigMapNamePrefixes, "list of configmaps to be included")
+	fs.StringSliceVar(&o.OptionalSecretNamePrefixes, "optional-secrets", o.OptionalSecretNamePrefixes, "list of optional secret names to be included")
+	fs.StringSliceVar(&o.OptionalConfigMapNamePrefixes, "optional-configmaps", o.OptionalConfigMapNamePrefixes, "list of optional configmaps to be included")
+	fs.StringVar(&o.ResourceDir, "resource-dir", o.ResourceDir, "directory for all files supporting the static pod manifest")
+	fs.StringVar(&o.PodManifestDir, "pod-manifest-dir", o.PodManifestDir, "directory for the static pod manifest")
+	fs.DurationVar(&o.Timeout, "timeout-duration", 120*time.Second, "maximum time in seconds to wait for the copying to complete (default: 2m)")
+	fs.StringVar(&o.StaticPodManifestsLockFile, "pod-manifests-lock-file", o.StaticPodManifestsLockFile, "path to a file that will be used to coordinate writing static pod manifests between multiple processes")
+
+	fs.StringSliceVar(&o.CertSecretNames, "cert-secrets", o.CertSecretNames, "list of secret names to be included")
+	fs.StringSliceVar(&o.CertConfigMapNamePrefixes, "cert-configmaps", o.CertConfigMapNamePrefixes, "list of configmaps to be included")
+	fs.StringSliceVar(&o.OptionalCertSecretNamePrefixes, "optional-cert-secrets", o.OptionalCertSecretNamePrefixes, "list of optional secret names to be included")
+	fs.StringSliceVar(&o.OptionalCertConfigMapNamePrefixes, "optional-cert-configmaps", o.OptionalCertConfigMapNamePrefixes, "list of optional configmaps to be included")
+	fs.StringVar(&o.CertDir, "cert-dir", o.CertDir, "directory for all certs")
+}
+
+// Complete creates the client, with protobuf for the secrets, configmaps and pods, and takes the node name from the
+// downward API.
+func (o *InstallOptions) Complete() error {
+	clientConfig, err := client.GetKubeConfigOrInClusterConfig(o.KubeConfig, nil)
+	if err != nil {
+		return err
+	}
+	protoConfig := rest.CopyConfig(clientConfig)
+	protoConfig.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
+	protoConfig.ContentType = "application/vnd.kubernetes.protobuf"
+	o.KubeClient, err = kubernetes.NewForConfig(protoConfig)
+	if err != nil {
+		return err
+	}
+
+	o.NodeName = os.Getenv("NODE_NAME")
+	return nil
+}
+
+// Validate verifies the inputs.
+func (o *InstallOptions) Validate() error {
+	if len(o.Revision) == 0 {
+		return fmt.Errorf("--revision is required")
+	}
+	if len(o.NodeName) == 0 {
+		return fmt.Errorf("env var NODE_NAME is required")
+	}
+	if len(o.Namespace) == 0 {
+		return fmt.Errorf("--namespace is required")
+	}
+	if len(o.PodConfigMapNamePrefix) == 0 {
+		return fmt.Errorf("--pod is required")
+	}
+	if len(o.ConfigMapNamePrefixes) == 0 {
+		return fmt.Errorf("--configmaps is required")
+	}
+	if o.Timeout == 0 {
+		return fmt.Errorf("--timeout-duration cannot be 0")
+	}
+	if o.KubeClient == nil {
+		return fmt.Errorf("missing client")
+	}
+	return nil
+}
+
+func (o *InstallOptions) nameFor(prefix string) string {
+	return fmt.Sprintf("%s-%s", prefix, o.Revision)
+}
+
+// InstallRevision writes the fetched secrets and configmaps of the revision, sets the file modes and owners of their
+// annotations and activates the static pod manifests of the revision. The outcome is reported as an event of the
+// installer like the installer of library-go does. The status, which can be nil, records how long writing the
+// resources and the manifests took.
+func InstallRevision(ctx context.Context, o *InstallOptions, resources *Resources, status *StatusOptions) error {
+	recorder := newEventRecorder(ctx, o)
+
+	endWrite := status.Phase(PhaseWriteResources)
+	err := WriteRevision(o, resources)
+	if err == nil {
+		err = ApplyFilePermissions(o, resources)
+	}
+	endWrite()
+	if err == nil {
+		endActivate := status.Phase(PhaseWriteManifest)
+		err = ActivateRevision(ctx, o.ResourceDir, o.PodManifestDir, o.PodConfigMapNamePrefix, o.Revision, o.StaticPodManifestsLockFile)
+		endActivate()
+	}
+	if err != nil {
+		recorder.Warningf("StaticPodInstallerFailed", "Installing revision %s: %v", o.Revision, err)
+		// the prefix of the installer of library-go, the installer metrics find the error by it
+		return fmt.Errorf("failed to copy: %v", err)
+	}
+	recorder.Eventf("StaticPodInstallerCompleted", "Successfully installed revision %s", o.Revision)
+	return nil
+}
+
+// WriteRevision writes the secrets and configmaps of the revision to its resource dir, the certs to the cert dir and
+// the static pod manifests of the pod configmap to the resource dir of the revision. REVISION, NODE_NAME and
+// NODE_ENVVAR_NAME are replaced in their data. Every manifest gets a new uid, the kubelet does not terminate a static
+// pod gracefully that comes back with the same name, file name and uid. The pod manifest dir is not touched.
+func WriteRevision(o *InstallOptions, resources *Resources) error {
+	dir := revisionDir(o.ResourceDir, o.PodConfigMapNamePrefix, o.Revision)
+	klog.Infof("Creating target resource directory %q ...", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, prefix := range append(append([]string{}, o.SecretNamePrefixes...), o.OptionalSecretNamePrefixes...) {
+		if secret, ok := resources.Secrets[o.nameFor(prefix)]; ok {
+			if err := writeSecret(filepath.Join(dir, "secrets", prefix), o.substituteSecret(secret)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, prefix := range append(append([]string{}, o.ConfigMapNamePrefixes...), o.OptionalConfigMapNamePrefixes...) {
+		if configMap, ok := resources.ConfigMaps[o.nameFor(prefix)]; ok {
+			if err := writeConfigMap(filepath.Join(dir, "configmaps", prefix), o.substituteConfigMap(configMap)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the certs as they are now prime the kube-apiserver, the cert syncer keeps them up to date afterwards
+	if len(o.CertDir) > 0 {
+		for _, name := range append(append([]string{}, o.CertSecretNames...), o.OptionalCertSecretNamePrefixes...) {
+			if secret, ok := resources.Secrets[name]; ok {
+				if err := writeSecret(filepath.Join(o.CertDir, "secrets", name), o.substituteSecret(secret)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, name := range append(append([]string{}, o.CertConfigMapNamePrefixes...), o.OptionalCertConfigMapNamePrefixes...) {
+			if configMap, ok := resources.ConfigMaps[name]; ok {
+				if err := writeConfigMap(filepath.Join(o.CertDir, "configmaps", name), o.substituteConfigMap(configMap)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	podConfigMap, ok := resources.ConfigMaps[o.nameFor(o.PodConfigMapNamePrefix)]
+	if !ok {
+		return fmt.Errorf("configmap %s/%s was not fetched", o.Namespace, o.nameFor(o.PodConfigMapNamePrefix))
+	}
+	// the key must be pod.yaml or have a -pod.yaml suffix to be considered
+	for key, rawPod := range o.substituteConfigMap(podConfigMap).Data {
+		manifestFileName := key
+		if key == "pod.yaml" {
+			manifestFileName = o.PodConfigMapNamePrefix + ".yaml"
+		} else if !strings.HasSuffix(key, "-pod.yaml") {
+			continue
+		}
+		pod, err := resourceread.ReadPodV1([]byte(rawPod))
+		if err != nil {
+			return err
+		}
+		pod.UID = uuid.NewUUID()
+		path := filepath.Join(dir, manifestFileName)
+		klog.Infof("Writing pod manifest %q ...", path)
+		if err := ioutil.WriteFile(path, []byte(resourceread.WritePodV1OrDie(pod)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *InstallOptions) replaceBuiltinVariables(content string) string {
+	content = strings.ReplaceAll(content, "REVISION", o.Revision)
+	content = strings.ReplaceAll(content, "NODE_NAME", o.NodeName)
+	return strings.ReplaceAll(content, "NODE_ENVVAR_NAME", strings.ReplaceAll(strings.ReplaceAll(o.NodeName, "-", "_"), ".", "_"))
+}
+
+func (o *InstallOptions) substituteConfigMap(configMap *corev1.ConfigMap) *corev1.ConfigMap {
+	configMap = configMap.DeepCopy()
+	for key, content := range configMap.Data {
+		configMap.Data[key] = o.replaceBuiltinVariables(content)
+	}
+	return configMap
+}
+
+func (o *InstallOptions) substituteSecret(secret *corev1.Secret) *corev1.Secret {
+	secret = secret.DeepCopy()
+	for key, content := range secret.Data {
+		secret.Data[key] = []byte(o.replaceBuiltinVariables(string(content)))
+	}
+	return secret
+}
+
+// writeConfigMap writes the keys of the configmap to the dir with 0644, 0755 for *.sh keys.
+func writeConfigMap(dir string, configMap *corev1.ConfigMap) error {
+	klog.Infof("Creating directory %q ...", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for key, content := range configMap.Data {
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(key, ".sh") {
+			mode = 0755
+		}
+		klog.Infof("Writing config file %q ...", filepath.Join(dir, key))
+		if err := staticpod.WriteFileAtomic([]byte(content), mode, filepath.Join(dir, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSecret writes the keys of the secret to the dir with 0600, 0700 for *.sh keys.
+func writeSecret(dir string, secret *corev1.Secret) error {
+	klog.Infof("Creating directory %q ...", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for key, content := range secret.Data {
+		mode := os.FileMode(0600)
+		if strings.HasSuffix(key, ".sh") {
+			mode = 0700
+		}
+		klog.Infof("Writing secret manifest %q ...", filepath.Join(dir, key))
+		if err := staticpod.WriteFileAtomic(content, mode, filepath.Join(dir, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
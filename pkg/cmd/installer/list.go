@@ -0,0 +1,141 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/retry"
+)
+
+const (
+	// RevisionLabel is the label of the operator on the secrets and configmaps of a revision, with the revision as
+	// value. The revision-status configmap of the revision gets it last, once all of them are labeled.
+	RevisionLabel = "revision"
+
+	// FetchStrategyGet gets every secret and configmap of a revision with a request of its own.
+	FetchStrategyGet = "Get"
+	// FetchStrategyList lists the secrets and configmaps of a revision by their revision label.
+	FetchStrategyList = "List"
+)
+
+// FetchOptions choose how the installer fetches the secrets and configmaps of a revision.
+type FetchOptions struct {
+	FetchStrategy string
+}
+
+func NewFetchOptions() *FetchOptions {
+	return &FetchOptions{FetchStrategy: FetchStrategyGet}
+}
+
+func (o *FetchOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.FetchStrategy, "fetch-strategy", o.FetchStrategy, "How the secrets and configmaps of the revision are fetched, Get one by one or List by their revision label with two requests")
+}
+
+// Validate verifies the inputs.
+func (o *FetchOptions) Validate() error {
+	if o.FetchStrategy != FetchStrategyGet && o.FetchStrategy != FetchStrategyList {
+		return fmt.Errorf("--fetch-strategy must be %s or %s, got %q", FetchStrategyGet, FetchStrategyList, o.FetchStrategy)
+	}
+	return nil
+}
+
+// ContentSource returns the content source of the fetch strategy for the kube-apiserver. With the List strategy it
+// lists the labeled secrets and configmaps of the revision first, and falls back to the Get strategy when the
+// listing fails.
+func (o *FetchOptions) ContentSource(ctx context.Context, client kubernetes.Interface, install *InstallOptions) ContentSource {
+	source := NewAPIContentSource(client)
+	if o.FetchStrategy != FetchStrategyList {
+		return source
+	}
+	listed, err := NewListedContentSource(ctx, source, client, install)
+	if err != nil {
+		klog.Warningf("Failed to list the secrets and configmaps of revision %s, getting them one by one: %v", install.Revision, err)
+		return source
+	}
+	return listed
+}
+
+// NewListedContentSource lists the secrets and configmaps with the revision label of the revision and serves them.
+// Once the revision-status configmap of the revision is labeled too, the labels are complete and missing optional
+// resources of the revision are not found without asking the source. Missing required resources, resources without
+// the label like the certs, and all resources of a revision whose labels are not complete yet are got from the
+// source.
+func NewListedContentSource(ctx context.Context, source ContentSource, client kubernetes.Interface, install *InstallOptions) (ContentSource, error) {
+	selector := labels.SelectorFromSet(labels.Set{RevisionLabel: install.Revision}).String()
+	listed := &listedContentSource{
+		ContentSource: source,
+		secrets:       map[string]*corev1.Secret{},
+		configMaps:    map[string]*corev1.ConfigMap{},
+		optional:      sets.NewString(),
+	}
+	err := retry.RetryOnConnectionErrors(ctx, func(ctx context.Context) (bool, error) {
+		secrets, err := client.CoreV1().Secrets(install.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			klog.Infof("Failed to list the secrets of revision %s: %v", install.Revision, err)
+			return false, err
+		}
+		configMaps, err := client.CoreV1().ConfigMaps(install.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			klog.Infof("Failed to list the configmaps of revision %s: %v", install.Revision, err)
+			return false, err
+		}
+		for i := range secrets.Items {
+			listed.secrets[secrets.Items[i].Name] = &secrets.Items[i]
+		}
+		for i := range configMaps.Items {
+			listed.configMaps[configMaps.Items[i].Name] = &configMaps.Items[i]
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, listed.complete = listed.configMaps[fmt.Sprintf("revision-status-%s", install.Revision)]
+	if listed.complete {
+		for _, prefix := range append(append([]string{}, install.OptionalSecretNamePrefixes...), install.OptionalConfigMapNamePrefixes...) {
+			listed.optional.Insert(fmt.Sprintf("%s-%s", prefix, install.Revision))
+		}
+	}
+	klog.Infof("Listed %d secrets and %d configmaps of revision %s, labels complete: %v", len(listed.secrets), len(listed.configMaps), install.Revision, listed.complete)
+	return listed, nil
+}
+
+type listedContentSource struct {
+	ContentSource
+	secrets    map[string]*corev1.Secret
+	configMaps map[string]*corev1.ConfigMap
+	// complete is whether the operator labeled all the resources of the revision
+	complete bool
+	// optional are the names of the optional resources of the revision, empty unless the labels are complete
+	optional sets.String
+}
+
+func (s *listedContentSource) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	if secret, ok := s.secrets[name]; ok {
+		return secret.DeepCopy(), nil
+	}
+	if s.optional.Has(name) {
+		return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+	}
+	return s.ContentSource.GetSecret(ctx, namespace, name)
+}
+
+func (s *listedContentSource) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	if configMap, ok := s.configMaps[name]; ok {
+		return configMap.DeepCopy(), nil
+	}
+	if s.optional.Has(name) {
+		return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+	}
+	return s.ContentSource.GetConfigMap(ctx, namespace, name)
+}
@@ -0,0 +1,102 @@
+package installer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestListedContentSource(t *testing.T) {
+	labeled := map[string]string{RevisionLabel: "7"}
+	tests := []struct {
+		name string
+		// statusLabels are the labels of the revision-status configmap, the labels are complete with the revision label
+		statusLabels     map[string]string
+		expectedGets     []string
+		expectedNotFound []string
+	}{
+		{
+			name:         "labels complete",
+			statusLabels: labeled,
+			// the certs are not revisioned and have no revision label
+			expectedGets:     []string{"secrets/serving-cert", "secrets/user-serving-cert", "configmaps/config-7"},
+			expectedNotFound: []string{"secret/encryption-config-7", "configmap/oauth-metadata-7", "secret/user-serving-cert"},
+		},
+		{
+			name:             "labels incomplete",
+			expectedGets:     []string{"secrets/encryption-config-7", "secrets/serving-cert", "secrets/user-serving-cert", "configmaps/config-7", "configmaps/oauth-metadata-7"},
+			expectedNotFound: []string{"secret/encryption-config-7", "configmap/oauth-metadata-7", "secret/user-serving-cert"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset([]runtime.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "revision-status-7", Labels: test.statusLabels}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-7", Labels: labeled}, Data: map[string]string{"pod.yaml": "{}"}},
+				// a required configmap the operator did not label yet
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "config-7"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "etcd-client-7", Labels: labeled}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "serving-cert"}},
+			}...)
+			install := &InstallOptions{
+				KubeClient:                     client,
+				Revision:                       "7",
+				Namespace:                      "openshift-kube-apiserver",
+				PodConfigMapNamePrefix:         "kube-apiserver-pod",
+				SecretNamePrefixes:             []string{"etcd-client"},
+				OptionalSecretNamePrefixes:     []string{"encryption-config"},
+				ConfigMapNamePrefixes:          []string{"config"},
+				OptionalConfigMapNamePrefixes:  []string{"oauth-metadata"},
+				CertDir:                        "/etc/kubernetes/static-pod-resources/kube-apiserver-certs",
+				CertSecretNames:                []string{"serving-cert"},
+				OptionalCertSecretNamePrefixes: []string{"user-serving-cert"},
+			}
+			o := NewFetchOptions()
+			o.FetchStrategy = FetchStrategyList
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			source := o.ContentSource(context.TODO(), client, install)
+			resources, err := FetchResources(context.TODO(), install, source, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gets []string
+			lists := 0
+			for _, action := range client.Actions() {
+				switch action.GetVerb() {
+				case "get":
+					gets = append(gets, action.GetResource().Resource+"/"+action.(clienttesting.GetAction).GetName())
+				case "list":
+					lists++
+				}
+			}
+			if lists != 2 {
+				t.Errorf("expected 2 lists, got %d", lists)
+			}
+			if strings.Join(gets, " ") != strings.Join(test.expectedGets, " ") {
+				t.Errorf("expected the gets %v, got %v", test.expectedGets, gets)
+			}
+			for _, name := range []string{"kube-apiserver-pod-7", "config-7"} {
+				if _, ok := resources.ConfigMaps[name]; !ok {
+					t.Errorf("expected configmap %s to be fetched", name)
+				}
+			}
+			for _, key := range test.expectedNotFound {
+				parts := strings.SplitN(key, "/", 2)
+				_, secret := resources.Secrets[parts[1]]
+				_, configMap := resources.ConfigMaps[parts[1]]
+				if parts[0] == "secret" && secret || parts[0] == "configmap" && configMap {
+					t.Errorf("expected %s to be not found", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// FileModeAnnotation sets the mode of the files of a secret or configmap, e.g. "0640" for all of them or
+	// "audit.yaml=0640,*=0600" by key, where * is the mode of the other keys.
+	FileModeAnnotation = "installer.openshift.io/file-mode"
+	// FileOwnerAnnotation sets the numeric owner of the files of a secret or configmap, e.g. "1001:1001" or "1001" to
+	// keep the group, for all of them or "audit.yaml=1001:1001,*=0:0" by key.
+	FileOwnerAnnotation = "installer.openshift.io/file-owner"
+)
+
+// fileOwner is a uid and gid of chown, -1 keeps it.
+type fileOwner struct {
+	uid, gid int
+}
+
+// ApplyFilePermissions sets the modes and owners of the annotations of the secrets and configmaps of a revision and
+// of the certs on the files the installer wrote for them. It writes secrets with 0600 and configmaps
+// with 0644, or 0755 for *.sh keys. Invalid annotations fail the installer.
+func ApplyFilePermissions(o *InstallOptions, fetched *Resources) error {
+	type resource struct {
+		kind, name, dir string
+	}
+	var resources []resource
+	revision := revisionDir(o.ResourceDir, o.PodConfigMapNamePrefix, o.Revision)
+	for _, prefix := range append(append([]string{}, o.SecretNamePrefixes...), o.OptionalSecretNamePrefixes...) {
+		resources = append(resources, resource{kind: "secret", name: fmt.Sprintf("%s-%s", prefix, o.Revision), dir: filepath.Join(revision, "secrets", prefix)})
+	}
+	for _, prefix := range append(append([]string{}, o.ConfigMapNamePrefixes...), o.OptionalConfigMapNamePrefixes...) {
+		resources = append(resources, resource{kind: "configmap", name: fmt.Sprintf("%s-%s", prefix, o.Revision), dir: filepath.Join(revision, "configmaps", prefix)})
+	}
+	if len(o.CertDir) > 0 {
+		for _, name := range append(append([]string{}, o.CertSecretNames...), o.OptionalCertSecretNamePrefixes...) {
+			resources = append(resources, resource{kind: "secret", name: name, dir: filepath.Join(o.CertDir, "secrets", name)})
+		}
+		for _, name := range append(append([]string{}, o.CertConfigMapNamePrefixes...), o.OptionalCertConfigMapNamePrefixes...) {
+			resources = append(resources, resource{kind: "configmap", name: name, dir: filepath.Join(o.CertDir, "configmaps", name)})
+		}
+	}
+
+	for _, r := range resources {
+		var annotations map[string]string
+		var keys []string
+		if r.kind == "secret" {
+			secret, ok := fetched.Secrets[r.name]
+			if !ok {
+				continue
+			}
+			annotations = secret.Annotations
+			for key := range secret.Data {
+				keys = append(keys, key)
+			}
+		} else {
+			configMap, ok := fetched.ConfigMaps[r.name]
+			if !ok {
+				continue
+			}
+			annotations = configMap.Annotations
+			for key := range configMap.Data {
+				keys = append(keys, key)
+			}
+		}
+		if len(annotations[FileModeAnnotation]) == 0 && len(annotations[FileOwnerAnnotation]) == 0 {
+			continue
+		}
+
+		modes, err := parseFileAnnotation(annotations[FileModeAnnotation], parseFileMode)
+		if err != nil {
+			return fmt.Errorf("invalid %s of %s %s/%s: %v", FileModeAnnotation, r.kind, o.Namespace, r.name, err)
+		}
+		owners, err := parseFileAnnotation(annotations[FileOwnerAnnotation], parseFileOwner)
+		if err != nil {
+			return fmt.Errorf("invalid %s of %s %s/%s: %v", FileOwnerAnnotation, r.kind, o.Namespace, r.name, err)
+		}
+		for _, key := range keys {
+			path := filepath.Join(r.dir, key)
+			if mode, ok := fileValue(modes, key); ok {
+				klog.Infof("Setting the mode of %q to %04o", path, mode)
+				if err := os.Chmod(path, mode.(os.FileMode)); err != nil {
+					return err
+				}
+			}
+			if owner, ok := fileValue(owners, key); ok {
+				klog.Infof("Setting the owner of %q to %d:%d", path, owner.(fileOwner).uid, owner.(fileOwner).gid)
+				if err := os.Chown(path, owner.(fileOwner).uid, owner.(fileOwner).gid); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseFileAnnotation parses a value for all keys, stored as *, or comma separated key=value pairs.
+func parseFileAnnotation(annotation string, parse func(string) (interface{}, error)) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(annotation) == 0 {
+		return values, nil
+	}
+	if !strings.Contains(annotation, "=") {
+		value, err := parse(annotation)
+		if err != nil {
+			return nil, err
+		}
+		values["*"] = value
+		return values, nil
+	}
+	for _, pair := range strings.Split(annotation, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		if _, ok := values[parts[0]]; ok {
+			return nil, fmt.Errorf("%s is set twice", parts[0])
+		}
+		value, err := parse(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		values[parts[0]] = value
+	}
+	return values, nil
+}
+
+func fileValue(values map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := values[key]; ok {
+		return value, true
+	}
+	value, ok := values["*"]
+	return value, ok
+}
+
+// parseFileMode parses an octal mode of the permission bits, without setuid, setgid and sticky bits.
+func parseFileMode(value string) (interface{}, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return nil, fmt.Errorf("%q is not an octal file mode up to 0777", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// parseFileOwner parses a numeric uid:gid or uid.
+func parseFileOwner(value string) (interface{}, error) {
+	parts := strings.SplitN(value, ":", 2)
+	owner := fileOwner{uid: -1, gid: -1}
+	var err error
+	if owner.uid, err = strconv.Atoi(parts[0]); err != nil || owner.uid < 0 {
+		return nil, fmt.Errorf("%q is not a numeric uid:gid", value)
+	}
+	if len(parts) == 2 {
+		if owner.gid, err = strconv.Atoi(parts[1]); err != nil || owner.gid < 0 {
+			return nil, fmt.Errorf("%q is not a numeric uid:gid", value)
+		}
+	}
+	return owner, nil
+}
@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyFilePermissions(t *testing.T) {
+	// only root can give files away, the tests keep the current owner
+	owner := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+
+	tests := []struct {
+		name          string
+		secretModes   string
+		configModes   string
+		owner         string
+		expectedModes map[string]os.FileMode
+		expectedErr   string
+	}{
+		{
+			name: "defaults",
+			expectedModes: map[string]os.FileMode{
+				"resources/kube-apiserver-pod-3/secrets/encryption-config/encryption-config": 0600,
+				"resources/kube-apiserver-pod-3/configmaps/config/audit.yaml":                0644,
+				"resources/kube-apiserver-pod-3/configmaps/config/config.yaml":               0644,
+				"certs/secrets/serving-cert/tls.key":                                         0600,
+			},
+		},
+		{
+			name:        "modes by key and for all keys",
+			secretModes: "0640",
+			configModes: "audit.yaml=0640, *=0600",
+			owner:       owner,
+			expectedModes: map[string]os.FileMode{
+				"resources/kube-apiserver-pod-3/secrets/encryption-config/encryption-config": 0640,
+				"resources/kube-apiserver-pod-3/configmaps/config/audit.yaml":                0640,
+				"resources/kube-apiserver-pod-3/configmaps/config/config.yaml":               0600,
+				"certs/secrets/serving-cert/tls.key":                                         0640,
+			},
+		},
+		{name: "setuid", secretModes: "4755", expectedErr: `invalid installer.openshift.io/file-mode of secret openshift-kube-apiserver/encryption-config-3: "4755" is not an octal file mode`},
+		{name: "key set twice", configModes: "audit.yaml=0640,audit.yaml=0600", expectedErr: "audit.yaml is set twice"},
+		{name: "user name", owner: "kube-apiserver", expectedErr: `"kube-apiserver" is not a numeric uid:gid`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "installer-permissions")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			writeFiles(t, dir, map[string]string{
+				"resources/kube-apiserver-pod-3/secrets/encryption-config/encryption-config": "encryption",
+				"resources/kube-apiserver-pod-3/configmaps/config/audit.yaml":                "audit",
+				"resources/kube-apiserver-pod-3/configmaps/config/config.yaml":               "config",
+				"certs/secrets/serving-cert/tls.key":                                         "key",
+			})
+			for path, mode := range map[string]os.FileMode{
+				"resources/kube-apiserver-pod-3/secrets/encryption-config/encryption-config": 0600,
+				"certs/secrets/serving-cert/tls.key":                                         0600,
+			} {
+				if err := os.Chmod(filepath.Join(dir, path), mode); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			annotations := func(modes string) map[string]string {
+				annotations := map[string]string{}
+				if len(modes) > 0 {
+					annotations[FileModeAnnotation] = modes
+				}
+				if len(test.owner) > 0 {
+					annotations[FileOwnerAnnotation] = test.owner
+				}
+				return annotations
+			}
+			resources := &Resources{
+				Secrets: map[string]*corev1.Secret{
+					"encryption-config-3": {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "encryption-config-3", Annotations: annotations(test.secretModes)}, Data: map[string][]byte{"encryption-config": []byte("encryption")}},
+					"serving-cert":        {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "serving-cert", Annotations: annotations(test.secretModes)}, Data: map[string][]byte{"tls.key": []byte("key")}},
+				},
+				ConfigMaps: map[string]*corev1.ConfigMap{
+					"config-3": {ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "config-3", Annotations: annotations(test.configModes)}, Data: map[string]string{"audit.yaml": "audit", "config.yaml": "config"}},
+				},
+			}
+			install := &InstallOptions{
+				Revision:                       "3",
+				Namespace:                      "openshift-kube-apiserver",
+				PodConfigMapNamePrefix:         "kube-apiserver-pod",
+				SecretNamePrefixes:             []string{"encryption-config"},
+				ConfigMapNamePrefixes:          []string{"config"},
+				OptionalConfigMapNamePrefixes:  []string{"oauth-metadata"},
+				ResourceDir:                    filepath.Join(dir, "resources"),
+				CertDir:                        filepath.Join(dir, "certs"),
+				CertSecretNames:                []string{"serving-cert"},
+				OptionalCertSecretNamePrefixes: []string{"user-serving-cert"},
+			}
+
+			err = ApplyFilePermissions(install, resources)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatal(err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected %q, got %v", test.expectedErr, err)
+			}
+			for path, expected := range test.expectedModes {
+				info, err := os.Stat(filepath.Join(dir, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != expected {
+					t.Errorf("expected %s to have mode %04o, got %04o", path, expected, info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
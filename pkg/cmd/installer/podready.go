@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// PodReadyOptions make the installer wait for the static pod of the revision to become ready after installing it.
+type PodReadyOptions struct {
+	WaitForPodReady bool
+	PodReadyTimeout time.Duration
+	interval        time.Duration
+}
+
+func NewPodReadyOptions() *PodReadyOptions {
+	return &PodReadyOptions{PodReadyTimeout: 5 * time.Minute, interval: 5 * time.Second}
+}
+
+func (o *PodReadyOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.WaitForPodReady, "wait-for-pod-ready", o.WaitForPodReady, "Wait until the static pod of the revision is ready before the installer succeeds")
+	fs.DurationVar(&o.PodReadyTimeout, "pod-ready-timeout", o.PodReadyTimeout, "How long to wait for the static pod of the revision to become ready")
+}
+
+// Validate verifies the inputs.
+func (o *PodReadyOptions) Validate() error {
+	if o.WaitForPodReady && o.PodReadyTimeout <= 0 {
+		return fmt.Errorf("--pod-ready-timeout must be positive")
+	}
+	return nil
+}
+
+// Wait polls the mirror pod of the static pod that the installer wrote until it runs the revision and is ready. The
+// kubelet names the mirror pod after the static pod and the node. Errors of the API are retried, the kube-apiserver
+// that restarts with the revision may be the only one.
+func (o *PodReadyOptions) Wait(ctx context.Context, install *InstallOptions) error {
+	if !o.WaitForPodReady {
+		return nil
+	}
+	manifest, err := ioutil.ReadFile(filepath.Join(install.PodManifestDir, install.PodConfigMapNamePrefix+".yaml"))
+	if err != nil {
+		return err
+	}
+	staticPod, err := resourceread.ReadPodV1(manifest)
+	if err != nil {
+		return err
+	}
+	namespace, name := staticPod.Namespace, fmt.Sprintf("%s-%s", staticPod.Name, install.NodeName)
+
+	klog.Infof("Waiting up to %s for pod %s/%s to become ready with revision %s", o.PodReadyTimeout, namespace, name, install.Revision)
+	var state string
+	err = wait.PollImmediateWithContext(ctx, o.interval, o.PodReadyTimeout, func(ctx context.Context) (bool, error) {
+		pod, err := install.KubeClient.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			state = err.Error()
+			klog.Infof("Failed to get pod %s/%s: %v", namespace, name, err)
+			return false, nil
+		}
+		if revision := pod.Labels["revision"]; revision != install.Revision {
+			state = fmt.Sprintf("the pod runs revision %q", revision)
+			return false, nil
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		state = fmt.Sprintf("the pod is not ready in phase %s", pod.Status.Phase)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.RestartCount > 0 {
+				state = fmt.Sprintf("%s, container %s restarted %d times", state, status.Name, status.RestartCount)
+			}
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for pod %s/%s to become ready with revision %s: %s", namespace, name, install.Revision, state)
+	}
+	if err != nil {
+		return err
+	}
+	klog.Infof("Pod %s/%s is ready with revision %s", namespace, name, install.Revision)
+	return nil
+}
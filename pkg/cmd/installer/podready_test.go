@@ -0,0 +1,66 @@
+package installer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodReadyWait(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pod-ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "kube-apiserver-pod.yaml"), []byte(manifest("4", "a")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		revision    string
+		ready       corev1.ConditionStatus
+		restarts    int32
+		expectedErr string
+	}{
+		{name: "ready", revision: "4", ready: corev1.ConditionTrue},
+		{name: "old revision", revision: "3", ready: corev1.ConditionTrue, expectedErr: `the pod runs revision "3"`},
+		{name: "crashlooping", revision: "4", ready: corev1.ConditionFalse, restarts: 3, expectedErr: "container kube-apiserver restarted 3 times"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-master-0", Labels: map[string]string{"revision": test.revision}},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: test.ready}},
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "kube-apiserver", RestartCount: test.restarts}},
+				},
+			}
+			install := &InstallOptions{
+				KubeClient:             fake.NewSimpleClientset(pod),
+				Revision:               "4",
+				NodeName:               "master-0",
+				PodConfigMapNamePrefix: "kube-apiserver-pod",
+				PodManifestDir:         dir,
+			}
+			o := &PodReadyOptions{WaitForPodReady: true, PodReadyTimeout: 50 * time.Millisecond, interval: 10 * time.Millisecond}
+
+			err := o.Wait(context.TODO(), install)
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatal(err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr) || !strings.HasPrefix(err.Error(), "timed out")):
+				t.Fatalf("expected a timeout with %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// RetentionOptions make the installer remove the resource dirs of old revisions from the node and refuse to install
+// a revision when the disk of the resource dir is full.
+type RetentionOptions struct {
+	KeepRevisions int
+	MinFreeDisk   string
+
+	minFreeBytes int64
+	// availableBytes returns the disk space available to unprivileged users in the dir
+	availableBytes func(dir string) (int64, error)
+}
+
+func NewRetentionOptions() *RetentionOptions {
+	return &RetentionOptions{availableBytes: availableBytes}
+}
+
+func (o *RetentionOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.KeepRevisions, "keep-revisions", o.KeepRevisions, "Remove the resource dirs of the revisions older than this many revisions below the installed one, 0 keeps all")
+	fs.StringVar(&o.MinFreeDisk, "min-free-disk", o.MinFreeDisk, "Fail when less disk space is available for the resource dir, e.g. 1Gi")
+}
+
+// Validate verifies the inputs.
+func (o *RetentionOptions) Validate() error {
+	if o.KeepRevisions < 0 {
+		return fmt.Errorf("--keep-revisions must not be negative")
+	}
+	if len(o.MinFreeDisk) == 0 {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(o.MinFreeDisk)
+	if err != nil {
+		return fmt.Errorf("--min-free-disk: %v", err)
+	}
+	if quantity.Sign() <= 0 {
+		return fmt.Errorf("--min-free-disk must be greater than zero")
+	}
+	o.minFreeBytes = quantity.Value()
+	return nil
+}
+
+// Apply removes the resource dirs of the revisions below the one to install except for the newest KeepRevisions and
+// the one the static pod manifest runs, which the startup monitor falls back to. Newer revisions, e.g. after a
+// rollback, are kept. Then it fails when less than MinFreeDisk is available for the resource dir. Both are reported
+// as events of the installer.
+func (o *RetentionOptions) Apply(ctx context.Context, install *InstallOptions) error {
+	if o.KeepRevisions == 0 && o.minFreeBytes == 0 {
+		return nil
+	}
+	recorder := newEventRecorder(ctx, install)
+
+	if o.KeepRevisions > 0 {
+		removed, freed, err := o.removeOldRevisions(install)
+		if err != nil {
+			return err
+		}
+		if len(removed) > 0 {
+			recorder.Eventf("RevisionResourcesRemoved", "Removed the resource dirs of revisions %s from node %s, freeing %d bytes", strings.Join(removed, ", "), install.NodeName, freed)
+		}
+	}
+
+	if o.minFreeBytes > 0 {
+		available, err := o.availableBytes(install.ResourceDir)
+		if err != nil {
+			return err
+		}
+		if available < o.minFreeBytes {
+			err := fmt.Errorf("only %d bytes are available for %s on node %s, --min-free-disk is %s", available, install.ResourceDir, install.NodeName, o.MinFreeDisk)
+			recorder.Warningf("InsufficientDiskSpace", "Not installing revision %s: %v", install.Revision, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// removeOldRevisions removes the old resource dirs and returns their revisions and the bytes of their files.
+func (o *RetentionOptions) removeOldRevisions(install *InstallOptions) ([]string, int64, error) {
+	target, err := strconv.Atoi(install.Revision)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid revision %q: %v", install.Revision, err)
+	}
+	active := -1
+	if data, err := ioutil.ReadFile(filepath.Join(install.PodManifestDir, install.PodConfigMapNamePrefix+".yaml")); err == nil {
+		if pod, err := resourceread.ReadPodV1(data); err == nil {
+			if active, err = strconv.Atoi(pod.Labels["revision"]); err != nil {
+				active = -1
+			}
+		}
+	}
+
+	files, err := ioutil.ReadDir(install.ResourceDir)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var older []int
+	for _, file := range files {
+		suffix := strings.TrimPrefix(file.Name(), install.PodConfigMapNamePrefix+"-")
+		revision, err := strconv.Atoi(suffix)
+		if !file.IsDir() || suffix == file.Name() || err != nil {
+			continue
+		}
+		if revision < target && revision != active {
+			older = append(older, revision)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(older)))
+	if len(older) <= o.KeepRevisions {
+		return nil, 0, nil
+	}
+
+	var removed []string
+	var freed int64
+	for _, revision := range older[o.KeepRevisions:] {
+		dir := revisionDir(install.ResourceDir, install.PodConfigMapNamePrefix, strconv.Itoa(revision))
+		size, err := dirSize(dir)
+		if err != nil {
+			return nil, 0, err
+		}
+		klog.Infof("Removing the resource dir %s of revision %d, %d bytes", dir, revision, size)
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, 0, err
+		}
+		removed = append(removed, strconv.Itoa(revision))
+		freed += size
+	}
+	return removed, freed, nil
+}
+
+// dirSize returns the size of the regular files in the dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// availableBytes checks the closest existing parent of a dir that the installer has not created yet.
+func availableBytes(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	for os.IsNotExist(err) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+		err = syscall.Statfs(dir, &stat)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
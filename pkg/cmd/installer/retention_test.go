@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFiles(t, dir, map[string]string{
+		"manifests/kube-apiserver-pod.yaml":              manifest("2", "a"),
+		"resources/kube-apiserver-pod-1/configmaps/a/a":  "1",
+		"resources/kube-apiserver-pod-2/configmaps/a/a":  "2",
+		"resources/kube-apiserver-pod-3/configmaps/a/a":  "33",
+		"resources/kube-apiserver-pod-4/configmaps/a/a":  "4",
+		"resources/kube-apiserver-pod-5/configmaps/a/a":  "5",
+		"resources/kube-apiserver-pod-7/configmaps/a/a":  "7",
+		"resources/kube-apiserver-certs/configmaps/a/a":  "certs",
+		"resources/kube-apiserver-pod-foo/configmaps/a/": "",
+	})
+	client := fake.NewSimpleClientset()
+	install := &InstallOptions{
+		KubeClient:             client,
+		Revision:               "6",
+		NodeName:               "master-0",
+		Namespace:              "openshift-kube-apiserver",
+		PodConfigMapNamePrefix: "kube-apiserver-pod",
+		ResourceDir:            filepath.Join(dir, "resources"),
+		PodManifestDir:         filepath.Join(dir, "manifests"),
+	}
+	o := NewRetentionOptions()
+	o.KeepRevisions, o.MinFreeDisk = 2, "1Gi"
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	o.availableBytes = func(string) (int64, error) { return 2 << 30, nil }
+
+	if err := o.Apply(context.TODO(), install); err != nil {
+		t.Fatal(err)
+	}
+	// 5 and 4 are kept, 2 runs on the node, 7 is newer
+	files, err := ioutil.ReadDir(install.ResourceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	if expected := "kube-apiserver-certs kube-apiserver-pod-2 kube-apiserver-pod-4 kube-apiserver-pod-5 kube-apiserver-pod-7 kube-apiserver-pod-foo"; strings.Join(names, " ") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(names, " "))
+	}
+	if message := lastEvent(t, client); !strings.Contains(message, "Removed the resource dirs of revisions 3, 1 from node master-0, freeing 3 bytes") {
+		t.Errorf("unexpected event %q", message)
+	}
+
+	client.ClearActions()
+	o.availableBytes = func(string) (int64, error) { return 1 << 20, nil }
+	if err := o.Apply(context.TODO(), install); err == nil || !strings.Contains(err.Error(), "only 1048576 bytes are available") {
+		t.Errorf("expected the install to be refused, got %v", err)
+	}
+	if message := lastEvent(t, client); !strings.HasPrefix(message, "Not installing revision 6: only 1048576 bytes") {
+		t.Errorf("unexpected event %q", message)
+	}
+}
+
+func lastEvent(t *testing.T, client *fake.Clientset) string {
+	t.Helper()
+	var message string
+	for _, action := range client.Actions() {
+		if create, ok := action.(clienttesting.CreateAction); ok && action.GetResource().Resource == "events" {
+			message = create.GetObject().(*corev1.Event).Message
+		}
+	}
+	return message
+}
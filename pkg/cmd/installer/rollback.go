@@ -0,0 +1,145 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// rollbackOpts are the options of the installer rollback command.
+type rollbackOpts struct {
+	toRevision     string
+	podPrefix      string
+	resourceDir    string
+	podManifestDir string
+	lockFile       string
+	timeout        time.Duration
+	out            io.Writer
+}
+
+// NewRollbackCommand creates the rollback command. It activates the static pod manifests of a revision that is still
+// in the resource dir on the node again, without the kube-apiserver, e.g. when a bad revision took it down.
+func NewRollbackCommand() *cobra.Command {
+	o := &rollbackOpts{
+		podPrefix:      "kube-apiserver-pod",
+		resourceDir:    "/etc/kubernetes/static-pod-resources",
+		podManifestDir: "/etc/kubernetes/manifests",
+		lockFile:       "/var/lock/kube-apiserver-installer.lock",
+		timeout:        2 * time.Minute,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Activate the static pod manifest of a previous revision from the resource dir on the node",
+		Run: func(cmd *cobra.Command, args []string) {
+			o.out = cmd.OutOrStdout()
+			if err := o.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			ctx, cancel := context.WithTimeout(context.TODO(), o.timeout)
+			defer cancel()
+			if err := o.Run(ctx); err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *rollbackOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.toRevision, "to-revision", o.toRevision, "The revision to activate")
+	fs.StringVar(&o.podPrefix, "pod", o.podPrefix, "The name of the configmap of the static pod, which prefixes the resource dirs of the revisions")
+	fs.StringVar(&o.resourceDir, "resource-dir", o.resourceDir, "The directory of the resources of the revisions")
+	fs.StringVar(&o.podManifestDir, "pod-manifest-dir", o.podManifestDir, "The directory of the static pod manifests")
+	fs.StringVar(&o.lockFile, "pod-manifests-lock-file", o.lockFile, "The lock file of the installers and the startup monitor, empty to not take it")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "How long to wait for the lock")
+}
+
+// Validate verifies the inputs.
+func (o *rollbackOpts) Validate() error {
+	if len(o.toRevision) == 0 {
+		return fmt.Errorf("--to-revision is required")
+	}
+	return nil
+}
+
+// Run refuses a revision whose resources drifted from the recorded digests, gives its static pod manifests a new uid
+// like every installation does, activates them and records the digests again.
+func (o *rollbackOpts) Run(ctx context.Context) error {
+	dir := revisionDir(o.resourceDir, o.podPrefix, o.toRevision)
+	manifests, err := revisionManifests(dir, o.podPrefix)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("revision %s has no static pod manifest in %s", o.toRevision, dir)
+	}
+
+	report, err := VerifyInstalledRevision(o.resourceDir, o.podManifestDir, o.podPrefix, o.toRevision)
+	switch {
+	case os.IsNotExist(err):
+		klog.Warningf("Revision %s has no recorded digests, its files are not verified", o.toRevision)
+	case err != nil:
+		return err
+	default:
+		manifestPath := filepath.Join(o.podManifestDir, o.podPrefix+".yaml")
+		for _, drift := range report.Drift {
+			// the manifest in the pod manifest dir is replaced anyway
+			if drift.Path != manifestPath {
+				return fmt.Errorf("revision %s drifted since it was installed, %s %s", o.toRevision, drift.Change, drift.Path)
+			}
+		}
+	}
+
+	previous := "none"
+	if data, err := ioutil.ReadFile(filepath.Join(o.podManifestDir, o.podPrefix+".yaml")); err == nil {
+		if pod, err := resourceread.ReadPodV1(data); err == nil {
+			previous = pod.Labels["revision"]
+		}
+	}
+
+	// the kubelet does not terminate a static pod gracefully that comes back with the same uid
+	for _, name := range manifests {
+		path := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pod, err := resourceread.ReadPodV1(data)
+		if err != nil {
+			return fmt.Errorf("the static pod manifest %s does not decode: %v", path, err)
+		}
+		pod.UID = uuid.NewUUID()
+		if err := replaceFile(path, []byte(resourceread.WritePodV1OrDie(pod))); err != nil {
+			return err
+		}
+	}
+	if err := ActivateRevision(ctx, o.resourceDir, o.podManifestDir, o.podPrefix, o.toRevision, o.lockFile); err != nil {
+		return err
+	}
+	install := &InstallOptions{
+		Revision:               o.toRevision,
+		PodConfigMapNamePrefix: o.podPrefix,
+		ResourceDir:            o.resourceDir,
+		PodManifestDir:         o.podManifestDir,
+	}
+	if err := RecordInstalledRevision(install); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.out, "Activated revision %s, the static pod manifest ran revision %s before\n", o.toRevision, previous)
+	return err
+}
@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+func TestRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-rollback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resourceDir, podManifestDir := filepath.Join(dir, "resources"), filepath.Join(dir, "manifests")
+	for _, revision := range []string{"4", "5"} {
+		writeFiles(t, dir, map[string]string{
+			"manifests/kube-apiserver-pod.yaml":                                     manifest(revision, "uid-"+revision),
+			"resources/kube-apiserver-pod-" + revision + "/kube-apiserver-pod.yaml": manifest(revision, "uid-"+revision),
+			"resources/kube-apiserver-pod-" + revision + "/configmaps/config/a":     revision,
+		})
+		install := &InstallOptions{Revision: revision, PodConfigMapNamePrefix: "kube-apiserver-pod", ResourceDir: resourceDir, PodManifestDir: podManifestDir}
+		if err := RecordInstalledRevision(install); err != nil {
+			t.Fatal(err)
+		}
+	}
+	revision5, err := ioutil.ReadFile(filepath.Join(resourceDir, "kube-apiserver-pod-5", "kube-apiserver-pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	o := &rollbackOpts{toRevision: "4", podPrefix: "kube-apiserver-pod", resourceDir: resourceDir, podManifestDir: podManifestDir, lockFile: filepath.Join(dir, "installer.lock"), out: out}
+	if err := o.Run(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Activated revision 4, the static pod manifest ran revision 5 before\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	active, err := ioutil.ReadFile(filepath.Join(podManifestDir, "kube-apiserver-pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod, err := resourceread.ReadPodV1(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Labels["revision"] != "4" || pod.UID == "uid-4" {
+		t.Errorf("expected revision 4 with a new uid, got revision %s with uid %s", pod.Labels["revision"], pod.UID)
+	}
+	report, err := VerifyInstalledRevision(resourceDir, podManifestDir, "kube-apiserver-pod", "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ManifestRevision != "4" || len(report.Drift) != 0 {
+		t.Errorf("expected revision 4 to be active without drift, got %+v", report)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(resourceDir, "kube-apiserver-pod-5", "kube-apiserver-pod.yaml")); err != nil || !bytes.Equal(data, revision5) {
+		t.Errorf("expected the manifest of revision 5 to be intact, got %v", err)
+	}
+	if files, err := ioutil.ReadDir(podManifestDir); err != nil || len(files) != 1 {
+		t.Errorf("expected only the static pod manifest in the pod manifest dir, got %v", err)
+	}
+
+	// the resources of revision 5 drifted since it was installed
+	writeFiles(t, dir, map[string]string{"resources/kube-apiserver-pod-5/configmaps/config/a": "changed"})
+	o.toRevision = "5"
+	if err := o.Run(context.TODO()); err == nil || !strings.Contains(err.Error(), "Modified configmaps/config/a") {
+		t.Errorf("expected the drifted revision to be refused, got %v", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(podManifestDir, "kube-apiserver-pod.yaml")); err != nil || !bytes.Equal(data, active) {
+		t.Errorf("expected revision 4 to stay active, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/library-go/pkg/operator/resource/retry"
+)
+
+// The phases of an installer in its status.
+const (
+	PhaseListResources   = "listResources"
+	PhaseFetchSecrets    = "fetchSecrets"
+	PhaseFetchConfigMaps = "fetchConfigMaps"
+	PhaseCheckCerts      = "checkCerts"
+	PhaseRemoveRevisions = "removeRevisions"
+	PhaseWriteResources  = "writeResources"
+	PhaseWriteManifest   = "writeManifest"
+	PhaseWaitForPodReady = "waitForPodReady"
+)
+
+// StatusAnnotation is the annotation of the installer pod with the status of the installer as JSON.
+const StatusAnnotation = "installer.openshift.io/status"
+
+// statusPatchTimeout is how long the installer tries to write its status to its pod when it exits.
+const statusPatchTimeout = 30 * time.Second
+
+// metricsTextfileName is the file of the installer metrics in the textfile dir of the node-exporter, which only reads
+// *.prom files.
+const metricsTextfileName = "kube-apiserver-installer.prom"
+
+// Status is what an installer reports about its run in the annotation of its pod.
+type Status struct {
+	Revision string `json:"revision"`
+	NodeName string `json:"nodeName,omitempty"`
+	// Error is why the installer failed, empty when it succeeded
+	Error string `json:"error,omitempty"`
+	// Phases are the durations of the phases the installer ran in seconds. The fetch phases run at the same time,
+	// they last from the first until the last request of their kind.
+	Phases map[string]float64 `json:"phases"`
+	// Requests are the requests for the secrets and configmaps of the revision by kind, secret or configmap
+	Requests map[string]*RequestStats `json:"requests,omitempty"`
+	// Retries are the requests that repeated a failed one
+	Retries int `json:"retries"`
+}
+
+// RequestStats are the latencies of the requests for a kind of resources.
+type RequestStats struct {
+	Count        int     `json:"count"`
+	Errors       int     `json:"errors"`
+	TotalSeconds float64 `json:"totalSeconds"`
+	MaxSeconds   float64 `json:"maxSeconds"`
+}
+
+// StatusOptions make the installer record the durations of its phases, its retries and the latencies of its
+// requests, and write them to the annotation of its pod and to the textfile dir of the node-exporter when it exits.
+// The error of a failed installer is written to the termination message file on a single line, the kubelet copies the
+// termination message into the node status of the operator. A nil StatusOptions records nothing.
+type StatusOptions struct {
+	TerminationMessageFile string
+	StatusPod              string
+	MetricsTextfileDir     string
+
+	lock   sync.Mutex
+	status Status
+	// fetches are the start of the first and the end of the last request by kind
+	fetches map[string][2]time.Time
+	// attempts are the requests by kind and name
+	attempts map[string]int
+	now      func() time.Time
+}
+
+func NewStatusOptions() *StatusOptions {
+	return &StatusOptions{
+		status:   Status{Phases: map[string]float64{}, Requests: map[string]*RequestStats{}},
+		fetches:  map[string][2]time.Time{},
+		attempts: map[string]int{},
+		now:      time.Now,
+	}
+}
+
+func (o *StatusOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.TerminationMessageFile, "termination-message-file", o.TerminationMessageFile, "Write the error of a failed installer on a single line to this file, e.g. the termination message path of the container")
+	fs.StringVar(&o.StatusPod, "status-pod", o.StatusPod, "Write the phase durations, retries and request latencies of the installer as JSON to the "+StatusAnnotation+" annotation of this pod in the namespace when it exits, e.g. the installer pod")
+	fs.StringVar(&o.MetricsTextfileDir, "metrics-textfile-dir", o.MetricsTextfileDir, "Write the phase durations, retries and request latencies of the installer to "+metricsTextfileName+" in this textfile dir of the node-exporter when it exits")
+}
+
+// Phase starts measuring a phase, the returned func ends it. A phase that runs more than once is summed up.
+func (o *StatusOptions) Phase(name string) func() {
+	if o == nil {
+		return func() {}
+	}
+	start := o.now()
+	return func() {
+		o.lock.Lock()
+		defer o.lock.Unlock()
+		o.status.Phases[name] += o.now().Sub(start).Seconds()
+	}
+}
+
+func (o *StatusOptions) clock() time.Time {
+	if o == nil {
+		return time.Time{}
+	}
+	return o.now()
+}
+
+// observeRequest records a request for a secret or configmap. Missing ones are not counted as errors, the optional
+// ones are missing regularly.
+func (o *StatusOptions) observeRequest(kind, name string, start time.Time, err error) {
+	if o == nil {
+		return
+	}
+	end := o.now()
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	stats, ok := o.status.Requests[kind]
+	if !ok {
+		stats = &RequestStats{}
+		o.status.Requests[kind] = stats
+	}
+	latency := end.Sub(start).Seconds()
+	stats.Count++
+	stats.TotalSeconds += latency
+	if latency > stats.MaxSeconds {
+		stats.MaxSeconds = latency
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		stats.Errors++
+	}
+	if o.attempts[kind+"/"+name] > 0 {
+		o.status.Retries++
+	}
+	o.attempts[kind+"/"+name]++
+
+	fetch, ok := o.fetches[kind]
+	if !ok || start.Before(fetch[0]) {
+		fetch[0] = start
+	}
+	if end.After(fetch[1]) {
+		fetch[1] = end
+	}
+	o.fetches[kind] = fetch
+	phase := PhaseFetchConfigMaps
+	if kind == "secret" {
+		phase = PhaseFetchSecrets
+	}
+	o.status.Phases[phase] = fetch[1].Sub(fetch[0]).Seconds()
+}
+
+// Write writes the error of the install, nil when it succeeded, to the termination message file, and the status to
+// the textfile dir and the annotation of the status pod. An offline install has no pod to annotate.
+func (o *StatusOptions) Write(install *InstallOptions, installErr error) error {
+	if o == nil || (len(o.TerminationMessageFile) == 0 && len(o.StatusPod) == 0 && len(o.MetricsTextfileDir) == 0) {
+		return nil
+	}
+	o.lock.Lock()
+	status := o.status
+	o.lock.Unlock()
+	status.Revision, status.NodeName = install.Revision, install.NodeName
+	if installErr != nil {
+		status.Error = installErr.Error()
+	}
+
+	if len(o.TerminationMessageFile) > 0 && installErr != nil {
+		// the termination message path is mounted by the kubelet, it can only be written in place
+		if err := ioutil.WriteFile(o.TerminationMessageFile, []byte(strings.ReplaceAll(status.Error, "\n", " ")+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	if len(o.MetricsTextfileDir) > 0 {
+		// the node-exporter must not read a partial file, it ignores the hidden temporary one
+		tmp := filepath.Join(o.MetricsTextfileDir, "."+metricsTextfileName+".tmp")
+		if err := ioutil.WriteFile(tmp, status.textfile(o.now()), 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, filepath.Join(o.MetricsTextfileDir, metricsTextfileName)); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if len(o.StatusPod) > 0 && !install.Offline {
+		if err := o.annotatePod(install, status); err != nil {
+			return fmt.Errorf("failed to annotate pod %s/%s with the status: %v", install.Namespace, o.StatusPod, err)
+		}
+	}
+	return nil
+}
+
+// annotatePod writes the status to the annotation of the status pod, retrying on connection errors.
+func (o *StatusOptions) annotatePod(install *InstallOptions, status Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{StatusAnnotation: string(data)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// the install has its own timeout, which may have passed
+	ctx, cancel := context.WithTimeout(context.Background(), statusPatchTimeout)
+	defer cancel()
+	return retry.RetryOnConnectionErrors(ctx, func(ctx context.Context) (bool, error) {
+		if _, err := install.KubeClient.CoreV1().Pods(install.Namespace).Patch(ctx, o.StatusPod, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// textfile returns the status in the text format of Prometheus.
+func (s Status) textfile(now time.Time) []byte {
+	buf := &bytes.Buffer{}
+	revision := fmt.Sprintf("revision=%q", s.Revision)
+	gauge := func(name, help string) {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("kube_apiserver_installer_succeeded", "Whether the last installer on the node succeeded.")
+	succeeded := 1
+	if len(s.Error) > 0 {
+		succeeded = 0
+	}
+	fmt.Fprintf(buf, "kube_apiserver_installer_succeeded{%s} %d\n", revision, succeeded)
+	gauge("kube_apiserver_installer_finished_timestamp_seconds", "When the last installer on the node finished as unix timestamp.")
+	fmt.Fprintf(buf, "kube_apiserver_installer_finished_timestamp_seconds{%s} %d\n", revision, now.Unix())
+
+	phases := make([]string, 0, len(s.Phases))
+	for phase := range s.Phases {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	gauge("kube_apiserver_installer_phase_duration_seconds", "The duration of the phases of the last installer on the node.")
+	for _, phase := range phases {
+		fmt.Fprintf(buf, "kube_apiserver_installer_phase_duration_seconds{%s,phase=%q} %g\n", revision, phase, s.Phases[phase])
+	}
+
+	kinds := make([]string, 0, len(s.Requests))
+	for kind := range s.Requests {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, metric := range []struct {
+		name, help string
+		value      func(*RequestStats) float64
+	}{
+		{name: "kube_apiserver_installer_requests", help: "The requests of the last installer on the node for the secrets and configmaps of the revision.", value: func(r *RequestStats) float64 { return float64(r.Count) }},
+		{name: "kube_apiserver_installer_request_errors", help: "The failed requests of the last installer on the node.", value: func(r *RequestStats) float64 { return float64(r.Errors) }},
+		{name: "kube_apiserver_installer_request_duration_seconds_sum", help: "The summed up latency of the requests of the last installer on the node.", value: func(r *RequestStats) float64 { return r.TotalSeconds }},
+		{name: "kube_apiserver_installer_request_duration_seconds_max", help: "The latency of the slowest request of the last installer on the node.", value: func(r *RequestStats) float64 { return r.MaxSeconds }},
+	} {
+		gauge(metric.name, metric.help)
+		for _, kind := range kinds {
+			fmt.Fprintf(buf, "%s{%s,kind=%q} %g\n", metric.name, revision, kind, metric.value(s.Requests[kind]))
+		}
+	}
+
+	gauge("kube_apiserver_installer_retries", "The requests of the last installer on the node that repeated a failed one.")
+	fmt.Fprintf(buf, "kube_apiserver_installer_retries{%s} %d\n", revision, s.Retries)
+	return buf.Bytes()
+}
+
+// ParseStatus returns the status in the annotation of an installer pod, false when it has none, e.g. when the
+// installer was killed.
+func ParseStatus(pod *corev1.Pod) (*Status, bool) {
+	data, ok := pod.Annotations[StatusAnnotation]
+	if !ok {
+		return nil, false
+	}
+	status := &Status{}
+	if err := json.Unmarshal([]byte(data), status); err != nil || len(status.Revision) == 0 {
+		return nil, false
+	}
+	return status, true
+}
+
+// NewTimedContentSource returns a content source that records the latencies of the requests to the source in the
+// status.
+func NewTimedContentSource(source ContentSource, status *StatusOptions) ContentSource {
+	return timedContentSource{ContentSource: source, status: status}
+}
+
+type timedContentSource struct {
+	ContentSource
+	status *StatusOptions
+}
+
+func (s timedContentSource) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	start := s.status.clock()
+	secret, err := s.ContentSource.GetSecret(ctx, namespace, name)
+	s.status.observeRequest("secret", name, start, err)
+	return secret, err
+}
+
+func (s timedContentSource) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	start := s.status.clock()
+	configMap, err := s.ContentSource.GetConfigMap(ctx, namespace, name)
+	s.status.observeRequest("configmap", name, start, err)
+	return configMap, err
+}
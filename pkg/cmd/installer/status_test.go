@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// flakyContentSource fails the first get of every secret.
+type flakyContentSource struct {
+	ContentSource
+	failed map[string]bool
+}
+
+func (s *flakyContentSource) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	if !s.failed[name] {
+		s.failed[name] = true
+		return nil, errors.New("connection refused")
+	}
+	return s.ContentSource.GetSecret(ctx, namespace, name)
+}
+
+func TestStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installer-status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "installer-7-master-0"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "etcd-client-7"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-7"}, Data: map[string]string{"pod.yaml": "{}"}},
+	)
+	install := &InstallOptions{
+		KubeClient:                    client,
+		Revision:                      "7",
+		NodeName:                      "master-0",
+		Namespace:                     "openshift-kube-apiserver",
+		PodConfigMapNamePrefix:        "kube-apiserver-pod",
+		SecretNamePrefixes:            []string{"etcd-client"},
+		OptionalConfigMapNamePrefixes: []string{"oauth-metadata"},
+	}
+	o := NewStatusOptions()
+	o.TerminationMessageFile = filepath.Join(dir, "termination-log")
+	o.StatusPod = "installer-7-master-0"
+	o.MetricsTextfileDir = dir
+	// every reading of the clock is a second later
+	clock := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	o.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	source := NewTimedContentSource(&flakyContentSource{ContentSource: NewAPIContentSource(client), failed: map[string]bool{}}, o)
+	if _, err := FetchResources(context.TODO(), install, source, 1); err != nil {
+		t.Fatal(err)
+	}
+	o.Phase(PhaseWriteManifest)()
+	if err := o.Write(install, errors.New("failed to copy: no space left on device")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the termination message is only the error
+	data, err := ioutil.ReadFile(o.TerminationMessageFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message := string(data); message != "failed to copy: no space left on device\n" {
+		t.Errorf("expected the error as termination message, got %q", message)
+	}
+	pod, err := client.CoreV1().Pods("openshift-kube-apiserver").Get(context.TODO(), "installer-7-master-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, ok := ParseStatus(pod)
+	if !ok {
+		t.Fatalf("expected a status in %v", pod.Annotations)
+	}
+	if status.Revision != "7" || status.NodeName != "master-0" || status.Error != "failed to copy: no space left on device" {
+		t.Errorf("unexpected status %#v", status)
+	}
+	// the secret is requested twice, the missing optional configmap is no error
+	if status.Retries != 1 || status.Requests["secret"].Count != 2 || status.Requests["secret"].Errors != 1 || status.Requests["configmap"].Count != 2 || status.Requests["configmap"].Errors != 0 {
+		t.Errorf("unexpected requests %+v %+v and retries %d", status.Requests["secret"], status.Requests["configmap"], status.Retries)
+	}
+	if status.Requests["secret"].MaxSeconds != 1 || status.Phases[PhaseWriteManifest] != 1 || status.Phases[PhaseFetchSecrets] == 0 || status.Phases[PhaseFetchConfigMaps] == 0 {
+		t.Errorf("unexpected phases %v and latencies %+v", status.Phases, status.Requests["secret"])
+	}
+
+	textfile, err := ioutil.ReadFile(filepath.Join(dir, metricsTextfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`kube_apiserver_installer_succeeded{revision="7"} 0`,
+		`kube_apiserver_installer_phase_duration_seconds{revision="7",phase="writeManifest"} 1`,
+		`kube_apiserver_installer_requests{revision="7",kind="secret"} 2`,
+		`kube_apiserver_installer_request_errors{revision="7",kind="secret"} 1`,
+		`kube_apiserver_installer_retries{revision="7"} 1`,
+	} {
+		if !strings.Contains(string(textfile), expected+"\n") {
+			t.Errorf("expected %q in\n%s", expected, textfile)
+		}
+	}
+
+	if _, ok := ParseStatus(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{StatusAnnotation: "{"}}}); ok {
+		t.Errorf("expected no status in an invalid annotation")
+	}
+}
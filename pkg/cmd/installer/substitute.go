@@ -0,0 +1,156 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// builtinVariables are replaced by the installer in the configmaps and secrets of a revision when it writes them.
+var builtinVariables = []string{"REVISION", "NODE_NAME", "NODE_ENVVAR_NAME"}
+
+var variablePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// SubstitutionFunc returns template variables for the configmaps and secrets of the revision the installer installs,
+// e.g. values of the node it runs on.
+type SubstitutionFunc func(ctx context.Context, o *InstallOptions) (map[string]string, error)
+
+// Substitutions are the template variables that the installer replaces in the data of the configmaps and secrets of
+// a revision in addition to the built-in REVISION, NODE_NAME and NODE_ENVVAR_NAME. They come from --substitute and
+// from the substitution funcs of the operators embedding the installer.
+type Substitutions struct {
+	flagValues []string
+	funcs      []SubstitutionFunc
+}
+
+func NewSubstitutions() *Substitutions {
+	return &Substitutions{}
+}
+
+// WithSubstitutionFunc registers a func that returns additional template variables.
+func (s *Substitutions) WithSubstitutionFunc(fn SubstitutionFunc) *Substitutions {
+	s.funcs = append(s.funcs, fn)
+	return s
+}
+
+func (s *Substitutions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringArrayVar(&s.flagValues, "substitute", s.flagValues, "A KEY=VALUE template variable to replace in the configmaps and secrets of the revision, can be repeated")
+}
+
+// Validate verifies the inputs.
+func (s *Substitutions) Validate() error {
+	_, err := s.parseFlags()
+	return err
+}
+
+func (s *Substitutions) parseFlags() (map[string]string, error) {
+	values := map[string]string{}
+	for _, flag := range s.flagValues {
+		parts := strings.SplitN(flag, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("--substitute must be KEY=VALUE, got %q", flag)
+		}
+		if _, ok := values[parts[0]]; ok {
+			return nil, fmt.Errorf("--substitute sets %s twice", parts[0])
+		}
+		if err := validateVariable(parts[0]); err != nil {
+			return nil, fmt.Errorf("--substitute: %v", err)
+		}
+		values[parts[0]] = parts[1]
+	}
+	return values, nil
+}
+
+// Resolve returns the variables of the flags and of the substitution funcs. A variable must not be set twice or
+// collide with a built-in one.
+func (s *Substitutions) Resolve(ctx context.Context, o *InstallOptions) (map[string]string, error) {
+	values, err := s.parseFlags()
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range s.funcs {
+		funcValues, err := fn(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range funcValues {
+			if _, ok := values[key]; ok {
+				return nil, fmt.Errorf("template variable %s is set twice", key)
+			}
+			if err := validateVariable(key); err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// validateVariable rejects variables that would replace a part of a built-in one, which the installer replaces
+// afterwards, or that would be replaced by them.
+func validateVariable(key string) error {
+	if !variablePattern.MatchString(key) {
+		return fmt.Errorf("template variable %q must consist of upper case letters, digits and underscores", key)
+	}
+	for _, builtin := range builtinVariables {
+		if strings.Contains(builtin, key) || strings.Contains(key, builtin) {
+			return fmt.Errorf("template variable %s collides with the built-in %s", key, builtin)
+		}
+	}
+	return nil
+}
+
+// NewSubstitutingContentSource returns a content source that replaces the variables in the data of the configmaps and
+// secrets of the source. Longer variables take precedence, so that a variable that is a part of another one does not
+// break it.
+func NewSubstitutingContentSource(source ContentSource, values map[string]string) ContentSource {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	oldnew := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		oldnew = append(oldnew, key, values[key])
+	}
+	return substitutingContentSource{ContentSource: source, replacer: strings.NewReplacer(oldnew...)}
+}
+
+type substitutingContentSource struct {
+	ContentSource
+	replacer *strings.Replacer
+}
+
+func (s substitutingContentSource) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	secret, err := s.ContentSource.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	secret = secret.DeepCopy()
+	for key, value := range secret.Data {
+		secret.Data[key] = []byte(s.replacer.Replace(string(value)))
+	}
+	return secret, nil
+}
+
+func (s substitutingContentSource) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	configMap, err := s.ContentSource.GetConfigMap(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	configMap = configMap.DeepCopy()
+	for key, value := range configMap.Data {
+		configMap.Data[key] = s.replacer.Replace(value)
+	}
+	return configMap, nil
+}
@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSubstitutions(t *testing.T) {
+	nodeValues := func(values map[string]string) SubstitutionFunc {
+		return func(context.Context, *InstallOptions) (map[string]string, error) {
+			return values, nil
+		}
+	}
+	tests := []struct {
+		name        string
+		flags       []string
+		funcs       []SubstitutionFunc
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "flags and funcs",
+			flags:    []string{"IP_FAMILY=ipv6", "ENDPOINT=https://10.0.0.1:6443?a=b"},
+			funcs:    []SubstitutionFunc{nodeValues(map[string]string{"CGROUP_DRIVER": "systemd"})},
+			expected: map[string]string{"IP_FAMILY": "ipv6", "ENDPOINT": "https://10.0.0.1:6443?a=b", "CGROUP_DRIVER": "systemd"},
+		},
+		{name: "no value", flags: []string{"IP_FAMILY"}, expectedErr: "must be KEY=VALUE"},
+		{name: "lower case", flags: []string{"ip_family=ipv6"}, expectedErr: "upper case"},
+		{name: "built-in", flags: []string{"REVISION=1"}, expectedErr: "collides with the built-in REVISION"},
+		{name: "part of a built-in", flags: []string{"NODE=master-0"}, expectedErr: "collides with the built-in NODE_NAME"},
+		{name: "built-in as a part", funcs: []SubstitutionFunc{nodeValues(map[string]string{"OLD_REVISION": "1"})}, expectedErr: "collides with the built-in REVISION"},
+		{name: "set twice", flags: []string{"IP_FAMILY=ipv6"}, funcs: []SubstitutionFunc{nodeValues(map[string]string{"IP_FAMILY": "ipv4"})}, expectedErr: "IP_FAMILY is set twice"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewSubstitutions()
+			s.flagValues = test.flags
+			for _, fn := range test.funcs {
+				s.WithSubstitutionFunc(fn)
+			}
+			values, err := s.Resolve(context.TODO(), &InstallOptions{})
+			switch {
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Fatal(err)
+			case len(test.expectedErr) > 0 && (err == nil || !strings.Contains(err.Error(), test.expectedErr)):
+				t.Fatalf("expected %q, got %v", test.expectedErr, err)
+			case len(test.expectedErr) == 0 && len(values) != len(test.expected):
+				t.Fatalf("expected %v, got %v", test.expected, values)
+			}
+			for key, value := range test.expected {
+				if values[key] != value {
+					t.Errorf("expected %s=%s, got %q", key, value, values[key])
+				}
+			}
+		})
+	}
+}
+
+func TestSubstitutingContentSource(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "config-3"}, Data: map[string]string{"config.yaml": "family: IP_FAMILY_LIST\nfamily: IP_FAMILY\nrevision: REVISION\n"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "webhook-3"}, Data: map[string][]byte{"kubeconfig": []byte("server: ENDPOINT")}},
+	)
+	source := NewSubstitutingContentSource(NewAPIContentSource(client), map[string]string{"IP_FAMILY": "ipv6", "IP_FAMILY_LIST": "ipv6,ipv4", "ENDPOINT": "https://10.0.0.1:6443"})
+
+	configMap, err := source.GetConfigMap(context.TODO(), "openshift-kube-apiserver", "config-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the built-ins are left to the installer
+	if expected := "family: ipv6,ipv4\nfamily: ipv6\nrevision: REVISION\n"; configMap.Data["config.yaml"] != expected {
+		t.Errorf("expected %q, got %q", expected, configMap.Data["config.yaml"])
+	}
+	secret, err := source.GetSecret(context.TODO(), "openshift-kube-apiserver", "webhook-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "server: https://10.0.0.1:6443"; string(secret.Data["kubeconfig"]) != expected {
+		t.Errorf("expected %q, got %q", expected, string(secret.Data["kubeconfig"]))
+	}
+	if _, err := source.GetSecret(context.TODO(), "openshift-kube-apiserver", "missing-3"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing secret to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// verifyOpts are the options of the installer verify command.
+type verifyOpts struct {
+	revision       string
+	podPrefix      string
+	resourceDir    string
+	podManifestDir string
+	output         string
+	out            io.Writer
+}
+
+// NewVerifyCommand creates the verify command. It recomputes the digests of the files of an installed revision on the
+// node and reports the files that were modified, removed or added since the installer wrote them. It fails when any
+// file drifted.
+func NewVerifyCommand() *cobra.Command {
+	o := &verifyOpts{
+		podPrefix:      "kube-apiserver-pod",
+		resourceDir:    "/etc/kubernetes/static-pod-resources",
+		podManifestDir: "/etc/kubernetes/manifests",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the files of an installed revision against the digests of the installer",
+		Run: func(cmd *cobra.Command, args []string) {
+			o.out = cmd.OutOrStdout()
+			if err := o.Validate(); err != nil {
+				klog.Exit(err)
+			}
+			if err := o.Run(); err != nil {
+				klog.Exit(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *verifyOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.revision, "revision", o.revision, "The revision to verify")
+	fs.StringVar(&o.podPrefix, "pod", o.podPrefix, "The name of the configmap of the static pod, which prefixes the resource dirs of the revisions")
+	fs.StringVar(&o.resourceDir, "resource-dir", o.resourceDir, "The directory of the resources of the revisions")
+	fs.StringVar(&o.podManifestDir, "pod-manifest-dir", o.podManifestDir, "The directory of the static pod manifests")
+	fs.StringVar(&o.output, "output", o.output, "Print the report as, one of: json")
+}
+
+// Validate verifies the inputs.
+func (o *verifyOpts) Validate() error {
+	if len(o.revision) == 0 {
+		return fmt.Errorf("--revision is required")
+	}
+	if len(o.output) > 0 && o.output != jsonOutput {
+		return fmt.Errorf("--output must be %q, got %q", jsonOutput, o.output)
+	}
+	return nil
+}
+
+// Run prints the drift of the revision and returns an error if there is any.
+func (o *verifyOpts) Run() error {
+	report, err := VerifyInstalledRevision(o.resourceDir, o.podManifestDir, o.podPrefix, o.revision)
+	if err != nil {
+		return err
+	}
+	if o.output == jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(o.out, string(data)); err != nil {
+			return err
+		}
+	} else {
+		manifest := "the static pod manifest is not installed"
+		if len(report.ManifestRevision) > 0 {
+			manifest = fmt.Sprintf("the static pod manifest runs revision %s", report.ManifestRevision)
+		}
+		if _, err := fmt.Fprintf(o.out, "Revision %s, %s: %d files drifted\n", report.Revision, manifest, len(report.Drift)); err != nil {
+			return err
+		}
+		for _, drift := range report.Drift {
+			if _, err := fmt.Fprintf(o.out, "%s %s\n", drift.Change, drift.Path); err != nil {
+				return err
+			}
+		}
+	}
+	if len(report.Drift) > 0 {
+		return fmt.Errorf("revision %s drifted from what the installer wrote", o.revision)
+	}
+	return nil
+}
@@ -1,10 +1,22 @@
 package operator
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
+	"github.com/openshift/library-go/pkg/config/client"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 )
 
@@ -15,5 +27,106 @@ func NewOperator() *cobra.Command {
 	cmd.Use = "operator"
 	cmd.Short = "Start the Cluster kube-apiserver Operator"
 
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if err := applyLeaderElectionTuning(cmd.Flags()); err != nil {
+			klog.Warningf("Using the default leader election: %v", err)
+		}
+		run(cmd, args)
+	}
+
 	return cmd
 }
+
+// applyLeaderElectionTuning adds the leader election durations of the operator config to the config file of the
+// operator. library-go only reads them from the config file, which is managed by the cluster version operator, so a
+// copy with the durations is passed instead, and the operator still terminates when the original file changes.
+func applyLeaderElectionTuning(flags *pflag.FlagSet) error {
+	kubeConfigFile, err := flags.GetString("kubeconfig")
+	if err != nil {
+		return err
+	}
+	configFile, err := flags.GetString("config")
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := client.GetKubeConfigOrInClusterConfig(kubeConfigFile, nil)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	operatorConfig, err := operatorconfig.GetFromClient(ctx, kubeClient.CoreV1())
+	if err != nil {
+		return err
+	}
+	leaderElection := operatorConfig.Operator.LeaderElection
+	if leaderElection == (operatorconfig.OperatorLeaderElectionConfig{}) {
+		return nil
+	}
+	if errs := operatorconfig.ValidateOperatorTuning(operatorConfig.Operator, field.NewPath("operator")); len(errs) > 0 {
+		return fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+
+	content := []byte("apiVersion: operator.openshift.io/v1alpha1\nkind: GenericOperatorConfig\n")
+	if len(configFile) > 0 {
+		if content, err = ioutil.ReadFile(configFile); err != nil {
+			return err
+		}
+	}
+	tunedContent, err := withLeaderElection(content, leaderElection)
+	if err != nil {
+		return fmt.Errorf("%s: %v", configFile, err)
+	}
+	tunedFile, err := ioutil.TempFile("", "kube-apiserver-operator-config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer tunedFile.Close()
+	if _, err := tunedFile.Write(tunedContent); err != nil {
+		return err
+	}
+
+	if len(configFile) > 0 {
+		if err := flags.Set("terminate-on-files", configFile); err != nil {
+			return err
+		}
+	}
+	if err := flags.Set("config", tunedFile.Name()); err != nil {
+		return err
+	}
+	leaseDuration, renewDeadline, retryPeriod := operatorconfig.LeaderElectionDurations(leaderElection)
+	klog.Infof("Using the leader election of the operator config: lease duration %v, renew deadline %v, retry period %v", leaseDuration, renewDeadline, retryPeriod)
+	return nil
+}
+
+// withLeaderElection returns the config file content with the leader election durations that are set.
+func withLeaderElection(content []byte, leaderElection operatorconfig.OperatorLeaderElectionConfig) ([]byte, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	existing, ok := config["leaderElection"].(map[string]interface{})
+	if !ok {
+		existing = map[string]interface{}{}
+	}
+	for key, value := range map[string]string{
+		"leaseDuration": leaderElection.LeaseDuration,
+		"renewDeadline": leaderElection.RenewDeadline,
+		"retryPeriod":   leaderElection.RetryPeriod,
+	} {
+		if len(value) > 0 {
+			existing[key] = value
+		}
+	}
+	config["leaderElection"] = existing
+	return yaml.Marshal(config)
+}
@@ -0,0 +1,31 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestWithLeaderElection(t *testing.T) {
+	content := []byte(`apiVersion: operator.openshift.io/v1
+kind: GenericOperatorConfig
+leaderElection:
+  namespace: openshift-kube-apiserver-operator
+  retryPeriod: 20s
+`)
+	tuned, err := withLeaderElection(content, operatorconfig.OperatorLeaderElectionConfig{LeaseDuration: "270s", RenewDeadline: "240s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: operator.openshift.io/v1
+kind: GenericOperatorConfig
+leaderElection:
+  leaseDuration: 270s
+  namespace: openshift-kube-apiserver-operator
+  renewDeadline: 240s
+  retryPeriod: 20s
+`
+	if string(tuned) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, tuned)
+	}
+}
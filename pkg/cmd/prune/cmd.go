@@ -0,0 +1,243 @@
+package prune
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
+)
+
+const (
+	jsonOutput = "json"
+
+	retained = "Retained"
+	removed  = "Removed"
+)
+
+// pruneOpts are the options of the prune command of library-go with the output of the report, the pruning of
+// orphaned artifacts and a dry-run.
+type pruneOpts struct {
+	*prune.PruneOptions
+
+	orphans        bool
+	podManifestDir string
+	dryRun         bool
+
+	output string
+	out    io.Writer
+}
+
+// Report is what the prune command retained and removed in the resource dir of the node.
+type Report struct {
+	ResourceDir         string           `json:"resourceDir"`
+	StaticPodName       string           `json:"staticPodName"`
+	MaxEligibleRevision int              `json:"maxEligibleRevision"`
+	ProtectedRevisions  []int            `json:"protectedRevisions,omitempty"`
+	Revisions           []RevisionReport `json:"revisions"`
+	// Orphans are the orphaned artifacts prune removes with --orphans
+	Orphans []OrphanReport `json:"orphans,omitempty"`
+	// RetainedBytes and RemovedBytes are the disk usage of the retained and the removed revisions and orphans
+	RetainedBytes int64 `json:"retainedBytes"`
+	RemovedBytes  int64 `json:"removedBytes"`
+	// DryRun is true when nothing was removed
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// RevisionReport is what the prune command did to the resource dir of a revision.
+type RevisionReport struct {
+	Revision int    `json:"revision"`
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason"`
+}
+
+// NewPrune creates the prune command of library-go with --output=json, which prints a report of the retained and
+// removed revisions and their disk usage for node-level automation and must-gather.
+func NewPrune() *cobra.Command {
+	o := &pruneOpts{PruneOptions: prune.NewPruneOptions()}
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Prune static pod installer revisions",
+		Run: func(cmd *cobra.Command, args []string) {
+			klog.V(1).Info(cmd.Flags())
+			klog.V(1).Info(spew.Sdump(o))
+
+			o.out = cmd.OutOrStdout()
+			if err := o.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *pruneOpts) AddFlags(fs *pflag.FlagSet) {
+	o.PruneOptions.AddFlags(fs)
+	fs.BoolVar(&o.orphans, "orphans", o.orphans, "Also remove the configmaps and secrets of --cert-dir that neither the operator nor a retained revision uses, and the kube-apiserver static pod manifests of --pod-manifest-dir that no retained revision has with their backup and temporary copies.")
+	fs.StringVar(&o.podManifestDir, "pod-manifest-dir", o.podManifestDir, "Directory of the static pod manifests, e.g. /etc/kubernetes/manifests. Empty means --orphans leaves the manifests alone.")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print the paths that would be removed, one per line with the reason, or the report with --output=json, and remove nothing.")
+	fs.StringVar(&o.output, "output", o.output, "Print a report of the retained and removed revisions, one of: json")
+}
+
+// Validate verifies the inputs.
+func (o *pruneOpts) Validate() error {
+	if err := o.PruneOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.output) > 0 && o.output != jsonOutput {
+		return fmt.Errorf("--output must be %q, got %q", jsonOutput, o.output)
+	}
+	if len(o.podManifestDir) > 0 && !o.orphans {
+		return fmt.Errorf("--pod-manifest-dir requires --orphans")
+	}
+	return nil
+}
+
+// Run prunes the revisions and the orphans and prints the report, if requested. The report is taken before pruning
+// so that it has the disk usage of the removed revisions, and a dry-run only prints it.
+func (o *pruneOpts) Run() error {
+	if o.output != jsonOutput && !o.orphans && !o.dryRun {
+		return o.PruneOptions.Run()
+	}
+
+	report, err := newReport(o.PruneOptions)
+	if err != nil {
+		return err
+	}
+	if o.orphans {
+		if report.Orphans, err = o.orphanReport(report); err != nil {
+			return err
+		}
+		for _, orphan := range report.Orphans {
+			report.RemovedBytes += orphan.Bytes
+		}
+	}
+	report.DryRun = o.dryRun
+
+	if !o.dryRun {
+		if err := o.PruneOptions.Run(); err != nil {
+			return err
+		}
+		for _, orphan := range report.Orphans {
+			if err := os.RemoveAll(orphan.Path); err != nil {
+				return err
+			}
+			klog.Infof("Removed %s: %s", orphan.Path, orphan.Reason)
+		}
+	}
+
+	switch {
+	case o.output == jsonOutput:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	case o.dryRun:
+		for _, revision := range report.Revisions {
+			if revision.Action == removed {
+				if _, err := fmt.Fprintf(o.out, "%s\t%s\n", revision.Path, revision.Reason); err != nil {
+					return err
+				}
+			}
+		}
+		for _, orphan := range report.Orphans {
+			if _, err := fmt.Fprintf(o.out, "%s\t%s\n", orphan.Path, orphan.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newReport returns what prune retains and removes in the resource dir, with the same rules as the prune command of
+// library-go: the revisions above the max eligible revision and the protected revisions are retained.
+func newReport(o *prune.PruneOptions) (*Report, error) {
+	files, err := ioutil.ReadDir(o.ResourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	protectedIDs := sets.NewInt(o.ProtectedRevisions...)
+	report := &Report{
+		ResourceDir:         o.ResourceDir,
+		StaticPodName:       o.StaticPodName,
+		MaxEligibleRevision: o.MaxEligibleRevision,
+		ProtectedRevisions:  o.ProtectedRevisions,
+		Revisions:           []RevisionReport{},
+	}
+	for _, file := range files {
+		if !file.IsDir() || !strings.HasPrefix(file.Name(), o.StaticPodName) {
+			continue
+		}
+		fileSplit := strings.Split(file.Name(), o.StaticPodName+"-")
+		revisionID, err := strconv.Atoi(fileSplit[len(fileSplit)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		revision := RevisionReport{
+			Revision: revisionID,
+			Path:     filepath.Join(o.ResourceDir, file.Name()),
+		}
+		revision.Bytes, err = diskUsage(revision.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case protectedIDs.Has(revisionID):
+			revision.Action, revision.Reason = retained, "protected"
+		case revisionID > o.MaxEligibleRevision:
+			revision.Action, revision.Reason = retained, fmt.Sprintf("newer than the max eligible revision %d", o.MaxEligibleRevision)
+		default:
+			revision.Action, revision.Reason = removed, "not protected"
+		}
+
+		if revision.Action == retained {
+			report.RetainedBytes += revision.Bytes
+		} else {
+			report.RemovedBytes += revision.Bytes
+		}
+		report.Revisions = append(report.Revisions, revision)
+	}
+	sort.Slice(report.Revisions, func(i, j int) bool { return report.Revisions[i].Revision < report.Revisions[j].Revision })
+
+	return report, nil
+}
+
+// diskUsage returns the size of the regular files in the dir.
+func diskUsage(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,77 @@
+package prune
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
+)
+
+func TestRunReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for revision, size := range map[string]int{"1": 10, "2": 20, "3": 30, "4": 40} {
+		revisionDir := filepath.Join(dir, "kube-apiserver-pod-"+revision, "configmaps", "config")
+		if err := os.MkdirAll(revisionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(revisionDir, "config.yaml"), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "kube-apiserver-certs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	o := &pruneOpts{
+		PruneOptions: &prune.PruneOptions{
+			MaxEligibleRevision: 3,
+			ProtectedRevisions:  []int{1},
+			ResourceDir:         dir,
+			StaticPodName:       "kube-apiserver-pod",
+		},
+		output: jsonOutput,
+		out:    out,
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(out.Bytes(), report); err != nil {
+		t.Fatalf("failed to decode the report: %v\n%s", err, out.String())
+	}
+	var actions []string
+	for _, revision := range report.Revisions {
+		actions = append(actions, revision.Action+" "+revision.Reason)
+	}
+	if !reflect.DeepEqual(actions, []string{"Retained protected", "Removed not protected", "Removed not protected", "Retained newer than the max eligible revision 3"}) {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+	if report.RetainedBytes != 50 || report.RemovedBytes != 50 {
+		t.Errorf("expected 50 retained and 50 removed bytes, got %d and %d", report.RetainedBytes, report.RemovedBytes)
+	}
+	for revision, exists := range map[string]bool{"1": true, "2": false, "3": false, "4": true} {
+		if _, err := os.Stat(filepath.Join(dir, "kube-apiserver-pod-"+revision)); os.IsNotExist(err) == exists {
+			t.Errorf("expected revision %s to exist: %v, got %v", revision, exists, err)
+		}
+	}
+
+	o.output = "yaml"
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected an error for an unsupported output")
+	}
+}
@@ -0,0 +1,226 @@
+package prune
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/recovery"
+)
+
+// certReference is how the files of a revision reference the cert dir, which is mounted at
+// /etc/kubernetes/static-pod-certs.
+var certReference = regexp.MustCompile(`static-pod-certs/(configmaps|secrets)/([a-z0-9.-]+)/`)
+
+// OrphanReport is an artifact that no retained revision uses, which prune removes with --orphans.
+type OrphanReport struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	Reason string `json:"reason"`
+}
+
+// orphanReport returns the artifacts of the resource dir, the cert dir and the pod manifest dir that neither the
+// operator nor a retained revision of the report uses:
+//   - the configmaps and secrets of the cert dir that the cert syncer of the operator does not sync and the files of
+//     no retained revision reference,
+//   - the static pod manifests of the kube-apiserver namespace that no retained revision has, e.g. of a sidecar pod a
+//     later revision removed, other than the one of the recovery kube-apiserver,
+//   - backup and temporary copies of the static pod manifests,
+//   - the resources of the recovery kube-apiserver without its static pod manifest.
+//
+// The pod manifest dir is only looked at when it is set.
+func (o *pruneOpts) orphanReport(report *Report) ([]OrphanReport, error) {
+	var retainedDirs, allDirs []string
+	for _, revision := range report.Revisions {
+		allDirs = append(allDirs, revision.Path)
+		if revision.Action == retained {
+			retainedDirs = append(retainedDirs, revision.Path)
+		}
+	}
+
+	var orphans []OrphanReport
+	if len(o.CertDir) > 0 {
+		certOrphans, err := certDirOrphans(filepath.Join(o.ResourceDir, o.CertDir), retainedDirs)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, certOrphans...)
+	}
+	if len(o.podManifestDir) > 0 {
+		manifestOrphans, err := o.podManifestOrphans(retainedDirs, allDirs)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, manifestOrphans...)
+
+		recoveryDir := filepath.Join(o.ResourceDir, "recovery-kube-apiserver-pod")
+		if exists, err := pathExists(recoveryDir); err != nil {
+			return nil, err
+		} else if exists {
+			if exists, err := pathExists(filepath.Join(o.podManifestDir, recovery.RecoveryPodFileName)); err != nil {
+				return nil, err
+			} else if !exists {
+				orphans = append(orphans, OrphanReport{Path: recoveryDir, Reason: "resources of a recovery kube-apiserver that is not running"})
+			}
+		}
+	}
+
+	for i := range orphans {
+		var err error
+		if orphans[i].Bytes, err = diskUsage(orphans[i].Path); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Path < orphans[j].Path })
+	return orphans, nil
+}
+
+// certDirOrphans returns the configmaps and secrets of the cert dir that neither the cert syncer of the operator nor a
+// retained revision uses.
+func certDirOrphans(certDir string, retainedDirs []string) ([]OrphanReport, error) {
+	used := map[string]sets.String{"configmaps": sets.NewString(), "secrets": sets.NewString()}
+	for _, resource := range operator.CertConfigMaps {
+		used["configmaps"].Insert(resource.Name)
+	}
+	for _, resource := range operator.CertSecrets {
+		used["secrets"].Insert(resource.Name)
+	}
+	for _, dir := range retainedDirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, match := range certReference.FindAllStringSubmatch(string(data), -1) {
+				used[match[1]].Insert(match[2])
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var orphans []OrphanReport
+	for _, kind := range []string{"configmaps", "secrets"} {
+		files, err := ioutil.ReadDir(filepath.Join(certDir, kind))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !used[kind].Has(file.Name()) {
+				orphans = append(orphans, OrphanReport{
+					Path:   filepath.Join(certDir, kind, file.Name()),
+					Reason: "not synced by the operator and not referenced by a retained revision",
+				})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// podManifestOrphans returns the static pod manifests of the kube-apiserver namespace in the pod manifest dir that no
+// retained revision has, and the backup and temporary copies of the manifests of any revision.
+func (o *pruneOpts) podManifestOrphans(retainedDirs, allDirs []string) ([]OrphanReport, error) {
+	retainedManifests, err := manifestNames(retainedDirs, o.StaticPodName)
+	if err != nil {
+		return nil, err
+	}
+	knownManifests, err := manifestNames(allDirs, o.StaticPodName)
+	if err != nil {
+		return nil, err
+	}
+	knownManifests.Insert(o.StaticPodName+".yaml", recovery.RecoveryPodFileName)
+
+	files, err := ioutil.ReadDir(o.podManifestDir)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []OrphanReport
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		name, path := file.Name(), filepath.Join(o.podManifestDir, file.Name())
+		switch {
+		case strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp") && knownManifests.Has(strings.TrimSuffix(strings.TrimPrefix(name, "."), ".tmp")):
+			orphans = append(orphans, OrphanReport{Path: path, Reason: "temporary copy of a static pod manifest"})
+		case isBackup(name, knownManifests):
+			orphans = append(orphans, OrphanReport{Path: path, Reason: "backup copy of a static pod manifest"})
+		case strings.HasSuffix(name, ".yaml") && !retainedManifests.Has(name) && name != recovery.RecoveryPodFileName:
+			namespace, err := manifestNamespace(path)
+			if err != nil {
+				return nil, err
+			}
+			if namespace == operatorclient.TargetNamespace {
+				orphans = append(orphans, OrphanReport{Path: path, Reason: "static pod manifest of no retained revision"})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// manifestNames returns the names of the static pod manifests in the resource dirs of the revisions, like the
+// installer names them in the pod manifest dir.
+func manifestNames(revisionDirs []string, staticPodName string) (sets.String, error) {
+	names := sets.NewString()
+	for _, dir := range revisionDirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.Mode().IsRegular() && (file.Name() == staticPodName+".yaml" || strings.HasSuffix(file.Name(), "-pod.yaml")) {
+				names.Insert(file.Name())
+			}
+		}
+	}
+	return names, nil
+}
+
+// isBackup returns whether the file is a copy of a known manifest with a suffix, e.g. kube-apiserver-pod.yaml.bak or
+// the timestamped copies of past recoveries.
+func isBackup(name string, knownManifests sets.String) bool {
+	for _, manifest := range knownManifests.List() {
+		if strings.HasPrefix(name, manifest+".") && len(name) > len(manifest)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestNamespace returns the namespace of the object of a manifest, empty for a file that is not one.
+func manifestNamespace(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return "", nil
+	}
+	return obj.Namespace, nil
+}
+
+func pathExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
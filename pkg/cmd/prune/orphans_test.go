@@ -0,0 +1,121 @@
+package prune
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
+)
+
+func TestRunOrphans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	resourceDir, manifestDir := filepath.Join(dir, "static-pod-resources"), filepath.Join(dir, "manifests")
+
+	pod := func(namespace, name string) string {
+		return "apiVersion: v1\nkind: Pod\nmetadata:\n  namespace: " + namespace + "\n  name: " + name + "\n"
+	}
+	files := map[string]string{
+		// the protected revision references a cert the operator does not sync anymore
+		"static-pod-resources/kube-apiserver-pod-1/kube-apiserver-pod.yaml":                pod("openshift-kube-apiserver", "kube-apiserver") + "# /etc/kubernetes/static-pod-certs/secrets/legacy-cert/tls.crt\n",
+		"static-pod-resources/kube-apiserver-pod-2/kube-apiserver-pod.yaml":                pod("openshift-kube-apiserver", "kube-apiserver"),
+		"static-pod-resources/kube-apiserver-pod-2/old-sidecar-pod.yaml":                   pod("openshift-kube-apiserver", "old-sidecar"),
+		"static-pod-resources/kube-apiserver-pod-3/kube-apiserver-pod.yaml":                pod("openshift-kube-apiserver", "kube-apiserver"),
+		"static-pod-resources/kube-apiserver-certs/secrets/aggregator-client/tls.crt":      "cert",
+		"static-pod-resources/kube-apiserver-certs/secrets/legacy-cert/tls.crt":            "cert",
+		"static-pod-resources/kube-apiserver-certs/secrets/stale-cert/tls.crt":             "cert",
+		"static-pod-resources/kube-apiserver-certs/configmaps/stale-ca/ca-bundle.crt":      "ca",
+		"static-pod-resources/recovery-kube-apiserver-pod/config.yaml":                     "config",
+		"manifests/kube-apiserver-pod.yaml":                                                pod("openshift-kube-apiserver", "kube-apiserver"),
+		"manifests/old-sidecar-pod.yaml":                                                   pod("openshift-kube-apiserver", "old-sidecar"),
+		"manifests/etcd-pod.yaml":                                                          pod("openshift-etcd", "etcd"),
+		"manifests/kube-apiserver-pod.yaml.10989-1016-10-161-59-59":                        pod("openshift-kube-apiserver", "kube-apiserver"),
+		"manifests/.kube-apiserver-pod.yaml.tmp":                                           pod("openshift-kube-apiserver", "kube-apiserver"),
+		"static-pod-resources/kube-apiserver-certs/configmaps/client-ca/ca-bundle.crt":     "ca",
+		"static-pod-resources/kube-apiserver-certs/secrets/kubelet-client/tls.crt":         "cert",
+		"static-pod-resources/kube-apiserver-certs/secrets/node-kubeconfigs/lb.kubeconfig": "kubeconfig",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ordered by path
+	orphans := []string{
+		filepath.Join(manifestDir, ".kube-apiserver-pod.yaml.tmp"),
+		filepath.Join(manifestDir, "kube-apiserver-pod.yaml.10989-1016-10-161-59-59"),
+		filepath.Join(manifestDir, "old-sidecar-pod.yaml"),
+		filepath.Join(resourceDir, "kube-apiserver-certs", "configmaps", "stale-ca"),
+		filepath.Join(resourceDir, "kube-apiserver-certs", "secrets", "stale-cert"),
+		filepath.Join(resourceDir, "recovery-kube-apiserver-pod"),
+	}
+
+	out := &bytes.Buffer{}
+	o := &pruneOpts{
+		PruneOptions: &prune.PruneOptions{
+			MaxEligibleRevision: 2,
+			ProtectedRevisions:  []int{1},
+			ResourceDir:         resourceDir,
+			CertDir:             "kube-apiserver-certs",
+			StaticPodName:       "kube-apiserver-pod",
+		},
+		orphans:        true,
+		podManifestDir: manifestDir,
+		dryRun:         true,
+		out:            out,
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		paths = append(paths, strings.Split(line, "\t")[0])
+	}
+	if expected := append([]string{filepath.Join(resourceDir, "kube-apiserver-pod-2")}, orphans...); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected the dry-run to print\n%s\ngot\n%s", strings.Join(expected, "\n"), out.String())
+	}
+	for path := range files {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("expected the dry-run to remove nothing: %v", err)
+		}
+	}
+
+	o.dryRun = false
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	removed := append([]string{filepath.Join(resourceDir, "kube-apiserver-pod-2")}, orphans...)
+	for path := range files {
+		path = filepath.Join(dir, path)
+		expectRemoved := false
+		for _, orphan := range removed {
+			if path == orphan || strings.HasPrefix(path, orphan+"/") {
+				expectRemoved = true
+			}
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) != expectRemoved {
+			t.Errorf("expected %s to be removed: %v, got %v", path, expectRemoved, err)
+		}
+	}
+
+	o.orphans = false
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected an error for --pod-manifest-dir without --orphans")
+	}
+}
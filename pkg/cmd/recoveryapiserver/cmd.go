@@ -0,0 +1,140 @@
+package recoveryapiserver
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/recovery"
+)
+
+// recoveryOpts holds where the static pods and their resources are on the node.
+type recoveryOpts struct {
+	podManifestDir        string
+	staticPodResourcesDir string
+	revision              string
+
+	out io.Writer
+}
+
+// NewRecoveryApiserverCommand creates the recovery-apiserver command. Its create subcommand renders a localhost-only
+// kube-apiserver static pod from the on-disk content of a revision of the node, with freshly generated short-lived
+// certificates and the etcd servers and etcd client certificate of the revision, for bootstrapping a cluster whose
+// kube-apiservers are all down. Its destroy subcommand removes it again.
+func NewRecoveryApiserverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recovery-apiserver",
+		Short: "Create or destroy a localhost recovery kube-apiserver on this node",
+	}
+
+	cmd.AddCommand(newCreateCommand())
+	cmd.AddCommand(newDestroyCommand())
+
+	return cmd
+}
+
+func newCreateCommand() *cobra.Command {
+	opts := newRecoveryOpts()
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Render a localhost recovery kube-apiserver static pod from the latest revision on this node",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Create(); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&opts.revision, "revision", opts.revision, "The revision whose resources the recovery kube-apiserver is created from, the latest one on the node when empty")
+
+	return cmd
+}
+
+func newDestroyCommand() *cobra.Command {
+	opts := newRecoveryOpts()
+	cmd := &cobra.Command{
+		Use:   "destroy",
+		Short: "Remove the localhost recovery kube-apiserver static pod and its resources from this node",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			apiserver := &recovery.Apiserver{PodManifestDir: opts.podManifestDir, StaticPodResourcesDir: opts.staticPodResourcesDir}
+			if err := apiserver.Destroy(); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newRecoveryOpts() *recoveryOpts {
+	return &recoveryOpts{
+		podManifestDir:        "/etc/kubernetes/manifests",
+		staticPodResourcesDir: "/etc/kubernetes/static-pod-resources",
+	}
+}
+
+func (o *recoveryOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.podManifestDir, "pod-manifest-dir", o.podManifestDir, "The directory of the static pod manifests of the kubelet")
+	fs.StringVar(&o.staticPodResourcesDir, "static-pod-resources-dir", o.staticPodResourcesDir, "The directory of the resources of the static pod revisions")
+}
+
+// Validate verifies the inputs.
+func (o *recoveryOpts) Validate() error {
+	if len(o.podManifestDir) == 0 {
+		return fmt.Errorf("--pod-manifest-dir is required")
+	}
+	if len(o.staticPodResourcesDir) == 0 {
+		return fmt.Errorf("--static-pod-resources-dir is required")
+	}
+	if len(o.revision) > 0 {
+		if revision, err := strconv.Atoi(o.revision); err != nil || revision < 1 {
+			return fmt.Errorf("--revision must be a positive number, got %q", o.revision)
+		}
+	}
+	return nil
+}
+
+// Create renders the recovery kube-apiserver and prints how to reach it.
+func (o *recoveryOpts) Create() error {
+	revisionDir := filepath.Join(o.staticPodResourcesDir, "kube-apiserver-pod-"+o.revision)
+	if len(o.revision) == 0 {
+		var err error
+		revisionDir, err = recovery.LatestRevisionDir(o.staticPodResourcesDir)
+		if err != nil {
+			return err
+		}
+	}
+	klog.Infof("Creating the recovery kube-apiserver from %q", revisionDir)
+
+	apiserver := &recovery.Apiserver{
+		PodManifestDir:        o.podManifestDir,
+		StaticPodResourcesDir: o.staticPodResourcesDir,
+		RevisionDir:           revisionDir,
+	}
+	if err := apiserver.Create(); err != nil {
+		return err
+	}
+
+	restConfig, err := apiserver.RestConfig()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.out, "The recovery kube-apiserver will serve on %s once the kubelet started it.\nexport KUBECONFIG=%s\n",
+		restConfig.Host, filepath.Join(apiserver.GetRecoveryResourcesDir(), recovery.AdminKubeconfigFileName))
+	return err
+}
@@ -0,0 +1,162 @@
+package regeneratecertificates
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configversionedclient "github.com/openshift/client-go/config/clientset/versioned"
+	configexternalinformers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/genericoperatorclient"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// regenerateOpts holds how to reach the cluster and which certificates to regenerate.
+type regenerateOpts struct {
+	kubeconfig string
+	certs      []string
+	dryRun     bool
+
+	out io.Writer
+}
+
+// NewRegenerateCertificatesCommand creates the regenerate-certificates command. It runs the cert rotators of the
+// cert-regeneration-controller once and exits: the expired or missing certificates are regenerated, the others are
+// left alone. --certs limits it to the named certificates, so recovering one broken signer does not churn every
+// certificate of the cluster, and --dry-run prints what would be regenerated.
+func NewRegenerateCertificatesCommand() *cobra.Command {
+	opts := regenerateOpts{}
+	cmd := &cobra.Command{
+		Use:   "regenerate-certificates",
+		Short: "Regenerate the expired or missing certificates of the kube-apiserver once",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *regenerateOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster, defaults to the in-cluster config")
+	fs.StringSliceVar(&o.certs, "certs", o.certs, "The certificates to regenerate by the name of their secret, of their signer secret or of their cert rotator, all when empty")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print the certificates that would be regenerated without regenerating them")
+}
+
+// Validate verifies the inputs.
+func (o *regenerateOpts) Validate() error {
+	for _, cert := range o.certs {
+		if len(cert) == 0 {
+			return fmt.Errorf("--certs must not contain empty names")
+		}
+	}
+	return nil
+}
+
+// Run regenerates the certificates once, or prints the plan in dry-run mode.
+func (o *regenerateOpts) Run(ctx context.Context) error {
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	configClient, err := configversionedclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	configInformers := configexternalinformers.NewSharedInformerFactory(configClient, 10*time.Minute)
+	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(
+		kubeClient,
+		operatorclient.GlobalMachineSpecifiedConfigNamespace,
+		operatorclient.GlobalUserSpecifiedConfigNamespace,
+		operatorclient.OperatorNamespace,
+		operatorclient.TargetNamespace,
+	)
+	operatorClient, dynamicInformers, err := genericoperatorclient.NewStaticPodOperatorClient(config, operatorv1.GroupVersion.WithResource("kubeapiservers"))
+	if err != nil {
+		return err
+	}
+	certRotationScale, err := certrotation.GetCertRotationScale(kubeClient, operatorclient.GlobalUserSpecifiedConfigNamespace)
+	if err != nil {
+		return err
+	}
+
+	controller, err := certrotationcontroller.NewCertRotationControllerOnlyWhenExpired(
+		kubeClient,
+		operatorClient,
+		configInformers,
+		kubeInformersForNamespaces,
+		events.NewLoggingEventRecorder("regenerate-certificates"),
+		certRotationScale,
+	)
+	if err != nil {
+		return err
+	}
+	if len(o.certs) > 0 {
+		if err := controller.Select(o.certs); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	configInformers.Start(ctx.Done())
+	kubeInformersForNamespaces.Start(ctx.Done())
+	dynamicInformers.Start(ctx.Done())
+	controller.WaitForReady(ctx.Done())
+
+	regenerations, err := controller.Plan(time.Now())
+	if err != nil {
+		return err
+	}
+	if err := PrintRegenerations(o.out, regenerations, o.dryRun); err != nil {
+		return err
+	}
+	if o.dryRun || len(regenerations) == 0 {
+		return nil
+	}
+	return controller.RunOnce()
+}
+
+// PrintRegenerations prints the certificates that are, or would be in dry-run mode, regenerated.
+func PrintRegenerations(out io.Writer, regenerations []certrotationcontroller.Regeneration, dryRun bool) error {
+	if len(regenerations) == 0 {
+		_, err := fmt.Fprintln(out, "No certificate is expired or missing.")
+		return err
+	}
+	action := "Regenerating"
+	if dryRun {
+		action = "Would regenerate"
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s:\n", action)
+	fmt.Fprintln(w, "SECRET\tREASON\tCONTROLLER")
+	for _, regeneration := range regenerations {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", regeneration.Secret, regeneration.Reason, regeneration.Controller)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,32 @@
+package regeneratecertificates
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+)
+
+func TestPrintRegenerations(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := PrintRegenerations(out, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No certificate is expired or missing.\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	out.Reset()
+	regenerations := []certrotationcontroller.Regeneration{
+		{Controller: "KubeControllerManagerClient", Secret: "openshift-config-managed/kube-controller-manager-client-cert-key", Reason: "missing"},
+	}
+	if err := PrintRegenerations(out, regenerations, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Would regenerate:", "openshift-config-managed/kube-controller-manager-client-cert-key  missing  KubeControllerManagerClient"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out.String())
+		}
+	}
+}
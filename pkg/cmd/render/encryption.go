@@ -0,0 +1,115 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/config/v1"
+
+	"github.com/openshift/library-go/pkg/operator/encryption/crypto"
+	"github.com/openshift/library-go/pkg/operator/encryption/encryptionconfig"
+	"github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/encryption/state"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// encryptionConfigFile is the encryption config of the bootstrap kube-apiserver in the asset input dir.
+	encryptionConfigFile = "encryption-config.yaml"
+	// bootstrapKeyID is the ID of the key render generates, the first key the encryption controllers would create.
+	bootstrapKeyID = "1"
+)
+
+// renderEncryption writes the aescbc encryption config of the bootstrap kube-apiserver to the asset input dir, unless
+// it is there already, and the key and encryption config secrets of the encryption controllers of the operator with
+// the same key to the manifest dir. The encryption controllers take over the key like one they created, so that the
+// cluster encrypts from the first write on and no revision has to be rolled out for it. It returns whether the
+// encryption config was generated.
+func renderEncryption(assetInputDir, manifestDir string) (bool, error) {
+	configPath := filepath.Join(assetInputDir, encryptionConfigFile)
+	key, err := readBootstrapKey(configPath)
+	if err != nil {
+		return false, err
+	}
+	generated := key == nil
+	if generated {
+		key = &state.KeyState{
+			Key:            apiserverconfigv1.Key{Name: bootstrapKeyID, Secret: base64.StdEncoding.EncodeToString(crypto.NewAES256Key())},
+			Mode:           state.AESCBC,
+			InternalReason: "bootstrap",
+		}
+	}
+
+	encryptionState := map[schema.GroupResource]state.GroupResourceState{}
+	for _, gr := range operator.EncryptedResources {
+		encryptionState[gr] = state.GroupResourceState{WriteKey: *key, ReadKeys: []state.KeyState{*key}}
+	}
+	config := encryptionconfig.FromEncryptionState(encryptionState)
+
+	managedConfigSecret, err := encryptionconfig.ToSecret("openshift-config-managed", fmt.Sprintf("%s-%s", encryptionconfig.EncryptionConfSecretName, operatorclient.TargetNamespace), config)
+	if err != nil {
+		return false, err
+	}
+	targetConfigSecret, err := encryptionconfig.ToSecret(operatorclient.TargetNamespace, encryptionconfig.EncryptionConfSecretName, config)
+	if err != nil {
+		return false, err
+	}
+	keySecret, err := secrets.FromKeyState(operatorclient.TargetNamespace, *key)
+	if err != nil {
+		return false, err
+	}
+	keySecret.APIVersion, keySecret.Kind = corev1.SchemeGroupVersion.String(), "Secret"
+
+	if generated {
+		if err := ioutil.WriteFile(configPath, managedConfigSecret.Data[encryptionconfig.EncryptionConfSecretKey], os.FileMode(0600)); err != nil {
+			return false, fmt.Errorf("failed to write the encryption config: %v", err)
+		}
+	}
+	if err := os.MkdirAll(manifestDir, os.ModePerm); err != nil {
+		return false, err
+	}
+	for _, secret := range []*corev1.Secret{keySecret, managedConfigSecret, targetConfigSecret} {
+		data, err := yaml.Marshal(secret)
+		if err != nil {
+			return false, err
+		}
+		path := filepath.Join(manifestDir, fmt.Sprintf("secret-%s-%s.yaml", secret.Namespace, secret.Name))
+		if err := ioutil.WriteFile(path, data, os.FileMode(0600)); err != nil {
+			return false, fmt.Errorf("failed to write %q: %v", path, err)
+		}
+	}
+	return generated, nil
+}
+
+// readBootstrapKey returns the write key of the encryption config render generated before, nil without one.
+func readBootstrapKey(path string) (*state.KeyState, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the encryption config: %v", err)
+	}
+	config, err := encryptionconfig.FromSecret(&corev1.Secret{Data: map[string][]byte{encryptionconfig.EncryptionConfSecretKey: data}})
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption config %s: %v", path, err)
+	}
+	if len(config.Resources) == 0 || len(config.Resources[0].Providers) == 0 || config.Resources[0].Providers[0].AESCBC == nil || len(config.Resources[0].Providers[0].AESCBC.Keys) != 1 {
+		return nil, fmt.Errorf("invalid encryption config %s: the first provider must be aescbc with a single key", path)
+	}
+	key := config.Resources[0].Providers[0].AESCBC.Keys[0]
+	// the encryption controllers take the key ID from the name of the key secret
+	if _, err := strconv.ParseUint(key.Name, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid encryption config %s: the key name %q must be a key ID", path, key.Name)
+	}
+	return &state.KeyState{Key: key, Mode: state.AESCBC, InternalReason: "bootstrap"}, nil
+}
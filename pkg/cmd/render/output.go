@@ -0,0 +1,180 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// filesOutputFormat only writes the rendered files.
+	filesOutputFormat = "files"
+	// kustomizeOutputFormat writes a kustomization.yaml to the asset output directory with the manifests to create in
+	// the cluster as resources.
+	kustomizeOutputFormat = "kustomize"
+	// indexOutputFormat writes render-index.json to the asset output directory, describing every rendered file.
+	indexOutputFormat = "index"
+
+	kustomizationFile = "kustomization.yaml"
+	renderIndexFile   = "render-index.json"
+)
+
+// Purposes of the rendered files in the render index.
+const (
+	// BootstrapManifestPurpose is the static pod of the bootstrap kube-apiserver, run by the kubelet of the bootstrap
+	// host.
+	BootstrapManifestPurpose = "BootstrapManifest"
+	// SystemdUnitPurpose is a systemd unit of the bootstrap kube-apiserver, run by systemd on the bootstrap host.
+	SystemdUnitPurpose = "SystemdUnit"
+	// ManifestPurpose is a manifest to create in the cluster.
+	ManifestPurpose = "Manifest"
+	// BootstrapConfigPurpose is the config of the bootstrap kube-apiserver.
+	BootstrapConfigPurpose = "BootstrapConfig"
+	// BoundServiceAccountSigningKeyPurpose is a key of the bound service account token signing key pair that render
+	// generated into the asset input directory.
+	BoundServiceAccountSigningKeyPurpose = "BoundServiceAccountSigningKey"
+	// EncryptionConfigPurpose is the encryption config of the bootstrap kube-apiserver that render generated into the
+	// asset input directory.
+	EncryptionConfigPurpose = "EncryptionConfig"
+)
+
+// RenderIndex describes every file render wrote.
+type RenderIndex struct {
+	// bootstrapMode is static-pod or systemd
+	BootstrapMode string `json:"bootstrapMode"`
+	// files are ordered by path
+	Files []RenderedFile `json:"files"`
+}
+
+// RenderedFile is a file render wrote.
+type RenderedFile struct {
+	// path is relative to the asset output directory for the files in it, absolute or as given otherwise
+	Path string `json:"path"`
+	// purpose is BootstrapManifest, SystemdUnit, Manifest, BootstrapConfig, BoundServiceAccountSigningKey or
+	// EncryptionConfig
+	Purpose string `json:"purpose"`
+	// apiVersion, kind, namespace and name identify the object of a manifest or bootstrap manifest
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// kustomization is the part of a kustomize.config.k8s.io Kustomization that render writes.
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+// writeOutputFormat writes the kustomization or the render index of the rendered files, nothing for the files format.
+// The generated files are written outside of the asset output directory.
+func (r *renderOpts) writeOutputFormat(generatedFiles []RenderedFile) error {
+	switch r.outputFormat {
+	case kustomizeOutputFormat:
+		index, err := r.renderIndex(nil)
+		if err != nil {
+			return err
+		}
+		k := kustomization{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization", Resources: []string{}}
+		for _, file := range index.Files {
+			if file.Purpose == ManifestPurpose {
+				k.Resources = append(k.Resources, file.Path)
+			}
+		}
+		data, err := yaml.Marshal(k)
+		if err != nil {
+			return err
+		}
+		return writeOutputFile(filepath.Join(r.generic.AssetOutputDir, kustomizationFile), data)
+	case indexOutputFormat:
+		index, err := r.renderIndex(generatedFiles)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutputFile(filepath.Join(r.generic.AssetOutputDir, renderIndexFile), append(data, '\n'))
+	}
+	return nil
+}
+
+// renderIndex lists the files of the output directories of the asset output directory, the bootstrap config and the
+// generated files.
+func (r *renderOpts) renderIndex(generatedFiles []RenderedFile) (*RenderIndex, error) {
+	bootstrapMode := r.bootstrapMode
+	if len(bootstrapMode) == 0 {
+		bootstrapMode = staticPodBootstrapMode
+	}
+	index := &RenderIndex{BootstrapMode: bootstrapMode, Files: []RenderedFile{}}
+	for dir, purpose := range map[string]string{
+		"bootstrap-manifests": BootstrapManifestPurpose,
+		"bootstrap-systemd":   SystemdUnitPurpose,
+		"manifests":           ManifestPurpose,
+	} {
+		files, err := ioutil.ReadDir(filepath.Join(r.generic.AssetOutputDir, dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			rendered := RenderedFile{Path: filepath.Join(dir, file.Name()), Purpose: purpose}
+			if purpose != SystemdUnitPurpose {
+				if err := identifyManifest(filepath.Join(r.generic.AssetOutputDir, rendered.Path), &rendered); err != nil {
+					return nil, err
+				}
+			}
+			index.Files = append(index.Files, rendered)
+		}
+	}
+	index.Files = append(index.Files, RenderedFile{Path: r.outputPath(r.generic.ConfigOutputFile), Purpose: BootstrapConfigPurpose})
+	for _, file := range generatedFiles {
+		index.Files = append(index.Files, RenderedFile{Path: r.outputPath(file.Path), Purpose: file.Purpose})
+	}
+	sort.Slice(index.Files, func(i, j int) bool { return index.Files[i].Path < index.Files[j].Path })
+	return index, nil
+}
+
+// outputPath returns the path relative to the asset output directory if the file is in it, the path as given otherwise.
+func (r *renderOpts) outputPath(path string) string {
+	if rel, err := filepath.Rel(r.generic.AssetOutputDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// identifyManifest sets the apiVersion, kind, namespace and name of the object of a manifest.
+func identifyManifest(path string, rendered *RenderedFile) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	rendered.APIVersion, rendered.Kind = obj.APIVersion, obj.Kind
+	rendered.Namespace, rendered.Name = obj.Namespace, obj.Name
+	return nil
+}
+
+func writeOutputFile(path string, data []byte) error {
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %v", path, err)
+	}
+	return nil
+}
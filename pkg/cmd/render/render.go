@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
@@ -11,8 +12,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
@@ -20,13 +25,21 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	kyaml "k8s.io/apimachinery/pkg/util/yaml"
 	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/client-go/util/cert"
 	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
 
 	configv1 "github.com/openshift/api/config/v1"
 	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/boundsatokensignercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/fips"
+	"github.com/openshift/library-go/pkg/assets"
 	libgoaudit "github.com/openshift/library-go/pkg/operator/apiserver/audit"
 	genericrender "github.com/openshift/library-go/pkg/operator/render"
 	genericrenderoptions "github.com/openshift/library-go/pkg/operator/render/options"
@@ -37,14 +50,49 @@ type renderOpts struct {
 	manifest genericrenderoptions.ManifestOptions
 	generic  genericrenderoptions.GenericOptions
 
-	lockHostPath      string
-	etcdServerURLs    []string
-	etcdServingCA     string
+	lockHostPath   string
+	etcdServerURLs []string
+	etcdServingCA  string
+
+	// etcdCAFile, etcdClientCertFile and etcdClientKeyFile are files on the bootstrap host of an external etcd
+	etcdCAFile         string
+	etcdClientCertFile string
+	etcdClientKeyFile  string
+
 	clusterConfigFile string
 	clusterAuthFile   string
 	infraConfigFile   string
+	bootstrapMode     string
+
+	featureGateConfigFile string
+	featureGates          []string
+
+	// apiServerConfigFile is an apiserver.config.openshift.io manifest, auditProfile overrides its audit profile
+	apiServerConfigFile string
+	auditProfile        string
+
+	serviceNetworkCIDRs []string
+	clusterNetworkCIDRs []string
+
+	outputFormat string
+
+	fips bool
 }
 
+const (
+	// staticPodBootstrapMode renders the bootstrap kube-apiserver as a static pod run by the kubelet.
+	staticPodBootstrapMode = "static-pod"
+	// systemdBootstrapMode renders the bootstrap kube-apiserver as systemd units running podman, for bootstrap hosts
+	// without a kubelet.
+	systemdBootstrapMode = "systemd"
+
+	bootstrapPodManifest = "kube-apiserver-pod.yaml"
+	insecureReadyzUnit   = "bootstrap-kube-apiserver-insecure-readyz.service"
+
+	// serviceNetworkServingCert is the serving cert of the kubernetes service in the asset input dir.
+	serviceNetworkServingCert = "kube-apiserver-service-network-server.crt"
+)
+
 // NewRenderCommand creates a render command.
 func NewRenderCommand() *cobra.Command {
 	renderOpts := renderOpts{
@@ -54,6 +102,8 @@ func NewRenderCommand() *cobra.Command {
 		lockHostPath:   "/var/run/kubernetes/lock",
 		etcdServerURLs: []string{"https://127.0.0.1:2379"},
 		etcdServingCA:  "root-ca.crt",
+		bootstrapMode:  staticPodBootstrapMode,
+		outputFormat:   filesOutputFormat,
 	}
 	cmd := &cobra.Command{
 		Use:   "render",
@@ -83,9 +133,21 @@ func (r *renderOpts) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&r.lockHostPath, "manifest-lock-host-path", r.lockHostPath, "A host path mounted into the apiserver pods to hold lock.")
 	fs.StringArrayVar(&r.etcdServerURLs, "manifest-etcd-server-urls", r.etcdServerURLs, "The etcd server URL, comma separated.")
 	fs.StringVar(&r.etcdServingCA, "manifest-etcd-serving-ca", r.etcdServingCA, "The etcd serving CA.")
+	fs.StringVar(&r.etcdCAFile, "etcd-ca-file", r.etcdCAFile, "Absolute path of the CA bundle of an external etcd on the bootstrap host, instead of --manifest-etcd-serving-ca of the asset input dir.")
+	fs.StringVar(&r.etcdClientCertFile, "etcd-client-cert-file", r.etcdClientCertFile, "Absolute path of the client certificate for an external etcd on the bootstrap host, instead of etcd-client.crt of the asset input dir.")
+	fs.StringVar(&r.etcdClientKeyFile, "etcd-client-key-file", r.etcdClientKeyFile, "Absolute path of the client key for an external etcd on the bootstrap host, instead of etcd-client.key of the asset input dir.")
 	fs.StringVar(&r.clusterConfigFile, "cluster-config-file", r.clusterConfigFile, "Openshift Cluster API Config file.")
 	fs.StringVar(&r.clusterAuthFile, "cluster-auth-file", r.clusterAuthFile, "Openshift Cluster Authentication API Config file.")
 	fs.StringVar(&r.infraConfigFile, "infra-config-file", "", "File containing infrastructure.config.openshift.io manifest.")
+	fs.StringVar(&r.featureGateConfigFile, "feature-gate-config-file", "", "File containing the featuregate.config.openshift.io manifest, its feature set is rendered into the bootstrap config.")
+	fs.StringSliceVar(&r.featureGates, "feature-gates", r.featureGates, "Feature gates of the bootstrap kube-apiserver as Name=true or Name=false, comma separated, on top of the feature set of --feature-gate-config-file.")
+	fs.StringSliceVar(&r.serviceNetworkCIDRs, "service-network-cidrs", r.serviceNetworkCIDRs, "Service network CIDRs, comma separated, the CIDR of the primary IP family first. Two CIDRs of different IP families make a dual-stack cluster. Overrides the service network of --cluster-config-file.")
+	fs.StringSliceVar(&r.clusterNetworkCIDRs, "cluster-network-cidrs", r.clusterNetworkCIDRs, "Cluster (pod) network CIDRs, comma separated, the CIDRs of the primary IP family first. Overrides the cluster network of --cluster-config-file.")
+	fs.StringVar(&r.apiServerConfigFile, "apiserver-config-file", r.apiServerConfigFile, "File containing the apiserver.config.openshift.io manifest. Its audit profile and custom rules are rendered into the bootstrap config, encryption type aescbc renders an encryption key and config for the bootstrap kube-apiserver and the operator.")
+	fs.StringVar(&r.auditProfile, "audit-profile", r.auditProfile, "Audit profile of the bootstrap kube-apiserver, \"Default\", \"WriteRequestBodies\", \"AllRequestBodies\" or \"None\". Overrides the audit profile of --apiserver-config-file.")
+	fs.StringVar(&r.bootstrapMode, "bootstrap-mode", r.bootstrapMode, "How the bootstrap kube-apiserver is run, \"static-pod\" writes bootstrap-manifests/kube-apiserver-pod.yaml, \"systemd\" writes podman units to bootstrap-systemd instead.")
+	fs.BoolVar(&r.fips, "fips", r.fips, "Render for a cluster in FIPS mode: fail if the TLS settings of the bootstrap config or the keys of the asset input directory do not work in FIPS mode.")
+	fs.StringVar(&r.outputFormat, "output-format", r.outputFormat, "What is written on top of the rendered files, \"files\" nothing, \"kustomize\" a kustomization.yaml with the manifests as resources, \"index\" a render-index.json describing every rendered file, both to the asset output directory.")
 }
 
 // Validate verifies the inputs.
@@ -110,6 +172,48 @@ func (r *renderOpts) Validate() error {
 	if len(r.etcdServingCA) == 0 {
 		return errors.New("missing etcd serving CA: --manifest-etcd-serving-ca")
 	}
+	switch r.bootstrapMode {
+	case "", staticPodBootstrapMode, systemdBootstrapMode:
+	default:
+		return fmt.Errorf("invalid --bootstrap-mode %q, must be %q or %q", r.bootstrapMode, staticPodBootstrapMode, systemdBootstrapMode)
+	}
+	switch r.outputFormat {
+	case "", filesOutputFormat, kustomizeOutputFormat, indexOutputFormat:
+	default:
+		return fmt.Errorf("invalid --output-format %q, must be %q, %q or %q", r.outputFormat, filesOutputFormat, kustomizeOutputFormat, indexOutputFormat)
+	}
+
+	for _, etcdServerURLs := range r.etcdServerURLs {
+		if err := validateEtcdServerURLs(etcdServerURLs); err != nil {
+			return fmt.Errorf("invalid --manifest-etcd-server-urls: %v", err)
+		}
+	}
+	if (len(r.etcdClientCertFile) == 0) != (len(r.etcdClientKeyFile) == 0) {
+		return errors.New("--etcd-client-cert-file and --etcd-client-key-file must be set together")
+	}
+	for flag, file := range map[string]string{"--etcd-ca-file": r.etcdCAFile, "--etcd-client-cert-file": r.etcdClientCertFile, "--etcd-client-key-file": r.etcdClientKeyFile} {
+		if len(file) > 0 && !filepath.IsAbs(file) {
+			return fmt.Errorf("%s must be an absolute path on the bootstrap host, got %q", flag, file)
+		}
+	}
+	if _, err := utilnet.ParseCIDRs(r.serviceNetworkCIDRs); err != nil {
+		return fmt.Errorf("invalid --service-network-cidrs: %v", err)
+	}
+	if _, err := utilnet.ParseCIDRs(r.clusterNetworkCIDRs); err != nil {
+		return fmt.Errorf("invalid --cluster-network-cidrs: %v", err)
+	}
+
+	switch configv1.AuditProfileType(r.auditProfile) {
+	case "", configv1.DefaultAuditProfileType, configv1.WriteRequestBodiesAuditProfileType, configv1.AllRequestBodiesAuditProfileType, configv1.NoneAuditProfileType:
+	default:
+		return fmt.Errorf("invalid --audit-profile %q, must be %q, %q, %q or %q", r.auditProfile, configv1.DefaultAuditProfileType, configv1.WriteRequestBodiesAuditProfileType, configv1.AllRequestBodiesAuditProfileType, configv1.NoneAuditProfileType)
+	}
+
+	for _, gate := range r.featureGates {
+		if _, _, err := parseFeatureGate(gate); err != nil {
+			return fmt.Errorf("invalid --feature-gates: %v", err)
+		}
+	}
 
 	if err := validateBoundSATokensSigningKeys(r.generic.AssetInputDir); err != nil {
 		return err
@@ -142,16 +246,28 @@ type TemplateData struct {
 	// EtcdServingCA is the serving CA used by the etcd servers.
 	EtcdServingCA string
 
+	// EtcdCAFile, EtcdClientCertFile and EtcdClientKeyFile are the files of an external etcd on the bootstrap host.
+	// Empty means EtcdServingCA, etcd-client.crt and etcd-client.key of the secrets.
+	EtcdCAFile         string
+	EtcdClientCertFile string
+	EtcdClientKeyFile  string
+
+	// EtcdCertDirs are the directories of the external etcd files, mounted at the same path.
+	EtcdCertDirs []string
+
 	// ClusterCIDR is the IP range for pod IPs.
 	ClusterCIDR []string
 
-	// ServiceClusterIPRange is the IP range for service IPs.
+	// ServiceClusterIPRange is the IP range for service IPs, the one of the primary IP family first.
 	ServiceCIDR []string
 
+	// ServiceNetworkIPs are the IPs of the kubernetes service, the first IP of every service CIDR.
+	ServiceNetworkIPs []string
+
 	// BindAddress is the IP address and port to bind to
 	BindAddress string
 
-	// BindNetwork is the network (tcp4 or tcp6) to bind to
+	// BindNetwork is the network (tcp4, tcp6 or tcp for dual-stack) to bind to
 	BindNetwork string
 
 	// TerminationGracePeriodSeconds is set in pod manifest
@@ -161,6 +277,13 @@ type TemplateData struct {
 	ShutdownDelayDuration string
 
 	ServiceAccountIssuer string
+
+	// FeatureGates are the feature-gates of the kube-apiserver as Name=true or Name=false. Empty means the gates of the
+	// Default feature set in the config overrides template.
+	FeatureGates []string
+
+	// EncryptionProviderConfig is the encryption config of the bootstrap kube-apiserver. Empty means no encryption.
+	EncryptionProviderConfig string
 }
 
 // Run contains the logic of the render command.
@@ -169,6 +292,9 @@ func (r *renderOpts) Run() error {
 		LockHostPath:                  r.lockHostPath,
 		EtcdServerURLs:                r.etcdServerURLs,
 		EtcdServingCA:                 r.etcdServingCA,
+		EtcdCAFile:                    r.etcdCAFile,
+		EtcdClientCertFile:            r.etcdClientCertFile,
+		EtcdClientKeyFile:             r.etcdClientKeyFile,
 		BindAddress:                   "0.0.0.0:6443",
 		BindNetwork:                   "tcp4",
 		TerminationGracePeriodSeconds: 135, // bit more than 70s (minimal termination period) + 60s (apiserver graceful termination)
@@ -198,6 +324,7 @@ func (r *renderOpts) Run() error {
 	boundSAPublicPath := filepath.Join(r.generic.AssetInputDir, "bound-service-account-signing-key.pub")
 	boundSAPrivatePath := filepath.Join(r.generic.AssetInputDir, "bound-service-account-signing-key.key")
 	_, privStatErr := os.Stat(boundSAPrivatePath)
+	var generatedFiles []RenderedFile
 	if privStatErr != nil {
 		if !os.IsNotExist(privStatErr) {
 			return fmt.Errorf("failed to access %s: %v", boundSAPrivatePath, privStatErr)
@@ -216,25 +343,27 @@ func (r *renderOpts) Run() error {
 		if err := ioutil.WriteFile(boundSAPublicPath, pubPEM, os.FileMode(0644)); err != nil {
 			return fmt.Errorf("failed to write public key for bound SA token verification: %v", err)
 		}
+		generatedFiles = append(generatedFiles,
+			RenderedFile{Path: boundSAPrivatePath, Purpose: BoundServiceAccountSigningKeyPurpose},
+			RenderedFile{Path: boundSAPublicPath, Purpose: BoundServiceAccountSigningKeyPurpose},
+		)
 	}
 
-	if len(renderConfig.ClusterCIDR) > 0 {
-		anyIPv4 := false
-		for _, cidr := range renderConfig.ClusterCIDR {
-			cidrBaseIP, _, err := net.ParseCIDR(cidr)
-			if err != nil {
-				return fmt.Errorf("invalid cluster CIDR %q: %v", cidr, err)
-			}
-			if cidrBaseIP.To4() != nil {
-				anyIPv4 = true
-				break
-			}
-		}
-		if !anyIPv4 {
-			// Single-stack IPv6 cluster, so listen on IPv6 not IPv4.
-			renderConfig.BindAddress = "[::]:6443"
-			renderConfig.BindNetwork = "tcp6"
-		}
+	if err := configureExternalEtcd(&renderConfig, time.Now()); err != nil {
+		return err
+	}
+
+	if len(r.serviceNetworkCIDRs) > 0 {
+		renderConfig.ServiceCIDR = r.serviceNetworkCIDRs
+	}
+	if len(r.clusterNetworkCIDRs) > 0 {
+		renderConfig.ClusterCIDR = r.clusterNetworkCIDRs
+	}
+	if err := configureIPFamilies(&renderConfig); err != nil {
+		return err
+	}
+	if err := validateServingCertIPs(filepath.Join(r.generic.AssetInputDir, serviceNetworkServingCert), renderConfig.ServiceNetworkIPs); err != nil {
+		return err
 	}
 
 	if len(r.infraConfigFile) > 0 {
@@ -250,11 +379,59 @@ func (r *renderOpts) Run() error {
 		}
 	}
 
+	if len(r.featureGateConfigFile) > 0 || len(r.featureGates) > 0 {
+		var featureGate *configv1.FeatureGate
+		if len(r.featureGateConfigFile) > 0 {
+			var err error
+			if featureGate, err = getFeatureGate(r.featureGateConfigFile); err != nil {
+				return fmt.Errorf("failed to get feature gate config: %w", err)
+			}
+		}
+		gates, err := featureGates(featureGate, r.featureGates)
+		if err != nil {
+			return err
+		}
+		if len(renderConfig.ServiceCIDR) == 2 && !hasFeatureGate(gates, "IPv6DualStack") {
+			gates = append(gates, "IPv6DualStack=true")
+		}
+		renderConfig.FeatureGates = gates
+	}
+
+	audit := configv1.Audit{Profile: configv1.DefaultAuditProfileType}
+	if len(r.apiServerConfigFile) > 0 {
+		apiServer, err := getAPIServer(r.apiServerConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to get apiserver config: %w", err)
+		}
+		if len(apiServer.Spec.Audit.Profile) > 0 {
+			audit.Profile = apiServer.Spec.Audit.Profile
+		}
+		audit.CustomRules = apiServer.Spec.Audit.CustomRules
+
+		switch apiServer.Spec.Encryption.Type {
+		case "", configv1.EncryptionTypeIdentity:
+		case configv1.EncryptionTypeAESCBC:
+			generated, err := renderEncryption(r.generic.AssetInputDir, filepath.Join(r.generic.AssetOutputDir, "manifests"))
+			if err != nil {
+				return fmt.Errorf("failed to render the encryption config: %w", err)
+			}
+			if generated {
+				generatedFiles = append(generatedFiles, RenderedFile{Path: filepath.Join(r.generic.AssetInputDir, encryptionConfigFile), Purpose: EncryptionConfigPurpose})
+			}
+			renderConfig.EncryptionProviderConfig = "/etc/kubernetes/secrets/" + encryptionConfigFile
+		default:
+			return fmt.Errorf("unsupported encryption type %q in %s", apiServer.Spec.Encryption.Type, r.apiServerConfigFile)
+		}
+	}
+	if len(r.auditProfile) > 0 {
+		audit.Profile = configv1.AuditProfileType(r.auditProfile)
+	}
+
 	if err := r.manifest.ApplyTo(&renderConfig.ManifestConfig); err != nil {
 		return err
 	}
 
-	defaultConfig, err := bootstrapDefaultConfig()
+	defaultConfig, err := bootstrapDefaultConfig(audit)
 	if err != nil {
 		return fmt.Errorf("failed to get default config with audit policy - %s", err)
 	}
@@ -269,10 +446,54 @@ func (r *renderOpts) Run() error {
 		return err
 	}
 
-	return genericrender.WriteFiles(&r.generic, &renderConfig.FileConfig, renderConfig)
+	if r.fips {
+		if err := validateFIPS(r.generic.AssetInputDir, renderConfig.BootstrapConfig); err != nil {
+			return err
+		}
+	}
+
+	if r.bootstrapMode == systemdBootstrapMode {
+		// the manifests and the bootstrap config stay where they are, only the bootstrap pod is replaced by the units
+		if err := genericrender.WriteFiles(&r.generic, &renderConfig.FileConfig, renderConfig, skipFile(bootstrapPodManifest)); err != nil {
+			return err
+		}
+		if err := writeSystemdUnits(&r.generic, renderConfig); err != nil {
+			return err
+		}
+	} else if err := genericrender.WriteFiles(&r.generic, &renderConfig.FileConfig, renderConfig); err != nil {
+		return err
+	}
+	return r.writeOutputFormat(generatedFiles)
+}
+
+// writeSystemdUnits writes the units of the bootstrap-systemd templates to the bootstrap-systemd output directory.
+func writeSystemdUnits(opt *genericrenderoptions.GenericOptions, renderConfig TemplateData) error {
+	predicates := []assets.FileInfoPredicate{onlySystemdUnits}
+	if len(renderConfig.OperatorImage) == 0 {
+		predicates = append(predicates, skipFile(insecureReadyzUnit))
+	}
+	units, err := assets.New(filepath.Join(opt.TemplatesDir, "bootstrap-systemd"), renderConfig, predicates...)
+	if err != nil {
+		return fmt.Errorf("failed rendering systemd units: %v", err)
+	}
+	if err := units.WriteFiles(filepath.Join(opt.AssetOutputDir, "bootstrap-systemd")); err != nil {
+		return fmt.Errorf("failed writing systemd units to %q: %v", filepath.Join(opt.AssetOutputDir, "bootstrap-systemd"), err)
+	}
+	return nil
+}
+
+func onlySystemdUnits(info os.FileInfo) bool {
+	return strings.HasSuffix(info.Name(), ".service")
 }
 
-func bootstrapDefaultConfig() ([]byte, error) {
+func skipFile(name string) assets.FileInfoPredicate {
+	return func(info os.FileInfo) bool {
+		return info.Name() != name
+	}
+}
+
+// bootstrapDefaultConfig returns the default config with the audit policy of the audit config.
+func bootstrapDefaultConfig(audit configv1.Audit) ([]byte, error) {
 	asset := filepath.Join("assets", "config", "defaultconfig.yaml")
 	raw, err := bindata.Asset(asset)
 	if err != nil {
@@ -289,9 +510,9 @@ func bootstrapDefaultConfig() ([]byte, error) {
 		return nil, fmt.Errorf("failed to decode default config into unstructured - %s", err)
 	}
 
-	policy, err := libgoaudit.GetAuditPolicy(configv1.Audit{Profile: configv1.DefaultAuditProfileType})
+	policy, err := libgoaudit.GetAuditPolicy(audit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retreive default audit policy: %v", err)
+		return nil, fmt.Errorf("failed to retreive audit policy: %v", err)
 	}
 	if err := addAuditPolicyToConfig(defaultConfig, policy); err != nil {
 		return nil, fmt.Errorf("failed to add audit policy into default config - %s", err)
@@ -461,6 +682,204 @@ func discoverCIDRsFromClusterAPI(clusterConfigFileData []byte, renderConfig *Tem
 	return nil
 }
 
+// configureIPFamilies validates the IP families of the service and cluster networks, sets the IPs of the kubernetes
+// service and the bind address. Like the network config observer of the operator it binds to IPv6 for an IPv6
+// single-stack cluster and to both IP families for a dual-stack one, so that the bootstrap kube-apiserver serves the
+// same addresses as the kube-apiservers of the operator. Without service network the cluster network decides.
+func configureIPFamilies(renderConfig *TemplateData) error {
+	serviceCIDRs, err := utilnet.ParseCIDRs(renderConfig.ServiceCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid service network: %v", err)
+	}
+	clusterCIDRs, err := utilnet.ParseCIDRs(renderConfig.ClusterCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid cluster network: %v", err)
+	}
+
+	if len(serviceCIDRs) > 2 {
+		return fmt.Errorf("service network %v has more than two CIDRs", renderConfig.ServiceCIDR)
+	}
+	if len(serviceCIDRs) == 2 {
+		if dualStack, _ := utilnet.IsDualStackCIDRs(serviceCIDRs); !dualStack {
+			return fmt.Errorf("service network %v must have one IPv4 and one IPv6 CIDR to be dual-stack", renderConfig.ServiceCIDR)
+		}
+		if dualStack, _ := utilnet.IsDualStackCIDRs(clusterCIDRs); len(clusterCIDRs) > 0 && !dualStack {
+			return fmt.Errorf("cluster network %v must have IPv4 and IPv6 CIDRs for the dual-stack service network %v", renderConfig.ClusterCIDR, renderConfig.ServiceCIDR)
+		}
+	}
+	if len(serviceCIDRs) > 0 && len(clusterCIDRs) > 0 && utilnet.IsIPv6CIDR(serviceCIDRs[0]) != utilnet.IsIPv6CIDR(clusterCIDRs[0]) {
+		return fmt.Errorf("the primary IP family of the service network %v is not the one of the cluster network %v, list the CIDRs of the primary IP family first", renderConfig.ServiceCIDR, renderConfig.ClusterCIDR)
+	}
+
+	renderConfig.ServiceNetworkIPs = nil
+	for _, cidr := range serviceCIDRs {
+		ip, err := utilnet.GetIndexedIP(cidr, 1)
+		if err != nil {
+			return fmt.Errorf("invalid service network CIDR %v: %v", cidr, err)
+		}
+		renderConfig.ServiceNetworkIPs = append(renderConfig.ServiceNetworkIPs, ip.String())
+	}
+
+	cidrs := serviceCIDRs
+	if len(cidrs) == 0 {
+		cidrs = clusterCIDRs
+	}
+	anyIPv4, anyIPv6 := false, false
+	for _, cidr := range cidrs {
+		if utilnet.IsIPv6CIDR(cidr) {
+			anyIPv6 = true
+		} else {
+			anyIPv4 = true
+		}
+	}
+	renderConfig.BindAddress, renderConfig.BindNetwork = "0.0.0.0:6443", "tcp4"
+	switch {
+	case anyIPv4 && anyIPv6:
+		// dual-stack, the IPv6 wildcard address accepts IPv4 connections as well
+		renderConfig.BindAddress, renderConfig.BindNetwork = "[::]:6443", "tcp"
+	case anyIPv6:
+		renderConfig.BindAddress, renderConfig.BindNetwork = "[::]:6443", "tcp6"
+	}
+	return nil
+}
+
+// validateServingCertIPs verifies that the serving cert, if it exists, has the IPs as SANs. Clients of the kubernetes
+// service of every IP family connect by IP.
+func validateServingCertIPs(certFile string, ips []string) error {
+	if len(ips) == 0 {
+		return nil
+	}
+	data, err := ioutil.ReadFile(certFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	certs, err := cert.ParseCertsPEM(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", certFile, err)
+	}
+	for _, ip := range ips {
+		found := false
+		for _, certIP := range certs[0].IPAddresses {
+			if certIP.Equal(net.ParseIP(ip)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is missing the IP SAN %s of the kubernetes service, it has %v", certFile, ip, certs[0].IPAddresses)
+		}
+	}
+	return nil
+}
+
+// configureExternalEtcd sets the directories to mount for the files of an external etcd and verifies the files that
+// can be read: the CA bundle must parse, the client certificate must match the key, be valid at the given time and
+// allow client authentication. Files that do not exist where render runs are only mounted.
+func configureExternalEtcd(data *TemplateData, now time.Time) error {
+	dirs := sets.NewString()
+	for _, file := range []string{data.EtcdCAFile, data.EtcdClientCertFile, data.EtcdClientKeyFile} {
+		if len(file) > 0 {
+			dirs.Insert(filepath.Dir(file))
+		}
+	}
+	data.EtcdCertDirs = dirs.List()
+
+	if len(data.EtcdCAFile) > 0 {
+		caBundle, err := ioutil.ReadFile(data.EtcdCAFile)
+		switch {
+		case os.IsNotExist(err):
+			klog.Warningf("%s does not exist here, it is not verified", data.EtcdCAFile)
+		case err != nil:
+			return err
+		default:
+			if _, err := cert.ParseCertsPEM(caBundle); err != nil {
+				return fmt.Errorf("invalid etcd CA bundle %s: %v", data.EtcdCAFile, err)
+			}
+		}
+	}
+
+	if len(data.EtcdClientCertFile) == 0 {
+		return nil
+	}
+	keyPair, err := tls.LoadX509KeyPair(data.EtcdClientCertFile, data.EtcdClientKeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		klog.Warningf("%s or %s does not exist here, they are not verified", data.EtcdClientCertFile, data.EtcdClientKeyFile)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid etcd client certificate %s and key %s: %v", data.EtcdClientCertFile, data.EtcdClientKeyFile, err)
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid etcd client certificate %s: %v", data.EtcdClientCertFile, err)
+	}
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("etcd client certificate %s is valid from %s to %s only", data.EtcdClientCertFile, leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+	}
+	if len(leaf.ExtKeyUsage) > 0 {
+		clientAuth := false
+		for _, usage := range leaf.ExtKeyUsage {
+			clientAuth = clientAuth || usage == x509.ExtKeyUsageClientAuth || usage == x509.ExtKeyUsageAny
+		}
+		if !clientAuth {
+			return fmt.Errorf("etcd client certificate %s does not allow client authentication", data.EtcdClientCertFile)
+		}
+	}
+	return nil
+}
+
+// validateFIPS verifies that the TLS settings of the bootstrap config and the private keys of the asset input directory
+// work in FIPS mode, so that the bootstrap kube-apiserver does not fail with a crypto error.
+func validateFIPS(assetInputDir string, bootstrapConfig []byte) error {
+	config := &kubecontrolplanev1.KubeAPIServerConfig{}
+	if err := yaml.Unmarshal(bootstrapConfig, config); err != nil {
+		return fmt.Errorf("failed to decode the bootstrap config: %v", err)
+	}
+	errs := fips.ValidateServingInfo(config.ServingInfo.ServingInfo)
+
+	keyFiles, err := filepath.Glob(filepath.Join(assetInputDir, "*.key"))
+	if err != nil {
+		return err
+	}
+	for _, keyFile := range keyFiles {
+		keyPEM, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		if err := fips.ValidatePrivateKey(keyPEM); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", keyFile, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("the bootstrap kube-apiserver does not work in FIPS mode: %v", utilerrors.NewAggregate(errs))
+	}
+	return nil
+}
+
+// validateEtcdServerURLs verifies the comma separated etcd server URLs. IPv6 addresses must be in brackets, otherwise
+// the port cannot be told apart from the address.
+func validateEtcdServerURLs(etcdServerURLs string) error {
+	for _, etcdServerURL := range strings.Split(etcdServerURLs, ",") {
+		u, err := url.Parse(etcdServerURL)
+		if err != nil {
+			return fmt.Errorf("invalid etcd server URL %q: %v", etcdServerURL, err)
+		}
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("etcd server URL %q must be https or http", etcdServerURL)
+		}
+		if len(u.Hostname()) == 0 {
+			return fmt.Errorf("etcd server URL %q has no host", etcdServerURL)
+		}
+		if strings.Count(u.Host, ":") > 1 && !strings.HasPrefix(u.Host, "[") {
+			return fmt.Errorf("the IPv6 address of etcd server URL %q must be in brackets, e.g. https://[fd00::1]:2379", etcdServerURL)
+		}
+	}
+	return nil
+}
+
 func validateBoundSATokensSigningKeys(assetsDir string) error {
 	boundSAPublicPath := filepath.Join(assetsDir, "bound-service-account-signing-key.pub")
 	boundSAPrivatePath := filepath.Join(assetsDir, "bound-service-account-signing-key.key")
@@ -481,8 +900,21 @@ func validateBoundSATokensSigningKeys(assetsDir string) error {
 		} else if pubStatErr == nil {
 			return fmt.Errorf("%s was supplied, but the matching private key is missing", boundSAPublicPath)
 		}
+		return nil
 	}
 
+	// a supplied keypair, e.g. shared by the clusters behind the same issuer, must be usable by the kube-apiserver
+	privateKey, err := ioutil.ReadFile(boundSAPrivatePath)
+	if err != nil {
+		return err
+	}
+	publicKey, err := ioutil.ReadFile(boundSAPublicPath)
+	if err != nil {
+		return err
+	}
+	if err := boundsatokensignercontroller.ValidateKeyPair(privateKey, publicKey); err != nil {
+		return fmt.Errorf("invalid bound service account signing keypair %s and %s: %v", boundSAPrivatePath, boundSAPublicPath, err)
+	}
 	return nil
 }
 
@@ -531,3 +963,113 @@ func getInfrastructure(file string) (*configv1.Infrastructure, error) {
 	}
 	return config, nil
 }
+
+func getAPIServer(file string) (*configv1.APIServer, error) {
+	config := &configv1.APIServer{}
+	yamlData, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	configJson, err := yaml.YAMLToJSON(yamlData)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(configJson, config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func getFeatureGate(file string) (*configv1.FeatureGate, error) {
+	config := &configv1.FeatureGate{}
+	yamlData, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	configJson, err := yaml.YAMLToJSON(yamlData)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(configJson, config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// featureGates returns the feature gates of the feature set of the feature gate config, of the Default feature set
+// without one, with the explicit gates on top. Like the config observer of the operator it renders the enabled gates
+// before the disabled ones and leaves out the gates of FeatureBlacklist, so that the bootstrap kube-apiserver has the
+// same gates as the kube-apiservers of the operator.
+func featureGates(featureGate *configv1.FeatureGate, explicit []string) ([]string, error) {
+	enabled, disabled := configv1.FeatureSets[configv1.Default].Enabled, configv1.FeatureSets[configv1.Default].Disabled
+	if featureGate != nil {
+		switch featureSet := featureGate.Spec.FeatureSet; featureSet {
+		case configv1.CustomNoUpgrade:
+			enabled, disabled = nil, nil
+			if custom := featureGate.Spec.CustomNoUpgrade; custom != nil {
+				enabled, disabled = custom.Enabled, custom.Disabled
+			}
+		default:
+			gates, ok := configv1.FeatureSets[featureSet]
+			if !ok {
+				return nil, fmt.Errorf("unknown feature set %q", featureSet)
+			}
+			enabled, disabled = gates.Enabled, gates.Disabled
+		}
+	}
+
+	var names []string
+	values := map[string]bool{}
+	set := func(name string, value bool) {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+	for _, name := range enabled {
+		set(name, true)
+	}
+	for _, name := range disabled {
+		set(name, false)
+	}
+	for _, gate := range explicit {
+		name, value, err := parseFeatureGate(gate)
+		if err != nil {
+			return nil, err
+		}
+		set(name, value)
+	}
+
+	ret := []string{}
+	for _, name := range names {
+		if configobservercontroller.FeatureBlacklist.Has(name) {
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("%s=%t", name, values[name]))
+	}
+	return ret, nil
+}
+
+// parseFeatureGate parses a feature gate of the form Name=true or Name=false.
+func parseFeatureGate(gate string) (string, bool, error) {
+	parts := strings.SplitN(gate, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 {
+		return "", false, fmt.Errorf("feature gate %q is not of the form Name=true or Name=false", gate)
+	}
+	value, err := strconv.ParseBool(parts[1])
+	if err != nil {
+		return "", false, fmt.Errorf("feature gate %q is not of the form Name=true or Name=false", gate)
+	}
+	return parts[0], value, nil
+}
+
+func hasFeatureGate(gates []string, name string) bool {
+	for _, gate := range gates {
+		if strings.HasPrefix(gate, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,11 @@ package render
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -10,18 +14,28 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/config/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
 
 	configv1 "github.com/openshift/api/config/v1"
 	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/library-go/pkg/crypto"
 	libgoaudit "github.com/openshift/library-go/pkg/operator/apiserver/audit"
+	"github.com/openshift/library-go/pkg/operator/encryption/encryptionconfig"
+	"github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/encryption/state"
 	genericrenderoptions "github.com/openshift/library-go/pkg/operator/render/options"
 	kyaml "k8s.io/apimachinery/pkg/util/yaml"
 )
@@ -292,6 +306,39 @@ func TestRenderCommand(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "checks feature gates of a feature gate manifest",
+			args: []string{
+				"--asset-input-dir=" + assetsInputDir,
+				"--templates-input-dir=" + templateDir,
+				"--feature-gate-config-file=" + filepath.Join(assetsInputDir, "featuregate.yaml"),
+				"--feature-gates=Bar=true,Baz=false",
+				"--asset-output-dir=",
+				"--config-output-file=",
+			},
+			setupFunction: func() error {
+				data := `apiVersion: config.openshift.io/v1
+kind: FeatureGate
+metadata:
+  name: cluster
+spec:
+  featureSet: CustomNoUpgrade
+  customNoUpgrade:
+    enabled:
+    - Foo
+    disabled:
+    - Bar`
+				return ioutil.WriteFile(filepath.Join(assetsInputDir, "featuregate.yaml"), []byte(data), 0644)
+			},
+			testFunction: func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error {
+				gates := cfg.APIServerArguments["feature-gates"]
+				expectedGates := kubecontrolplanev1.Arguments{"Foo=true", "Bar=true", "Baz=false"}
+				if !reflect.DeepEqual(gates, expectedGates) {
+					return fmt.Errorf("expected the feature-gates to be %q, but they were %q", expectedGates, gates)
+				}
+				return nil
+			},
+		},
 		{
 			name: "checks BindAddress under IPv6",
 			args: []string{
@@ -327,10 +374,10 @@ func TestRenderCommand(t *testing.T) {
 				return ioutil.WriteFile(filepath.Join(assetsInputDir, "config-dual.yaml"), []byte(networkConfigDual), 0644)
 			},
 			testFunction: func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error {
-				if cfg.ServingInfo.BindAddress != "0.0.0.0:6443" {
+				if cfg.ServingInfo.BindAddress != "[::]:6443" {
 					return fmt.Errorf("incorrect dual-stack BindAddress: %s", cfg.ServingInfo.BindAddress)
 				}
-				if cfg.ServingInfo.BindNetwork != "tcp4" {
+				if cfg.ServingInfo.BindNetwork != "tcp" {
 					return fmt.Errorf("incorrect dual-stack BindNetwork: %s", cfg.ServingInfo.BindNetwork)
 				}
 				if cfg.ServicesSubnet != "fd02::/112,172.30.0.0/16" {
@@ -339,6 +386,38 @@ func TestRenderCommand(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "checks the network flags under dual IPv4-IPv6",
+			args: []string{
+				"--asset-input-dir=" + assetsInputDir,
+				"--templates-input-dir=" + templateDir,
+				"--cluster-config-file=" + filepath.Join(assetsInputDir, "config-dual.yaml"),
+				"--service-network-cidrs=172.30.0.0/16,fd02::/112",
+				"--cluster-network-cidrs=10.128.0.0/14,fd01::/48",
+				"--manifest-etcd-server-urls=https://192.168.1.10:2379,https://[fd00::10]:2379",
+				"--asset-output-dir=",
+				"--config-output-file=",
+			},
+			setupFunction: func() error {
+				return ioutil.WriteFile(filepath.Join(assetsInputDir, "config-dual.yaml"), []byte(networkConfigDual), 0644)
+			},
+			testFunction: func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error {
+				if cfg.ServingInfo.BindAddress != "[::]:6443" || cfg.ServingInfo.BindNetwork != "tcp" {
+					return fmt.Errorf("incorrect dual-stack BindAddress and BindNetwork: %s %s", cfg.ServingInfo.BindAddress, cfg.ServingInfo.BindNetwork)
+				}
+				if cfg.ServicesSubnet != "172.30.0.0/16,fd02::/112" {
+					return fmt.Errorf("incorrect dual-stack ServicesSubnet: %s", cfg.ServicesSubnet)
+				}
+				names := cfg.ServingInfo.NamedCertificates[0].Names
+				if !sets.NewString(names...).HasAll("172.30.0.1", "fd02::1") {
+					return fmt.Errorf("missing kubernetes service IPs in the names of the service network serving cert: %v", names)
+				}
+				if etcdServers := cfg.APIServerArguments["etcd-servers"]; !reflect.DeepEqual(etcdServers, kubecontrolplanev1.Arguments{"https://192.168.1.10:2379,https://[fd00::10]:2379"}) {
+					return fmt.Errorf("incorrect etcd-servers: %v", etcdServers)
+				}
+				return nil
+			},
+		},
 		{
 			name: "checks service account issuer when authentication no exists",
 			args: []string{
@@ -451,17 +530,10 @@ spec:
 				"--config-output-file=",
 			},
 			setupFunction: func() error {
-				data := `DUMMY DATA`
 				if err := os.Mkdir(filepath.Join(assetsInputDir, "2"), 0700); err != nil {
 					return err
 				}
-				if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "2", "bound-service-account-signing-key.key"), []byte(data), 0644); err != nil {
-					return err
-				}
-				if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "2", "bound-service-account-signing-key.pub"), []byte(data), 0644); err != nil {
-					return err
-				}
-				return nil
+				return writeBoundSAKeyPair(filepath.Join(assetsInputDir, "2"))
 			},
 			testFunction: func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error {
 				if len(cfg.APIServerArguments["service-account-signing-key-file"]) == 0 {
@@ -537,6 +609,50 @@ spec:
 				return nil
 			},
 		},
+		{
+			name: "audit profile of the apiserver config and the flag",
+			args: []string{
+				"--asset-input-dir=" + assetsInputDir,
+				"--templates-input-dir=" + templateDir,
+				"--asset-output-dir=",
+				"--config-output-file=",
+				"--apiserver-config-file=" + filepath.Join(assetsInputDir, "apiserver.yaml"),
+				"--audit-profile=WriteRequestBodies",
+			},
+			setupFunction: func() error {
+				data := `apiVersion: config.openshift.io/v1
+kind: APIServer
+metadata:
+  name: cluster
+spec:
+  audit:
+    profile: AllRequestBodies
+    customRules:
+    - group: system:authenticated:oauth
+      profile: None`
+				return ioutil.WriteFile(filepath.Join(assetsInputDir, "apiserver.yaml"), []byte(data), 0644)
+			},
+			testFunction: func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error {
+				expected, err := libgoaudit.GetAuditPolicy(configv1.Audit{
+					Profile:     configv1.WriteRequestBodiesAuditProfileType,
+					CustomRules: []configv1.AuditCustomRule{{Group: "system:authenticated:oauth", Profile: configv1.NoneAuditProfileType}},
+				})
+				if err != nil {
+					return err
+				}
+				policy := &auditv1.Policy{}
+				if err := json.Unmarshal(cfg.AuditConfig.PolicyConfiguration.Raw, policy); err != nil {
+					return err
+				}
+				if !equality.Semantic.DeepEqual(expected.Rules, policy.Rules) {
+					return fmt.Errorf("expected the rules of the WriteRequestBodies profile with the custom rule, got %v", policy.Rules)
+				}
+				if _, ok := cfg.APIServerArguments["encryption-provider-config"]; ok {
+					return fmt.Errorf("expected no encryption without an encryption type")
+				}
+				return nil
+			},
+		},
 		{
 			name: "infrastructure file with SNO topology",
 			args: []string{
@@ -624,7 +740,7 @@ spec:
 }
 
 func TestGetDefaultConfigWithAuditPolicy(t *testing.T) {
-	raw, err := bootstrapDefaultConfig()
+	raw, err := bootstrapDefaultConfig(configv1.Audit{Profile: configv1.DefaultAuditProfileType})
 	require.NoError(t, err)
 	require.True(t, len(raw) > 0)
 
@@ -656,6 +772,319 @@ func TestGetDefaultConfigWithAuditPolicy(t *testing.T) {
 	require.True(t, isEqual)
 }
 
+func TestRenderSystemdBootstrapMode(t *testing.T) {
+	assetsInputDir, err := ioutil.TempDir("", "testdata")
+	if err != nil {
+		t.Fatalf("unable to create assets input directory, error: %v", err)
+	}
+	defer os.RemoveAll(assetsInputDir)
+	templateDir := filepath.Join("..", "..", "..", "bindata", "bootkube")
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedUnits []string
+	}{
+		{
+			name: "systemd units without operator image",
+			args: []string{
+				"--asset-input-dir=" + assetsInputDir,
+				"--templates-input-dir=" + templateDir,
+				"--asset-output-dir=",
+				"--config-output-file=",
+				"--bootstrap-mode=systemd",
+			},
+			expectedUnits: []string{"bootstrap-kube-apiserver.service"},
+		},
+		{
+			name: "systemd units with operator image",
+			args: []string{
+				"--asset-input-dir=" + assetsInputDir,
+				"--templates-input-dir=" + templateDir,
+				"--asset-output-dir=",
+				"--config-output-file=",
+				"--bootstrap-mode=systemd",
+				"--manifest-operator-image=quay.io/openshift/cluster-kube-apiserver-operator:test",
+			},
+			expectedUnits: []string{"bootstrap-kube-apiserver-insecure-readyz.service", "bootstrap-kube-apiserver.service"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			teardown, outputDir, err := setupAssetOutputDir(strings.ReplaceAll(test.name, " ", "_"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer teardown()
+
+			if err := runRender(setOutputFlags(test.args, outputDir)...); err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(outputDir, "manifests", "bootstrap-manifests", "kube-apiserver-pod.yaml")); !os.IsNotExist(err) {
+				t.Errorf("expected no bootstrap pod in systemd mode, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "configs", "config.yaml")); err != nil {
+				t.Errorf("expected the bootstrap config to be written: %v", err)
+			}
+
+			files, err := ioutil.ReadDir(filepath.Join(outputDir, "manifests", "bootstrap-systemd"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var units []string
+			for _, file := range files {
+				units = append(units, file.Name())
+			}
+			if !reflect.DeepEqual(test.expectedUnits, units) {
+				t.Fatalf("expected units %v, got %v", test.expectedUnits, units)
+			}
+
+			unit, err := ioutil.ReadFile(filepath.Join(outputDir, "manifests", "bootstrap-systemd", "bootstrap-kube-apiserver.service"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range []string{"TimeoutStopSec=135", "--openshift-config=/etc/kubernetes/config/kube-apiserver-config.yaml", " openshift/origin-hyperkube:latest "} {
+				if !strings.Contains(string(unit), expected) {
+					t.Errorf("expected the unit to contain %q:\n%s", expected, unit)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderOutputFormat(t *testing.T) {
+	assetsInputDir, err := ioutil.TempDir("", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(assetsInputDir)
+	templateDir := filepath.Join("..", "..", "..", "bindata", "bootkube")
+
+	teardown, outputDir, err := setupAssetOutputDir("render_output_format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	args := setOutputFlags([]string{
+		"--asset-input-dir=" + assetsInputDir,
+		"--templates-input-dir=" + templateDir,
+		"--asset-output-dir=",
+		"--config-output-file=",
+	}, outputDir)
+
+	if err := runRender(append(args, "--output-format=index")...); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(outputDir, "manifests", "render-index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := &RenderIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]RenderedFile{}
+	for _, file := range index.Files {
+		files[file.Path] = file
+	}
+	for _, expected := range []RenderedFile{
+		{Path: "bootstrap-manifests/kube-apiserver-pod.yaml", Purpose: BootstrapManifestPurpose, APIVersion: "v1", Kind: "Pod", Namespace: "openshift-kube-apiserver", Name: "bootstrap-kube-apiserver"},
+		{Path: "manifests/00_openshift-kube-apiserver-ns.yaml", Purpose: ManifestPurpose, APIVersion: "v1", Kind: "Namespace", Name: "openshift-kube-apiserver"},
+		{Path: filepath.Join(outputDir, "configs", "config.yaml"), Purpose: BootstrapConfigPurpose},
+		{Path: filepath.Join(assetsInputDir, "bound-service-account-signing-key.key"), Purpose: BoundServiceAccountSigningKeyPurpose},
+	} {
+		if !reflect.DeepEqual(expected, files[expected.Path]) {
+			t.Errorf("expected %+v in the render index, got %+v", expected, files[expected.Path])
+		}
+	}
+	if index.BootstrapMode != staticPodBootstrapMode {
+		t.Errorf("expected bootstrap mode %q, got %q", staticPodBootstrapMode, index.BootstrapMode)
+	}
+
+	if err := runRender(append(args, "--output-format=kustomize")...); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(filepath.Join(outputDir, "manifests", "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &kustomization{}
+	if err := yaml.Unmarshal(data, k); err != nil {
+		t.Fatal(err)
+	}
+	manifests, err := ioutil.ReadDir(filepath.Join(outputDir, "manifests", "manifests"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Resources) != len(manifests) || k.Resources[0] != "manifests/00_openshift-kube-apiserver-ns.yaml" {
+		t.Errorf("expected the %d manifests as resources, got %v", len(manifests), k.Resources)
+	}
+}
+
+func TestRenderFIPS(t *testing.T) {
+	assetsInputDir, err := ioutil.TempDir("", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(assetsInputDir)
+	templateDir := filepath.Join("..", "..", "..", "bindata", "bootkube")
+
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weakKey)})
+	if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "kube-apiserver-lb-server.key"), weakKeyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	teardown, outputDir, err := setupAssetOutputDir("render_fips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	args := setOutputFlags([]string{
+		"--asset-input-dir=" + assetsInputDir,
+		"--templates-input-dir=" + templateDir,
+		"--asset-output-dir=",
+		"--config-output-file=",
+	}, outputDir)
+
+	if err := runRender(args...); err != nil {
+		t.Fatalf("expected the weak key to be accepted without FIPS mode: %v", err)
+	}
+
+	config := &kubecontrolplanev1.KubeAPIServerConfig{}
+	config.ServingInfo.MinTLSVersion = "VersionTLS13"
+	configData, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = validateFIPS(assetsInputDir, configData)
+	if err == nil {
+		t.Fatal("expected the weak key and TLS 1.3 to fail in FIPS mode")
+	}
+	for _, expected := range []string{
+		"servingInfo.minTLSVersion VersionTLS13",
+		filepath.Join(assetsInputDir, "kube-apiserver-lb-server.key") + ": RSA key of 1024 bits is not allowed in FIPS mode",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got %v", expected, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(assetsInputDir, "kube-apiserver-lb-server.key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRender(append(args, "--fips")...); err != nil {
+		t.Errorf("expected the bootstrap config and the generated keys to work in FIPS mode: %v", err)
+	}
+}
+
+func TestRenderEncryption(t *testing.T) {
+	assetsInputDir, err := ioutil.TempDir("", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(assetsInputDir)
+	templateDir := filepath.Join("..", "..", "..", "bindata", "bootkube")
+
+	apiServer := `apiVersion: config.openshift.io/v1
+kind: APIServer
+metadata:
+  name: cluster
+spec:
+  encryption:
+    type: aescbc`
+	if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "apiserver.yaml"), []byte(apiServer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	teardown, outputDir, err := setupAssetOutputDir("render_encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	args := setOutputFlags([]string{
+		"--asset-input-dir=" + assetsInputDir,
+		"--templates-input-dir=" + templateDir,
+		"--asset-output-dir=",
+		"--config-output-file=",
+		"--apiserver-config-file=" + filepath.Join(assetsInputDir, "apiserver.yaml"),
+	}, outputDir)
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		if err := runRender(args...); err != nil {
+			t.Fatal(err)
+		}
+
+		rawConfigFile, err := ioutil.ReadFile(filepath.Join(outputDir, "configs", "config.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := &kubecontrolplanev1.KubeAPIServerConfig{}
+		if err := kyaml.Unmarshal(rawConfigFile, cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got, expected := cfg.APIServerArguments["encryption-provider-config"], (kubecontrolplanev1.Arguments{"/etc/kubernetes/secrets/encryption-config.yaml"}); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected encryption-provider-config %v, got %v", expected, got)
+		}
+
+		key, err := readBootstrapKey(filepath.Join(assetsInputDir, "encryption-config.yaml"))
+		if err != nil || key == nil {
+			t.Fatalf("expected the encryption config in the asset input dir: %v", err)
+		}
+		keys = append(keys, key.Key.Secret)
+
+		// the encryption controllers read the key and the encryption config from the manifests like their own
+		manifestDir := filepath.Join(outputDir, "manifests", "manifests")
+		keySecret := &corev1.Secret{}
+		if err := readManifest(filepath.Join(manifestDir, "secret-openshift-config-managed-encryption-key-openshift-kube-apiserver-1.yaml"), keySecret); err != nil {
+			t.Fatal(err)
+		}
+		keyState, err := secrets.ToKeyState(keySecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keyState.Mode != state.AESCBC || keyState.Key.Secret != key.Key.Secret {
+			t.Errorf("expected the aescbc key of the encryption config, got %+v", keyState)
+		}
+		for _, name := range []string{"secret-openshift-config-managed-encryption-config-openshift-kube-apiserver.yaml", "secret-openshift-kube-apiserver-encryption-config.yaml"} {
+			configSecret := &corev1.Secret{}
+			if err := readManifest(filepath.Join(manifestDir, name), configSecret); err != nil {
+				t.Fatal(err)
+			}
+			encryptionState, _ := encryptionconfig.ToEncryptionState(mustEncryptionConfig(t, configSecret), []*corev1.Secret{keySecret})
+			for _, gr := range operator.EncryptedResources {
+				if writeKey := encryptionState[gr].WriteKey; !state.EqualKeyAndEqualID(&keyState, &writeKey) {
+					t.Errorf("%s: expected the key to be the write key of %s, got %+v", name, gr, writeKey)
+				}
+			}
+		}
+	}
+	if keys[0] != keys[1] {
+		t.Error("expected the second render to keep the key of the encryption config")
+	}
+}
+
+func readManifest(path string, obj interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, obj)
+}
+
+func mustEncryptionConfig(t *testing.T, secret *corev1.Secret) *apiserverconfigv1.EncryptionConfiguration {
+	config, err := encryptionconfig.FromSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
 func setupAssetOutputDir(testName string) (teardown func(), outputDir string, err error) {
 	outputDir, err = ioutil.TempDir("", testName)
 	if err != nil {
@@ -689,6 +1118,130 @@ func setOutputFlags(args []string, dir string) []string {
 	return newArgs
 }
 
+func TestRenderExternalEtcd(t *testing.T) {
+	assetsInputDir, err := ioutil.TempDir("", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(assetsInputDir)
+	templateDir := filepath.Join("..", "..", "..", "bindata", "bootkube")
+
+	etcdDir, err := ioutil.TempDir("", "etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(etcdDir)
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("etcd-signer", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.WriteCertConfigFile(filepath.Join(etcdDir, "ca.crt"), filepath.Join(etcdDir, "ca.key")); err != nil {
+		t.Fatal(err)
+	}
+	signer := &crypto.CA{Config: ca, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	clientCert, err := signer.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "etcd"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := clientCert.WriteCertConfigFile(filepath.Join(etcdDir, "client.crt"), filepath.Join(etcdDir, "client.key")); err != nil {
+		t.Fatal(err)
+	}
+
+	teardown, outputDir, err := setupAssetOutputDir("render_external_etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+	args := setOutputFlags([]string{
+		"--asset-input-dir=" + assetsInputDir,
+		"--templates-input-dir=" + templateDir,
+		"--asset-output-dir=",
+		"--config-output-file=",
+		"--manifest-etcd-server-urls=https://etcd-0.example.com:2379,https://etcd-1.example.com:2379",
+		"--etcd-ca-file=" + filepath.Join(etcdDir, "ca.crt"),
+		"--etcd-client-cert-file=" + filepath.Join(etcdDir, "client.crt"),
+		"--etcd-client-key-file=" + filepath.Join(etcdDir, "client.key"),
+	}, outputDir)
+	if err := runRender(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	configData, err := ioutil.ReadFile(filepath.Join(outputDir, "configs", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &kubecontrolplanev1.KubeAPIServerConfig{}
+	if err := yaml.Unmarshal(configData, config); err != nil {
+		t.Fatal(err)
+	}
+	for arg, expected := range map[string][]string{
+		"etcd-servers":  {"https://etcd-0.example.com:2379,https://etcd-1.example.com:2379"},
+		"etcd-cafile":   {filepath.Join(etcdDir, "ca.crt")},
+		"etcd-certfile": {filepath.Join(etcdDir, "client.crt")},
+		"etcd-keyfile":  {filepath.Join(etcdDir, "client.key")},
+	} {
+		if actual := []string(config.APIServerArguments[arg]); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %s %v, got %v", arg, expected, actual)
+		}
+	}
+
+	podData, err := ioutil.ReadFile(filepath.Join(outputDir, "manifests", "bootstrap-manifests", "kube-apiserver-pod.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal(podData, pod); err != nil {
+		t.Fatal(err)
+	}
+	mounted := false
+	for _, mount := range pod.Spec.Containers[0].VolumeMounts {
+		mounted = mounted || (mount.Name == "etcd-certs-0" && mount.MountPath == etcdDir && mount.ReadOnly)
+	}
+	if !mounted {
+		t.Errorf("expected %s to be mounted, got %v", etcdDir, pod.Spec.Containers[0].VolumeMounts)
+	}
+
+	data := &TemplateData{EtcdCAFile: filepath.Join(etcdDir, "ca.crt"), EtcdClientCertFile: filepath.Join(etcdDir, "client.crt"), EtcdClientKeyFile: filepath.Join(etcdDir, "ca.key")}
+	if err := configureExternalEtcd(data, time.Now()); err == nil || !strings.HasPrefix(err.Error(), "invalid etcd client certificate") {
+		t.Errorf("expected an error for a key of another certificate, got %v", err)
+	}
+	data.EtcdClientKeyFile = filepath.Join(etcdDir, "client.key")
+	if err := configureExternalEtcd(data, time.Now().Add(2*time.Hour)); err == nil || !strings.Contains(err.Error(), "is valid from") {
+		t.Errorf("expected an error for an expired certificate, got %v", err)
+	}
+	serverCert, err := signer.MakeServerCertForDuration(sets.NewString("localhost"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := serverCert.WriteCertConfigFile(filepath.Join(etcdDir, "server.crt"), filepath.Join(etcdDir, "server.key")); err != nil {
+		t.Fatal(err)
+	}
+	data.EtcdClientCertFile, data.EtcdClientKeyFile = filepath.Join(etcdDir, "server.crt"), filepath.Join(etcdDir, "server.key")
+	if err := configureExternalEtcd(data, time.Now()); err == nil || !strings.Contains(err.Error(), "does not allow client authentication") {
+		t.Errorf("expected an error for a serving certificate, got %v", err)
+	}
+	data = &TemplateData{EtcdCAFile: filepath.Join(etcdDir, "missing", "ca.crt")}
+	if err := configureExternalEtcd(data, time.Now()); err != nil || !reflect.DeepEqual(data.EtcdCertDirs, []string{filepath.Join(etcdDir, "missing")}) {
+		t.Errorf("expected a missing file to be mounted only, got %v, %v", data.EtcdCertDirs, err)
+	}
+}
+
+// writeBoundSAKeyPair writes a matching bound service account signing keypair to dir.
+func writeBoundSAKeyPair(dir string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "bound-service-account-signing-key.pub"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "bound-service-account-signing-key.key"), pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+}
+
 func runRender(args ...string) error {
 	c := NewRenderCommand()
 	os.Args = append([]string{""}, args...)
@@ -705,6 +1258,7 @@ func Test_renderOpts_Validate(t *testing.T) {
 	tests := []struct {
 		name          string
 		assetInputDir string
+		auditProfile  string
 		setupFunction func() error
 		testFunction  func(cfg *kubecontrolplanev1.KubeAPIServerConfig) error
 		wantErr       bool
@@ -737,15 +1291,52 @@ func Test_renderOpts_Validate(t *testing.T) {
 			name:          "user provided bound-sa-signing-key - both keys exist",
 			assetInputDir: filepath.Join(assetsInputDir, "2"),
 			setupFunction: func() error {
-				data := `DUMMY DATA`
 				if err := os.Mkdir(filepath.Join(assetsInputDir, "2"), 0700); err != nil {
 					return err
 				}
-				if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "2", "bound-service-account-signing-key.pub"), []byte(data), 0644); err != nil {
+				return writeBoundSAKeyPair(filepath.Join(assetsInputDir, "2"))
+			},
+		},
+		{
+			name:          "user provided bound-sa-signing-key - invalid keys",
+			assetInputDir: filepath.Join(assetsInputDir, "4"),
+			setupFunction: func() error {
+				data := `DUMMY DATA`
+				if err := os.Mkdir(filepath.Join(assetsInputDir, "4"), 0700); err != nil {
+					return err
+				}
+				if err := ioutil.WriteFile(filepath.Join(assetsInputDir, "4", "bound-service-account-signing-key.pub"), []byte(data), 0644); err != nil {
 					return err
 				}
-				return ioutil.WriteFile(filepath.Join(assetsInputDir, "2", "bound-service-account-signing-key.key"), []byte(data), 0600)
+				return ioutil.WriteFile(filepath.Join(assetsInputDir, "4", "bound-service-account-signing-key.key"), []byte(data), 0600)
 			},
+			wantErr: true,
+		},
+		{
+			name:          "user provided bound-sa-signing-key - public key of another keypair",
+			assetInputDir: filepath.Join(assetsInputDir, "5"),
+			setupFunction: func() error {
+				if err := os.MkdirAll(filepath.Join(assetsInputDir, "5", "other"), 0700); err != nil {
+					return err
+				}
+				if err := writeBoundSAKeyPair(filepath.Join(assetsInputDir, "5")); err != nil {
+					return err
+				}
+				if err := writeBoundSAKeyPair(filepath.Join(assetsInputDir, "5", "other")); err != nil {
+					return err
+				}
+				return os.Rename(filepath.Join(assetsInputDir, "5", "other", "bound-service-account-signing-key.pub"), filepath.Join(assetsInputDir, "5", "bound-service-account-signing-key.pub"))
+			},
+			wantErr: true,
+		},
+		{
+			name:          "unknown audit profile",
+			assetInputDir: filepath.Join(assetsInputDir, "6"),
+			auditProfile:  "Everything",
+			setupFunction: func() error {
+				return os.Mkdir(filepath.Join(assetsInputDir, "6"), 0700)
+			},
+			wantErr: true,
 		},
 		{
 			name:          "user provided bound-sa-signing-key - neither key exists",
@@ -777,6 +1368,7 @@ func Test_renderOpts_Validate(t *testing.T) {
 				etcdServingCA:  "root-ca.crt",
 			}
 			r.generic.TemplatesDir = templateDir
+			r.auditProfile = tt.auditProfile
 
 			r.generic.AssetInputDir = tt.assetInputDir
 			r.generic.AssetOutputDir = filepath.Join(outputDir, "manifests")
@@ -788,3 +1380,132 @@ func Test_renderOpts_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureGates(t *testing.T) {
+	techPreview := &configv1.FeatureGate{Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: configv1.TechPreviewNoUpgrade}}}
+	gates, err := featureGates(techPreview, []string{"APIPriorityAndFairness=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLen := len(configv1.FeatureSets[configv1.TechPreviewNoUpgrade].Enabled) + len(configv1.FeatureSets[configv1.TechPreviewNoUpgrade].Disabled)
+	if len(gates) != expectedLen {
+		t.Errorf("expected %d feature gates, got %v", expectedLen, gates)
+	}
+	if !hasFeatureGate(gates, "APIPriorityAndFairness") {
+		t.Errorf("expected APIPriorityAndFairness in %v", gates)
+	}
+	for _, gate := range gates {
+		if strings.HasPrefix(gate, "APIPriorityAndFairness=") && gate != "APIPriorityAndFairness=false" {
+			t.Errorf("expected the explicit gate to override the feature set, got %s", gate)
+		}
+	}
+
+	unknown := &configv1.FeatureGate{Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: "Unknown"}}}
+	if _, err := featureGates(unknown, nil); err == nil {
+		t.Errorf("expected an error for an unknown feature set")
+	}
+	for _, gate := range []string{"Foo", "=true", "Foo=yes"} {
+		if _, err := featureGates(nil, []string{gate}); err == nil {
+			t.Errorf("expected an error for %q", gate)
+		}
+	}
+}
+
+func TestConfigureIPFamilies(t *testing.T) {
+	tests := []struct {
+		name                      string
+		serviceCIDR               []string
+		clusterCIDR               []string
+		expectedBindAddress       string
+		expectedBindNetwork       string
+		expectedServiceNetworkIPs []string
+		expectedErr               string
+	}{
+		{
+			name:                "no network",
+			expectedBindAddress: "0.0.0.0:6443",
+			expectedBindNetwork: "tcp4",
+		},
+		{
+			name:                      "IPv4",
+			serviceCIDR:               []string{"172.30.0.0/16"},
+			clusterCIDR:               []string{"10.128.0.0/14", "10.132.0.0/14"},
+			expectedBindAddress:       "0.0.0.0:6443",
+			expectedBindNetwork:       "tcp4",
+			expectedServiceNetworkIPs: []string{"172.30.0.1"},
+		},
+		{
+			name:                "IPv6 cluster network only",
+			clusterCIDR:         []string{"fd01::/48"},
+			expectedBindAddress: "[::]:6443",
+			expectedBindNetwork: "tcp6",
+		},
+		{
+			name:                      "IPv6-primary dual-stack",
+			serviceCIDR:               []string{"fd02::/112", "172.30.0.0/16"},
+			clusterCIDR:               []string{"fd01::/48", "10.128.0.0/14"},
+			expectedBindAddress:       "[::]:6443",
+			expectedBindNetwork:       "tcp",
+			expectedServiceNetworkIPs: []string{"fd02::1", "172.30.0.1"},
+		},
+		{
+			name:        "three service CIDRs",
+			serviceCIDR: []string{"fd02::/112", "172.30.0.0/16", "172.31.0.0/16"},
+			expectedErr: "service network [fd02::/112 172.30.0.0/16 172.31.0.0/16] has more than two CIDRs",
+		},
+		{
+			name:        "two IPv4 service CIDRs",
+			serviceCIDR: []string{"172.30.0.0/16", "172.31.0.0/16"},
+			expectedErr: "service network [172.30.0.0/16 172.31.0.0/16] must have one IPv4 and one IPv6 CIDR to be dual-stack",
+		},
+		{
+			name:        "single-stack cluster network",
+			serviceCIDR: []string{"fd02::/112", "172.30.0.0/16"},
+			clusterCIDR: []string{"fd01::/48"},
+			expectedErr: "cluster network [fd01::/48] must have IPv4 and IPv6 CIDRs for the dual-stack service network [fd02::/112 172.30.0.0/16]",
+		},
+		{
+			name:        "different primary IP families",
+			serviceCIDR: []string{"172.30.0.0/16", "fd02::/112"},
+			clusterCIDR: []string{"fd01::/48", "10.128.0.0/14"},
+			expectedErr: "the primary IP family of the service network [172.30.0.0/16 fd02::/112] is not the one of the cluster network [fd01::/48 10.128.0.0/14], list the CIDRs of the primary IP family first",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderConfig := TemplateData{ServiceCIDR: tt.serviceCIDR, ClusterCIDR: tt.clusterCIDR}
+			err := configureIPFamilies(&renderConfig)
+			if len(tt.expectedErr) > 0 {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if renderConfig.BindAddress != tt.expectedBindAddress || renderConfig.BindNetwork != tt.expectedBindNetwork {
+				t.Errorf("expected %s %s, got %s %s", tt.expectedBindAddress, tt.expectedBindNetwork, renderConfig.BindAddress, renderConfig.BindNetwork)
+			}
+			if !reflect.DeepEqual(renderConfig.ServiceNetworkIPs, tt.expectedServiceNetworkIPs) {
+				t.Errorf("expected service network IPs %v, got %v", tt.expectedServiceNetworkIPs, renderConfig.ServiceNetworkIPs)
+			}
+		})
+	}
+}
+
+func TestValidateEtcdServerURLs(t *testing.T) {
+	for etcdServerURLs, valid := range map[string]bool{
+		"https://127.0.0.1:2379":                            true,
+		"https://[::1]:2379":                                true,
+		"https://192.168.1.10:2379,https://[fd00::10]:2379": true,
+		"https://etcd.example.com:2379":                     true,
+		"https://fd00::10:2379":                             false,
+		"127.0.0.1:2379":                                    false,
+		"https://:2379":                                     false,
+	} {
+		if err := validateEtcdServerURLs(etcdServerURLs); (err == nil) != valid {
+			t.Errorf("%s: expected valid %v, got %v", etcdServerURLs, valid, err)
+		}
+	}
+}
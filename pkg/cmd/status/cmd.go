@@ -0,0 +1,387 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerstatus"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutpause"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+
+	// encryptedConditionType is the condition of the encryption controllers of library-go
+	encryptedConditionType = "Encrypted"
+)
+
+// certNamespaces are the namespaces whose TLS secrets are checked for expiring certificates.
+var certNamespaces = []string{
+	operatorclient.TargetNamespace,
+	operatorclient.OperatorNamespace,
+	operatorclient.GlobalMachineSpecifiedConfigNamespace,
+}
+
+// statusOpts holds how to reach the cluster and how to print the status.
+type statusOpts struct {
+	kubeconfig string
+	output     string
+	certs      int
+
+	out io.Writer
+}
+
+// NewStatusCommand creates the status command. It prints the revisions of every node, the pending rollout, the
+// certificates that expire first, the encryption state and the active Degraded conditions of the kube-apiserver, what
+// support engineers otherwise collect with a handful of oc commands.
+func NewStatusCommand() *cobra.Command {
+	opts := statusOpts{
+		output: textOutput,
+		certs:  5,
+	}
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Summarize the state of the kube-apiserver control plane",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.out = cmd.OutOrStdout()
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *statusOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "The kubeconfig of the cluster, defaults to the in-cluster config")
+	fs.StringVarP(&o.output, "output", "o", o.output, "The output format, text or json")
+	fs.IntVar(&o.certs, "certs", o.certs, "How many of the certificates that expire first are printed")
+}
+
+// Validate verifies the inputs.
+func (o *statusOpts) Validate() error {
+	if o.output != textOutput && o.output != jsonOutput {
+		return fmt.Errorf("--output must be %s or %s", textOutput, jsonOutput)
+	}
+	if o.certs < 0 {
+		return fmt.Errorf("--certs must not be negative")
+	}
+	return nil
+}
+
+// Run reads the state from the cluster and prints it.
+func (o *statusOpts) Run(ctx context.Context) error {
+	config, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	operatorClient, err := operatorclientv1.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	configClient, err := configclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	in := inputs{now: time.Now()}
+	kubeAPIServer, err := operatorClient.KubeAPIServers().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	in.status = &kubeAPIServer.Status.StaticPodOperatorStatus
+	apiServer, err := configClient.ConfigV1().APIServers().Get(ctx, "cluster", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		in.encryptionType = apiServer.Spec.Encryption.Type
+	}
+	pause, err := kubeClient.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(ctx, rolloutpause.ConfigMapName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		in.paused = pause.Data[rolloutpause.PausedKey] == "true"
+	}
+	installers, err := kubeClient.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(ctx, installerstatus.ConfigMapName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		in.installers = map[string]installerstatus.NodeInstallerStatus{}
+		for node, raw := range installers.Data {
+			var installer installerstatus.NodeInstallerStatus
+			if err := json.Unmarshal([]byte(raw), &installer); err != nil {
+				return fmt.Errorf("invalid installer status of node %s: %v", node, err)
+			}
+			in.installers[node] = installer
+		}
+	}
+	for _, namespace := range certNamespaces {
+		secrets, err := kubeClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		in.secrets = append(in.secrets, secrets.Items...)
+	}
+
+	status := summarize(in, o.certs)
+	if o.output == jsonOutput {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+	return printText(o.out, status)
+}
+
+// inputs are what the status is summarized from.
+type inputs struct {
+	status         *operatorv1.StaticPodOperatorStatus
+	encryptionType configv1.EncryptionType
+	paused         bool
+	// installers are the installer statuses of the node-installer-status configmap by node
+	installers map[string]installerstatus.NodeInstallerStatus
+	secrets    []corev1.Secret
+	now        time.Time
+}
+
+// Status is the summary of the state of the kube-apiserver control plane.
+type Status struct {
+	// latestAvailableRevision is the revision the nodes are rolled out to
+	LatestAvailableRevision int32  `json:"latestAvailableRevision"`
+	Nodes                   []Node `json:"nodes"`
+	// rollout is the pending rollout, nil when every node is at the latest available revision
+	Rollout     *Rollout    `json:"rollout,omitempty"`
+	Certs       []Cert      `json:"certs"`
+	Encryption  Encryption  `json:"encryption"`
+	Degraded    []Condition `json:"degraded"`
+	GeneratedAt metav1.Time `json:"generatedAt"`
+}
+
+// Node is the revisions of a node.
+type Node struct {
+	Name               string `json:"name"`
+	CurrentRevision    int32  `json:"currentRevision"`
+	TargetRevision     int32  `json:"targetRevision"`
+	LastFailedRevision int32  `json:"lastFailedRevision,omitempty"`
+	// lastFailedRevisionErrors are the errors of the installer of the last failed revision
+	LastFailedRevisionErrors []string `json:"lastFailedRevisionErrors,omitempty"`
+	// installer is the installer progress of the node-installer-status configmap, nil before the operator published it
+	Installer *installerstatus.NodeInstallerStatus `json:"installer,omitempty"`
+	// installingFor is how long the target revision is being installed
+	InstallingFor string `json:"installingFor,omitempty"`
+}
+
+// Rollout is the pending rollout of the latest available revision.
+type Rollout struct {
+	// paused is true when the rollout is paused in the operator config
+	Paused bool `json:"paused"`
+	// pendingNodes are the nodes that are not at the latest available revision
+	PendingNodes []string `json:"pendingNodes"`
+}
+
+// Cert is a certificate of a TLS secret.
+type Cert struct {
+	Namespace string      `json:"namespace"`
+	Secret    string      `json:"secret"`
+	Subject   string      `json:"subject"`
+	NotAfter  metav1.Time `json:"notAfter"`
+	// expiresIn is how long the certificate is valid, negative when it expired
+	ExpiresIn string `json:"expiresIn"`
+}
+
+// Encryption is the encryption at rest of the API resources.
+type Encryption struct {
+	// type is the encryption type of apiserver/cluster, identity when not encrypted
+	Type configv1.EncryptionType `json:"type"`
+	// condition is the Encrypted condition of the encryption controllers
+	Condition *Condition `json:"condition,omitempty"`
+}
+
+// Condition is a condition of the operator.
+type Condition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// summarize returns the status of the inputs with the certs certificates that expire first.
+func summarize(in inputs, certs int) *Status {
+	status := &Status{
+		LatestAvailableRevision: in.status.LatestAvailableRevision,
+		Nodes:                   []Node{},
+		Certs:                   []Cert{},
+		Encryption:              Encryption{Type: in.encryptionType},
+		Degraded:                []Condition{},
+		GeneratedAt:             metav1.NewTime(in.now),
+	}
+	if len(status.Encryption.Type) == 0 {
+		status.Encryption.Type = configv1.EncryptionTypeIdentity
+	}
+
+	rollout := &Rollout{Paused: in.paused, PendingNodes: []string{}}
+	for _, nodeStatus := range in.status.NodeStatuses {
+		node := Node{
+			Name:                     nodeStatus.NodeName,
+			CurrentRevision:          nodeStatus.CurrentRevision,
+			TargetRevision:           nodeStatus.TargetRevision,
+			LastFailedRevision:       nodeStatus.LastFailedRevision,
+			LastFailedRevisionErrors: nodeStatus.LastFailedRevisionErrors,
+		}
+		if installer, ok := in.installers[nodeStatus.NodeName]; ok {
+			node.Installer = &installer
+			if installer.InstallingSince != nil {
+				node.InstallingFor = in.now.Sub(installer.InstallingSince.Time).Round(time.Second).String()
+			}
+		}
+		status.Nodes = append(status.Nodes, node)
+		if nodeStatus.CurrentRevision != in.status.LatestAvailableRevision {
+			rollout.PendingNodes = append(rollout.PendingNodes, nodeStatus.NodeName)
+		}
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool { return status.Nodes[i].Name < status.Nodes[j].Name })
+	sort.Strings(rollout.PendingNodes)
+	if len(rollout.PendingNodes) > 0 {
+		status.Rollout = rollout
+	}
+
+	for _, secret := range in.secrets {
+		if len(secret.Data[corev1.TLSCertKey]) == 0 {
+			continue
+		}
+		parsed, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+		if err != nil || len(parsed) == 0 {
+			continue
+		}
+		status.Certs = append(status.Certs, Cert{
+			Namespace: secret.Namespace,
+			Secret:    secret.Name,
+			Subject:   parsed[0].Subject.CommonName,
+			NotAfter:  metav1.NewTime(parsed[0].NotAfter),
+			ExpiresIn: parsed[0].NotAfter.Sub(in.now).Round(time.Minute).String(),
+		})
+	}
+	sort.SliceStable(status.Certs, func(i, j int) bool { return status.Certs[i].NotAfter.Before(&status.Certs[j].NotAfter) })
+	if len(status.Certs) > certs {
+		status.Certs = status.Certs[:certs]
+	}
+
+	for _, condition := range in.status.Conditions {
+		c := Condition{
+			Type:               condition.Type,
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		}
+		switch {
+		case condition.Type == encryptedConditionType:
+			status.Encryption.Condition = &c
+		case strings.HasSuffix(condition.Type, "Degraded") && condition.Status == operatorv1.ConditionTrue:
+			status.Degraded = append(status.Degraded, c)
+		}
+	}
+	sort.Slice(status.Degraded, func(i, j int) bool { return status.Degraded[i].Type < status.Degraded[j].Type })
+	return status
+}
+
+// printText prints the status for humans.
+func printText(out io.Writer, status *Status) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Latest available revision: %d\n\n", status.LatestAvailableRevision)
+	fmt.Fprintf(w, "NODE\tCURRENT\tTARGET\tINSTALLER\tINSTALLING FOR\tREADY SINCE\tLAST FAILED\n")
+	for _, node := range status.Nodes {
+		installer, installingFor, readySince := "-", "-", "-"
+		if node.Installer != nil {
+			if node.Installer.Installer != nil {
+				installer = fmt.Sprintf("%d: %s", node.Installer.Installer.Revision, node.Installer.Installer.Outcome)
+			}
+			if node.Installer.RevisionReady != nil {
+				readySince = node.Installer.RevisionReady.UTC().Format(time.RFC3339)
+			}
+		}
+		if len(node.InstallingFor) > 0 {
+			installingFor = node.InstallingFor
+		}
+		lastFailed := "-"
+		switch {
+		case node.Installer != nil && node.Installer.LastFailure != nil:
+			failure := node.Installer.LastFailure
+			lastFailed = fmt.Sprintf("%d: %s %s", failure.Revision, failure.Reason, failure.Message)
+		case node.LastFailedRevision > 0:
+			lastFailed = fmt.Sprintf("%d: %s", node.LastFailedRevision, strings.Join(node.LastFailedRevisionErrors, "; "))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", node.Name, node.CurrentRevision, node.TargetRevision, installer, installingFor, readySince, strings.ReplaceAll(lastFailed, "\n", "; "))
+	}
+
+	switch {
+	case status.Rollout == nil:
+		fmt.Fprintf(w, "\nRollout: none, every node is at revision %d\n", status.LatestAvailableRevision)
+	case status.Rollout.Paused:
+		fmt.Fprintf(w, "\nRollout: paused, revision %d pending on %s\n", status.LatestAvailableRevision, strings.Join(status.Rollout.PendingNodes, ", "))
+	default:
+		fmt.Fprintf(w, "\nRollout: revision %d pending on %s\n", status.LatestAvailableRevision, strings.Join(status.Rollout.PendingNodes, ", "))
+	}
+
+	fmt.Fprintf(w, "\nCERTIFICATE\tSUBJECT\tNOT AFTER\tEXPIRES IN\n")
+	for _, cert := range status.Certs {
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\n", cert.Namespace, cert.Secret, cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339), cert.ExpiresIn)
+	}
+
+	fmt.Fprintf(w, "\nEncryption: %s", status.Encryption.Type)
+	if condition := status.Encryption.Condition; condition != nil {
+		fmt.Fprintf(w, ", Encrypted=%s %s: %s", condition.Status, condition.Reason, condition.Message)
+	}
+	fmt.Fprintf(w, "\n")
+
+	if len(status.Degraded) == 0 {
+		fmt.Fprintf(w, "\nDegraded: none\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "\nDEGRADED\tREASON\tSINCE\tMESSAGE\n")
+	for _, condition := range status.Degraded {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", condition.Type, condition.Reason, condition.LastTransitionTime.UTC().Format(time.RFC3339), strings.ReplaceAll(condition.Message, "\n", "; "))
+	}
+	return w.Flush()
+}
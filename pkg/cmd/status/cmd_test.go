@@ -0,0 +1,139 @@
+package status
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerhistory"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerstatus"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	in := inputs{
+		status: &operatorv1.StaticPodOperatorStatus{
+			OperatorStatus: operatorv1.OperatorStatus{
+				Conditions: []operatorv1.OperatorCondition{
+					{Type: "NodeInstallerDegraded", Status: operatorv1.ConditionTrue, Reason: "InstallerPodFailed", Message: "installer failed"},
+					{Type: "StaticPodsDegraded", Status: operatorv1.ConditionFalse},
+					{Type: "Encrypted", Status: operatorv1.ConditionTrue, Reason: "EncryptionCompleted"},
+					{Type: "Progressing", Status: operatorv1.ConditionTrue},
+				},
+			},
+			LatestAvailableRevision: 7,
+			NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-1", CurrentRevision: 6, TargetRevision: 7, LastFailedRevision: 7, LastFailedRevisionErrors: []string{"timeout"}},
+				{NodeName: "master-0", CurrentRevision: 7, TargetRevision: 0},
+			},
+		},
+		encryptionType: configv1.EncryptionTypeAESCBC,
+		paused:         true,
+		installers: map[string]installerstatus.NodeInstallerStatus{
+			"master-1": {
+				CurrentRevision: 6, TargetRevision: 7,
+				Installer:       &installerhistory.Attempt{Revision: 7, Pod: "installer-7-retry-1-master-1", Outcome: installerhistory.OutcomeRunning},
+				InstallingSince: &metav1.Time{Time: now.Add(-5 * time.Minute)},
+				RevisionReady:   &metav1.Time{Time: now.Add(-24 * time.Hour)},
+				LastFailure:     &installerstatus.Failure{Revision: 7, Reason: "timeout", Message: "context deadline exceeded"},
+			},
+		},
+		secrets: []corev1.Secret{
+			*newCertSecret(t, "later", now.Add(30*24*time.Hour)),
+			*newCertSecret(t, "expired", now.Add(-time.Hour)),
+			{ObjectMeta: metav1.ObjectMeta{Name: "no-cert"}},
+			*newCertSecret(t, "soon", now.Add(time.Hour)),
+		},
+		now: now,
+	}
+
+	status := summarize(in, 2)
+	if !reflect.DeepEqual(status.Rollout, &Rollout{Paused: true, PendingNodes: []string{"master-1"}}) {
+		t.Errorf("unexpected rollout: %+v", status.Rollout)
+	}
+	if status.Nodes[0].Name != "master-0" || status.Nodes[0].Installer != nil || status.Nodes[1].LastFailedRevision != 7 || status.Nodes[1].InstallingFor != "5m0s" {
+		t.Errorf("unexpected nodes: %+v", status.Nodes)
+	}
+	var certs []string
+	for _, cert := range status.Certs {
+		certs = append(certs, cert.Secret+" "+cert.ExpiresIn)
+	}
+	if !reflect.DeepEqual(certs, []string{"expired -1h0m0s", "soon 1h0m0s"}) {
+		t.Errorf("unexpected certs: %v", certs)
+	}
+	if status.Encryption.Type != configv1.EncryptionTypeAESCBC || status.Encryption.Condition == nil || status.Encryption.Condition.Reason != "EncryptionCompleted" {
+		t.Errorf("unexpected encryption: %+v", status.Encryption)
+	}
+	if len(status.Degraded) != 1 || status.Degraded[0].Type != "NodeInstallerDegraded" {
+		t.Errorf("unexpected degraded conditions: %+v", status.Degraded)
+	}
+
+	out := &bytes.Buffer{}
+	if err := printText(out, status); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"Latest available revision: 7",
+		"master-1  6        7       7: Running  5m0s            2021-05-31T12:00:00Z  7: timeout context deadline exceeded",
+		"Rollout: paused, revision 7 pending on master-1",
+		"openshift-kube-apiserver/expired",
+		"Encryption: aescbc, Encrypted=True EncryptionCompleted",
+		"NodeInstallerDegraded  InstallerPodFailed",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out.String())
+		}
+	}
+
+	in.status.NodeStatuses[0].CurrentRevision = 7
+	in.status.Conditions = nil
+	in.encryptionType = ""
+	status = summarize(in, 0)
+	if status.Rollout != nil || len(status.Certs) != 0 || status.Encryption.Type != configv1.EncryptionTypeIdentity {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	out.Reset()
+	if err := printText(out, status); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Rollout: none, every node is at revision 7", "Degraded: none"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func newCertSecret(t *testing.T, name string, notAfter time.Time) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-30 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name},
+		Data:       map[string][]byte{corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})},
+	}
+}
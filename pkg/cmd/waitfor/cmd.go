@@ -0,0 +1,88 @@
+package waitfor
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// Check returns true once the installer may proceed. An error is logged and the check is retried.
+type Check func(ctx context.Context, client kubernetes.Interface) (bool, error)
+
+// waitOpts holds the check and how often it is retried.
+type waitOpts struct {
+	short    string
+	interval time.Duration
+	validate func() error
+	check    Check
+}
+
+// NewCommand creates a wait command. It runs as init container of the installer pods and returns once the check
+// passes. addFlags adds the flags of the check, validate verifies them before the first check.
+func NewCommand(use, short string, addFlags func(fs *pflag.FlagSet), validate func() error, check Check) *cobra.Command {
+	opts := waitOpts{
+		short:    short,
+		interval: 10 * time.Second,
+		validate: validate,
+		check:    check,
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(context.Background()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	addFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&opts.interval, "interval", opts.interval, "How often the check is retried")
+
+	return cmd
+}
+
+// Run polls the check with an in-cluster client until it passes.
+func (o *waitOpts) Run(ctx context.Context) error {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("%s, checking every %s", o.short, o.interval)
+	return wait.PollImmediateInfiniteWithContext(ctx, o.interval, func(ctx context.Context) (bool, error) {
+		done, err := o.check(ctx, client)
+		if err != nil {
+			klog.Warning(err)
+			return false, nil
+		}
+		return done, nil
+	})
+}
+
+// ConfigMapData returns the data of the configmap, nil if it does not exist yet.
+func ConfigMapData(ctx context.Context, client kubernetes.Interface, namespace, name string) (map[string]string, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return configMap.Data, nil
+}
@@ -0,0 +1,65 @@
+package waitforcanary
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+)
+
+// waitOpts holds the revision to wait for and where the verified revision is recorded.
+type waitOpts struct {
+	revision      int
+	namespace     string
+	configMapName string
+}
+
+// NewWaitForCanaryCommand creates the wait-for-canary command. It runs as init container of the installer pods of
+// the nodes after the canary and returns once the canary rollout controller verified the revision.
+func NewWaitForCanaryCommand() *cobra.Command {
+	opts := &waitOpts{
+		namespace:     "openshift-kube-apiserver",
+		configMapName: "canary-rollout",
+	}
+	return waitfor.NewCommand("wait-for-canary", "Wait until a revision is verified on the canary node", opts.AddFlags, opts.Validate, opts.verified)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.revision, "revision", o.revision, "The revision to wait for")
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the configmap")
+	fs.StringVar(&o.configMapName, "configmap", o.configMapName, "The configmap that records the verified revision")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if o.revision <= 0 {
+		return fmt.Errorf("--revision must be positive")
+	}
+	if len(o.namespace) == 0 || len(o.configMapName) == 0 {
+		return fmt.Errorf("--namespace and --configmap are required")
+	}
+	return nil
+}
+
+// verified returns true once the verified revision of the configmap reaches the revision.
+func (o *waitOpts) verified(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	data, err := waitfor.ConfigMapData(ctx, client, o.namespace, o.configMapName)
+	if err != nil {
+		return false, err
+	}
+	verifiedRevision, err := strconv.Atoi(data["verifiedRevision"])
+	if err != nil {
+		return false, nil
+	}
+	if verifiedRevision >= o.revision {
+		klog.Infof("Revision %d is verified", verifiedRevision)
+		return true, nil
+	}
+	return false, nil
+}
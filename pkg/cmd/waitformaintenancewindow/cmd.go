@@ -0,0 +1,56 @@
+package waitformaintenancewindow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+)
+
+// waitOpts holds where the state of the maintenance windows is recorded.
+type waitOpts struct {
+	namespace     string
+	configMapName string
+}
+
+// NewWaitForMaintenanceWindowCommand creates the wait-for-maintenance-window command. It runs as init container of the
+// installer pods and returns once the maintenance window controller recorded that a maintenance window is open.
+func NewWaitForMaintenanceWindowCommand() *cobra.Command {
+	opts := &waitOpts{
+		namespace:     "openshift-kube-apiserver",
+		configMapName: "rollout-maintenance-window",
+	}
+	return waitfor.NewCommand("wait-for-maintenance-window", "Wait until a maintenance window for new revisions is open", opts.AddFlags, opts.Validate, opts.open)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the configmap")
+	fs.StringVar(&o.configMapName, "configmap", o.configMapName, "The configmap that records whether a maintenance window is open")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if len(o.namespace) == 0 || len(o.configMapName) == 0 {
+		return fmt.Errorf("--namespace and --configmap are required")
+	}
+	return nil
+}
+
+// open returns true once the configmap records that a maintenance window is open. A missing configmap is not enough,
+// the installer pod might have been created before the controller published the first state.
+func (o *waitOpts) open(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	data, err := waitfor.ConfigMapData(ctx, client, o.namespace, o.configMapName)
+	if err != nil {
+		return false, err
+	}
+	if data["open"] != "true" {
+		return false, nil
+	}
+	klog.Infof("A maintenance window is open")
+	return true, nil
+}
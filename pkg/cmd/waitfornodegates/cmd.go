@@ -0,0 +1,60 @@
+package waitfornodegates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodegates"
+)
+
+// waitOpts holds the node and the gates it has to satisfy.
+type waitOpts struct {
+	nodeName    string
+	conditions  []string
+	annotations []string
+}
+
+// NewWaitForNodeGatesCommand creates the wait-for-node-gates command. It runs as init container of the installer pods
+// and returns once the node has the conditions and annotations the rollout config requires before an installation.
+func NewWaitForNodeGatesCommand() *cobra.Command {
+	opts := &waitOpts{}
+	return waitfor.NewCommand("wait-for-node-gates", "Wait until a node satisfies the node gates of the rollout", opts.AddFlags, opts.Validate, opts.satisfied)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.nodeName, "node", o.nodeName, "The node that has to satisfy the gates")
+	fs.StringArrayVar(&o.conditions, "condition", o.conditions, "A node condition type that has to be True, can be repeated")
+	fs.StringArrayVar(&o.annotations, "annotation", o.annotations, "A node annotation that has to be set, optionally as key=value, can be repeated")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if len(o.nodeName) == 0 {
+		return fmt.Errorf("--node is required")
+	}
+	if len(o.conditions) == 0 && len(o.annotations) == 0 {
+		return fmt.Errorf("at least one --condition or --annotation is required")
+	}
+	return nil
+}
+
+// satisfied returns true once the node satisfies all gates.
+func (o *waitOpts) satisfied(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	node, err := client.CoreV1().Nodes().Get(ctx, o.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if unmet := nodegates.Unmet(node, nodegates.FromFlags(o.conditions, o.annotations)); len(unmet) > 0 {
+		klog.V(2).Infof("Node %s waits for %s", o.nodeName, nodegates.Describe(unmet))
+		return false, nil
+	}
+	klog.Infof("Node %s satisfies the node gates", o.nodeName)
+	return true, nil
+}
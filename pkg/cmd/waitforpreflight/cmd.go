@@ -0,0 +1,63 @@
+package waitforpreflight
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+)
+
+// waitOpts holds where the result of the preflight checks is recorded and the revision to install.
+type waitOpts struct {
+	namespace     string
+	configMapName string
+	revision      int
+}
+
+// NewWaitForPreflightCommand creates the wait-for-preflight command. It runs as init container of the installer pods
+// and returns once the rollout preflight controller recorded that the checks passed for the revision.
+func NewWaitForPreflightCommand() *cobra.Command {
+	opts := &waitOpts{
+		namespace:     "openshift-kube-apiserver",
+		configMapName: "rollout-preflight",
+	}
+	return waitfor.NewCommand("wait-for-preflight", "Wait until the preflight checks of a revision passed", opts.AddFlags, opts.Validate, opts.passed)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the configmap")
+	fs.StringVar(&o.configMapName, "configmap", o.configMapName, "The configmap that records the result of the preflight checks")
+	fs.IntVar(&o.revision, "revision", o.revision, "The revision to install")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if len(o.namespace) == 0 || len(o.configMapName) == 0 {
+		return fmt.Errorf("--namespace and --configmap are required")
+	}
+	if o.revision <= 0 {
+		return fmt.Errorf("--revision is required")
+	}
+	return nil
+}
+
+// passed returns true once the configmap records that the checks passed for the revision or a later one. The checks
+// of an earlier revision do not count, its manifest is a different one.
+func (o *waitOpts) passed(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	data, err := waitfor.ConfigMapData(ctx, client, o.namespace, o.configMapName)
+	if err != nil {
+		return false, err
+	}
+	revision, err := strconv.Atoi(data["revision"])
+	if err != nil || revision < o.revision || data["passed"] != "true" {
+		return false, nil
+	}
+	klog.Infof("The preflight checks of revision %d passed", revision)
+	return true, nil
+}
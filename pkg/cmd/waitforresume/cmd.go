@@ -0,0 +1,56 @@
+package waitforresume
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+)
+
+// waitOpts holds where the paused state of the rollout is recorded.
+type waitOpts struct {
+	namespace     string
+	configMapName string
+}
+
+// NewWaitForResumeCommand creates the wait-for-resume command. It runs as init container of the installer pods
+// created while the rollout is paused and returns once the rollout pause controller recorded that it is resumed.
+func NewWaitForResumeCommand() *cobra.Command {
+	opts := &waitOpts{
+		namespace:     "openshift-kube-apiserver",
+		configMapName: "rollout-pause",
+	}
+	return waitfor.NewCommand("wait-for-resume", "Wait until the rollout of new revisions is resumed", opts.AddFlags, opts.Validate, opts.resumed)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the configmap")
+	fs.StringVar(&o.configMapName, "configmap", o.configMapName, "The configmap that records whether the rollout is paused")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if len(o.namespace) == 0 || len(o.configMapName) == 0 {
+		return fmt.Errorf("--namespace and --configmap are required")
+	}
+	return nil
+}
+
+// resumed returns true once the configmap records that the rollout is not paused. A missing configmap is not enough,
+// the installer pod might have been created before the controller published the pause.
+func (o *waitOpts) resumed(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	data, err := waitfor.ConfigMapData(ctx, client, o.namespace, o.configMapName)
+	if err != nil {
+		return false, err
+	}
+	if data["paused"] != "false" {
+		return false, nil
+	}
+	klog.Infof("The rollout is resumed")
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package waitwhileexcluded
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/waitfor"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodeexclusion"
+)
+
+// waitOpts holds where the node exclusions are recorded and the node to wait for.
+type waitOpts struct {
+	namespace     string
+	configMapName string
+	nodeName      string
+}
+
+// NewWaitWhileExcludedCommand creates the wait-while-excluded command. It runs as init container of the installer pods
+// of excluded nodes and returns once the exclusion of the node is removed or expired.
+func NewWaitWhileExcludedCommand() *cobra.Command {
+	opts := &waitOpts{
+		namespace:     "openshift-kube-apiserver",
+		configMapName: "rollout-node-exclusions",
+	}
+	return waitfor.NewCommand("wait-while-excluded", "Wait until a node is no longer excluded from rollouts", opts.AddFlags, opts.Validate, opts.included)
+}
+
+func (o *waitOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of the configmap")
+	fs.StringVar(&o.configMapName, "configmap", o.configMapName, "The configmap that records the node exclusions")
+	fs.StringVar(&o.nodeName, "node", o.nodeName, "The node to wait for")
+}
+
+// Validate verifies the inputs.
+func (o *waitOpts) Validate() error {
+	if len(o.namespace) == 0 || len(o.configMapName) == 0 {
+		return fmt.Errorf("--namespace and --configmap are required")
+	}
+	if len(o.nodeName) == 0 {
+		return fmt.Errorf("--node is required")
+	}
+	return nil
+}
+
+// included returns true once the configmap has no active exclusion of the node. A missing configmap is not enough, the
+// installer pod might have been created before the controller recorded the exclusion.
+func (o *waitOpts) included(ctx context.Context, client kubernetes.Interface) (bool, error) {
+	data, err := waitfor.ConfigMapData(ctx, client, o.namespace, o.configMapName)
+	if err != nil || data == nil {
+		return false, err
+	}
+	records, err := nodeexclusion.ParseRecords(data[nodeexclusion.ExclusionsKey])
+	if err != nil {
+		return false, fmt.Errorf("failed to decode configmap %s/%s: %v", o.namespace, o.configMapName, err)
+	}
+	if record, ok := records[o.nodeName]; ok && record.Active(time.Now()) {
+		return false, nil
+	}
+	klog.Infof("Node %s is no longer excluded from rollouts", o.nodeName)
+	return true, nil
+}
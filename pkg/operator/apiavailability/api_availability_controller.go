@@ -0,0 +1,276 @@
+package apiavailability
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	operatorcontrolplaneinformers "github.com/openshift/client-go/operatorcontrolplane/informers/externalversions"
+	operatorcontrolplanev1alpha1listers "github.com/openshift/client-go/operatorcontrolplane/listers/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/terminationobserver"
+)
+
+const (
+	// ConfigMapName is the configmap in the operator namespace with the availability of the API over every window and
+	// the hourly samples it is computed from.
+	ConfigMapName = "api-availability"
+
+	APIAvailabilityErrorBudgetExhaustedConditionType = "APIAvailabilityErrorBudgetExhausted"
+
+	// objective is the availability objective of the API, the same as of the KubeAPIErrorBudgetBurn alerts
+	objective = 0.99
+	// retention is how long the samples are kept, the longest window
+	retention = 7 * 24 * time.Hour
+
+	// sampleInterval is how often the API is probed
+	sampleInterval = 30 * time.Second
+	// reportInterval is how often the configmap is written while the API is available. Unavailable samples are written
+	// right away.
+	reportInterval = 5 * time.Minute
+	// readyzTimeout is how long a /readyz request may take before the API counts as unavailable
+	readyzTimeout = 10 * time.Second
+
+	bucketsKey = "buckets"
+
+	// loadBalancerCheckTarget is in the names of the connectivity checks of the API load balancers
+	loadBalancerCheckTarget = "-to-load-balancer-api-"
+)
+
+// windows are the windows the availability is reported for.
+var windows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{name: "1d", duration: 24 * time.Hour},
+	{name: "7d", duration: retention},
+}
+
+var (
+	registerMetrics sync.Once
+
+	availabilityGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_api_availability_ratio",
+		Help: "Report the ratio of the samples of the API that were available in the window, 1d or 7d.",
+	}, []string{"window"})
+
+	errorBudgetRemainingGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_api_error_budget_remaining_ratio",
+		Help: "Report the ratio of the error budget of the 99% availability objective of the API that is left in the window, 1d or 7d, negative when it is exceeded.",
+	}, []string{"window"})
+
+	samplesCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_operator_api_availability_samples_total",
+		Help: "Report the number of samples of the API by result, available or unavailable.",
+	}, []string{"result"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(availabilityGauge)
+		legacyregistry.MustRegister(errorBudgetRemainingGauge)
+		legacyregistry.MustRegister(samplesCounter)
+	})
+}
+
+// APIAvailabilityController samples the availability of the API every 30 seconds and reports the availability and the
+// remaining error budget of the last day and week in the api-availability configmap of the operator namespace, in
+// metrics and in the APIAvailabilityErrorBudgetExhausted condition. A sample is unavailable when the /readyz request of
+// the operator fails, when a connectivity check of an API load balancer is in an outage, or when a kube-apiserver
+// reported a non-graceful termination or late connections since the previous sample. The API is not sampled while the
+// operator does not run.
+type APIAvailabilityController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient corev1client.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+	eventLister     corev1listers.EventLister
+	checkLister     operatorcontrolplanev1alpha1listers.PodNetworkConnectivityCheckLister
+	readyz          func(ctx context.Context) error
+
+	now func() time.Time
+
+	// buckets are loaded from the configmap on the first sync
+	buckets      []Bucket
+	loaded       bool
+	lastSample   time.Time
+	lastReport   time.Time
+	reportNeeded bool
+}
+
+func NewAPIAvailabilityController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	operatorcontrolplaneInformers operatorcontrolplaneinformers.SharedInformerFactory,
+	configMapClient corev1client.ConfigMapsGetter,
+	readyzClient rest.Interface,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	configMapInformer := kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps()
+	eventInformer := kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Events()
+	checkInformer := operatorcontrolplaneInformers.Controlplane().V1alpha1().PodNetworkConnectivityChecks()
+	c := &APIAvailabilityController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		configMapLister: configMapInformer.Lister(),
+		eventLister:     eventInformer.Lister(),
+		checkLister:     checkInformer.Lister(),
+		readyz: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, readyzTimeout)
+			defer cancel()
+			return readyzClient.Get().AbsPath("/readyz").Do(ctx).Error()
+		},
+		now: time.Now,
+	}
+	// the informers do not trigger syncs, every sync is a sample
+	return factory.New().WithBareInformers(
+		operatorClient.Informer(),
+		configMapInformer.Informer(),
+		eventInformer.Informer(),
+		checkInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("APIAvailabilityController", c.sync)).ResyncEvery(resyncinterval.For("APIAvailabilityController", sampleInterval)).ToController("APIAvailabilityController", eventRecorder.WithComponentSuffix("api-availability-controller"))
+}
+
+func (c *APIAvailabilityController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	if !c.loaded {
+		configMap, err := c.configMapLister.ConfigMaps(operatorclient.OperatorNamespace).Get(ConfigMapName)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return err
+		default:
+			c.buckets = bucketsOf(configMap.Data)
+		}
+		c.loaded = true
+	}
+
+	now := c.now()
+	s, err := c.sample(ctx, now)
+	if err != nil {
+		return err
+	}
+	c.buckets = addSample(c.buckets, s, now)
+	c.lastSample = now
+	if s.unavailable() {
+		samplesCounter.WithLabelValues("unavailable").Inc()
+		c.reportNeeded = true
+	} else {
+		samplesCounter.WithLabelValues("available").Inc()
+	}
+
+	for _, w := range windows {
+		availability := availabilityOf(c.buckets, w.duration, now)
+		availabilityGauge.WithLabelValues(w.name).Set(availability.Availability)
+		errorBudgetRemainingGauge.WithLabelValues(w.name).Set(availability.ErrorBudgetRemaining)
+	}
+
+	// the condition is updated with the configmap, not on every sample
+	if !c.reportNeeded && now.Sub(c.lastReport) < reportInterval {
+		return nil
+	}
+	condition := newErrorBudgetCondition(availabilityOf(c.buckets, retention, now))
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+		return err
+	}
+	data, err := newReport(c.buckets, now)
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: ConfigMapName},
+		Data:       data,
+	}); err != nil {
+		return err
+	}
+	c.lastReport = now
+	c.reportNeeded = false
+	return nil
+}
+
+// sample probes the API. The terminations are the ones reported since the previous sample, the first sample of the
+// operator process does not look back.
+func (c *APIAvailabilityController) sample(ctx context.Context, now time.Time) (sample, error) {
+	s := sample{readyzFailed: c.readyz(ctx) != nil}
+
+	checks, err := c.checkLister.PodNetworkConnectivityChecks(operatorclient.TargetNamespace).List(labels.Everything())
+	if err != nil {
+		return s, err
+	}
+	s.loadBalancerOutage = hasLoadBalancerOutage(checks, now)
+
+	if !c.lastSample.IsZero() {
+		events, err := c.eventLister.Events(operatorclient.TargetNamespace).List(labels.Everything())
+		if err != nil {
+			return s, err
+		}
+		s.disruptiveTermination = hasDisruptiveTermination(events, c.lastSample)
+	}
+	return s, nil
+}
+
+// hasLoadBalancerOutage returns true when a connectivity check of an API load balancer is in an outage. The check of a
+// source pod that is gone is not updated anymore, its outage only counts while the latest failure is recent.
+func hasLoadBalancerOutage(checks []*v1alpha1.PodNetworkConnectivityCheck, now time.Time) bool {
+	for _, check := range checks {
+		if !strings.Contains(check.Name, loadBalancerCheckTarget) {
+			continue
+		}
+		if len(check.Status.Outages) == 0 || len(check.Status.Failures) == 0 || !check.Status.Outages[0].End.IsZero() {
+			continue
+		}
+		if now.Sub(check.Status.Failures[0].Start.Time) <= 2*sampleInterval {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDisruptiveTermination returns true when a kube-apiserver reported a non-graceful termination or late connections
+// after since.
+func hasDisruptiveTermination(events []*corev1.Event, since time.Time) bool {
+	for _, event := range events {
+		if event.Reason != terminationobserver.NonGracefulTerminationReason && event.Reason != terminationobserver.LateConnectionsReason {
+			continue
+		}
+		if event.LastTimestamp.Time.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+func newErrorBudgetCondition(availability Availability) operatorv1.OperatorCondition {
+	condition := operatorv1.OperatorCondition{
+		Type:   APIAvailabilityErrorBudgetExhaustedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+		Message: fmt.Sprintf("The API was %.2f%% available over the last 7 days, %.0f%% of the error budget of the %.0f%% objective is left (%d of %d samples unavailable: %d readyz failures, %d load balancer outages, %d disruptive terminations).",
+			availability.Availability*100, availability.ErrorBudgetRemaining*100, objective*100,
+			availability.Unavailable, availability.Samples,
+			availability.ReadyzFailures, availability.LoadBalancerOutages, availability.DisruptiveTerminations),
+	}
+	if availability.ErrorBudgetRemaining <= 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ObjectiveMissed"
+	}
+	return condition
+}
@@ -0,0 +1,112 @@
+package apiavailability
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAvailability(t *testing.T) {
+	now := time.Date(2021, 6, 8, 12, 30, 0, 0, time.UTC)
+	buckets := []Bucket{
+		// dropped by the retention
+		{Start: metav1.NewTime(now.Add(-retention).Truncate(time.Hour)), Samples: 120, Unavailable: 120},
+		// only in the 7d window
+		{Start: metav1.NewTime(now.Add(-2 * 24 * time.Hour).Truncate(time.Hour)), Samples: 1200, Unavailable: 3, ReadyzFailures: 3},
+		{Start: metav1.NewTime(now.Add(-time.Hour).Truncate(time.Hour)), Samples: 118},
+	}
+	buckets = addSample(buckets, sample{readyzFailed: true, loadBalancerOutage: true}, now.Add(-time.Hour))
+	buckets = addSample(buckets, sample{}, now)
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(buckets))
+	}
+
+	daily := availabilityOf(buckets, 24*time.Hour, now)
+	expectedDaily := Availability{
+		Since:               metav1.NewTime(now.Add(-time.Hour).Truncate(time.Hour)),
+		Samples:             120,
+		Unavailable:         1,
+		Availability:        1 - 1.0/120,
+		Objective:           objective,
+		ReadyzFailures:      1,
+		LoadBalancerOutages: 1,
+	}
+	expectedDaily.ErrorBudgetRemaining = daily.ErrorBudgetRemaining
+	if diff := cmp.Diff(expectedDaily, daily); diff != "" {
+		t.Errorf("unexpected daily availability: %s", diff)
+	}
+	if math.Abs(daily.ErrorBudgetRemaining-(1-(1.0/120)/0.01)) > 1e-9 {
+		t.Errorf("unexpected daily error budget remaining: %v", daily.ErrorBudgetRemaining)
+	}
+
+	weekly := availabilityOf(buckets, retention, now)
+	if weekly.Samples != 1320 || weekly.Unavailable != 4 || weekly.ReadyzFailures != 4 {
+		t.Errorf("unexpected weekly availability: %+v", weekly)
+	}
+	if condition := newErrorBudgetCondition(weekly); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected the error budget not to be exhausted: %s", condition.Message)
+	}
+	if condition := newErrorBudgetCondition(daily); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected the error budget not to be exhausted: %s", condition.Message)
+	}
+	exhausted := addSample(nil, sample{disruptiveTermination: true}, now)
+	if condition := newErrorBudgetCondition(availabilityOf(exhausted, retention, now)); condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected the error budget to be exhausted: %s", condition.Message)
+	}
+
+	data, err := newReport(buckets, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(buckets, bucketsOf(data)); diff != "" {
+		t.Errorf("unexpected buckets of the report: %s", diff)
+	}
+	if bucketsOf(map[string]string{bucketsKey: "invalid"}) != nil {
+		t.Errorf("expected invalid buckets to be dropped")
+	}
+}
+
+func TestSampleSources(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	check := func(name string, outageEnd, lastFailure time.Time) *v1alpha1.PodNetworkConnectivityCheck {
+		return &v1alpha1.PodNetworkConnectivityCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1alpha1.PodNetworkConnectivityCheckStatus{
+				Outages:  []v1alpha1.OutageEntry{{Start: metav1.NewTime(now.Add(-time.Hour)), End: metav1.NewTime(outageEnd)}},
+				Failures: []v1alpha1.LogEntry{{Start: metav1.NewTime(lastFailure)}},
+			},
+		}
+	}
+	for name, checks := range map[string][]*v1alpha1.PodNetworkConnectivityCheck{
+		"over":             {check("kube-apiserver-master-0-to-load-balancer-api-external", now.Add(-time.Minute), now.Add(-time.Minute))},
+		"source gone":      {check("kube-apiserver-master-0-to-load-balancer-api-internal", time.Time{}, now.Add(-time.Hour))},
+		"not an API check": {check("kube-apiserver-master-0-to-etcd-server-master-1", time.Time{}, now)},
+	} {
+		if hasLoadBalancerOutage(checks, now) {
+			t.Errorf("%s: expected no load balancer outage", name)
+		}
+	}
+	if !hasLoadBalancerOutage([]*v1alpha1.PodNetworkConnectivityCheck{check("kube-apiserver-master-0-to-load-balancer-api-internal", time.Time{}, now)}, now) {
+		t.Errorf("expected a load balancer outage")
+	}
+
+	event := func(reason string, at time.Time) *corev1.Event {
+		return &corev1.Event{Reason: reason, LastTimestamp: metav1.NewTime(at)}
+	}
+	events := []*corev1.Event{
+		event("NonGracefulTermination", now.Add(-time.Hour)),
+		event("TerminationStart", now),
+	}
+	if hasDisruptiveTermination(events, now.Add(-time.Minute)) {
+		t.Errorf("expected no disruptive termination")
+	}
+	if !hasDisruptiveTermination(append(events, event("LateConnections", now)), now.Add(-time.Minute)) {
+		t.Errorf("expected a disruptive termination")
+	}
+}
@@ -0,0 +1,135 @@
+package apiavailability
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Bucket counts the samples of an hour.
+type Bucket struct {
+	// start is the start of the hour
+	Start metav1.Time `json:"start"`
+	// samples are the samples taken in the hour
+	Samples int `json:"samples"`
+	// unavailable are the samples with at least one of the failures below
+	Unavailable int `json:"unavailable"`
+	// readyzFailures are the samples whose /readyz request through the kubernetes service failed
+	ReadyzFailures int `json:"readyzFailures"`
+	// loadBalancerOutages are the samples with an ongoing outage of a connectivity check of an API load balancer
+	LoadBalancerOutages int `json:"loadBalancerOutages"`
+	// disruptiveTerminations are the samples after which a kube-apiserver reported a non-graceful termination or late
+	// connections
+	DisruptiveTerminations int `json:"disruptiveTerminations"`
+}
+
+// sample is the result of one probe of the API.
+type sample struct {
+	readyzFailed          bool
+	loadBalancerOutage    bool
+	disruptiveTermination bool
+}
+
+func (s sample) unavailable() bool {
+	return s.readyzFailed || s.loadBalancerOutage || s.disruptiveTermination
+}
+
+// Availability is the availability of the API over a window.
+type Availability struct {
+	// since is the start of the oldest sampled hour of the window
+	Since metav1.Time `json:"since"`
+	// samples and unavailable are the samples of the window and the unavailable ones
+	Samples     int `json:"samples"`
+	Unavailable int `json:"unavailable"`
+	// availability is the ratio of available samples, 1 without samples
+	Availability float64 `json:"availability"`
+	// objective is the availability objective
+	Objective float64 `json:"objective"`
+	// errorBudgetRemaining is the ratio of the error budget of the objective that is left, negative when the budget
+	// is exceeded
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+	// the causes of the unavailable samples, a sample can have several
+	ReadyzFailures         int `json:"readyzFailures"`
+	LoadBalancerOutages    int `json:"loadBalancerOutages"`
+	DisruptiveTerminations int `json:"disruptiveTerminations"`
+}
+
+// addSample adds a sample to the bucket of its hour and drops the buckets that are older than the retention. The
+// buckets are ordered by start.
+func addSample(buckets []Bucket, s sample, at time.Time) []Bucket {
+	start := at.UTC().Truncate(time.Hour)
+	if len(buckets) == 0 || buckets[len(buckets)-1].Start.Time.Before(start) {
+		buckets = append(buckets, Bucket{Start: metav1.NewTime(start)})
+	}
+	bucket := &buckets[len(buckets)-1]
+	bucket.Samples++
+	if s.unavailable() {
+		bucket.Unavailable++
+	}
+	if s.readyzFailed {
+		bucket.ReadyzFailures++
+	}
+	if s.loadBalancerOutage {
+		bucket.LoadBalancerOutages++
+	}
+	if s.disruptiveTermination {
+		bucket.DisruptiveTerminations++
+	}
+
+	for len(buckets) > 0 && !buckets[0].Start.Time.After(start.Add(-retention)) {
+		buckets = buckets[1:]
+	}
+	return buckets
+}
+
+// availabilityOf returns the availability of the buckets that started within the window before now.
+func availabilityOf(buckets []Bucket, window time.Duration, now time.Time) Availability {
+	ret := Availability{Objective: objective}
+	for _, bucket := range buckets {
+		if !bucket.Start.Time.After(now.Add(-window)) {
+			continue
+		}
+		if ret.Since.IsZero() {
+			ret.Since = bucket.Start
+		}
+		ret.Samples += bucket.Samples
+		ret.Unavailable += bucket.Unavailable
+		ret.ReadyzFailures += bucket.ReadyzFailures
+		ret.LoadBalancerOutages += bucket.LoadBalancerOutages
+		ret.DisruptiveTerminations += bucket.DisruptiveTerminations
+	}
+	ret.Availability = 1
+	if ret.Samples > 0 {
+		ret.Availability = 1 - float64(ret.Unavailable)/float64(ret.Samples)
+	}
+	ret.ErrorBudgetRemaining = 1 - (1-ret.Availability)/(1-objective)
+	return ret
+}
+
+// newReport returns the data of the api-availability configmap: the availability of every window and the buckets.
+func newReport(buckets []Bucket, now time.Time) (map[string]string, error) {
+	data := map[string]string{}
+	for _, w := range windows {
+		raw, err := json.Marshal(availabilityOf(buckets, w.duration, now))
+		if err != nil {
+			return nil, err
+		}
+		data[w.name] = string(raw)
+	}
+	raw, err := json.Marshal(buckets)
+	if err != nil {
+		return nil, err
+	}
+	data[bucketsKey] = string(raw)
+	return data, nil
+}
+
+// bucketsOf returns the buckets of the api-availability configmap, nil if they do not decode.
+func bucketsOf(data map[string]string) []Bucket {
+	var buckets []Bucket
+	if err := json.Unmarshal([]byte(data[bucketsKey]), &buckets); err != nil {
+		return nil
+	}
+	return buckets
+}
@@ -0,0 +1,113 @@
+package auditlogvolume
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	AuditLogVolumeDegradedConditionType = "AuditLogVolumeDegraded"
+
+	AuditLogVolumeNearlyFullReason = "AuditLogVolumeNearlyFull"
+
+	// defaultMinAvailablePercent leaves room for a few rotations of the default audit log of 100 MiB on small volumes.
+	defaultMinAvailablePercent = 10
+)
+
+// AuditLogVolumeController degrades the operator while the volume of the audit log of a node has less available space
+// than auditLog.minAvailablePercent of the operator config. The check-endpoints sidecars report the usage of their
+// node with a Monitor.
+type AuditLogVolumeController struct {
+	operatorClient        v1helpers.StaticPodOperatorClient
+	configMapLister       corev1listers.ConfigMapLister
+	configConfigMapLister corev1listers.ConfigMapLister
+}
+
+func NewAuditLogVolumeController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &AuditLogVolumeController{
+		operatorClient:        operatorClient,
+		configMapLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		configConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("AuditLogVolumeController", c.sync)).ToController("AuditLogVolumeController", eventRecorder.WithComponentSuffix("audit-log-volume-controller"))
+}
+
+func (c *AuditLogVolumeController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return err
+	}
+	if errs := operatorconfig.ValidateAuditLog(operatorConfig.AuditLog, field.NewPath("auditLog")); len(errs) > 0 {
+		return fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+
+	cond, err := c.newAuditLogVolumeCondition(int(pointer.Int32Deref(operatorConfig.AuditLog.MinAvailablePercent, defaultMinAvailablePercent)), status)
+	if err != nil {
+		return err
+	}
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *AuditLogVolumeController) newAuditLogVolumeCondition(minAvailablePercent int, status *operatorv1.StaticPodOperatorStatus) (operatorv1.OperatorCondition, error) {
+	var nearlyFull []string
+	for _, nodeStatus := range status.NodeStatuses {
+		cm, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(ConfigMapName(nodeStatus.NodeName))
+		if apierrors.IsNotFound(err) {
+			// not reported yet
+			continue
+		} else if err != nil {
+			return operatorv1.OperatorCondition{}, err
+		}
+		usage, err := fromConfigMap(cm)
+		if err != nil {
+			return operatorv1.OperatorCondition{}, err
+		}
+		if usage.AvailablePercent() < minAvailablePercent {
+			nearlyFull = append(nearlyFull, fmt.Sprintf("node %q has %d%% (%d MiB) of %d MiB available", nodeStatus.NodeName, usage.AvailablePercent(), usage.AvailableBytes>>20, usage.CapacityBytes>>20))
+		}
+	}
+	if len(nearlyFull) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   AuditLogVolumeDegradedConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}, nil
+	}
+	sort.Strings(nearlyFull)
+	return operatorv1.OperatorCondition{
+		Type:    AuditLogVolumeDegradedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  AuditLogVolumeNearlyFullReason,
+		Message: fmt.Sprintf("the audit log volume has less than %d%% available, audit events are lost once it is full:\n%s", minAvailablePercent, strings.Join(nearlyFull, "\n")),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package auditlogvolume
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewAuditLogVolumeCondition(t *testing.T) {
+	const gib = 1 << 30
+	status := &operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{
+		{NodeName: "master-0"},
+		{NodeName: "master-1"},
+		{NodeName: "master-2"},
+	}}
+
+	tests := []struct {
+		name     string
+		usage    map[string]Usage
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name:     "not reported",
+			expected: operatorv1.OperatorCondition{Type: AuditLogVolumeDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "enough space",
+			usage: map[string]Usage{
+				"master-0": {AvailableBytes: 50 * gib, CapacityBytes: 100 * gib},
+				"master-1": {AvailableBytes: 10 * gib, CapacityBytes: 100 * gib},
+			},
+			expected: operatorv1.OperatorCondition{Type: AuditLogVolumeDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "nearly full",
+			usage: map[string]Usage{
+				"master-0": {AvailableBytes: 50 * gib, CapacityBytes: 100 * gib},
+				"master-1": {AvailableBytes: 9 * gib, CapacityBytes: 100 * gib},
+				"master-2": {AvailableBytes: 0, CapacityBytes: 100 * gib},
+				// not a control plane node of the operator
+				"master-3": {AvailableBytes: 0, CapacityBytes: 100 * gib},
+			},
+			expected: operatorv1.OperatorCondition{
+				Type:   AuditLogVolumeDegradedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: AuditLogVolumeNearlyFullReason,
+				Message: "the audit log volume has less than 10% available, audit events are lost once it is full:\n" +
+					`node "master-1" has 9% (9216 MiB) of 102400 MiB available` + "\n" +
+					`node "master-2" has 0% (0 MiB) of 102400 MiB available`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for node, usage := range test.usage {
+				if err := indexer.Add(toConfigMap("openshift-kube-apiserver", node, usage)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &AuditLogVolumeController{configMapLister: corev1listers.NewConfigMapLister(indexer)}
+
+			cond, err := c.newAuditLogVolumeCondition(defaultMinAvailablePercent, status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expected, cond); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package auditlogvolume
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+)
+
+var (
+	registerMetrics sync.Once
+
+	availableBytesGauge *metrics.Gauge
+	capacityBytesGauge  *metrics.Gauge
+)
+
+func registerMonitorMetrics() {
+	registerMetrics.Do(func() {
+		availableBytesGauge = metrics.NewGauge(&metrics.GaugeOpts{
+			Name: "kube_apiserver_audit_log_volume_available_bytes",
+			Help: "Bytes of the volume of the kube-apiserver audit log that are available to the kube-apiserver.",
+		})
+		capacityBytesGauge = metrics.NewGauge(&metrics.GaugeOpts{
+			Name: "kube_apiserver_audit_log_volume_capacity_bytes",
+			Help: "Size of the volume of the kube-apiserver audit log in bytes.",
+		})
+		legacyregistry.MustRegister(availableBytesGauge, capacityBytesGauge)
+	})
+}
+
+// Monitor reports the usage of the volume of the audit log of its node in metrics and in the config map of the node,
+// so that the operator degrades before the kube-apiserver fails to write audit events.
+type Monitor struct {
+	dir       string
+	namespace string
+	nodeName  string
+	client    corev1client.ConfigMapsGetter
+
+	reported *Usage
+}
+
+func NewMonitor(dir, namespace, nodeName string, client corev1client.ConfigMapsGetter) *Monitor {
+	registerMonitorMetrics()
+	return &Monitor{dir: dir, namespace: namespace, nodeName: nodeName, client: client}
+}
+
+// Run checks the volume every interval until the context is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := m.sync(ctx); err != nil {
+			klog.Warningf("Failed to report the usage of the audit log volume %s: %v", m.dir, err)
+		}
+	}, interval)
+}
+
+func (m *Monitor) sync(ctx context.Context) error {
+	usage, err := statUsage(m.dir)
+	if err != nil {
+		return err
+	}
+	availableBytesGauge.Set(float64(usage.AvailableBytes))
+	capacityBytesGauge.Set(float64(usage.CapacityBytes))
+
+	// the config map only changes with the available share, not with every audit event
+	if m.reported != nil && m.reported.CapacityBytes == usage.CapacityBytes && m.reported.AvailablePercent() == usage.AvailablePercent() {
+		return nil
+	}
+	required := toConfigMap(m.namespace, m.nodeName, usage)
+	existing, err := m.client.ConfigMaps(m.namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = m.client.ConfigMaps(m.namespace).Create(ctx, required, metav1.CreateOptions{})
+	case err == nil:
+		existing = existing.DeepCopy()
+		existing.Data = required.Data
+		_, err = m.client.ConfigMaps(m.namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	m.reported = &usage
+	return nil
+}
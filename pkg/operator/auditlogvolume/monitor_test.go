@@ -0,0 +1,62 @@
+package auditlogvolume
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMonitor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-log-volume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client := fake.NewSimpleClientset()
+	m := NewMonitor(dir, "openshift-kube-apiserver", "master-0", client.CoreV1())
+
+	if err := m.sync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := client.CoreV1().ConfigMaps("openshift-kube-apiserver").Get(context.TODO(), "audit-log-volume-master-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage, err := fromConfigMap(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.CapacityBytes == 0 || usage.AvailableBytes > usage.CapacityBytes {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	// the config map is not updated while the available share stays the same
+	client.ClearActions()
+	m.reported = &Usage{AvailableBytes: usage.AvailableBytes, CapacityBytes: usage.CapacityBytes}
+	if err := m.sync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("unexpected update of an unchanged usage")
+		}
+	}
+
+	// a changed share is reported
+	client.ClearActions()
+	m.reported = &Usage{AvailableBytes: 0, CapacityBytes: usage.CapacityBytes}
+	if err := m.sync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	updated := false
+	for _, action := range client.Actions() {
+		updated = updated || action.GetVerb() == "update"
+	}
+	if !updated && usage.AvailablePercent() > 0 {
+		t.Errorf("expected an update of the changed usage")
+	}
+}
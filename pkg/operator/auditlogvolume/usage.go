@@ -0,0 +1,69 @@
+package auditlogvolume
+
+import (
+	"fmt"
+	"strconv"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	availableBytesKey = "availableBytes"
+	capacityBytesKey  = "capacityBytes"
+)
+
+// ConfigMapName is the name of the config map in the target namespace the check-endpoints sidecar of the node reports
+// the usage of the audit log volume in.
+func ConfigMapName(nodeName string) string {
+	return "audit-log-volume-" + nodeName
+}
+
+// Usage is the space of the volume of the audit log of a node, as available to unprivileged users.
+type Usage struct {
+	AvailableBytes uint64
+	CapacityBytes  uint64
+}
+
+// AvailablePercent is the share of the capacity that is available, rounded down.
+func (u Usage) AvailablePercent() int {
+	if u.CapacityBytes == 0 {
+		return 0
+	}
+	return int(u.AvailableBytes * 100 / u.CapacityBytes)
+}
+
+// statUsage returns the usage of the file system of dir.
+func statUsage(dir string) (Usage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return Usage{}, err
+	}
+	return Usage{
+		AvailableBytes: uint64(stat.Bavail) * uint64(stat.Bsize),
+		CapacityBytes:  uint64(stat.Blocks) * uint64(stat.Bsize),
+	}, nil
+}
+
+func toConfigMap(namespace, nodeName string, usage Usage) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: ConfigMapName(nodeName)},
+		Data: map[string]string{
+			availableBytesKey: strconv.FormatUint(usage.AvailableBytes, 10),
+			capacityBytesKey:  strconv.FormatUint(usage.CapacityBytes, 10),
+		},
+	}
+}
+
+func fromConfigMap(cm *corev1.ConfigMap) (Usage, error) {
+	available, err := strconv.ParseUint(cm.Data[availableBytesKey], 10, 64)
+	if err != nil {
+		return Usage{}, fmt.Errorf("configmap %s/%s: invalid %s: %v", cm.Namespace, cm.Name, availableBytesKey, err)
+	}
+	capacity, err := strconv.ParseUint(cm.Data[capacityBytesKey], 10, 64)
+	if err != nil {
+		return Usage{}, fmt.Errorf("configmap %s/%s: invalid %s: %v", cm.Namespace, cm.Name, capacityBytesKey, err)
+	}
+	return Usage{AvailableBytes: available, CapacityBytes: capacity}, nil
+}
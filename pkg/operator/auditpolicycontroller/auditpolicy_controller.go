@@ -0,0 +1,240 @@
+package auditpolicycontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// AuditPolicyCustomRulesConflictConditionType is true while custom rules override rules of the audit profile, e.g.
+	// a rule that matches every request and makes the profile unused.
+	AuditPolicyCustomRulesConflictConditionType = "AuditPolicyCustomRulesConflict"
+
+	// AuditDisabledConditionType is true while the acknowledged None audit profile of apiserver/cluster disables audit
+	// logging.
+	AuditDisabledConditionType = "AuditDisabled"
+
+	// customRulesKey is the key of the custom rules config map holding the policy.
+	customRulesKey = "policy.yaml"
+)
+
+// auditPolicyController reconciles a config map in the target namespace with the audit.k8s.io/v1 policy.yaml of the
+// audit profile of apiserver/cluster, like the library-go audit policy controller, and merges the custom rules, the
+// intents, the overrides and the sampling of the auditPolicy of the operator config into it.
+type auditPolicyController struct {
+	apiserverConfigLister                configv1listers.APIServerLister
+	configConfigMapLister                corev1listers.ConfigMapLister
+	targetConfigMapLister                corev1listers.ConfigMapLister
+	namespaceLister                      corev1listers.NamespaceLister
+	kubeClient                           kubernetes.Interface
+	operatorClient                       v1helpers.OperatorClient
+	targetNamespace, targetConfigMapName string
+}
+
+func NewAuditPolicyController(
+	targetNamespace string,
+	targetConfigMapName string,
+	apiserverConfigLister configv1listers.APIServerLister,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	configInformers configinformers.SharedInformerFactory,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &auditPolicyController{
+		operatorClient:        operatorClient,
+		apiserverConfigLister: apiserverConfigLister,
+		configConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		targetConfigMapLister: kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().ConfigMaps().Lister(),
+		namespaceLister:       kubeInformersForNamespaces.InformersFor("").Core().V1().Namespaces().Lister(),
+		kubeClient:            kubeClient,
+		targetNamespace:       targetNamespace,
+		targetConfigMapName:   targetConfigMapName,
+	}
+
+	return factory.New().WithSync(syncmetrics.Instrument("auditPolicyController", c.sync)).ResyncEvery(resyncinterval.For("auditPolicyController", 10*time.Second)).WithInformers(
+		configInformers.Config().V1().APIServers().Informer(),
+		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		// namespace prefixes of the overrides are expanded to the existing namespaces
+		kubeInformersForNamespaces.InformersFor("").Core().V1().Namespaces().Informer(),
+		operatorClient.Informer(),
+	).ToController("auditPolicyController", eventRecorder.WithComponentSuffix("audit-policy-controller"))
+}
+
+func (c *auditPolicyController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorConfigSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfigSpec.ManagementState {
+	case operatorv1.Managed:
+	case operatorv1.Unmanaged:
+		return nil
+	case operatorv1.Removed:
+		return c.kubeClient.CoreV1().ConfigMaps(c.targetNamespace).Delete(ctx, c.targetConfigMapName, metav1.DeleteOptions{})
+	default:
+		syncCtx.Recorder().Warningf("ManagementStateUnknown", "Unrecognized operator management state %q", operatorConfigSpec.ManagementState)
+		return nil
+	}
+
+	config, err := c.apiserverConfigLister.Get("cluster")
+	if err != nil {
+		return err
+	}
+
+	conflicts, disabled, err := c.syncAuditPolicy(ctx, config.Spec.Audit, syncCtx.Recorder())
+
+	// update failing condition
+	degraded := operatorv1.OperatorCondition{
+		Type:   "AuditPolicyDegraded",
+		Status: operatorv1.ConditionFalse,
+	}
+	if err != nil {
+		degraded.Status = operatorv1.ConditionTrue
+		degraded.Reason = "Error"
+		degraded.Message = err.Error()
+	}
+	conflict := operatorv1.OperatorCondition{
+		Type:   AuditPolicyCustomRulesConflictConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if len(conflicts) > 0 {
+		conflict.Status = operatorv1.ConditionTrue
+		conflict.Reason = "CustomRulesOverrideProfile"
+		conflict.Message = strings.Join(conflicts, "\n")
+	}
+	auditDisabled := operatorv1.OperatorCondition{
+		Type:   AuditDisabledConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if disabled {
+		auditDisabled.Status = operatorv1.ConditionTrue
+		auditDisabled.Reason = "NoneAuditProfileAcknowledged"
+		auditDisabled.Message = "The None audit profile of apiserver/cluster disables the audit log of the kube-apiservers, only custom rules and overrides are logged. Set another profile to enable it again."
+	}
+	if _, _, updateError := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(degraded), v1helpers.UpdateConditionFn(conflict), v1helpers.UpdateConditionFn(auditDisabled)); updateError != nil {
+		if err == nil {
+			return updateError
+		}
+	}
+
+	return err
+}
+
+// syncAuditPolicy applies the audit policy and returns the conflicts of the custom rules with the profile. Invalid
+// custom rules, intents or overrides leave the applied policy unchanged, dropping them would silently stop auditing
+// what they log. So does a policy that fails the kube-apiserver validation. A change of the policy is recorded as an
+// event with its diff. The None profile is only rolled out once it is acknowledged in the operator config, disabled
+// tells whether it applies.
+func (c *auditPolicyController) syncAuditPolicy(ctx context.Context, config configv1.Audit, recorder events.Recorder) (conflicts []string, disabled bool, err error) {
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return nil, false, err
+	}
+	customRules, err := c.getCustomRules(operatorConfig.AuditPolicy.CustomRulesConfigMap)
+	if err != nil {
+		return nil, false, err
+	}
+	namespaces, err := c.getNamespaces(operatorConfig.AuditPolicy.Overrides)
+	if err != nil {
+		return nil, false, err
+	}
+	bs, conflicts, err := RenderPolicy(PolicyInputs{
+		Audit:       config,
+		Config:      operatorConfig.AuditPolicy,
+		CustomRules: customRules,
+		Namespaces:  namespaces,
+	})
+	var invalid *InvalidPolicyError
+	if errors.As(err, &invalid) {
+		recorder.Warningf("AuditPolicyInvalid", "Refusing to roll out the invalid audit policy: %v", invalid.Err)
+		return conflicts, false, fmt.Errorf("refusing to roll out the %v", err)
+	}
+	if err != nil {
+		return conflicts, false, err
+	}
+
+	existing, err := c.targetConfigMapLister.ConfigMaps(c.targetNamespace).Get(c.targetConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return conflicts, false, err
+	default:
+		if diff := policyDiff(existing.Data["policy.yaml"], string(bs)); len(diff) > 0 {
+			recorder.Eventf("AuditPolicyChanged", "Audit policy changed:\n%s", diff)
+		}
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.targetNamespace,
+			Name:      c.targetConfigMapName,
+		},
+		Data: map[string]string{
+			"policy.yaml": string(bs),
+		},
+	}
+
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.kubeClient.CoreV1(), recorder, cm)
+	return conflicts, config.Profile == configv1.NoneAuditProfileType, err
+}
+
+// getCustomRules returns the policy.yaml of the custom rules config map, none if there is no config map.
+func (c *auditPolicyController) getCustomRules(name string) ([]byte, error) {
+	if len(name) == 0 {
+		return nil, nil
+	}
+	cm, err := c.configConfigMapLister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("custom audit rules: %w", err)
+	}
+	data, ok := cm.Data[customRulesKey]
+	if !ok {
+		return nil, fmt.Errorf("custom audit rules: configmap %s/%s has no %s", operatorclient.GlobalUserSpecifiedConfigNamespace, name, customRulesKey)
+	}
+	return []byte(data), nil
+}
+
+// getNamespaces returns the names of the existing namespaces if there are overrides, their namespace prefixes are
+// expanded to them.
+func (c *auditPolicyController) getNamespaces(overrides []operatorconfig.AuditPolicyOverride) ([]string, error) {
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,116 @@
+package auditpolicycontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSyncAuditPolicy(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		profile          configv1.AuditProfileType
+		operatorConfig   string
+		existingPolicy   string
+		expectError      bool
+		expectDisabled   bool
+		expectApplied    bool
+		expectedEventMsg string
+	}{
+		{
+			name:          "default profile",
+			profile:       configv1.DefaultAuditProfileType,
+			expectApplied: true,
+		},
+		{
+			name:        "unacknowledged none profile",
+			profile:     configv1.NoneAuditProfileType,
+			expectError: true,
+		},
+		{
+			name:           "acknowledged none profile",
+			profile:        configv1.NoneAuditProfileType,
+			operatorConfig: "auditPolicy:\n  acknowledgeNoneProfile: true\n",
+			expectDisabled: true,
+			expectApplied:  true,
+		},
+		{
+			name:             "changed policy",
+			profile:          configv1.DefaultAuditProfileType,
+			existingPolicy:   "apiVersion: audit.k8s.io/v1\nkind: Policy\n",
+			expectApplied:    true,
+			expectedEventMsg: "Audit policy changed:\n",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var objects []runtime.Object
+			if len(scenario.existingPolicy) > 0 {
+				existing := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-audit-policies"},
+					Data:       map[string]string{"policy.yaml": scenario.existingPolicy},
+				}
+				if err := configMapIndexer.Add(existing); err != nil {
+					t.Fatal(err)
+				}
+				objects = append(objects, existing)
+			}
+			kubeClient := fake.NewSimpleClientset(objects...)
+			c := &auditPolicyController{
+				configConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				targetConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				namespaceLister:       corev1listers.NewNamespaceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+				kubeClient:            kubeClient,
+				targetNamespace:       "openshift-kube-apiserver",
+				targetConfigMapName:   "kube-apiserver-audit-policies",
+			}
+			recorder := events.NewInMemoryRecorder(t.Name())
+
+			_, disabled, err := c.syncAuditPolicy(context.TODO(), configv1.Audit{Profile: scenario.profile}, recorder)
+
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if disabled != scenario.expectDisabled {
+				t.Errorf("expected disabled %v, got %v", scenario.expectDisabled, disabled)
+			}
+			applied, err := kubeClient.CoreV1().ConfigMaps("openshift-kube-apiserver").Get(context.TODO(), "kube-apiserver-audit-policies", metav1.GetOptions{})
+			if err == nil && applied.Data["policy.yaml"] == scenario.existingPolicy {
+				applied = nil
+			}
+			if scenario.expectApplied != (applied != nil) {
+				t.Errorf("expected applied %v, got %v", scenario.expectApplied, applied)
+			}
+			if len(scenario.expectedEventMsg) > 0 {
+				found := false
+				for _, event := range recorder.Events() {
+					if event.Reason == "AuditPolicyChanged" && strings.HasPrefix(event.Message, scenario.expectedEventMsg) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected an AuditPolicyChanged event, got %v", recorder.Events())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package auditpolicycontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	assets "github.com/openshift/library-go/pkg/operator/apiserver/audit/bindata"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/audit/policy"
+)
+
+// baseRules are the rules that every audit policy of library-go starts with, e.g. to not log events and health
+// checks. The custom rules follow them.
+var baseRules []auditv1.PolicyRule
+
+func init() {
+	bs, err := assets.Asset("pkg/operator/apiserver/audit/manifests/base-policy.yaml")
+	if err != nil {
+		panic(err)
+	}
+	var basePolicy auditv1.Policy
+	if err := yaml.Unmarshal(bs, &basePolicy); err != nil {
+		panic(err)
+	}
+	baseRules = basePolicy.Rules
+}
+
+// decodeCustomRules returns the rules of the yaml serialized audit.k8s.io/v1 Policy. Unknown fields and omitStages,
+// which applies to the whole policy, are rejected, as are invalid rules.
+func decodeCustomRules(data []byte) ([]auditv1.PolicyRule, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	var custom auditv1.Policy
+	if err := decoder.Decode(&custom); err != nil {
+		return nil, fmt.Errorf("unable to decode: %v", err)
+	}
+	if len(custom.APIVersion) > 0 && custom.APIVersion != auditv1.SchemeGroupVersion.String() {
+		return nil, fmt.Errorf("unsupported apiVersion %q, only %s is supported", custom.APIVersion, auditv1.SchemeGroupVersion)
+	}
+	if len(custom.Kind) > 0 && custom.Kind != "Policy" {
+		return nil, fmt.Errorf("unsupported kind %q, only Policy is supported", custom.Kind)
+	}
+	if len(custom.OmitStages) > 0 {
+		return nil, fmt.Errorf("omitStages applies to the whole policy, set it in the rules instead")
+	}
+	if len(custom.Rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+
+	// the kube-apiserver validation of the policy, with the errors of the custom rules only
+	rulesOnly := auditv1.Policy{Rules: custom.Rules}
+	rulesOnly.Kind = "Policy"
+	rulesOnly.APIVersion = auditv1.SchemeGroupVersion.String()
+	bs, err := yaml.Marshal(rulesOnly)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policy.LoadPolicyFromBytes(bs); err != nil {
+		return nil, err
+	}
+	return custom.Rules, nil
+}
+
+// mergeCustomRules inserts the custom rules after the base rules, so that they take precedence over the rules of the
+// profile. The first matching rule of an audit policy sets the level of a request.
+func mergeCustomRules(rules, customRules []auditv1.PolicyRule) []auditv1.PolicyRule {
+	if len(customRules) == 0 {
+		return rules
+	}
+	merged := make([]auditv1.PolicyRule, 0, len(rules)+len(customRules))
+	merged = append(merged, rules[:len(baseRules)]...)
+	merged = append(merged, customRules...)
+	return append(merged, rules[len(baseRules):]...)
+}
+
+// customRuleConflicts returns a description of every custom rule that overrides rules of the profile: a rule that
+// matches every request makes the profile unused, a rule for resources that the profile logs at another level
+// changes what is logged for them.
+func customRuleConflicts(customRules, profileRules []auditv1.PolicyRule, profile configv1.AuditProfileType) []string {
+	var conflicts []string
+	for i, custom := range customRules {
+		if matchesEverything(custom) {
+			conflicts = append(conflicts, fmt.Sprintf("rules[%d] matches every request, the rules of the %s profile are not used", i, profile))
+			continue
+		}
+		overridden := sets.NewString()
+		for _, profileRule := range profileRules {
+			if profileRule.Level == custom.Level || !overlaps(custom.Verbs, profileRule.Verbs) || !overlaps(custom.Namespaces, profileRule.Namespaces) {
+				continue
+			}
+			for _, resources := range overlappingResources(custom.Resources, profileRule.Resources) {
+				overridden.Insert(fmt.Sprintf("%s at level %s", resources, profileRule.Level))
+			}
+		}
+		if overridden.Len() > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("rules[%d] sets level %s for %s of the %s profile", i, custom.Level, strings.Join(overridden.List(), ", "), profile))
+		}
+	}
+	return conflicts
+}
+
+// matchesEverything returns true if the rule has no selector.
+func matchesEverything(rule auditv1.PolicyRule) bool {
+	return len(rule.Users) == 0 && len(rule.UserGroups) == 0 && len(rule.Verbs) == 0 && len(rule.Resources) == 0 &&
+		len(rule.Namespaces) == 0 && len(rule.NonResourceURLs) == 0
+}
+
+// overlaps returns true if both selectors match a common value. An empty selector matches everything.
+func overlaps(a, b []string) bool {
+	return len(a) == 0 || len(b) == 0 || sets.NewString(a...).HasAny(b...)
+}
+
+// overlappingResources returns the group/resources that both rules match. A rule without resources only matches
+// resource requests if it has no non-resource URLs either, such rules are not reported.
+func overlappingResources(custom, profile []auditv1.GroupResources) []string {
+	var resources []string
+	for _, c := range custom {
+		for _, p := range profile {
+			if c.Group != p.Group {
+				continue
+			}
+			var common []string
+			switch {
+			case len(c.Resources) == 0 && len(p.Resources) == 0:
+				common = []string{"*"}
+			case len(c.Resources) == 0:
+				common = p.Resources
+			case len(p.Resources) == 0:
+				common = c.Resources
+			default:
+				common = sets.NewString(c.Resources...).Intersection(sets.NewString(p.Resources...)).List()
+			}
+			for _, resource := range common {
+				if len(c.Group) > 0 {
+					resource = resource + "." + c.Group
+				}
+				resources = append(resources, resource)
+			}
+		}
+	}
+	return resources
+}
@@ -0,0 +1,129 @@
+package auditpolicycontroller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/apiserver/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+func TestDecodeCustomRules(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		data          string
+		expectedRules int
+		expectedError string
+	}{
+		{
+			name: "rules",
+			data: `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+- level: RequestResponse
+  resources:
+  - group: ""
+    resources: ["configmaps"]
+  namespaces: ["openshift-config"]
+- level: None
+  users: ["system:serviceaccount:monitoring:scraper"]
+`,
+			expectedRules: 2,
+		},
+		{
+			name:          "rules only",
+			data:          "rules:\n- level: Metadata\n  verbs: [\"delete\"]\n",
+			expectedRules: 1,
+		},
+		{
+			name:          "no rules",
+			data:          "apiVersion: audit.k8s.io/v1\nkind: Policy\n",
+			expectedError: "no rules",
+		},
+		{
+			name:          "omitStages",
+			data:          "omitStages: [\"RequestReceived\"]\nrules:\n- level: Metadata\n",
+			expectedError: "omitStages",
+		},
+		{
+			name:          "unknown field",
+			data:          "rules:\n- level: Metadata\n  resource: secrets\n",
+			expectedError: "unknown field",
+		},
+		{
+			name:          "wrong kind",
+			data:          "kind: ConfigMap\nrules:\n- level: Metadata\n",
+			expectedError: "unsupported kind",
+		},
+		{
+			name:          "invalid level",
+			data:          "rules:\n- level: Everything\n",
+			expectedError: "rules[0].level",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			rules, err := decodeCustomRules([]byte(scenario.data))
+			if len(scenario.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), scenario.expectedError) {
+					t.Fatalf("expected error %q, got %v", scenario.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rules) != scenario.expectedRules {
+				t.Errorf("expected %d rules, got %d", scenario.expectedRules, len(rules))
+			}
+		})
+	}
+}
+
+func TestMergeCustomRules(t *testing.T) {
+	policy, err := audit.GetAuditPolicy(configv1.Audit{Profile: configv1.DefaultAuditProfileType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := auditv1.PolicyRule{Level: auditv1.LevelRequestResponse, Namespaces: []string{"openshift-config"}}
+
+	merged := mergeCustomRules(policy.Rules, []auditv1.PolicyRule{custom})
+	if len(merged) != len(policy.Rules)+1 {
+		t.Fatalf("expected %d rules, got %d", len(policy.Rules)+1, len(merged))
+	}
+	if diff := cmp.Diff(policy.Rules[:len(baseRules)], merged[:len(baseRules)]); len(diff) > 0 {
+		t.Errorf("expected the base rules first: %s", diff)
+	}
+	if diff := cmp.Diff(custom, merged[len(baseRules)]); len(diff) > 0 {
+		t.Errorf("expected the custom rule after the base rules: %s", diff)
+	}
+	if diff := cmp.Diff(policy.Rules[len(baseRules):], merged[len(baseRules)+1:]); len(diff) > 0 {
+		t.Errorf("expected the profile rules last: %s", diff)
+	}
+}
+
+func TestCustomRuleConflicts(t *testing.T) {
+	policy, err := audit.GetAuditPolicy(configv1.Audit{Profile: configv1.DefaultAuditProfileType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profileRules := policy.Rules[len(baseRules):]
+
+	conflicts := customRuleConflicts([]auditv1.PolicyRule{
+		// no overlap with the profile rules that select resources
+		{Level: auditv1.LevelRequestResponse, Resources: []auditv1.GroupResources{{Group: "rbac.authorization.k8s.io"}}},
+		{Level: auditv1.LevelMetadata, Resources: []auditv1.GroupResources{{Group: "oauth.openshift.io", Resources: []string{"oauthaccesstokens", "oauthclients"}}}},
+		{Level: auditv1.LevelRequest},
+	}, profileRules, configv1.DefaultAuditProfileType)
+
+	expected := []string{
+		"rules[1] sets level Metadata for oauthaccesstokens.oauth.openshift.io at level RequestResponse of the Default profile",
+		"rules[2] matches every request, the rules of the Default profile are not used",
+	}
+	if diff := cmp.Diff(expected, conflicts); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,26 @@
+package auditpolicycontroller
+
+import (
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// exemptionRules compiles the exemptions into rules, in their order. A rule matches a request only if both its users
+// and its groups do, so the users and the groups of an exemption get a rule each.
+func exemptionRules(exemptions []operatorconfig.AuditPolicyExemption) []auditv1.PolicyRule {
+	var rules []auditv1.PolicyRule
+	for _, exemption := range exemptions {
+		level := exemption.Level
+		if len(level) == 0 {
+			level = auditv1.LevelNone
+		}
+		if len(exemption.Users) > 0 {
+			rules = append(rules, auditv1.PolicyRule{Level: level, Users: exemption.Users, Verbs: exemption.Verbs})
+		}
+		if len(exemption.Groups) > 0 {
+			rules = append(rules, auditv1.PolicyRule{Level: level, UserGroups: exemption.Groups, Verbs: exemption.Verbs})
+		}
+	}
+	return rules
+}
@@ -0,0 +1,27 @@
+package auditpolicycontroller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestExemptionRules(t *testing.T) {
+	exemptions := []operatorconfig.AuditPolicyExemption{
+		{Users: []string{"system:serviceaccount:backup:velero"}},
+		{Users: []string{"system:serviceaccount:monitoring:scraper"}, Groups: []string{"system:serviceaccounts:monitoring"}, Level: auditv1.LevelMetadata, Verbs: []string{"get", "list", "watch"}},
+	}
+
+	expected := []auditv1.PolicyRule{
+		{Level: auditv1.LevelNone, Users: []string{"system:serviceaccount:backup:velero"}},
+		// the users and the groups are exempted, not the users in the groups
+		{Level: auditv1.LevelMetadata, Users: []string{"system:serviceaccount:monitoring:scraper"}, Verbs: []string{"get", "list", "watch"}},
+		{Level: auditv1.LevelMetadata, UserGroups: []string{"system:serviceaccounts:monitoring"}, Verbs: []string{"get", "list", "watch"}},
+	}
+	if diff := cmp.Diff(expected, exemptionRules(exemptions)); len(diff) > 0 {
+		t.Errorf("unexpected rules (-want +got):\n%s", diff)
+	}
+}
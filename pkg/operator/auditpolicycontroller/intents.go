@@ -0,0 +1,45 @@
+package auditpolicycontroller
+
+import (
+	"sort"
+
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// levelOrder orders the audit levels by how much of a request they log.
+var levelOrder = map[auditv1.Level]int{
+	auditv1.LevelNone:            0,
+	auditv1.LevelMetadata:        1,
+	auditv1.LevelRequest:         2,
+	auditv1.LevelRequestResponse: 3,
+}
+
+// intentRules compiles the intents into rules. The first matching rule of an audit policy sets the level, so the rules
+// are ordered by their level, the highest first, and a request that matches several intents is logged at the highest
+// of their levels. An intent above Metadata is preceded by a rule that logs the sensitive resources it matches at
+// Metadata.
+func intentRules(intents []operatorconfig.AuditPolicyIntent) []auditv1.PolicyRule {
+	sorted := make([]operatorconfig.AuditPolicyIntent, len(intents))
+	copy(sorted, intents)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return levelOrder[sorted[i].Level] > levelOrder[sorted[j].Level]
+	})
+
+	var rules []auditv1.PolicyRule
+	for _, intent := range sorted {
+		rule := auditv1.PolicyRule{
+			Level:      intent.Level,
+			Users:      intent.Users,
+			UserGroups: intent.Groups,
+			Verbs:      intent.Verbs,
+			Namespaces: intent.Namespaces,
+		}
+		for _, resources := range intent.Resources {
+			rule.Resources = append(rule.Resources, auditv1.GroupResources{Group: resources.Group, Resources: resources.Resources})
+		}
+		rules = append(rules, rule)
+	}
+	return redactRules(rules)
+}
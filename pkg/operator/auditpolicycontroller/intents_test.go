@@ -0,0 +1,40 @@
+package auditpolicycontroller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestIntentRules(t *testing.T) {
+	intents := []operatorconfig.AuditPolicyIntent{
+		{Level: auditv1.LevelNone, Users: []string{"system:serviceaccount:backup:velero"}},
+		{Level: auditv1.LevelRequest, Verbs: []string{"delete"}, Resources: []operatorconfig.AuditPolicyResources{{Group: "apps"}, {Resources: []string{"secrets", "configmaps"}}}},
+		{Level: auditv1.LevelRequestResponse, Groups: []string{"system:cluster-admins"}},
+		{Level: auditv1.LevelMetadata, Namespaces: []string{"openshift-config"}},
+	}
+
+	expected := []auditv1.PolicyRule{
+		{Level: auditv1.LevelMetadata, UserGroups: []string{"system:cluster-admins"}, Resources: sensitiveResources},
+		{Level: auditv1.LevelRequestResponse, UserGroups: []string{"system:cluster-admins"}},
+		// only the secrets of the selected resources are sensitive
+		{Level: auditv1.LevelMetadata, Verbs: []string{"delete"}, Resources: []auditv1.GroupResources{{Resources: []string{"secrets"}}}},
+		{Level: auditv1.LevelRequest, Verbs: []string{"delete"}, Resources: []auditv1.GroupResources{{Group: "apps"}, {Resources: []string{"secrets", "configmaps"}}}},
+		{Level: auditv1.LevelMetadata, Namespaces: []string{"openshift-config"}},
+		{Level: auditv1.LevelNone, Users: []string{"system:serviceaccount:backup:velero"}},
+	}
+	if diff := cmp.Diff(expected, intentRules(intents)); len(diff) > 0 {
+		t.Errorf("unexpected rules (-want +got):\n%s", diff)
+	}
+
+	// no sensitive resource selected, no redaction rule
+	rules := intentRules([]operatorconfig.AuditPolicyIntent{
+		{Level: auditv1.LevelRequestResponse, Resources: []operatorconfig.AuditPolicyResources{{Group: "apps", Resources: []string{"deployments"}}}},
+	})
+	if len(rules) != 1 {
+		t.Errorf("expected a single rule, got %v", rules)
+	}
+}
@@ -0,0 +1,163 @@
+package auditpolicycontroller
+
+import (
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/apiserver/audit"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// overrideRules returns the rules of the profiles of the overrides, scoped to their namespaces and resources, in the
+// order of the overrides. Namespace prefixes are expanded to the existing namespaces, an override whose namespaces
+// match none of them has no rules.
+func overrideRules(overrides []operatorconfig.AuditPolicyOverride, existingNamespaces []string) ([]auditv1.PolicyRule, error) {
+	var rules []auditv1.PolicyRule
+	for i, override := range overrides {
+		policy, err := audit.GetAuditPolicy(configv1.Audit{Profile: override.Profile})
+		if err != nil {
+			return nil, fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		var namespaces []string
+		if len(override.Namespaces) > 0 {
+			namespaces = expandNamespaces(override.Namespaces, existingNamespaces)
+			if len(namespaces) == 0 {
+				continue
+			}
+		}
+		var resources []auditv1.GroupResources
+		for _, r := range override.Resources {
+			resources = append(resources, auditv1.GroupResources{Group: r.Group, Resources: r.Resources})
+		}
+		for _, rule := range policy.Rules[len(baseRules):] {
+			if scoped, ok := scopeRule(rule, namespaces, resources); ok {
+				rules = append(rules, scoped)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// expandNamespaces returns the namespaces of the patterns, sorted. A pattern with a trailing * is replaced by the
+// existing namespaces with its prefix, other patterns are kept even if the namespace does not exist yet.
+func expandNamespaces(patterns, existingNamespaces []string) []string {
+	namespaces := sets.NewString()
+	for _, pattern := range patterns {
+		prefix := strings.TrimSuffix(pattern, "*")
+		if prefix == pattern {
+			namespaces.Insert(pattern)
+			continue
+		}
+		for _, namespace := range existingNamespaces {
+			if strings.HasPrefix(namespace, prefix) {
+				namespaces.Insert(namespace)
+			}
+		}
+	}
+	return namespaces.List()
+}
+
+// scopeRule returns the rule limited to the namespaces and resources, false if it cannot match any of them. Rules of
+// non-resource URLs never match, an override always selects namespaces or resources.
+func scopeRule(rule auditv1.PolicyRule, namespaces []string, resources []auditv1.GroupResources) (auditv1.PolicyRule, bool) {
+	if len(rule.NonResourceURLs) > 0 {
+		return auditv1.PolicyRule{}, false
+	}
+	scoped := rule.DeepCopy()
+	if len(namespaces) > 0 {
+		if len(scoped.Namespaces) == 0 {
+			scoped.Namespaces = namespaces
+		} else {
+			scoped.Namespaces = sets.NewString(scoped.Namespaces...).Intersection(sets.NewString(namespaces...)).List()
+			if len(scoped.Namespaces) == 0 {
+				return auditv1.PolicyRule{}, false
+			}
+		}
+	}
+	if len(resources) > 0 {
+		if len(scoped.Resources) == 0 {
+			scoped.Resources = resources
+		} else {
+			scoped.Resources = intersectGroupResources(scoped.Resources, resources)
+			if len(scoped.Resources) == 0 {
+				return auditv1.PolicyRule{}, false
+			}
+		}
+	}
+	return *scoped, true
+}
+
+// intersectGroupResources returns the group/resources of the rule that the override selects too. The resource names
+// of the rule are kept.
+func intersectGroupResources(rule, override []auditv1.GroupResources) []auditv1.GroupResources {
+	var intersection []auditv1.GroupResources
+	for _, r := range rule {
+		for _, o := range override {
+			if r.Group != o.Group {
+				continue
+			}
+			common := auditv1.GroupResources{Group: r.Group, ResourceNames: r.ResourceNames}
+			switch {
+			case len(o.Resources) == 0:
+				common.Resources = r.Resources
+			case len(r.Resources) == 0:
+				common.Resources = o.Resources
+			default:
+				resources := sets.NewString()
+				for _, ruleResource := range r.Resources {
+					for _, overrideResource := range o.Resources {
+						if resource, ok := intersectResource(ruleResource, overrideResource); ok {
+							resources.Insert(resource)
+						}
+					}
+				}
+				if resources.Len() == 0 {
+					continue
+				}
+				common.Resources = resources.List()
+			}
+			intersection = append(intersection, common)
+		}
+	}
+	return intersection
+}
+
+// intersectResource returns the resource that matches the requests both resources of audit policy rules match, e.g.
+// "pods/status" for "*/status" and "pods/*". "*" matches every resource and subresource.
+func intersectResource(a, b string) (string, bool) {
+	switch {
+	case a == "*":
+		return b, true
+	case b == "*":
+		return a, true
+	}
+	aResource, aSubresource := splitResource(a)
+	bResource, bSubresource := splitResource(b)
+	if len(aSubresource) == 0 || len(bSubresource) == 0 {
+		return a, a == b
+	}
+	resource, resourceOK := intersectPart(aResource, bResource)
+	subresource, subresourceOK := intersectPart(aSubresource, bSubresource)
+	return resource + "/" + subresource, resourceOK && subresourceOK
+}
+
+func intersectPart(a, b string) (string, bool) {
+	switch {
+	case a == "*":
+		return b, true
+	case b == "*":
+		return a, true
+	}
+	return a, a == b
+}
+
+func splitResource(resource string) (string, string) {
+	if i := strings.Index(resource, "/"); i >= 0 {
+		return resource[:i], resource[i+1:]
+	}
+	return resource, ""
+}
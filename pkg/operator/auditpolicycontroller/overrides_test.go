@@ -0,0 +1,92 @@
+package auditpolicycontroller
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/audit/policy"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestOverrideRules(t *testing.T) {
+	overrides := []operatorconfig.AuditPolicyOverride{
+		{
+			Profile:    configv1.WriteRequestBodiesAuditProfileType,
+			Namespaces: []string{"openshift-*"},
+			Resources: []operatorconfig.AuditPolicyResources{
+				{Group: "rbac.authorization.k8s.io"},
+				{Resources: []string{"secrets"}},
+			},
+		},
+		// no matching namespace, no rules
+		{Profile: configv1.AllRequestBodiesAuditProfileType, Namespaces: []string{"team-*"}},
+		{Profile: configv1.NoneAuditProfileType, Namespaces: []string{"noisy"}},
+	}
+
+	rules, err := overrideRules(overrides, []string{"default", "openshift-etcd", "openshift-config", "kube-system"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespaces := []string{"openshift-config", "openshift-etcd"}
+	resources := []auditv1.GroupResources{{Group: "rbac.authorization.k8s.io"}, {Resources: []string{"secrets"}}}
+	expected := []auditv1.PolicyRule{
+		// the profile never logs the bodies of secrets
+		{Level: auditv1.LevelMetadata, Namespaces: namespaces, Resources: []auditv1.GroupResources{{Resources: []string{"secrets"}}}},
+		{Level: auditv1.LevelRequestResponse, Namespaces: namespaces, Resources: resources, Verbs: []string{"update", "patch", "create", "delete", "deletecollection"}},
+		{Level: auditv1.LevelMetadata, Namespaces: namespaces, Resources: resources, OmitStages: []auditv1.Stage{auditv1.StageRequestReceived}},
+		{Level: auditv1.LevelNone, Namespaces: []string{"noisy"}},
+	}
+	if diff := cmp.Diff(expected, rules); len(diff) > 0 {
+		t.Error(diff)
+	}
+
+	// the kube-apiserver accepts the rendered rules
+	p := auditv1.Policy{Rules: append(append([]auditv1.PolicyRule{}, baseRules...), rules...)}
+	p.Kind = "Policy"
+	p.APIVersion = auditv1.SchemeGroupVersion.String()
+	bs, err := yaml.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := policy.LoadPolicyFromBytes(bs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpandNamespaces(t *testing.T) {
+	namespaces := expandNamespaces([]string{"openshift-*", "team-a", "openshift-etcd"}, []string{"openshift-etcd", "openshift", "openshift-apiserver", "default"})
+	expected := []string{"openshift-apiserver", "openshift-etcd", "team-a"}
+	if diff := cmp.Diff(expected, namespaces); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
+
+func TestIntersectResource(t *testing.T) {
+	scenarios := []struct {
+		a, b       string
+		expected   string
+		expectedOK bool
+	}{
+		{a: "pods", b: "pods", expected: "pods", expectedOK: true},
+		{a: "pods", b: "secrets"},
+		{a: "*", b: "pods/exec", expected: "pods/exec", expectedOK: true},
+		{a: "pods", b: "pods/exec"},
+		{a: "pods/*", b: "pods/exec", expected: "pods/exec", expectedOK: true},
+		{a: "*/status", b: "pods/*", expected: "pods/status", expectedOK: true},
+		{a: "*/status", b: "pods/exec"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.a+" "+scenario.b, func(t *testing.T) {
+			resource, ok := intersectResource(scenario.a, scenario.b)
+			if ok != scenario.expectedOK || (ok && resource != scenario.expected) {
+				t.Errorf("expected %q %v, got %q %v", scenario.expected, scenario.expectedOK, resource, ok)
+			}
+		})
+	}
+}
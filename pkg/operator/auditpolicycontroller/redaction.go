@@ -0,0 +1,144 @@
+package auditpolicycontroller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/apis/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/audit/policy"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// sensitiveResources are the resources whose request and response bodies hold credentials. They are never logged
+// above Metadata, like the profiles of library-go do for secrets, routes and OAuth clients.
+var sensitiveResources = []auditv1.GroupResources{
+	{Group: "", Resources: []string{"secrets", "serviceaccounts/token"}},
+	{Group: "authentication.k8s.io", Resources: []string{"tokenreviews"}},
+	{Group: "oauth.openshift.io", Resources: []string{"oauthaccesstokens", "oauthauthorizetokens", "useroauthaccesstokens", "oauthclients"}},
+	{Group: "route.openshift.io", Resources: []string{"routes"}},
+}
+
+// redactionCheckPlaceholder stands for the users, groups, namespaces, names and verbs that no rule of a policy names.
+const redactionCheckPlaceholder = "redaction-check"
+
+// redactRules precedes every rule above Metadata that matches sensitive resources with a copy that logs them at
+// Metadata. Rules of non-resource URLs never match a resource.
+func redactRules(rules []auditv1.PolicyRule) []auditv1.PolicyRule {
+	var redacted []auditv1.PolicyRule
+	for _, rule := range rules {
+		if levelOrder[rule.Level] > levelOrder[auditv1.LevelMetadata] && len(rule.NonResourceURLs) == 0 {
+			sensitive := sensitiveResources
+			if len(rule.Resources) > 0 {
+				sensitive = intersectGroupResources(rule.Resources, sensitiveResources)
+			}
+			if len(sensitive) > 0 {
+				metadata := *rule.DeepCopy()
+				metadata.Level = auditv1.LevelMetadata
+				metadata.Resources = sensitive
+				redacted = append(redacted, metadata)
+			}
+		}
+		redacted = append(redacted, rule)
+	}
+	return redacted
+}
+
+// verifyRedaction checks that the policy logs no request to a sensitive resource above Metadata, whoever sends it,
+// with whatever verb, in whatever namespace. A request carries one user, namespace, name and verb, and a rule that
+// matches one of its groups matches it, so it is enough to try the values the rules name, one at a time, and a
+// placeholder for all the others. The error names the first offending rule and a request it logs.
+func verifyRedaction(p *audit.Policy) error {
+	users := sets.NewString(redactionCheckPlaceholder)
+	groups := sets.NewString(redactionCheckPlaceholder)
+	verbs := sets.NewString(redactionCheckPlaceholder)
+	// cluster scoped requests and lists have none
+	namespaces := sets.NewString(redactionCheckPlaceholder, "")
+	names := sets.NewString(redactionCheckPlaceholder, "")
+	for _, rule := range p.Rules {
+		users.Insert(rule.Users...)
+		groups.Insert(rule.UserGroups...)
+		namespaces.Insert(rule.Namespaces...)
+		verbs.Insert(rule.Verbs...)
+		for _, resources := range rule.Resources {
+			names.Insert(resources.ResourceNames...)
+		}
+	}
+
+	checker := policy.NewChecker(p)
+	for _, resources := range sensitiveResources {
+		for _, groupResource := range resources.Resources {
+			resource, subresource := splitResource(groupResource)
+			for _, userName := range candidates(users) {
+				for _, group := range candidates(groups) {
+					for _, namespace := range candidates(namespaces) {
+						for _, name := range candidates(names) {
+							for _, verb := range candidates(verbs) {
+								attributes := authorizer.AttributesRecord{
+									User:            &user.DefaultInfo{Name: userName, Groups: []string{group}},
+									Verb:            verb,
+									Namespace:       namespace,
+									APIGroup:        resources.Group,
+									Resource:        resource,
+									Subresource:     subresource,
+									Name:            name,
+									ResourceRequest: true,
+								}
+								if level, _ := checker.LevelAndStages(attributes); level.GreaterOrEqual(audit.LevelRequest) {
+									return fmt.Errorf("rules[%d] logs %s at %s, the bodies of sensitive resources hold credentials and must not be logged above %s", matchingRule(p, attributes), describeRequest(attributes), level, audit.LevelMetadata)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// candidates returns the values with the placeholder first, so that an error names the least specific request.
+func candidates(values sets.String) []string {
+	return append([]string{redactionCheckPlaceholder}, values.Difference(sets.NewString(redactionCheckPlaceholder)).List()...)
+}
+
+// matchingRule returns the index of the first rule of the policy that matches the request.
+func matchingRule(p *audit.Policy, attributes authorizer.Attributes) int {
+	for i, rule := range p.Rules {
+		single := rule.DeepCopy()
+		single.Level = audit.LevelRequestResponse
+		if level, _ := policy.NewChecker(&audit.Policy{Rules: []audit.PolicyRule{*single}}).LevelAndStages(attributes); level == audit.LevelRequestResponse {
+			return i
+		}
+	}
+	return -1
+}
+
+// describeRequest describes the request of the attributes, leaving out the placeholders.
+func describeRequest(attributes authorizer.AttributesRecord) string {
+	resource := attributes.Resource
+	if len(attributes.Subresource) > 0 {
+		resource += "/" + attributes.Subresource
+	}
+	if len(attributes.APIGroup) > 0 {
+		resource += "." + attributes.APIGroup
+	}
+	description := fmt.Sprintf("requests to %s", resource)
+	if attributes.Verb != redactionCheckPlaceholder {
+		description = fmt.Sprintf("%s %s", attributes.Verb, description)
+	}
+	if len(attributes.Name) > 0 && attributes.Name != redactionCheckPlaceholder {
+		description += fmt.Sprintf(" named %q", attributes.Name)
+	}
+	if len(attributes.Namespace) > 0 && attributes.Namespace != redactionCheckPlaceholder {
+		description += fmt.Sprintf(" in namespace %q", attributes.Namespace)
+	}
+	if attributes.User.GetName() != redactionCheckPlaceholder {
+		description += fmt.Sprintf(" by user %q", attributes.User.GetName())
+	}
+	if group := attributes.User.GetGroups()[0]; group != redactionCheckPlaceholder {
+		description += fmt.Sprintf(" by group %q", group)
+	}
+	return description
+}
@@ -0,0 +1,87 @@
+package auditpolicycontroller
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestRenderPolicyRedaction(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		in            PolicyInputs
+		expectedError string
+	}{
+		{name: "Default profile", in: PolicyInputs{Audit: configv1.Audit{Profile: configv1.DefaultAuditProfileType}}},
+		{name: "WriteRequestBodies profile", in: PolicyInputs{Audit: configv1.Audit{Profile: configv1.WriteRequestBodiesAuditProfileType}}},
+		{name: "AllRequestBodies profile", in: PolicyInputs{Audit: configv1.Audit{Profile: configv1.AllRequestBodiesAuditProfileType}}},
+		{
+			name: "AllRequestBodies for a group",
+			in: PolicyInputs{Audit: configv1.Audit{
+				Profile:     configv1.DefaultAuditProfileType,
+				CustomRules: []configv1.AuditCustomRule{{Group: "system:authenticated:oauth", Profile: configv1.AllRequestBodiesAuditProfileType}},
+			}},
+		},
+		{
+			name: "override and intent",
+			in: PolicyInputs{
+				Audit: configv1.Audit{Profile: configv1.DefaultAuditProfileType},
+				Config: operatorconfig.AuditPolicyConfig{
+					Overrides: []operatorconfig.AuditPolicyOverride{{Profile: configv1.AllRequestBodiesAuditProfileType, Namespaces: []string{"payments"}}},
+					Intents:   []operatorconfig.AuditPolicyIntent{{Level: auditv1.LevelRequestResponse, Users: []string{"alice"}}},
+				},
+			},
+		},
+		{
+			name: "custom rule logging secrets",
+			in: PolicyInputs{
+				Audit:       configv1.Audit{Profile: configv1.DefaultAuditProfileType},
+				Config:      operatorconfig.AuditPolicyConfig{CustomRulesConfigMap: "audit-rules"},
+				CustomRules: []byte("rules:\n- level: Metadata\n  users: [bob]\n- level: Request\n  namespaces: [payments]\n  verbs: [create]\n  resources:\n  - resources: [secrets]\n"),
+			},
+			expectedError: `rules[3] logs create requests to secrets in namespace "payments" at Request`,
+		},
+		{
+			name: "custom rule logging everything of a user",
+			in: PolicyInputs{
+				Audit:       configv1.Audit{Profile: configv1.DefaultAuditProfileType},
+				Config:      operatorconfig.AuditPolicyConfig{CustomRulesConfigMap: "audit-rules"},
+				CustomRules: []byte("rules:\n- level: RequestResponse\n  users: [alice]\n"),
+			},
+			expectedError: `rules[2] logs requests to secrets by user "alice" at RequestResponse`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, _, err := RenderPolicy(scenario.in)
+			if len(scenario.expectedError) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if _, ok := err.(*InvalidPolicyError); !ok || !strings.Contains(err.Error(), scenario.expectedError) {
+				t.Errorf("expected an invalid policy error containing %q, got %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRedactRules(t *testing.T) {
+	rules := redactRules([]auditv1.PolicyRule{
+		{Level: auditv1.LevelNone, Users: []string{"system:serviceaccount:backup:velero"}},
+		{Level: auditv1.LevelRequest, Resources: []auditv1.GroupResources{{Group: "apps"}}},
+		{Level: auditv1.LevelRequestResponse, Namespaces: []string{"payments"}},
+	})
+	if len(rules) != 4 {
+		t.Fatalf("expected a single redaction rule, got %v", rules)
+	}
+	if rules[2].Level != auditv1.LevelMetadata || len(rules[2].Namespaces) != 1 || len(rules[2].Resources) != len(sensitiveResources) {
+		t.Errorf("unexpected redaction rule %v", rules[2])
+	}
+}
@@ -0,0 +1,90 @@
+package auditpolicycontroller
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/apiserver/audit"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// PolicyInputs are what the audit policy is rendered from.
+type PolicyInputs struct {
+	// Audit is the audit config of apiserver/cluster.
+	Audit configv1.Audit
+	// Config is the auditPolicy of the operator config.
+	Config operatorconfig.AuditPolicyConfig
+	// CustomRules is the policy.yaml of the custom rules config map of the config, if it names one.
+	CustomRules []byte
+	// Namespaces are the existing namespaces, the namespace prefixes of the overrides are expanded to them.
+	Namespaces []string
+}
+
+// InvalidPolicyError is returned for a rendered policy that the kube-apiserver would fail to load or that logs
+// credentials.
+type InvalidPolicyError struct {
+	Err error
+}
+
+func (e *InvalidPolicyError) Error() string {
+	return fmt.Sprintf("invalid audit policy: %v", e.Err)
+}
+
+// RenderPolicy returns the audit.k8s.io/v1 policy.yaml that the operator rolls out for the inputs and the conflicts of
+// the custom rules with the profile. The rules of the profile follow the custom rules, the exemptions, the intents, the
+// overrides and the sampling of the config, in this order. An unacknowledged None profile, a policy the kube-apiserver would reject
+// and a policy that logs the bodies of sensitive resources are errors.
+func RenderPolicy(in PolicyInputs) ([]byte, []string, error) {
+	desired, err := audit.GetAuditPolicy(in.Audit)
+	if err != nil {
+		return nil, nil, err
+	}
+	desired = desired.DeepCopy()
+	desired.Kind = "Policy"
+	desired.APIVersion = auditv1.SchemeGroupVersion.String()
+
+	if errs := operatorconfig.ValidateAuditPolicy(in.Config, field.NewPath("auditPolicy")); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+	if in.Audit.Profile == configv1.NoneAuditProfileType && !in.Config.AcknowledgeNoneProfile {
+		return nil, nil, fmt.Errorf("refusing to roll out the None audit profile of apiserver/cluster, it disables the audit log: set auditPolicy.acknowledgeNoneProfile in the operator config to accept it")
+	}
+	var customRules []auditv1.PolicyRule
+	if len(in.Config.CustomRulesConfigMap) > 0 {
+		customRules, err = decodeCustomRules(in.CustomRules)
+		if err != nil {
+			return nil, nil, fmt.Errorf("custom audit rules in configmap %s: %w", in.Config.CustomRulesConfigMap, err)
+		}
+	}
+	overrides, err := overrideRules(in.Config.Overrides, in.Namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts := customRuleConflicts(customRules, desired.Rules[len(baseRules):], in.Audit.Profile)
+	// the rules of the profiles log credentials, e.g. the token reviews of AllRequestBodies
+	desired.Rules = append(desired.Rules[:len(baseRules):len(baseRules)], redactRules(desired.Rules[len(baseRules):])...)
+	// the custom rules take precedence over the exemptions, the exemptions over the intents, the intents over the
+	// overrides, the overrides over the sampling, all of them over the profile
+	rules := append(customRules, exemptionRules(in.Config.Exemptions)...)
+	rules = append(rules, intentRules(in.Config.Intents)...)
+	rules = append(append(rules, redactRules(overrides)...), samplingRules(in.Config.Sampling, in.Audit.Profile)...)
+	desired.Rules = mergeCustomRules(desired.Rules, rules)
+
+	bs, err := yaml.Marshal(desired)
+	if err != nil {
+		return nil, nil, err
+	}
+	loaded, err := validatePolicy(bs)
+	if err != nil {
+		return nil, conflicts, &InvalidPolicyError{Err: err}
+	}
+	// custom rules are rolled out as they are, the redaction of everything else is verified too
+	if err := verifyRedaction(loaded); err != nil {
+		return nil, conflicts, &InvalidPolicyError{Err: err}
+	}
+	return bs, conflicts, nil
+}
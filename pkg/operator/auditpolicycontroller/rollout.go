@@ -0,0 +1,65 @@
+package auditpolicycontroller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit/policy"
+)
+
+// maxPolicyDiffLength bounds the diff recorded in the event of a policy change, the policy of a profile with custom
+// rules is larger than an event should be.
+const maxPolicyDiffLength = 1024
+
+// validatePolicy validates the whole policy like the kube-apiserver does when it loads the policy file. An invalid
+// policy must never reach a revision, every kube-apiserver would fail to start with it.
+func validatePolicy(data []byte) (*audit.Policy, error) {
+	return policy.LoadPolicyFromBytes(data)
+}
+
+// policyDiff returns the lines removed from the current policy.yaml prefixed with "-" and the lines added by the
+// desired one prefixed with "+", truncated to maxPolicyDiffLength, or an empty string if they are equal.
+func policyDiff(current, desired string) string {
+	if current == desired {
+		return ""
+	}
+	a, b := strings.Split(current, "\n"), strings.Split(desired, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&diff, "-%s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+%s\n", b[j])
+			j++
+		}
+	}
+	if diff.Len() > maxPolicyDiffLength {
+		return diff.String()[:maxPolicyDiffLength] + "\n... (truncated)"
+	}
+	return diff.String()
+}
@@ -0,0 +1,81 @@
+package auditpolicycontroller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		policy      string
+		expectError bool
+	}{
+		{
+			name: "valid",
+			policy: `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+- level: Metadata
+`,
+		},
+		{
+			name: "invalid level",
+			policy: `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+- level: Everything
+`,
+			expectError: true,
+		},
+		{
+			name: "resources of a non-resource url rule",
+			policy: `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+- level: None
+  nonResourceURLs:
+  - /healthz
+  resources:
+  - resources:
+    - pods
+`,
+			expectError: true,
+		},
+		{
+			name:        "not a policy",
+			policy:      "rules: [",
+			expectError: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := validatePolicy([]byte(scenario.policy))
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicyDiff(t *testing.T) {
+	current := "rules:\n- level: None\n- level: Metadata\n"
+	desired := "rules:\n- level: None\n- level: RequestResponse\n"
+
+	if diff := policyDiff(current, current); len(diff) > 0 {
+		t.Errorf("expected no diff of equal policies, got %q", diff)
+	}
+
+	expected := `-- level: Metadata
++- level: RequestResponse
+`
+	if diff := policyDiff(current, desired); diff != expected {
+		t.Errorf("expected diff\n%s\ngot\n%s", expected, diff)
+	}
+
+	large := strings.Repeat("- level: Metadata\n", 100)
+	if diff := policyDiff("", large); len(diff) > maxPolicyDiffLength+len("\n... (truncated)") || !strings.HasSuffix(diff, "... (truncated)") {
+		t.Errorf("expected a truncated diff, got %d bytes", len(diff))
+	}
+}
@@ -0,0 +1,33 @@
+package auditpolicycontroller
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var readVerbs = []string{"get", "list", "watch"}
+
+// securityRelevantResources are still logged at Metadata when the level of read requests is lowered, reading them
+// discloses credentials or who may do what.
+var securityRelevantResources = []auditv1.GroupResources{
+	{Group: "", Resources: []string{"secrets", "configmaps", "serviceaccounts"}},
+	{Group: "rbac.authorization.k8s.io"},
+	{Group: "authorization.openshift.io"},
+	{Group: "oauth.openshift.io", Resources: []string{"oauthaccesstokens", "oauthauthorizetokens", "useroauthaccesstokens"}},
+	{Group: "user.openshift.io", Resources: []string{"users", "groups", "identities"}},
+}
+
+// samplingRules returns the rules that log reads of the security relevant resources at Metadata and every other read
+// request at the read level of the sampling, none without sampling or with the None profile, which logs nothing.
+func samplingRules(sampling *operatorconfig.AuditSamplingConfig, profile configv1.AuditProfileType) []auditv1.PolicyRule {
+	if sampling == nil || profile == configv1.NoneAuditProfileType {
+		return nil
+	}
+	omitStages := []auditv1.Stage{auditv1.StageRequestReceived}
+	return []auditv1.PolicyRule{
+		{Level: auditv1.LevelMetadata, Verbs: readVerbs, Resources: securityRelevantResources, OmitStages: omitStages},
+		{Level: sampling.ReadLevel, Verbs: readVerbs, OmitStages: omitStages},
+	}
+}
@@ -0,0 +1,73 @@
+package auditpolicycontroller
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	libgoaudit "github.com/openshift/library-go/pkg/operator/apiserver/audit"
+	"k8s.io/apiserver/pkg/apis/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/audit/policy"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestSamplingRules(t *testing.T) {
+	if rules := samplingRules(&operatorconfig.AuditSamplingConfig{ReadLevel: auditv1.LevelNone}, configv1.NoneAuditProfileType); len(rules) > 0 {
+		t.Errorf("expected no rules with the None profile, got %v", rules)
+	}
+	if rules := samplingRules(nil, configv1.AllRequestBodiesAuditProfileType); len(rules) > 0 {
+		t.Errorf("expected no rules without sampling, got %v", rules)
+	}
+
+	profile, err := libgoaudit.GetAuditPolicy(configv1.Audit{Profile: configv1.AllRequestBodiesAuditProfileType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := profile.DeepCopy()
+	merged.Kind = "Policy"
+	merged.APIVersion = auditv1.SchemeGroupVersion.String()
+	merged.Rules = mergeCustomRules(merged.Rules, samplingRules(&operatorconfig.AuditSamplingConfig{ReadLevel: auditv1.LevelNone}, configv1.AllRequestBodiesAuditProfileType))
+	bs, err := yaml.Marshal(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := policy.LoadPolicyFromBytes(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := policy.NewChecker(loaded)
+
+	scenarios := []struct {
+		name          string
+		verb          string
+		apiGroup      string
+		resource      string
+		expectedLevel audit.Level
+	}{
+		{name: "read of a pod", verb: "list", resource: "pods", expectedLevel: audit.LevelNone},
+		{name: "watch of a route", verb: "watch", apiGroup: "route.openshift.io", resource: "routes", expectedLevel: audit.LevelNone},
+		{name: "read of a secret", verb: "get", resource: "secrets", expectedLevel: audit.LevelMetadata},
+		{name: "read of a role binding", verb: "get", apiGroup: "rbac.authorization.k8s.io", resource: "rolebindings", expectedLevel: audit.LevelMetadata},
+		{name: "write of a pod", verb: "create", resource: "pods", expectedLevel: audit.LevelRequestResponse},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			level, _ := checker.LevelAndStages(authorizer.AttributesRecord{
+				User:            &user.DefaultInfo{Name: "alice", Groups: []string{"system:authenticated"}},
+				Verb:            scenario.verb,
+				Namespace:       "team-a",
+				APIGroup:        scenario.apiGroup,
+				APIVersion:      "v1",
+				Resource:        scenario.resource,
+				ResourceRequest: true,
+			})
+			if level != scenario.expectedLevel {
+				t.Errorf("expected level %s, got %s", scenario.expectedLevel, level)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package auditwebhookcontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"k8s.io/client-go/util/cert"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// KubeconfigSecretName is the secret in the operator namespace with the kubeconfig rendered from the url of the
+	// audit webhook. The config observer syncs it into the revisions like a kubeConfigSecret of openshift-config.
+	KubeconfigSecretName = "audit-webhook-kubeconfig"
+	KubeconfigKey        = "kubeConfig"
+
+	caBundleKey = "ca-bundle.crt"
+	webhookName = "audit-webhook"
+)
+
+// AuditWebhookController renders the kubeconfig of an audit webhook that is configured by url in the operator config,
+// with the CAs of caConfigMap and the client certificate of clientCertSecret inlined. It removes the kubeconfig when
+// the webhook is configured by kubeConfigSecret or not at all. A CA bundle or client certificate that does not parse
+// keeps the previous kubeconfig and degrades the controller.
+type AuditWebhookController struct {
+	secretClient          coreclientv1.SecretsGetter
+	configConfigMapLister corev1listers.ConfigMapLister
+	configSecretLister    corev1listers.SecretLister
+	operatorSecretLister  corev1listers.SecretLister
+}
+
+func NewAuditWebhookController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	secretClient coreclientv1.SecretsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &AuditWebhookController{
+		secretClient:          secretClient,
+		configConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		configSecretLister:    kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Lister(),
+		operatorSecretLister:  kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
+	}
+	return factory.New().WithInformers(
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Informer(),
+	).WithSync(syncmetrics.Instrument("AuditWebhookController", c.sync)).WithSyncDegradedOnError(operatorClient).ToController("AuditWebhookController", eventRecorder.WithComponentSuffix("audit-webhook-controller"))
+}
+
+func (c *AuditWebhookController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return err
+	}
+	config := operatorConfig.AuditWebhook
+	if errs := operatorconfig.ValidateAuditWebhook(config, field.NewPath("auditWebhook")); len(errs) > 0 {
+		// the config observer reports the invalid operator config
+		return nil
+	}
+	if config == nil || len(config.URL) == 0 {
+		return c.removeKubeconfig(ctx, syncCtx.Recorder())
+	}
+
+	kubeconfig, err := c.renderKubeconfig(config)
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplySecret(ctx, c.secretClient, syncCtx.Recorder(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: KubeconfigSecretName},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{KubeconfigKey: kubeconfig},
+	})
+	return err
+}
+
+// renderKubeconfig returns a kubeconfig for the url with a single cluster, user and context and the certificates
+// inlined, like the config observer expects of a kubeConfigSecret.
+func (c *AuditWebhookController) renderKubeconfig(config *operatorconfig.AuditWebhookConfig) ([]byte, error) {
+	cluster := clientcmdv1.Cluster{Server: config.URL}
+	if len(config.CAConfigMap) > 0 {
+		configMap, err := c.configConfigMapLister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(config.CAConfigMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the CA bundle of the audit webhook: %w", err)
+		}
+		caBundle := []byte(configMap.Data[caBundleKey])
+		if _, err := cert.ParseCertsPEM(caBundle); err != nil {
+			return nil, fmt.Errorf("invalid %s of configmap %s/%s: %v", caBundleKey, operatorclient.GlobalUserSpecifiedConfigNamespace, config.CAConfigMap, err)
+		}
+		cluster.CertificateAuthorityData = caBundle
+	}
+
+	secret, err := c.configSecretLister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(config.ClientCertSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the client certificate of the audit webhook: %w", err)
+	}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return nil, fmt.Errorf("invalid client certificate in secret %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, config.ClientCertSecret, err)
+	}
+
+	return yaml.Marshal(&clientcmdv1.Config{
+		Kind:       "Config",
+		APIVersion: "v1",
+		Clusters:   []clientcmdv1.NamedCluster{{Name: webhookName, Cluster: cluster}},
+		AuthInfos: []clientcmdv1.NamedAuthInfo{{Name: webhookName, AuthInfo: clientcmdv1.AuthInfo{
+			ClientCertificateData: secret.Data[corev1.TLSCertKey],
+			ClientKeyData:         secret.Data[corev1.TLSPrivateKeyKey],
+		}}},
+		Contexts:       []clientcmdv1.NamedContext{{Name: webhookName, Context: clientcmdv1.Context{Cluster: webhookName, AuthInfo: webhookName}}},
+		CurrentContext: webhookName,
+	})
+}
+
+func (c *AuditWebhookController) removeKubeconfig(ctx context.Context, recorder events.Recorder) error {
+	if _, err := c.operatorSecretLister.Secrets(operatorclient.OperatorNamespace).Get(KubeconfigSecretName); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	err := c.secretClient.Secrets(operatorclient.OperatorNamespace).Delete(ctx, KubeconfigSecretName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err == nil {
+		recorder.Eventf("SecretDeleted", "Deleted Secret/%s -n %s, the audit webhook is not configured by url", KubeconfigSecretName, operatorclient.OperatorNamespace)
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package auditwebhookcontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/auth"
+)
+
+func TestSync(t *testing.T) {
+	clientCert, clientKey, err := certutil.GenerateSelfSignedCertKey("kube-apiserver", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _, err := certutil.GenerateSelfSignedCertKey("siem-ca", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorConfig := func(config string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+			Data:       map[string]string{"config.yaml": config},
+		}
+	}
+	clientCertSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "siem-client"},
+		Data:       map[string][]byte{"tls.crt": clientCert, "tls.key": clientKey},
+	}
+	caConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "siem-ca"},
+		Data:       map[string]string{"ca-bundle.crt": string(ca)},
+	}
+	rendered := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver-operator", Name: KubeconfigSecretName}}
+
+	scenarios := []struct {
+		name           string
+		objects        []runtime.Object
+		expectRendered bool
+		expectedCAData []byte
+		expectError    bool
+	}{
+		{
+			name:           "url with a CA",
+			objects:        []runtime.Object{operatorConfig("auditWebhook:\n  url: https://siem.example.com:8443/audit\n  caConfigMap: siem-ca\n  clientCertSecret: siem-client\n"), caConfigMap, clientCertSecret},
+			expectRendered: true,
+			expectedCAData: ca,
+		},
+		{
+			name:           "url with the system trust store",
+			objects:        []runtime.Object{operatorConfig("auditWebhook:\n  url: https://siem.example.com:8443/audit\n  clientCertSecret: siem-client\n"), clientCertSecret},
+			expectRendered: true,
+		},
+		{
+			name: "invalid client certificate",
+			objects: []runtime.Object{
+				operatorConfig("auditWebhook:\n  url: https://siem.example.com:8443/audit\n  clientCertSecret: siem-client\n"),
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "siem-client"}, Data: map[string][]byte{"tls.crt": clientCert}},
+			},
+			expectError: true,
+		},
+		{
+			name:    "kubeconfig secret removes the rendered kubeconfig",
+			objects: []runtime.Object{operatorConfig("auditWebhook:\n  kubeConfigSecret: siem-webhook\n"), rendered},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(scenario.objects...)
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, object := range scenario.objects {
+				indexer := configMapIndexer
+				if _, ok := object.(*corev1.Secret); ok {
+					indexer = secretIndexer
+				}
+				if err := indexer.Add(object); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &AuditWebhookController{
+				secretClient:          client.CoreV1(),
+				configConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				configSecretLister:    corev1listers.NewSecretLister(secretIndexer),
+				operatorSecretLister:  corev1listers.NewSecretLister(secretIndexer),
+			}
+
+			err := c.sync(context.TODO(), factory.NewSyncContext("AuditWebhookController", events.NewInMemoryRecorder(t.Name())))
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			secret, err := client.CoreV1().Secrets("openshift-kube-apiserver-operator").Get(context.TODO(), KubeconfigSecretName, metav1.GetOptions{})
+			if !scenario.expectRendered {
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("expected no rendered kubeconfig, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the rendered kubeconfig passes the validation of the config observer
+			if errs := auth.ValidateKubeconfigSecret(secret); len(errs) > 0 {
+				t.Fatalf("invalid kubeconfig: %v", errs)
+			}
+			kubeconfig, err := clientcmd.Load(secret.Data[KubeconfigKey])
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := kubeconfig.Clusters[kubeconfig.Contexts[kubeconfig.CurrentContext].Cluster]
+			if cluster.Server != "https://siem.example.com:8443/audit" || string(cluster.CertificateAuthorityData) != string(scenario.expectedCAData) {
+				t.Errorf("unexpected cluster %+v", cluster)
+			}
+		})
+	}
+}
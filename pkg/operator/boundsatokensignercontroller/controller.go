@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -16,17 +17,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	errorsutil "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/util/keyutil"
 	"k8s.io/klog/v2"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 )
 
 const (
@@ -43,14 +50,21 @@ const (
 	PublicKeyKey         = "service-account.pub"
 
 	PublicKeyConfigMapName = "bound-sa-token-signing-certs"
+
+	// SigningKeySourceAnnotation is set on the signing secrets holding the keypair of the secret referenced by
+	// serviceAccountSigningKey of the operator config, to the namespace/name of that secret.
+	SigningKeySourceAnnotation = "kubeapiserver.operator.openshift.io/signing-key-source"
 )
 
 // BoundSATokenSignerController manages the keypair used to sign bound
 // tokens and the key bundle used to verify them.
 type BoundSATokenSignerController struct {
-	operatorClient  v1helpers.StaticPodOperatorClient
-	secretClient    corev1client.SecretsGetter
-	configMapClient corev1client.ConfigMapsGetter
+	operatorClient        v1helpers.StaticPodOperatorClient
+	secretClient          corev1client.SecretsGetter
+	configMapClient       corev1client.ConfigMapsGetter
+	configConfigMapLister corev1listers.ConfigMapLister
+
+	now func() time.Time
 }
 
 func NewBoundSATokenSignerController(
@@ -61,17 +75,21 @@ func NewBoundSATokenSignerController(
 ) factory.Controller {
 
 	ret := &BoundSATokenSignerController{
-		operatorClient:  operatorClient,
-		secretClient:    v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
-		configMapClient: v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		operatorClient:        operatorClient,
+		secretClient:          v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		configMapClient:       v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		configConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		now:                   time.Now,
 	}
 
 	return factory.New().WithInformers(
 		kubeInformersForNamespaces.InformersFor(operatorNamespace).Core().V1().Secrets().Informer(),
 		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().Secrets().Informer(),
 		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
 		operatorClient.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ret.sync).ToController("BoundSATokenSignerController", eventRecorder)
+	).ResyncEvery(resyncinterval.For("BoundSATokenSignerController", time.Minute)).WithSync(syncmetrics.Instrument("BoundSATokenSignerController", ret.sync)).ToController("BoundSATokenSignerController", eventRecorder)
 }
 
 func (c *BoundSATokenSignerController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
@@ -93,7 +111,9 @@ func (c *BoundSATokenSignerController) sync(ctx context.Context, syncCtx factory
 
 // ensureNextOperatorSigningSecret ensures the existence of a secret in the operator
 // namespace containing an RSA keypair used for signing and validating bound service
-// account tokens.
+// account tokens. The keypair is the one referenced by the operator config, if any,
+// and generated otherwise. A generated keypair is rotated as configured by
+// serviceAccountSigningKeyRotation of the operator config.
 func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx context.Context, syncCtx factory.SyncContext) error {
 	// Attempt to retrieve the operator secret
 	secret, err := c.secretClient.Secrets(operatorNamespace).Get(ctx, NextSigningKeySecretName, metav1.GetOptions{})
@@ -101,11 +121,35 @@ func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx conte
 		return err
 	}
 
-	// Create or update the secret if it is missing or lacks the expected keypair data
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return err
+	}
+	if errs := operatorconfig.ValidateServiceAccountSigningKey(operatorConfig.ServiceAccountSigningKey, field.NewPath("serviceAccountSigningKey")); len(errs) > 0 {
+		return fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+	if operatorConfig.ServiceAccountSigningKey != nil {
+		return c.ensureUserProvidedNextSigningSecret(ctx, syncCtx, secret, operatorConfig.ServiceAccountSigningKey.SecretName)
+	}
+
+	// Create or update the secret if it is missing, lacks the expected keypair data or
+	// still holds a user-provided keypair that is no longer referenced. The replaced
+	// keypair keeps signing tokens until the new public key is on all nodes.
 	needKeypair := secret == nil || len(secret.Data[PrivateKeyKey]) == 0 || len(secret.Data[PublicKeyKey]) == 0
+	if secret != nil && len(secret.Annotations[SigningKeySourceAnnotation]) > 0 {
+		klog.V(2).Infof("The signing secret for bound service account tokens of %s is no longer referenced by the operator config.", secret.Annotations[SigningKeySourceAnnotation])
+		needKeypair = true
+	}
+	rotationReason := ""
+	if !needKeypair && operatorConfig.ServiceAccountSigningKey == nil {
+		rotationReason, needKeypair, err = c.rotationDue(ctx, syncCtx, operatorConfig.ServiceAccountSigningKeyRotation, secret)
+		if err != nil {
+			return err
+		}
+	}
 	if needKeypair {
 		klog.V(2).Infof("Creating a new signing secret for bound service account tokens.")
-		newSecret, err := newNextSigningSecret()
+		newSecret, err := newNextSigningSecret(rotationReason, operatorConfig.ServiceAccountSigningKeyRotation.ForceRotationReason, c.now())
 		if err != nil {
 			return err
 		}
@@ -119,6 +163,87 @@ func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx conte
 	return nil
 }
 
+// rotationDue tells whether the generated keypair of the secret is to be rotated now.
+// A rotation is not started while the previous one is not complete, nor while it is
+// risky, the rotation is recorded in an event then and started once it is safe.
+func (c *BoundSATokenSignerController) rotationDue(ctx context.Context, syncCtx factory.SyncContext, config operatorconfig.ServiceAccountSigningKeyRotationConfig, secret *corev1.Secret) (string, bool, error) {
+	if errs := operatorconfig.ValidateServiceAccountSigningKeyRotation(config, field.NewPath("serviceAccountSigningKeyRotation")); len(errs) > 0 {
+		return "", false, fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+	reason, due := rotationDue(config, secret, c.now())
+	if !due {
+		return "", false, nil
+	}
+
+	operandSecret, err := c.secretClient.Secrets(targetNamespace).Get(ctx, SigningKeySecretName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", false, err
+	}
+	if operandSecret == nil || !bytes.Equal(operandSecret.Data[PrivateKeyKey], secret.Data[PrivateKeyKey]) {
+		klog.V(2).Infof("The rotation of the bound service account signing key (%s) waits for the previous keypair to sign tokens.", reason)
+		return "", false, nil
+	}
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return "", false, err
+	}
+	if risks := rotationRisks(operatorStatus); len(risks) > 0 {
+		syncCtx.Recorder().Warningf("BoundSATokenSignerRotationDeferred", "Deferring the rotation of the bound service account signing key (%s): %s", reason, strings.Join(risks, "; "))
+		return "", false, nil
+	}
+	cause := fmt.Sprintf("the keypair is older than %s", config.Interval)
+	if reason == ForceRotationReason {
+		cause = fmt.Sprintf("forceRotationReason %q", config.ForceRotationReason)
+	}
+	syncCtx.Recorder().Eventf("BoundSATokenSignerRotationStarted", "Rotating the bound service account signing key: %s", cause)
+	return reason, true, nil
+}
+
+// ensureUserProvidedNextSigningSecret ensures that the secret in the operator namespace
+// holds the keypair of the given secret in the openshift-config namespace. The keypair
+// is validated first, an invalid keypair keeps the current one.
+func (c *BoundSATokenSignerController) ensureUserProvidedNextSigningSecret(ctx context.Context, syncCtx factory.SyncContext, secret *corev1.Secret, secretName string) error {
+	source := operatorclient.GlobalUserSpecifiedConfigNamespace + "/" + secretName
+	userSecret, err := c.secretClient.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get the bound service account signing key %s: %v", source, err)
+	}
+	privateKey, publicKey := userSecret.Data[PrivateKeyKey], userSecret.Data[PublicKeyKey]
+	if err := ValidateKeyPair(privateKey, publicKey); err != nil {
+		syncCtx.Recorder().Warningf("BoundSATokenSigningKeyInvalid", "Invalid bound service account signing key %s: %v", source, err)
+		return fmt.Errorf("invalid bound service account signing key %s: %v", source, err)
+	}
+
+	upToDate := secret != nil &&
+		secret.Annotations[SigningKeySourceAnnotation] == source &&
+		bytes.Equal(secret.Data[PrivateKeyKey], privateKey) &&
+		bytes.Equal(secret.Data[PublicKeyKey], publicKey)
+	if upToDate {
+		return nil
+	}
+
+	klog.V(2).Infof("Updating the signing secret for bound service account tokens with the keypair of %s.", source)
+	_, _, err = resourceapply.ApplySecret(ctx, c.secretClient, syncCtx.Recorder(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorNamespace,
+			Name:      NextSigningKeySecretName,
+			Annotations: map[string]string{
+				SigningKeySourceAnnotation: source,
+				// the propagation delay of the keypair starts when its public key is on all nodes
+				PublicKeySyncedAnnotation + "-":          "",
+				SigningKeyCreatedAnnotation + "-":        "",
+				SigningKeyRotationReasonAnnotation + "-": "",
+				ForceRotationReasonAnnotation + "-":      "",
+			},
+		},
+		Data: map[string][]byte{
+			PrivateKeyKey: privateKey,
+			PublicKeyKey:  publicKey,
+		},
+	})
+	return err
+}
+
 // ensurePublicKeyConfigMap ensures that the public key in the operator secret is
 // present in the operand configmap. If the configmap is missing, it will be created
 // with the current public key. If the configmap exists but does not contain the
@@ -209,7 +334,7 @@ func (c *BoundSATokenSignerController) ensureOperandSigningSecret(ctx context.Co
 		bytes.Equal(operandSecret.Data[PublicKeyKey], operatorSecret.Data[PublicKeyKey]) &&
 		bytes.Equal(operandSecret.Data[PrivateKeyKey], operatorSecret.Data[PrivateKeyKey]))
 	if operandSecretUpToDate {
-		return nil
+		return c.updateRotationCondition(operatorv1.ConditionFalse, "AsExpected", "")
 	}
 
 	currPublicKey := string(operatorSecret.Data[PublicKeyKey])
@@ -235,7 +360,11 @@ func (c *BoundSATokenSignerController) ensureOperandSigningSecret(ctx context.Co
 	} else {
 		// Update the operand secret only if the current public key has been synced to
 		// all nodes.
-		syncAllowed, err = c.publicKeySyncedToAllNodes(ctx, currPublicKey)
+		synced, err := c.publicKeySyncedToAllNodes(ctx, currPublicKey)
+		if err != nil {
+			return err
+		}
+		syncAllowed, err = c.promotionAllowed(ctx, syncCtx, operatorSecret, synced)
 		if err != nil {
 			return err
 		}
@@ -251,7 +380,10 @@ func (c *BoundSATokenSignerController) ensureOperandSigningSecret(ctx context.Co
 	_, _, err = resourceapply.SyncSecret(ctx, c.secretClient, syncCtx.Recorder(),
 		operatorNamespace, NextSigningKeySecretName,
 		targetNamespace, SigningKeySecretName, []metav1.OwnerReference{})
-	return err
+	if err != nil {
+		return err
+	}
+	return c.updateRotationCondition(operatorv1.ConditionFalse, "AsExpected", "")
 }
 
 // publicKeySyncedToAllNodes indicates whether the given public key is present on the
@@ -291,8 +423,9 @@ func (c *BoundSATokenSignerController) publicKeySyncedToAllNodes(ctx context.Con
 	return true, nil
 }
 
-// newNextSigningSecret creates a new secret populated with a new keypair.
-func newNextSigningSecret() (*corev1.Secret, error) {
+// newNextSigningSecret creates a new secret populated with a new keypair. The reason of
+// a rotation and the force rotation reason in effect are recorded in annotations.
+func newNextSigningSecret(rotationReason, forceRotationReason string, now time.Time) (*corev1.Secret, error) {
 	rsaKey, err := rsa.GenerateKey(rand.Reader, keySize)
 	if err != nil {
 		return nil, err
@@ -305,10 +438,24 @@ func newNextSigningSecret() (*corev1.Secret, error) {
 	if err != nil {
 		return nil, err
 	}
+	annotations := map[string]string{
+		// removes the source of a previously referenced keypair
+		SigningKeySourceAnnotation + "-": "",
+		PublicKeySyncedAnnotation + "-":  "",
+		SigningKeyCreatedAnnotation:      now.UTC().Format(time.RFC3339),
+	}
+	for key, value := range map[string]string{SigningKeyRotationReasonAnnotation: rotationReason, ForceRotationReasonAnnotation: forceRotationReason} {
+		if len(value) > 0 {
+			annotations[key] = value
+		} else {
+			annotations[key+"-"] = ""
+		}
+	}
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: operatorNamespace,
-			Name:      NextSigningKeySecretName,
+			Namespace:   operatorNamespace,
+			Name:        NextSigningKeySecretName,
+			Annotations: annotations,
 		},
 		Data: map[string][]byte{
 			PrivateKeyKey: privateBytes,
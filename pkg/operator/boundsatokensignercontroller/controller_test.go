@@ -0,0 +1,147 @@
+package boundsatokensignercontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/keyutil"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func newKeyPairPEM(t *testing.T, bits int) (privateKeyPEM, publicKeyPEM []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyPEM, err = keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPEM, err = publicKeyToPem(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privateKeyPEM, publicKeyPEM
+}
+
+func TestValidateKeyPair(t *testing.T) {
+	privateKey, publicKey := newKeyPairPEM(t, keySize)
+	otherPrivateKey, otherPublicKey := newKeyPairPEM(t, keySize)
+	smallPrivateKey, smallPublicKey := newKeyPairPEM(t, 1024)
+
+	scenarios := []struct {
+		name          string
+		privateKey    []byte
+		publicKey     []byte
+		expectedError string
+	}{
+		{name: "matching", privateKey: privateKey, publicKey: publicKey},
+		{name: "previous public key of the issuer", privateKey: privateKey, publicKey: append(append([]byte{}, otherPublicKey...), publicKey...)},
+		{name: "no private key", publicKey: publicKey, expectedError: "the private key is missing"},
+		{name: "no public key", privateKey: privateKey, expectedError: "the public key is missing"},
+		{name: "garbage", privateKey: []byte("DUMMY DATA"), publicKey: publicKey, expectedError: "unable to parse the private key"},
+		{name: "mismatch", privateKey: privateKey, publicKey: otherPublicKey, expectedError: "the public key does not match the private key"},
+		{name: "too small", privateKey: smallPrivateKey, publicKey: smallPublicKey, expectedError: "at least 2048 bits"},
+		{name: "private key as public key", privateKey: otherPrivateKey, publicKey: otherPrivateKey, expectedError: "must not contain a private key"},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			err := ValidateKeyPair(scenario.privateKey, scenario.publicKey)
+			if len(scenario.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), scenario.expectedError) {
+				t.Fatalf("expected error %q, got %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestEnsureNextOperatorSigningSecretWithUserProvidedKey(t *testing.T) {
+	privateKey, publicKey := newKeyPairPEM(t, keySize)
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: "escrowed-key"},
+		Data:       map[string][]byte{PrivateKeyKey: privateKey, PublicKeyKey: publicKey},
+	})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c := &BoundSATokenSignerController{
+		secretClient:          kubeClient.CoreV1(),
+		configMapClient:       kubeClient.CoreV1(),
+		configConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+		now:                   time.Now,
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	setOperatorConfig := func(config string) {
+		if err := indexer.Add(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: operatorconfig.ConfigMapName},
+			Data:       map[string]string{operatorconfig.ConfigKey: config},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getNextSecret := func() *corev1.Secret {
+		secret, err := kubeClient.CoreV1().Secrets(operatorNamespace).Get(context.TODO(), NextSigningKeySecretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+
+	// the user-provided keypair replaces a generated one
+	if err := c.ensureNextOperatorSigningSecret(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	generated := getNextSecret()
+	setOperatorConfig("serviceAccountSigningKey:\n  secretName: escrowed-key\n")
+	if err := c.ensureNextOperatorSigningSecret(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	secret := getNextSecret()
+	if !bytes.Equal(secret.Data[PrivateKeyKey], privateKey) || !bytes.Equal(secret.Data[PublicKeyKey], publicKey) {
+		t.Errorf("expected the user-provided keypair")
+	}
+	if source := secret.Annotations[SigningKeySourceAnnotation]; source != "openshift-config/escrowed-key" {
+		t.Errorf("unexpected source %q", source)
+	}
+
+	// an invalid keypair keeps the current one
+	setOperatorConfig("serviceAccountSigningKey:\n  secretName: missing-key\n")
+	if err := c.ensureNextOperatorSigningSecret(context.TODO(), syncCtx); err == nil || !strings.Contains(err.Error(), "openshift-config/missing-key") {
+		t.Errorf("expected an error about the missing secret, got %v", err)
+	}
+	if secret := getNextSecret(); !bytes.Equal(secret.Data[PrivateKeyKey], privateKey) {
+		t.Errorf("expected the user-provided keypair to be kept")
+	}
+
+	// removing the reference rotates to a generated keypair
+	setOperatorConfig("")
+	if err := c.ensureNextOperatorSigningSecret(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	secret = getNextSecret()
+	if bytes.Equal(secret.Data[PrivateKeyKey], privateKey) || bytes.Equal(secret.Data[PrivateKeyKey], generated.Data[PrivateKeyKey]) {
+		t.Errorf("expected a newly generated keypair")
+	}
+	if _, ok := secret.Annotations[SigningKeySourceAnnotation]; ok {
+		t.Errorf("expected the source annotation to be removed, got %v", secret.Annotations)
+	}
+}
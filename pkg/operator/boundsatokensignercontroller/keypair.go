@@ -0,0 +1,59 @@
+package boundsatokensignercontroller
+
+import (
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/keyutil"
+)
+
+// ValidateKeyPair verifies that the PEM encoded private key is an RSA key of at least 2048 bits and that its public
+// key is one of the PEM encoded public keys. Further public keys are allowed, e.g. of a previous keypair of the same
+// issuer whose tokens are still accepted, but private keys are rejected because the public keys are distributed in
+// a configmap.
+func ValidateKeyPair(privateKeyPEM, publicKeysPEM []byte) error {
+	if len(privateKeyPEM) == 0 {
+		return fmt.Errorf("the private key is missing")
+	}
+	if len(publicKeysPEM) == 0 {
+		return fmt.Errorf("the public key is missing")
+	}
+
+	privateKey, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("unable to parse the private key: %v", err)
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("the private key must be an RSA key, got %T", privateKey)
+	}
+	if bits := rsaKey.N.BitLen(); bits < keySize {
+		return fmt.Errorf("the private key must have at least %d bits, got %d", keySize, bits)
+	}
+	if err := rsaKey.Validate(); err != nil {
+		return fmt.Errorf("invalid private key: %v", err)
+	}
+
+	for rest := publicKeysPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if strings.Contains(block.Type, "PRIVATE KEY") {
+			return fmt.Errorf("the public keys must not contain a private key")
+		}
+	}
+	publicKeys, err := keyutil.ParsePublicKeysPEM(publicKeysPEM)
+	if err != nil {
+		return fmt.Errorf("unable to parse the public key: %v", err)
+	}
+	for _, publicKey := range publicKeys {
+		if rsaKey.PublicKey.Equal(publicKey) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the public key does not match the private key")
+}
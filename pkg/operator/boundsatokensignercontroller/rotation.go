@@ -0,0 +1,175 @@
+package boundsatokensignercontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	// SigningKeyCreatedAnnotation is set on the signing secrets holding a generated keypair to the time it was
+	// generated, the interval of a rotation starts then.
+	SigningKeyCreatedAnnotation = "kubeapiserver.operator.openshift.io/signing-key-created"
+	// SigningKeyRotationReasonAnnotation is set on the signing secrets holding a keypair generated by a rotation, to
+	// IntervalRotationReason or ForceRotationReason.
+	SigningKeyRotationReasonAnnotation = "kubeapiserver.operator.openshift.io/signing-key-rotation-reason"
+	// ForceRotationReasonAnnotation is set on the signing secrets holding a generated keypair to the
+	// forceRotationReason of the operator config when it was generated, a different one rotates it.
+	ForceRotationReasonAnnotation = "kubeapiserver.operator.openshift.io/force-rotation-reason"
+	// PublicKeySyncedAnnotation is set on the next signing secret to the time its public key was found on all nodes,
+	// the propagation delay starts then.
+	PublicKeySyncedAnnotation = "kubeapiserver.operator.openshift.io/public-key-synced"
+
+	IntervalRotationReason = "Interval"
+	ForceRotationReason    = "ForceRotationReason"
+
+	// RotationProgressingConditionType is true while a new keypair is not signing tokens yet. The reason tells which
+	// step of the rotation it waits for.
+	RotationProgressingConditionType = "BoundSATokenSignerRotationProgressing"
+	RotationPublicKeyPendingReason   = "PublicKeyPending"
+	RotationPropagationDelayReason   = "PropagationDelay"
+	RotationHeldReason               = "RotationHeld"
+
+	// defaultRotationPropagationDelay covers the refresh of projected tokens, which the kubelet refreshes after 80% of
+	// their default lifetime of 1h, and the caches of the JWKS of typical token consumers.
+	defaultRotationPropagationDelay = time.Hour
+)
+
+// riskyConditionTypes are the operator conditions that hold the promotion of a new keypair while they are true. A
+// kube-apiserver that falls back or fails to install a revision could end up without the new public key and would
+// reject the tokens signed with the new private key.
+var riskyConditionTypes = []string{"RolledBack", "NodeInstallerDegraded", "StaticPodsDegraded"}
+
+// rotationDue returns the reason to rotate the generated keypair of the secret, if it is due. A changed
+// forceRotationReason takes precedence over the interval.
+func rotationDue(config operatorconfig.ServiceAccountSigningKeyRotationConfig, secret *corev1.Secret, now time.Time) (string, bool) {
+	if len(config.ForceRotationReason) > 0 && config.ForceRotationReason != secret.Annotations[ForceRotationReasonAnnotation] {
+		return ForceRotationReason, true
+	}
+	if len(config.Interval) == 0 {
+		return "", false
+	}
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil {
+		return "", false
+	}
+	created := secret.CreationTimestamp.Time
+	if t, err := time.Parse(time.RFC3339, secret.Annotations[SigningKeyCreatedAnnotation]); err == nil {
+		created = t
+	}
+	if now.Before(created.Add(interval)) {
+		return "", false
+	}
+	return IntervalRotationReason, true
+}
+
+// rotationRisks returns why replacing the signing keypair is not safe now: nodes that install a revision or failed
+// to, and the conditions of riskyConditionTypes that are true.
+func rotationRisks(status *operatorv1.StaticPodOperatorStatus) []string {
+	var risks []string
+	for _, node := range status.NodeStatuses {
+		switch {
+		case node.LastFailedRevision > node.CurrentRevision:
+			risks = append(risks, fmt.Sprintf("node %s failed to install revision %d", node.NodeName, node.LastFailedRevision))
+		case node.TargetRevision > node.CurrentRevision:
+			risks = append(risks, fmt.Sprintf("node %s is installing revision %d", node.NodeName, node.TargetRevision))
+		}
+	}
+	for _, conditionType := range riskyConditionTypes {
+		if cond := v1helpers.FindOperatorCondition(status.Conditions, conditionType); cond != nil && cond.Status == operatorv1.ConditionTrue {
+			risks = append(risks, fmt.Sprintf("%s is true: %s", conditionType, cond.Message))
+		}
+	}
+	return risks
+}
+
+// propagationDelay returns how long the public key of the keypair of the secret has to be on all nodes before the
+// keypair signs tokens.
+func propagationDelay(config operatorconfig.ServiceAccountSigningKeyRotationConfig, secret *corev1.Secret) time.Duration {
+	if delay, err := time.ParseDuration(config.PropagationDelay); err == nil {
+		return delay
+	}
+	if len(secret.Annotations[SigningKeyRotationReasonAnnotation]) > 0 {
+		return defaultRotationPropagationDelay
+	}
+	return 0
+}
+
+// promotionAllowed tells whether the keypair of the next signing secret, whose public key is on all nodes when
+// synced, may replace the keypair that signs tokens now. It records when the public key was first found on all nodes
+// and forgets it when the key is missing again, e.g. after a node fell back to an older revision, which restarts the
+// propagation delay. Risks hold the promotion without restarting it. The progress is reported in the
+// RotationProgressingConditionType condition.
+func (c *BoundSATokenSignerController) promotionAllowed(ctx context.Context, syncCtx factory.SyncContext, operatorSecret *corev1.Secret, synced bool) (bool, error) {
+	syncedSince, err := time.Parse(time.RFC3339, operatorSecret.Annotations[PublicKeySyncedAnnotation])
+	hasSyncedSince := err == nil
+
+	if !synced {
+		if hasSyncedSince {
+			syncCtx.Recorder().Warningf("BoundSATokenSignerRotationRestarted", "The public key of the new bound service account signing key is missing on a node again, restarting its propagation delay")
+			if err := c.setPublicKeySynced(ctx, operatorSecret, ""); err != nil {
+				return false, err
+			}
+		}
+		return false, c.updateRotationCondition(operatorv1.ConditionTrue, RotationPublicKeyPendingReason,
+			"The public key of the new bound service account signing key is being distributed to all nodes.")
+	}
+
+	now := c.now()
+	if !hasSyncedSince {
+		syncedSince = now
+		if err := c.setPublicKeySynced(ctx, operatorSecret, now.UTC().Format(time.RFC3339)); err != nil {
+			return false, err
+		}
+	}
+
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return false, err
+	}
+	if until := syncedSince.Add(propagationDelay(operatorConfig.ServiceAccountSigningKeyRotation, operatorSecret)); now.Before(until) {
+		return false, c.updateRotationCondition(operatorv1.ConditionTrue, RotationPropagationDelayReason,
+			fmt.Sprintf("The public key of the new bound service account signing key is on all nodes, it signs tokens after %s.", until.UTC().Format(time.RFC3339)))
+	}
+
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return false, err
+	}
+	if risks := rotationRisks(operatorStatus); len(risks) > 0 {
+		return false, c.updateRotationCondition(operatorv1.ConditionTrue, RotationHeldReason,
+			fmt.Sprintf("The new bound service account signing key is held back: %s", strings.Join(risks, "; ")))
+	}
+	return true, nil
+}
+
+// setPublicKeySynced sets or, when empty, removes the PublicKeySyncedAnnotation of the next signing secret.
+func (c *BoundSATokenSignerController) setPublicKeySynced(ctx context.Context, operatorSecret *corev1.Secret, value string) error {
+	secret := operatorSecret.DeepCopy()
+	if len(value) == 0 {
+		delete(secret.Annotations, PublicKeySyncedAnnotation)
+	} else {
+		metav1.SetMetaDataAnnotation(&secret.ObjectMeta, PublicKeySyncedAnnotation, value)
+	}
+	_, err := c.secretClient.Secrets(operatorNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *BoundSATokenSignerController) updateRotationCondition(status operatorv1.ConditionStatus, reason, message string) error {
+	_, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
+		Type:    RotationProgressingConditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}))
+	return err
+}
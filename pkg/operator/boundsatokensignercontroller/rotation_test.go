@@ -0,0 +1,246 @@
+package boundsatokensignercontroller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestRotationDue(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		SigningKeyCreatedAnnotation:   created.Format(time.RFC3339),
+		ForceRotationReasonAnnotation: "CHG-1",
+	}}}
+	tests := []struct {
+		name           string
+		config         operatorconfig.ServiceAccountSigningKeyRotationConfig
+		now            time.Time
+		expectedReason string
+	}{
+		{name: "not configured", now: created.Add(1000 * time.Hour)},
+		{name: "interval not elapsed", config: operatorconfig.ServiceAccountSigningKeyRotationConfig{Interval: "720h"}, now: created.Add(719 * time.Hour)},
+		{name: "interval elapsed", config: operatorconfig.ServiceAccountSigningKeyRotationConfig{Interval: "720h"}, now: created.Add(720 * time.Hour), expectedReason: IntervalRotationReason},
+		{name: "same force rotation reason", config: operatorconfig.ServiceAccountSigningKeyRotationConfig{ForceRotationReason: "CHG-1"}, now: created},
+		{name: "new force rotation reason", config: operatorconfig.ServiceAccountSigningKeyRotationConfig{Interval: "720h", ForceRotationReason: "CHG-2"}, now: created.Add(720 * time.Hour), expectedReason: ForceRotationReason},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, due := rotationDue(test.config, secret, test.now)
+			if reason != test.expectedReason || due != (len(test.expectedReason) > 0) {
+				t.Errorf("expected %q, got %q %v", test.expectedReason, reason, due)
+			}
+		})
+	}
+}
+
+func TestRotationRisks(t *testing.T) {
+	status := &operatorv1.StaticPodOperatorStatus{
+		OperatorStatus: operatorv1.OperatorStatus{Conditions: []operatorv1.OperatorCondition{
+			{Type: "RolledBack", Status: operatorv1.ConditionTrue, Message: "master-2 fell back to revision 6"},
+			{Type: "NodeInstallerDegraded", Status: operatorv1.ConditionFalse},
+		}},
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 7},
+			{NodeName: "master-1", CurrentRevision: 6, TargetRevision: 7},
+			{NodeName: "master-2", CurrentRevision: 6, LastFailedRevision: 7},
+		},
+	}
+	expected := []string{
+		"node master-1 is installing revision 7",
+		"node master-2 failed to install revision 7",
+		"RolledBack is true: master-2 fell back to revision 6",
+	}
+	if risks := rotationRisks(status); strings.Join(risks, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, got %v", expected, risks)
+	}
+	if risks := rotationRisks(&operatorv1.StaticPodOperatorStatus{NodeStatuses: status.NodeStatuses[:1]}); len(risks) != 0 {
+		t.Errorf("expected no risks, got %v", risks)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	privateKey, publicKey := newKeyPairPEM(t, keySize)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	signingSecret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: map[string]string{SigningKeyCreatedAnnotation: created.Format(time.RFC3339)}},
+			Data:       map[string][]byte{PrivateKeyKey: privateKey, PublicKeyKey: publicKey},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(
+		signingSecret(operatorNamespace, NextSigningKeySecretName),
+		signingSecret(targetNamespace, SigningKeySecretName),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: PublicKeyConfigMapName},
+			Data:       map[string]string{"service-account-001.pub": string(publicKey)},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: PublicKeyConfigMapName + "-3"},
+			Data:       map[string]string{"service-account-001.pub": string(publicKey)},
+		},
+	)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{
+		NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 3}, {NodeName: "master-1", CurrentRevision: 3}},
+	}, nil, nil)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: operatorconfig.ConfigMapName},
+		Data:       map[string]string{operatorconfig.ConfigKey: "serviceAccountSigningKeyRotation:\n  interval: 720h\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	now := created.Add(100 * time.Hour)
+	c := &BoundSATokenSignerController{
+		operatorClient:        operatorClient,
+		secretClient:          kubeClient.CoreV1(),
+		configMapClient:       kubeClient.CoreV1(),
+		configConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+		now:                   func() time.Time { return now },
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	sync := func() {
+		t.Helper()
+		if err := c.sync(context.TODO(), syncCtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getSecret := func(namespace, name string) *corev1.Secret {
+		t.Helper()
+		secret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	expectCondition := func(status operatorv1.ConditionStatus, reason string) {
+		t.Helper()
+		_, operatorStatus, _, err := operatorClient.GetStaticPodOperatorState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cond := v1helpers.FindOperatorCondition(operatorStatus.Conditions, RotationProgressingConditionType)
+		if cond == nil || cond.Status != status || cond.Reason != reason {
+			t.Errorf("expected %s %s, got %+v", status, reason, cond)
+		}
+	}
+	setStatus := func(status *operatorv1.StaticPodOperatorStatus) {
+		t.Helper()
+		if _, _, err := v1helpers.UpdateStaticPodStatus(operatorClient, func(current *operatorv1.StaticPodOperatorStatus) error {
+			current.NodeStatuses, current.Conditions = status.NodeStatuses, status.Conditions
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setRevisionPublicKeys := func(publicKeys ...[]byte) {
+		t.Helper()
+		data := map[string]string{}
+		for i, publicKey := range publicKeys {
+			data[PublicKeyConfigMapName+string(rune('a'+i))] = string(publicKey)
+		}
+		if _, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Update(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: PublicKeyConfigMapName + "-3"},
+			Data:       data,
+		}, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the keypair is not rotated before the interval elapsed
+	sync()
+	expectCondition(operatorv1.ConditionFalse, "AsExpected")
+	if !bytes.Equal(getSecret(operatorNamespace, NextSigningKeySecretName).Data[PrivateKeyKey], privateKey) {
+		t.Fatal("expected the keypair not to be rotated")
+	}
+
+	// a risk defers the rotation
+	now = created.Add(720 * time.Hour)
+	status := &operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{
+		{NodeName: "master-0", CurrentRevision: 3},
+		{NodeName: "master-1", CurrentRevision: 3, LastFailedRevision: 4},
+	}}
+	setStatus(status)
+	sync()
+	if !bytes.Equal(getSecret(operatorNamespace, NextSigningKeySecretName).Data[PrivateKeyKey], privateKey) {
+		t.Fatal("expected the rotation to be deferred")
+	}
+
+	// the new public key is distributed first
+	status.NodeStatuses[1].LastFailedRevision = 0
+	setStatus(status)
+	sync()
+	next := getSecret(operatorNamespace, NextSigningKeySecretName)
+	if bytes.Equal(next.Data[PrivateKeyKey], privateKey) || next.Annotations[SigningKeyRotationReasonAnnotation] != IntervalRotationReason ||
+		next.Annotations[SigningKeyCreatedAnnotation] != now.Format(time.RFC3339) {
+		t.Fatalf("expected a new keypair, got %v", next.Annotations)
+	}
+	if !bytes.Equal(getSecret(targetNamespace, SigningKeySecretName).Data[PrivateKeyKey], privateKey) {
+		t.Fatal("expected the previous keypair to keep signing")
+	}
+	expectCondition(operatorv1.ConditionTrue, RotationPublicKeyPendingReason)
+
+	// once it is on all nodes, the propagation delay starts
+	setRevisionPublicKeys(publicKey, next.Data[PublicKeyKey])
+	sync()
+	expectCondition(operatorv1.ConditionTrue, RotationPropagationDelayReason)
+	if synced := getSecret(operatorNamespace, NextSigningKeySecretName).Annotations[PublicKeySyncedAnnotation]; synced != now.Format(time.RFC3339) {
+		t.Errorf("expected the public key to be synced at %s, got %q", now.Format(time.RFC3339), synced)
+	}
+
+	// a node that loses the public key restarts it
+	now = now.Add(30 * time.Minute)
+	setRevisionPublicKeys(publicKey)
+	sync()
+	expectCondition(operatorv1.ConditionTrue, RotationPublicKeyPendingReason)
+	if synced, ok := getSecret(operatorNamespace, NextSigningKeySecretName).Annotations[PublicKeySyncedAnnotation]; ok {
+		t.Errorf("expected the synced time to be removed, got %q", synced)
+	}
+	setRevisionPublicKeys(publicKey, next.Data[PublicKeyKey])
+	sync()
+	now = now.Add(59 * time.Minute)
+	sync()
+	expectCondition(operatorv1.ConditionTrue, RotationPropagationDelayReason)
+
+	// risks hold the promotion after the delay
+	now = now.Add(time.Minute)
+	status.Conditions = []operatorv1.OperatorCondition{{Type: "StaticPodsDegraded", Status: operatorv1.ConditionTrue, Message: "pod crashlooping"}}
+	setStatus(status)
+	sync()
+	expectCondition(operatorv1.ConditionTrue, RotationHeldReason)
+	if !bytes.Equal(getSecret(targetNamespace, SigningKeySecretName).Data[PrivateKeyKey], privateKey) {
+		t.Fatal("expected the previous keypair to keep signing")
+	}
+
+	// and the new keypair signs tokens once they are gone
+	status.Conditions = nil
+	setStatus(status)
+	sync()
+	expectCondition(operatorv1.ConditionFalse, "AsExpected")
+	if !bytes.Equal(getSecret(targetNamespace, SigningKeySecretName).Data[PrivateKeyKey], next.Data[PrivateKeyKey]) {
+		t.Fatal("expected the new keypair to sign tokens")
+	}
+	configMap, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.TODO(), PublicKeyConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMap.Data) != 2 {
+		t.Errorf("expected the previous public key to stay accepted, got %v", configMap.Data)
+	}
+}
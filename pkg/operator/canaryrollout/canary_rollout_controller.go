@@ -0,0 +1,281 @@
+package canaryrollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/terminationobserver"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace that records the last verified revision. The installer
+	// pods of the nodes after the canary wait for it.
+	ConfigMapName       = "canary-rollout"
+	VerifiedRevisionKey = "verifiedRevision"
+
+	CanaryRolloutProgressingConditionType = "CanaryRolloutProgressing"
+	CanaryRolloutDegradedConditionType    = "CanaryRolloutDegraded"
+
+	defaultSoakPeriod = 10 * time.Minute
+)
+
+// canaryState is the health of the kube-apiserver of the canary node.
+type canaryState struct {
+	verified bool
+	// failure is set when the canary is not healthy, the rollout stops
+	failure string
+	// progress describes what the canary waits for
+	progress string
+}
+
+// CanaryRolloutController verifies a new revision on the first node it is installed on, the canary, when the rollout
+// strategy of the operator config is Canary. Once the kube-apiserver of the canary stayed ready without restarts and
+// disruptive terminations for the soak period, the revision is recorded as verified and the installer pods of the
+// other nodes, which wait for it, continue. Error rates of the canary are not checked, the operator has no per node
+// source for them.
+type CanaryRolloutController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	podLister       corev1listers.PodLister
+	eventLister     corev1listers.EventLister
+	configMapLister corev1listers.ConfigMapLister
+	configMapClient coreclientv1.ConfigMapsGetter
+
+	now func() time.Time
+}
+
+func NewCanaryRolloutController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &CanaryRolloutController{
+		operatorClient:  operatorClient,
+		podLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		eventLister:     kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Events().Lister(),
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		configMapClient: configMapClient,
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Events().Informer(),
+	).WithSync(syncmetrics.Instrument("CanaryRolloutController", c.sync)).ResyncEvery(resyncinterval.For("CanaryRolloutController", 30*time.Second)).ToController("CanaryRolloutController", eventRecorder.WithComponentSuffix("canary-rollout-controller"))
+}
+
+func (c *CanaryRolloutController) sync(ctx context.Context, syncCtx factory.SyncContext) (err error) {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	progressing := operatorv1.OperatorCondition{Type: CanaryRolloutProgressingConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+	degraded := operatorv1.OperatorCondition{Type: CanaryRolloutDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+	defer func() {
+		if _, _, updateErr := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(progressing), v1helpers.UpdateConditionFn(degraded)); updateErr != nil {
+			err = updateErr
+		}
+	}()
+
+	revision := status.LatestAvailableRevision
+	if rollout.Strategy != operatorconfig.RolloutCanary || revision == 0 {
+		return nil
+	}
+	verifiedRevision, err := c.verifiedRevision()
+	if err != nil {
+		return err
+	}
+	if verifiedRevision >= revision {
+		return nil
+	}
+
+	canaryNode := canaryNodeName(status.NodeStatuses, revision)
+	if len(canaryNode) == 0 {
+		progressing.Status, progressing.Reason = operatorv1.ConditionTrue, "InstallingCanary"
+		progressing.Message = fmt.Sprintf("revision %d is installed on the canary node first", revision)
+		return nil
+	}
+
+	pod, err := c.podLister.Pods(operatorclient.TargetNamespace).Get(fmt.Sprintf("kube-apiserver-%s", canaryNode))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	soakPeriod := defaultSoakPeriod
+	if len(rollout.CanarySoakPeriod) > 0 {
+		if soakPeriod, err = time.ParseDuration(rollout.CanarySoakPeriod); err != nil {
+			return err
+		}
+	}
+
+	podEvents, err := c.eventLister.Events(operatorclient.TargetNamespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	state := newCanaryState(pod, podEvents, revision, soakPeriod, c.now())
+	switch {
+	case len(state.failure) > 0:
+		degraded.Status, degraded.Reason = operatorv1.ConditionTrue, "CanaryUnhealthy"
+		degraded.Message = fmt.Sprintf("revision %d is not rolled out to the other nodes, the kube-apiserver on the canary node %s %s", revision, canaryNode, state.failure)
+		progressing.Status, progressing.Reason = operatorv1.ConditionTrue, "CanaryUnhealthy"
+		progressing.Message = degraded.Message
+		return nil
+	case !state.verified:
+		progressing.Status, progressing.Reason = operatorv1.ConditionTrue, "VerifyingCanary"
+		progressing.Message = fmt.Sprintf("verifying revision %d on the canary node %s: %s", revision, canaryNode, state.progress)
+		return nil
+	}
+
+	klog.Infof("Revision %d is verified on the canary node %s", revision, canaryNode)
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       map[string]string{VerifiedRevisionKey: strconv.Itoa(int(revision))},
+	})
+	if err == nil {
+		syncCtx.Recorder().Eventf("CanaryRevisionVerified", "revision %d is verified on the canary node %s, rolling out to the other nodes", revision, canaryNode)
+	}
+	return err
+}
+
+func (c *CanaryRolloutController) verifiedRevision() (int32, error) {
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(ConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	revision, err := strconv.Atoi(configMap.Data[VerifiedRevisionKey])
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s in configmap %s/%s: %v", VerifiedRevisionKey, operatorclient.TargetNamespace, ConfigMapName, err)
+	}
+	return int32(revision), nil
+}
+
+// canaryNodeName returns the first node that runs the revision, the other nodes wait for it to be verified. The
+// installer leaves the current revision of a node at the previous one when the startup monitor falls back or the
+// kube-apiserver never becomes ready, the canary is then the node that failed or still installs the revision.
+func canaryNodeName(nodeStatuses []operatorv1.NodeStatus, revision int32) string {
+	for _, isCanary := range []func(operatorv1.NodeStatus) bool{
+		func(nodeStatus operatorv1.NodeStatus) bool { return nodeStatus.CurrentRevision == revision },
+		func(nodeStatus operatorv1.NodeStatus) bool { return nodeStatus.LastFailedRevision == revision },
+		func(nodeStatus operatorv1.NodeStatus) bool { return nodeStatus.TargetRevision == revision },
+	} {
+		for _, nodeStatus := range nodeStatuses {
+			if isCanary(nodeStatus) {
+				return nodeStatus.NodeName
+			}
+		}
+	}
+	return ""
+}
+
+// newCanaryState checks the mirror pod of the kube-apiserver on the canary node. The kube-apiserver has to be ready,
+// its readiness probe checks /readyz, for the whole soak period and must not restart. A fallback of the startup
+// monitor to the previous revision fails the canary too, as does a non-graceful termination or late connections
+// reported for the pod after it became ready.
+func newCanaryState(pod *corev1.Pod, podEvents []*corev1.Event, revision int32, soakPeriod time.Duration, now time.Time) canaryState {
+	if pod == nil {
+		return canaryState{progress: "waiting for the kube-apiserver pod"}
+	}
+	if failedRevision := pod.Annotations["startup-monitor.static-pods.openshift.io/fallback-for-revision"]; failedRevision == strconv.Itoa(int(revision)) {
+		return canaryState{failure: fmt.Sprintf("fell back to revision %s", pod.Labels["revision"])}
+	}
+	if pod.Labels["revision"] != strconv.Itoa(int(revision)) {
+		return canaryState{progress: fmt.Sprintf("waiting for the kube-apiserver pod of revision %d", revision)}
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == "kube-apiserver" && containerStatus.RestartCount > 0 {
+			return canaryState{failure: fmt.Sprintf("restarted %d times", containerStatus.RestartCount)}
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.PodReady {
+			continue
+		}
+		since := now.Sub(cond.LastTransitionTime.Time)
+		if cond.Status != corev1.ConditionTrue {
+			if since > soakPeriod {
+				return canaryState{failure: fmt.Sprintf("has not been ready for %v", since.Round(time.Second))}
+			}
+			return canaryState{progress: "waiting for the kube-apiserver to become ready"}
+		}
+		if reason := disruptiveTermination(pod.Name, podEvents, cond.LastTransitionTime.Time); len(reason) > 0 {
+			return canaryState{failure: fmt.Sprintf("reported %s", reason)}
+		}
+		if since < soakPeriod {
+			return canaryState{progress: fmt.Sprintf("ready for %v of %v", since.Round(time.Second), soakPeriod)}
+		}
+		return canaryState{verified: true}
+	}
+	return canaryState{progress: "waiting for the kube-apiserver to become ready"}
+}
+
+// disruptiveTermination returns the reason of a non-graceful termination or late connections event of the pod after
+// since, the kube-apiserver records them when it ends without finishing its graceful termination.
+func disruptiveTermination(podName string, podEvents []*corev1.Event, since time.Time) string {
+	for _, event := range podEvents {
+		if event.InvolvedObject.Name != podName {
+			continue
+		}
+		if event.Reason != terminationobserver.NonGracefulTerminationReason && event.Reason != terminationobserver.LateConnectionsReason {
+			continue
+		}
+		if event.LastTimestamp.Time.After(since) {
+			return event.Reason
+		}
+	}
+	return ""
+}
+
+// RolloutFromSpec returns the rollout config observed from the operator config.
+func RolloutFromSpec(spec *operatorv1.StaticPodOperatorSpec) (operatorconfig.RolloutConfig, error) {
+	rollout := operatorconfig.RolloutConfig{}
+	if len(spec.ObservedConfig.Raw) == 0 {
+		return rollout, nil
+	}
+	observedConfig := map[string]interface{}{}
+	if err := json.Unmarshal(spec.ObservedConfig.Raw, &observedConfig); err != nil {
+		return rollout, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
+	}
+	observedRollout, found, err := unstructured.NestedMap(observedConfig, "rollout")
+	if err != nil || !found {
+		return rollout, err
+	}
+	raw, err := json.Marshal(observedRollout)
+	if err != nil {
+		return rollout, err
+	}
+	if err := json.Unmarshal(raw, &rollout); err != nil {
+		return rollout, fmt.Errorf("incorrect value of rollout in the observed config: %v", err)
+	}
+	return rollout, nil
+}
@@ -0,0 +1,176 @@
+package canaryrollout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewCanaryState(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	pod := func(revision string, ready corev1.ConditionStatus, since time.Duration, restarts int32, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "kube-apiserver-master-0",
+				Labels:      map[string]string{"apiserver": "true", "revision": revision},
+				Annotations: annotations,
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             ready,
+					LastTransitionTime: metav1.NewTime(now.Add(-since)),
+				}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "kube-apiserver", RestartCount: restarts}},
+			},
+		}
+	}
+	event := func(reason string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "kube-apiserver-master-0"},
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+
+	tests := []struct {
+		name            string
+		pod             *corev1.Pod
+		events          []*corev1.Event
+		expectVerified  bool
+		expectedFailure string
+	}{
+		{
+			name: "no pod",
+		},
+		{
+			name: "old revision",
+			pod:  pod("4", corev1.ConditionTrue, time.Hour, 0, nil),
+		},
+		{
+			name: "soaking",
+			pod:  pod("5", corev1.ConditionTrue, 5*time.Minute, 0, nil),
+		},
+		{
+			name:           "soaked",
+			pod:            pod("5", corev1.ConditionTrue, 10*time.Minute, 0, nil),
+			expectVerified: true,
+		},
+		{
+			name: "starting",
+			pod:  pod("5", corev1.ConditionFalse, time.Minute, 0, nil),
+		},
+		{
+			name:            "never ready",
+			pod:             pod("5", corev1.ConditionFalse, 11*time.Minute, 0, nil),
+			expectedFailure: "has not been ready for 11m0s",
+		},
+		{
+			name:            "restarted",
+			pod:             pod("5", corev1.ConditionTrue, time.Hour, 2, nil),
+			expectedFailure: "restarted 2 times",
+		},
+		{
+			name:            "fell back",
+			pod:             pod("4", corev1.ConditionTrue, time.Hour, 0, map[string]string{"startup-monitor.static-pods.openshift.io/fallback-for-revision": "5"}),
+			expectedFailure: "fell back to revision 4",
+		},
+		{
+			name:            "non-graceful termination",
+			pod:             pod("5", corev1.ConditionTrue, 5*time.Minute, 0, nil),
+			events:          []*corev1.Event{event("NonGracefulTermination", now.Add(-time.Minute))},
+			expectedFailure: "reported NonGracefulTermination",
+		},
+		{
+			name:           "non-graceful termination of the previous revision",
+			pod:            pod("5", corev1.ConditionTrue, 10*time.Minute, 0, nil),
+			events:         []*corev1.Event{event("NonGracefulTermination", now.Add(-11*time.Minute))},
+			expectVerified: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := newCanaryState(test.pod, test.events, 5, 10*time.Minute, now)
+			if state.verified != test.expectVerified {
+				t.Errorf("expected verified %v, got %#v", test.expectVerified, state)
+			}
+			if state.failure != test.expectedFailure {
+				t.Errorf("expected failure %q, got %q", test.expectedFailure, state.failure)
+			}
+			if !state.verified && len(state.failure) == 0 && len(state.progress) == 0 {
+				t.Errorf("expected the progress to be described")
+			}
+		})
+	}
+}
+
+func TestSyncFallback(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	indexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	podIndexer := indexer()
+	// the startup monitor fell back to revision 4 on the canary, the installer keeps its current revision
+	if err := podIndexer.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "openshift-kube-apiserver",
+			Name:        "kube-apiserver-master-1",
+			Labels:      map[string]string{"apiserver": "true", "revision": "4"},
+			Annotations: map[string]string{"startup-monitor.static-pods.openshift.io/fallback-for-revision": "5"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+			ManagementState: operatorv1.Managed,
+			ObservedConfig:  runtime.RawExtension{Raw: []byte(`{"rollout":{"strategy":"Canary"}}`)},
+		}},
+		&operatorv1.StaticPodOperatorStatus{
+			LatestAvailableRevision: 5,
+			NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 4},
+				{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: "OperandFailedFallback"},
+				{NodeName: "master-2", CurrentRevision: 4},
+			},
+		},
+		nil,
+		nil,
+	)
+	c := &CanaryRolloutController{
+		operatorClient:  operatorClient,
+		podLister:       corev1listers.NewPodLister(podIndexer),
+		eventLister:     corev1listers.NewEventLister(indexer()),
+		configMapLister: corev1listers.NewConfigMapLister(indexer()),
+		configMapClient: fake.NewSimpleClientset().CoreV1(),
+		now:             func() time.Time { return now },
+	}
+
+	if err := c.sync(context.TODO(), factory.NewSyncContext("CanaryRolloutController", events.NewInMemoryRecorder(t.Name()))); err != nil {
+		t.Fatal(err)
+	}
+
+	_, status, _, err := operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	degraded := v1helpers.FindOperatorCondition(status.Conditions, CanaryRolloutDegradedConditionType)
+	if degraded == nil || degraded.Status != operatorv1.ConditionTrue {
+		t.Fatalf("expected %s=True, got %v", CanaryRolloutDegradedConditionType, degraded)
+	}
+	if expected := "revision 5 is not rolled out to the other nodes, the kube-apiserver on the canary node master-1 fell back to revision 4"; degraded.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, degraded.Message)
+	}
+}
@@ -0,0 +1,56 @@
+package canaryrollout
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// WaitForCanary returns an installer pod mutation that holds back the installer pods of the nodes after the canary
+// until the revision is verified. An init container waits for the revision in the canary-rollout configmap, the
+// installer controller keeps waiting for the pending installer pod meanwhile. A newer revision replaces the waiting
+// installer pod.
+func WaitForCanary(operatorClient v1helpers.StaticPodOperatorClient) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if rollout.Strategy != operatorconfig.RolloutCanary {
+			return nil
+		}
+		_, status, _, err := operatorClient.GetStaticPodOperatorState()
+		if err != nil {
+			return err
+		}
+		if !waitsForCanary(status.NodeStatuses, nodeName, revision) {
+			return nil
+		}
+		addWaitForCanaryContainer(pod, revision)
+		return nil
+	}
+}
+
+// waitsForCanary returns true if another node already runs the revision, the node is not the canary then.
+func waitsForCanary(nodeStatuses []operatorv1.NodeStatus, nodeName string, revision int32) bool {
+	for _, nodeStatus := range nodeStatuses {
+		if nodeStatus.NodeName != nodeName && nodeStatus.CurrentRevision >= revision {
+			return true
+		}
+	}
+	return false
+}
+
+func addWaitForCanaryContainer(pod *corev1.Pod, revision int32) {
+	installergate.AddWaitContainer(pod, "wait-for-canary",
+		fmt.Sprintf("--revision=%d", revision),
+		fmt.Sprintf("--namespace=%s", operatorclient.TargetNamespace),
+		fmt.Sprintf("--configmap=%s", ConfigMapName),
+	)
+}
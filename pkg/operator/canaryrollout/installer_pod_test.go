@@ -0,0 +1,66 @@
+package canaryrollout
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitForCanary(t *testing.T) {
+	nodeStatuses := []operatorv1.NodeStatus{
+		{NodeName: "master-0", CurrentRevision: 5},
+		{NodeName: "master-1", CurrentRevision: 4},
+		{NodeName: "master-2", CurrentRevision: 4},
+	}
+	canarySpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"rollout":{"strategy":"Canary"}}`)},
+	}}
+
+	tests := []struct {
+		name         string
+		operatorSpec *operatorv1.StaticPodOperatorSpec
+		nodeName     string
+		revision     int32
+		expectWait   bool
+	}{
+		{name: "serial", operatorSpec: &operatorv1.StaticPodOperatorSpec{}, nodeName: "master-1", revision: 5},
+		{name: "canary node", operatorSpec: canarySpec, nodeName: "master-0", revision: 6},
+		{name: "node after the canary", operatorSpec: canarySpec, nodeName: "master-1", revision: 5, expectWait: true},
+		{name: "retried canary", operatorSpec: canarySpec, nodeName: "master-0", revision: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClient(test.operatorSpec, &operatorv1.StaticPodOperatorStatus{NodeStatuses: nodeStatuses}, nil, nil)
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:         "installer",
+				Image:        "quay.io/openshift/cluster-kube-apiserver-operator",
+				VolumeMounts: []corev1.VolumeMount{{Name: "kubelet-dir"}, {Name: "kube-api-access", MountPath: "/var/run/secrets/kubernetes.io/serviceaccount"}},
+			}}}}
+
+			if err := WaitForCanary(operatorClient)(pod, test.nodeName, test.operatorSpec, test.revision); err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.expectWait {
+				if len(pod.Spec.InitContainers) > 0 {
+					t.Fatalf("expected the installer not to wait, got %v", pod.Spec.InitContainers)
+				}
+				return
+			}
+			if len(pod.Spec.InitContainers) != 1 {
+				t.Fatalf("expected one init container, got %v", pod.Spec.InitContainers)
+			}
+			container := pod.Spec.InitContainers[0]
+			if container.Image != pod.Spec.Containers[0].Image || container.Args[0] != "--revision=5" {
+				t.Errorf("unexpected init container %v", container)
+			}
+			if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "kube-api-access" {
+				t.Errorf("expected only the service account token to be mounted, got %v", container.VolumeMounts)
+			}
+		})
+	}
+}
@@ -28,7 +28,7 @@ import (
 const defaultRotationDay = 24 * time.Hour
 
 type CertRotationController struct {
-	certRotators []factory.Controller
+	certRotators []certRotator
 
 	networkLister        configlisterv1.NetworkLister
 	infrastructureLister configlisterv1.InfrastructureLister
@@ -128,7 +128,7 @@ func newCertRotationController(
 		rotationDay = rotationDay / 60
 	}
 
-	certRotator := certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"AggregatorProxyClientCert",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -166,9 +166,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"KubeAPIServerToKubeletClientCert",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -206,9 +204,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"LocalhostServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -246,9 +242,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"ServiceNetworkServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -287,9 +281,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"ExternalLoadBalancerServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -328,9 +320,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"InternalLoadBalancerServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -369,9 +359,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"LocalhostRecoveryServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace:     operatorclient.OperatorNamespace,
@@ -407,9 +395,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"KubeControllerManagerClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -447,9 +433,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"KubeSchedulerClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -487,9 +471,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"ControlPlaneNodeAdminClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -527,9 +509,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"CheckEndpointsClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -567,9 +547,7 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	certRotator = certrotation.NewCertRotationController(
+	ret.addCertRotator(
 		"NodeSystemAdminClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace:              operatorclient.OperatorNamespace,
@@ -614,11 +592,40 @@ func newCertRotationController(
 		operatorClient,
 		eventRecorder,
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
 	return ret, nil
 }
 
+// certRotator is a cert rotation controller with the signer and the target cert it manages.
+type certRotator struct {
+	factory.Controller
+
+	name   string
+	signer certrotation.RotatedSigningCASecret
+	target certrotation.RotatedSelfSignedCertKeySecret
+}
+
+func (c *CertRotationController) addCertRotator(
+	name string,
+	signer certrotation.RotatedSigningCASecret,
+	caBundle certrotation.CABundleConfigMap,
+	target certrotation.RotatedSelfSignedCertKeySecret,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	eventRecorder events.Recorder,
+) {
+	c.certRotators = append(c.certRotators, certRotator{
+		Controller: certrotation.NewCertRotationController(name, signer, caBundle, target, operatorClient, eventRecorder),
+		name:       name,
+		signer:     signer,
+		target:     target,
+	})
+	// RunOnce syncs the cert rotators without running their informers' controllers
+	c.cachesToSync = append(c.cachesToSync,
+		signer.Informer.Informer().HasSynced,
+		caBundle.Informer.Informer().HasSynced,
+		target.Informer.Informer().HasSynced,
+	)
+}
+
 func (c *CertRotationController) WaitForReady(stopCh <-chan struct{}) {
 	klog.Infof("Waiting for CertRotation")
 	defer klog.Infof("Finished waiting for CertRotation")
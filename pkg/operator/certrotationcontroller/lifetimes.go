@@ -0,0 +1,90 @@
+package certrotationcontroller
+
+import (
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// CertLifetime is how long a certificate of a cert rotator is valid and when it is refreshed.
+type CertLifetime struct {
+	// Controller is the name of the cert rotator.
+	Controller string `json:"controller"`
+	// Secret is the namespace/name of the secret of the certificate.
+	Secret string `json:"secret"`
+	// Signer is whether the certificate is the signer of the cert rotator, otherwise it is its target cert.
+	Signer bool `json:"signer"`
+	// Validity is how long a new certificate is valid.
+	Validity string `json:"validity"`
+	// Refresh is how old a certificate gets before it is refreshed.
+	Refresh string `json:"refresh"`
+	// RefreshOnlyWhenExpired is whether the certificate is only refreshed once it expired.
+	RefreshOnlyWhenExpired bool `json:"refreshOnlyWhenExpired,omitempty"`
+}
+
+// Lifetimes returns the lifetimes of the signers and target certs of the cert rotators, a signer shared by several cert
+// rotators once.
+func (c *CertRotationController) Lifetimes() []CertLifetime {
+	var ret []CertLifetime
+	seen := map[string]bool{}
+	for _, r := range c.certRotators {
+		if signer := r.signer.Namespace + "/" + r.signer.Name; !seen[signer] {
+			seen[signer] = true
+			ret = append(ret, CertLifetime{
+				Controller:             r.name,
+				Secret:                 signer,
+				Signer:                 true,
+				Validity:               r.signer.Validity.String(),
+				Refresh:                r.signer.Refresh.String(),
+				RefreshOnlyWhenExpired: r.signer.RefreshOnlyWhenExpired,
+			})
+		}
+		ret = append(ret, CertLifetime{
+			Controller:             r.name,
+			Secret:                 r.target.Namespace + "/" + r.target.Name,
+			Validity:               r.target.Validity.String(),
+			Refresh:                r.target.Refresh.String(),
+			RefreshOnlyWhenExpired: r.target.RefreshOnlyWhenExpired,
+		})
+	}
+	return ret
+}
+
+// DefaultLifetimes returns the lifetimes of the certificates the operator rotates without an unsupported rotation base.
+// The cert rotators are built like the operator builds them, with clients and informers that are never used.
+func DefaultLifetimes() ([]CertLifetime, error) {
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{})
+	if err != nil {
+		return nil, err
+	}
+	configClient, err := configclient.NewForConfig(&rest.Config{})
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCertRotationController(
+		kubeClient,
+		nil,
+		configinformers.NewSharedInformerFactory(configClient, 0),
+		v1helpers.NewKubeInformersForNamespaces(
+			kubeClient,
+			operatorclient.GlobalMachineSpecifiedConfigNamespace,
+			operatorclient.GlobalUserSpecifiedConfigNamespace,
+			operatorclient.OperatorNamespace,
+			operatorclient.TargetNamespace,
+		),
+		events.NewInMemoryRecorder("lifetimes"),
+		time.Duration(0),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c.Lifetimes(), nil
+}
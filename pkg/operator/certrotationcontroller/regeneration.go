@@ -0,0 +1,118 @@
+package certrotationcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+// Regeneration is a certificate a cert rotator regenerates.
+type Regeneration struct {
+	// Controller is the name of the cert rotator.
+	Controller string `json:"controller"`
+	// Secret is the namespace/name of the secret of the certificate.
+	Secret string `json:"secret"`
+	// Reason is why the certificate is regenerated.
+	Reason string `json:"reason"`
+}
+
+// Names returns the names the cert rotators can be selected by: the name of the cert rotator, of its signer and of its
+// target cert secret.
+func (c *CertRotationController) Names() []string {
+	names := sets.NewString()
+	for _, r := range c.certRotators {
+		names.Insert(r.name, r.signer.Name, r.target.Name)
+	}
+	return names.List()
+}
+
+// Select keeps the cert rotators that match any of the names, by the name of the cert rotator, of its signer or of its
+// target cert secret. A signer name selects every cert rotator of that signer. It must be called before the controller
+// runs. It returns an error for a name that matches no cert rotator.
+func (c *CertRotationController) Select(names []string) error {
+	wanted := sets.NewString(names...)
+	unmatched := sets.NewString(names...)
+	var selected []certRotator
+	for _, r := range c.certRotators {
+		matched := false
+		for _, name := range []string{r.name, r.signer.Name, r.target.Name} {
+			if wanted.Has(name) {
+				unmatched.Delete(name)
+				matched = true
+			}
+		}
+		if matched {
+			selected = append(selected, r)
+		}
+	}
+	if unmatched.Len() > 0 {
+		return fmt.Errorf("unknown certificates %s, known are %s", strings.Join(unmatched.List(), ","), strings.Join(c.Names(), ","))
+	}
+	c.certRotators = selected
+	return nil
+}
+
+// Plan returns the certificates the cert rotators regenerate when they only refresh expired certificates: the
+// signers and target certs that are missing or expired at the given time, and the target certs of a regenerated
+// signer. It reads the secrets from the listers of the cert rotators, i.e. after WaitForReady.
+func (c *CertRotationController) Plan(now time.Time) ([]Regeneration, error) {
+	var ret []Regeneration
+	for _, r := range c.certRotators {
+		signerReason, err := expiredReason(r.signer.Lister, r.signer.Namespace, r.signer.Name, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(signerReason) > 0 {
+			ret = append(ret, Regeneration{Controller: r.name, Secret: r.signer.Namespace + "/" + r.signer.Name, Reason: signerReason})
+		}
+		targetReason, err := expiredReason(r.target.Lister, r.target.Namespace, r.target.Name, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(targetReason) == 0 && len(signerReason) > 0 {
+			targetReason = fmt.Sprintf("signer %s/%s is regenerated", r.signer.Namespace, r.signer.Name)
+		}
+		if len(targetReason) > 0 {
+			ret = append(ret, Regeneration{Controller: r.name, Secret: r.target.Namespace + "/" + r.target.Name, Reason: targetReason})
+		}
+	}
+
+	// signers shared by several cert rotators are listed once
+	seen := sets.NewString()
+	deduplicated := ret[:0]
+	for _, regeneration := range ret {
+		if seen.Has(regeneration.Secret) {
+			continue
+		}
+		seen.Insert(regeneration.Secret)
+		deduplicated = append(deduplicated, regeneration)
+	}
+	sort.SliceStable(deduplicated, func(i, j int) bool { return deduplicated[i].Secret < deduplicated[j].Secret })
+	return deduplicated, nil
+}
+
+// expiredReason returns why the certificate of the secret is regenerated, or an empty string if it is not.
+func expiredReason(lister corev1listers.SecretLister, namespace, name string, now time.Time) (string, error) {
+	secret, err := lister.Secrets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return "missing", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	notAfter, err := time.Parse(time.RFC3339, secret.Annotations[certrotation.CertificateNotAfterAnnotation])
+	if err != nil {
+		return fmt.Sprintf("missing or invalid %s annotation", certrotation.CertificateNotAfterAnnotation), nil
+	}
+	if !now.Before(notAfter) {
+		return fmt.Sprintf("expired at %s", notAfter.Format(time.RFC3339)), nil
+	}
+	return "", nil
+}
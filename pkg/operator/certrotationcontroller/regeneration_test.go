@@ -0,0 +1,85 @@
+package certrotationcontroller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+)
+
+func TestSelectAndPlan(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for name, notAfter := range map[string]time.Time{
+		"signer":       now.Add(time.Hour),
+		"client":       now.Add(-time.Hour),
+		"other-signer": now.Add(-time.Hour),
+		"other-client": now.Add(time.Hour),
+	} {
+		if err := indexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        name,
+			Annotations: map[string]string{certrotation.CertificateNotAfterAnnotation: notAfter.Format(time.RFC3339)},
+		}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := corev1listers.NewSecretLister(indexer)
+	newRotator := func(name, signer, target string) certRotator {
+		return certRotator{
+			name:   name,
+			signer: certrotation.RotatedSigningCASecret{Namespace: "ns", Name: signer, Lister: lister},
+			target: certrotation.RotatedSelfSignedCertKeySecret{Namespace: "ns", Name: target, Lister: lister},
+		}
+	}
+	newController := func() *CertRotationController {
+		return &CertRotationController{certRotators: []certRotator{
+			newRotator("ClientCert", "signer", "client"),
+			newRotator("OtherClientCert", "other-signer", "other-client"),
+			newRotator("MissingClientCert", "other-signer", "missing-client"),
+		}}
+	}
+
+	c := newController()
+	regenerations, err := c.Plan(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Regeneration{
+		{Controller: "ClientCert", Secret: "ns/client", Reason: "expired at 2021-06-01T11:00:00Z"},
+		{Controller: "MissingClientCert", Secret: "ns/missing-client", Reason: "missing"},
+		{Controller: "OtherClientCert", Secret: "ns/other-client", Reason: "signer ns/other-signer is regenerated"},
+		{Controller: "OtherClientCert", Secret: "ns/other-signer", Reason: "expired at 2021-06-01T11:00:00Z"},
+	}
+	if !reflect.DeepEqual(regenerations, expected) {
+		t.Errorf("unexpected plan:\n%+v\nexpected:\n%+v", regenerations, expected)
+	}
+
+	c = newController()
+	if err := c.Select([]string{"client"}); err != nil {
+		t.Fatal(err)
+	}
+	if regenerations, err := c.Plan(now); err != nil || len(regenerations) != 1 || regenerations[0].Secret != "ns/client" {
+		t.Errorf("unexpected plan of the selected client: %+v, %v", regenerations, err)
+	}
+
+	c = newController()
+	if err := c.Select([]string{"other-signer"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.certRotators) != 2 {
+		t.Errorf("expected the signer to select both of its cert rotators, got %d", len(c.certRotators))
+	}
+
+	c = newController()
+	if err := c.Select([]string{"ClientCert", "unknown"}); err == nil || !strings.HasPrefix(err.Error(), "unknown certificates unknown, known are ClientCert,") {
+		t.Errorf("expected an unknown certificate error, got %v", err)
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	corelistersv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 )
 
 var (
@@ -38,7 +41,7 @@ func NewCertRotationTimeUpgradeableController(
 	return factory.New().WithInformers(
 		operatorClient.Informer(),
 		configMapInformer.Informer(),
-	).WithSync(c.sync).ResyncEvery(time.Minute).ToController("CertRotationTimeUpgradeableController", eventRecorder.WithComponentSuffix("certRotationTime-upgradeable"))
+	).WithSync(syncmetrics.Instrument("CertRotationTimeUpgradeableController", c.sync)).ResyncEvery(resyncinterval.For("CertRotationTimeUpgradeableController", time.Minute)).ToController("CertRotationTimeUpgradeableController", eventRecorder.WithComponentSuffix("certRotationTime-upgradeable"))
 }
 
 func (c *CertRotationTimeUpgradeableController) sync(ctx context.Context, syncContext factory.SyncContext) error {
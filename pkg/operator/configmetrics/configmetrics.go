@@ -30,6 +30,11 @@ func Register(configInformer configinformers.SharedInformerFactory) {
 			Name: "cluster_proxy_enabled",
 			Help: "Reports whether the cluster has been configured to use a proxy. type is which type of proxy configuration has been set - http for an http proxy, https for an https proxy, and trusted_ca if a custom CA was specified.",
 		}, []string{"type"}),
+		apiserverLister: configInformer.Config().V1().APIServers().Lister(),
+		auditProfile: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cluster_audit_profile",
+			Help: "Reports the audit profile the kube-apiservers are configured with. profile is the audit profile of the cluster. The value is 0 if the None profile disables the audit log or 1 otherwise.",
+		}, []string{"profile"}),
 	})
 }
 
@@ -38,9 +43,11 @@ type configMetrics struct {
 	cloudProvider        *prometheus.GaugeVec
 	featureSet           *prometheus.GaugeVec
 	proxyEnablement      *prometheus.GaugeVec
+	auditProfile         *prometheus.GaugeVec
 	infrastructureLister configlisters.InfrastructureLister
 	featuregateLister    configlisters.FeatureGateLister
 	proxyLister          configlisters.ProxyLister
+	apiserverLister      configlisters.APIServerLister
 }
 
 func (m *configMetrics) Create(version *semver.Version) bool {
@@ -52,6 +59,7 @@ func (m *configMetrics) Describe(ch chan<- *prometheus.Desc) {
 	ch <- m.cloudProvider.WithLabelValues("", "").Desc()
 	ch <- m.featureSet.WithLabelValues("").Desc()
 	ch <- m.proxyEnablement.WithLabelValues("").Desc()
+	ch <- m.auditProfile.WithLabelValues("").Desc()
 }
 
 // Collect calculates metrics from the cached config and reports them to the prometheus collector.
@@ -88,6 +96,13 @@ func (m *configMetrics) Collect(ch chan<- prometheus.Metric) {
 		ch <- booleanGaugeValue(m.proxyEnablement.WithLabelValues("https"), len(proxy.Spec.HTTPSProxy) > 0)
 		ch <- booleanGaugeValue(m.proxyEnablement.WithLabelValues("trusted_ca"), len(proxy.Spec.TrustedCA.Name) > 0)
 	}
+	if apiserver, err := m.apiserverLister.Get("cluster"); err == nil {
+		profile := apiserver.Spec.Audit.Profile
+		if len(profile) == 0 {
+			profile = configv1.DefaultAuditProfileType
+		}
+		ch <- booleanGaugeValue(m.auditProfile.WithLabelValues(string(profile)), profile != configv1.NoneAuditProfileType)
+	}
 }
 
 func booleanGaugeValue(g prometheus.Gauge, value bool) prometheus.Gauge {
@@ -0,0 +1,52 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// advertiseAddressSubnetsPath is not part of the kube-apiserver config, it is read by the target config controller to
+// select the --advertise-address in the static pod and pruned from the config.yaml of the kube-apiserver.
+var advertiseAddressSubnetsPath = []string{"advertiseAddressSubnets"}
+
+// ObserveAdvertiseAddressSubnets observes the subnets the advertise address of the kube-apiservers is selected from.
+func ObserveAdvertiseAddressSubnets(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, advertiseAddressSubnetsPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateAdvertiseAddressSubnets(operatorConfig.AdvertiseAddressSubnets, field.NewPath("advertiseAddressSubnets")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveAdvertiseAddressSubnetsFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(operatorConfig.AdvertiseAddressSubnets) > 0 {
+		if err := unstructured.SetNestedStringSlice(observedConfig, operatorConfig.AdvertiseAddressSubnets, advertiseAddressSubnetsPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentSubnets, _, _ := unstructured.NestedStringSlice(existingConfig, advertiseAddressSubnetsPath...)
+	if (len(currentSubnets) > 0 || len(operatorConfig.AdvertiseAddressSubnets) > 0) && !equality.Semantic.DeepEqual(currentSubnets, operatorConfig.AdvertiseAddressSubnets) {
+		recorder.Eventf("ObserveAdvertiseAddressSubnets", "advertise address subnets changed to %v", operatorConfig.AdvertiseAddressSubnets)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,79 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveAdvertiseAddressSubnets(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "subnets",
+			operatorConfig: "advertiseAddressSubnets:\n- 192.168.10.0/24\n- fd00:10::/64\n",
+			expectedConfig: map[string]interface{}{"advertiseAddressSubnets": []interface{}{"192.168.10.0/24", "fd00:10::/64"}},
+		},
+		{
+			name:           "invalid subnet keeps the existing config",
+			operatorConfig: "advertiseAddressSubnets:\n- 192.168.10.1\n",
+			existingConfig: map[string]interface{}{"advertiseAddressSubnets": []interface{}{"192.168.10.0/24"}},
+			expectedConfig: map[string]interface{}{"advertiseAddressSubnets": []interface{}{"192.168.10.0/24"}},
+			expectError:    true,
+		},
+		{
+			name:           "duplicate subnet",
+			operatorConfig: "advertiseAddressSubnets:\n- 192.168.10.0/24\n- 192.168.10.1/24\n",
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveAdvertiseAddressSubnets(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package apiserver
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var anonymousAuthPath = []string{"apiServerArguments", "anonymous-auth"}
+
+// platformsWithoutAnonymousHealthChecks are the platforms whose installer provisioned load balancers do not probe
+// https://:6443/readyz anonymously. GCP health checks the insecure-readyz sidecar on port 6080 which authenticates
+// itself once anonymous requests are rejected, on None the load balancer is provided and configured by the user.
+var platformsWithoutAnonymousHealthChecks = sets.NewString(
+	string(configv1.GCPPlatformType),
+	string(configv1.NonePlatformType),
+)
+
+// ObserveAnonymousAuth sets anonymous-auth=false when the operator config disables anonymous authentication.
+// Anonymous requests are only rejected once the cluster finished bootstrapping and on platforms where the
+// load balancer health checks keep working, otherwise the default from defaultconfig.yaml (true) is kept and
+// the refusal is reported as an error.
+func ObserveAnonymousAuth(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, anonymousAuthPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateAnonymousAuth(operatorConfig.AnonymousAuth, field.NewPath("anonymousAuth")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveAnonymousAuthFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	currentAnonymousAuth, _, _ := unstructured.NestedStringSlice(existingConfig, anonymousAuthPath...)
+	currentlyDisabled := len(currentAnonymousAuth) == 1 && currentAnonymousAuth[0] == "false"
+
+	if operatorConfig.AnonymousAuth != operatorconfig.AnonymousAuthDisabled {
+		if currentlyDisabled {
+			recorder.Eventf("ObserveAnonymousAuth", "anonymous-auth changed to true")
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	if err := anonymousAuthCanBeDisabled(listers); err != nil {
+		// rejecting anonymous requests now would break the cluster, fall back to the default
+		err = fmt.Errorf("anonymous authentication cannot be disabled: %v", err)
+		recorder.Warningf("ObserveAnonymousAuthFailed", err.Error())
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedStringSlice(observedConfig, []string{"false"}, anonymousAuthPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if !currentlyDisabled {
+		recorder.Eventf("ObserveAnonymousAuth", "anonymous-auth changed to false")
+	}
+
+	return observedConfig, errs
+}
+
+// anonymousAuthCanBeDisabled checks that bootstrapping is done and that the load balancers of the platform
+// do not depend on anonymous access. The kubelet probes are moved to the authenticated insecure-readyz sidecar
+// by the target config controller when anonymous-auth is false.
+func anonymousAuthCanBeDisabled(listers configobservation.Listers) error {
+	bootstrap, err := listers.KubeSystemConfigMapLister.ConfigMaps("kube-system").Get("bootstrap")
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("configmaps kube-system/bootstrap not found, the cluster is still bootstrapping")
+	}
+	if err != nil {
+		return err
+	}
+	if status := bootstrap.Data["status"]; status != "complete" {
+		return fmt.Errorf("the cluster is still bootstrapping, kube-system/bootstrap status is %q", status)
+	}
+
+	infrastructure, err := listers.InfrastructureLister().Get("cluster")
+	if err != nil {
+		return err
+	}
+	var platform configv1.PlatformType
+	if infrastructure.Status.PlatformStatus != nil {
+		platform = infrastructure.Status.PlatformStatus.Type
+	}
+	if !platformsWithoutAnonymousHealthChecks.Has(string(platform)) {
+		return fmt.Errorf("the load balancers of platform %q health check https://:6443/readyz anonymously, supported platforms are %v", platform, platformsWithoutAnonymousHealthChecks.List())
+	}
+
+	return nil
+}
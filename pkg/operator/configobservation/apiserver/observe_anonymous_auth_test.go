@@ -0,0 +1,131 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveAnonymousAuth(t *testing.T) {
+	disabledConfig := map[string]interface{}{"apiServerArguments": map[string]interface{}{
+		"anonymous-auth": []interface{}{"false"},
+	}}
+
+	scenarios := []struct {
+		name            string
+		operatorConfig  string
+		bootstrapStatus string
+		platform        configv1.PlatformType
+		existingConfig  map[string]interface{}
+		expectedConfig  map[string]interface{}
+		expectError     bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "enabled",
+			operatorConfig: "anonymousAuth: Enabled\n",
+			existingConfig: disabledConfig,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:            "disabled",
+			operatorConfig:  "anonymousAuth: Disabled\n",
+			bootstrapStatus: "complete",
+			platform:        configv1.NonePlatformType,
+			expectedConfig:  disabledConfig,
+		},
+		{
+			name:           "unsupported value keeps the existing config",
+			operatorConfig: "anonymousAuth: Restricted\n",
+			existingConfig: disabledConfig,
+			expectedConfig: disabledConfig,
+			expectError:    true,
+		},
+		{
+			name:            "still bootstrapping",
+			operatorConfig:  "anonymousAuth: Disabled\n",
+			bootstrapStatus: "progressing",
+			platform:        configv1.NonePlatformType,
+			expectedConfig:  map[string]interface{}{},
+			expectError:     true,
+		},
+		{
+			name:           "no bootstrap configmap",
+			operatorConfig: "anonymousAuth: Disabled\n",
+			platform:       configv1.GCPPlatformType,
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+		{
+			name:            "load balancer health checks depend on anonymous access",
+			operatorConfig:  "anonymousAuth: Disabled\n",
+			bootstrapStatus: "complete",
+			platform:        configv1.AWSPlatformType,
+			existingConfig:  disabledConfig,
+			expectedConfig:  map[string]interface{}{},
+			expectError:     true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			kubeSystemIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.bootstrapStatus) > 0 {
+				if err := kubeSystemIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "bootstrap"},
+					Data:       map[string]string{"status": scenario.bootstrapStatus},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			infrastructureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := infrastructureIndexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: scenario.platform}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister:     corev1listers.NewConfigMapLister(configIndexer),
+				KubeSystemConfigMapLister: corev1listers.NewConfigMapLister(kubeSystemIndexer),
+				InfrastructureLister_:     configlistersv1.NewInfrastructureLister(infrastructureIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveAnonymousAuth(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package apiserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// observedFlags are set by the config observers. Overrides of them are rejected, they are also used to tell
+// the overrides apart from the other observed arguments.
+var observedFlags = sets.NewString(
+	"anonymous-auth",
+	"api-audiences",
+	"audit-log-maxage",
+	"audit-log-maxbackup",
+	"audit-log-maxsize",
+	"audit-policy-file",
+	"audit-webhook-batch-buffer-size",
+	"audit-webhook-batch-max-size",
+	"audit-webhook-batch-max-wait",
+	"audit-webhook-batch-throttle-burst",
+	"audit-webhook-batch-throttle-qps",
+	"audit-webhook-config-file",
+	"audit-webhook-initial-backoff",
+	"audit-webhook-mode",
+	"authentication-token-webhook-config-file",
+	"authentication-token-webhook-version",
+	"cloud-config",
+	"cloud-provider",
+	"cors-allowed-origins",
+	"default-not-ready-toleration-seconds",
+	"default-unreachable-toleration-seconds",
+	"encryption-provider-config",
+	"etcd-compaction-interval",
+	"etcd-count-metric-poll-period",
+	"etcd-db-metric-poll-interval",
+	"etcd-healthcheck-timeout",
+	"etcd-servers",
+	"feature-gates",
+	"kubelet-preferred-address-types",
+	"profiling",
+	"runtime-config",
+	"service-account-issuer",
+	"service-account-jwks-uri",
+	"service-node-port-range",
+	"shutdown-delay-duration",
+	"tls-cipher-suites",
+	"tls-min-version",
+)
+
+// commandLineFlags are passed on the command line of the static pod.
+var commandLineFlags = sets.NewString(
+	"advertise-address",
+	"openshift-config",
+	"permit-address-sharing",
+	"v",
+	"vmodule",
+)
+
+type argumentOverridesObserver struct {
+	managedFlags sets.String
+}
+
+// NewArgumentOverridesObserver returns an ObserveConfigFunc that applies the tech preview apiServerArguments of
+// the operator config. The overrides are only applied with the TechPreviewNoUpgrade feature set and after they
+// passed ValidateAPIServerArguments. Flags set by the config observers, on the command line of the static pod or
+// pointing at files managed by the operator cannot be overridden.
+func NewArgumentOverridesObserver() configobserver.ObserveConfigFunc {
+	managedFlags := observedFlags.Union(commandLineFlags)
+	for _, asset := range []string{"assets/config/defaultconfig.yaml", "assets/config/config-overrides.yaml"} {
+		managedFlags.Insert(fileFlags(bindata.MustAsset(asset))...)
+	}
+	return (&argumentOverridesObserver{managedFlags: managedFlags}).observe
+}
+
+func (o *argumentOverridesObserver) observe(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, overridePaths(ret)...)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	existingOverrides := argumentOverrides(existingConfig)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingOverrides, append(errs, err)
+	}
+	if len(operatorConfig.APIServerArguments) == 0 {
+		return map[string]interface{}{}, errs
+	}
+
+	featureGate, err := listers.FeatureGateLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingOverrides, append(errs, err)
+	}
+	if featureGate == nil || featureGate.Spec.FeatureSet != configv1.TechPreviewNoUpgrade {
+		err := fmt.Errorf("apiServerArguments of the operator config require the %s feature set", configv1.TechPreviewNoUpgrade)
+		recorder.Warningf("ObserveArgumentOverridesFailed", err.Error())
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	if validationErrs := operatorconfig.ValidateAPIServerArguments(operatorConfig.APIServerArguments, o.managedFlags, field.NewPath("apiServerArguments")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveArgumentOverridesFailed", err.Error())
+		return existingOverrides, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	for name, values := range operatorConfig.APIServerArguments {
+		if err := unstructured.SetNestedStringSlice(observedConfig, values, "apiServerArguments", name); err != nil {
+			return existingOverrides, append(errs, err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(existingOverrides, observedConfig) {
+		recorder.Eventf("ObserveArgumentOverrides", "kube-apiserver argument overrides changed to %s", strings.Join(describeOverrides(observedConfig), " "))
+	}
+
+	return observedConfig, errs
+}
+
+// fileFlags returns the apiServerArguments of a KubeAPIServerConfig that point at files.
+func fileFlags(rawConfig []byte) []string {
+	config := &kubecontrolplanev1.KubeAPIServerConfig{}
+	if err := yaml.Unmarshal(rawConfig, config); err != nil {
+		panic(err)
+	}
+	var names []string
+	for name, values := range config.APIServerArguments {
+		for _, value := range values {
+			if strings.HasPrefix(value, "/") {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// argumentOverrides returns the observed arguments no other observer sets.
+func argumentOverrides(config map[string]interface{}) map[string]interface{} {
+	overrides := map[string]interface{}{}
+	args, _, _ := unstructured.NestedMap(config, "apiServerArguments")
+	for name, values := range args {
+		if !observedFlags.Has(name) {
+			overrides[name] = values
+		}
+	}
+	if len(overrides) == 0 {
+		return overrides
+	}
+	return map[string]interface{}{"apiServerArguments": overrides}
+}
+
+func overridePaths(config map[string]interface{}) [][]string {
+	args, _, _ := unstructured.NestedMap(config, "apiServerArguments")
+	var paths [][]string
+	for name := range args {
+		paths = append(paths, []string{"apiServerArguments", name})
+	}
+	return paths
+}
+
+// describeOverrides returns the overrides as sorted command line flags.
+func describeOverrides(config map[string]interface{}) []string {
+	args, _, _ := unstructured.NestedMap(config, "apiServerArguments")
+	var flags []string
+	for name := range args {
+		values, _, _ := unstructured.NestedStringSlice(args, name)
+		for _, value := range values {
+			flags = append(flags, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// NewArgumentOverridesCondition returns the APIServerArgumentOverrides condition listing the argument overrides of the
+// observed config.
+func NewArgumentOverridesCondition(observedConfig map[string]interface{}, _ *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+	flags := describeOverrides(argumentOverrides(observedConfig))
+	if len(flags) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   "APIServerArgumentOverrides",
+			Status: operatorv1.ConditionFalse,
+			Reason: "NoOverrides",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "APIServerArgumentOverrides",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "OverridesApplied",
+		Message: fmt.Sprintf("the following kube-apiserver arguments are overridden: %s", strings.Join(flags, " ")),
+	}
+}
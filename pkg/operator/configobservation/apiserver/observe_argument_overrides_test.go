@@ -0,0 +1,157 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveArgumentOverrides(t *testing.T) {
+	overrideConfig := map[string]interface{}{"apiServerArguments": map[string]interface{}{
+		"goaway-chance":         []interface{}{"0.001"},
+		"max-requests-inflight": []interface{}{"4000"},
+	}}
+
+	scenarios := []struct {
+		name              string
+		operatorConfig    string
+		featureSet        configv1.FeatureSet
+		existingConfig    map[string]interface{}
+		expectedConfig    map[string]interface{}
+		expectedCondition operatorv1.OperatorCondition
+		expectError       bool
+	}{
+		{
+			name:              "no overrides",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: operatorv1.OperatorCondition{Type: "APIServerArgumentOverrides", Status: operatorv1.ConditionFalse, Reason: "NoOverrides"},
+		},
+		{
+			name:           "overrides applied",
+			operatorConfig: "apiServerArguments:\n  max-requests-inflight: [\"4000\"]\n  goaway-chance: [\"0.001\"]\n",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-servers": []interface{}{"https://localhost:2379"},
+			}},
+			expectedConfig: overrideConfig,
+			expectedCondition: operatorv1.OperatorCondition{
+				Type:    "APIServerArgumentOverrides",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "OverridesApplied",
+				Message: "the following kube-apiserver arguments are overridden: --goaway-chance=0.001 --max-requests-inflight=4000",
+			},
+		},
+		{
+			name:              "tech preview is required",
+			operatorConfig:    "apiServerArguments:\n  max-requests-inflight: [\"4000\"]\n",
+			existingConfig:    overrideConfig,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: operatorv1.OperatorCondition{Type: "APIServerArgumentOverrides", Status: operatorv1.ConditionFalse, Reason: "NoOverrides"},
+			expectError:       true,
+		},
+		{
+			name:           "unknown flag keeps the existing overrides",
+			operatorConfig: "apiServerArguments:\n  max-requests-in-flight: [\"4000\"]\n",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-servers":          []interface{}{"https://localhost:2379"},
+				"goaway-chance":         []interface{}{"0.001"},
+				"max-requests-inflight": []interface{}{"4000"},
+			}},
+			expectedConfig: overrideConfig,
+			expectedCondition: operatorv1.OperatorCondition{
+				Type:    "APIServerArgumentOverrides",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "OverridesApplied",
+				Message: "the following kube-apiserver arguments are overridden: --goaway-chance=0.001 --max-requests-inflight=4000",
+			},
+			expectError: true,
+		},
+		{
+			name:              "observed flag",
+			operatorConfig:    "apiServerArguments:\n  feature-gates: [\"APIPriorityAndFairness=false\"]\n",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: operatorv1.OperatorCondition{Type: "APIServerArgumentOverrides", Status: operatorv1.ConditionFalse, Reason: "NoOverrides"},
+			expectError:       true,
+		},
+		{
+			name:              "file managed by the operator",
+			operatorConfig:    "apiServerArguments:\n  etcd-cafile: [\"/tmp/ca.crt\"]\n",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: operatorv1.OperatorCondition{Type: "APIServerArgumentOverrides", Status: operatorv1.ConditionFalse, Reason: "NoOverrides"},
+			expectError:       true,
+		},
+		{
+			name:              "conflicting values",
+			operatorConfig:    "apiServerArguments:\n  max-requests-inflight: [\"4000\", \"5000\"]\n",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: operatorv1.OperatorCondition{Type: "APIServerArgumentOverrides", Status: operatorv1.ConditionFalse, Reason: "NoOverrides"},
+			expectError:       true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			featureGateIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := featureGateIndexer.Add(&configv1.FeatureGate{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: scenario.featureSet}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+				FeatureGateLister_:    configlistersv1.NewFeatureGateLister(featureGateIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observe := NewArgumentOverridesObserver()
+			observedConfig, errs := observe(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			// the condition is set from the whole observed config, which includes the arguments of the other observers
+			args := map[string]interface{}{"etcd-servers": []interface{}{"https://localhost:2379"}}
+			overrides, _, _ := unstructured.NestedMap(observedConfig, "apiServerArguments")
+			for name, values := range overrides {
+				args[name] = values
+			}
+			if condition := NewArgumentOverridesCondition(map[string]interface{}{"apiServerArguments": args}, nil); !cmp.Equal(scenario.expectedCondition, condition) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(scenario.expectedCondition, condition))
+			}
+		})
+	}
+}
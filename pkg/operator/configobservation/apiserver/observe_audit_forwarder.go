@@ -0,0 +1,82 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// auditForwarderCAConfigMap is the revisioned config map in the target namespace with the CA bundle of the endpoint
+// of the audit forwarder.
+const auditForwarderCAConfigMap = "audit-forwarder-ca"
+
+// auditForwarderPath is not part of the kube-apiserver config, it is read by the target config controller to add the
+// audit forwarder sidecar.
+var auditForwarderPath = []string{"auditForwarder"}
+
+// ObserveAuditForwarder observes the audit forwarder sidecar of the operator config and syncs the config map with the
+// CA bundle of its endpoint to the target namespace.
+func ObserveAuditForwarder(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, auditForwarderPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	config := operatorConfig.AuditForwarder
+	if validationErrs := operatorconfig.ValidateAuditForwarder(config, field.NewPath("auditForwarder")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveAuditForwarderFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	destination := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: auditForwarderCAConfigMap}
+	source := resourcesynccontroller.ResourceLocation{}
+	if config != nil && config.TLS != nil && len(config.TLS.CAConfigMap) > 0 {
+		caConfigMap, err := listers.ConfigConfigMapLister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(config.TLS.CAConfigMap)
+		if err != nil {
+			return existingConfig, append(errs, fmt.Errorf("failed to get configmap %s/%s: %w", operatorclient.GlobalUserSpecifiedConfigNamespace, config.TLS.CAConfigMap, err))
+		}
+		if len(caConfigMap.Data["ca-bundle.crt"]) == 0 {
+			err := fmt.Errorf("configmap %s/%s has no ca-bundle.crt", operatorclient.GlobalUserSpecifiedConfigNamespace, config.TLS.CAConfigMap)
+			recorder.Warningf("ObserveAuditForwarderFailed", err.Error())
+			return existingConfig, append(errs, err)
+		}
+		source = resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: config.TLS.CAConfigMap}
+	}
+	if err := listers.ResourceSyncer().SyncConfigMap(destination, source); err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	var observedAuditForwarder map[string]interface{}
+	if config != nil {
+		observedAuditForwarder, err = toUnstructured(config)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+		if err := unstructured.SetNestedMap(observedConfig, observedAuditForwarder, auditForwarderPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentAuditForwarder, _, _ := unstructured.NestedMap(existingConfig, auditForwarderPath...)
+	if (len(currentAuditForwarder) > 0 || len(observedAuditForwarder) > 0) && !equality.Semantic.DeepEqual(currentAuditForwarder, observedAuditForwarder) {
+		recorder.Eventf("ObserveAuditForwarder", "kube-apiserver audit forwarder sidecar changed to %v", observedAuditForwarder)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,119 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveAuditForwarder(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		caConfigMap    map[string]string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectedSynced map[string]string
+		expectError    bool
+	}{
+		{
+			name:           "unset",
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{"configmap/audit-forwarder-ca.openshift-kube-apiserver": "DELETE"},
+		},
+		{
+			name:           "syslog",
+			operatorConfig: "auditForwarder:\n  protocol: Syslog\n  endpoint: siem.example.com:514\n",
+			expectedConfig: map[string]interface{}{"auditForwarder": map[string]interface{}{
+				"protocol": "Syslog",
+				"endpoint": "siem.example.com:514",
+			}},
+			expectedSynced: map[string]string{"configmap/audit-forwarder-ca.openshift-kube-apiserver": "DELETE"},
+		},
+		{
+			name:           "tls with a ca bundle",
+			operatorConfig: "auditForwarder:\n  protocol: Syslog\n  endpoint: siem.example.com:6514\n  tls:\n    caConfigMap: siem-ca\n",
+			caConfigMap:    map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----"},
+			expectedConfig: map[string]interface{}{"auditForwarder": map[string]interface{}{
+				"protocol": "Syslog",
+				"endpoint": "siem.example.com:6514",
+				"tls":      map[string]interface{}{"caConfigMap": "siem-ca"},
+			}},
+			expectedSynced: map[string]string{"configmap/audit-forwarder-ca.openshift-kube-apiserver": "configmap/siem-ca.openshift-config"},
+		},
+		{
+			name:           "ca config map without a ca bundle keeps the existing config",
+			operatorConfig: "auditForwarder:\n  protocol: Syslog\n  endpoint: siem.example.com:6514\n  tls:\n    caConfigMap: siem-ca\n",
+			caConfigMap:    map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+			existingConfig: map[string]interface{}{"auditForwarder": map[string]interface{}{
+				"protocol": "Syslog",
+				"endpoint": "siem.example.com:514",
+			}},
+			expectedConfig: map[string]interface{}{"auditForwarder": map[string]interface{}{
+				"protocol": "Syslog",
+				"endpoint": "siem.example.com:514",
+			}},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+		{
+			name:           "unsupported protocol",
+			operatorConfig: "auditForwarder:\n  protocol: FluentForward\n  endpoint: fluentd:24224\n",
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if scenario.caConfigMap != nil {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "siem-ca"},
+					Data:       scenario.caConfigMap,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			synced := map[string]string{}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				ResourceSync:          &mockResourceSyncer{t: t, synced: synced},
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveAuditForwarder(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			if !cmp.Equal(scenario.expectedSynced, synced) {
+				t.Fatalf("unexpected synced resources, diff = %v", cmp.Diff(scenario.expectedSynced, synced))
+			}
+		})
+	}
+}
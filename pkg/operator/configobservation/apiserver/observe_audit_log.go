@@ -0,0 +1,99 @@
+package apiserver
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var (
+	auditLogMaxSizePath   = []string{"apiServerArguments", "audit-log-maxsize"}
+	auditLogMaxBackupPath = []string{"apiServerArguments", "audit-log-maxbackup"}
+	auditLogMaxAgePath    = []string{"apiServerArguments", "audit-log-maxage"}
+)
+
+// defaultAuditLog is the audit log rotation of the audit profiles that write audit events.
+var defaultAuditLog = operatorconfig.AuditLogConfig{MaxSize: pointer.Int32(100), MaxBackup: pointer.Int32(10)}
+
+// auditLogForProfile are the audit profiles that deviate from defaultAuditLog. The None profile only logs the
+// events of its custom rules, keeping a history of them is not worth the disk.
+var auditLogForProfile = map[configv1.AuditProfileType]operatorconfig.AuditLogConfig{
+	configv1.NoneAuditProfileType: {MaxSize: pointer.Int32(100), MaxBackup: pointer.Int32(1)},
+}
+
+// ObserveAuditLog sets the rotation and retention of the audit log from the defaults of the audit profile of
+// apiserver/cluster and the auditLog of the operator config.
+func ObserveAuditLog(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, auditLogMaxSizePath, auditLogMaxBackupPath, auditLogMaxAgePath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	apiServer, err := listers.APIServerLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateAuditLog(operatorConfig.AuditLog, field.NewPath("auditLog")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveAuditLogFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	auditLog := defaultAuditLog
+	if apiServer != nil {
+		if profileAuditLog, ok := auditLogForProfile[apiServer.Spec.Audit.Profile]; ok {
+			auditLog = profileAuditLog
+		}
+	}
+	if operatorConfig.AuditLog.MaxSize != nil {
+		auditLog.MaxSize = operatorConfig.AuditLog.MaxSize
+	}
+	if operatorConfig.AuditLog.MaxBackup != nil {
+		auditLog.MaxBackup = operatorConfig.AuditLog.MaxBackup
+	}
+	if operatorConfig.AuditLog.MaxAge != nil {
+		auditLog.MaxAge = operatorConfig.AuditLog.MaxAge
+	}
+
+	observedConfig := map[string]interface{}{}
+	for _, arg := range []struct {
+		path  []string
+		value *int32
+	}{
+		{path: auditLogMaxSizePath, value: auditLog.MaxSize},
+		{path: auditLogMaxBackupPath, value: auditLog.MaxBackup},
+		{path: auditLogMaxAgePath, value: auditLog.MaxAge},
+	} {
+		if arg.value == nil {
+			continue
+		}
+		if err := unstructured.SetNestedStringSlice(observedConfig, []string{strconv.Itoa(int(*arg.value))}, arg.path...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentConfig := configobserver.Pruned(existingConfig, auditLogMaxSizePath, auditLogMaxBackupPath, auditLogMaxAgePath)
+	if !equality.Semantic.DeepEqual(currentConfig, observedConfig) {
+		recorder.Eventf("ObserveAuditLog", "audit log rotation changed to maxsize=%d MiB maxbackup=%d maxage=%d days",
+			pointer.Int32Deref(auditLog.MaxSize, 0), pointer.Int32Deref(auditLog.MaxBackup, 0), pointer.Int32Deref(auditLog.MaxAge, 0))
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,108 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveAuditLog(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		profile        configv1.AuditProfileType
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:    "default profile",
+			profile: configv1.DefaultAuditProfileType,
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-log-maxsize":   []interface{}{"100"},
+				"audit-log-maxbackup": []interface{}{"10"},
+			}},
+		},
+		{
+			name:    "none profile",
+			profile: configv1.NoneAuditProfileType,
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-log-maxsize":   []interface{}{"100"},
+				"audit-log-maxbackup": []interface{}{"1"},
+			}},
+		},
+		{
+			name:           "operator config overrides the profile defaults",
+			profile:        configv1.AllRequestBodiesAuditProfileType,
+			operatorConfig: "auditLog:\n  maxSize: 50\n  maxAge: 7\n",
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-log-maxsize":   []interface{}{"50"},
+				"audit-log-maxbackup": []interface{}{"10"},
+				"audit-log-maxage":    []interface{}{"7"},
+			}},
+		},
+		{
+			name:           "unlimited backups keep the existing config",
+			profile:        configv1.DefaultAuditProfileType,
+			operatorConfig: "auditLog:\n  maxBackup: 0\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-log-maxsize":   []interface{}{"100"},
+				"audit-log-maxbackup": []interface{}{"20"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-log-maxsize":   []interface{}{"100"},
+				"audit-log-maxbackup": []interface{}{"20"},
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			apiServerIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := apiServerIndexer.Add(&configv1.APIServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.APIServerSpec{Audit: configv1.Audit{Profile: scenario.profile}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				APIServerLister_:      configlistersv1.NewAPIServerLister(apiServerIndexer),
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveAuditLog(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
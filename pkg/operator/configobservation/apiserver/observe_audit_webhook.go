@@ -0,0 +1,143 @@
+package apiserver
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditwebhookcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/auth"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	// auditWebhookSecret is the revisioned secret in the target namespace with the kubeconfig of the audit webhook.
+	auditWebhookSecret = "audit-webhook"
+	auditWebhookFile   = "/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"
+)
+
+var (
+	auditWebhookConfigFilePath     = []string{"apiServerArguments", "audit-webhook-config-file"}
+	auditWebhookModePath           = []string{"apiServerArguments", "audit-webhook-mode"}
+	auditWebhookInitialBackoffPath = []string{"apiServerArguments", "audit-webhook-initial-backoff"}
+	auditWebhookBufferSizePath     = []string{"apiServerArguments", "audit-webhook-batch-buffer-size"}
+	auditWebhookMaxSizePath        = []string{"apiServerArguments", "audit-webhook-batch-max-size"}
+	auditWebhookMaxWaitPath        = []string{"apiServerArguments", "audit-webhook-batch-max-wait"}
+	auditWebhookThrottleQPSPath    = []string{"apiServerArguments", "audit-webhook-batch-throttle-qps"}
+	auditWebhookThrottleBurstPath  = []string{"apiServerArguments", "audit-webhook-batch-throttle-burst"}
+
+	auditWebhookPaths = [][]string{
+		auditWebhookConfigFilePath,
+		auditWebhookModePath,
+		auditWebhookInitialBackoffPath,
+		auditWebhookBufferSizePath,
+		auditWebhookMaxSizePath,
+		auditWebhookMaxWaitPath,
+		auditWebhookThrottleQPSPath,
+		auditWebhookThrottleBurstPath,
+	}
+)
+
+// ObserveAuditWebhook sets the audit webhook backend of the kube-apiserver from the auditWebhook of the operator
+// config and syncs the secret with its kubeconfig to the target namespace. That is the kubeConfigSecret of
+// openshift-config, or for a webhook configured by url the kubeconfig the audit webhook controller renders in the
+// operator namespace. An invalid config or kubeconfig keeps the existing config, the kube-apiserver does not start
+// with a kubeconfig it cannot load.
+func ObserveAuditWebhook(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, auditWebhookPaths...)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	config := operatorConfig.AuditWebhook
+	if validationErrs := operatorconfig.ValidateAuditWebhook(config, field.NewPath("auditWebhook")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveAuditWebhookFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	destination := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: auditWebhookSecret}
+	observedConfig := map[string]interface{}{}
+	if config == nil {
+		// remove whatever we synced
+		if err := listers.ResourceSyncer().SyncSecret(destination, resourcesynccontroller.ResourceLocation{}); err != nil {
+			return existingConfig, append(errs, err)
+		}
+		return observedConfig, errs
+	}
+
+	source := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: config.KubeConfigSecret}
+	secretLister := listers.ConfigSecretLister()
+	if len(config.URL) > 0 {
+		source = resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: auditwebhookcontroller.KubeconfigSecretName}
+		secretLister = listers.OperatorSecretLister
+	}
+	secret, err := secretLister.Secrets(source.Namespace).Get(source.Name)
+	if err != nil {
+		return existingConfig, append(errs, fmt.Errorf("failed to get secret %s/%s: %w", source.Namespace, source.Name, err))
+	}
+	if secretErrs := auth.ValidateKubeconfigSecret(secret); len(secretErrs) > 0 {
+		err := fmt.Errorf("secret %s/%s is invalid: %w", source.Namespace, source.Name, utilerrors.NewAggregate(secretErrs))
+		recorder.Warningf("ObserveAuditWebhookFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	mode := config.Mode
+	if len(mode) == 0 {
+		mode = operatorconfig.AuditWebhookBatch
+	}
+	args := []struct {
+		path  []string
+		value string
+	}{
+		{path: auditWebhookConfigFilePath, value: auditWebhookFile},
+		{path: auditWebhookModePath, value: string(mode)},
+		{path: auditWebhookInitialBackoffPath, value: config.InitialBackoff},
+		{path: auditWebhookBufferSizePath, value: int32String(config.Batch.BufferSize)},
+		{path: auditWebhookMaxSizePath, value: int32String(config.Batch.MaxSize)},
+		{path: auditWebhookMaxWaitPath, value: config.Batch.MaxWait},
+		{path: auditWebhookThrottleQPSPath, value: int32String(config.Batch.ThrottleQPS)},
+		{path: auditWebhookThrottleBurstPath, value: int32String(config.Batch.ThrottleBurst)},
+	}
+	for _, arg := range args {
+		if len(arg.value) == 0 {
+			continue
+		}
+		if err := unstructured.SetNestedStringSlice(observedConfig, []string{arg.value}, arg.path...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	if err := listers.ResourceSyncer().SyncSecret(destination, source); err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	currentConfig := configobserver.Pruned(existingConfig, auditWebhookPaths...)
+	if !equality.Semantic.DeepEqual(currentConfig, observedConfig) {
+		recorder.Eventf("ObserveAuditWebhook", "audit webhook changed to %s mode with the kubeconfig of secret %s/%s",
+			mode, source.Namespace, source.Name)
+	}
+
+	return observedConfig, errs
+}
+
+func int32String(value *int32) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*value))
+}
@@ -0,0 +1,191 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+const auditWebhookKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: siem
+  cluster:
+    server: https://siem.example.com:8443/audit
+contexts:
+- name: siem
+  context:
+    cluster: siem
+    user: kube-apiserver
+current-context: siem
+users:
+- name: kube-apiserver
+  user:
+    token: secret-token
+`
+
+func TestObserveAuditWebhook(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		kubeConfig     string
+		// renderedKubeConfig is the kubeconfig the audit webhook controller rendered for a url
+		renderedKubeConfig string
+		existingConfig     map[string]interface{}
+		expectedConfig     map[string]interface{}
+		expectedSynced     map[string]string
+		expectError        bool
+	}{
+		{
+			name:           "unset",
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{"secret/audit-webhook.openshift-kube-apiserver": "DELETE"},
+		},
+		{
+			name:           "batch mode by default",
+			operatorConfig: "auditWebhook:\n  kubeConfigSecret: siem-webhook\n",
+			kubeConfig:     auditWebhookKubeConfig,
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-webhook-config-file": []interface{}{"/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"},
+				"audit-webhook-mode":        []interface{}{"batch"},
+			}},
+			expectedSynced: map[string]string{"secret/audit-webhook.openshift-kube-apiserver": "secret/siem-webhook.openshift-config"},
+		},
+		{
+			name: "batching and throttling",
+			operatorConfig: `auditWebhook:
+  kubeConfigSecret: siem-webhook
+  initialBackoff: 5s
+  batch:
+    bufferSize: 20000
+    maxSize: 500
+    maxWait: 10s
+    throttleQPS: 20
+    throttleBurst: 30
+`,
+			kubeConfig: auditWebhookKubeConfig,
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-webhook-config-file":          []interface{}{"/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"},
+				"audit-webhook-mode":                 []interface{}{"batch"},
+				"audit-webhook-initial-backoff":      []interface{}{"5s"},
+				"audit-webhook-batch-buffer-size":    []interface{}{"20000"},
+				"audit-webhook-batch-max-size":       []interface{}{"500"},
+				"audit-webhook-batch-max-wait":       []interface{}{"10s"},
+				"audit-webhook-batch-throttle-qps":   []interface{}{"20"},
+				"audit-webhook-batch-throttle-burst": []interface{}{"30"},
+			}},
+			expectedSynced: map[string]string{"secret/audit-webhook.openshift-kube-apiserver": "secret/siem-webhook.openshift-config"},
+		},
+		{
+			name:               "url",
+			operatorConfig:     "auditWebhook:\n  url: https://siem.example.com:8443/audit\n  clientCertSecret: siem-client\n",
+			renderedKubeConfig: auditWebhookKubeConfig,
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-webhook-config-file": []interface{}{"/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"},
+				"audit-webhook-mode":        []interface{}{"batch"},
+			}},
+			expectedSynced: map[string]string{"secret/audit-webhook.openshift-kube-apiserver": "secret/audit-webhook-kubeconfig.openshift-kube-apiserver-operator"},
+		},
+		{
+			name:           "url before the kubeconfig is rendered",
+			operatorConfig: "auditWebhook:\n  url: https://siem.example.com:8443/audit\n  clientCertSecret: siem-client\n",
+			kubeConfig:     auditWebhookKubeConfig,
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+		{
+			name:           "missing secret keeps the existing config",
+			operatorConfig: "auditWebhook:\n  kubeConfigSecret: siem-webhook\n  mode: blocking\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-webhook-config-file": []interface{}{"/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"},
+				"audit-webhook-mode":        []interface{}{"batch"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"audit-webhook-config-file": []interface{}{"/etc/kubernetes/static-pod-resources/secrets/audit-webhook/kubeConfig"},
+				"audit-webhook-mode":        []interface{}{"batch"},
+			}},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+		{
+			name:           "invalid kubeconfig",
+			operatorConfig: "auditWebhook:\n  kubeConfigSecret: siem-webhook\n",
+			kubeConfig:     "apiVersion: v1\nkind: Config\n",
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+		{
+			name:           "invalid operator config",
+			operatorConfig: "auditWebhook:\n  kubeConfigSecret: siem-webhook\n  mode: stream\n",
+			kubeConfig:     auditWebhookKubeConfig,
+			expectedConfig: map[string]interface{}{},
+			expectedSynced: map[string]string{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.kubeConfig) > 0 {
+				if err := secretIndexer.Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "siem-webhook"},
+					Data:       map[string][]byte{"kubeConfig": []byte(scenario.kubeConfig)},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(scenario.renderedKubeConfig) > 0 {
+				if err := secretIndexer.Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver-operator", Name: "audit-webhook-kubeconfig"},
+					Data:       map[string][]byte{"kubeConfig": []byte(scenario.renderedKubeConfig)},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			synced := map[string]string{}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				ConfigSecretLister_:   corev1listers.NewSecretLister(secretIndexer),
+				OperatorSecretLister:  corev1listers.NewSecretLister(secretIndexer),
+				ResourceSync:          &mockResourceSyncer{t: t, synced: synced},
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveAuditWebhook(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			if !cmp.Equal(scenario.expectedSynced, synced) {
+				t.Fatalf("unexpected synced resources, diff = %v", cmp.Diff(scenario.expectedSynced, synced))
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// connectivityChecksPath is not part of the kube-apiserver config, it is read by the connectivity check controller.
+var connectivityChecksPath = []string{"connectivityChecks"}
+
+// ObserveConnectivityChecks observes the additional connectivity check targets in the operator config.
+func ObserveConnectivityChecks(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, connectivityChecksPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateConnectivityChecks(operatorConfig.ConnectivityChecks, field.NewPath("connectivityChecks")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveConnectivityChecksFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedChecks, err := toUnstructured(operatorConfig.ConnectivityChecks)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedChecks) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedChecks, connectivityChecksPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentChecks, _, _ := unstructured.NestedMap(existingConfig, connectivityChecksPath...)
+	if (len(currentChecks) > 0 || len(observedChecks) > 0) && !equality.Semantic.DeepEqual(currentChecks, observedChecks) {
+		recorder.Eventf("ObserveConnectivityChecks", "connectivity checks config changed to %v", observedChecks)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,92 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveConnectivityChecks(t *testing.T) {
+	checksConfig := map[string]interface{}{"connectivityChecks": map[string]interface{}{
+		"additionalTargets": []interface{}{
+			map[string]interface{}{"name": "oidc-provider", "endpoint": "sso.example.com:443"},
+			map[string]interface{}{"name": "kms", "endpoint": "10.0.0.5:5696", "tlsClientCert": "kms-client"},
+		},
+	}}
+
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "unset",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name: "additional targets",
+			operatorConfig: `connectivityChecks:
+  additionalTargets:
+  - name: oidc-provider
+    endpoint: sso.example.com:443
+  - name: kms
+    endpoint: 10.0.0.5:5696
+    tlsClientCert: kms-client
+`,
+			expectedConfig: checksConfig,
+		},
+		{
+			name:           "removed",
+			existingConfig: checksConfig,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "invalid config keeps the existing config",
+			operatorConfig: "connectivityChecks:\n  additionalTargets:\n  - name: kms\n    endpoint: 10.0.0.5\n",
+			existingConfig: checksConfig,
+			expectedConfig: checksConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveConnectivityChecks(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
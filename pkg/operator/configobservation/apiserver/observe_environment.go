@@ -0,0 +1,94 @@
+package apiserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// environmentPath is not part of the kube-apiserver config, it is read by the target config controller to set the
+// environment of the kube-apiserver container and pruned from the config.yaml of the kube-apiserver.
+var environmentPath = []string{"environment"}
+
+// ObserveEnvironment observes the environment variables of the operator config. Invalid variables, e.g. ones that are
+// no longer allowed after an upgrade, clear the whole environment instead of degrading the operator.
+func ObserveEnvironment(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, environmentPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	environment := operatorConfig.Environment
+	if validationErrs := operatorconfig.ValidateEnvironment(environment, field.NewPath("environment")); len(validationErrs) > 0 {
+		recorder.Warningf("ObserveEnvironmentFailed", "invalid operator config, clearing the kube-apiserver environment: %v", validationErrs.ToAggregate())
+		environment = nil
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(environment) > 0 {
+		if err := unstructured.SetNestedStringMap(observedConfig, environment, environmentPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentEnvironment, _, _ := unstructured.NestedStringMap(existingConfig, environmentPath...)
+	if (len(currentEnvironment) > 0 || len(environment) > 0) && !equality.Semantic.DeepEqual(currentEnvironment, environment) {
+		recorder.Eventf("ObserveEnvironment", "kube-apiserver environment changed to %s", strings.Join(describeEnvironment(environment), " "))
+	}
+
+	return observedConfig, errs
+}
+
+// describeEnvironment returns the environment as sorted name=value pairs.
+func describeEnvironment(environment map[string]string) []string {
+	var vars []string
+	for name, value := range environment {
+		vars = append(vars, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// NewEnvironmentCondition returns the APIServerEnvironment condition with the environment of the observed config, or
+// why the environment of the operator config is not applied.
+func NewEnvironmentCondition(observedConfig map[string]interface{}, operatorConfig *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+	if validationErrs := operatorconfig.ValidateEnvironment(operatorConfig.Environment, field.NewPath("environment")); len(validationErrs) > 0 {
+		return operatorv1.OperatorCondition{
+			Type:    "APIServerEnvironment",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidEnvironment",
+			Message: fmt.Sprintf("the environment of the operator config is not applied: %v", validationErrs.ToAggregate()),
+		}
+	}
+	environment, _, _ := unstructured.NestedStringMap(observedConfig, environmentPath...)
+	if len(environment) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   "APIServerEnvironment",
+			Status: operatorv1.ConditionFalse,
+			Reason: "NoEnvironment",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "APIServerEnvironment",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "EnvironmentApplied",
+		Message: fmt.Sprintf("the kube-apiserver runs with %s", strings.Join(describeEnvironment(environment), " ")),
+	}
+}
@@ -0,0 +1,83 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestObserveEnvironment(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectedReason string
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+			expectedReason: "NoEnvironment",
+		},
+		{
+			name:           "allowed variables",
+			operatorConfig: "environment:\n  GOGC: \"200\"\n  GOMEMLIMIT: 6GiB\n",
+			expectedConfig: map[string]interface{}{"environment": map[string]interface{}{"GOGC": "200", "GOMEMLIMIT": "6GiB"}},
+			expectedReason: "EnvironmentApplied",
+		},
+		{
+			name:           "invalid variables clear the environment",
+			operatorConfig: "environment:\n  GOGC: \"200\"\n  GOFLAGS: -mod=vendor\n",
+			existingConfig: map[string]interface{}{"environment": map[string]interface{}{"GOGC": "200"}},
+			expectedConfig: map[string]interface{}{},
+			expectedReason: "InvalidEnvironment",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveEnvironment(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if condition := NewEnvironmentCondition(observedConfig, operatorConfig); condition.Reason != scenario.expectedReason {
+				t.Fatalf("expected APIServerEnvironment with reason %s, got %v", scenario.expectedReason, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var (
+	etcdHealthcheckTimeoutPath    = []string{"apiServerArguments", "etcd-healthcheck-timeout"}
+	etcdCompactionIntervalPath    = []string{"apiServerArguments", "etcd-compaction-interval"}
+	etcdCountMetricPollPeriodPath = []string{"apiServerArguments", "etcd-count-metric-poll-period"}
+	etcdDBMetricPollIntervalPath  = []string{"apiServerArguments", "etcd-db-metric-poll-interval"}
+)
+
+// ObserveEtcdOptions sets the etcd health check timeout and the storage backend polling intervals from the etcd
+// section of the operator config. Unset values keep the kube-apiserver defaults.
+func ObserveEtcdOptions(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, etcdHealthcheckTimeoutPath, etcdCompactionIntervalPath, etcdCountMetricPollPeriodPath, etcdDBMetricPollIntervalPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	etcdConfig := operatorConfig.Etcd
+	if validationErrs := operatorconfig.ValidateEtcdConfig(etcdConfig, field.NewPath("etcd")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveEtcdOptionsFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	for _, option := range []struct {
+		path  []string
+		value string
+	}{
+		{path: etcdHealthcheckTimeoutPath, value: etcdConfig.HealthcheckTimeout},
+		{path: etcdCompactionIntervalPath, value: etcdConfig.CompactionInterval},
+		{path: etcdCountMetricPollPeriodPath, value: etcdConfig.CountMetricPollPeriod},
+		{path: etcdDBMetricPollIntervalPath, value: etcdConfig.DBMetricPollInterval},
+	} {
+		var observed []string
+		if len(option.value) > 0 {
+			observed = []string{option.value}
+			if err := unstructured.SetNestedStringSlice(observedConfig, observed, option.path...); err != nil {
+				return existingConfig, append(errs, err)
+			}
+		}
+
+		current, _, _ := unstructured.NestedStringSlice(existingConfig, option.path...)
+		if !equality.Semantic.DeepEqual(current, observed) {
+			recorder.Eventf("ObserveEtcdOptions", "%s changed to %v", option.path[len(option.path)-1], observed)
+		}
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,99 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveEtcdOptions(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "all options",
+			operatorConfig: "etcd:\n  healthcheckTimeout: 10s\n  compactionInterval: 10m\n  countMetricPollPeriod: 0s\n  dbMetricPollInterval: 1m\n",
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-healthcheck-timeout":      []interface{}{"10s"},
+				"etcd-compaction-interval":      []interface{}{"10m"},
+				"etcd-count-metric-poll-period": []interface{}{"0s"},
+				"etcd-db-metric-poll-interval":  []interface{}{"1m"},
+			}},
+		},
+		{
+			name:           "unset options are removed",
+			operatorConfig: "etcd:\n  healthcheckTimeout: 5s\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-healthcheck-timeout": []interface{}{"10s"},
+				"etcd-compaction-interval": []interface{}{"10m"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-healthcheck-timeout": []interface{}{"5s"},
+			}},
+		},
+		{
+			name:           "compaction cannot be disabled",
+			operatorConfig: "etcd:\n  compactionInterval: 0s\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-compaction-interval": []interface{}{"10m"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"etcd-compaction-interval": []interface{}{"10m"},
+			}},
+			expectError: true,
+		},
+		{
+			name:           "invalid duration",
+			operatorConfig: "etcd:\n  healthcheckTimeout: ten\n",
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveEtcdOptions(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// hostPathMountsPath is not part of the kube-apiserver config, it is read by the target config controller to mount
+// the host paths into the kube-apiserver container.
+var hostPathMountsPath = []string{"hostPathMounts"}
+
+// ObserveHostPathMounts observes the host path mounts of the operator config. Their volume names must not collide
+// with the volumes of the static pod and of the sidecars.
+func ObserveHostPathMounts(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, hostPathMountsPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	usedVolumes := operatorconfig.StaticPodVolumes.Union(nil)
+	for _, volume := range operatorConfig.Sidecars.Volumes {
+		usedVolumes.Insert(volume.Name)
+	}
+	if validationErrs := operatorconfig.ValidateHostPathMounts(operatorConfig.HostPathMounts, usedVolumes, field.NewPath("hostPathMounts")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveHostPathMountsFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	var observedMounts []interface{}
+	for _, mount := range operatorConfig.HostPathMounts {
+		observedMount, err := toUnstructured(mount)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+		observedMounts = append(observedMounts, observedMount)
+	}
+	if len(observedMounts) > 0 {
+		if err := unstructured.SetNestedSlice(observedConfig, observedMounts, hostPathMountsPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentMounts, _, _ := unstructured.NestedSlice(existingConfig, hostPathMountsPath...)
+	if !equality.Semantic.DeepEqual(currentMounts, observedMounts) {
+		var hostPaths []string
+		for _, mount := range operatorConfig.HostPathMounts {
+			hostPaths = append(hostPaths, mount.HostPath)
+		}
+		recorder.Eventf("ObserveHostPathMounts", "kube-apiserver host path mounts changed to %v", hostPaths)
+	}
+
+	return observedConfig, errs
+}
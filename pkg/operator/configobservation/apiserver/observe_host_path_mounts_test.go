@@ -0,0 +1,89 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveHostPathMounts(t *testing.T) {
+	kmsConfig := map[string]interface{}{"hostPathMounts": []interface{}{
+		map[string]interface{}{"name": "kms", "hostPath": "/var/run/kmsplugin", "mountPath": "/var/run/kmsplugin", "type": "Directory"},
+	}}
+
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "unset",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "kms plugin socket",
+			operatorConfig: "hostPathMounts:\n- name: kms\n  hostPath: /var/run/kmsplugin\n  mountPath: /var/run/kmsplugin\n  type: Directory\n",
+			expectedConfig: kmsConfig,
+		},
+		{
+			name:           "removed",
+			existingConfig: kmsConfig,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "collision with a sidecar volume keeps the existing config",
+			operatorConfig: "hostPathMounts:\n- name: kms\n  hostPath: /var/run/kmsplugin\n  mountPath: /var/run/kmsplugin\nsidecars:\n  volumes:\n  - name: kms\n    emptyDir: {}\n",
+			existingConfig: kmsConfig,
+			expectedConfig: kmsConfig,
+			expectError:    true,
+		},
+		{
+			name:           "managed host path keeps the existing config",
+			operatorConfig: "hostPathMounts:\n- name: kubelet\n  hostPath: /var/lib/kubelet\n  mountPath: /var/lib/kubelet\n",
+			existingConfig: kmsConfig,
+			expectedConfig: kmsConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveHostPathMounts(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// insecureReadyzPath is not part of the kube-apiserver config, it is read by the target config controller to set the
+// port of the insecure-readyz sidecar or to remove it.
+var insecureReadyzPath = []string{"insecureReadyz"}
+
+// ObserveInsecureReadyz observes the insecure-readyz sidecar settings of the operator config. The sidecar is kept
+// when something depends on it, in that case the refusal is reported as an error.
+func ObserveInsecureReadyz(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, insecureReadyzPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateInsecureReadyz(operatorConfig.InsecureReadyz, field.NewPath("insecureReadyz")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveInsecureReadyzFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	insecureReadyz := operatorConfig.InsecureReadyz
+	if insecureReadyz.Disabled {
+		if err := insecureReadyzCanBeDisabled(listers, operatorConfig); err != nil {
+			// removing the sidecar now would break the health checks, keep it on its default port
+			err = fmt.Errorf("the insecure-readyz sidecar cannot be disabled: %v", err)
+			recorder.Warningf("ObserveInsecureReadyzFailed", err.Error())
+			insecureReadyz = operatorconfig.InsecureReadyzConfig{}
+			errs = append(errs, err)
+		}
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedInsecureReadyz, err := toUnstructured(insecureReadyz)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedInsecureReadyz) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedInsecureReadyz, insecureReadyzPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentInsecureReadyz, _, _ := unstructured.NestedMap(existingConfig, insecureReadyzPath...)
+	if (len(currentInsecureReadyz) > 0 || len(observedInsecureReadyz) > 0) && !equality.Semantic.DeepEqual(currentInsecureReadyz, observedInsecureReadyz) {
+		recorder.Eventf("ObserveInsecureReadyz", "kube-apiserver insecure-readyz sidecar changed to %v", observedInsecureReadyz)
+	}
+
+	return observedConfig, errs
+}
+
+// insecureReadyzCanBeDisabled checks that neither the load balancers of the platform nor the kubelet probes go through
+// the insecure-readyz sidecar.
+func insecureReadyzCanBeDisabled(listers configobservation.Listers, operatorConfig *operatorconfig.KubeAPIServerOperatorConfig) error {
+	if operatorConfig.AnonymousAuth == operatorconfig.AnonymousAuthDisabled {
+		return fmt.Errorf("anonymousAuth is %s, the kubelet probes go through the sidecar", operatorconfig.AnonymousAuthDisabled)
+	}
+
+	infrastructure, err := listers.InfrastructureLister().Get("cluster")
+	if err != nil {
+		return err
+	}
+	if infrastructure.Status.PlatformStatus != nil && infrastructure.Status.PlatformStatus.Type == configv1.GCPPlatformType {
+		return fmt.Errorf("the load balancers of platform %q health check the sidecar", configv1.GCPPlatformType)
+	}
+
+	return nil
+}
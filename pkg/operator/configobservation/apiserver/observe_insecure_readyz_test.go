@@ -0,0 +1,110 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveInsecureReadyz(t *testing.T) {
+	disabledConfig := map[string]interface{}{"insecureReadyz": map[string]interface{}{"disabled": true}}
+
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		platform       configv1.PlatformType
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "default",
+			platform:       configv1.AWSPlatformType,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "port",
+			operatorConfig: "insecureReadyz:\n  port: 16080\n",
+			platform:       configv1.AWSPlatformType,
+			expectedConfig: map[string]interface{}{"insecureReadyz": map[string]interface{}{"port": float64(16080)}},
+		},
+		{
+			name:           "disabled",
+			operatorConfig: "insecureReadyz:\n  disabled: true\n",
+			platform:       configv1.AWSPlatformType,
+			expectedConfig: disabledConfig,
+		},
+		{
+			name:           "GCP health checks the sidecar",
+			operatorConfig: "insecureReadyz:\n  disabled: true\n",
+			platform:       configv1.GCPPlatformType,
+			existingConfig: disabledConfig,
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+		{
+			name:           "the kubelet probes go through the sidecar without anonymous auth",
+			operatorConfig: "anonymousAuth: Disabled\ninsecureReadyz:\n  disabled: true\n",
+			platform:       configv1.NonePlatformType,
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+		{
+			name:           "invalid port keeps the existing config",
+			operatorConfig: "insecureReadyz:\n  port: 6443\n",
+			platform:       configv1.AWSPlatformType,
+			existingConfig: disabledConfig,
+			expectedConfig: disabledConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			infrastructureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := infrastructureIndexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: scenario.platform}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+				InfrastructureLister_: configlistersv1.NewInfrastructureLister(infrastructureIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveInsecureReadyz(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// installerPath is not part of the kube-apiserver config, it is read when the installer pods are created.
+var installerPath = []string{"installer"}
+
+// ObserveInstaller observes the retry policy and the pod settings of the installer pods in the operator config.
+func ObserveInstaller(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, installerPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateInstaller(operatorConfig.Installer, field.NewPath("installer")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveInstallerFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedInstaller, err := toUnstructured(operatorConfig.Installer)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedInstaller) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedInstaller, installerPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentInstaller, _, _ := unstructured.NestedMap(existingConfig, installerPath...)
+	if (len(currentInstaller) > 0 || len(observedInstaller) > 0) && !equality.Semantic.DeepEqual(currentInstaller, observedInstaller) {
+		recorder.Eventf("ObserveInstaller", "installer config changed to %v", observedInstaller)
+	}
+
+	return observedConfig, errs
+}
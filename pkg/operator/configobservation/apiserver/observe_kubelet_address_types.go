@@ -0,0 +1,52 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var kubeletPreferredAddressTypesPath = []string{"apiServerArguments", "kubelet-preferred-address-types"}
+
+// ObserveKubeletPreferredAddressTypes sets kubelet-preferred-address-types from the operator config. Without a value
+// in the operator config the default from defaultconfig.yaml (InternalIP) applies.
+func ObserveKubeletPreferredAddressTypes(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, kubeletPreferredAddressTypesPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	addressTypes := operatorConfig.KubeletPreferredAddressTypes
+	if validationErrs := operatorconfig.ValidateKubeletPreferredAddressTypes(addressTypes, field.NewPath("kubeletPreferredAddressTypes")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveKubeletPreferredAddressTypesFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(addressTypes) > 0 {
+		if err := unstructured.SetNestedStringSlice(observedConfig, addressTypes, kubeletPreferredAddressTypesPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentAddressTypes, _, _ := unstructured.NestedStringSlice(existingConfig, kubeletPreferredAddressTypesPath...)
+	if !equality.Semantic.DeepEqual(currentAddressTypes, addressTypes) {
+		recorder.Eventf("ObserveKubeletPreferredAddressTypes", "kubelet-preferred-address-types changed to %v", addressTypes)
+	}
+
+	return observedConfig, errs
+}
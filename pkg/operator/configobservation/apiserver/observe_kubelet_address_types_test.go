@@ -0,0 +1,85 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveKubeletPreferredAddressTypes(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "address types are kept in order",
+			operatorConfig: "kubeletPreferredAddressTypes:\n- Hostname\n- InternalIP\n",
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"kubelet-preferred-address-types": []interface{}{"Hostname", "InternalIP"},
+			}},
+		},
+		{
+			name:           "unsupported address type keeps the existing config",
+			operatorConfig: "kubeletPreferredAddressTypes:\n- LegacyHostIP\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"kubelet-preferred-address-types": []interface{}{"InternalDNS"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"kubelet-preferred-address-types": []interface{}{"InternalDNS"},
+			}},
+			expectError: true,
+		},
+		{
+			name:           "duplicate address type",
+			operatorConfig: "kubeletPreferredAddressTypes:\n- InternalIP\n- InternalIP\n",
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveKubeletPreferredAddressTypes(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
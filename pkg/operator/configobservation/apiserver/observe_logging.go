@@ -0,0 +1,65 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// loggingPath is not part of the kube-apiserver config, it is read by the target config controller to render the
+// verbosity of the static pod containers and pruned from the config.yaml of the kube-apiserver.
+var loggingPath = []string{"logging"}
+
+// ObserveLogging copies the per container log levels and the vmodule of the operator config into the observed config.
+func ObserveLogging(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, loggingPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateLoggingConfig(operatorConfig.Logging, field.NewPath("logging")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveLoggingFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	logging := map[string]interface{}{}
+	for key, value := range map[string]string{
+		"kubeAPIServer":  string(operatorConfig.Logging.KubeAPIServer),
+		"certSyncer":     string(operatorConfig.Logging.CertSyncer),
+		"checkEndpoints": string(operatorConfig.Logging.CheckEndpoints),
+		"insecureReadyz": string(operatorConfig.Logging.InsecureReadyz),
+		"vmodule":        operatorConfig.Logging.VModule,
+	} {
+		if len(value) > 0 {
+			logging[key] = value
+		}
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(logging) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, logging, loggingPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentLogging, _, _ := unstructured.NestedMap(existingConfig, loggingPath...)
+	if (len(currentLogging) > 0 || len(logging) > 0) && !equality.Semantic.DeepEqual(currentLogging, logging) {
+		recorder.Eventf("ObserveLogging", "logging changed to %v", logging)
+	}
+
+	return observedConfig, errs
+}
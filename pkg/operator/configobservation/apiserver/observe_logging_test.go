@@ -0,0 +1,90 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveLogging(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "log levels and vmodule are observed",
+			operatorConfig: "logging:\n  kubeAPIServer: Debug\n  certSyncer: Trace\n  vmodule: httplog=4,rest*=6\n",
+			expectedConfig: map[string]interface{}{"logging": map[string]interface{}{
+				"kubeAPIServer": "Debug",
+				"certSyncer":    "Trace",
+				"vmodule":       "httplog=4,rest*=6",
+			}},
+		},
+		{
+			name:           "logging removed",
+			operatorConfig: "logging: {}\n",
+			existingConfig: map[string]interface{}{"logging": map[string]interface{}{"kubeAPIServer": "Debug"}},
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "unsupported log level keeps the existing config",
+			operatorConfig: "logging:\n  checkEndpoints: Verbose\n",
+			existingConfig: map[string]interface{}{"logging": map[string]interface{}{"checkEndpoints": "Debug"}},
+			expectedConfig: map[string]interface{}{"logging": map[string]interface{}{"checkEndpoints": "Debug"}},
+			expectError:    true,
+		},
+		{
+			name:           "malformed vmodule keeps the existing config",
+			operatorConfig: "logging:\n  vmodule: httplog=4;rm -rf\n",
+			existingConfig: map[string]interface{}{},
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveLogging(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// nonRootPath is not part of the kube-apiserver config, it is read by the target config controller to run the
+// containers of the static pod as a non-root user.
+var nonRootPath = []string{"nonRoot"}
+
+// ObserveNonRoot observes the tech preview nonRoot of the operator config. It is only applied with the
+// TechPreviewNoUpgrade feature set.
+func ObserveNonRoot(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, nonRootPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	currentNonRoot, _, _ := unstructured.NestedMap(existingConfig, nonRootPath...)
+	if operatorConfig.NonRoot == nil {
+		if len(currentNonRoot) > 0 {
+			recorder.Eventf("ObserveNonRoot", "kube-apiserver runs as root again")
+		}
+		return map[string]interface{}{}, errs
+	}
+
+	featureGate, err := listers.FeatureGateLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	if featureGate == nil || featureGate.Spec.FeatureSet != configv1.TechPreviewNoUpgrade {
+		err := fmt.Errorf("nonRoot of the operator config requires the %s feature set", configv1.TechPreviewNoUpgrade)
+		recorder.Warningf("ObserveNonRootFailed", err.Error())
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	if validationErrs := operatorconfig.ValidateNonRoot(operatorConfig.NonRoot, field.NewPath("nonRoot")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveNonRootFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedNonRoot, err := toUnstructured(operatorConfig.NonRoot)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedMap(observedConfig, observedNonRoot, nonRootPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if !equality.Semantic.DeepEqual(currentNonRoot, observedNonRoot) {
+		recorder.Eventf("ObserveNonRoot", "kube-apiserver runs as uid %d", operatorConfig.NonRoot.UID)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveNonRoot(t *testing.T) {
+	nonRootConfig := map[string]interface{}{"nonRoot": map[string]interface{}{"uid": float64(1001)}}
+
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		featureSet     configv1.FeatureSet
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "unset",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "non-root",
+			operatorConfig: "nonRoot:\n  uid: 1001\n",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			expectedConfig: nonRootConfig,
+		},
+		{
+			name:           "removed",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			existingConfig: nonRootConfig,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "tech preview is required",
+			operatorConfig: "nonRoot:\n  uid: 1001\n",
+			existingConfig: nonRootConfig,
+			expectedConfig: map[string]interface{}{},
+			expectError:    true,
+		},
+		{
+			name:           "root keeps the existing config",
+			operatorConfig: "nonRoot:\n  uid: 0\n",
+			featureSet:     configv1.TechPreviewNoUpgrade,
+			existingConfig: nonRootConfig,
+			expectedConfig: nonRootConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			featureGateIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := featureGateIndexer.Add(&configv1.FeatureGate{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: scenario.featureSet}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+				FeatureGateLister_:    configlistersv1.NewFeatureGateLister(featureGateIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveNonRoot(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
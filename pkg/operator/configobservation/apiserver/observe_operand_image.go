@@ -0,0 +1,88 @@
+package apiserver
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// operandImagePath is not part of the kube-apiserver config, it is read by the target config controller to replace
+// the kube-apiserver image of the static pod.
+var operandImagePath = []string{"operandImage"}
+
+// ObserveOperandImage observes the tech preview operandImage of the operator config. The image is only applied with
+// the TechPreviewNoUpgrade feature set.
+func ObserveOperandImage(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, operandImagePath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	currentImage, _, _ := unstructured.NestedString(existingConfig, operandImagePath...)
+	if len(operatorConfig.OperandImage) == 0 {
+		if len(currentImage) > 0 {
+			recorder.Eventf("ObserveOperandImage", "kube-apiserver image override removed")
+		}
+		return map[string]interface{}{}, errs
+	}
+
+	featureGate, err := listers.FeatureGateLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	if featureGate == nil || featureGate.Spec.FeatureSet != configv1.TechPreviewNoUpgrade {
+		err := fmt.Errorf("operandImage of the operator config requires the %s feature set", configv1.TechPreviewNoUpgrade)
+		recorder.Warningf("ObserveOperandImageFailed", err.Error())
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	if validationErrs := operatorconfig.ValidateOperandImage(operatorConfig.OperandImage, field.NewPath("operandImage")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveOperandImageFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedField(observedConfig, operatorConfig.OperandImage, operandImagePath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if currentImage != operatorConfig.OperandImage {
+		recorder.Eventf("ObserveOperandImage", "kube-apiserver image overridden with %s", operatorConfig.OperandImage)
+	}
+
+	return observedConfig, errs
+}
+
+// NewOperandImageUpgradeableCondition returns the OperandImageOverrideUpgradeable condition of the observed config.
+// While an image is applied it is false, an upgrade would silently keep the development image.
+func NewOperandImageUpgradeableCondition(observedConfig map[string]interface{}, _ *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+	image, _, _ := unstructured.NestedString(observedConfig, operandImagePath...)
+	if len(image) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   "OperandImageOverrideUpgradeable",
+			Status: operatorv1.ConditionTrue,
+			Reason: "AsExpected",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "OperandImageOverrideUpgradeable",
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "OperandImageOverridden",
+		Message: fmt.Sprintf("the kube-apiserver image is overridden with %s", image),
+	}
+}
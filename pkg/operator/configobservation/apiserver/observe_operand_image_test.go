@@ -0,0 +1,121 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveOperandImage(t *testing.T) {
+	devImageConfig := map[string]interface{}{"operandImage": "quay.io/user/hyperkube:dev"}
+	overriddenCondition := operatorv1.OperatorCondition{
+		Type:    "OperandImageOverrideUpgradeable",
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "OperandImageOverridden",
+		Message: "the kube-apiserver image is overridden with quay.io/user/hyperkube:dev",
+	}
+	upgradeableCondition := operatorv1.OperatorCondition{Type: "OperandImageOverrideUpgradeable", Status: operatorv1.ConditionTrue, Reason: "AsExpected"}
+
+	scenarios := []struct {
+		name              string
+		operatorConfig    string
+		featureSet        configv1.FeatureSet
+		existingConfig    map[string]interface{}
+		expectedConfig    map[string]interface{}
+		expectedCondition operatorv1.OperatorCondition
+		expectError       bool
+	}{
+		{
+			name:              "no override",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: upgradeableCondition,
+		},
+		{
+			name:              "override applied",
+			operatorConfig:    "operandImage: quay.io/user/hyperkube:dev\n",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			expectedConfig:    devImageConfig,
+			expectedCondition: overriddenCondition,
+		},
+		{
+			name:              "override removed",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			existingConfig:    devImageConfig,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: upgradeableCondition,
+		},
+		{
+			name:              "tech preview is required",
+			operatorConfig:    "operandImage: quay.io/user/hyperkube:dev\n",
+			existingConfig:    devImageConfig,
+			expectedConfig:    map[string]interface{}{},
+			expectedCondition: upgradeableCondition,
+			expectError:       true,
+		},
+		{
+			name:              "invalid image keeps the existing override",
+			operatorConfig:    "operandImage: hyperkube\n",
+			featureSet:        configv1.TechPreviewNoUpgrade,
+			existingConfig:    devImageConfig,
+			expectedConfig:    devImageConfig,
+			expectedCondition: overriddenCondition,
+			expectError:       true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			featureGateIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := featureGateIndexer.Add(&configv1.FeatureGate{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: scenario.featureSet}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+				FeatureGateLister_:    configlistersv1.NewFeatureGateLister(featureGateIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observe := ObserveOperandImage
+			observedConfig, errs := observe(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			condition := NewOperandImageUpgradeableCondition(observedConfig, nil)
+			if !cmp.Equal(scenario.expectedCondition, condition) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(scenario.expectedCondition, condition))
+			}
+		})
+	}
+}
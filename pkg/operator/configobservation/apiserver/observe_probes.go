@@ -0,0 +1,104 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// probesPath is not part of the kube-apiserver config, it is read by the target config controller to set the probes
+// of the kube-apiserver container and pruned from the config.yaml of the kube-apiserver.
+var probesPath = []string{"probes"}
+
+// singleReplicaProbes are the defaults for the SingleReplica control plane topology. A restart of the only
+// kube-apiserver is an outage of the cluster, so the liveness probe tolerates longer failures.
+var singleReplicaProbes = operatorconfig.ProbesConfig{
+	Liveness: &operatorconfig.ProbeConfig{FailureThreshold: pointer.Int32(6)},
+}
+
+// ObserveProbes combines the topology defaults with the probes of the operator config. Values of the operator
+// config take precedence over the defaults.
+func ObserveProbes(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, probesPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	infra, err := listers.InfrastructureLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateProbes(operatorConfig.Probes, field.NewPath("probes")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveProbesFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	probes := operatorconfig.ProbesConfig{}
+	if infra != nil && infra.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode {
+		probes = singleReplicaProbes
+	}
+	probes = operatorconfig.ProbesConfig{
+		Liveness:  mergeProbe(probes.Liveness, operatorConfig.Probes.Liveness),
+		Readiness: mergeProbe(probes.Readiness, operatorConfig.Probes.Readiness),
+		Startup:   mergeProbe(probes.Startup, operatorConfig.Probes.Startup),
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedProbes, err := toUnstructured(probes)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedProbes) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedProbes, probesPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentProbes, _, _ := unstructured.NestedMap(existingConfig, probesPath...)
+	if (len(currentProbes) > 0 || len(observedProbes) > 0) && !equality.Semantic.DeepEqual(currentProbes, observedProbes) {
+		recorder.Eventf("ObserveProbes", "kube-apiserver probes changed to %v", observedProbes)
+	}
+
+	return observedConfig, errs
+}
+
+// mergeProbe returns the defaults with the set values of override applied on top of them.
+func mergeProbe(defaults, override *operatorconfig.ProbeConfig) *operatorconfig.ProbeConfig {
+	if defaults == nil {
+		return override
+	}
+	if override == nil {
+		return defaults
+	}
+	merged := *defaults
+	if override.InitialDelaySeconds != nil {
+		merged.InitialDelaySeconds = override.InitialDelaySeconds
+	}
+	if override.PeriodSeconds != nil {
+		merged.PeriodSeconds = override.PeriodSeconds
+	}
+	if override.TimeoutSeconds != nil {
+		merged.TimeoutSeconds = override.TimeoutSeconds
+	}
+	if override.FailureThreshold != nil {
+		merged.FailureThreshold = override.FailureThreshold
+	}
+	return &merged
+}
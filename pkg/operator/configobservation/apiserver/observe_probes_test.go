@@ -0,0 +1,95 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveProbes(t *testing.T) {
+	scenarios := []struct {
+		name                 string
+		operatorConfig       string
+		controlPlaneTopology configv1.TopologyMode
+		existingConfig       map[string]interface{}
+		expectedConfig       map[string]interface{}
+		expectError          bool
+	}{
+		{
+			name:           "highly available without operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:                 "single replica defaults",
+			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
+			expectedConfig: map[string]interface{}{"probes": map[string]interface{}{
+				"liveness": map[string]interface{}{"failureThreshold": float64(6)},
+			}},
+		},
+		{
+			name:                 "operator config is merged with the defaults",
+			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
+			operatorConfig:       "probes:\n  liveness:\n    initialDelaySeconds: 90\n  startup:\n    periodSeconds: 10\n    failureThreshold: 30\n",
+			expectedConfig: map[string]interface{}{"probes": map[string]interface{}{
+				"liveness": map[string]interface{}{"initialDelaySeconds": float64(90), "failureThreshold": float64(6)},
+				"startup":  map[string]interface{}{"periodSeconds": float64(10), "failureThreshold": float64(30)},
+			}},
+		},
+		{
+			name:           "invalid operator config keeps the existing config",
+			operatorConfig: "probes:\n  readiness:\n    periodSeconds: 0\n",
+			existingConfig: map[string]interface{}{"probes": map[string]interface{}{"readiness": map[string]interface{}{"periodSeconds": float64(5)}}},
+			expectedConfig: map[string]interface{}{"probes": map[string]interface{}{"readiness": map[string]interface{}{"periodSeconds": float64(5)}}},
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			infrastructureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := infrastructureIndexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{ControlPlaneTopology: scenario.controlPlaneTopology},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configMapIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				InfrastructureLister_: configlistersv1.NewInfrastructureLister(infrastructureIndexer),
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveProbes(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+var profilingPath = []string{"apiServerArguments", "profiling"}
+
+// ObserveProfiling sets profiling=false when the operator config disables profiling. The target config controller
+// disables the debug endpoints of the check-endpoints sidecar along with it.
+func ObserveProfiling(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, profilingPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateProfiling(operatorConfig.Profiling, field.NewPath("profiling")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveProfilingFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	currentProfiling, _, _ := unstructured.NestedStringSlice(existingConfig, profilingPath...)
+	currentlyDisabled := len(currentProfiling) == 1 && currentProfiling[0] == "false"
+
+	if operatorConfig.Profiling != operatorconfig.ProfilingDisabled {
+		if currentlyDisabled {
+			recorder.Eventf("ObserveProfiling", "profiling changed to true")
+		}
+		return map[string]interface{}{}, errs
+	}
+
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedStringSlice(observedConfig, []string{"false"}, profilingPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if !currentlyDisabled {
+		recorder.Eventf("ObserveProfiling", "profiling changed to false")
+	}
+
+	return observedConfig, errs
+}
+
+// NewProfilingCondition returns the APIServerProfiling condition telling whether the observed config serves the debug
+// endpoints.
+func NewProfilingCondition(observedConfig map[string]interface{}, _ *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+	profiling, _, _ := unstructured.NestedStringSlice(observedConfig, profilingPath...)
+	if len(profiling) == 1 && profiling[0] == "false" {
+		return operatorv1.OperatorCondition{
+			Type:    "APIServerProfiling",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "ProfilingDisabled",
+			Message: "the kube-apiserver and the check-endpoints sidecar do not serve /debug/pprof and /debug/flags",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "APIServerProfiling",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ProfilingEnabled",
+		Message: "the kube-apiserver and the check-endpoints sidecar serve /debug/pprof and /debug/flags to authorized users",
+	}
+}
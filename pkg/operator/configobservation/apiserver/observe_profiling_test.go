@@ -0,0 +1,90 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveProfiling(t *testing.T) {
+	disabledConfig := map[string]interface{}{"apiServerArguments": map[string]interface{}{"profiling": []interface{}{"false"}}}
+
+	scenarios := []struct {
+		name            string
+		operatorConfig  string
+		existingConfig  map[string]interface{}
+		expectedConfig  map[string]interface{}
+		expectedEnabled bool
+		expectError     bool
+	}{
+		{
+			name:            "enabled by default",
+			expectedConfig:  map[string]interface{}{},
+			expectedEnabled: true,
+		},
+		{
+			name:           "disabled",
+			operatorConfig: "profiling: Disabled\n",
+			expectedConfig: disabledConfig,
+		},
+		{
+			name:            "enabled again",
+			operatorConfig:  "profiling: Enabled\n",
+			existingConfig:  disabledConfig,
+			expectedConfig:  map[string]interface{}{},
+			expectedEnabled: true,
+		},
+		{
+			name:           "invalid mode keeps the existing config",
+			operatorConfig: "profiling: Off\n",
+			existingConfig: disabledConfig,
+			expectedConfig: disabledConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observe := ObserveProfiling
+			observedConfig, errs := observe(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+			condition := NewProfilingCondition(observedConfig, nil)
+			if enabled := condition.Status == operatorv1.ConditionTrue; enabled != scenario.expectedEnabled {
+				t.Fatalf("expected profiling enabled %v, got condition %v", scenario.expectedEnabled, condition)
+			}
+		})
+	}
+}
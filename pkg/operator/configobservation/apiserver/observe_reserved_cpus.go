@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// reservedCPUsPath is not part of the kube-apiserver config, it is read by the target config controller to pin the
+// static pod containers and pruned from the config.yaml of the kube-apiserver.
+var reservedCPUsPath = []string{"reservedCPUs"}
+
+// ObserveReservedCPUs observes the reservedCPUs of the operator config on SingleReplica control planes.
+func ObserveReservedCPUs(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, reservedCPUsPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	infra, err := listers.InfrastructureLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	if infra == nil || infra.Status.ControlPlaneTopology != configv1.SingleReplicaTopologyMode {
+		return map[string]interface{}{}, errs
+	}
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateReservedCPUs(operatorConfig.ReservedCPUs, field.NewPath("reservedCPUs")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveReservedCPUsFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(operatorConfig.ReservedCPUs) > 0 {
+		if err := unstructured.SetNestedField(observedConfig, operatorConfig.ReservedCPUs, reservedCPUsPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentReservedCPUs, _, _ := unstructured.NestedString(existingConfig, reservedCPUsPath...)
+	if currentReservedCPUs != operatorConfig.ReservedCPUs {
+		recorder.Eventf("ObserveReservedCPUs", "reserved cpus changed from %q to %q", currentReservedCPUs, operatorConfig.ReservedCPUs)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,97 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// resourcesPath is not part of the kube-apiserver config, it is read by the target config controller to set the
+// resources of the static pod containers and pruned from the config.yaml of the kube-apiserver.
+var resourcesPath = []string{"resources"}
+
+// singleReplicaResources are the defaults for the SingleReplica control plane topology. A single node has no
+// other kube-apiserver to compete with for cpu shares, so the request of the pod template is lowered to leave
+// the reserved cpus of small edge nodes to the workloads.
+var singleReplicaResources = map[string]corev1.ResourceRequirements{
+	"kube-apiserver": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+}
+
+// ObserveResources combines the topology defaults with the resources of the operator config. A container listed
+// in the operator config replaces the defaults of that container.
+func ObserveResources(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, resourcesPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	infra, err := listers.InfrastructureLister().Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return existingConfig, append(errs, err)
+	}
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateResources(operatorConfig.Resources, field.NewPath("resources")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveResourcesFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	resources := map[string]corev1.ResourceRequirements{}
+	if infra != nil && infra.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode {
+		for container, requirements := range singleReplicaResources {
+			resources[container] = requirements
+		}
+	}
+	for container, requirements := range operatorConfig.Resources {
+		resources[container] = requirements
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(resources) > 0 {
+		observedResources, err := toUnstructured(resources)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+		if err := unstructured.SetNestedMap(observedConfig, observedResources, resourcesPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentResources, _, _ := unstructured.NestedMap(existingConfig, resourcesPath...)
+	observedResources, _, _ := unstructured.NestedMap(observedConfig, resourcesPath...)
+	if !equality.Semantic.DeepEqual(currentResources, observedResources) {
+		recorder.Eventf("ObserveResources", "static pod resources changed to %v", observedResources)
+	}
+
+	return observedConfig, errs
+}
+
+// toUnstructured converts obj into the json representation of the observed config.
+func toUnstructured(obj interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,56 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// rolloutPath is not part of the kube-apiserver config, it is read by the canary rollout controller and by the
+// installer pods of the nodes that wait for the canary.
+var rolloutPath = []string{"rollout"}
+
+// ObserveRollout observes the rollout strategy of the operator config.
+func ObserveRollout(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, rolloutPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateRollout(operatorConfig.Rollout, field.NewPath("rollout")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveRolloutFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedRollout, err := toUnstructured(operatorConfig.Rollout)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedRollout) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedRollout, rolloutPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentRollout, _, _ := unstructured.NestedMap(existingConfig, rolloutPath...)
+	if (len(currentRollout) > 0 || len(observedRollout) > 0) && !equality.Semantic.DeepEqual(currentRollout, observedRollout) {
+		recorder.Eventf("ObserveRollout", "rollout changed to %v", observedRollout)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,84 @@
+package apiserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// runtimeConfigPath is merged with the --runtime-config flag of the static pod which disables the deprecated beta APIs.
+var runtimeConfigPath = []string{"apiServerArguments", "runtime-config"}
+
+// ObserveRuntimeConfig enables the allowlisted API group versions listed in runtimeConfig of the operator config.
+func ObserveRuntimeConfig(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, runtimeConfigPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateRuntimeConfig(operatorConfig.RuntimeConfig, field.NewPath("runtimeConfig")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveRuntimeConfigFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	groupVersions := append([]string{}, operatorConfig.RuntimeConfig...)
+	sort.Strings(groupVersions)
+
+	observedConfig := map[string]interface{}{}
+	var runtimeConfig []string
+	for _, groupVersion := range groupVersions {
+		runtimeConfig = append(runtimeConfig, groupVersion+"=true")
+	}
+	if len(runtimeConfig) > 0 {
+		if err := unstructured.SetNestedStringSlice(observedConfig, runtimeConfig, runtimeConfigPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentRuntimeConfig, _, _ := unstructured.NestedStringSlice(existingConfig, runtimeConfigPath...)
+	if !equality.Semantic.DeepEqual(currentRuntimeConfig, runtimeConfig) {
+		recorder.Eventf("ObserveRuntimeConfig", "runtime-config changed to %v", runtimeConfig)
+	}
+
+	return observedConfig, errs
+}
+
+// NewRuntimeConfigUpgradeableCondition returns the RuntimeConfigUpgradeable condition of the observed config. As long
+// as any API group version is enabled it is false, these APIs are not guaranteed to survive an upgrade.
+func NewRuntimeConfigUpgradeableCondition(observedConfig map[string]interface{}, _ *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+	runtimeConfig, _, _ := unstructured.NestedStringSlice(observedConfig, runtimeConfigPath...)
+	var enabledGroupVersions []string
+	for _, value := range runtimeConfig {
+		enabledGroupVersions = append(enabledGroupVersions, strings.TrimSuffix(value, "=true"))
+	}
+	if len(enabledGroupVersions) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   "RuntimeConfigUpgradeable",
+			Status: operatorv1.ConditionTrue,
+			Reason: "AsExpected",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "RuntimeConfigUpgradeable",
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "NonDefaultAPIsEnabled",
+		Message: fmt.Sprintf("the following API group versions are enabled and do not allow updates: %s", strings.Join(enabledGroupVersions, ", ")),
+	}
+}
@@ -0,0 +1,105 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveRuntimeConfig(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "no operator config",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "enabled group versions are sorted",
+			operatorConfig: "runtimeConfig:\n- storage.k8s.io/v1alpha1\n- flowcontrol.apiserver.k8s.io/v1alpha1\n",
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"runtime-config": []interface{}{"flowcontrol.apiserver.k8s.io/v1alpha1=true", "storage.k8s.io/v1alpha1=true"},
+			}},
+		},
+		{
+			name:           "group versions removed",
+			operatorConfig: "runtimeConfig: []\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{"runtime-config": []interface{}{"storage.k8s.io/v1alpha1=true"}}},
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "group version not on the allowlist keeps the existing config",
+			operatorConfig: "runtimeConfig:\n- apiextensions.k8s.io/v1beta1\n",
+			existingConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"runtime-config": []interface{}{"storage.k8s.io/v1alpha1=true"},
+			}},
+			expectedConfig: map[string]interface{}{"apiServerArguments": map[string]interface{}{
+				"runtime-config": []interface{}{"storage.k8s.io/v1alpha1=true"},
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(indexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveRuntimeConfig(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
+
+func TestNewRuntimeConfigUpgradeableCondition(t *testing.T) {
+	if condition := NewRuntimeConfigUpgradeableCondition(map[string]interface{}{}, nil); condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected RuntimeConfigUpgradeable=True without enabled group versions, got %v", condition)
+	}
+
+	observedConfig := map[string]interface{}{"apiServerArguments": map[string]interface{}{
+		"runtime-config": []interface{}{"flowcontrol.apiserver.k8s.io/v1alpha1=true", "storage.k8s.io/v1alpha1=true"},
+	}}
+	expected := operatorv1.OperatorCondition{
+		Type:    "RuntimeConfigUpgradeable",
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "NonDefaultAPIsEnabled",
+		Message: "the following API group versions are enabled and do not allow updates: flowcontrol.apiserver.k8s.io/v1alpha1, storage.k8s.io/v1alpha1",
+	}
+	if condition := NewRuntimeConfigUpgradeableCondition(observedConfig, nil); !cmp.Equal(expected, condition) {
+		t.Errorf("unexpected condition, diff = %v", cmp.Diff(expected, condition))
+	}
+}
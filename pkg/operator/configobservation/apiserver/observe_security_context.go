@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// securityContextPath is not part of the kube-apiserver config, it is read by the target config controller to set the
+// pod security context of the static pod.
+var securityContextPath = []string{"securityContext"}
+
+// ObserveSecurityContext observes the seccomp profile and the SELinux options of the operator config. The seccomp
+// profile defaults to RuntimeDefault.
+func ObserveSecurityContext(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, securityContextPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateSecurityContext(operatorConfig.SecurityContext, field.NewPath("securityContext")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveSecurityContextFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	securityContext := operatorConfig.SecurityContext
+	if securityContext.SeccompProfile == nil {
+		securityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	observedSecurityContext, err := toUnstructured(securityContext)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedMap(observedConfig, observedSecurityContext, securityContextPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	currentSecurityContext, _, _ := unstructured.NestedMap(existingConfig, securityContextPath...)
+	if !equality.Semantic.DeepEqual(currentSecurityContext, observedSecurityContext) {
+		recorder.Eventf("ObserveSecurityContext", "kube-apiserver security context changed to %v", observedSecurityContext)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,90 @@
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveSecurityContext(t *testing.T) {
+	runtimeDefaultConfig := map[string]interface{}{"securityContext": map[string]interface{}{
+		"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+	}}
+	hardenedConfig := map[string]interface{}{"securityContext": map[string]interface{}{
+		"seccompProfile": map[string]interface{}{"type": "Localhost", "localhostProfile": "kube-apiserver.json"},
+		"seLinuxOptions": map[string]interface{}{"type": "kube_apiserver_t"},
+	}}
+
+	scenarios := []struct {
+		name           string
+		operatorConfig string
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:           "defaults to RuntimeDefault",
+			expectedConfig: runtimeDefaultConfig,
+		},
+		{
+			name:           "selinux options keep the default seccomp profile",
+			operatorConfig: "securityContext:\n  seLinuxOptions:\n    level: s0:c1,c2\n",
+			expectedConfig: map[string]interface{}{"securityContext": map[string]interface{}{
+				"seccompProfile": map[string]interface{}{"type": "RuntimeDefault"},
+				"seLinuxOptions": map[string]interface{}{"level": "s0:c1,c2"},
+			}},
+		},
+		{
+			name:           "localhost profile and selinux type",
+			operatorConfig: "securityContext:\n  seccompProfile:\n    type: Localhost\n    localhostProfile: kube-apiserver.json\n  seLinuxOptions:\n    type: kube_apiserver_t\n",
+			existingConfig: runtimeDefaultConfig,
+			expectedConfig: hardenedConfig,
+		},
+		{
+			name:           "invalid config keeps the existing config",
+			operatorConfig: "securityContext:\n  seccompProfile:\n    type: Localhost\n",
+			existingConfig: hardenedConfig,
+			expectedConfig: hardenedConfig,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			configIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if len(scenario.operatorConfig) > 0 {
+				if err := configIndexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+					Data:       map[string]string{"config.yaml": scenario.operatorConfig},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				ConfigConfigMapLister: corev1listers.NewConfigMapLister(configIndexer),
+			}
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observedConfig, errs := ObserveSecurityContext(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError != (len(errs) > 0) {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
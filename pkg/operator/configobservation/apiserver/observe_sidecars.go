@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// sidecarsPath is not part of the kube-apiserver config, it is read by the target config controller to add the
+// sidecars to the static pod and pruned from the config.yaml of the kube-apiserver.
+var sidecarsPath = []string{"sidecars"}
+
+// ObserveSidecars observes the sidecar containers and volumes of the operator config.
+func ObserveSidecars(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, sidecarsPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateSidecars(operatorConfig.Sidecars, field.NewPath("sidecars")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveSidecarsFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if len(operatorConfig.Sidecars.Containers) > 0 || len(operatorConfig.Sidecars.Volumes) > 0 {
+		sidecars, err := toUnstructured(operatorConfig.Sidecars)
+		if err != nil {
+			return existingConfig, append(errs, err)
+		}
+		if err := unstructured.SetNestedMap(observedConfig, sidecars, sidecarsPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentSidecars, _, _ := unstructured.NestedMap(existingConfig, sidecarsPath...)
+	observedSidecars, _, _ := unstructured.NestedMap(observedConfig, sidecarsPath...)
+	if !equality.Semantic.DeepEqual(currentSidecars, observedSidecars) {
+		var names []string
+		for _, container := range operatorConfig.Sidecars.Containers {
+			names = append(names, container.Name)
+		}
+		recorder.Eventf("ObserveSidecars", "kube-apiserver sidecars changed to %v", names)
+	}
+
+	return observedConfig, errs
+}
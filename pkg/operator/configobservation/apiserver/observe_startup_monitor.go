@@ -0,0 +1,56 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// startupMonitorPath is not part of the kube-apiserver config, it is read by the startup monitor enablement and by the
+// target config controller to configure the startup monitor pod.
+var startupMonitorPath = []string{"startupMonitor"}
+
+// ObserveStartupMonitor observes the startup monitor settings of the operator config.
+func ObserveStartupMonitor(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, startupMonitorPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateStartupMonitor(operatorConfig.StartupMonitor, field.NewPath("startupMonitor")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveStartupMonitorFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedStartupMonitor, err := toUnstructured(operatorConfig.StartupMonitor)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedStartupMonitor) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedStartupMonitor, startupMonitorPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentStartupMonitor, _, _ := unstructured.NestedMap(existingConfig, startupMonitorPath...)
+	if (len(currentStartupMonitor) > 0 || len(observedStartupMonitor) > 0) && !equality.Semantic.DeepEqual(currentStartupMonitor, observedStartupMonitor) {
+		recorder.Eventf("ObserveStartupMonitor", "startup monitor changed to %v", observedStartupMonitor)
+	}
+
+	return observedConfig, errs
+}
@@ -0,0 +1,55 @@
+package apiserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// staticPodDetectionPath is not part of the kube-apiserver config, it is read by the static pod detection.
+var staticPodDetectionPath = []string{"staticPodDetection"}
+
+// ObserveStaticPodDetection observes the detection timeouts of missing and failing static pods in the operator config.
+func ObserveStaticPodDetection(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, staticPodDetectionPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if validationErrs := operatorconfig.ValidateStaticPodDetection(operatorConfig.StaticPodDetection, field.NewPath("staticPodDetection")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveStaticPodDetectionFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	observedDetection, err := toUnstructured(operatorConfig.StaticPodDetection)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if len(observedDetection) > 0 {
+		if err := unstructured.SetNestedMap(observedConfig, observedDetection, staticPodDetectionPath...); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+
+	currentDetection, _, _ := unstructured.NestedMap(existingConfig, staticPodDetectionPath...)
+	if (len(currentDetection) > 0 || len(observedDetection) > 0) && !equality.Semantic.DeepEqual(currentDetection, observedDetection) {
+		recorder.Eventf("ObserveStaticPodDetection", "static pod detection config changed to %v", observedDetection)
+	}
+
+	return observedConfig, errs
+}
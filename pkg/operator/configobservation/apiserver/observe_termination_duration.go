@@ -2,12 +2,16 @@ package apiserver
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
 )
@@ -16,9 +20,30 @@ var shutdownDelayDurationPath = []string{"apiServerArguments", "shutdown-delay-d
 
 var gracefulTerminationDurationPath = []string{"gracefulTerminationDuration"}
 
+// terminationOverheadSeconds is added to a shutdown delay of the operator config to get the graceful termination
+// duration: 60s for in-flight requests and 5s for the process to exit, the same as the platform defaults.
+const terminationOverheadSeconds = 65
+
+// shutdownDelayFromOperatorConfig returns the shutdownDelayDuration of the operator config, nil if it is not set.
+func shutdownDelayFromOperatorConfig(listers configobservation.Listers) (*time.Duration, error) {
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return nil, err
+	}
+	if len(operatorConfig.ShutdownDelayDuration) == 0 {
+		return nil, nil
+	}
+	if validationErrs := operatorconfig.ValidateShutdownDelayDuration(operatorConfig.ShutdownDelayDuration, field.NewPath("shutdownDelayDuration")); len(validationErrs) > 0 {
+		return nil, fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+	}
+	shutdownDelay, _ := time.ParseDuration(operatorConfig.ShutdownDelayDuration)
+	return &shutdownDelay, nil
+}
+
 // ObserveShutdownDelayDuration allows for overwriting shutdown-delay-duration value.
 // It exists because the time needed for an LB to notice and remove unhealthy instances might vary by platform.
-func ObserveShutdownDelayDuration(genericListers configobserver.Listers, _ events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+// The shutdownDelayDuration of the operator config takes precedence over the platform defaults.
+func ObserveShutdownDelayDuration(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
 	defer func() {
 		// Prune the observed config so that it only contains shutdown-delay-duration field.
 		ret = configobserver.Pruned(ret, shutdownDelayDurationPath)
@@ -33,7 +58,15 @@ func ObserveShutdownDelayDuration(genericListers configobserver.Listers, _ event
 		return existingConfig, append(errs, err)
 	}
 
+	shutdownDelay, err := shutdownDelayFromOperatorConfig(listers)
+	if err != nil {
+		recorder.Warningf("ObserveShutdownDelayDurationFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
 	switch {
+	case shutdownDelay != nil:
+		observedShutdownDelayDuration = fmt.Sprintf("%ds", int(shutdownDelay.Seconds()))
 	case infra.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode:
 		// reduce the shutdown delay to 0 to reach the maximum downtime for SNO
 		observedShutdownDelayDuration = "0s"
@@ -71,7 +104,8 @@ func ObserveShutdownDelayDuration(genericListers configobserver.Listers, _ event
 	return existingConfig, errs
 }
 
-// ObserveGracefulTerminationDuration sets the graceful termination duration according to the current platform.
+// ObserveGracefulTerminationDuration sets the graceful termination duration according to the current platform, or
+// derives it from the shutdownDelayDuration of the operator config so that both stay consistent.
 func ObserveGracefulTerminationDuration(genericListers configobserver.Listers, _ events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
 	defer func() {
 		// Prune the observed config so that it only contains gracefulTerminationDuration field.
@@ -87,7 +121,14 @@ func ObserveGracefulTerminationDuration(genericListers configobserver.Listers, _
 		return existingConfig, append(errs, err)
 	}
 
+	shutdownDelay, err := shutdownDelayFromOperatorConfig(listers)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+
 	switch {
+	case shutdownDelay != nil:
+		observedGracefulTerminationDuration = strconv.Itoa(int(shutdownDelay.Seconds()) + terminationOverheadSeconds)
 	case infra.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode:
 		// reduce termination duration from 135s (default) to 15s to reach the maximum downtime for SNO:
 		// - the shutdown-delay-duration is set to 0s because there is no load-balancer, and no fallback apiserver
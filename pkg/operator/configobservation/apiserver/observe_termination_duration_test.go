@@ -8,8 +8,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -27,6 +29,8 @@ func TestObserveWatchTerminationDuration(t *testing.T) {
 		expectedKubeAPIConfig   map[string]interface{}
 		platformType            configv1.PlatformType
 		controlPlaneTopology    configv1.TopologyMode
+		operatorConfig          string
+		expectError             bool
 	}{
 
 		// scenario 1
@@ -57,13 +61,39 @@ func TestObserveWatchTerminationDuration(t *testing.T) {
 			controlPlaneTopology:  configv1.SingleReplicaTopologyMode,
 		},
 
-		// scenario 4
+		// scenario 5
 		{
 			name:                  "sno takes precedence over platform type",
 			expectedKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "15"},
 			controlPlaneTopology:  configv1.SingleReplicaTopologyMode,
 			platformType:          configv1.AWSPlatformType,
 		},
+
+		// scenario 6
+		{
+			name:                  "the operator config shutdown delay takes precedence over the platform",
+			existingKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "275"},
+			expectedKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "155"},
+			platformType:          configv1.AWSPlatformType,
+			operatorConfig:        "shutdownDelayDuration: 90s\n",
+		},
+
+		// scenario 7
+		{
+			name:                  "the operator config shutdown delay takes precedence over sno",
+			expectedKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "65"},
+			controlPlaneTopology:  configv1.SingleReplicaTopologyMode,
+			operatorConfig:        "shutdownDelayDuration: 0s\n",
+		},
+
+		// scenario 8
+		{
+			name:                  "an invalid operator config shutdown delay keeps the existing config",
+			existingKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "275"},
+			expectedKubeAPIConfig: map[string]interface{}{"gracefulTerminationDuration": "275"},
+			operatorConfig:        "shutdownDelayDuration: 1500ms\n",
+			expectError:           true,
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -78,14 +108,15 @@ func TestObserveWatchTerminationDuration(t *testing.T) {
 			})
 			listers := configobservation.Listers{
 				InfrastructureLister_: configlistersv1.NewInfrastructureLister(infrastructureIndexer),
+				ConfigConfigMapLister: operatorConfigLister(t, scenario.operatorConfig),
 			}
 
 			// act
 			observedKubeAPIConfig, err := ObserveGracefulTerminationDuration(listers, eventRecorder, scenario.existingKubeAPIConfig)
 
 			// validate
-			if len(err) > 0 {
-				t.Fatal(err)
+			if scenario.expectError != (len(err) > 0) {
+				t.Fatalf("unexpected errors: %v", err)
 			}
 			if !cmp.Equal(scenario.expectedKubeAPIConfig, observedKubeAPIConfig) {
 				t.Fatalf("unexpected configuraiton, diff = %v", cmp.Diff(scenario.expectedKubeAPIConfig, observedKubeAPIConfig))
@@ -101,6 +132,7 @@ func TestObserveShutdownDelayDuration(t *testing.T) {
 		existingConfig          kubecontrolplanev1.KubeAPIServerConfig
 		platformType            configv1.PlatformType
 		controlPlaneTopology    configv1.TopologyMode
+		operatorConfig          string
 	}{
 
 		// scenario 1
@@ -177,6 +209,24 @@ func TestObserveShutdownDelayDuration(t *testing.T) {
 			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
 			platformType:         configv1.AWSPlatformType,
 		},
+
+		// scenario 6
+		{
+			name: "the operator config shutdown delay takes precedence over the platform",
+			validateKubeAPIConfigFn: func(actualKasConfig kubecontrolplanev1.KubeAPIServerConfig) error {
+				shutdownDurationArgs := actualKasConfig.APIServerArguments["shutdown-delay-duration"]
+				if len(shutdownDurationArgs) != 1 {
+					return fmt.Errorf("expected only one argument under shutdown-delay-duration key, got %d", len(shutdownDurationArgs))
+				}
+				if shutdownDurationArgs[0] != "90s" {
+					return fmt.Errorf("incorrect shutdown-delay-duration value, expected = 90s, got %v", shutdownDurationArgs[0])
+				}
+				return nil
+			},
+			controlPlaneTopology: configv1.SingleReplicaTopologyMode,
+			platformType:         configv1.AWSPlatformType,
+			operatorConfig:       "shutdownDelayDuration: 1m30s\n",
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -191,6 +241,7 @@ func TestObserveShutdownDelayDuration(t *testing.T) {
 			})
 			listers := configobservation.Listers{
 				InfrastructureLister_: configlistersv1.NewInfrastructureLister(infrastructureIndexer),
+				ConfigConfigMapLister: operatorConfigLister(t, scenario.operatorConfig),
 			}
 
 			// act
@@ -209,6 +260,18 @@ func TestObserveShutdownDelayDuration(t *testing.T) {
 	}
 }
 
+// operatorConfigLister returns a lister of the openshift-config configmaps holding the given operator config.
+func operatorConfigLister(t *testing.T, operatorConfig string) corev1listers.ConfigMapLister {
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if len(operatorConfig) > 0 {
+		require.NoError(t, configMapIndexer.Add(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+			Data:       map[string]string{"config.yaml": operatorConfig},
+		}))
+	}
+	return corev1listers.NewConfigMapLister(configMapIndexer)
+}
+
 func unstructuredAPIConfig(t *testing.T, existingCfg kubecontrolplanev1.KubeAPIServerConfig) map[string]interface{} {
 	existingCfg.TypeMeta = metav1.TypeMeta{
 		Kind: "KubeAPIServerConfig",
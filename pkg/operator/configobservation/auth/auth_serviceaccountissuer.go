@@ -7,14 +7,18 @@ import (
 
 	"k8s.io/klog/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 )
 
 var (
@@ -23,9 +27,14 @@ var (
 	jwksURIPath              = []string{"apiServerArguments", "service-account-jwks-uri"}
 )
 
+// defaultServiceAccountIssuer is the issuer and audience set by config-overrides.yaml when
+// Authentication.Spec.ServiceAccountIssuer is empty.
+const defaultServiceAccountIssuer = "https://kubernetes.default.svc"
+
 // ObserveServiceAccountIssuer changes apiServerArguments.service-account-issuer from
 // the default value if Authentication.Spec.ServiceAccountIssuer specifies a valid
-// non-empty value.
+// non-empty value. The additionalAPIAudiences of the operator config are appended
+// to the issuer in apiServerArguments.api-audiences.
 func ObserveServiceAccountIssuer(
 	genericListers configobserver.Listers,
 	recorder events.Recorder,
@@ -33,7 +42,17 @@ func ObserveServiceAccountIssuer(
 ) (map[string]interface{}, []error) {
 
 	listers := genericListers.(configobservation.Listers)
-	ret, errs := observedConfig(existingConfig, listers.AuthConfigLister.Get, listers.InfrastructureLister().Get, recorder)
+	operatorConfig, err := operatorconfig.Get(listers.ConfigConfigMapLister)
+	if err != nil {
+		return configobserver.Pruned(existingConfig, serviceAccountIssuerPath, audiencesPath, jwksURIPath), []error{err}
+	}
+	if validationErrs := operatorconfig.ValidateAdditionalAPIAudiences(operatorConfig.AdditionalAPIAudiences, field.NewPath("additionalAPIAudiences")); len(validationErrs) > 0 {
+		err := fmt.Errorf("invalid operator config: %v", validationErrs.ToAggregate())
+		recorder.Warningf("ObserveServiceAccountIssuerFailed", err.Error())
+		return configobserver.Pruned(existingConfig, serviceAccountIssuerPath, audiencesPath, jwksURIPath), []error{err}
+	}
+
+	ret, errs := observedConfig(existingConfig, listers.AuthConfigLister.Get, listers.InfrastructureLister().Get, operatorConfig.AdditionalAPIAudiences, recorder)
 	return configobserver.Pruned(ret, serviceAccountIssuerPath, audiencesPath, jwksURIPath), errs
 }
 
@@ -44,6 +63,7 @@ func observedConfig(
 	existingConfig map[string]interface{},
 	getAuthConfig func(string) (*configv1.Authentication, error),
 	getInfrastructureConfig func(string) (*configv1.Infrastructure, error),
+	additionalAudiences []string,
 	recorder events.Recorder,
 ) (map[string]interface{}, []error) {
 
@@ -88,16 +108,17 @@ func observedConfig(
 	if len(newIssuer) != 0 {
 		issuerChanged = existingIssuer != newIssuer
 		// configure the issuer if set by the user and is a valid issuer
-		return map[string]interface{}{
+		ret := map[string]interface{}{
 			"apiServerArguments": map[string]interface{}{
 				"service-account-issuer": []interface{}{
 					newIssuer,
 				},
-				"api-audiences": []interface{}{
-					newIssuer,
-				},
 			},
-		}, errs
+		}
+		if err := setAudiences(ret, existingConfig, newIssuer, additionalAudiences, recorder); err != nil {
+			return existingConfig, append(errs, err)
+		}
+		return ret, errs
 	}
 
 	// if the issuer is not set, rely on the config-overrides.yaml to set both
@@ -114,13 +135,43 @@ func observedConfig(
 	}
 
 	issuerChanged = existingIssuer != newIssuer
-	return map[string]interface{}{
+	ret := map[string]interface{}{
 		"apiServerArguments": map[string]interface{}{
 			"service-account-jwks-uri": []interface{}{
 				apiServerInternalURL + "/openid/v1/jwks",
 			},
 		},
-	}, errs
+	}
+	// the audiences from config-overrides.yaml are replaced, not extended, so keep the default issuer in front
+	if len(additionalAudiences) > 0 {
+		if err := setAudiences(ret, existingConfig, defaultServiceAccountIssuer, additionalAudiences, recorder); err != nil {
+			return existingConfig, append(errs, err)
+		}
+	}
+	return ret, errs
+}
+
+// setAudiences sets api-audiences to the issuer followed by the additional audiences. Tokens issued for the
+// issuer have to stay valid, so it always remains the first audience.
+func setAudiences(observedConfig, existingConfig map[string]interface{}, issuer string, additionalAudiences []string, recorder events.Recorder) error {
+	audiences := []string{issuer}
+	seen := sets.NewString(issuer)
+	for _, audience := range additionalAudiences {
+		if seen.Has(audience) {
+			continue
+		}
+		seen.Insert(audience)
+		audiences = append(audiences, audience)
+	}
+	if err := unstructured.SetNestedStringSlice(observedConfig, audiences, audiencesPath...); err != nil {
+		return err
+	}
+
+	existingAudiences, _, _ := unstructured.NestedStringSlice(existingConfig, audiencesPath...)
+	if !equality.Semantic.DeepEqual(existingAudiences, audiences) {
+		recorder.Eventf("ObserveAPIAudiences", "api-audiences changed to %v", audiences)
+	}
+	return nil
 }
 
 // checkIssuer validates the issuer in the same way that it will be validated by
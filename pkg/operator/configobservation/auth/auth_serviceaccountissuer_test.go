@@ -94,6 +94,7 @@ func TestObservedConfig(t *testing.T) {
 						},
 					}, tc.infraError
 				},
+				nil,
 				testRecorder,
 			)
 
@@ -130,6 +131,56 @@ func TestObservedConfig(t *testing.T) {
 	}
 }
 
+func TestObservedConfigAdditionalAudiences(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		issuer              string
+		additionalAudiences []string
+		expectedAudiences   []string
+	}{
+		{
+			name:                "default issuer",
+			additionalAudiences: []string{"vault", "istio-ca"},
+			expectedAudiences:   []string{"https://kubernetes.default.svc", "vault", "istio-ca"},
+		},
+		{
+			name:                "custom issuer",
+			issuer:              "https://example.com",
+			additionalAudiences: []string{"vault"},
+			expectedAudiences:   []string{"https://example.com", "vault"},
+		},
+		{
+			name:                "issuer listed as additional audience",
+			issuer:              "https://example.com",
+			additionalAudiences: []string{"https://example.com", "vault"},
+			expectedAudiences:   []string{"https://example.com", "vault"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			newConfig, errs := observedConfig(
+				map[string]interface{}{},
+				func(_ string) (*configv1.Authentication, error) {
+					return authConfigForIssuer(tc.issuer), nil
+				},
+				func(_ string) (*configv1.Infrastructure, error) {
+					return &configv1.Infrastructure{
+						Status: configv1.InfrastructureStatus{
+							APIServerInternalURL: "https://lb.example.com",
+						},
+					}, nil
+				},
+				tc.additionalAudiences,
+				events.NewInMemoryRecorder("SAIssuerTest"),
+			)
+			require.Len(t, errs, 0)
+
+			audiences, _, err := unstructured.NestedStringSlice(newConfig, audiencesPath...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedAudiences, audiences)
+		})
+	}
+}
+
 func authConfigForIssuer(issuer string) *configv1.Authentication {
 	return &configv1.Authentication{
 		Spec: configv1.AuthenticationSpec{
@@ -69,7 +69,7 @@ func ObserveWebhookTokenAuthenticator(genericListers configobserver.Listers, rec
 			return existingConfig, append(errs, fmt.Errorf("failed to get secret openshift-config/%s: %w", webhookSecretName, err))
 		}
 
-		if secretErrors := validateKubeconfigSecret(kubeconfigSecret); len(secretErrors) > 0 {
+		if secretErrors := ValidateKubeconfigSecret(kubeconfigSecret); len(secretErrors) > 0 {
 			return existingConfig, append(errs,
 				fmt.Errorf("secret openshift-config/%s is invalid: %w", webhookSecretName, utilerrors.NewAggregate(secretErrors)))
 		}
@@ -105,7 +105,9 @@ func ObserveWebhookTokenAuthenticator(genericListers configobserver.Listers, rec
 	return observedConfig, errs
 }
 
-func validateKubeconfigSecret(secret *corev1.Secret) []error {
+// ValidateKubeconfigSecret validates the kubeConfig key of a secret of a webhook, it must have a single cluster, user
+// and context and the content of files inlined.
+func ValidateKubeconfigSecret(secret *corev1.Secret) []error {
 	kubeconfigRaw, ok := secret.Data["kubeConfig"]
 	if !ok {
 		return []error{fmt.Errorf("missing required 'kubeConfig' key")}
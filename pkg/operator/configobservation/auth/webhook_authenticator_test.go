@@ -188,7 +188,7 @@ func TestObserveWebhookTokenAuthenticator(t *testing.T) {
 	}
 }
 
-func Test_validateKubeconfigSecret(t *testing.T) {
+func Test_ValidateKubeconfigSecret(t *testing.T) {
 	tests := []struct {
 		name string
 		data map[string][]byte
@@ -263,15 +263,15 @@ users:
 			secret := &corev1.Secret{
 				Data: tt.data,
 			}
-			got := validateKubeconfigSecret(secret)
+			got := ValidateKubeconfigSecret(secret)
 			if len(got) != len(tt.want) {
-				t.Errorf("validateKubeconfigSecret() = %v, want %v", got, tt.want)
+				t.Errorf("ValidateKubeconfigSecret() = %v, want %v", got, tt.want)
 				return
 			}
 
 			for i, err := range got {
 				if !strings.Contains(err.Error(), tt.want[i]) {
-					t.Errorf("validateKubeconfigSecret() = %v\n, want\n %v", got, tt.want)
+					t.Errorf("ValidateKubeconfigSecret() = %v\n, want\n %v", got, tt.want)
 					return
 				}
 			}
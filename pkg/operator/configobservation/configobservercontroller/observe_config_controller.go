@@ -1,7 +1,11 @@
 package configobservercontroller
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
@@ -22,6 +26,7 @@ import (
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/etcdendpoints"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/images"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/network"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/node"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/scheduler"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 )
@@ -30,13 +35,20 @@ var FeatureBlacklist sets.String
 
 type ConfigObserver struct {
 	factory.Controller
+
+	failureController    factory.Controller
+	historyController    factory.Controller
+	conditionsController factory.Controller
 }
 
 func NewConfigObserver(
 	operatorClient v1helpers.OperatorClient,
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	configInformer configinformers.SharedInformerFactory,
+	configDynamicInformers dynamicinformer.DynamicSharedInformerFactory,
+	nodeConfigServed bool,
 	resourceSyncer resourcesynccontroller.ResourceSyncer,
+	configMapClient coreclientv1.ConfigMapsGetter,
 	eventRecorder events.Recorder,
 ) *ConfigObserver {
 	interestingNamespaces := []string{
@@ -58,6 +70,7 @@ func NewConfigObserver(
 		operatorClient.Informer(),
 		kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().Endpoints().Informer(),
 		kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor("kube-system").Core().V1().ConfigMaps().Informer(),
 		configInformer.Config().V1().Images().Informer(),
 		configInformer.Config().V1().Infrastructures().Informer(),
 		configInformer.Config().V1().Authentications().Informer(),
@@ -69,7 +82,21 @@ func NewConfigObserver(
 	for _, ns := range interestingNamespaces {
 		infomers = append(infomers, kubeInformersForNamespaces.InformersFor(ns).Core().V1().ConfigMaps().Informer())
 	}
+	// the kubeconfig rendered for an audit webhook configured by url
+	infomers = append(infomers, kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Informer())
+	// nodes.config.openshift.io is only watched when it is served, an informer of a resource without CRD never syncs
+	var nodeConfigLister cache.GenericLister
+	if nodeConfigServed {
+		infomers = append(infomers, configDynamicInformers.ForResource(node.NodeConfigResource).Informer())
+		nodeConfigLister = configDynamicInformers.ForResource(node.NodeConfigResource).Lister()
+	}
 
+	// every observer is instrumented so that failures can be attributed to it
+	tracker := newObserverTracker()
+	var observers []configobserver.ObserveConfigFunc
+	for _, o := range newObservers(operatorClient, nodeConfigServed) {
+		observers = append(observers, tracker.instrument(o.name, o.observe))
+	}
 	c := &ConfigObserver{
 		Controller: configobserver.NewConfigObserver(
 			operatorClient,
@@ -83,9 +110,13 @@ func NewConfigObserver(
 				NetworkLister:         configInformer.Config().V1().Networks().Lister(),
 				ProxyLister_:          configInformer.Config().V1().Proxies().Lister(),
 				SchedulerLister:       configInformer.Config().V1().Schedulers().Lister(),
+				NodeConfigLister:      nodeConfigLister,
 
 				SecretLister_:                kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Lister(),
 				ConfigSecretLister_:          kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Lister(),
+				OperatorSecretLister:         kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Lister(),
+				ConfigConfigMapLister:        kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+				KubeSystemConfigMapLister:    kubeInformersForNamespaces.InformersFor("kube-system").Core().V1().ConfigMaps().Lister(),
 				OpenshiftEtcdEndpointsLister: kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().Endpoints().Lister(),
 				ConfigmapLister:              kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().ConfigMaps().Lister(),
 
@@ -96,6 +127,7 @@ func NewConfigObserver(
 					kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().Endpoints().Informer().HasSynced,
 					kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().ConfigMaps().Informer().HasSynced,
 					kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Informer().HasSynced,
+					kubeInformersForNamespaces.InformersFor("kube-system").Core().V1().ConfigMaps().Informer().HasSynced,
 
 					configInformer.Config().V1().APIServers().Informer().HasSynced,
 					configInformer.Config().V1().Authentications().Informer().HasSynced,
@@ -109,44 +141,110 @@ func NewConfigObserver(
 				),
 			},
 			infomers,
-			// We are disabling this because it doesn't work today and customers aren't going to be able to get the kube service network options right.
-			// Customers may only use SNI.  I'm leaving this code in case we ever come up with a way to make an SNI-like thing based on IPs.
-			//apiserver.ObserveDefaultUserServingCertificate,
-			apiserver.ObserveNamedCertificates,
-			apiserver.ObserveUserClientCABundle,
-			apiserver.ObserveAdditionalCORSAllowedOrigins,
-			apiserver.ObserveShutdownDelayDuration,
-			apiserver.ObserveGracefulTerminationDuration,
-			libgoapiserver.ObserveTLSSecurityProfile,
-			auth.ObserveAuthMetadata,
-			auth.ObserveServiceAccountIssuer,
-			auth.ObserveWebhookTokenAuthenticator,
-			encryption.NewEncryptionConfigObserver(
-				operatorclient.TargetNamespace,
-				// static path at which we expect to find the encryption config secret
-				"/etc/kubernetes/static-pod-resources/secrets/encryption-config/encryption-config",
-			),
-			etcdendpoints.ObserveStorageURLs,
-			cloudprovider.NewCloudProviderObserver(
-				"openshift-kube-apiserver",
-				[]string{"apiServerArguments", "cloud-provider"},
-				[]string{"apiServerArguments", "cloud-config"}),
-			featuregates.NewObserveFeatureFlagsFunc(
-				nil,
-				FeatureBlacklist,
-				[]string{"apiServerArguments", "feature-gates"},
-			),
-			network.ObserveRestrictedCIDRs,
-			network.ObserveServicesSubnet,
-			network.ObserveExternalIPPolicy,
-			network.ObserveServicesNodePortRange,
-			proxy.NewProxyObserveFunc([]string{"targetconfigcontroller", "proxy"}),
-			images.ObserveInternalRegistryHostname,
-			images.ObserveExternalRegistryHostnames,
-			images.ObserveAllowedRegistriesForImport,
-			scheduler.ObserveDefaultNodeSelector,
+			observers...,
 		),
+		failureController:    newObserverFailureController(tracker, operatorClient, eventRecorder),
+		historyController:    newObservedConfigHistoryController(tracker, operatorClient, kubeInformersForNamespaces, configMapClient, eventRecorder),
+		conditionsController: newObservedConfigConditionsController(operatorClient, kubeInformersForNamespaces, eventRecorder),
 	}
 
 	return c
 }
+
+// namedObserver is a config observer with the name it is instrumented and reported by.
+type namedObserver struct {
+	name    string
+	observe configobserver.ObserveConfigFunc
+}
+
+// newObservers returns the config observers of the operator in the order they run. The worker latency profile is only
+// observed when nodes.config.openshift.io is served.
+func newObservers(operatorClient v1helpers.OperatorClient, nodeConfigServed bool) []namedObserver {
+	observers := []namedObserver{
+		// We are disabling this because it doesn't work today and customers aren't going to be able to get the kube service network options right.
+		// Customers may only use SNI.  I'm leaving this code in case we ever come up with a way to make an SNI-like thing based on IPs.
+		//apiserver.ObserveDefaultUserServingCertificate,
+		{"apiserver.ObserveNamedCertificates", apiserver.ObserveNamedCertificates},
+		{"apiserver.ObserveUserClientCABundle", apiserver.ObserveUserClientCABundle},
+		{"apiserver.ObserveAdditionalCORSAllowedOrigins", apiserver.ObserveAdditionalCORSAllowedOrigins},
+		{"apiserver.ObserveShutdownDelayDuration", apiserver.ObserveShutdownDelayDuration},
+		{"apiserver.ObserveGracefulTerminationDuration", apiserver.ObserveGracefulTerminationDuration},
+		{"apiserver.ObserveRuntimeConfig", apiserver.ObserveRuntimeConfig},
+		{"apiserver.ObserveKubeletPreferredAddressTypes", apiserver.ObserveKubeletPreferredAddressTypes},
+		{"apiserver.ObserveAnonymousAuth", apiserver.ObserveAnonymousAuth},
+		{"apiserver.ObserveEtcdOptions", apiserver.ObserveEtcdOptions},
+		{"apiserver.ObserveLogging", apiserver.ObserveLogging},
+		{"apiserver.ObserveResources", apiserver.ObserveResources},
+		{"apiserver.ObserveReservedCPUs", apiserver.ObserveReservedCPUs},
+		{"apiserver.ObserveSidecars", apiserver.ObserveSidecars},
+		{"apiserver.ObserveProbes", apiserver.ObserveProbes},
+		{"apiserver.ObserveAdvertiseAddressSubnets", apiserver.ObserveAdvertiseAddressSubnets},
+		{"apiserver.ObserveEnvironment", apiserver.ObserveEnvironment},
+		{"apiserver.ObserveAuditLog", apiserver.ObserveAuditLog},
+		{"apiserver.ObserveAuditWebhook", apiserver.ObserveAuditWebhook},
+		{"apiserver.ObserveAuditForwarder", apiserver.ObserveAuditForwarder},
+		{"apiserver.ArgumentOverridesObserver", apiserver.NewArgumentOverridesObserver()},
+		{"apiserver.ObserveOperandImage", apiserver.ObserveOperandImage},
+		{"apiserver.ObserveNonRoot", apiserver.ObserveNonRoot},
+		{"apiserver.ObserveSecurityContext", apiserver.ObserveSecurityContext},
+		{"apiserver.ObserveProfiling", apiserver.ObserveProfiling},
+		{"apiserver.ObserveInsecureReadyz", apiserver.ObserveInsecureReadyz},
+		{"apiserver.ObserveStartupMonitor", apiserver.ObserveStartupMonitor},
+		{"apiserver.ObserveHostPathMounts", apiserver.ObserveHostPathMounts},
+		{"apiserver.ObserveRollout", apiserver.ObserveRollout},
+		{"apiserver.ObserveInstaller", apiserver.ObserveInstaller},
+		{"apiserver.ObserveStaticPodDetection", apiserver.ObserveStaticPodDetection},
+		{"apiserver.ObserveConnectivityChecks", apiserver.ObserveConnectivityChecks},
+		{"apiserver.ObserveTLSSecurityProfile", libgoapiserver.ObserveTLSSecurityProfile},
+		{"auth.ObserveAuthMetadata", auth.ObserveAuthMetadata},
+		{"auth.ObserveServiceAccountIssuer", auth.ObserveServiceAccountIssuer},
+		{"auth.ObserveWebhookTokenAuthenticator", auth.ObserveWebhookTokenAuthenticator},
+		{"encryption.EncryptionConfigObserver", encryption.NewEncryptionConfigObserver(
+			operatorclient.TargetNamespace,
+			// static path at which we expect to find the encryption config secret
+			"/etc/kubernetes/static-pod-resources/secrets/encryption-config/encryption-config",
+		)},
+		{"etcdendpoints.ObserveStorageURLs", etcdendpoints.ObserveStorageURLs},
+		{"cloudprovider.CloudProviderObserver", cloudprovider.NewCloudProviderObserver(
+			"openshift-kube-apiserver",
+			[]string{"apiServerArguments", "cloud-provider"},
+			[]string{"apiServerArguments", "cloud-config"})},
+		{"featuregates.ObserveFeatureFlags", featuregates.NewObserveFeatureFlagsFunc(
+			nil,
+			FeatureBlacklist,
+			[]string{"apiServerArguments", "feature-gates"},
+		)},
+		{"network.ObserveRestrictedCIDRs", network.ObserveRestrictedCIDRs},
+		{"network.ObserveServicesSubnet", network.ObserveServicesSubnet},
+		{"network.ObserveExternalIPPolicy", network.ObserveExternalIPPolicy},
+		{"network.ObserveServicesNodePortRange", network.ObserveServicesNodePortRange},
+		{"proxy.ObserveProxy", proxy.NewProxyObserveFunc([]string{"targetconfigcontroller", "proxy"})},
+		{"images.ObserveInternalRegistryHostname", images.ObserveInternalRegistryHostname},
+		{"images.ObserveExternalRegistryHostnames", images.ObserveExternalRegistryHostnames},
+		{"images.ObserveAllowedRegistriesForImport", images.ObserveAllowedRegistriesForImport},
+		{"images.ObserveAdditionalTrustedCA", images.ObserveAdditionalTrustedCA},
+		{"scheduler.ObserveDefaultNodeSelector", scheduler.ObserveDefaultNodeSelector},
+	}
+	if nodeConfigServed {
+		observers = append(observers, namedObserver{"node.LatencyProfileObserver", node.NewLatencyProfileObserver(operatorClient)})
+	}
+	return observers
+}
+
+// ObserverNames returns the names of the config observers compiled into the operator in the order they run.
+func ObserverNames() []string {
+	var names []string
+	for _, o := range newObservers(nil, true) {
+		names = append(names, o.name)
+	}
+	return names
+}
+
+// Run runs the config observer, the controller reporting its persistently failing observers, the controller
+// recording the history of the observed config and the controller setting the conditions describing it.
+func (c *ConfigObserver) Run(ctx context.Context, workers int) {
+	go c.failureController.Run(ctx, 1)
+	go c.historyController.Run(ctx, 1)
+	go c.conditionsController.Run(ctx, 1)
+	c.Controller.Run(ctx, workers)
+}
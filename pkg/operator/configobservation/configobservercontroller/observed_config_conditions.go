@@ -0,0 +1,66 @@
+package configobservercontroller
+
+import (
+	"context"
+	"encoding/json"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/apiserver"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+// observedConfigConditionFunc returns a condition of the operator that describes the observed config. The operator
+// config is passed for conditions that report what was not observed.
+type observedConfigConditionFunc func(observedConfig map[string]interface{}, operatorConfig *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition
+
+// newObservedConfigConditions returns the conditions that describe the observed config. The config observers only
+// observe, these conditions are set from their result.
+func newObservedConfigConditions() []observedConfigConditionFunc {
+	return []observedConfigConditionFunc{
+		apiserver.NewRuntimeConfigUpgradeableCondition,
+		apiserver.NewArgumentOverridesCondition,
+		apiserver.NewEnvironmentCondition,
+		apiserver.NewOperandImageUpgradeableCondition,
+		apiserver.NewProfilingCondition,
+	}
+}
+
+// newObservedConfigConditionsController sets the conditions describing the observed config whenever it or the
+// operator config changes.
+func newObservedConfigConditionsController(operatorClient v1helpers.OperatorClient, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, eventRecorder events.Recorder) factory.Controller {
+	configConfigMapInformer := kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps()
+	return factory.New().WithInformers(operatorClient.Informer(), configConfigMapInformer.Informer()).WithSync(syncmetrics.Instrument("ObservedConfigConditionsController", func(ctx context.Context, syncContext factory.SyncContext) error {
+		return syncObservedConfigConditions(operatorClient, configConfigMapInformer.Lister(), newObservedConfigConditions())
+	})).ToController("ObservedConfigConditionsController", eventRecorder.WithComponentSuffix("observed-config-conditions-controller"))
+}
+
+func syncObservedConfigConditions(operatorClient v1helpers.OperatorClient, configConfigMapLister corev1listers.ConfigMapLister, conditions []observedConfigConditionFunc) error {
+	spec, _, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	observedConfig := map[string]interface{}{}
+	if len(spec.ObservedConfig.Raw) > 0 {
+		if err := json.Unmarshal(spec.ObservedConfig.Raw, &observedConfig); err != nil {
+			return err
+		}
+	}
+	operatorConfig, err := operatorconfig.Get(configConfigMapLister)
+	if err != nil {
+		return err
+	}
+
+	var updateFuncs []v1helpers.UpdateStatusFunc
+	for _, condition := range conditions {
+		updateFuncs = append(updateFuncs, v1helpers.UpdateConditionFn(condition(observedConfig, operatorConfig)))
+	}
+	_, _, err = v1helpers.UpdateStatus(operatorClient, updateFuncs...)
+	return err
+}
@@ -0,0 +1,44 @@
+package configobservercontroller
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestSyncObservedConfigConditions(t *testing.T) {
+	operatorClient := v1helpers.NewFakeOperatorClient(
+		&operatorv1.OperatorSpec{
+			ManagementState: operatorv1.Managed,
+			ObservedConfig:  runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"runtime-config":["storage.k8s.io/v1alpha1=true"]}}`)},
+		},
+		&operatorv1.OperatorStatus{},
+		nil,
+	)
+	configMapLister := corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+	hasObservedConfig := func(observedConfig map[string]interface{}, _ *operatorconfig.KubeAPIServerOperatorConfig) operatorv1.OperatorCondition {
+		status := operatorv1.ConditionFalse
+		if len(observedConfig) > 0 {
+			status = operatorv1.ConditionTrue
+		}
+		return operatorv1.OperatorCondition{Type: "HasObservedConfig", Status: status}
+	}
+
+	if err := syncObservedConfigConditions(operatorClient, configMapLister, []observedConfigConditionFunc{hasObservedConfig}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, status, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if condition := v1helpers.FindOperatorCondition(status.Conditions, "HasObservedConfig"); condition == nil || condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected HasObservedConfig=True, got %v", condition)
+	}
+}
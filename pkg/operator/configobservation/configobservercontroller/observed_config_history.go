@@ -0,0 +1,128 @@
+package configobservercontroller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// HistoryConfigMapName is the configmap in the operator namespace with the last snapshots of the observed config.
+	HistoryConfigMapName = "observed-config-history"
+	// historyKey holds the snapshots as a JSON list, the oldest first
+	historyKey = "history"
+
+	// maxSnapshots is how many snapshots are kept
+	maxSnapshots = 10
+)
+
+// Snapshot is an observed config of the operator and the observers that changed it.
+type Snapshot struct {
+	// time is when the observed config was seen first
+	Time metav1.Time `json:"time"`
+	// observers are the observers whose observed config changed since the previous snapshot, empty for the first
+	// snapshot after the operator started
+	Observers      []string        `json:"observers,omitempty"`
+	ObservedConfig json.RawMessage `json:"observedConfig"`
+}
+
+// newObservedConfigHistoryController records every change of the observed config in the observed-config-history
+// configmap, with when it was seen and the observers that changed it. A rollout triggered by a config change can be
+// traced back to the observers after the fact, also from a must-gather.
+func newObservedConfigHistoryController(
+	tracker *observerTracker,
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	configMapLister := kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Lister()
+	return factory.New().WithInformers(operatorClient.Informer()).WithSync(syncmetrics.Instrument("ObservedConfigHistoryController", func(ctx context.Context, syncContext factory.SyncContext) error {
+		return syncObservedConfigHistory(ctx, tracker, operatorClient, configMapLister, configMapClient, syncContext.Recorder())
+	})).ToController("ObservedConfigHistoryController", eventRecorder.WithComponentSuffix("observed-config-history-controller"))
+}
+
+func syncObservedConfigHistory(ctx context.Context, tracker *observerTracker, operatorClient v1helpers.OperatorClient, configMapLister corev1listers.ConfigMapLister, configMapClient coreclientv1.ConfigMapsGetter, recorder events.Recorder) error {
+	spec, _, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	if len(spec.ObservedConfig.Raw) == 0 {
+		return nil
+	}
+
+	var existing string
+	configMap, err := configMapLister.ConfigMaps(operatorclient.OperatorNamespace).Get(HistoryConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		existing = configMap.Data[historyKey]
+	}
+
+	history, changed, err := appendSnapshot(existing, spec.ObservedConfig.Raw, tracker, tracker.now())
+	if err != nil || !changed {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, configMapClient, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: HistoryConfigMapName},
+		Data:       map[string]string{historyKey: history},
+	})
+	return err
+}
+
+// appendSnapshot appends the observed config to the recorded history unless it is the config of the last snapshot,
+// and drops the oldest snapshots beyond the maximum. A history that does not decode is started over.
+func appendSnapshot(existing string, observedConfig []byte, tracker *observerTracker, now time.Time) (string, bool, error) {
+	var snapshots []Snapshot
+	if len(existing) > 0 && json.Unmarshal([]byte(existing), &snapshots) != nil {
+		snapshots = nil
+	}
+	if len(snapshots) > 0 {
+		same, err := sameJSON(snapshots[len(snapshots)-1].ObservedConfig, observedConfig)
+		if err != nil || same {
+			return existing, false, err
+		}
+	}
+
+	snapshots = append(snapshots, Snapshot{
+		Time:           metav1.NewTime(now),
+		Observers:      tracker.takeChanged(),
+		ObservedConfig: observedConfig,
+	})
+	if len(snapshots) > maxSnapshots {
+		snapshots = snapshots[len(snapshots)-maxSnapshots:]
+	}
+	history, err := json.Marshal(snapshots)
+	if err != nil {
+		return "", false, err
+	}
+	return string(history), true, nil
+}
+
+// sameJSON tells whether two JSON documents are equal, regardless of the order of keys and whitespace.
+func sameJSON(a, b []byte) (bool, error) {
+	var decodedA, decodedB interface{}
+	if err := json.Unmarshal(a, &decodedA); err != nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(b, &decodedB); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(decodedA, decodedB), nil
+}
@@ -0,0 +1,61 @@
+package configobservercontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestAppendSnapshot(t *testing.T) {
+	now := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newObserverTracker()
+	value := "a"
+	observe := tracker.instrument("test.ObserveSomething", func(configobserver.Listers, events.Recorder, map[string]interface{}) (map[string]interface{}, []error) {
+		return map[string]interface{}{"value": value}, nil
+	})
+	recorder := events.NewInMemoryRecorder(t.Name())
+
+	// the first observation is no change
+	observe(nil, recorder, map[string]interface{}{})
+	history, changed, err := appendSnapshot("", []byte(`{"value":"a"}`), tracker, now)
+	if err != nil || !changed {
+		t.Fatalf("expected the first snapshot to be appended, got %v, %v", changed, err)
+	}
+
+	// the same config with other whitespace and order of keys
+	if _, changed, err := appendSnapshot(history, []byte(`{ "value": "a" }`), tracker, now.Add(time.Minute)); err != nil || changed {
+		t.Fatalf("expected an unchanged config not to be appended, got %v, %v", changed, err)
+	}
+
+	for i := 0; i < maxSnapshots; i++ {
+		value = fmt.Sprintf("b%d", i)
+		observe(nil, recorder, map[string]interface{}{})
+		history, changed, err = appendSnapshot(history, []byte(fmt.Sprintf(`{"value":%q}`, value)), tracker, now.Add(time.Duration(i+2)*time.Minute))
+		if err != nil || !changed {
+			t.Fatalf("expected snapshot %d to be appended, got %v, %v", i, changed, err)
+		}
+	}
+
+	var snapshots []Snapshot
+	if err := json.Unmarshal([]byte(history), &snapshots); err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != maxSnapshots {
+		t.Fatalf("expected %d snapshots, got %d", maxSnapshots, len(snapshots))
+	}
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	if string(first.ObservedConfig) != `{"value":"b0"}` || !first.Time.Time.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("expected the oldest snapshots to be dropped, got %s at %s", first.ObservedConfig, first.Time)
+	}
+	if expected := []string{"test.ObserveSomething"}; !reflect.DeepEqual(expected, last.Observers) {
+		t.Errorf("expected observers %v, got %v", expected, last.Observers)
+	}
+	if changed := tracker.takeChanged(); len(changed) != 0 {
+		t.Errorf("expected the changes to be taken by the snapshot, got %v", changed)
+	}
+}
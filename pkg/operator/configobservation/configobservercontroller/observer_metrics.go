@@ -0,0 +1,192 @@
+package configobservercontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	ConfigObserverDegradedConditionType = "ConfigObserverDegraded"
+
+	// persistentFailureThreshold is how long an observer has to fail without a single success before it is
+	// reported in the ConfigObserverDegraded condition. Shorter failures, e.g. while caches catch up, are
+	// only visible in ConfigObservationDegraded and the metrics.
+	persistentFailureThreshold = 5 * time.Minute
+)
+
+var (
+	registerMetrics sync.Once
+
+	observerDurationHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Name:    "openshift_kube_apiserver_operator_config_observer_duration_seconds",
+		Help:    "Report the time it takes an individual config observer to evaluate.",
+		Buckets: metrics.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"observer"})
+
+	observerLastSuccessGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_config_observer_last_success_timestamp_seconds",
+		Help: "Report the last time an individual config observer evaluated without errors.",
+	}, []string{"observer"})
+
+	observerErrorsCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_operator_config_observer_errors_total",
+		Help: "Report the number of errors returned by an individual config observer.",
+	}, []string{"observer"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(observerDurationHistogram)
+		legacyregistry.MustRegister(observerLastSuccessGauge)
+		legacyregistry.MustRegister(observerErrorsCounter)
+	})
+}
+
+// observerFailure is the first failure of a sequence of failed evaluations and the last error.
+type observerFailure struct {
+	since     time.Time
+	lastError error
+}
+
+// observerTracker instruments config observers and remembers which of them keep failing and which of them changed
+// their observed config.
+type observerTracker struct {
+	lock     sync.Mutex
+	failures map[string]observerFailure
+	// outputs are the last successfully observed configs, encoded to compare them
+	outputs map[string][]byte
+	// changed are the observers whose observed config changed since the changes were last taken
+	changed sets.String
+	now     func() time.Time
+}
+
+func newObserverTracker() *observerTracker {
+	return &observerTracker{failures: map[string]observerFailure{}, outputs: map[string][]byte{}, changed: sets.NewString(), now: time.Now}
+}
+
+// instrument returns an observer that records the duration, errors and last success of observe under the given
+// name. The returned errors are prefixed with the name so that ConfigObservationDegraded tells which observer failed.
+func (t *observerTracker) instrument(name string, observe configobserver.ObserveConfigFunc) configobserver.ObserveConfigFunc {
+	return func(listers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+		start := t.now()
+		observedConfig, errs := observe(listers, recorder, existingConfig)
+		observerDurationHistogram.WithLabelValues(name).Observe(t.now().Sub(start).Seconds())
+
+		if len(errs) == 0 {
+			observerLastSuccessGauge.WithLabelValues(name).Set(float64(t.now().Unix()))
+			t.succeeded(name, observedConfig)
+			return observedConfig, errs
+		}
+
+		observerErrorsCounter.WithLabelValues(name).Add(float64(len(errs)))
+		namedErrs := make([]error, 0, len(errs))
+		for _, err := range errs {
+			namedErrs = append(namedErrs, fmt.Errorf("%s: %w", name, err))
+		}
+		t.failed(name, utilerrors.NewAggregate(errs), start)
+		return observedConfig, namedErrs
+	}
+}
+
+func (t *observerTracker) succeeded(name string, observedConfig map[string]interface{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.failures, name)
+
+	output, err := json.Marshal(observedConfig)
+	if err != nil {
+		return
+	}
+	// the first observation after the start of the operator is no change
+	if previous, ok := t.outputs[name]; ok && !bytes.Equal(previous, output) {
+		t.changed.Insert(name)
+	}
+	t.outputs[name] = output
+}
+
+// takeChanged returns the observers whose observed config changed since the last call, sorted by name.
+func (t *observerTracker) takeChanged() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	changed := t.changed.List()
+	t.changed = sets.NewString()
+	return changed
+}
+
+func (t *observerTracker) failed(name string, err error, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	failure, ok := t.failures[name]
+	if !ok {
+		failure.since = at
+	}
+	failure.lastError = err
+	t.failures[name] = failure
+}
+
+// persistentFailures describes every observer that has been failing for longer than the threshold, sorted by name.
+func (t *observerTracker) persistentFailures(threshold time.Duration) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var names []string
+	for name, failure := range t.failures {
+		if t.now().Sub(failure.since) >= threshold {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var messages []string
+	for _, name := range names {
+		failure := t.failures[name]
+		messages = append(messages, fmt.Sprintf("%s failing since %s: %v", name, failure.since.UTC().Format(time.RFC3339), failure.lastError))
+	}
+	return messages
+}
+
+// newObserverFailureController aggregates the persistent failures of the tracked observers into the
+// ConfigObserverDegraded condition.
+func newObserverFailureController(tracker *observerTracker, operatorClient v1helpers.OperatorClient, eventRecorder events.Recorder) factory.Controller {
+	return factory.New().WithSync(syncmetrics.Instrument("ConfigObserverFailureController", func(ctx context.Context, syncContext factory.SyncContext) error {
+		cond := newObserverDegradedCondition(tracker.persistentFailures(persistentFailureThreshold))
+		if _, _, err := v1helpers.UpdateStatus(operatorClient, v1helpers.UpdateConditionFn(cond)); err != nil {
+			return err
+		}
+		return nil
+	})).ResyncEvery(resyncinterval.For("ConfigObserverFailureController", time.Minute)).ToController("ConfigObserverFailureController", eventRecorder.WithComponentSuffix("config-observer-failure-controller"))
+}
+
+func newObserverDegradedCondition(failures []string) operatorv1.OperatorCondition {
+	if len(failures) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   ConfigObserverDegradedConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    ConfigObserverDegradedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "PersistentObserverFailure",
+		Message: strings.Join(failures, "\n"),
+	}
+}
@@ -0,0 +1,49 @@
+package configobservercontroller
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestObserverTracker(t *testing.T) {
+	now := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newObserverTracker()
+	tracker.now = func() time.Time { return now }
+
+	var observeErr error
+	observe := tracker.instrument("test.ObserveSomething", func(configobserver.Listers, events.Recorder, map[string]interface{}) (map[string]interface{}, []error) {
+		if observeErr != nil {
+			return map[string]interface{}{}, []error{observeErr}
+		}
+		return map[string]interface{}{}, nil
+	})
+	recorder := events.NewInMemoryRecorder(t.Name())
+
+	observeErr = fmt.Errorf("lister not synced")
+	_, errs := observe(nil, recorder, map[string]interface{}{})
+	if len(errs) != 1 || errs[0].Error() != "test.ObserveSomething: lister not synced" {
+		t.Fatalf("expected the error to name the observer, got %v", errs)
+	}
+	if failures := tracker.persistentFailures(persistentFailureThreshold); len(failures) != 0 {
+		t.Fatalf("expected no persistent failures yet, got %v", failures)
+	}
+
+	now = now.Add(persistentFailureThreshold)
+	observeErr = fmt.Errorf("still not synced")
+	observe(nil, recorder, map[string]interface{}{})
+	expected := []string{"test.ObserveSomething failing since 2021-09-01T10:00:00Z: still not synced"}
+	if failures := tracker.persistentFailures(persistentFailureThreshold); !reflect.DeepEqual(expected, failures) {
+		t.Fatalf("expected %v, got %v", expected, failures)
+	}
+
+	observeErr = nil
+	observe(nil, recorder, map[string]interface{}{})
+	if failures := tracker.persistentFailures(persistentFailureThreshold); len(failures) != 0 {
+		t.Fatalf("expected a success to clear the failure, got %v", failures)
+	}
+}
@@ -12,10 +12,16 @@ import (
 
 	"github.com/openshift/library-go/pkg/operator/configobserver"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 )
 
+// AdditionalTrustedCAConfigMapName is the name of the configmap in the target namespace that holds a copy of
+// the image registry additional trusted CAs referenced by image.config.openshift.io/cluster.
+const AdditionalTrustedCAConfigMapName = "image-additional-trusted-ca"
+
 // ObserveInternalRegistryHostname reads the internal registry hostname from the cluster configuration as provided by
 // the registry operator.
 func ObserveInternalRegistryHostname(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
@@ -157,6 +163,41 @@ func ObserveAllowedRegistriesForImport(genericListers configobserver.Listers, re
 	return observedConfig, errs
 }
 
+// ObserveAdditionalTrustedCA syncs the configmap referenced by spec.additionalTrustedCA of the cluster image config
+// into the operand namespace. The kube-apiserver appends every bundle found in that configmap to its system trust
+// store, so that webhooks and OIDC providers signed by the same internal CA as the registries are trusted as well.
+// Nothing is added to the observed config, the configmap is consumed directly by the static pod.
+func ObserveAdditionalTrustedCA(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (map[string]interface{}, []error) {
+	listers := genericListers.(configobservation.Listers)
+	var errs []error
+
+	destination := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: AdditionalTrustedCAConfigMapName}
+
+	configImage, err := listers.ImageConfigLister.Get("cluster")
+	if errors.IsNotFound(err) {
+		klog.Warningf("image.config.openshift.io/cluster: not found")
+		if err := listers.ResourceSyncer().SyncConfigMap(destination, resourcesynccontroller.ResourceLocation{}); err != nil {
+			errs = append(errs, err)
+		}
+		return map[string]interface{}{}, errs
+	}
+	if err != nil {
+		// keep whatever has been synced so far
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	// an empty source means the destination is removed
+	source := resourcesynccontroller.ResourceLocation{}
+	if configMapName := configImage.Spec.AdditionalTrustedCA.Name; len(configMapName) > 0 {
+		source = resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: configMapName}
+	}
+	if err := listers.ResourceSyncer().SyncConfigMap(destination, source); err != nil {
+		return map[string]interface{}{}, append(errs, err)
+	}
+
+	return map[string]interface{}{}, errs
+}
+
 // convert converts an arbitrary object into the json decoded equivalent by
 // first encoding it into a json string and then decoding the string and
 // returning it.
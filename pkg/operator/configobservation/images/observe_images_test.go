@@ -9,6 +9,7 @@ import (
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -220,3 +221,83 @@ func TestObserveImageConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestObserveAdditionalTrustedCA(t *testing.T) {
+	testCases := []struct {
+		name           string
+		imageConfig    *configv1.Image
+		expectedSynced map[string]string
+	}{
+		{
+			name: "NoImageConfig",
+			expectedSynced: map[string]string{
+				"configmap/image-additional-trusted-ca.openshift-kube-apiserver": "DELETE",
+			},
+		},
+		{
+			name: "NoAdditionalTrustedCA",
+			imageConfig: &configv1.Image{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			},
+			expectedSynced: map[string]string{
+				"configmap/image-additional-trusted-ca.openshift-kube-apiserver": "DELETE",
+			},
+		},
+		{
+			name: "AdditionalTrustedCA",
+			imageConfig: &configv1.Image{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.ImageSpec{
+					AdditionalTrustedCA: configv1.ConfigMapNameReference{Name: "registry-cas"},
+				},
+			},
+			expectedSynced: map[string]string{
+				"configmap/image-additional-trusted-ca.openshift-kube-apiserver": "configmap/registry-cas.openshift-config",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if tc.imageConfig != nil {
+				if err := indexer.Add(tc.imageConfig); err != nil {
+					t.Fatal(err)
+				}
+			}
+			synced := map[string]string{}
+			listers := configobservation.Listers{
+				ImageConfigLister: configlistersv1.NewImageLister(indexer),
+				ResourceSync:      &mockResourceSyncer{synced: synced},
+			}
+
+			observed, errs := ObserveAdditionalTrustedCA(listers, events.NewInMemoryRecorder(t.Name()), map[string]interface{}{})
+			if len(errs) != 0 {
+				t.Fatalf("unexpected error: %v", errs)
+			}
+			if len(observed) != 0 {
+				t.Errorf("expected empty observed config, got %v", observed)
+			}
+			if !equality.Semantic.DeepEqual(tc.expectedSynced, synced) {
+				t.Errorf("unexpected synced resources, got: %v, expected: %v", synced, tc.expectedSynced)
+			}
+		})
+	}
+}
+
+type mockResourceSyncer struct {
+	synced map[string]string
+}
+
+func (rs *mockResourceSyncer) SyncConfigMap(destination, source resourcesynccontroller.ResourceLocation) error {
+	if (source == resourcesynccontroller.ResourceLocation{}) {
+		rs.synced[fmt.Sprintf("configmap/%v.%v", destination.Name, destination.Namespace)] = "DELETE"
+	} else {
+		rs.synced[fmt.Sprintf("configmap/%v.%v", destination.Name, destination.Namespace)] = fmt.Sprintf("configmap/%v.%v", source.Name, source.Namespace)
+	}
+	return nil
+}
+
+func (rs *mockResourceSyncer) SyncSecret(destination, source resourcesynccontroller.ResourceLocation) error {
+	return fmt.Errorf("unexpected secret sync to %v", destination)
+}
@@ -20,11 +20,16 @@ type Listers struct {
 	NetworkLister         configlistersv1.NetworkLister
 	ProxyLister_          configlistersv1.ProxyLister
 	SchedulerLister       configlistersv1.SchedulerLister
+	// NodeConfigLister lists nodes.config.openshift.io, there is no typed lister for it yet
+	NodeConfigLister cache.GenericLister
 
 	OpenshiftEtcdEndpointsLister corelistersv1.EndpointsLister
 	ConfigmapLister              corelistersv1.ConfigMapLister
 	SecretLister_                corelistersv1.SecretLister
 	ConfigSecretLister_          corelistersv1.SecretLister
+	OperatorSecretLister         corelistersv1.SecretLister
+	ConfigConfigMapLister        corelistersv1.ConfigMapLister
+	KubeSystemConfigMapLister    corelistersv1.ConfigMapLister
 
 	ResourceSync       resourcesynccontroller.ResourceSyncer
 	PreRunCachesSynced []cache.InformerSynced
@@ -98,9 +98,14 @@ func ObserveServicesSubnet(genericListers configobserver.Listers, recorder event
 	}
 	bindAddress := "0.0.0.0:6443"
 	bindNetwork := "tcp4"
-	if len(serviceCIDRs) == 1 && utilnet.IsIPv6CIDRString(serviceCIDRs[0]) {
+	switch {
+	case len(serviceCIDRs) == 1 && utilnet.IsIPv6CIDRString(serviceCIDRs[0]):
 		bindAddress = "[::]:6443"
 		bindNetwork = "tcp6"
+	case len(serviceCIDRs) > 1:
+		// dual-stack, the IPv6 wildcard address accepts IPv4 connections as well
+		bindAddress = "[::]:6443"
+		bindNetwork = "tcp"
 	}
 	if err := unstructured.SetNestedField(out, bindAddress, bindAddressConfigPath...); err != nil {
 		errs = append(errs, err)
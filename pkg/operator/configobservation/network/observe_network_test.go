@@ -213,6 +213,34 @@ func TestObserveServicesSubnet(t *testing.T) {
 	if conf != "tcp6" {
 		t.Errorf("Unexpected value: %v", conf)
 	}
+
+	// Switch to dual-stack and see that both address families are accepted.
+	if err := indexer.Update(&configv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.NetworkStatus{
+			ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14"}, {CIDR: "fd01::/48"}},
+			ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"},
+		},
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	result, errors = ObserveServicesSubnet(listers, events.NewInMemoryRecorder("network"), result)
+	if len(errors) > 0 {
+		t.Errorf("expected len(errors) == 0: %v", errors)
+	}
+	conf, _, _ = unstructured.NestedString(result, "servicesSubnet")
+	if conf != "172.30.0.0/16,fd02::/112" {
+		t.Errorf("Unexpected value: %v", conf)
+	}
+	conf, _, _ = unstructured.NestedString(result, "servingInfo", "bindAddress")
+	if conf != "[::]:6443" {
+		t.Errorf("Unexpected value: %v", conf)
+	}
+	conf, _, _ = unstructured.NestedString(result, "servingInfo", "bindNetwork")
+	if conf != "tcp" {
+		t.Errorf("Unexpected value: %v", conf)
+	}
 }
 
 func TestObserveExternalIPPolicy(t *testing.T) {
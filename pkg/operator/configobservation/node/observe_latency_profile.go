@@ -0,0 +1,185 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+// NodeConfigResource is the resource of the cluster scoped node configuration. There is no typed client for it yet,
+// so it is read through a dynamic informer.
+var NodeConfigResource = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "nodes"}
+
+// NodeConfigServed returns whether the apiserver serves NodeConfigResource. Releases without its CRD do not, and an
+// informer of a resource that is not served never syncs.
+func NodeConfigServed(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(NodeConfigResource.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == NodeConfigResource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WorkerLatencyProfileType is the value of spec.workerLatencyProfile in nodes.config.openshift.io/cluster.
+type WorkerLatencyProfileType string
+
+const (
+	// DefaultUpdateDefaultReaction keeps the upstream defaults.
+	DefaultUpdateDefaultReaction WorkerLatencyProfileType = "Default"
+	// MediumUpdateAverageReaction is meant for clusters with a medium latency between the workers and the control plane.
+	MediumUpdateAverageReaction WorkerLatencyProfileType = "MediumUpdateAverageReaction"
+	// LowUpdateSlowReaction is meant for clusters with a high latency between the workers and the control plane.
+	LowUpdateSlowReaction WorkerLatencyProfileType = "LowUpdateSlowReaction"
+)
+
+var (
+	notReadyTolerationSecondsPath    = []string{"apiServerArguments", "default-not-ready-toleration-seconds"}
+	unreachableTolerationSecondsPath = []string{"apiServerArguments", "default-unreachable-toleration-seconds"}
+)
+
+// latencyProfileTolerations holds the toleration seconds the kube-apiserver sets on pods by default for
+// the not-ready and unreachable taints, per worker latency profile.
+type latencyProfileTolerations struct {
+	notReadyTolerationSeconds    string
+	unreachableTolerationSeconds string
+}
+
+// latencyProfiles are the tolerations set by each supported profile. The Default profile sets the upstream
+// defaults explicitly so that switching back from another profile triggers a rollout.
+var latencyProfiles = map[WorkerLatencyProfileType]latencyProfileTolerations{
+	DefaultUpdateDefaultReaction: {notReadyTolerationSeconds: "300", unreachableTolerationSeconds: "300"},
+	MediumUpdateAverageReaction:  {notReadyTolerationSeconds: "60", unreachableTolerationSeconds: "60"},
+	LowUpdateSlowReaction:        {notReadyTolerationSeconds: "60", unreachableTolerationSeconds: "60"},
+}
+
+type latencyProfileObserver struct {
+	operatorClient v1helpers.OperatorClient
+}
+
+// NewLatencyProfileObserver returns an ObserveConfigFunc that translates the worker latency profile of
+// nodes.config.openshift.io/cluster into the default toleration seconds of the kube-apiserver. A profile
+// that conflicts with values set manually through unsupportedConfigOverrides is reported as an error.
+func NewLatencyProfileObserver(operatorClient v1helpers.OperatorClient) configobserver.ObserveConfigFunc {
+	return (&latencyProfileObserver{operatorClient: operatorClient}).observe
+}
+
+func (o *latencyProfileObserver) observe(genericListers configobserver.Listers, recorder events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, notReadyTolerationSecondsPath, unreachableTolerationSecondsPath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+
+	nodeConfig, err := listers.NodeConfigLister.Get("cluster")
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("%s.%s/cluster: not found", NodeConfigResource.Resource, NodeConfigResource.Group)
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+	unstructuredNodeConfig, ok := nodeConfig.(*unstructured.Unstructured)
+	if !ok {
+		return existingConfig, append(errs, fmt.Errorf("unexpected type %T for %s.%s/cluster", nodeConfig, NodeConfigResource.Resource, NodeConfigResource.Group))
+	}
+	profile, _, err := unstructured.NestedString(unstructuredNodeConfig.Object, "spec", "workerLatencyProfile")
+	if err != nil {
+		return existingConfig, append(errs, fmt.Errorf("unable to read spec.workerLatencyProfile: %v", err))
+	}
+	if len(profile) == 0 {
+		// no profile selected, keep the upstream defaults
+		return map[string]interface{}{}, nil
+	}
+
+	tolerations, ok := latencyProfiles[WorkerLatencyProfileType(profile)]
+	if !ok {
+		err := fmt.Errorf("unsupported worker latency profile %q", profile)
+		recorder.Warningf("ObserveWorkerLatencyProfileFailed", err.Error())
+		return existingConfig, append(errs, err)
+	}
+
+	observedConfig := map[string]interface{}{}
+	if err := unstructured.SetNestedStringSlice(observedConfig, []string{tolerations.notReadyTolerationSeconds}, notReadyTolerationSecondsPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	if err := unstructured.SetNestedStringSlice(observedConfig, []string{tolerations.unreachableTolerationSeconds}, unreachableTolerationSecondsPath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	currentNotReady, _, _ := unstructured.NestedStringSlice(existingConfig, notReadyTolerationSecondsPath...)
+	currentUnreachable, _, _ := unstructured.NestedStringSlice(existingConfig, unreachableTolerationSecondsPath...)
+	if !equalToSingleValue(currentNotReady, tolerations.notReadyTolerationSeconds) || !equalToSingleValue(currentUnreachable, tolerations.unreachableTolerationSeconds) {
+		recorder.Eventf("ObserveWorkerLatencyProfile", "Worker latency profile %q selected, default-not-ready-toleration-seconds=%s, default-unreachable-toleration-seconds=%s", profile, tolerations.notReadyTolerationSeconds, tolerations.unreachableTolerationSeconds)
+	}
+
+	// the unsupported overrides are merged on top of the observed config, make it visible when they win over the profile
+	conflicts, err := o.conflictingOverrides(tolerations)
+	if err != nil {
+		return observedConfig, append(errs, err)
+	}
+	for _, conflict := range conflicts {
+		err := fmt.Errorf("worker latency profile %q conflicts with spec.unsupportedConfigOverrides: %s", profile, conflict)
+		recorder.Warningf("WorkerLatencyProfileConflict", err.Error())
+		errs = append(errs, err)
+	}
+
+	return observedConfig, errs
+}
+
+// conflictingOverrides returns a description of every toleration argument that is set to a different value
+// through spec.unsupportedConfigOverrides.
+func (o *latencyProfileObserver) conflictingOverrides(tolerations latencyProfileTolerations) ([]string, error) {
+	spec, _, _, err := o.operatorClient.GetOperatorState()
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.UnsupportedConfigOverrides.Raw) == 0 {
+		return nil, nil
+	}
+	overrides := map[string]interface{}{}
+	if err := json.NewDecoder(bytes.NewBuffer(spec.UnsupportedConfigOverrides.Raw)).Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode spec.unsupportedConfigOverrides: %v", err)
+	}
+
+	var conflicts []string
+	for _, expected := range []struct {
+		path  []string
+		value string
+	}{
+		{path: notReadyTolerationSecondsPath, value: tolerations.notReadyTolerationSeconds},
+		{path: unreachableTolerationSecondsPath, value: tolerations.unreachableTolerationSeconds},
+	} {
+		actual, found, err := unstructured.NestedStringSlice(overrides, expected.path...)
+		if err != nil || !found {
+			continue
+		}
+		if !equalToSingleValue(actual, expected.value) {
+			conflicts = append(conflicts, fmt.Sprintf("%s=%v, expected %s", expected.path[len(expected.path)-1], actual, expected.value))
+		}
+	}
+	return conflicts, nil
+}
+
+func equalToSingleValue(values []string, value string) bool {
+	return len(values) == 1 && values[0] == value
+}
@@ -0,0 +1,159 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+)
+
+func TestObserveWorkerLatencyProfile(t *testing.T) {
+	scenarios := []struct {
+		name                       string
+		workerLatencyProfile       string
+		noNodeConfig               bool
+		unsupportedConfigOverrides string
+		existingConfig             map[string]interface{}
+		expectedConfig             map[string]interface{}
+		expectError                bool
+	}{
+		{
+			name:           "no node config",
+			noNodeConfig:   true,
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:           "no profile",
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name:                 "default profile",
+			workerLatencyProfile: "Default",
+			expectedConfig:       tolerationsConfig("300", "300"),
+		},
+		{
+			name:                 "medium profile",
+			workerLatencyProfile: "MediumUpdateAverageReaction",
+			existingConfig:       tolerationsConfig("300", "300"),
+			expectedConfig:       tolerationsConfig("60", "60"),
+		},
+		{
+			name:                 "low profile",
+			workerLatencyProfile: "LowUpdateSlowReaction",
+			expectedConfig:       tolerationsConfig("60", "60"),
+		},
+		{
+			name:                 "unknown profile keeps the existing config",
+			workerLatencyProfile: "Fastest",
+			existingConfig:       tolerationsConfig("60", "60"),
+			expectedConfig:       tolerationsConfig("60", "60"),
+			expectError:          true,
+		},
+		{
+			name:                       "matching unsupported override",
+			workerLatencyProfile:       "LowUpdateSlowReaction",
+			unsupportedConfigOverrides: `{"apiServerArguments":{"default-not-ready-toleration-seconds":["60"]}}`,
+			expectedConfig:             tolerationsConfig("60", "60"),
+		},
+		{
+			name:                       "conflicting unsupported override",
+			workerLatencyProfile:       "LowUpdateSlowReaction",
+			unsupportedConfigOverrides: `{"apiServerArguments":{"default-unreachable-toleration-seconds":["30"]}}`,
+			expectedConfig:             tolerationsConfig("60", "60"),
+			expectError:                true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if !scenario.noNodeConfig {
+				nodeConfig := &unstructured.Unstructured{}
+				nodeConfig.SetAPIVersion("config.openshift.io/v1")
+				nodeConfig.SetKind("Node")
+				nodeConfig.SetName("cluster")
+				if len(scenario.workerLatencyProfile) > 0 {
+					if err := unstructured.SetNestedField(nodeConfig.Object, scenario.workerLatencyProfile, "spec", "workerLatencyProfile"); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := indexer.Add(nodeConfig); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{
+				NodeConfigLister: cache.NewGenericLister(indexer, NodeConfigResource.GroupResource()),
+			}
+			operatorClient := v1helpers.NewFakeOperatorClient(
+				&operatorv1.OperatorSpec{
+					ManagementState:            operatorv1.Managed,
+					UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(scenario.unsupportedConfigOverrides)},
+				},
+				&operatorv1.OperatorStatus{},
+				nil,
+			)
+			existingConfig := scenario.existingConfig
+			if existingConfig == nil {
+				existingConfig = map[string]interface{}{}
+			}
+
+			observe := NewLatencyProfileObserver(operatorClient)
+			observedConfig, errs := observe(listers, events.NewInMemoryRecorder(t.Name()), existingConfig)
+
+			if scenario.expectError && len(errs) == 0 {
+				t.Fatalf("expected an error")
+			}
+			if !scenario.expectError && len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !cmp.Equal(scenario.expectedConfig, observedConfig) {
+				t.Fatalf("unexpected configuration, diff = %v", cmp.Diff(scenario.expectedConfig, observedConfig))
+			}
+		})
+	}
+}
+
+func tolerationsConfig(notReady, unreachable string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiServerArguments": map[string]interface{}{
+			"default-not-ready-toleration-seconds":   []interface{}{notReady},
+			"default-unreachable-toleration-seconds": []interface{}{unreachable},
+		},
+	}
+}
+
+func TestNodeConfigServed(t *testing.T) {
+	for _, scenario := range []struct {
+		name      string
+		resources []metav1.APIResource
+		expected  bool
+	}{
+		{name: "served", resources: []metav1.APIResource{{Name: "apiservers"}, {Name: "nodes"}}, expected: true},
+		{name: "no CRD", resources: []metav1.APIResource{{Name: "apiservers"}}},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+				{GroupVersion: "config.openshift.io/v1", APIResources: scenario.resources},
+			}}}
+			served, err := NodeConfigServed(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if served != scenario.expected {
+				t.Errorf("expected served to be %v, got %v", scenario.expected, served)
+			}
+		})
+	}
+}
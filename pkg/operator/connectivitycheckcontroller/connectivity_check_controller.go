@@ -2,17 +2,22 @@ package connectivitycheckcontroller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
 	operatorcontrolplaneclient "github.com/openshift/client-go/operatorcontrolplane/clientset/versioned"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/connectivitycheckcontroller"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -20,11 +25,16 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoveryv1listers "k8s.io/client-go/listers/discovery/v1"
 )
 
 type KubeAPIServerConnectivityCheckController interface {
@@ -52,7 +62,9 @@ func NewKubeAPIServerConnectivityCheckController(
 			[]factory.Informer{
 				kubeInformersForNamespaces.InformersFor("openshift-apiserver").Core().V1().Endpoints().Informer(),
 				kubeInformersForNamespaces.InformersFor("openshift-apiserver").Core().V1().Services().Informer(),
+				kubeInformersForNamespaces.InformersFor("openshift-apiserver").Discovery().V1().EndpointSlices().Informer(),
 				configInformers.Config().V1().Infrastructures().Informer(),
+				configInformers.Config().V1().Networks().Informer(),
 			},
 			recorder,
 			false,
@@ -60,11 +72,14 @@ func NewKubeAPIServerConnectivityCheckController(
 	}
 	generator := &connectivityCheckTemplateProvider{
 		kubeClient:           kubeClient,
+		checkClient:          operatorcontrolplaneClient,
 		operatorClient:       operatorClient,
 		endpointsLister:      kubeInformersForNamespaces.InformersFor("openshift-apiserver").Core().V1().Endpoints().Lister(),
 		serviceLister:        kubeInformersForNamespaces.InformersFor("openshift-apiserver").Core().V1().Services().Lister(),
+		endpointSliceLister:  kubeInformersForNamespaces.InformersFor("openshift-apiserver").Discovery().V1().EndpointSlices().Lister(),
 		nodeLister:           kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Lister(),
 		infrastructureLister: configInformers.Config().V1().Infrastructures().Lister(),
+		networkLister:        configInformers.Config().V1().Networks().Lister(),
 	}
 	return c.WithPodNetworkConnectivityCheckFn(generator.generate)
 }
@@ -75,15 +90,23 @@ type kubeAPIServerConnectivityCheckController struct {
 
 type connectivityCheckTemplateProvider struct {
 	kubeClient           kubernetes.Interface
+	checkClient          operatorcontrolplaneclient.Interface
 	operatorClient       v1helpers.OperatorClient
 	endpointsLister      corev1listers.EndpointsLister
 	serviceLister        corev1listers.ServiceLister
+	endpointSliceLister  discoveryv1listers.EndpointSliceLister
 	nodeLister           corev1listers.NodeLister
 	infrastructureLister configv1listers.InfrastructureLister
+	networkLister        configv1listers.NetworkLister
 }
 
 func (c *connectivityCheckTemplateProvider) generate(ctx context.Context, syncContext factory.SyncContext) ([]*v1alpha1.PodNetworkConnectivityCheck, error) {
 	var templates []*v1alpha1.PodNetworkConnectivityCheck
+	families, err := c.clusterIPFamilies()
+	if err != nil {
+		syncContext.Recorder().Warningf("EndpointDetectionFailure", "error detecting the IP families of the cluster: %v", err)
+	}
+
 	// each storage endpoint
 	etcdEndpoints, err := c.getTemplatesForEtcdEndpoints(syncContext)
 	if err != nil {
@@ -105,6 +128,15 @@ func (c *connectivityCheckTemplateProvider) generate(ctx context.Context, syncCo
 	}
 	templates = append(templates, oasEndpointIPs...)
 
+	// each oas endpoint of the secondary family of a dual-stack cluster
+	for _, family := range secondaryIPFamilies(families) {
+		oasEndpointIPs, err := c.getTemplatesForOpenShiftAPIServerEndpointsOfFamily(family)
+		if err != nil {
+			syncContext.Recorder().Warningf("EndpointDetectionFailure", "error detecting openshift-apiserver service endpoints of the %s family: %v", family, err)
+		}
+		templates = append(templates, oasEndpointIPs...)
+	}
+
 	// api load balancer endpoints
 	loadBalancerEndpoints, err := c.getTemplatesForApiLoadBalancerEndpoints(syncContext)
 	if err != nil {
@@ -112,6 +144,38 @@ func (c *connectivityCheckTemplateProvider) generate(ctx context.Context, syncCo
 	}
 	templates = append(templates, loadBalancerEndpoints...)
 
+	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the operatorSpec: %w", err)
+	}
+	config, err := observedConnectivityChecks(operatorSpec.ObservedConfig.Raw)
+	if err != nil {
+		syncContext.Recorder().Warningf("EndpointDetectionFailure", "error reading the connectivity checks config: %v", err)
+	}
+
+	// additional targets of the operator config
+	templates = append(templates, getTemplatesForAdditionalTargets(config.AdditionalTargets)...)
+
+	// a check per IP family of every target host name of a dual-stack cluster
+	templates = append(templates, ipFamilyTemplates(templates, families)...)
+
+	// the interval and timeout of the target classes, the additional targets may have their own
+	applyTargetClassSettings(templates, config.TargetClasses)
+
+	// the successes and the history that every check keeps
+	for _, template := range templates {
+		withSuccessLogInterval(config.SuccessLogInterval)(template)
+		withRetention(config.Retention)(template)
+	}
+
+	// a DNS check per target host name, so that DNS failures have their own outages
+	templates = append(templates, dnsCheckTemplates(templates)...)
+
+	labelIPFamilies(templates)
+
+	// targets that are unreachable on purpose, the connectivity check controller deletes their checks
+	templates = withoutDisabledTargets(templates, config.DisabledTargets)
+
 	nodes, err := c.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
 		LabelSelector: labels.Set{"node-role.kubernetes.io/master": ""}.AsSelector().String(),
 	})
@@ -131,6 +195,11 @@ func (c *connectivityCheckTemplateProvider) generate(ctx context.Context, syncCo
 		}
 	}
 
+	// the connectivity check controller only updates the spec of existing checks
+	if err := c.updateCheckSettings(ctx, checks); err != nil {
+		syncContext.Recorder().Warningf("EndpointDetectionFailure", "error updating the settings of the connectivity checks: %v", err)
+	}
+
 	return checks, nil
 }
 
@@ -140,10 +209,15 @@ func (c *connectivityCheckTemplateProvider) getTemplatesForOpenShiftAPIServerSer
 	if err != nil {
 		return nil, err
 	}
-	for _, address := range ips {
+	for i, address := range ips {
+		// a dual-stack service has a cluster IP per family, the primary one keeps the name of single-stack clusters
+		name := "cluster"
+		if host, _, err := net.SplitHostPort(address); err == nil && i > 0 {
+			name = "cluster-" + strings.ToLower(string(ipFamilyOf(net.ParseIP(host))))
+		}
 		templates = append(templates, connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate(address,
 			operatorclient.TargetNamespace,
-			withTarget("openshift-apiserver-service", "cluster"),
+			withTarget("openshift-apiserver-service", name),
 		))
 	}
 	return templates, nil
@@ -154,12 +228,22 @@ func (c *connectivityCheckTemplateProvider) listAddressesForOpenShiftAPIServerSe
 	if err != nil {
 		return nil, err
 	}
-	for _, port := range service.Spec.Ports {
-		if port.TargetPort.IntValue() == 6443 {
-			return []string{net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(port.Port)))}, nil
+	clusterIPs := service.Spec.ClusterIPs
+	if len(clusterIPs) == 0 {
+		clusterIPs = []string{service.Spec.ClusterIP}
+	}
+	port := "443"
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.TargetPort.IntValue() == 6443 {
+			port = strconv.Itoa(int(servicePort.Port))
+			break
 		}
 	}
-	return []string{net.JoinHostPort(service.Spec.ClusterIP, "443")}, nil
+	var addresses []string
+	for _, clusterIP := range clusterIPs {
+		addresses = append(addresses, net.JoinHostPort(clusterIP, port))
+	}
+	return addresses, nil
 }
 
 func (c *connectivityCheckTemplateProvider) getTemplatesForOpenShiftAPIServerEndpoints(syncContext factory.SyncContext) ([]*v1alpha1.PodNetworkConnectivityCheck, error) {
@@ -268,6 +352,216 @@ func (c *connectivityCheckTemplateProvider) getTemplatesForApiLoadBalancerEndpoi
 	return templates, err
 }
 
+// getTemplatesForAdditionalTargets returns a check of every additional target of the operator config.
+func getTemplatesForAdditionalTargets(targets []operatorconfig.ConnectivityCheckTarget) []*v1alpha1.PodNetworkConnectivityCheck {
+	var templates []*v1alpha1.PodNetworkConnectivityCheck
+	for _, target := range targets {
+		templates = append(templates, connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate(
+			target.Endpoint,
+			operatorclient.TargetNamespace,
+			withTarget(operatorconfig.ConnectivityCheckTargetClassAdditional, target.Name),
+			connectivitycheckcontroller.WithTlsClientCert(target.TLSClientCert),
+			withSettings(target.ConnectivityCheckSettings),
+		))
+	}
+	return templates
+}
+
+// observedConnectivityChecks returns the connectivity checks config in the observed config.
+func observedConnectivityChecks(rawObservedConfig []byte) (operatorconfig.ConnectivityChecksConfig, error) {
+	var observedConfig struct {
+		ConnectivityChecks operatorconfig.ConnectivityChecksConfig `json:"connectivityChecks"`
+	}
+	if len(rawObservedConfig) == 0 {
+		return observedConfig.ConnectivityChecks, nil
+	}
+	if err := json.Unmarshal(rawObservedConfig, &observedConfig); err != nil {
+		return observedConfig.ConnectivityChecks, fmt.Errorf("failed to unmarshal the observedConfig: %w", err)
+	}
+	return observedConfig.ConnectivityChecks, nil
+}
+
+// withSettings sets the interval, timeout and HTTP GET annotations that check-endpoints reads.
+func withSettings(settings operatorconfig.ConnectivityCheckSettings) func(check *v1alpha1.PodNetworkConnectivityCheck) {
+	return func(check *v1alpha1.PodNetworkConnectivityCheck) {
+		annotations := map[string]string{
+			v1alpha1helpers.IntervalAnnotation: settings.Interval,
+			v1alpha1helpers.TimeoutAnnotation:  settings.Timeout,
+		}
+		if httpGet := settings.HTTPGet; httpGet != nil {
+			var codes []string
+			for _, code := range httpGet.ExpectedStatusCodes {
+				codes = append(codes, strconv.Itoa(code))
+			}
+			annotations[v1alpha1helpers.HTTPGetPathAnnotation] = httpGet.Path
+			annotations[v1alpha1helpers.HTTPGetSchemeAnnotation] = httpGet.Scheme
+			annotations[v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation] = strings.Join(codes, ",")
+		}
+		for annotation, value := range annotations {
+			if len(value) == 0 {
+				continue
+			}
+			if check.Annotations == nil {
+				check.Annotations = map[string]string{}
+			}
+			check.Annotations[annotation] = value
+		}
+	}
+}
+
+// withSuccessLogInterval sets the success log interval annotation that check-endpoints reads.
+func withSuccessLogInterval(interval string) func(check *v1alpha1.PodNetworkConnectivityCheck) {
+	return func(check *v1alpha1.PodNetworkConnectivityCheck) {
+		if len(interval) == 0 {
+			return
+		}
+		if check.Annotations == nil {
+			check.Annotations = map[string]string{}
+		}
+		check.Annotations[v1alpha1helpers.SuccessLogIntervalAnnotation] = interval
+	}
+}
+
+// withRetention sets the retention annotations that check-endpoints reads.
+func withRetention(retention *operatorconfig.ConnectivityCheckRetention) func(check *v1alpha1.PodNetworkConnectivityCheck) {
+	return func(check *v1alpha1.PodNetworkConnectivityCheck) {
+		if retention == nil {
+			return
+		}
+		annotations := map[string]string{
+			v1alpha1helpers.MaxAgeAnnotation:              retention.MaxAge,
+			v1alpha1helpers.OutageCompactionGapAnnotation: retention.OutageCompactionGap,
+		}
+		if retention.MaxOutages > 0 {
+			annotations[v1alpha1helpers.MaxOutagesAnnotation] = strconv.Itoa(retention.MaxOutages)
+		}
+		if retention.MaxLogEntries > 0 {
+			annotations[v1alpha1helpers.MaxLogEntriesAnnotation] = strconv.Itoa(retention.MaxLogEntries)
+		}
+		for annotation, value := range annotations {
+			if len(value) == 0 {
+				continue
+			}
+			if check.Annotations == nil {
+				check.Annotations = map[string]string{}
+			}
+			check.Annotations[annotation] = value
+		}
+	}
+}
+
+// applyTargetClassSettings sets the interval, timeout and HTTP GET request of the class of every template whose target
+// has no settings of its own. The class is the prefix of the target in the name of the template.
+func applyTargetClassSettings(templates []*v1alpha1.PodNetworkConnectivityCheck, classes []operatorconfig.ConnectivityCheckTargetClass) {
+	for _, class := range classes {
+		for _, template := range templates {
+			if !strings.HasPrefix(template.Name, "$(SOURCE)-to-"+class.Name+"-") {
+				continue
+			}
+			settings := class.ConnectivityCheckSettings
+			if _, ok := template.Annotations[v1alpha1helpers.IntervalAnnotation]; ok {
+				settings.Interval = ""
+			}
+			if _, ok := template.Annotations[v1alpha1helpers.TimeoutAnnotation]; ok {
+				settings.Timeout = ""
+			}
+			if _, ok := template.Annotations[v1alpha1helpers.HTTPGetPathAnnotation]; ok {
+				settings.HTTPGet = nil
+			}
+			withSettings(settings)(template)
+		}
+	}
+}
+
+// updateCheckSettings updates the settings annotations and the IP family label of the existing checks.
+func (c *connectivityCheckTemplateProvider) updateCheckSettings(ctx context.Context, checks []*v1alpha1.PodNetworkConnectivityCheck) error {
+	var errs []error
+	for _, check := range checks {
+		existing, err := c.checkClient.ControlplaneV1alpha1().PodNetworkConnectivityChecks(check.Namespace).Get(ctx, check.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		updated := existing.DeepCopy()
+		for _, annotation := range v1alpha1helpers.SettingsAnnotations {
+			if value, ok := check.Annotations[annotation]; ok {
+				if updated.Annotations == nil {
+					updated.Annotations = map[string]string{}
+				}
+				updated.Annotations[annotation] = value
+			} else {
+				delete(updated.Annotations, annotation)
+			}
+		}
+		if family, ok := check.Labels[v1alpha1helpers.IPFamilyLabel]; ok {
+			if updated.Labels == nil {
+				updated.Labels = map[string]string{}
+			}
+			updated.Labels[v1alpha1helpers.IPFamilyLabel] = family
+		}
+		if equality.Semantic.DeepEqual(existing.Annotations, updated.Annotations) && equality.Semantic.DeepEqual(existing.Labels, updated.Labels) {
+			continue
+		}
+		if _, err := c.checkClient.ControlplaneV1alpha1().PodNetworkConnectivityChecks(check.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// dnsCheckTemplates returns a DNS check for every template whose target is a host name. The target endpoint of a DNS
+// check has no port, check-endpoints only resolves its host.
+func dnsCheckTemplates(templates []*v1alpha1.PodNetworkConnectivityCheck) []*v1alpha1.PodNetworkConnectivityCheck {
+	var dnsTemplates []*v1alpha1.PodNetworkConnectivityCheck
+	for _, template := range templates {
+		host, _, err := net.SplitHostPort(template.Spec.TargetEndpoint)
+		if err != nil || len(host) == 0 || net.ParseIP(host) != nil {
+			continue
+		}
+		check := template.DeepCopy()
+		check.Name = check.Name + "-dns"
+		check.Spec.TargetEndpoint = host + ":"
+		check.Spec.TLSClientCert = configv1.SecretNameReference{}
+		for _, annotation := range []string{v1alpha1helpers.HTTPGetPathAnnotation, v1alpha1helpers.HTTPGetSchemeAnnotation, v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation} {
+			delete(check.Annotations, annotation)
+		}
+		dnsTemplates = append(dnsTemplates, check)
+	}
+	return dnsTemplates
+}
+
+// withoutDisabledTargets returns the templates whose target is not disabled. A disabled target class disables all of
+// its targets, a disabled target its DNS and IP family checks as well.
+func withoutDisabledTargets(templates []*v1alpha1.PodNetworkConnectivityCheck, disabled []string) []*v1alpha1.PodNetworkConnectivityCheck {
+	if len(disabled) == 0 {
+		return templates
+	}
+	isDisabled := func(target string) bool {
+		for _, d := range disabled {
+			if sets.NewString(operatorconfig.ConnectivityCheckTargetClasses...).Has(d) && strings.HasPrefix(target, d+"-") {
+				return true
+			}
+			for _, suffix := range []string{"", "-dns", "-ipv4", "-ipv6", "-ipv4-dns", "-ipv6-dns"} {
+				if target == d+suffix {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var enabled []*v1alpha1.PodNetworkConnectivityCheck
+	for _, template := range templates {
+		if isDisabled(strings.TrimPrefix(template.Name, "$(SOURCE)-to-")) {
+			continue
+		}
+		enabled = append(enabled, template)
+	}
+	return enabled
+}
+
 func (c *connectivityCheckTemplateProvider) findNodeForInternalIP(internalIP string) (*corev1.Node, error) {
 	switch internalIP {
 	case "localhost", "127.0.0.1", "::1":
@@ -0,0 +1,120 @@
+package connectivitycheckcontroller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/connectivitycheckcontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestDNSCheckTemplates(t *testing.T) {
+	templates := []*v1alpha1.PodNetworkConnectivityCheck{
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-0")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("[fd00::1]:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-1")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api-int.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-internal")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("kms.example.com:5696", "openshift-kube-apiserver",
+			withTarget("additional", "kms"),
+			connectivitycheckcontroller.WithTlsClientCert("kms-client"),
+		),
+	}
+
+	var names, endpoints []string
+	for _, check := range dnsCheckTemplates(templates) {
+		names = append(names, check.Name)
+		endpoints = append(endpoints, check.Spec.TargetEndpoint)
+		if len(check.Spec.TLSClientCert.Name) > 0 {
+			t.Errorf("%s: expected no client certificate, got %s", check.Name, check.Spec.TLSClientCert.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"$(SOURCE)-to-load-balancer-api-internal-dns", "$(SOURCE)-to-additional-kms-dns"}, names); len(diff) > 0 {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"api-int.example.com:", "kms.example.com:"}, endpoints); len(diff) > 0 {
+		t.Error(diff)
+	}
+	if templates[3].Spec.TLSClientCert.Name != "kms-client" {
+		t.Errorf("expected the template to keep its client certificate")
+	}
+}
+
+func TestApplyTargetClassSettings(t *testing.T) {
+	templates := []*v1alpha1.PodNetworkConnectivityCheck{
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-0")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-external")),
+	}
+	templates = append(templates, getTemplatesForAdditionalTargets([]operatorconfig.ConnectivityCheckTarget{
+		{Name: "kms", Endpoint: "kms.example.com:5696", ConnectivityCheckSettings: operatorconfig.ConnectivityCheckSettings{Interval: "1m"}},
+		{Name: "oidc", Endpoint: "sso.example.com:80", ConnectivityCheckSettings: operatorconfig.ConnectivityCheckSettings{HTTPGet: &operatorconfig.ConnectivityCheckHTTPGet{Path: "/healthz", Scheme: "HTTP"}}},
+	})...)
+
+	applyTargetClassSettings(templates, []operatorconfig.ConnectivityCheckTargetClass{
+		{Name: "load-balancer", ConnectivityCheckSettings: operatorconfig.ConnectivityCheckSettings{Interval: "5s"}},
+		{Name: "additional", ConnectivityCheckSettings: operatorconfig.ConnectivityCheckSettings{Interval: "30s", Timeout: "3s", HTTPGet: &operatorconfig.ConnectivityCheckHTTPGet{Path: "/readyz", ExpectedStatusCodes: []int{200, 204}}}},
+	})
+
+	expected := map[string]map[string]string{
+		"$(SOURCE)-to-etcd-server-master-0":       nil,
+		"$(SOURCE)-to-load-balancer-api-external": {v1alpha1helpers.IntervalAnnotation: "5s"},
+		"$(SOURCE)-to-additional-kms": {
+			v1alpha1helpers.IntervalAnnotation:                   "1m",
+			v1alpha1helpers.TimeoutAnnotation:                    "3s",
+			v1alpha1helpers.HTTPGetPathAnnotation:                "/readyz",
+			v1alpha1helpers.HTTPGetExpectedStatusCodesAnnotation: "200,204",
+		},
+		"$(SOURCE)-to-additional-oidc": {
+			v1alpha1helpers.IntervalAnnotation:      "30s",
+			v1alpha1helpers.TimeoutAnnotation:       "3s",
+			v1alpha1helpers.HTTPGetPathAnnotation:   "/healthz",
+			v1alpha1helpers.HTTPGetSchemeAnnotation: "HTTP",
+		},
+	}
+	for _, template := range templates {
+		if diff := cmp.Diff(expected[template.Name], template.Annotations); len(diff) > 0 {
+			t.Errorf("%s: %s", template.Name, diff)
+		}
+	}
+}
+
+func TestWithRetention(t *testing.T) {
+	check := connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-0"))
+	withRetention(nil)(check)
+	if len(check.Annotations) > 0 {
+		t.Errorf("expected no annotations, got %v", check.Annotations)
+	}
+
+	withRetention(&operatorconfig.ConnectivityCheckRetention{MaxOutages: 5, OutageCompactionGap: "1m"})(check)
+	expected := map[string]string{
+		v1alpha1helpers.MaxOutagesAnnotation:          "5",
+		v1alpha1helpers.OutageCompactionGapAnnotation: "1m",
+	}
+	if diff := cmp.Diff(expected, check.Annotations); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
+
+func TestWithoutDisabledTargets(t *testing.T) {
+	templates := []*v1alpha1.PodNetworkConnectivityCheck{
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-1")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.10:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-10")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-external")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api.example.com:", "openshift-kube-apiserver", withTarget("load-balancer", "api-external-dns")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api-int.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-internal")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("172.30.0.10:443", "openshift-kube-apiserver", withTarget("openshift-apiserver-service", "cluster")),
+	}
+
+	var names []string
+	for _, check := range withoutDisabledTargets(templates, []string{"load-balancer-api-external", "etcd-server-master-1", "openshift-apiserver-service"}) {
+		names = append(names, check.Name)
+	}
+	expected := []string{"$(SOURCE)-to-etcd-server-master-10", "$(SOURCE)-to-load-balancer-api-internal"}
+	if diff := cmp.Diff(expected, names); len(diff) > 0 {
+		t.Error(diff)
+	}
+	if len(withoutDisabledTargets(templates, nil)) != len(templates) {
+		t.Errorf("expected all templates without disabled targets")
+	}
+}
@@ -0,0 +1,136 @@
+package connectivitycheckcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	operatorcontrolplaneinformers "github.com/openshift/client-go/operatorcontrolplane/informers/externalversions"
+	operatorcontrolplanev1alpha1listers "github.com/openshift/client-go/operatorcontrolplane/listers/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	ConnectivityOutageConditionType = "ConnectivityOutage"
+
+	// sustainedOutageDuration is how long a check has to fail before its outage is reported. Shorter outages, e.g.
+	// while the target restarts, are expected.
+	sustainedOutageDuration = 5 * time.Minute
+
+	// maxReportedOutages limits the length of the condition message on large clusters with many failing checks
+	maxReportedOutages = 10
+)
+
+// ConnectivityOutageController summarizes the sustained outages of the PodNetworkConnectivityChecks in
+// openshift-kube-apiserver in the ConnectivityOutage condition, so that they show up in the operator status without
+// reading the check resources of every source and target.
+type ConnectivityOutageController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	checkLister    operatorcontrolplanev1alpha1listers.PodNetworkConnectivityCheckLister
+
+	now func() time.Time
+}
+
+func NewConnectivityOutageController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	operatorcontrolplaneInformers operatorcontrolplaneinformers.SharedInformerFactory,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &ConnectivityOutageController{
+		operatorClient: operatorClient,
+		checkLister:    operatorcontrolplaneInformers.Controlplane().V1alpha1().PodNetworkConnectivityChecks().Lister(),
+		now:            time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		operatorcontrolplaneInformers.Controlplane().V1alpha1().PodNetworkConnectivityChecks().Informer(),
+	).WithSync(syncmetrics.Instrument("ConnectivityOutageController", c.sync)).ResyncEvery(resyncinterval.For("ConnectivityOutageController", time.Minute)).ToController("ConnectivityOutageController", eventRecorder.WithComponentSuffix("connectivity-outage-controller"))
+}
+
+func (c *ConnectivityOutageController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	checks, err := c.checkLister.PodNetworkConnectivityChecks(operatorclient.TargetNamespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	condition := newConnectivityOutageCondition(sustainedOutages(checks, c.now()))
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// outage is an ongoing outage of a check.
+type outage struct {
+	source string
+	target string
+	since  time.Time
+	// message is the message of the latest failure
+	message string
+}
+
+// sustainedOutages returns the ongoing outages of the checks that started more than sustainedOutageDuration ago,
+// ordered by source and target. The check of a source pod that is gone is not updated anymore, its outage is only
+// ongoing while the latest failure is recent.
+func sustainedOutages(checks []*v1alpha1.PodNetworkConnectivityCheck, now time.Time) []outage {
+	var outages []outage
+	for _, check := range checks {
+		if len(check.Status.Outages) == 0 || len(check.Status.Failures) == 0 {
+			continue
+		}
+		latest := check.Status.Outages[0]
+		if !latest.End.IsZero() || now.Sub(latest.Start.Time) < sustainedOutageDuration {
+			continue
+		}
+		if now.Sub(check.Status.Failures[0].Start.Time) > sustainedOutageDuration {
+			continue
+		}
+		outages = append(outages, outage{
+			source:  check.Spec.SourcePod,
+			target:  check.Spec.TargetEndpoint,
+			since:   latest.Start.Time,
+			message: check.Status.Failures[0].Message,
+		})
+	}
+	sort.Slice(outages, func(i, j int) bool {
+		if outages[i].source != outages[j].source {
+			return outages[i].source < outages[j].source
+		}
+		return outages[i].target < outages[j].target
+	})
+	return outages
+}
+
+func newConnectivityOutageCondition(outages []outage) operatorv1.OperatorCondition {
+	if len(outages) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   ConnectivityOutageConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	var lines []string
+	for i, o := range outages {
+		if i == maxReportedOutages {
+			lines = append(lines, fmt.Sprintf("and %d more", len(outages)-maxReportedOutages))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s to %s failing since %s: %s", o.source, o.target, o.since.UTC().Format(time.RFC3339), o.message))
+	}
+	return operatorv1.OperatorCondition{
+		Type:    ConnectivityOutageConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "SustainedOutage",
+		Message: strings.Join(lines, "\n"),
+	}
+}
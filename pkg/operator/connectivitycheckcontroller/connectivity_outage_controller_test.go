@@ -0,0 +1,75 @@
+package connectivitycheckcontroller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSustainedOutages(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	check := func(source, target string, outageStart, outageEnd, lastFailure time.Time) *v1alpha1.PodNetworkConnectivityCheck {
+		c := &v1alpha1.PodNetworkConnectivityCheck{
+			Spec: v1alpha1.PodNetworkConnectivityCheckSpec{SourcePod: source, TargetEndpoint: target},
+		}
+		if !outageStart.IsZero() {
+			c.Status.Outages = []v1alpha1.OutageEntry{{Start: metav1.NewTime(outageStart), End: metav1.NewTime(outageEnd)}}
+		}
+		if !lastFailure.IsZero() {
+			c.Status.Failures = []v1alpha1.LogEntry{{Start: metav1.NewTime(lastFailure), Message: "connection refused"}}
+		}
+		return c
+	}
+
+	checks := []*v1alpha1.PodNetworkConnectivityCheck{
+		check("kube-apiserver-master-1", "10.0.0.1:2379", now.Add(-10*time.Minute), time.Time{}, now.Add(-time.Second)),
+		check("kube-apiserver-master-0", "10.0.0.2:2379", now.Add(-6*time.Minute), time.Time{}, now.Add(-time.Second)),
+		// too short
+		check("kube-apiserver-master-0", "10.0.0.3:2379", now.Add(-time.Minute), time.Time{}, now.Add(-time.Second)),
+		// over
+		check("kube-apiserver-master-0", "10.0.0.4:2379", now.Add(-time.Hour), now.Add(-30*time.Minute), now.Add(-30*time.Minute)),
+		// source gone
+		check("kube-apiserver-master-2", "10.0.0.1:2379", now.Add(-time.Hour), time.Time{}, now.Add(-30*time.Minute)),
+		// never failed
+		check("kube-apiserver-master-0", "10.0.0.5:2379", time.Time{}, time.Time{}, time.Time{}),
+	}
+
+	var pairs []string
+	for _, o := range sustainedOutages(checks, now) {
+		pairs = append(pairs, o.source+" "+o.target)
+	}
+	expected := []string{"kube-apiserver-master-0 10.0.0.2:2379", "kube-apiserver-master-1 10.0.0.1:2379"}
+	if diff := cmp.Diff(expected, pairs); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
+
+func TestNewConnectivityOutageCondition(t *testing.T) {
+	if condition := newConnectivityOutageCondition(nil); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected no outage, got %#v", condition)
+	}
+
+	since := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	condition := newConnectivityOutageCondition([]outage{{source: "kube-apiserver-master-0", target: "10.0.0.1:2379", since: since, message: "etcd-server-master-1: connection refused"}})
+	if condition.Status != operatorv1.ConditionTrue || condition.Reason != "SustainedOutage" {
+		t.Errorf("expected a sustained outage, got %#v", condition)
+	}
+	if expected := "kube-apiserver-master-0 to 10.0.0.1:2379 failing since 2021-06-01T12:00:00Z: etcd-server-master-1: connection refused"; condition.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, condition.Message)
+	}
+
+	var outages []outage
+	for i := 0; i < maxReportedOutages+3; i++ {
+		outages = append(outages, outage{source: "kube-apiserver-master-0", target: fmt.Sprintf("10.0.0.%d:2379", i), since: since})
+	}
+	lines := strings.Split(newConnectivityOutageCondition(outages).Message, "\n")
+	if len(lines) != maxReportedOutages+1 || lines[maxReportedOutages] != "and 3 more" {
+		t.Errorf("expected %d outages and a summary, got %q", maxReportedOutages, lines)
+	}
+}
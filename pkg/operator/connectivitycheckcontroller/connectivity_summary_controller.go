@@ -0,0 +1,177 @@
+package connectivitycheckcontroller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	operatorcontrolplaneinformers "github.com/openshift/client-go/operatorcontrolplane/informers/externalversions"
+	operatorcontrolplanev1alpha1listers "github.com/openshift/client-go/operatorcontrolplane/listers/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/informers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConnectivitySummaryLabel marks the connectivity summary config maps of the source nodes.
+	ConnectivitySummaryLabel = "check-endpoints.openshift.io/connectivity-summary"
+
+	connectivitySummaryPrefix = "connectivity-summary-"
+)
+
+// ConnectivitySummaryController maintains a connectivity-summary-<node> config map in openshift-kube-apiserver per
+// node with a kube-apiserver, with the targets that its checks currently fail to reach and the time of the latest
+// outage start or end, so that a node with network trouble is found without reading the checks of every source and
+// target.
+type ConnectivitySummaryController struct {
+	configMapClient corev1client.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+	checkLister     operatorcontrolplanev1alpha1listers.PodNetworkConnectivityCheckLister
+
+	now func() time.Time
+}
+
+func NewConnectivitySummaryController(
+	configMapClient corev1client.ConfigMapsGetter,
+	kubeInformersForTargetNamespace informers.SharedInformerFactory,
+	operatorcontrolplaneInformers operatorcontrolplaneinformers.SharedInformerFactory,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &ConnectivitySummaryController{
+		configMapClient: configMapClient,
+		configMapLister: kubeInformersForTargetNamespace.Core().V1().ConfigMaps().Lister(),
+		checkLister:     operatorcontrolplaneInformers.Controlplane().V1alpha1().PodNetworkConnectivityChecks().Lister(),
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		kubeInformersForTargetNamespace.Core().V1().ConfigMaps().Informer(),
+		operatorcontrolplaneInformers.Controlplane().V1alpha1().PodNetworkConnectivityChecks().Informer(),
+	).WithSync(syncmetrics.Instrument("ConnectivitySummaryController", c.sync)).ResyncEvery(resyncinterval.For("ConnectivitySummaryController", time.Minute)).ToController("ConnectivitySummaryController", eventRecorder.WithComponentSuffix("connectivity-summary-controller"))
+}
+
+func (c *ConnectivitySummaryController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	checks, err := c.checkLister.PodNetworkConnectivityChecks(operatorclient.TargetNamespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	summaries := connectivitySummaries(checks, c.now())
+
+	var errs []error
+	for _, summary := range summaries {
+		if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// the summaries of nodes without checks, e.g. of removed masters
+	existing, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{ConnectivitySummaryLabel: ""}))
+	if err != nil {
+		return err
+	}
+	for _, configMap := range existing {
+		if _, ok := summaries[configMap.Name]; ok {
+			continue
+		}
+		err := c.configMapClient.ConfigMaps(configMap.Namespace).Delete(ctx, configMap.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// failingTarget is a target a source currently fails to reach.
+type failingTarget struct {
+	target   string
+	endpoint string
+	since    time.Time
+}
+
+// connectivitySummaries returns the summary config map of every source node, by name. The checks of a source pod
+// that is gone are not updated anymore, their outages only count while the latest failure is recent.
+func connectivitySummaries(checks []*v1alpha1.PodNetworkConnectivityCheck, now time.Time) map[string]*corev1.ConfigMap {
+	type nodeSummary struct {
+		sourcePod      string
+		targets        int
+		failing        []failingTarget
+		lastTransition time.Time
+	}
+	nodes := map[string]*nodeSummary{}
+	for _, check := range checks {
+		node := strings.TrimPrefix(check.Spec.SourcePod, "kube-apiserver-")
+		if len(check.Spec.SourcePod) == 0 || node == check.Spec.SourcePod {
+			continue
+		}
+		summary, ok := nodes[node]
+		if !ok {
+			summary = &nodeSummary{sourcePod: check.Spec.SourcePod}
+			nodes[node] = summary
+		}
+		summary.targets++
+		if len(check.Status.Outages) == 0 {
+			continue
+		}
+		latest := check.Status.Outages[0]
+		transition := latest.End.Time
+		if latest.End.IsZero() {
+			transition = latest.Start.Time
+		}
+		if transition.After(summary.lastTransition) {
+			summary.lastTransition = transition
+		}
+		if !latest.End.IsZero() || len(check.Status.Failures) == 0 || now.Sub(check.Status.Failures[0].Start.Time) > sustainedOutageDuration {
+			continue
+		}
+		summary.failing = append(summary.failing, failingTarget{
+			target:   regexp.MustCompile(".*-to-").ReplaceAllString(check.Name, ""),
+			endpoint: check.Spec.TargetEndpoint,
+			since:    latest.Start.Time,
+		})
+	}
+
+	configMaps := map[string]*corev1.ConfigMap{}
+	for node, summary := range nodes {
+		sort.Slice(summary.failing, func(i, j int) bool { return summary.failing[i].target < summary.failing[j].target })
+		var failing []string
+		for _, f := range summary.failing {
+			failing = append(failing, fmt.Sprintf("%s %s since %s", f.target, f.endpoint, f.since.UTC().Format(time.RFC3339)))
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: operatorclient.TargetNamespace,
+				Name:      connectivitySummaryPrefix + node,
+				Labels:    map[string]string{ConnectivitySummaryLabel: ""},
+			},
+			Data: map[string]string{
+				"node":               node,
+				"sourcePod":          summary.sourcePod,
+				"targetCount":        strconv.Itoa(summary.targets),
+				"failingTargetCount": strconv.Itoa(len(failing)),
+				"failingTargets":     strings.Join(failing, "\n"),
+				"lastTransitionTime": "",
+			},
+		}
+		if !summary.lastTransition.IsZero() {
+			configMap.Data["lastTransitionTime"] = summary.lastTransition.UTC().Format(time.RFC3339)
+		}
+		configMaps[configMap.Name] = configMap
+	}
+	return configMaps
+}
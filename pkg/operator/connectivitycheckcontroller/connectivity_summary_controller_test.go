@@ -0,0 +1,76 @@
+package connectivitycheckcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConnectivitySummaries(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	check := func(source, target, endpoint string, outageStart, outageEnd, lastFailure time.Time) *v1alpha1.PodNetworkConnectivityCheck {
+		c := &v1alpha1.PodNetworkConnectivityCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: source + "-to-" + target},
+			Spec:       v1alpha1.PodNetworkConnectivityCheckSpec{SourcePod: source, TargetEndpoint: endpoint},
+		}
+		if !outageStart.IsZero() {
+			c.Status.Outages = []v1alpha1.OutageEntry{{Start: metav1.NewTime(outageStart), End: metav1.NewTime(outageEnd)}}
+		}
+		if !lastFailure.IsZero() {
+			c.Status.Failures = []v1alpha1.LogEntry{{Start: metav1.NewTime(lastFailure)}}
+		}
+		return c
+	}
+
+	checks := []*v1alpha1.PodNetworkConnectivityCheck{
+		check("kube-apiserver-master-0", "load-balancer-api-internal", "api-int.example.com:6443", now.Add(-time.Minute), time.Time{}, now.Add(-time.Second)),
+		check("kube-apiserver-master-0", "etcd-server-master-1", "10.0.0.2:2379", now.Add(-10*time.Minute), time.Time{}, now.Add(-time.Second)),
+		// over
+		check("kube-apiserver-master-0", "etcd-server-master-2", "10.0.0.3:2379", now.Add(-time.Hour), now.Add(-30*time.Minute), now.Add(-30*time.Minute)),
+		check("kube-apiserver-master-1", "etcd-server-master-0", "10.0.0.1:2379", now.Add(-time.Hour), now.Add(-2*time.Minute), now.Add(-2*time.Minute)),
+		// never failed
+		check("kube-apiserver-master-1", "etcd-server-master-2", "10.0.0.3:2379", time.Time{}, time.Time{}, time.Time{}),
+		// source gone
+		check("kube-apiserver-master-2", "etcd-server-master-0", "10.0.0.1:2379", now.Add(-time.Hour), time.Time{}, now.Add(-30*time.Minute)),
+	}
+
+	expected := map[string]map[string]string{
+		"connectivity-summary-master-0": {
+			"node":               "master-0",
+			"sourcePod":          "kube-apiserver-master-0",
+			"targetCount":        "3",
+			"failingTargetCount": "2",
+			"failingTargets":     "etcd-server-master-1 10.0.0.2:2379 since 2021-06-01T11:50:00Z\nload-balancer-api-internal api-int.example.com:6443 since 2021-06-01T11:59:00Z",
+			"lastTransitionTime": "2021-06-01T11:59:00Z",
+		},
+		"connectivity-summary-master-1": {
+			"node":               "master-1",
+			"sourcePod":          "kube-apiserver-master-1",
+			"targetCount":        "2",
+			"failingTargetCount": "0",
+			"failingTargets":     "",
+			"lastTransitionTime": "2021-06-01T11:58:00Z",
+		},
+		"connectivity-summary-master-2": {
+			"node":               "master-2",
+			"sourcePod":          "kube-apiserver-master-2",
+			"targetCount":        "1",
+			"failingTargetCount": "0",
+			"failingTargets":     "",
+			"lastTransitionTime": "2021-06-01T11:00:00Z",
+		},
+	}
+	actual := map[string]map[string]string{}
+	for name, configMap := range connectivitySummaries(checks, now) {
+		if _, ok := configMap.Labels[ConnectivitySummaryLabel]; !ok || configMap.Namespace != "openshift-kube-apiserver" {
+			t.Errorf("%s: expected a labeled config map in openshift-kube-apiserver, got %#v", name, configMap.ObjectMeta)
+		}
+		actual[name] = configMap.Data
+	}
+	if diff := cmp.Diff(expected, actual); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,135 @@
+package connectivitycheckcontroller
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/connectivitycheckcontroller"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// clusterIPFamilies returns the IP families of the service network, the primary family first. A dual-stack cluster
+// has both.
+func (c *connectivityCheckTemplateProvider) clusterIPFamilies() ([]corev1.IPFamily, error) {
+	network, err := c.networkLister.Get("cluster")
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	serviceNetwork := network.Status.ServiceNetwork
+	if len(serviceNetwork) == 0 {
+		serviceNetwork = network.Spec.ServiceNetwork
+	}
+	var families []corev1.IPFamily
+	for _, cidr := range serviceNetwork {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service network %q: %w", cidr, err)
+		}
+		family := ipFamilyOf(ip)
+		if len(families) == 0 || families[0] != family {
+			families = append(families, family)
+		}
+	}
+	return families, nil
+}
+
+// secondaryIPFamilies returns the IP families of a dual-stack cluster besides the primary one.
+func secondaryIPFamilies(families []corev1.IPFamily) []corev1.IPFamily {
+	if len(families) < 2 {
+		return nil
+	}
+	return families[1:]
+}
+
+// ipFamilyTemplates returns a check per IP family for every template whose target is a host name. The check of the
+// host name connects to any address of the host and would hide a broken secondary family.
+func ipFamilyTemplates(templates []*v1alpha1.PodNetworkConnectivityCheck, families []corev1.IPFamily) []*v1alpha1.PodNetworkConnectivityCheck {
+	if len(families) < 2 {
+		return nil
+	}
+	var familyTemplates []*v1alpha1.PodNetworkConnectivityCheck
+	for _, template := range templates {
+		host, _, err := net.SplitHostPort(template.Spec.TargetEndpoint)
+		if err != nil || len(host) == 0 || net.ParseIP(host) != nil {
+			continue
+		}
+		for _, family := range families {
+			check := template.DeepCopy()
+			check.Name = check.Name + "-" + strings.ToLower(string(family))
+			withIPFamily(family)(check)
+			familyTemplates = append(familyTemplates, check)
+		}
+	}
+	return familyTemplates
+}
+
+// getTemplatesForOpenShiftAPIServerEndpointsOfFamily returns the checks of the openshift-apiserver endpoints of an IP
+// family that the api endpoints do not have. The endpoints only have the addresses of the primary family, the
+// endpoint slices have one slice per family.
+func (c *connectivityCheckTemplateProvider) getTemplatesForOpenShiftAPIServerEndpointsOfFamily(family corev1.IPFamily) ([]*v1alpha1.PodNetworkConnectivityCheck, error) {
+	slices, err := c.endpointSliceLister.EndpointSlices("openshift-apiserver").List(labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: "api"}))
+	if err != nil {
+		return nil, err
+	}
+	var templates []*v1alpha1.PodNetworkConnectivityCheck
+	for _, slice := range slices {
+		if string(slice.AddressType) != string(family) || len(slice.Ports) == 0 || slice.Ports[0].Port == nil {
+			continue
+		}
+		port := strconv.Itoa(int(*slice.Ports[0].Port))
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.NodeName == nil || len(endpoint.Addresses) == 0 {
+				continue
+			}
+			templates = append(templates, connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate(
+				net.JoinHostPort(endpoint.Addresses[0], port),
+				operatorclient.TargetNamespace,
+				withTarget("openshift-apiserver-endpoint", *endpoint.NodeName+"-"+strings.ToLower(string(family))),
+			))
+		}
+	}
+	return templates, nil
+}
+
+// withIPFamily labels the check with the IP family of its target. check-endpoints only connects to the addresses of
+// that family of a host name.
+func withIPFamily(family corev1.IPFamily) func(check *v1alpha1.PodNetworkConnectivityCheck) {
+	return func(check *v1alpha1.PodNetworkConnectivityCheck) {
+		if check.Labels == nil {
+			check.Labels = map[string]string{}
+		}
+		check.Labels[v1alpha1helpers.IPFamilyLabel] = string(family)
+	}
+}
+
+// labelIPFamilies labels the checks of IP addresses with their family.
+func labelIPFamilies(templates []*v1alpha1.PodNetworkConnectivityCheck) {
+	for _, template := range templates {
+		host, _, err := net.SplitHostPort(template.Spec.TargetEndpoint)
+		if err != nil {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			withIPFamily(ipFamilyOf(ip))(template)
+		}
+	}
+}
+
+func ipFamilyOf(ip net.IP) corev1.IPFamily {
+	if ip.To4() != nil {
+		return corev1.IPv4Protocol
+	}
+	return corev1.IPv6Protocol
+}
@@ -0,0 +1,70 @@
+package connectivitycheckcontroller
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/api/operatorcontrolplane/v1alpha1"
+	"github.com/openshift/library-go/pkg/operator/connectivitycheckcontroller"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints/operatorcontrolplane/podnetworkconnectivitycheck/v1alpha1helpers"
+)
+
+func TestIPFamilyTemplates(t *testing.T) {
+	templates := []*v1alpha1.PodNetworkConnectivityCheck{
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-0")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api-int.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-internal")),
+	}
+	dualStack := []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+
+	if familyTemplates := ipFamilyTemplates(templates, []corev1.IPFamily{corev1.IPv4Protocol}); len(familyTemplates) > 0 {
+		t.Errorf("expected no checks per family on single-stack clusters, got %d", len(familyTemplates))
+	}
+
+	var names, families []string
+	for _, check := range ipFamilyTemplates(templates, dualStack) {
+		names = append(names, check.Name)
+		families = append(families, check.Labels[v1alpha1helpers.IPFamilyLabel])
+	}
+	if diff := cmp.Diff([]string{"$(SOURCE)-to-load-balancer-api-internal-ipv4", "$(SOURCE)-to-load-balancer-api-internal-ipv6"}, names); len(diff) > 0 {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"IPv4", "IPv6"}, families); len(diff) > 0 {
+		t.Error(diff)
+	}
+	if _, ok := templates[1].Labels[v1alpha1helpers.IPFamilyLabel]; ok {
+		t.Errorf("expected the template of the host name to have no IP family")
+	}
+}
+
+func TestLabelIPFamilies(t *testing.T) {
+	templates := []*v1alpha1.PodNetworkConnectivityCheck{
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("10.0.0.1:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-0")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("[fd00::1]:2379", "openshift-kube-apiserver", withTarget("etcd-server", "master-1")),
+		connectivitycheckcontroller.NewPodNetworkConnectivityCheckTemplate("api-int.example.com:6443", "openshift-kube-apiserver", withTarget("load-balancer", "api-internal")),
+	}
+	labelIPFamilies(templates)
+
+	var families []string
+	for _, check := range templates {
+		families = append(families, check.Labels[v1alpha1helpers.IPFamilyLabel])
+	}
+	if diff := cmp.Diff([]string{"IPv4", "IPv6", ""}, families); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
+
+func TestIPFamilyOf(t *testing.T) {
+	for ip, expected := range map[string]corev1.IPFamily{
+		"10.0.0.1":         corev1.IPv4Protocol,
+		"::ffff:10.0.0.1":  corev1.IPv4Protocol,
+		"fd00::1":          corev1.IPv6Protocol,
+		"2001:db8::8a2e:1": corev1.IPv6Protocol,
+	} {
+		if family := ipFamilyOf(net.ParseIP(ip)); family != expected {
+			t.Errorf("%s: expected %s, got %s", ip, expected, family)
+		}
+	}
+}
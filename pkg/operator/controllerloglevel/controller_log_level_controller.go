@@ -0,0 +1,89 @@
+package controllerloglevel
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/loglevel"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+// ControllerLogLevelController applies logging.operatorControllers of the operator config to the klog vmodule of the
+// operator, so that a single controller can be debugged without raising spec.operatorLogLevel and the log volume of all
+// controllers. The files of a controller are logged at its level, regardless of the operator log level. The vmodule the
+// operator was started with applies to the other files.
+type ControllerLogLevelController struct {
+	configMapLister corev1listers.ConfigMapLister
+	vmodule         flag.Value
+	// defaultVModule is the vmodule the operator was started with
+	defaultVModule string
+}
+
+func NewControllerLogLevelController(
+	configMapInformer corev1informers.ConfigMapInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &ControllerLogLevelController{
+		configMapLister: configMapInformer.Lister(),
+	}
+	if f := flag.CommandLine.Lookup("vmodule"); f != nil {
+		c.vmodule = f.Value
+		c.defaultVModule = f.Value.String()
+	}
+	return factory.New().WithInformers(
+		configMapInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("ControllerLogLevelController", c.sync)).ResyncEvery(resyncinterval.For("ControllerLogLevelController", 5*time.Minute)).ToController("ControllerLogLevelController", eventRecorder.WithComponentSuffix("controller-log-level-controller"))
+}
+
+func (c *ControllerLogLevelController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	if c.vmodule == nil {
+		return fmt.Errorf("the klog vmodule flag is not registered")
+	}
+	config, err := operatorconfig.Get(c.configMapLister)
+	if err != nil {
+		return err
+	}
+	// the invalid config is also reported by the logging observer
+	if errs := operatorconfig.ValidateLoggingConfig(config.Logging, field.NewPath("logging")); len(errs) > 0 {
+		return fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+
+	vmodule := newVModule(config.Logging.OperatorControllers, c.defaultVModule)
+	if vmodule == c.vmodule.String() {
+		return nil
+	}
+	if err := c.vmodule.Set(vmodule); err != nil {
+		return err
+	}
+	syncCtx.Recorder().Eventf("OperatorControllerLogLevelsChanged", "log levels of the operator controllers changed to %v", config.Logging.OperatorControllers)
+	return nil
+}
+
+// newVModule returns a vmodule with a pattern per file of every controller, followed by the default vmodule. klog
+// applies the first pattern that matches a file, the configured controllers take precedence.
+func newVModule(controllers map[string]operatorv1.LogLevel, defaultVModule string) string {
+	var patterns []string
+	for _, controller := range sets.StringKeySet(controllers).List() {
+		verbosity := loglevel.LogLevelToVerbosity(controllers[controller])
+		for _, file := range operatorconfig.OperatorControllerSourceFiles[controller] {
+			patterns = append(patterns, fmt.Sprintf("%s=%d", file, verbosity))
+		}
+	}
+	if len(defaultVModule) > 0 {
+		patterns = append(patterns, defaultVModule)
+	}
+	return strings.Join(patterns, ",")
+}
@@ -0,0 +1,33 @@
+package controllerloglevel
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestNewVModule(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		controllers    map[string]operatorv1.LogLevel
+		defaultVModule string
+		expected       string
+	}{
+		{name: "empty"},
+		{name: "default only", defaultVModule: "httplog=4", expected: "httplog=4"},
+		{
+			name:           "controllers before the default",
+			controllers:    map[string]operatorv1.LogLevel{"terminationObserver": operatorv1.Trace, "installer": operatorv1.Debug},
+			defaultVModule: "installer_controller=2",
+			expected:       "installer_controller=4,installer_state_controller=4,termination_observer=6,late_connections=6,disruption=6,installer_controller=2",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if vmodule := newVModule(scenario.controllers, scenario.defaultVModule); vmodule != scenario.expected {
+				t.Errorf("expected %q, got %q", scenario.expected, vmodule)
+			}
+		})
+	}
+}
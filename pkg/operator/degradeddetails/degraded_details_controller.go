@@ -0,0 +1,186 @@
+package degradeddetails
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/degradedreasons"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/healthsummary"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/staticpoddetection"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+// ConfigMapName is the configmap in the operator namespace with the details of every true Degraded condition, keyed by
+// condition type.
+const ConfigMapName = "degraded-details"
+
+// nodeFailureConditions are the Degraded conditions the node failures of a reason are reported by.
+var nodeFailureConditions = map[string]string{
+	degradedreasons.FetchFailureReason:        "NodeInstallerDegraded",
+	degradedreasons.DiskFailureReason:         "NodeInstallerDegraded",
+	degradedreasons.CrashLoopReason:           "StaticPodsDegraded",
+	degradedreasons.ManifestInvalidReason:     "StaticPodsDegraded",
+	degradedreasons.KubeletNotObservingReason: staticpoddetection.MissingStaticPodDegradedConditionType,
+}
+
+// nodeFailureRemediations are the remediation keys of the node failures of a reason.
+var nodeFailureRemediations = map[string][]string{
+	degradedreasons.FetchFailureReason:        {"check-apiserver-connectivity"},
+	degradedreasons.DiskFailureReason:         {"free-node-disk"},
+	degradedreasons.CrashLoopReason:           {"inspect-kube-apiserver-logs"},
+	degradedreasons.ManifestInvalidReason:     {"inspect-static-pod-manifest"},
+	degradedreasons.KubeletNotObservingReason: {"restart-kubelet"},
+}
+
+// subsystemRemediations are the remediation keys of the other Degraded conditions, by the subsystem of the health
+// summary they belong to.
+var subsystemRemediations = map[string][]string{
+	"rollout":      {"inspect-rollout"},
+	"certs":        {"check-certificates"},
+	"encryption":   {"check-encryption"},
+	"audit":        {"fix-audit-policy-config"},
+	"connectivity": {"check-network"},
+	"nodeSkew":     {"upgrade-kubelets"},
+	"":             {"inspect-operator-logs"},
+}
+
+// operatorObject is the object a Degraded condition affects when nothing more specific is known.
+var operatorObject = configv1.ObjectReference{Group: operatorv1.GroupName, Resource: "kubeapiservers", Name: "cluster"}
+
+// Detail is a failure behind a Degraded condition.
+type Detail struct {
+	// errorClass is a stable name of the failure, the reason of the condition unless the failure is classified
+	ErrorClass string `json:"errorClass"`
+	// affectedObjects are the objects that fail, nodes or the operator itself
+	AffectedObjects []configv1.ObjectReference `json:"affectedObjects"`
+	// remediation are stable keys of the remediations that apply, for the runbooks of auto-remediation
+	Remediation []string `json:"remediation"`
+}
+
+// DegradedDetailsController publishes the failures behind every true Degraded condition of the operator in the
+// degraded-details configmap of the operator namespace, one JSON list of details per condition type, so that
+// auto-remediation does not parse condition messages. The failures of nodes are classified like the conditions of
+// DegradedReasonsController, the failures of the other conditions are classified by their reason.
+type DegradedDetailsController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	podLister       corev1listers.PodLister
+	infraLister     configlistersv1.InfrastructureLister
+
+	now func() time.Time
+}
+
+func NewDegradedDetailsController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	infraInformer configv1informers.InfrastructureInformer,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &DegradedDetailsController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		podLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		infraLister:     infraInformer.Lister(),
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+		infraInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("DegradedDetailsController", c.sync)).ResyncEvery(resyncinterval.For("DegradedDetailsController", 30*time.Second)).ToController("DegradedDetailsController", eventRecorder.WithComponentSuffix("degraded-details-controller"))
+}
+
+func (c *DegradedDetailsController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	timeout, err := staticpoddetection.MissingPodTimeout(&spec.OperatorSpec, c.infraLister)
+	if err != nil {
+		return err
+	}
+	failures, err := degradedreasons.NodeFailures(c.podLister, status, timeout, c.now())
+	if err != nil {
+		return err
+	}
+
+	data, err := newDetails(status.Conditions, failures)
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: ConfigMapName},
+		Data:       data,
+	})
+	return err
+}
+
+// newDetails returns the details of every true Degraded condition. A condition with node failures has a detail per
+// reason of the failures, with the failing nodes, the others a detail with their reason and the operator.
+func newDetails(conditions []operatorv1.OperatorCondition, failures []degradedreasons.NodeFailure) (map[string]string, error) {
+	data := map[string]string{}
+	for _, cond := range conditions {
+		if !strings.HasSuffix(cond.Type, "Degraded") || cond.Status != operatorv1.ConditionTrue {
+			continue
+		}
+
+		nodes := map[string]sets.String{}
+		for _, failure := range failures {
+			if nodeFailureConditions[failure.Reason] != cond.Type {
+				continue
+			}
+			if _, ok := nodes[failure.Reason]; !ok {
+				nodes[failure.Reason] = sets.NewString()
+			}
+			nodes[failure.Reason].Insert(failure.NodeName)
+		}
+
+		var details []Detail
+		for reason, nodeNames := range nodes {
+			detail := Detail{ErrorClass: reason, Remediation: nodeFailureRemediations[reason]}
+			for _, nodeName := range nodeNames.List() {
+				detail.AffectedObjects = append(detail.AffectedObjects, configv1.ObjectReference{Resource: "nodes", Name: nodeName})
+			}
+			details = append(details, detail)
+		}
+		sort.Slice(details, func(i, j int) bool { return details[i].ErrorClass < details[j].ErrorClass })
+		if len(details) == 0 {
+			errorClass := cond.Reason
+			if len(errorClass) == 0 {
+				errorClass = "Unknown"
+			}
+			details = append(details, Detail{
+				ErrorClass:      errorClass,
+				AffectedObjects: []configv1.ObjectReference{operatorObject},
+				Remediation:     subsystemRemediations[healthsummary.SubsystemOf(cond.Type)],
+			})
+		}
+
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return nil, err
+		}
+		data[cond.Type] = string(raw)
+	}
+	return data, nil
+}
@@ -0,0 +1,71 @@
+package degradeddetails
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/degradedreasons"
+)
+
+func TestNewDetails(t *testing.T) {
+	conditions := []operatorv1.OperatorCondition{
+		{Type: "NodeInstallerDegraded", Status: operatorv1.ConditionTrue, Reason: "InstallerFailed"},
+		{Type: "StaticPodsDegraded", Status: operatorv1.ConditionTrue, Reason: "Error"},
+		{Type: "MissingStaticPodDegraded", Status: operatorv1.ConditionFalse},
+		{Type: "AuditPolicyDegraded", Status: operatorv1.ConditionTrue, Reason: "InvalidPolicy"},
+		{Type: "ResourceSyncControllerDegraded", Status: operatorv1.ConditionTrue},
+		{Type: "StaticPodCrashLoop", Status: operatorv1.ConditionTrue, Reason: degradedreasons.CrashLoopReason},
+	}
+	failures := []degradedreasons.NodeFailure{
+		{NodeName: "master-2", Reason: degradedreasons.FetchFailureReason},
+		{NodeName: "master-0", Reason: degradedreasons.FetchFailureReason},
+		{NodeName: "master-1", Reason: degradedreasons.DiskFailureReason},
+		// its condition is false
+		{NodeName: "master-1", Reason: degradedreasons.KubeletNotObservingReason},
+	}
+
+	data, err := newDetails(conditions, failures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]Detail{
+		"NodeInstallerDegraded": {
+			{
+				ErrorClass:      degradedreasons.DiskFailureReason,
+				AffectedObjects: []configv1.ObjectReference{{Resource: "nodes", Name: "master-1"}},
+				Remediation:     []string{"free-node-disk"},
+			},
+			{
+				ErrorClass:      degradedreasons.FetchFailureReason,
+				AffectedObjects: []configv1.ObjectReference{{Resource: "nodes", Name: "master-0"}, {Resource: "nodes", Name: "master-2"}},
+				Remediation:     []string{"check-apiserver-connectivity"},
+			},
+		},
+		// none of the node failures is classified
+		"StaticPodsDegraded": {
+			{ErrorClass: "Error", AffectedObjects: []configv1.ObjectReference{operatorObject}, Remediation: []string{"inspect-rollout"}},
+		},
+		"AuditPolicyDegraded": {
+			{ErrorClass: "InvalidPolicy", AffectedObjects: []configv1.ObjectReference{operatorObject}, Remediation: []string{"fix-audit-policy-config"}},
+		},
+		"ResourceSyncControllerDegraded": {
+			{ErrorClass: "Unknown", AffectedObjects: []configv1.ObjectReference{operatorObject}, Remediation: []string{"inspect-operator-logs"}},
+		},
+	}
+	actual := map[string][]Detail{}
+	for conditionType, raw := range data {
+		var details []Detail
+		if err := json.Unmarshal([]byte(raw), &details); err != nil {
+			t.Fatal(err)
+		}
+		actual[conditionType] = details
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected details (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,240 @@
+package degradedreasons
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installermetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/staticpoddetection"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// FetchFailureReason is an installer that failed to read the revision from the API.
+	FetchFailureReason = "FetchFailure"
+	// DiskFailureReason is an installer that failed to write the revision to the disk of the node.
+	DiskFailureReason = "DiskFailure"
+	// CrashLoopReason is a kube-apiserver static pod whose container keeps failing.
+	CrashLoopReason = "CrashLoop"
+	// ManifestInvalidReason is a kube-apiserver static pod whose containers the kubelet cannot create from the manifest.
+	ManifestInvalidReason = "ManifestInvalid"
+	// KubeletNotObservingReason is a kubelet that did not start the static pod of the revision its installer wrote.
+	KubeletNotObservingReason = "KubeletNotObserving"
+
+	// the node status reasons of the installer controller
+	installerFailedReason       = "InstallerFailed"
+	operandFailedReason         = "OperandFailed"
+	operandFailedFallbackReason = "OperandFailedFallback"
+)
+
+// conditionTypes are the conditions of the reasons, in the order they are reported.
+var conditionTypes = []struct {
+	conditionType string
+	reason        string
+}{
+	{conditionType: "NodeInstallerFetchFailure", reason: FetchFailureReason},
+	{conditionType: "NodeInstallerDiskFailure", reason: DiskFailureReason},
+	{conditionType: "StaticPodCrashLoop", reason: CrashLoopReason},
+	{conditionType: "StaticPodManifestInvalid", reason: ManifestInvalidReason},
+	{conditionType: "KubeletNotObservingStaticPod", reason: KubeletNotObservingReason},
+}
+
+// manifestWaitingReasons are the waiting reasons of containers that the kubelet cannot create from the pod spec.
+var manifestWaitingReasons = sets.NewString("CreateContainerConfigError", "CreateContainerError", "InvalidImageName")
+
+var (
+	registerMetrics sync.Once
+
+	degradedNodesGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_degraded_nodes",
+		Help: "Report 1 for each control plane node and reason it is degraded for: FetchFailure, DiskFailure, CrashLoop, ManifestInvalid or KubeletNotObserving.",
+	}, []string{"node", "reason"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(degradedNodesGauge)
+	})
+}
+
+// NodeFailure is a node that is degraded for a reason.
+type NodeFailure struct {
+	NodeName string
+	Reason   string
+	// Message tells what failed on the node.
+	Message string
+}
+
+// DegradedReasonsController splits the failures behind the NodeInstallerDegraded, StaticPodsDegraded and
+// MissingStaticPodDegraded conditions into a condition per reason, with a stable reason and the affected nodes in the
+// first line of the message, and the openshift_kube_apiserver_operator_degraded_nodes metric. Failures that fit no
+// reason are only reported by the broad conditions. The conditions do not end in Degraded, the broad ones degrade the
+// operator already.
+type DegradedReasonsController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	podLister      corev1listers.PodLister
+	infraLister    configlistersv1.InfrastructureLister
+
+	now func() time.Time
+}
+
+func NewDegradedReasonsController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	infraInformer configv1informers.InfrastructureInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &DegradedReasonsController{
+		operatorClient: operatorClient,
+		podLister:      kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		infraLister:    infraInformer.Lister(),
+		now:            time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+		infraInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("DegradedReasonsController", c.sync)).ResyncEvery(resyncinterval.For("DegradedReasonsController", 30*time.Second)).ToController("DegradedReasonsController", eventRecorder.WithComponentSuffix("degraded-reasons-controller"))
+}
+
+func (c *DegradedReasonsController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	timeout, err := staticpoddetection.MissingPodTimeout(&spec.OperatorSpec, c.infraLister)
+	if err != nil {
+		return err
+	}
+
+	failures, err := NodeFailures(c.podLister, status, timeout, c.now())
+	if err != nil {
+		return err
+	}
+	degradedNodesGauge.Reset()
+	for _, failure := range failures {
+		degradedNodesGauge.WithLabelValues(failure.NodeName, failure.Reason).Set(1)
+	}
+
+	var updateFuncs []v1helpers.UpdateStatusFunc
+	for _, cond := range newConditions(failures) {
+		updateFuncs = append(updateFuncs, v1helpers.UpdateConditionFn(cond))
+	}
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, updateFuncs...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NodeFailures classifies the failures of the nodes. A node can fail for several reasons, e.g. a crash looping
+// kube-apiserver of the current revision while the installer of the next one cannot write to the disk. Static pods
+// are missing after the timeout.
+func NodeFailures(podLister corev1listers.PodLister, status *operatorv1.StaticPodOperatorStatus, timeout time.Duration, now time.Time) ([]NodeFailure, error) {
+	var failures []NodeFailure
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.LastFailedRevision > nodeStatus.CurrentRevision {
+			switch nodeStatus.LastFailedReason {
+			case installerFailedReason:
+				errs := strings.Join(nodeStatus.LastFailedRevisionErrors, "\n")
+				message := fmt.Sprintf("node %q: installer of revision %d: %s", nodeStatus.NodeName, nodeStatus.LastFailedRevision, strings.TrimSpace(installermetrics.FailureError(errs)))
+				switch installermetrics.ClassifyFailure(errs) {
+				case "fetch", "timeout":
+					failures = append(failures, NodeFailure{NodeName: nodeStatus.NodeName, Reason: FetchFailureReason, Message: message})
+				case "write":
+					failures = append(failures, NodeFailure{NodeName: nodeStatus.NodeName, Reason: DiskFailureReason, Message: message})
+				}
+			case operandFailedReason, operandFailedFallbackReason:
+				failures = append(failures, NodeFailure{
+					NodeName: nodeStatus.NodeName,
+					Reason:   CrashLoopReason,
+					Message:  fmt.Sprintf("node %q: revision %d failed: %s", nodeStatus.NodeName, nodeStatus.LastFailedRevision, strings.Join(nodeStatus.LastFailedRevisionErrors, "; ")),
+				})
+			}
+		}
+
+		pod, err := podLister.Pods(operatorclient.TargetNamespace).Get(fmt.Sprintf("kube-apiserver-%s", nodeStatus.NodeName))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, containerStatus := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			waiting := containerStatus.State.Waiting
+			switch {
+			case waiting == nil:
+			case waiting.Reason == "CrashLoopBackOff":
+				failures = append(failures, NodeFailure{
+					NodeName: nodeStatus.NodeName,
+					Reason:   CrashLoopReason,
+					Message:  fmt.Sprintf("node %q: container %q of revision %s restarted %d times", nodeStatus.NodeName, containerStatus.Name, pod.Labels["revision"], containerStatus.RestartCount),
+				})
+			case manifestWaitingReasons.Has(waiting.Reason):
+				failures = append(failures, NodeFailure{
+					NodeName: nodeStatus.NodeName,
+					Reason:   ManifestInvalidReason,
+					Message:  fmt.Sprintf("node %q: container %q of revision %s: %s: %s", nodeStatus.NodeName, containerStatus.Name, pod.Labels["revision"], waiting.Reason, waiting.Message),
+				})
+			}
+		}
+	}
+
+	missing, err := staticpoddetection.MissingStaticPods(podLister, status, timeout, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, missingPod := range missing {
+		failures = append(failures, NodeFailure{NodeName: missingPod.NodeStatus.NodeName, Reason: KubeletNotObservingReason, Message: missingPod.Message})
+	}
+	return failures, nil
+}
+
+// newConditions returns a condition per reason. The first line of the message of a true condition lists the nodes,
+// the following lines tell what failed on them.
+func newConditions(failures []NodeFailure) []operatorv1.OperatorCondition {
+	var conditions []operatorv1.OperatorCondition
+	for _, conditionType := range conditionTypes {
+		nodes := sets.NewString()
+		var messages []string
+		for _, failure := range failures {
+			if failure.Reason == conditionType.reason {
+				nodes.Insert(failure.NodeName)
+				messages = append(messages, failure.Message)
+			}
+		}
+		if nodes.Len() == 0 {
+			conditions = append(conditions, operatorv1.OperatorCondition{
+				Type:   conditionType.conditionType,
+				Status: operatorv1.ConditionFalse,
+				Reason: "AsExpected",
+			})
+			continue
+		}
+		sort.Strings(messages)
+		conditions = append(conditions, operatorv1.OperatorCondition{
+			Type:    conditionType.conditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  conditionType.reason,
+			Message: fmt.Sprintf("nodes: %s\n%s", strings.Join(nodes.List(), ","), strings.Join(messages, "\n")),
+		})
+	}
+	return conditions
+}
@@ -0,0 +1,110 @@
+package degradedreasons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNodeFailures(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	mirrorPod := func(nodeName string, waiting *corev1.ContainerStateWaiting) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-" + nodeName, Labels: map[string]string{"revision": "4"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kube-apiserver", State: corev1.ContainerState{Waiting: waiting}, RestartCount: 7},
+			}},
+		}
+	}
+	installer := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "installer-5-master-4", Labels: map[string]string{"app": "installer"}},
+		Spec:       corev1.PodSpec{NodeName: "master-4"},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded, ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "installer",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(now.Add(-10 * time.Minute))}},
+		}}},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range []*corev1.Pod{
+		mirrorPod("master-0", nil),
+		mirrorPod("master-2", &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}),
+		mirrorPod("master-3", &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: `secret "serving-cert-4" not found`}),
+		mirrorPod("master-4", nil),
+		installer,
+	} {
+		if err := indexer.Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status := &operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{
+		{
+			NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: "InstallerFailed",
+			LastFailedRevisionErrors: []string{`installer: F0601 12:01:00.000000       1 cmd.go:105] failed to copy: secrets "etcd-client-5" not found`},
+		},
+		{
+			NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: "InstallerFailed",
+			LastFailedRevisionErrors: []string{"installer: F0601 12:01:00.000000       1 cmd.go:105] failed to copy: open /etc/kubernetes/manifests/kube-apiserver-pod.yaml: no space left on device"},
+		},
+		{NodeName: "master-2", CurrentRevision: 4},
+		{NodeName: "master-3", CurrentRevision: 4},
+		{NodeName: "master-4", CurrentRevision: 4, TargetRevision: 5},
+		// an old failure of a revision that is installed since
+		{NodeName: "master-5", CurrentRevision: 4, LastFailedRevision: 3, LastFailedReason: "OperandFailed"},
+	}}
+
+	failures, err := NodeFailures(corev1listers.NewPodLister(indexer), status, 5*time.Minute, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := map[string]string{}
+	for _, failure := range failures {
+		reasons[failure.NodeName] = failure.Reason
+	}
+	expected := map[string]string{
+		"master-0": FetchFailureReason,
+		"master-1": DiskFailureReason,
+		"master-2": CrashLoopReason,
+		"master-3": ManifestInvalidReason,
+		"master-4": KubeletNotObservingReason,
+	}
+	if diff := cmp.Diff(expected, reasons); len(diff) > 0 {
+		t.Errorf("unexpected reasons (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewConditions(t *testing.T) {
+	conditions := newConditions([]NodeFailure{
+		{NodeName: "master-1", Reason: CrashLoopReason, Message: `node "master-1": container "kube-apiserver" of revision 4 restarted 7 times`},
+		{NodeName: "master-0", Reason: CrashLoopReason, Message: `node "master-0": revision 5 failed: container "kube-apiserver" is terminated`},
+	})
+	if len(conditions) != len(conditionTypes) {
+		t.Fatalf("expected a condition per reason, got %v", conditions)
+	}
+	expected := operatorv1.OperatorCondition{
+		Type:   "StaticPodCrashLoop",
+		Status: operatorv1.ConditionTrue,
+		Reason: CrashLoopReason,
+		Message: "nodes: master-0,master-1\n" +
+			`node "master-0": revision 5 failed: container "kube-apiserver" is terminated` + "\n" +
+			`node "master-1": container "kube-apiserver" of revision 4 restarted 7 times`,
+	}
+	for _, cond := range conditions {
+		if cond.Type == expected.Type {
+			if diff := cmp.Diff(expected, cond); len(diff) > 0 {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+			continue
+		}
+		if cond.Status != operatorv1.ConditionFalse {
+			t.Errorf("expected %s to be false, got %v", cond.Type, cond)
+		}
+	}
+}
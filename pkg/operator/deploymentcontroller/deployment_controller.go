@@ -0,0 +1,346 @@
+package deploymentcontroller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/revision"
+	"github.com/openshift/library-go/pkg/operator/status"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// DeploymentName is the name of the kube-apiserver deployment. The encryption deployer of library-go looks the
+	// deployment up by this name to find the nodes the kube-apiserver runs on.
+	DeploymentName = "apiserver"
+
+	KubeAPIServerDeploymentDegradedConditionType    = "KubeAPIServerDeploymentDegraded"
+	KubeAPIServerDeploymentAvailableConditionType   = "KubeAPIServerDeploymentAvailable"
+	KubeAPIServerDeploymentProgressingConditionType = "KubeAPIServerDeploymentProgressing"
+)
+
+// masterNodeSelector selects the nodes the kube-apiserver deployment runs on, one replica per node.
+var masterNodeSelector = map[string]string{"node-role.kubernetes.io/master": ""}
+
+// Resources are the configmaps and secrets the installer copies into the static pod resource directories. In
+// deployment mode they are mounted from the target namespace instead.
+type Resources struct {
+	RevisionConfigMaps []revision.RevisionResource
+	RevisionSecrets    []revision.RevisionResource
+	CertConfigMaps     []installer.UnrevisionedResource
+	CertSecrets        []installer.UnrevisionedResource
+}
+
+// DeploymentController renders the kube-apiserver as a deployment instead of static pods, for control planes that
+// are hosted outside of the cluster nodes.
+//
+// It takes the pod manifest of the latest revision, the same one the installer writes to the nodes, and turns it into
+// the pod template of the deployment. The host path resource directories of the static pod are replaced by the
+// revisioned configmaps and secrets, so a new revision is rolled out by the deployment like it is by the installer.
+type DeploymentController struct {
+	targetNamespace string
+	resources       Resources
+
+	operatorClient    v1helpers.StaticPodOperatorClient
+	configMapLister   corelistersv1.ConfigMapLister
+	nodeLister        corelistersv1.NodeLister
+	deploymentsGetter appsclientv1.DeploymentsGetter
+	versionRecorder   status.VersionGetter
+}
+
+func NewDeploymentController(
+	targetNamespace string,
+	resources Resources,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	deploymentsGetter appsclientv1.DeploymentsGetter,
+	versionRecorder status.VersionGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &DeploymentController{
+		targetNamespace:   targetNamespace,
+		resources:         resources,
+		operatorClient:    operatorClient,
+		configMapLister:   kubeInformersForNamespaces.ConfigMapLister(),
+		nodeLister:        kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Lister(),
+		deploymentsGetter: deploymentsGetter,
+		versionRecorder:   versionRecorder,
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(targetNamespace).Apps().V1().Deployments().Informer(),
+		kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Informer(),
+	).WithSync(syncmetrics.Instrument("KubeAPIServerDeploymentController", c.sync)).ToController("KubeAPIServerDeploymentController", eventRecorder.WithComponentSuffix("deployment-controller"))
+}
+
+func (c *DeploymentController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	if operatorStatus.LatestAvailableRevision == 0 {
+		// the revision controller has not created the first revision yet
+		return nil
+	}
+
+	deployment, err := c.requiredDeployment(operatorStatus.LatestAvailableRevision)
+	if err != nil {
+		return c.updateStatus(nil, err)
+	}
+	actual, _, err := resourceapply.ApplyDeployment(ctx, c.deploymentsGetter, syncContext.Recorder(), deployment, resourcemerge.ExpectedDeploymentGeneration(deployment, operatorStatus.Generations))
+	return c.updateStatus(actual, err)
+}
+
+func (c *DeploymentController) requiredDeployment(revision int32) (*appsv1.Deployment, error) {
+	podConfigMap, err := c.configMapLister.ConfigMaps(c.targetNamespace).Get(fmt.Sprintf("kube-apiserver-pod-%d", revision))
+	if err != nil {
+		return nil, err
+	}
+	pod, err := resourceread.ReadPodV1([]byte(strings.ReplaceAll(podConfigMap.Data["pod.yaml"], "REVISION", strconv.Itoa(int(revision)))))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod manifest of revision %d: %v", revision, err)
+	}
+	nodes, err := c.nodeLister.List(labels.SelectorFromSet(masterNodeSelector))
+	if err != nil {
+		return nil, err
+	}
+	replicas := int32(len(nodes))
+	if replicas == 0 {
+		replicas = 1
+	}
+	return DeploymentFromStaticPod(pod, revision, replicas, c.resources), nil
+}
+
+func (c *DeploymentController) updateStatus(actual *appsv1.Deployment, syncErr error) error {
+	conditions := []operatorv1.OperatorCondition{newDegradedCondition(syncErr)}
+	var updateFuncs []v1helpers.UpdateStaticPodStatusFunc
+	if actual != nil {
+		available, progressing := newAvailableCondition(actual), newProgressingCondition(actual)
+		if available.Status == operatorv1.ConditionTrue && progressing.Status == operatorv1.ConditionFalse {
+			// all replicas run the latest revision
+			c.versionRecorder.SetVersion("kube-apiserver", status.VersionForOperandFromEnv())
+		}
+		conditions = append(conditions, available, progressing)
+		updateFuncs = append(updateFuncs, func(operatorStatus *operatorv1.StaticPodOperatorStatus) error {
+			resourcemerge.SetDeploymentGeneration(&operatorStatus.Generations, actual)
+			return nil
+		})
+	}
+	for _, cond := range conditions {
+		updateFuncs = append(updateFuncs, v1helpers.UpdateStaticPodConditionFn(cond))
+	}
+	if _, _, err := v1helpers.UpdateStaticPodStatus(c.operatorClient, updateFuncs...); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+// DeploymentFromStaticPod returns a deployment running the given static pod of the given revision:
+//   - the resource-dir and cert-dir host paths are replaced by mounts of the configmaps and secrets the installer
+//     would have copied into them, the revisioned ones with the revision suffix.
+//   - the audit log goes to an emptyDir.
+//   - the cert syncer is dropped, the kubelet keeps the mounted certificates up to date.
+//   - the pod runs in its own network namespace, one replica per master node.
+func DeploymentFromStaticPod(staticPod *corev1.Pod, revision int32, replicas int32, resources Resources) *appsv1.Deployment {
+	pod := staticPod.DeepCopy()
+
+	var volumes []corev1.Volume
+	resourceMounts := map[string][]corev1.VolumeMount{}
+	for _, volume := range pod.Spec.Volumes {
+		switch volume.Name {
+		case "resource-dir":
+			for _, cm := range resources.RevisionConfigMaps {
+				name := fmt.Sprintf("%s-%d", cm.Name, revision)
+				volumes = append(volumes, configMapVolume("configmap-"+cm.Name, name, cm.Optional))
+				resourceMounts[volume.Name] = append(resourceMounts[volume.Name], corev1.VolumeMount{Name: "configmap-" + cm.Name, MountPath: "configmaps/" + cm.Name})
+			}
+			for _, secret := range resources.RevisionSecrets {
+				name := fmt.Sprintf("%s-%d", secret.Name, revision)
+				volumes = append(volumes, secretVolume("secret-"+secret.Name, name, secret.Optional))
+				resourceMounts[volume.Name] = append(resourceMounts[volume.Name], corev1.VolumeMount{Name: "secret-" + secret.Name, MountPath: "secrets/" + secret.Name})
+			}
+		case "cert-dir":
+			for _, cm := range resources.CertConfigMaps {
+				volumes = append(volumes, configMapVolume("cert-configmap-"+cm.Name, cm.Name, cm.Optional))
+				resourceMounts[volume.Name] = append(resourceMounts[volume.Name], corev1.VolumeMount{Name: "cert-configmap-" + cm.Name, MountPath: "configmaps/" + cm.Name})
+			}
+			for _, secret := range resources.CertSecrets {
+				volumes = append(volumes, secretVolume("cert-secret-"+secret.Name, secret.Name, secret.Optional))
+				resourceMounts[volume.Name] = append(resourceMounts[volume.Name], corev1.VolumeMount{Name: "cert-secret-" + secret.Name, MountPath: "secrets/" + secret.Name})
+			}
+		default:
+			if volume.HostPath != nil {
+				volume.VolumeSource = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+			}
+			volumes = append(volumes, volume)
+		}
+	}
+	pod.Spec.Volumes = volumes
+
+	var containers []corev1.Container
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "kube-apiserver-cert-syncer" {
+			continue
+		}
+		containers = append(containers, container)
+	}
+	pod.Spec.Containers = containers
+
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			containers[i].VolumeMounts = expandResourceMounts(containers[i].VolumeMounts, resourceMounts)
+			for j := range containers[i].Ports {
+				containers[i].Ports[j].HostPort = 0
+			}
+		}
+	}
+
+	pod.Spec.HostNetwork = false
+	pod.Spec.NodeSelector = masterNodeSelector
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule})
+	pod.Spec.Affinity = &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"apiserver": "true"}},
+				TopologyKey:   "kubernetes.io/hostname",
+			}},
+		},
+	}
+
+	maxUnavailable := intstr.FromInt(1)
+	maxSurge := intstr.FromInt(0)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeploymentName,
+			Namespace: pod.Namespace,
+			Labels:    map[string]string{"app": pod.Labels["app"], "apiserver": "true"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": pod.Labels["app"], "apiserver": "true"}},
+			Strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable, MaxSurge: &maxSurge},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        pod.Name,
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}
+}
+
+// expandResourceMounts replaces the mounts of the resource directories by mounts of the resources below them.
+func expandResourceMounts(mounts []corev1.VolumeMount, resourceMounts map[string][]corev1.VolumeMount) []corev1.VolumeMount {
+	var expanded []corev1.VolumeMount
+	for _, mount := range mounts {
+		subMounts, ok := resourceMounts[mount.Name]
+		if !ok {
+			expanded = append(expanded, mount)
+			continue
+		}
+		for _, subMount := range subMounts {
+			subMount.MountPath = mount.MountPath + "/" + subMount.MountPath
+			subMount.ReadOnly = true
+			expanded = append(expanded, subMount)
+		}
+	}
+	return expanded
+}
+
+func configMapVolume(volumeName, name string, optional bool) corev1.Volume {
+	return corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Optional:             &optional,
+		}},
+	}
+}
+
+func secretVolume(volumeName, name string, optional bool) corev1.Volume {
+	return corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+			SecretName: name,
+			Optional:   &optional,
+		}},
+	}
+}
+
+func newDegradedCondition(err error) operatorv1.OperatorCondition {
+	if err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    KubeAPIServerDeploymentDegradedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "SyncError",
+			Message: err.Error(),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:   KubeAPIServerDeploymentDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+}
+
+func newAvailableCondition(deployment *appsv1.Deployment) operatorv1.OperatorCondition {
+	if deployment.Status.AvailableReplicas == 0 {
+		return operatorv1.OperatorCondition{
+			Type:    KubeAPIServerDeploymentAvailableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoAvailableReplicas",
+			Message: fmt.Sprintf("no kube-apiserver replica of deployment %s/%s is available", deployment.Namespace, deployment.Name),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    KubeAPIServerDeploymentAvailableConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "AsExpected",
+		Message: fmt.Sprintf("%d kube-apiserver replicas are available", deployment.Status.AvailableReplicas),
+	}
+}
+
+func newProgressingCondition(deployment *appsv1.Deployment) operatorv1.OperatorCondition {
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.UpdatedReplicas < replicas || deployment.Status.Replicas > replicas {
+		return operatorv1.OperatorCondition{
+			Type:    KubeAPIServerDeploymentProgressingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "RollingOut",
+			Message: fmt.Sprintf("%d of %d kube-apiserver replicas are updated", deployment.Status.UpdatedReplicas, replicas),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:   KubeAPIServerDeploymentProgressingConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+}
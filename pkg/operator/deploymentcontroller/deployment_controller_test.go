@@ -0,0 +1,126 @@
+package deploymentcontroller
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/revision"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentFromStaticPod(t *testing.T) {
+	staticPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kube-apiserver",
+			Namespace: "openshift-kube-apiserver",
+			Labels:    map[string]string{"app": "openshift-kube-apiserver", "apiserver": "true", "revision": "3"},
+		},
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			InitContainers: []corev1.Container{{
+				Name:         "setup",
+				VolumeMounts: []corev1.VolumeMount{{Name: "audit-dir", MountPath: "/var/log/kube-apiserver"}},
+			}},
+			Containers: []corev1.Container{
+				{
+					Name:  "kube-apiserver",
+					Ports: []corev1.ContainerPort{{ContainerPort: 6443}},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "resource-dir", MountPath: "/etc/kubernetes/static-pod-resources"},
+						{Name: "cert-dir", MountPath: "/etc/kubernetes/static-pod-certs"},
+						{Name: "audit-dir", MountPath: "/var/log/kube-apiserver"},
+					},
+				},
+				{
+					Name:         "kube-apiserver-cert-syncer",
+					VolumeMounts: []corev1.VolumeMount{{Name: "cert-dir", MountPath: "/etc/kubernetes/static-pod-certs"}},
+				},
+				{
+					Name:  "kube-apiserver-check-endpoints",
+					Ports: []corev1.ContainerPort{{Name: "check-endpoints", HostPort: 17697, ContainerPort: 17697}},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "resource-dir", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc/kubernetes/static-pod-resources/kube-apiserver-pod-3"}}},
+				{Name: "cert-dir", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc/kubernetes/static-pod-resources/kube-apiserver-certs"}}},
+				{Name: "audit-dir", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/kube-apiserver"}}},
+			},
+		},
+	}
+	resources := Resources{
+		RevisionConfigMaps: []revision.RevisionResource{{Name: "config"}},
+		RevisionSecrets:    []revision.RevisionResource{{Name: "encryption-config", Optional: true}},
+		CertConfigMaps:     []installer.UnrevisionedResource{{Name: "client-ca"}},
+		CertSecrets:        []installer.UnrevisionedResource{{Name: "user-serving-cert", Optional: true}},
+	}
+
+	deployment := DeploymentFromStaticPod(staticPod, 3, 3, resources)
+
+	if deployment.Name != DeploymentName || deployment.Namespace != "openshift-kube-apiserver" || *deployment.Spec.Replicas != 3 {
+		t.Fatalf("unexpected deployment %s/%s with %d replicas", deployment.Namespace, deployment.Name, *deployment.Spec.Replicas)
+	}
+	if _, ok := deployment.Spec.Selector.MatchLabels["revision"]; ok {
+		t.Errorf("the selector must not change with the revision: %v", deployment.Spec.Selector.MatchLabels)
+	}
+	podSpec := deployment.Spec.Template.Spec
+	if podSpec.HostNetwork {
+		t.Errorf("expected the deployment not to use the host network")
+	}
+	if deployment.Spec.Template.Labels["revision"] != "3" {
+		t.Errorf("expected the pods to carry the revision label, got %v", deployment.Spec.Template.Labels)
+	}
+
+	var containerNames []string
+	for _, container := range podSpec.Containers {
+		containerNames = append(containerNames, container.Name)
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				t.Errorf("container %s: unexpected host port %d", container.Name, port.HostPort)
+			}
+		}
+	}
+	if len(containerNames) != 2 || containerNames[0] != "kube-apiserver" || containerNames[1] != "kube-apiserver-check-endpoints" {
+		t.Errorf("expected the cert syncer to be dropped, got %v", containerNames)
+	}
+
+	volumes := map[string]corev1.Volume{}
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			t.Errorf("volume %s: unexpected host path %s", volume.Name, volume.HostPath.Path)
+		}
+		volumes[volume.Name] = volume
+	}
+	if cm := volumes["configmap-config"].ConfigMap; cm == nil || cm.Name != "config-3" {
+		t.Errorf("expected the revisioned config configmap to be mounted, got %#v", volumes["configmap-config"])
+	}
+	if secret := volumes["secret-encryption-config"].Secret; secret == nil || secret.SecretName != "encryption-config-3" || !*secret.Optional {
+		t.Errorf("expected the optional revisioned encryption-config secret to be mounted, got %#v", volumes["secret-encryption-config"])
+	}
+	if cm := volumes["cert-configmap-client-ca"].ConfigMap; cm == nil || cm.Name != "client-ca" {
+		t.Errorf("expected the client-ca configmap to be mounted, got %#v", volumes["cert-configmap-client-ca"])
+	}
+	if volumes["audit-dir"].EmptyDir == nil {
+		t.Errorf("expected the audit log to go to an emptyDir, got %#v", volumes["audit-dir"])
+	}
+
+	expectedMounts := map[string]string{
+		"configmap-config":              "/etc/kubernetes/static-pod-resources/configmaps/config",
+		"secret-encryption-config":      "/etc/kubernetes/static-pod-resources/secrets/encryption-config",
+		"cert-configmap-client-ca":      "/etc/kubernetes/static-pod-certs/configmaps/client-ca",
+		"cert-secret-user-serving-cert": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert",
+		"audit-dir":                     "/var/log/kube-apiserver",
+	}
+	mounts := map[string]string{}
+	for _, mount := range podSpec.Containers[0].VolumeMounts {
+		mounts[mount.Name] = mount.MountPath
+	}
+	if len(mounts) != len(expectedMounts) {
+		t.Errorf("expected mounts %v, got %v", expectedMounts, mounts)
+	}
+	for name, path := range expectedMounts {
+		if mounts[name] != path {
+			t.Errorf("mount %s: expected path %s, got %q", name, path, mounts[name])
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package encryptionconfigcontroller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/encryption/encryptionconfig"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/api/errors"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	EncryptionConfigProgressingConditionType = "EncryptionConfigProgressing"
+
+	EncryptionConfigObservedReason         = "AsExpected"
+	EncryptionConfigNotPickedUpReason      = "EncryptionConfigNotPickedUp"
+	EncryptionConfigNotRevisionedYetReason = "EncryptionConfigNotRevisioned"
+)
+
+// EncryptionConfigController reports whether every kube-apiserver runs with the newest encryption config.
+//
+// The kube-apiserver reads the file passed to --encryption-provider-config only on start. The encryption-config
+// secret of the target namespace is therefore a revisioned secret, the file at the stable path
+// /etc/kubernetes/static-pod-resources/secrets/encryption-config/encryption-config is versioned by the revision
+// directory it is installed into, and a change is picked up by rolling out a new revision. This controller makes
+// that explicit: it compares the newest encryption config with the encryption-config-<revision> secret of the
+// revision each node runs and sets EncryptionConfigProgressing while they differ. When the revision a node runs has
+// been pruned, the node falls back to the revisions after it: it runs the newest encryption config when all of them
+// that are left have it.
+type EncryptionConfigController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	secretLister    corelistersv1.SecretLister
+	configMapLister corelistersv1.ConfigMapLister
+	namespace       string
+}
+
+func NewEncryptionConfigController(
+	targetNamespace string,
+	operatorClient v1helpers.StaticPodOperatorClient,
+	secretInformer coreinformersv1.SecretInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &EncryptionConfigController{
+		operatorClient:  operatorClient,
+		secretLister:    secretInformer.Lister(),
+		configMapLister: configMapInformer.Lister(),
+		namespace:       targetNamespace,
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		secretInformer.Informer(),
+		configMapInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("EncryptionConfigController", c.sync)).ToController("EncryptionConfigController", eventRecorder.WithComponentSuffix("encryption-config-controller"))
+}
+
+func (c *EncryptionConfigController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	desired, err := c.encryptionConfig(encryptionconfig.EncryptionConfSecretName)
+	if err != nil {
+		return err
+	}
+	latestRevision, err := c.encryptionConfig(revisionedName(operatorStatus.LatestAvailableRevision))
+	if err != nil {
+		return err
+	}
+	current := map[string][]byte{}
+	for _, nodeStatus := range operatorStatus.NodeStatuses {
+		config, err := c.encryptionConfig(revisionedName(nodeStatus.CurrentRevision))
+		if err != nil {
+			return err
+		}
+		if config == nil {
+			pruned, err := c.revisionPruned(nodeStatus.CurrentRevision)
+			if err != nil {
+				return err
+			}
+			if pruned {
+				unchanged, err := c.unchangedSincePruned(operatorStatus.LatestAvailableRevision, latestRevision)
+				if err != nil {
+					return err
+				}
+				if unchanged {
+					config = latestRevision
+				}
+			}
+		}
+		current[nodeStatus.NodeName] = config
+	}
+
+	cond := newProgressingCondition(desired, operatorStatus.LatestAvailableRevision, latestRevision, current)
+	previous := v1helpers.FindOperatorCondition(operatorStatus.Conditions, EncryptionConfigProgressingConditionType)
+	if cond.Status == operatorv1.ConditionFalse && previous != nil && previous.Status == operatorv1.ConditionTrue {
+		syncContext.Recorder().Eventf("EncryptionConfigPickedUp", "All kube-apiservers run with the newest encryption config at revision %d", operatorStatus.LatestAvailableRevision)
+	}
+	if _, _, err := v1helpers.UpdateStaticPodStatus(c.operatorClient, v1helpers.UpdateStaticPodConditionFn(cond)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// encryptionConfig returns the encryption config stored in the given secret of the target namespace, or nil
+// if encryption is not configured there.
+func (c *EncryptionConfigController) encryptionConfig(name string) ([]byte, error) {
+	secret, err := c.secretLister.Secrets(c.namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data[encryptionconfig.EncryptionConfSecretKey], nil
+}
+
+// revisionPruned tells whether the resources of the revision have been pruned. The encryption-config secret of a
+// revision is optional, it is only missing from a revision that still exists when encryption was not configured.
+func (c *EncryptionConfigController) revisionPruned(revision int32) (bool, error) {
+	_, err := c.configMapLister.ConfigMaps(c.namespace).Get(fmt.Sprintf("revision-status-%d", revision))
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// unchangedSincePruned tells whether every revision older than the latest one up to the first pruned revision has the
+// encryption config of the latest revision. A node at a pruned revision is assumed to run that config then.
+func (c *EncryptionConfigController) unchangedSincePruned(latestRevision int32, latestRevisionConfig []byte) (bool, error) {
+	for revision := latestRevision - 1; revision > 0; revision-- {
+		config, err := c.encryptionConfig(revisionedName(revision))
+		if err != nil {
+			return false, err
+		}
+		if config == nil {
+			pruned, err := c.revisionPruned(revision)
+			if err != nil {
+				return false, err
+			}
+			if pruned {
+				return true, nil
+			}
+		}
+		if !bytes.Equal(config, latestRevisionConfig) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func revisionedName(revision int32) string {
+	return fmt.Sprintf("%s-%d", encryptionconfig.EncryptionConfSecretName, revision)
+}
+
+// newProgressingCondition compares the desired encryption config with the one of the latest revision and the ones
+// of the revisions the nodes currently run, keyed by node name.
+func newProgressingCondition(desired []byte, latestRevision int32, latestRevisionConfig []byte, current map[string][]byte) operatorv1.OperatorCondition {
+	if !bytes.Equal(desired, latestRevisionConfig) {
+		return operatorv1.OperatorCondition{
+			Type:    EncryptionConfigProgressingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  EncryptionConfigNotRevisionedYetReason,
+			Message: fmt.Sprintf("the encryption config changed after revision %d was created, waiting for a new revision", latestRevision),
+		}
+	}
+
+	var stale []string
+	for nodeName, config := range current {
+		if !bytes.Equal(desired, config) {
+			stale = append(stale, nodeName)
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return operatorv1.OperatorCondition{
+			Type:    EncryptionConfigProgressingConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  EncryptionConfigNotPickedUpReason,
+			Message: fmt.Sprintf("the kube-apiservers on nodes %s have not picked up the encryption config of revision %d yet", strings.Join(stale, ", "), latestRevision),
+		}
+	}
+
+	return operatorv1.OperatorCondition{
+		Type:   EncryptionConfigProgressingConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: EncryptionConfigObservedReason,
+	}
+}
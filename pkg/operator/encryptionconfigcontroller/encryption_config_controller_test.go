@@ -0,0 +1,162 @@
+package encryptionconfigcontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewProgressingCondition(t *testing.T) {
+	tests := []struct {
+		name string
+
+		desired              []byte
+		latestRevisionConfig []byte
+		current              map[string][]byte
+		expected             operatorv1.OperatorCondition
+	}{
+		{
+			name:    "encryption not configured",
+			current: map[string][]byte{"master-0": nil, "master-1": nil},
+			expected: operatorv1.OperatorCondition{
+				Type:   "EncryptionConfigProgressing",
+				Status: "False",
+				Reason: "AsExpected",
+			},
+		},
+		{
+			name:                 "all nodes run the newest config",
+			desired:              []byte("new"),
+			latestRevisionConfig: []byte("new"),
+			current:              map[string][]byte{"master-0": []byte("new"), "master-1": []byte("new")},
+			expected: operatorv1.OperatorCondition{
+				Type:   "EncryptionConfigProgressing",
+				Status: "False",
+				Reason: "AsExpected",
+			},
+		},
+		{
+			name:                 "no revision with the newest config yet",
+			desired:              []byte("new"),
+			latestRevisionConfig: []byte("old"),
+			current:              map[string][]byte{"master-0": []byte("old")},
+			expected: operatorv1.OperatorCondition{
+				Type:    "EncryptionConfigProgressing",
+				Status:  "True",
+				Reason:  "EncryptionConfigNotRevisioned",
+				Message: "the encryption config changed after revision 3 was created, waiting for a new revision",
+			},
+		},
+		{
+			name:                 "nodes still run an older config",
+			desired:              []byte("new"),
+			latestRevisionConfig: []byte("new"),
+			current:              map[string][]byte{"master-2": []byte("old"), "master-1": []byte("new"), "master-0": nil},
+			expected: operatorv1.OperatorCondition{
+				Type:    "EncryptionConfigProgressing",
+				Status:  "True",
+				Reason:  "EncryptionConfigNotPickedUp",
+				Message: "the kube-apiservers on nodes master-0, master-2 have not picked up the encryption config of revision 3 yet",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := newProgressingCondition(test.desired, 3, test.latestRevisionConfig, test.current)
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Fatal(spew.Sdump(actual))
+			}
+		})
+	}
+}
+
+func TestSyncPrunedRevision(t *testing.T) {
+	tests := []struct {
+		name         string
+		revisions    map[int32]string
+		nodeStatuses []operatorv1.NodeStatus
+		expected     operatorv1.ConditionStatus
+	}{
+		{
+			name:         "all nodes run the latest revision",
+			revisions:    map[int32]string{4: "old", 5: "new"},
+			nodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}},
+			expected:     operatorv1.ConditionFalse,
+		},
+		{
+			name:         "older revision without encryption config",
+			revisions:    map[int32]string{4: "", 5: "new"},
+			nodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}, {NodeName: "master-1", CurrentRevision: 4}},
+			expected:     operatorv1.ConditionTrue,
+		},
+		{
+			name:         "pruned revision before the encryption config changed",
+			revisions:    map[int32]string{4: "old", 5: "new"},
+			nodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}, {NodeName: "master-1", CurrentRevision: 3}},
+			expected:     operatorv1.ConditionTrue,
+		},
+		{
+			name:         "pruned revision after the encryption config changed",
+			revisions:    map[int32]string{4: "new", 5: "new"},
+			nodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}, {NodeName: "master-1", CurrentRevision: 3}},
+			expected:     operatorv1.ConditionFalse,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			addSecret := func(name, config string) {
+				if err := secrets.Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name},
+					Data:       map[string][]byte{"encryption-config": []byte(config)},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			addSecret("encryption-config", "new")
+			for revision, config := range test.revisions {
+				if err := configMaps.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: fmt.Sprintf("revision-status-%d", revision)}}); err != nil {
+					t.Fatal(err)
+				}
+				if len(config) > 0 {
+					addSecret(fmt.Sprintf("encryption-config-%d", revision), config)
+				}
+			}
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClient(nil, &operatorv1.StaticPodOperatorStatus{
+				LatestAvailableRevision: 5,
+				NodeStatuses:            test.nodeStatuses,
+			}, nil, nil)
+			c := &EncryptionConfigController{
+				operatorClient:  operatorClient,
+				secretLister:    corelistersv1.NewSecretLister(secrets),
+				configMapLister: corelistersv1.NewConfigMapLister(configMaps),
+				namespace:       "openshift-kube-apiserver",
+			}
+
+			if err := c.sync(context.TODO(), factory.NewSyncContext("EncryptionConfigController", events.NewInMemoryRecorder(t.Name()))); err != nil {
+				t.Fatal(err)
+			}
+
+			_, status, _, err := operatorClient.GetStaticPodOperatorState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			condition := v1helpers.FindOperatorCondition(status.Conditions, "EncryptionConfigProgressing")
+			if condition == nil || condition.Status != test.expected {
+				t.Errorf("expected EncryptionConfigProgressing=%s, got %v", test.expected, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package eventaggregation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultWindow is how long identical events are collapsed after one was recorded. Conditions that flap every sync
+// record the same events every few seconds, a single summary per window keeps the signal.
+const DefaultWindow = 5 * time.Minute
+
+// key identifies the events that are collapsed. The message is part of it, events about different objects or with
+// different content are all recorded.
+type key struct {
+	component string
+	eventType string
+	reason    string
+	message   string
+}
+
+// aggregate holds the events of a key that were collapsed since the first event of the window.
+type aggregate struct {
+	key       key
+	recorder  events.Recorder
+	windowEnd time.Time
+
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// aggregator is shared by the recorders of all components.
+type aggregator struct {
+	lock       sync.Mutex
+	window     time.Duration
+	now        func() time.Time
+	aggregates map[key]*aggregate
+}
+
+// Recorder records the first event of a component, type, reason and message and collapses the identical ones that
+// follow within the window. When the window ends, a summary with the number of collapsed events and when the first and
+// the last of them were seen is recorded, if any were collapsed. The next event starts a new window. It is meant for
+// controllers whose conditions flap, events of the resources they apply are only collapsed when they are identical.
+type Recorder struct {
+	delegate   events.Recorder
+	aggregator *aggregator
+}
+
+var _ events.Recorder = &Recorder{}
+
+// NewRecorder returns a recorder that collapses the events recorded with it and with the recorders derived from it by
+// ForComponent and WithComponentSuffix. Run has to be called to record the summaries.
+func NewRecorder(delegate events.Recorder, window time.Duration) *Recorder {
+	return &Recorder{
+		delegate: delegate,
+		aggregator: &aggregator{
+			window:     window,
+			now:        time.Now,
+			aggregates: map[key]*aggregate{},
+		},
+	}
+}
+
+// Run records the summaries of the windows that ended until the context is done.
+func (r *Recorder) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(context.Context) { r.flush(false) }, r.aggregator.window/10)
+}
+
+// flush records the summaries of the windows that ended, or of all windows.
+func (r *Recorder) flush(all bool) {
+	a := r.aggregator
+	a.lock.Lock()
+	now := a.now()
+	var ended []*aggregate
+	for k, agg := range a.aggregates {
+		if all || !now.Before(agg.windowEnd) {
+			delete(a.aggregates, k)
+			ended = append(ended, agg)
+		}
+	}
+	a.lock.Unlock()
+
+	sort.Slice(ended, func(i, j int) bool { return ended[i].windowEnd.Before(ended[j].windowEnd) })
+	for _, agg := range ended {
+		agg.recordSummary()
+	}
+}
+
+func (r *Recorder) record(eventType, reason, message string) {
+	a := r.aggregator
+	k := key{component: r.delegate.ComponentName(), eventType: eventType, reason: reason, message: message}
+
+	a.lock.Lock()
+	now := a.now()
+	previous, collapsing := a.aggregates[k]
+	if collapsing && now.Before(previous.windowEnd) {
+		if previous.count == 0 {
+			previous.firstSeen = now
+		}
+		previous.count++
+		previous.lastSeen = now
+		a.lock.Unlock()
+		return
+	}
+	a.aggregates[k] = &aggregate{key: k, recorder: r.delegate, windowEnd: now.Add(a.window)}
+	a.lock.Unlock()
+
+	if collapsing {
+		previous.recordSummary()
+	}
+	recordEvent(r.delegate, eventType, reason, message)
+}
+
+// recordSummary records the message with how many events were collapsed and when the first and the last of them were
+// seen, nothing if none was collapsed.
+func (agg *aggregate) recordSummary() {
+	if agg.count == 0 {
+		return
+	}
+	recordEvent(agg.recorder, agg.key.eventType, agg.key.reason, fmt.Sprintf("%s (%d identical events collapsed, first seen %s, last seen %s)",
+		agg.key.message, agg.count, agg.firstSeen.UTC().Format(time.RFC3339), agg.lastSeen.UTC().Format(time.RFC3339)))
+}
+
+func recordEvent(recorder events.Recorder, eventType, reason, message string) {
+	if eventType == corev1.EventTypeWarning {
+		recorder.Warning(reason, message)
+		return
+	}
+	recorder.Event(reason, message)
+}
+
+func (r *Recorder) Event(reason, message string) {
+	r.record(corev1.EventTypeNormal, reason, message)
+}
+
+func (r *Recorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	r.Event(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *Recorder) Warning(reason, message string) {
+	r.record(corev1.EventTypeWarning, reason, message)
+}
+
+func (r *Recorder) Warningf(reason, messageFmt string, args ...interface{}) {
+	r.Warning(reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *Recorder) ForComponent(componentName string) events.Recorder {
+	return &Recorder{delegate: r.delegate.ForComponent(componentName), aggregator: r.aggregator}
+}
+
+func (r *Recorder) WithComponentSuffix(componentNameSuffix string) events.Recorder {
+	return &Recorder{delegate: r.delegate.WithComponentSuffix(componentNameSuffix), aggregator: r.aggregator}
+}
+
+func (r *Recorder) ComponentName() string {
+	return r.delegate.ComponentName()
+}
+
+// Shutdown records the summaries of all windows before the wrapped recorder shuts down.
+func (r *Recorder) Shutdown() {
+	r.flush(true)
+	r.delegate.Shutdown()
+}
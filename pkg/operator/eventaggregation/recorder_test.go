@@ -0,0 +1,66 @@
+package eventaggregation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	inMemory := events.NewInMemoryRecorder("operator")
+	r := NewRecorder(inMemory, 5*time.Minute)
+	r.aggregator.now = func() time.Time { return now }
+	controller := r.WithComponentSuffix("audit-policy-controller")
+
+	// a flapping condition
+	for i := 0; i < 4; i++ {
+		controller.Warningf("AuditPolicyInvalid", "attempt %d", i%2)
+		now = now.Add(30 * time.Second)
+	}
+	// other types, reasons and messages are not collapsed
+	controller.Event("AuditPolicyInvalid", "normal")
+	controller.Event("AuditPolicyChanged", "changed")
+	controller.Event("AuditPolicyChanged", "changed again")
+
+	// the window did not end
+	r.flush(false)
+	expected := []string{
+		"Warning AuditPolicyInvalid attempt 0",
+		"Warning AuditPolicyInvalid attempt 1",
+		"Normal AuditPolicyInvalid normal",
+		"Normal AuditPolicyChanged changed",
+		"Normal AuditPolicyChanged changed again",
+	}
+	if diff := cmp.Diff(expected, recorded(inMemory)); len(diff) > 0 {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+
+	now = now.Add(5 * time.Minute)
+	r.flush(false)
+	expected = append(expected,
+		"Warning AuditPolicyInvalid attempt 0 (1 identical events collapsed, first seen 2021-06-01T12:01:00Z, last seen 2021-06-01T12:01:00Z)",
+		"Warning AuditPolicyInvalid attempt 1 (1 identical events collapsed, first seen 2021-06-01T12:01:30Z, last seen 2021-06-01T12:01:30Z)",
+	)
+	if diff := cmp.Diff(expected, recorded(inMemory)); len(diff) > 0 {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+
+	// a new window starts with the next event, nothing was collapsed in the others
+	controller.Warning("AuditPolicyInvalid", "attempt 2")
+	r.Shutdown()
+	expected = append(expected, "Warning AuditPolicyInvalid attempt 2")
+	if diff := cmp.Diff(expected, recorded(inMemory)); len(diff) > 0 {
+		t.Errorf("unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func recorded(recorder events.InMemoryRecorder) []string {
+	var recorded []string
+	for _, event := range recorder.Events() {
+		recorded = append(recorded, event.Type+" "+event.Reason+" "+event.Message)
+	}
+	return recorded
+}
@@ -3,7 +3,9 @@ package featureupgradablecontroller
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/blang/semver"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
@@ -11,7 +13,14 @@ import (
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 )
 
 var (
@@ -19,25 +28,34 @@ var (
 )
 
 // FeatureUpgradeableController is a controller that sets upgradeable=false if anything outside the allowed list is the specified featuregates.
+// The gates of the TechPreviewNoUpgrade, IPv6DualStackNoUpgrade and CustomNoUpgrade feature sets are analyzed against
+// the version of the update instead, only the gates that are not safe to update block it.
 type FeatureUpgradeableController struct {
-	operatorClient    v1helpers.OperatorClient
-	featureGateLister configlistersv1.FeatureGateLister
+	operatorClient        v1helpers.OperatorClient
+	featureGateLister     configlistersv1.FeatureGateLister
+	clusterVersionLister  configlistersv1.ClusterVersionLister
+	configConfigMapLister corev1listers.ConfigMapLister
 }
 
 func NewFeatureUpgradeableController(
 	operatorClient v1helpers.OperatorClient,
 	configInformer configinformers.SharedInformerFactory,
+	configConfigMapInformer corev1informers.ConfigMapInformer,
 	eventRecorder events.Recorder,
 ) factory.Controller {
 	c := &FeatureUpgradeableController{
-		operatorClient:    operatorClient,
-		featureGateLister: configInformer.Config().V1().FeatureGates().Lister(),
+		operatorClient:        operatorClient,
+		featureGateLister:     configInformer.Config().V1().FeatureGates().Lister(),
+		clusterVersionLister:  configInformer.Config().V1().ClusterVersions().Lister(),
+		configConfigMapLister: configConfigMapInformer.Lister(),
 	}
 
 	return factory.New().WithInformers(
 		operatorClient.Informer(),
 		configInformer.Config().V1().FeatureGates().Informer(),
-	).WithSync(c.sync).ToController("FeatureUpgradeableController", eventRecorder.WithComponentSuffix("feature-upgradeable"))
+		configInformer.Config().V1().ClusterVersions().Informer(),
+		configConfigMapInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("FeatureUpgradeableController", c.sync)).ToController("FeatureUpgradeableController", eventRecorder.WithComponentSuffix("feature-upgradeable"))
 }
 
 func (c *FeatureUpgradeableController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
@@ -46,7 +64,22 @@ func (c *FeatureUpgradeableController) sync(ctx context.Context, syncCtx factory
 		return err
 	}
 
-	cond := newUpgradeableCondition(featureGates)
+	clusterVersion, err := c.clusterVersionLister.Get("version")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	acknowledged := sets.NewString()
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return err
+	}
+	if errs := operatorconfig.ValidateFeatureGates(operatorConfig.FeatureGates, field.NewPath("featureGates")); len(errs) > 0 {
+		syncCtx.Recorder().Warningf("InvalidConfig", "Ignoring the acknowledged feature gate migrations: %v", errs.ToAggregate())
+	} else {
+		acknowledged.Insert(operatorConfig.FeatureGates.AcknowledgedMigrations...)
+	}
+
+	cond := newUpgradeableCondition(featureGates, targetVersion(clusterVersion), acknowledged)
 	if _, _, updateError := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); updateError != nil {
 		return updateError
 	}
@@ -54,7 +87,7 @@ func (c *FeatureUpgradeableController) sync(ctx context.Context, syncCtx factory
 	return nil
 }
 
-func newUpgradeableCondition(featureGates *configv1.FeatureGate) operatorv1.OperatorCondition {
+func newUpgradeableCondition(featureGates *configv1.FeatureGate, target *semver.Version, acknowledged sets.String) operatorv1.OperatorCondition {
 	if featureGatesAllowingUpgrade.Has(string(featureGates.Spec.FeatureSet)) {
 		return operatorv1.OperatorCondition{
 			Type:   "FeatureGatesUpgradeable",
@@ -63,6 +96,24 @@ func newUpgradeableCondition(featureGates *configv1.FeatureGate) operatorv1.Oper
 		}
 	}
 
+	if _, known := configv1.FeatureSets[featureGates.Spec.FeatureSet]; known {
+		analysis := analyzeFeatureGates(featureGates, target, acknowledged)
+		if len(analysis.blocking) == 0 {
+			return operatorv1.OperatorCondition{
+				Type:    "FeatureGatesUpgradeable",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "AnalyzedFeatureGates_" + string(featureGates.Spec.FeatureSet),
+				Message: fmt.Sprintf("%q allows the update to %s: %s", string(featureGates.Spec.FeatureSet), formatVersion(target), strings.Join(analysis.allowed, ", ")),
+			}
+		}
+		return operatorv1.OperatorCondition{
+			Type:    "FeatureGatesUpgradeable",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "RestrictedFeatureGates_" + string(featureGates.Spec.FeatureSet),
+			Message: fmt.Sprintf("%q does not allow the update to %s: %s", string(featureGates.Spec.FeatureSet), formatVersion(target), strings.Join(analysis.blocking, "; ")),
+		}
+	}
+
 	return operatorv1.OperatorCondition{
 		Type:    "FeatureGatesUpgradeable",
 		Status:  operatorv1.ConditionFalse,
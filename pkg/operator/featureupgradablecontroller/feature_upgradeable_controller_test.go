@@ -4,17 +4,22 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/davecgh/go-spew/spew"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func TestNewUpgradeableCondition(t *testing.T) {
 	tests := []struct {
 		name string
 
-		features string
-		expected operatorv1.OperatorCondition
+		features     string
+		custom       *configv1.CustomFeatureGates
+		target       string
+		acknowledged []string
+		expected     operatorv1.OperatorCondition
 	}{
 		{
 			name:     "default",
@@ -38,11 +43,81 @@ func TestNewUpgradeableCondition(t *testing.T) {
 		{
 			name:     "techpreview",
 			features: string(configv1.TechPreviewNoUpgrade),
+			target:   "4.10.0",
 			expected: operatorv1.OperatorCondition{
 				Reason:  "RestrictedFeatureGates_TechPreviewNoUpgrade",
 				Status:  "False",
 				Type:    "FeatureGatesUpgradeable",
-				Message: "\"TechPreviewNoUpgrade\" does not allow updates",
+				Message: "\"TechPreviewNoUpgrade\" does not allow the update to 4.10: ExternalCloudProvider has data that cannot be migrated",
+			},
+		},
+		{
+			name:     "techpreview unknown version",
+			features: string(configv1.TechPreviewNoUpgrade),
+			expected: operatorv1.OperatorCondition{
+				Reason:  "RestrictedFeatureGates_TechPreviewNoUpgrade",
+				Status:  "False",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"TechPreviewNoUpgrade\" does not allow the update to the next version: CSIDriverAzureDisk has data that cannot be migrated; CSIDriverVSphere has data that cannot be migrated; ExternalCloudProvider has data that cannot be migrated",
+			},
+		},
+		{
+			name:     "dual stack",
+			features: string(configv1.IPv6DualStackNoUpgrade),
+			target:   "4.9.12",
+			expected: operatorv1.OperatorCondition{
+				Reason:  "RestrictedFeatureGates_IPv6DualStackNoUpgrade",
+				Status:  "False",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"IPv6DualStackNoUpgrade\" does not allow the update to 4.9.12: IPv6DualStack has data to migrate: set the ipFamilyPolicy of dual-stack services to SingleStack, then add it to featureGates.acknowledgedMigrations of the operator config",
+			},
+		},
+		{
+			name:         "dual stack acknowledged",
+			features:     string(configv1.IPv6DualStackNoUpgrade),
+			target:       "4.9.12",
+			acknowledged: []string{"IPv6DualStack"},
+			expected: operatorv1.OperatorCondition{
+				Reason:  "AnalyzedFeatureGates_IPv6DualStackNoUpgrade",
+				Status:  "True",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"IPv6DualStackNoUpgrade\" allows the update to 4.9.12: IPv6DualStack is acknowledged to be migrated",
+			},
+		},
+		{
+			name:     "dual stack default in target",
+			features: string(configv1.IPv6DualStackNoUpgrade),
+			target:   "4.10.3",
+			expected: operatorv1.OperatorCondition{
+				Reason:  "AnalyzedFeatureGates_IPv6DualStackNoUpgrade",
+				Status:  "True",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"IPv6DualStackNoUpgrade\" allows the update to 4.10.3: IPv6DualStack is enabled by default in 4.10",
+			},
+		},
+		{
+			name:     "custom",
+			features: string(configv1.CustomNoUpgrade),
+			custom:   &configv1.CustomFeatureGates{Enabled: []string{"CSIMigrationAWS", "APIPriorityAndFairness"}},
+			target:   "4.10.0",
+			expected: operatorv1.OperatorCondition{
+				Reason:  "AnalyzedFeatureGates_CustomNoUpgrade",
+				Status:  "True",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"CustomNoUpgrade\" allows the update to 4.10: CSIMigrationAWS has no data to migrate",
+			},
+		},
+		{
+			name:         "custom unknown and disabled default gates",
+			features:     string(configv1.CustomNoUpgrade),
+			custom:       &configv1.CustomFeatureGates{Enabled: []string{"SomeAlphaGate"}, Disabled: []string{"APIPriorityAndFairness"}},
+			target:       "4.10.0",
+			acknowledged: []string{"SomeAlphaGate"},
+			expected: operatorv1.OperatorCondition{
+				Reason:  "RestrictedFeatureGates_CustomNoUpgrade",
+				Status:  "False",
+				Type:    "FeatureGatesUpgradeable",
+				Message: "\"CustomNoUpgrade\" does not allow the update to 4.10: APIPriorityAndFairness is a default feature gate and must not be disabled; SomeAlphaGate is not known to be safe to update",
 			},
 		},
 		{
@@ -59,13 +134,19 @@ func TestNewUpgradeableCondition(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			var target *semver.Version
+			if len(test.target) > 0 {
+				version := semver.MustParse(test.target)
+				target = &version
+			}
 			actual := newUpgradeableCondition(&configv1.FeatureGate{
 				Spec: configv1.FeatureGateSpec{
 					FeatureGateSelection: configv1.FeatureGateSelection{
-						FeatureSet: configv1.FeatureSet(test.features),
+						FeatureSet:      configv1.FeatureSet(test.features),
+						CustomNoUpgrade: test.custom,
 					},
 				},
-			})
+			}, target, sets.NewString(test.acknowledged...))
 
 			if !reflect.DeepEqual(test.expected, actual) {
 				t.Fatal(spew.Sdump(actual))
@@ -73,3 +154,48 @@ func TestNewUpgradeableCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestTargetVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterVersion *configv1.ClusterVersion
+		expected       string
+	}{
+		{
+			name: "unknown",
+		},
+		{
+			name:           "next minor",
+			clusterVersion: &configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.9.7"}}},
+			expected:       "4.10.0",
+		},
+		{
+			name: "requested update",
+			clusterVersion: &configv1.ClusterVersion{
+				Spec:   configv1.ClusterVersionSpec{DesiredUpdate: &configv1.Update{Version: "4.9.12"}},
+				Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.9.7"}},
+			},
+			expected: "4.9.12",
+		},
+		{
+			name: "completed update",
+			clusterVersion: &configv1.ClusterVersion{
+				Spec:   configv1.ClusterVersionSpec{DesiredUpdate: &configv1.Update{Version: "4.9.7"}},
+				Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.9.7"}},
+			},
+			expected: "4.10.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := targetVersion(test.clusterVersion)
+			switch {
+			case actual == nil && len(test.expected) > 0:
+				t.Fatalf("expected %s, got none", test.expected)
+			case actual != nil && actual.String() != test.expected:
+				t.Fatalf("expected %q, got %s", test.expected, actual)
+			}
+		})
+	}
+}
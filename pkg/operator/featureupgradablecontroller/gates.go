@@ -0,0 +1,138 @@
+package featureupgradablecontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// gateData tells what happens to the data a feature gate created when the gate is turned off or removed.
+type gateData int
+
+const (
+	// noGateData gates only change behavior, nothing they created outlives them.
+	noGateData gateData = iota
+	// migratableGateData gates created objects or settings that the cluster admin can migrate before the update.
+	migratableGateData
+	// unmigratableGateData gates created data that cannot be migrated, e.g. volumes or nodes bound to a driver.
+	unmigratableGateData
+)
+
+// gateMetadata describes how the update of a cluster that enables a gate beyond the default feature set behaves.
+type gateMetadata struct {
+	// defaultIn is the minor version that enables the gate by default, e.g. "4.10". Updates to it or a later version
+	// keep the gate enabled. Empty while the gate is not scheduled to become default.
+	defaultIn string
+	// data is what happens to the data of the gate when an update turns it off.
+	data gateData
+	// migration tells how to migrate the data of a migratableGateData gate.
+	migration string
+}
+
+// knownGates holds the metadata of the gates of the TechPreviewNoUpgrade and IPv6DualStackNoUpgrade feature sets of
+// this release. Gates without metadata always block updates.
+var knownGates = map[string]gateMetadata{
+	"CSIDriverAzureDisk": {defaultIn: "4.10", data: unmigratableGateData},
+	"CSIDriverVSphere":   {defaultIn: "4.10", data: unmigratableGateData},
+	// CSI migration translates in-tree volumes when they are used, their persistent volumes are not changed.
+	"CSIMigrationAWS":       {defaultIn: "4.12", data: noGateData},
+	"CSIMigrationOpenStack": {defaultIn: "4.11", data: noGateData},
+	"CSIMigrationGCE":       {defaultIn: "4.12", data: noGateData},
+	"CSIMigrationAzureDisk": {defaultIn: "4.11", data: noGateData},
+	"ExternalCloudProvider": {data: unmigratableGateData},
+	"IPv6DualStack": {
+		defaultIn: "4.10",
+		data:      migratableGateData,
+		migration: "set the ipFamilyPolicy of dual-stack services to SingleStack",
+	},
+}
+
+// gateAnalysis is the outcome of analyzing the feature gates of a feature set against the version of an update.
+type gateAnalysis struct {
+	// allowed lists why the gates that do not block the update are safe.
+	allowed []string
+	// blocking lists why the gates that block the update are not.
+	blocking []string
+}
+
+// analyzeFeatureGates checks every gate the feature set enables beyond the default feature set. A gate does not block
+// the update to target when target enables it by default, when it has no data, or when the migration of its data is
+// acknowledged. A nil target is an unknown version, gates only pass by their data then. Disabling a default gate
+// always blocks, updates may depend on it.
+func analyzeFeatureGates(featureGates *configv1.FeatureGate, target *semver.Version, acknowledged sets.String) gateAnalysis {
+	defaultEnabled := sets.NewString(configv1.FeatureSets[configv1.Default].Enabled...)
+	enabled, disabled := sets.NewString(), sets.NewString()
+	if featureGates.Spec.FeatureSet == configv1.CustomNoUpgrade {
+		if custom := featureGates.Spec.CustomNoUpgrade; custom != nil {
+			enabled.Insert(custom.Enabled...)
+			disabled.Insert(custom.Disabled...)
+		}
+	} else if featureSet, ok := configv1.FeatureSets[featureGates.Spec.FeatureSet]; ok {
+		enabled.Insert(featureSet.Enabled...)
+		disabled.Insert(featureSet.Disabled...)
+	}
+
+	var analysis gateAnalysis
+	for _, gate := range disabled.Intersection(defaultEnabled).List() {
+		analysis.blocking = append(analysis.blocking, fmt.Sprintf("%s is a default feature gate and must not be disabled", gate))
+	}
+	for _, gate := range enabled.Difference(defaultEnabled).List() {
+		metadata, known := knownGates[gate]
+		switch {
+		case !known:
+			analysis.blocking = append(analysis.blocking, fmt.Sprintf("%s is not known to be safe to update", gate))
+		case target != nil && enabledByDefault(metadata, *target):
+			analysis.allowed = append(analysis.allowed, fmt.Sprintf("%s is enabled by default in %s", gate, metadata.defaultIn))
+		case metadata.data == noGateData:
+			analysis.allowed = append(analysis.allowed, fmt.Sprintf("%s has no data to migrate", gate))
+		case metadata.data == migratableGateData && acknowledged.Has(gate):
+			analysis.allowed = append(analysis.allowed, fmt.Sprintf("%s is acknowledged to be migrated", gate))
+		case metadata.data == migratableGateData:
+			analysis.blocking = append(analysis.blocking, fmt.Sprintf("%s has data to migrate: %s, then add it to featureGates.acknowledgedMigrations of the operator config", gate, metadata.migration))
+		default:
+			analysis.blocking = append(analysis.blocking, fmt.Sprintf("%s has data that cannot be migrated", gate))
+		}
+	}
+	sort.Strings(analysis.blocking)
+	return analysis
+}
+
+func enabledByDefault(metadata gateMetadata, target semver.Version) bool {
+	if len(metadata.defaultIn) == 0 {
+		return false
+	}
+	defaultIn, err := semver.ParseTolerant(metadata.defaultIn)
+	if err != nil {
+		return false
+	}
+	return target.Major > defaultIn.Major || (target.Major == defaultIn.Major && target.Minor >= defaultIn.Minor)
+}
+
+// targetVersion returns the version the cluster updates to, the one of the requested update or else the next minor
+// version, which is the update Upgradeable=False blocks. It returns nil when the current version is not known.
+func targetVersion(clusterVersion *configv1.ClusterVersion) *semver.Version {
+	if clusterVersion == nil {
+		return nil
+	}
+	if update := clusterVersion.Spec.DesiredUpdate; update != nil && len(update.Version) > 0 && update.Version != clusterVersion.Status.Desired.Version {
+		if version, err := semver.ParseTolerant(update.Version); err == nil {
+			return &version
+		}
+	}
+	current, err := semver.ParseTolerant(clusterVersion.Status.Desired.Version)
+	if err != nil {
+		return nil
+	}
+	return &semver.Version{Major: current.Major, Minor: current.Minor + 1}
+}
+
+func formatVersion(version *semver.Version) string {
+	if version == nil {
+		return "the next version"
+	}
+	return strings.TrimSuffix(version.String(), ".0")
+}
@@ -0,0 +1,114 @@
+package fips
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// clusterConfigNamespace and clusterConfigName are the configmap with the install-config of the cluster
+	clusterConfigNamespace = "kube-system"
+	clusterConfigName      = "cluster-config-v1"
+	installConfigKey       = "install-config"
+
+	// minRSAKeyBits is the smallest RSA key FIPS 140-2 allows
+	minRSAKeyBits = 2048
+)
+
+// ApprovedCipherSuites are the TLS 1.2 cipher suites the FIPS validated crypto module of the kube-apiserver negotiates.
+// The other cipher suites of a TLS security profile are not offered in FIPS mode.
+var ApprovedCipherSuites = sets.NewString(
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+)
+
+// Enabled returns true when the cluster was installed in FIPS mode, i.e. with fips: true in its install-config.
+func Enabled(configMapLister corev1listers.ConfigMapLister) (bool, error) {
+	configMap, err := configMapLister.ConfigMaps(clusterConfigNamespace).Get(clusterConfigName)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	installConfig := struct {
+		FIPS bool `json:"fips"`
+	}{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[installConfigKey]), &installConfig); err != nil {
+		return false, fmt.Errorf("failed to decode %s of configmap %s/%s: %v", installConfigKey, clusterConfigNamespace, clusterConfigName, err)
+	}
+	return installConfig.FIPS, nil
+}
+
+// ValidateServingInfo returns the errors of the TLS settings of the serving info that do not work in FIPS mode: a
+// minimum TLS version of 1.3, which the FIPS validated crypto module does not serve, and cipher suites without any
+// FIPS approved one. Empty cipher suites leave the choice to the crypto module.
+func ValidateServingInfo(servingInfo configv1.ServingInfo) []error {
+	if servingInfo.MinTLSVersion == "VersionTLS13" {
+		// the cipher suites of TLS 1.3 are not configurable
+		return []error{fmt.Errorf("servingInfo.minTLSVersion VersionTLS13 (the Modern TLS security profile) is not supported in FIPS mode, the FIPS validated crypto module serves TLS 1.2 only, use VersionTLS12")}
+	}
+	var errs []error
+	if len(servingInfo.CipherSuites) > 0 && !ApprovedCipherSuites.HasAny(servingInfo.CipherSuites...) {
+		errs = append(errs, fmt.Errorf("servingInfo.cipherSuites %s has no FIPS approved cipher suite, add at least one of %s", strings.Join(servingInfo.CipherSuites, ","), strings.Join(ApprovedCipherSuites.List(), ",")))
+	}
+	return errs
+}
+
+// ValidatePrivateKey returns an error if the PEM encoded private key is not of a type FIPS allows: RSA of at least
+// 2048 bits, or ECDSA on the P-256 or P-384 curve.
+func ValidatePrivateKey(keyPEM []byte) error {
+	key, err := keyutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if bits := key.N.BitLen(); bits < minRSAKeyBits {
+			return fmt.Errorf("RSA key of %d bits is not allowed in FIPS mode, it needs at least %d bits", bits, minRSAKeyBits)
+		}
+	case *ecdsa.PrivateKey:
+		if curve := key.Curve; curve != elliptic.P256() && curve != elliptic.P384() {
+			return fmt.Errorf("ECDSA key on curve %s is not allowed in FIPS mode, use P-256 or P-384", curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		return fmt.Errorf("Ed25519 key is not allowed in FIPS mode, use RSA or ECDSA")
+	default:
+		return fmt.Errorf("%T key is not allowed in FIPS mode, use RSA or ECDSA", key)
+	}
+	return nil
+}
+
+// ValidateSecretKeys returns the errors of the private keys of the TLS secrets of the namespace that are not of a type
+// FIPS allows.
+func ValidateSecretKeys(secretLister corev1listers.SecretLister, namespace string) ([]error, error) {
+	secrets, err := secretLister.Secrets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, secret := range secrets {
+		if secret.Type != corev1.SecretTypeTLS || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+			continue
+		}
+		if err := ValidatePrivateKey(secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+			errs = append(errs, fmt.Errorf("secret %s/%s: %v", namespace, secret.Name, err))
+		}
+	}
+	return errs, nil
+}
@@ -0,0 +1,144 @@
+package fips
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/keyutil"
+)
+
+func TestEnabled(t *testing.T) {
+	for installConfig, expected := range map[string]bool{
+		"":                                 false,
+		"apiVersion: v1\nfips: false\n":    false,
+		"apiVersion: v1\nfips: true\n":     true,
+		"apiVersion: v1\nbaseDomain: ex\n": false,
+	} {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := indexer.Add(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-config-v1"},
+			Data:       map[string]string{"install-config": installConfig},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		enabled, err := Enabled(corev1listers.NewConfigMapLister(indexer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enabled != expected {
+			t.Errorf("%q: expected %v, got %v", installConfig, expected, enabled)
+		}
+	}
+
+	enabled, err := Enabled(corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})))
+	if err != nil || enabled {
+		t.Errorf("expected FIPS mode to be disabled without install-config, got %v, %v", enabled, err)
+	}
+}
+
+func TestValidateServingInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		servingInfo configv1.ServingInfo
+		expectedErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "intermediate profile",
+			servingInfo: configv1.ServingInfo{
+				MinTLSVersion: "VersionTLS12",
+				CipherSuites:  []string{"TLS_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			},
+		},
+		{
+			name:        "modern profile",
+			servingInfo: configv1.ServingInfo{MinTLSVersion: "VersionTLS13", CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}},
+			expectedErr: "servingInfo.minTLSVersion VersionTLS13",
+		},
+		{
+			name:        "no approved cipher suite",
+			servingInfo: configv1.ServingInfo{MinTLSVersion: "VersionTLS12", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"}},
+			expectedErr: "servingInfo.cipherSuites TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256 has no FIPS approved cipher suite",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateServingInfo(tt.servingInfo)
+			if len(tt.expectedErr) == 0 {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), tt.expectedErr) {
+				t.Errorf("expected error %q, got %v", tt.expectedErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidatePrivateKey(t *testing.T) {
+	keyPEM := func(key interface{}) []byte {
+		data, err := keyutil.MarshalPrivateKeyToPEM(key)
+		if err == nil {
+			return data
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: der})
+	}
+	rsaKey := func(bits int) interface{} {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	ecdsaKey := func(curve elliptic.Curve) interface{} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range map[string]struct {
+		key         interface{}
+		expectedErr string
+	}{
+		"RSA 2048":   {key: rsaKey(2048)},
+		"RSA 1024":   {key: rsaKey(1024), expectedErr: "RSA key of 1024 bits is not allowed in FIPS mode, it needs at least 2048 bits"},
+		"ECDSA P256": {key: ecdsaKey(elliptic.P256())},
+		"ECDSA P384": {key: ecdsaKey(elliptic.P384())},
+		"ECDSA P521": {key: ecdsaKey(elliptic.P521()), expectedErr: "ECDSA key on curve P-521 is not allowed in FIPS mode, use P-256 or P-384"},
+		"Ed25519":    {key: ed25519Key, expectedErr: "Ed25519 key is not allowed in FIPS mode, use RSA or ECDSA"},
+	} {
+		err := ValidatePrivateKey(keyPEM(tt.key))
+		if len(tt.expectedErr) == 0 && err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if len(tt.expectedErr) > 0 && (err == nil || err.Error() != tt.expectedErr) {
+			t.Errorf("%s: expected error %q, got %v", name, tt.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package healthsummary
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditpolicycontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/connectivitycheckcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodeexclusion"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutpreflight"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorfallback"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/webhooksupportabilitycontroller"
+)
+
+const (
+	// ConfigMapName is the configmap in the operator namespace with the health of every subsystem, keyed by subsystem.
+	ConfigMapName = "health-summary"
+
+	StateHealthy     = "Healthy"
+	StateProgressing = "Progressing"
+	StateWarning     = "Warning"
+	StateDegraded    = "Degraded"
+	// StateUnknown is a subsystem without any condition, e.g. before its controllers synced for the first time.
+	StateUnknown = "Unknown"
+
+	// checkInterval is how often lastCheckedTime is renewed while the health of a subsystem does not change. The
+	// operator status changes every few seconds during a rollout, writing the configmap on every change is not worth it.
+	// Health that was checked less than half of it ago is kept, so that the resyncs renew it.
+	checkInterval = time.Minute
+)
+
+// subsystem groups the conditions of the controllers of a part of the operator by the prefixes of their types.
+type subsystem struct {
+	name     string
+	prefixes []string
+}
+
+// subsystems are the keys of the configmap. A condition belongs to the first subsystem with a prefix of its type.
+var subsystems = []subsystem{
+	{name: "rollout", prefixes: []string{
+		"NodeInstaller", "Installer", "StaticPod", "MissingStaticPod", "KubeletNotObservingStaticPod", "RevisionController",
+		"Rollout", "RolledBack", "StartupMonitor", "CanaryRollout", "PendingWindow", "TargetConfigController", "KubeAPIServerDeployment",
+	}},
+	{name: "certs", prefixes: []string{"CertRotation", "NamedCertificate", "BoundSATokenSigner", "OIDCDiscovery", "NodeKubeconfigController"}},
+	{name: "encryption", prefixes: []string{"Encryption"}},
+	{name: "audit", prefixes: []string{"Audit"}},
+	{name: "connectivity", prefixes: []string{"Connectivity"}},
+	{name: "nodeSkew", prefixes: []string{"KubeletMinorVersion", "KubeletVersionSkew"}},
+	{name: "webhooks", prefixes: []string{"MutatingAdmissionWebhook", "ValidatingAdmissionWebhook", "CRDConversionWebhook", "AggregatedAPIServices", "AdmissionWebhookRisk"}},
+}
+
+var (
+	// failureConditionTypes degrade a subsystem when they are true, although their type does not end in Degraded.
+	failureConditionTypes = sets.NewString(
+		connectivitycheckcontroller.ConnectivityOutageConditionType,
+		startupmonitorfallback.RolledBackConditionType,
+	)
+	// warningConditionTypes need attention when they are true, the operator keeps working.
+	warningConditionTypes = sets.NewString(
+		auditpolicycontroller.AuditDisabledConditionType,
+		auditpolicycontroller.AuditPolicyCustomRulesConflictConditionType,
+		rolloutpreflight.RolloutPreflightBlockedConditionType,
+		nodeexclusion.RolloutNodeExcludedConditionType,
+		webhooksupportabilitycontroller.MutatingAdmissionWebhookConfigurationErrorConditionType,
+		webhooksupportabilitycontroller.ValidatingAdmissionWebhookConfigurationErrorConditionType,
+		webhooksupportabilitycontroller.CRDConversionWebhookConfigurationErrorConditionType,
+		webhooksupportabilitycontroller.AdmissionWebhookRiskDetectedConditionType,
+	)
+)
+
+// Health is the health of a subsystem.
+type Health struct {
+	// state is Healthy, Progressing, Warning, Degraded or Unknown
+	State string `json:"state"`
+	// conditions are the conditions of the subsystem that are not as expected, the cause of the state
+	Conditions []Condition `json:"conditions,omitempty"`
+	// lastTransitionTime is when the state changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// lastCheckedTime is when the state was last computed, at most a minute ago while the operator runs
+	LastCheckedTime metav1.Time `json:"lastCheckedTime"`
+}
+
+// Condition is an operator condition that is not as expected.
+type Condition struct {
+	Type   string                     `json:"type"`
+	Status operatorv1.ConditionStatus `json:"status"`
+	Reason string                     `json:"reason,omitempty"`
+}
+
+// HealthSummaryController publishes the health of the rollout, certs, encryption, audit, connectivity, nodeSkew and
+// webhooks subsystems in the health-summary configmap of the operator namespace, one JSON object per subsystem, computed from
+// the conditions of their controllers. Tooling can assess the health of the operator without knowing the conditions
+// and interpreting their messages.
+type HealthSummaryController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+
+	now func() time.Time
+}
+
+func NewHealthSummaryController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &HealthSummaryController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Lister(),
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("HealthSummaryController", c.sync)).ResyncEvery(resyncinterval.For("HealthSummaryController", checkInterval)).ToController("HealthSummaryController", eventRecorder.WithComponentSuffix("health-summary-controller"))
+}
+
+func (c *HealthSummaryController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]string{}
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.OperatorNamespace).Get(ConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		existing = configMap.Data
+	}
+
+	data, err := newSummary(existing, status.Conditions, c.now())
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: ConfigMapName},
+		Data:       data,
+	})
+	return err
+}
+
+// newSummary returns the health of every subsystem. The recorded health of a subsystem is kept while its state and
+// conditions stay the same and it was checked within half of the check interval, so that the configmap is not written on every
+// change of the operator status.
+func newSummary(existing map[string]string, conditions []operatorv1.OperatorCondition, now time.Time) (map[string]string, error) {
+	data := map[string]string{}
+	for _, s := range subsystems {
+		health := newHealth(s, conditions)
+		health.LastCheckedTime = metav1.NewTime(now)
+		health.LastTransitionTime = metav1.NewTime(now)
+
+		var recorded Health
+		// a record that does not decode is started over
+		if raw, ok := existing[s.name]; ok && json.Unmarshal([]byte(raw), &recorded) == nil {
+			if recorded.State == health.State {
+				health.LastTransitionTime = recorded.LastTransitionTime
+				if equality.Semantic.DeepEqual(recorded.Conditions, health.Conditions) && now.Sub(recorded.LastCheckedTime.Time) < checkInterval/2 {
+					data[s.name] = raw
+					continue
+				}
+			}
+		}
+
+		raw, err := json.Marshal(health)
+		if err != nil {
+			return nil, err
+		}
+		data[s.name] = string(raw)
+	}
+	return data, nil
+}
+
+// newHealth returns the state of a subsystem and the conditions that cause it. A subsystem is
+//
+//   - Degraded when a condition ending in Degraded or a failure condition is true, or one ending in Available is false,
+//   - otherwise Warning when a warning condition is true or one ending in Upgradeable is false,
+//   - otherwise Progressing when a condition ending in Progressing is true,
+//   - otherwise Healthy, or Unknown if it has no condition.
+func newHealth(s subsystem, conditions []operatorv1.OperatorCondition) Health {
+	var degraded, warning, progressing []Condition
+	found := false
+	for _, cond := range conditions {
+		if SubsystemOf(cond.Type) != s.name {
+			continue
+		}
+		found = true
+		c := Condition{Type: cond.Type, Status: cond.Status, Reason: cond.Reason}
+		switch {
+		case cond.Status == operatorv1.ConditionTrue && (strings.HasSuffix(cond.Type, "Degraded") || failureConditionTypes.Has(cond.Type)),
+			cond.Status == operatorv1.ConditionFalse && strings.HasSuffix(cond.Type, "Available"):
+			degraded = append(degraded, c)
+		case cond.Status == operatorv1.ConditionTrue && warningConditionTypes.Has(cond.Type),
+			cond.Status == operatorv1.ConditionFalse && strings.HasSuffix(cond.Type, "Upgradeable"):
+			warning = append(warning, c)
+		case cond.Status == operatorv1.ConditionTrue && strings.HasSuffix(cond.Type, "Progressing"):
+			progressing = append(progressing, c)
+		}
+	}
+
+	switch {
+	case len(degraded) > 0:
+		return Health{State: StateDegraded, Conditions: degraded}
+	case len(warning) > 0:
+		return Health{State: StateWarning, Conditions: warning}
+	case len(progressing) > 0:
+		return Health{State: StateProgressing, Conditions: progressing}
+	case found:
+		return Health{State: StateHealthy}
+	default:
+		return Health{State: StateUnknown}
+	}
+}
+
+// SubsystemOf returns the subsystem of a condition type, empty if it belongs to none.
+func SubsystemOf(conditionType string) string {
+	for _, s := range subsystems {
+		for _, prefix := range s.prefixes {
+			if strings.HasPrefix(conditionType, prefix) {
+				return s.name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package healthsummary
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewSummary(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	now := earlier.Add(20 * time.Second)
+	conditions := []operatorv1.OperatorCondition{
+		{Type: "NodeInstallerDegraded", Status: operatorv1.ConditionTrue, Reason: "InstallerFailed"},
+		{Type: "NodeInstallerProgressing", Status: operatorv1.ConditionTrue, Reason: "AllNodesAtLatestRevision"},
+		{Type: "CertRotation_AggregatorProxyClientCert_Degraded", Status: operatorv1.ConditionFalse},
+		{Type: "EncryptionMigrationControllerProgressing", Status: operatorv1.ConditionTrue, Reason: "Migrating"},
+		{Type: "AuditDisabled", Status: operatorv1.ConditionTrue, Reason: "NoneProfileAcknowledged"},
+		{Type: "AuditPolicyDegraded", Status: operatorv1.ConditionFalse},
+		{Type: "ConnectivityOutage", Status: operatorv1.ConditionFalse},
+		{Type: "Random", Status: operatorv1.ConditionTrue},
+	}
+	recordedAudit, err := json.Marshal(Health{
+		State:              StateWarning,
+		Conditions:         []Condition{{Type: "AuditDisabled", Status: operatorv1.ConditionTrue, Reason: "NoneProfileAcknowledged"}},
+		LastTransitionTime: metav1.NewTime(earlier.Add(-time.Hour)),
+		LastCheckedTime:    earlier,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordedCerts, err := json.Marshal(Health{State: StateHealthy, LastTransitionTime: earlier, LastCheckedTime: metav1.NewTime(earlier.Add(-time.Minute))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := newSummary(map[string]string{"audit": string(recordedAudit), "certs": string(recordedCerts), "rollout": "invalid"}, conditions, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Health{
+		"rollout": {
+			State:              StateDegraded,
+			Conditions:         []Condition{{Type: "NodeInstallerDegraded", Status: operatorv1.ConditionTrue, Reason: "InstallerFailed"}},
+			LastTransitionTime: metav1.NewTime(now),
+			LastCheckedTime:    metav1.NewTime(now),
+		},
+		// checked again after the check interval
+		"certs": {State: StateHealthy, LastTransitionTime: earlier, LastCheckedTime: metav1.NewTime(now)},
+		"encryption": {
+			State:              StateProgressing,
+			Conditions:         []Condition{{Type: "EncryptionMigrationControllerProgressing", Status: operatorv1.ConditionTrue, Reason: "Migrating"}},
+			LastTransitionTime: metav1.NewTime(now),
+			LastCheckedTime:    metav1.NewTime(now),
+		},
+		// unchanged within the check interval
+		"audit": {
+			State:              StateWarning,
+			Conditions:         []Condition{{Type: "AuditDisabled", Status: operatorv1.ConditionTrue, Reason: "NoneProfileAcknowledged"}},
+			LastTransitionTime: metav1.NewTime(earlier.Add(-time.Hour)),
+			LastCheckedTime:    earlier,
+		},
+		"connectivity": {State: StateHealthy, LastTransitionTime: metav1.NewTime(now), LastCheckedTime: metav1.NewTime(now)},
+		"nodeSkew":     {State: StateUnknown, LastTransitionTime: metav1.NewTime(now), LastCheckedTime: metav1.NewTime(now)},
+		"webhooks":     {State: StateUnknown, LastTransitionTime: metav1.NewTime(now), LastCheckedTime: metav1.NewTime(now)},
+	}
+	actual := map[string]Health{}
+	for name, raw := range data {
+		var health Health
+		if err := json.Unmarshal([]byte(raw), &health); err != nil {
+			t.Fatal(err)
+		}
+		actual[name] = health
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+	if data["audit"] != string(recordedAudit) {
+		t.Errorf("expected the unchanged audit health to be kept as it is, got %s", data["audit"])
+	}
+}
@@ -0,0 +1,57 @@
+package installergate
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// AddWaitContainer appends an init container to the installer pod that runs the wait command of the operator with the
+// args, the installer does not start before the command returns. The init container is named like the command and
+// runs the image of the installer with its service account token.
+func AddWaitContainer(pod *corev1.Pod, command string, args ...string) {
+	installer := pod.Spec.Containers[0]
+	var mounts []corev1.VolumeMount
+	for _, mount := range installer.VolumeMounts {
+		if mount.Name == "kube-api-access" {
+			mounts = append(mounts, mount)
+		}
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:                     command,
+		Image:                    installer.Image,
+		Command:                  []string{"cluster-kube-apiserver-operator", command},
+		Args:                     args,
+		ImagePullPolicy:          installer.ImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts:             mounts,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+	})
+}
+
+// AddSleepContainer appends an init container with the name to the installer pod that sleeps for the delay, rounded to
+// seconds, before the installer starts.
+func AddSleepContainer(pod *corev1.Pod, name string, delay time.Duration) {
+	installer := pod.Spec.Containers[0]
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:                     name,
+		Image:                    installer.Image,
+		Command:                  []string{"sleep"},
+		Args:                     []string{strconv.Itoa(int(delay.Round(time.Second).Seconds()))},
+		ImagePullPolicy:          installer.ImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+	})
+}
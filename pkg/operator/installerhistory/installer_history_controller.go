@@ -0,0 +1,218 @@
+package installerhistory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installermetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace with the installation attempts of every node, keyed by
+	// node name.
+	ConfigMapName = "installer-history"
+
+	// maxAttempts is how many attempts are kept per node
+	maxAttempts = 20
+	// maxErrorLength truncates the error of a failed attempt
+	maxErrorLength = 512
+
+	OutcomePending   = "Pending"
+	OutcomeRunning   = "Running"
+	OutcomeSucceeded = "Succeeded"
+	OutcomeFailed    = "Failed"
+)
+
+// Attempt is one installer pod of a node.
+type Attempt struct {
+	Revision int32  `json:"revision"`
+	Pod      string `json:"pod"`
+	// created is when the installer pod was created, it can wait in init containers before the installer starts
+	Created  metav1.Time  `json:"created"`
+	Started  *metav1.Time `json:"started,omitempty"`
+	Finished *metav1.Time `json:"finished,omitempty"`
+	Outcome  string       `json:"outcome"`
+	// errorClass is fetch, write, lock, timeout or unknown for a failed attempt
+	ErrorClass string `json:"errorClass,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// InstallerHistoryController records every installer pod of the control plane nodes in the installer-history
+// configmap: the revision, the pod, when it was created, started and finished, and the outcome with the class of the
+// error. The installer pods are pruned and the node statuses only keep the errors of the last failed attempt, the
+// configmap keeps the last attempts of every node.
+type InstallerHistoryController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+	podLister       corev1listers.PodLister
+}
+
+func NewInstallerHistoryController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &InstallerHistoryController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		podLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+	).WithSync(syncmetrics.Instrument("InstallerHistoryController", c.sync)).ToController("InstallerHistoryController", eventRecorder.WithComponentSuffix("installer-history-controller"))
+}
+
+func (c *InstallerHistoryController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]string{}
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(ConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		existing = configMap.Data
+	}
+
+	installers, err := c.podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{"app": "installer"}))
+	if err != nil {
+		return err
+	}
+
+	data, err := newHistory(existing, installers, status.NodeStatuses)
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       data,
+	})
+	return err
+}
+
+// newHistory merges the installer pods into the recorded attempts of the nodes. Attempts of pruned pods are kept, the
+// nodes that are no control plane nodes anymore are dropped.
+func newHistory(existing map[string]string, installers []*corev1.Pod, nodeStatuses []operatorv1.NodeStatus) (map[string]string, error) {
+	data := map[string]string{}
+	for _, nodeStatus := range nodeStatuses {
+		attempts := map[string]Attempt{}
+		if raw, ok := existing[nodeStatus.NodeName]; ok {
+			var recorded []Attempt
+			// a record that does not decode is started over
+			if err := json.Unmarshal([]byte(raw), &recorded); err == nil {
+				for _, attempt := range recorded {
+					attempts[attempt.Pod] = attempt
+				}
+			}
+		}
+		for _, installer := range installers {
+			if installer.Spec.NodeName != nodeStatus.NodeName {
+				continue
+			}
+			if attempt, ok := newAttempt(installer); ok {
+				attempts[attempt.Pod] = attempt
+			}
+		}
+		if len(attempts) == 0 {
+			continue
+		}
+
+		sorted := make([]Attempt, 0, len(attempts))
+		for _, attempt := range attempts {
+			sorted = append(sorted, attempt)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			if !sorted[i].Created.Equal(&sorted[j].Created) {
+				return sorted[i].Created.Before(&sorted[j].Created)
+			}
+			return sorted[i].Pod < sorted[j].Pod
+		})
+		if len(sorted) > maxAttempts {
+			sorted = sorted[len(sorted)-maxAttempts:]
+		}
+		raw, err := json.Marshal(sorted)
+		if err != nil {
+			return nil, err
+		}
+		data[nodeStatus.NodeName] = string(raw)
+	}
+	return data, nil
+}
+
+// newAttempt returns the attempt of an installer pod, false if the name has no revision.
+func newAttempt(installer *corev1.Pod) (Attempt, bool) {
+	// installer-<revision>-<node> or installer-<revision>-retry-<n>-<node>
+	parts := strings.SplitN(installer.Name, "-", 3)
+	if len(parts) < 3 || parts[0] != "installer" {
+		return Attempt{}, false
+	}
+	revision, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return Attempt{}, false
+	}
+
+	attempt := Attempt{
+		Revision: int32(revision),
+		Pod:      installer.Name,
+		Created:  installer.CreationTimestamp,
+		Outcome:  OutcomePending,
+	}
+	for _, containerStatus := range installer.Status.ContainerStatuses {
+		if containerStatus.Name != "installer" {
+			continue
+		}
+		switch state := containerStatus.State; {
+		case state.Running != nil:
+			attempt.Started = state.Running.StartedAt.DeepCopy()
+			attempt.Outcome = OutcomeRunning
+		case state.Terminated != nil:
+			attempt.Started = state.Terminated.StartedAt.DeepCopy()
+			attempt.Finished = state.Terminated.FinishedAt.DeepCopy()
+			attempt.Outcome = OutcomeSucceeded
+			if state.Terminated.ExitCode != 0 {
+				attempt.Outcome = OutcomeFailed
+				attempt.ErrorClass = installermetrics.ClassifyFailure(state.Terminated.Message)
+				attempt.Error = truncate(installermetrics.FailureError(state.Terminated.Message))
+			}
+		}
+	}
+	if attempt.Outcome == OutcomePending && installer.Status.Phase == corev1.PodFailed {
+		// failed before the installer started, e.g. in an init container
+		attempt.Outcome = OutcomeFailed
+		attempt.ErrorClass = "unknown"
+		attempt.Error = truncate(installer.Status.Message)
+	}
+	return attempt, true
+}
+
+func truncate(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+	return message[:maxErrorLength] + "..."
+}
@@ -0,0 +1,73 @@
+package installerhistory
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewHistory(t *testing.T) {
+	created := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	started := metav1.NewTime(created.Add(10 * time.Second))
+	finished := metav1.NewTime(created.Add(40 * time.Second))
+	later := metav1.NewTime(created.Add(5 * time.Minute))
+
+	installer := func(name, nodeName string, created metav1.Time, state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name, CreationTimestamp: created},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "installer", State: state}}},
+		}
+	}
+	pruned := Attempt{Revision: 4, Pod: "installer-4-master-0", Created: metav1.NewTime(created.Add(-time.Hour)), Outcome: OutcomeSucceeded}
+	recorded, err := json.Marshal([]Attempt{pruned})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := newHistory(
+		map[string]string{"master-0": string(recorded), "master-9": "[]"},
+		[]*corev1.Pod{
+			installer("installer-5-retry-1-master-0", "master-0", later, corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: later}}),
+			installer("installer-5-master-0", "master-0", created, corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				StartedAt: started, FinishedAt: finished, ExitCode: 1,
+				Message: "I0601 12:00:20.000000       1 cmd.go:250] Getting secrets ...\nF0601 12:00:40.000000       1 cmd.go:105] failed to copy: secrets \"etcd-client-5\" not found",
+			}}),
+			installer("installer-5-master-1", "master-1", created, corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}),
+		},
+		[]operatorv1.NodeStatus{{NodeName: "master-0"}, {NodeName: "master-1"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]Attempt{
+		"master-0": {
+			pruned,
+			{
+				Revision: 5, Pod: "installer-5-master-0", Created: created, Started: &started, Finished: &finished, Outcome: OutcomeFailed,
+				ErrorClass: "fetch", Error: `F0601 12:00:40.000000       1 cmd.go:105] failed to copy: secrets "etcd-client-5" not found`,
+			},
+			{Revision: 5, Pod: "installer-5-retry-1-master-0", Created: later, Started: &later, Outcome: OutcomeRunning},
+		},
+		"master-1": {
+			{Revision: 5, Pod: "installer-5-master-1", Created: created, Outcome: OutcomePending},
+		},
+	}
+	actual := map[string][]Attempt{}
+	for nodeName, raw := range data {
+		var attempts []Attempt
+		if err := json.Unmarshal([]byte(raw), &attempts); err != nil {
+			t.Fatal(err)
+		}
+		actual[nodeName] = attempts
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected history (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,370 @@
+package installermetrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	installercmd "github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// the classes of installer failures
+	failureFetch   = "fetch"
+	failureWrite   = "write"
+	failureLock    = "lock"
+	failureTimeout = "timeout"
+	failureUnknown = "unknown"
+
+	// slowInstallerThreshold is how long an installer runs before it is reported as slow
+	slowInstallerThreshold = 2 * time.Minute
+)
+
+var (
+	registerMetrics sync.Once
+
+	installerDurationHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Name:    "openshift_kube_apiserver_operator_installer_duration_seconds",
+		Help:    "Report the time the installer container of an installer pod ran on each control plane node.",
+		Buckets: metrics.ExponentialBuckets(5, 2, 10),
+	}, []string{"node", "result"})
+
+	installerFailuresCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_operator_installer_failures_total",
+		Help: "Report the number of failed installer pods on each control plane node by the class of the failure.",
+	}, []string{"node", "class"})
+
+	installerPhaseDurationHistogram = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Name:    "openshift_kube_apiserver_operator_installer_phase_duration_seconds",
+		Help:    "Report the time the phases of the installers took on each control plane node, from the status annotation of their pods.",
+		Buckets: metrics.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"node", "phase"})
+
+	installerRetriesCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_operator_installer_retries_total",
+		Help: "Report the number of requests of the installers on each control plane node that repeated a failed one.",
+	}, []string{"node"})
+
+	revisionRolloutDurationHistogram = metrics.NewHistogram(&metrics.HistogramOpts{
+		Name:    "openshift_kube_apiserver_operator_revision_rollout_duration_seconds",
+		Help:    "Report the time from the creation of a revision until it is rolled out to all control plane nodes.",
+		Buckets: metrics.ExponentialBuckets(30, 2, 10),
+	})
+
+	latestAvailableRevisionGauge = metrics.NewGauge(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_latest_available_revision",
+		Help: "Report the latest revision of the kube-apiserver static pod.",
+	})
+
+	nodeRevisionGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_node_revision",
+		Help: "Report the current and the target revision of the kube-apiserver static pod on each control plane node, the target is 0 while no installer runs.",
+	}, []string{"node", "type"})
+
+	latestRevisionCreatedGauge = metrics.NewGauge(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_latest_revision_created_timestamp_seconds",
+		Help: "Report the creation time of the latest revision as unix timestamp, 0 if it is unknown.",
+	})
+
+	rolloutInProgressGauge = metrics.NewGauge(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_rollout_in_progress",
+		Help: "Report 1 while the latest revision is not on all control plane nodes, 0 otherwise.",
+	})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(installerDurationHistogram)
+		legacyregistry.MustRegister(installerFailuresCounter)
+		legacyregistry.MustRegister(installerPhaseDurationHistogram)
+		legacyregistry.MustRegister(installerRetriesCounter)
+		legacyregistry.MustRegister(revisionRolloutDurationHistogram)
+		legacyregistry.MustRegister(latestAvailableRevisionGauge)
+		legacyregistry.MustRegister(nodeRevisionGauge)
+		legacyregistry.MustRegister(latestRevisionCreatedGauge)
+		legacyregistry.MustRegister(rolloutInProgressGauge)
+	})
+}
+
+// failureClasses map parts of the error of a failed installer to the class of the failure, the first match wins.
+var failureClasses = []struct {
+	class     string
+	fragments []string
+}{
+	{class: failureLock, fragments: []string{"failed to acquire an exclusive lock"}},
+	{class: failureTimeout, fragments: []string{"context deadline exceeded", "timed out", "i/o timeout"}},
+	{class: failureWrite, fragments: []string{"no space left on device", "read-only file system", "permission denied", "open /", "mkdir /", "remove /"}},
+	{class: failureFetch, fragments: []string{"not found", "forbidden", "unauthorized", "connection refused", "pod.yaml"}},
+}
+
+// installerResult is the outcome of an installer pod whose installer container terminated.
+type installerResult struct {
+	nodeName string
+	duration time.Duration
+	finished time.Time
+	// failure is the class of the failure, empty if the installer succeeded
+	failure string
+	// status is the status of the installer in the annotation of its pod, nil when it did not write one
+	status *installercmd.Status
+}
+
+// InstallerMetricsController exports the duration of the installer pods and their failures by node and class, the
+// time from the creation of a revision until it is rolled out to all nodes, and the revisions of the nodes. Only installers and rollouts that
+// finish while the operator runs are observed, a restarted operator does not count them twice. The phases and retries
+// of the status of the installers are exported too, and installers that ran longer than two minutes are
+// reported with a SlowInstaller event that names their slowest phases.
+type InstallerMetricsController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	podLister       corev1listers.PodLister
+	configMapLister corev1listers.ConfigMapLister
+
+	started time.Time
+	now     func() time.Time
+
+	// observed are the UIDs of the installer pods whose result is exported
+	observed sets.String
+	// rolledOut is the last revision observed on all nodes, -1 before the first sync
+	rolledOut int32
+}
+
+func NewInstallerMetricsController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &InstallerMetricsController{
+		operatorClient:  operatorClient,
+		podLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		started:         time.Now(),
+		now:             time.Now,
+		observed:        sets.NewString(),
+		rolledOut:       -1,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+	).WithSync(syncmetrics.Instrument("InstallerMetricsController", c.sync)).ToController("InstallerMetricsController", eventRecorder.WithComponentSuffix("installer-metrics-controller"))
+}
+
+func (c *InstallerMetricsController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	installers, err := c.podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{"app": "installer"}))
+	if err != nil {
+		return err
+	}
+	current := sets.NewString()
+	for _, installer := range installers {
+		current.Insert(string(installer.UID))
+		if c.observed.Has(string(installer.UID)) {
+			continue
+		}
+		result, ok := newInstallerResult(installer)
+		if !ok {
+			continue
+		}
+		c.observed.Insert(string(installer.UID))
+		if result.finished.Before(c.started) {
+			continue
+		}
+		if result.status != nil {
+			for phase, seconds := range result.status.Phases {
+				installerPhaseDurationHistogram.WithLabelValues(result.nodeName, phase).Observe(seconds)
+			}
+			installerRetriesCounter.WithLabelValues(result.nodeName).Add(float64(result.status.Retries))
+		}
+		if result.duration >= slowInstallerThreshold {
+			syncCtx.Recorder().Warningf("SlowInstaller", "%s", slowInstallerMessage(installer.Name, result))
+		}
+		if len(result.failure) > 0 {
+			installerDurationHistogram.WithLabelValues(result.nodeName, "failed").Observe(result.duration.Seconds())
+			installerFailuresCounter.WithLabelValues(result.nodeName, result.failure).Inc()
+			continue
+		}
+		installerDurationHistogram.WithLabelValues(result.nodeName, "succeeded").Observe(result.duration.Seconds())
+	}
+	// forget the pruned installer pods
+	c.observed = c.observed.Intersection(current)
+
+	if err := c.recordRevisions(status); err != nil {
+		return err
+	}
+
+	duration, ok, err := c.rolloutDuration(status)
+	if err != nil {
+		return err
+	}
+	if ok {
+		revisionRolloutDurationHistogram.Observe(duration.Seconds())
+	}
+	return nil
+}
+
+// recordRevisions exports the latest revision, when it was created, the revisions of the nodes and whether a rollout
+// is in progress. Removed nodes are dropped from the node revisions.
+func (c *InstallerMetricsController) recordRevisions(status *operatorv1.StaticPodOperatorStatus) error {
+	latestAvailableRevisionGauge.Set(float64(status.LatestAvailableRevision))
+
+	nodeRevisionGauge.Reset()
+	for _, nodeStatus := range status.NodeStatuses {
+		nodeRevisionGauge.WithLabelValues(nodeStatus.NodeName, "current").Set(float64(nodeStatus.CurrentRevision))
+		nodeRevisionGauge.WithLabelValues(nodeStatus.NodeName, "target").Set(float64(nodeStatus.TargetRevision))
+	}
+
+	if status.LatestAvailableRevision > 0 && !rolledOut(status) {
+		rolloutInProgressGauge.Set(1)
+	} else {
+		rolloutInProgressGauge.Set(0)
+	}
+
+	revisionStatus, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(fmt.Sprintf("revision-status-%d", status.LatestAvailableRevision))
+	switch {
+	case apierrors.IsNotFound(err):
+		latestRevisionCreatedGauge.Set(0)
+	case err != nil:
+		return err
+	default:
+		latestRevisionCreatedGauge.Set(float64(revisionStatus.CreationTimestamp.Unix()))
+	}
+	return nil
+}
+
+// rolloutDuration returns the time the latest revision took to roll out, once it is on all nodes. A revision that is
+// rolled out on the first sync finished before the operator started.
+func (c *InstallerMetricsController) rolloutDuration(status *operatorv1.StaticPodOperatorStatus) (time.Duration, bool, error) {
+	if !rolledOut(status) {
+		if c.rolledOut < 0 {
+			c.rolledOut = 0
+		}
+		return 0, false, nil
+	}
+	revision := status.LatestAvailableRevision
+	if c.rolledOut < 0 {
+		c.rolledOut = revision
+		return 0, false, nil
+	}
+	if revision <= c.rolledOut {
+		return 0, false, nil
+	}
+	c.rolledOut = revision
+
+	revisionStatus, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(fmt.Sprintf("revision-status-%d", revision))
+	if apierrors.IsNotFound(err) {
+		// pruned already, there is nothing to measure from
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return c.now().Sub(revisionStatus.CreationTimestamp.Time), true, nil
+}
+
+// rolledOut returns whether all nodes run the latest revision.
+func rolledOut(status *operatorv1.StaticPodOperatorStatus) bool {
+	if status.LatestAvailableRevision == 0 || len(status.NodeStatuses) == 0 {
+		return false
+	}
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.CurrentRevision < status.LatestAvailableRevision {
+			return false
+		}
+	}
+	return true
+}
+
+// newInstallerResult returns the result of the installer pod, false while its installer container did not terminate.
+func newInstallerResult(installer *corev1.Pod) (installerResult, bool) {
+	for _, containerStatus := range installer.Status.ContainerStatuses {
+		terminated := containerStatus.State.Terminated
+		if containerStatus.Name != "installer" || terminated == nil {
+			continue
+		}
+		result := installerResult{
+			nodeName: installer.Spec.NodeName,
+			duration: terminated.FinishedAt.Sub(terminated.StartedAt.Time),
+			finished: terminated.FinishedAt.Time,
+		}
+		if status, ok := installercmd.ParseStatus(installer); ok {
+			result.status = status
+		}
+		if terminated.ExitCode != 0 {
+			result.failure = ClassifyFailure(terminated.Message)
+		}
+		return result, true
+	}
+	return installerResult{}, false
+}
+
+// slowInstallerMessage describes a slow installer with its three slowest phases and its retries.
+func slowInstallerMessage(podName string, result installerResult) string {
+	message := fmt.Sprintf("The installer pod %s on node %s ran for %s", podName, result.nodeName, result.duration.Round(time.Second))
+	if result.status == nil {
+		return message + ", it did not report its phases"
+	}
+	phases := make([]string, 0, len(result.status.Phases))
+	for phase := range result.status.Phases {
+		phases = append(phases, phase)
+	}
+	sort.Slice(phases, func(i, j int) bool { return result.status.Phases[phases[i]] > result.status.Phases[phases[j]] })
+	if len(phases) > 3 {
+		phases = phases[:3]
+	}
+	slowest := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		slowest = append(slowest, fmt.Sprintf("%s %s", phase, time.Duration(result.status.Phases[phase]*float64(time.Second)).Round(100*time.Millisecond)))
+	}
+	message += fmt.Sprintf(", the slowest phases: %s, %d retried requests", strings.Join(slowest, ", "), result.status.Retries)
+	for _, kind := range []string{"secret", "configmap"} {
+		if requests, ok := result.status.Requests[kind]; ok && requests.Count > 0 {
+			message += fmt.Sprintf(", %d %s requests took up to %s", requests.Count, kind, time.Duration(requests.MaxSeconds*float64(time.Second)).Round(time.Millisecond))
+		}
+	}
+	return message
+}
+
+// ClassifyFailure returns the class of the error of a failed installer: fetch, write, lock, timeout or unknown.
+func ClassifyFailure(message string) string {
+	errLine := strings.ToLower(FailureError(message))
+	for _, failureClass := range failureClasses {
+		for _, fragment := range failureClass.fragments {
+			if strings.Contains(errLine, fragment) {
+				return failureClass.class
+			}
+		}
+	}
+	return failureUnknown
+}
+
+// FailureError returns the error in the termination message of a failed installer. The termination message holds the
+// error of the installer on a single line or else the end of its log, where the error is on the last line with
+// "failed to copy".
+func FailureError(message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], "failed to copy") {
+			return lines[i]
+		}
+	}
+	return lines[len(lines)-1]
+}
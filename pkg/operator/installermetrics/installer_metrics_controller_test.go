@@ -0,0 +1,211 @@
+package installermetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+
+	installercmd "github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "lock",
+			message:  "I0601 12:00:00.000000       1 cmd.go:325] acquiring an exclusive lock on a /var/lock/kube-apiserver-installer.lock\nF0601 12:01:00.000000       1 cmd.go:105] failed to copy: failed to acquire an exclusive lock on /var/lock/kube-apiserver-installer.lock, due to context deadline exceeded",
+			expected: failureLock,
+		},
+		{
+			name:     "timeout",
+			message:  `F0601 12:01:00.000000       1 cmd.go:105] failed to copy: Get "https://172.30.0.1:443/api/v1/namespaces/openshift-kube-apiserver/secrets/etcd-client-5": context deadline exceeded`,
+			expected: failureTimeout,
+		},
+		{
+			name:     "write",
+			message:  "I0601 12:00:00.000000       1 cmd.go:405] Writing pod manifest \"/etc/kubernetes/static-pod-resources/kube-apiserver-pod-5/kube-apiserver-pod.yaml\" ...\nF0601 12:01:00.000000       1 cmd.go:105] failed to copy: open /etc/kubernetes/manifests/kube-apiserver-pod.yaml: no space left on device",
+			expected: failureWrite,
+		},
+		{
+			name:     "fetch",
+			message:  `F0601 12:01:00.000000       1 cmd.go:105] failed to copy: secrets "etcd-client-5" not found`,
+			expected: failureFetch,
+		},
+		{
+			name:     "unknown",
+			message:  "panic: runtime error",
+			expected: failureUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := ClassifyFailure(test.message); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewInstallerResult(t *testing.T) {
+	started := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(started.Add(40 * time.Second))
+
+	pod := func(state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: "master-0"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "installer", State: state}}},
+		}
+	}
+
+	if _, ok := newInstallerResult(pod(corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}})); ok {
+		t.Errorf("expected no result for a running installer")
+	}
+
+	result, ok := newInstallerResult(pod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: started, FinishedAt: finished}}))
+	if !ok || result.nodeName != "master-0" || result.duration != 40*time.Second || len(result.failure) > 0 {
+		t.Errorf("unexpected result of a succeeded installer %#v", result)
+	}
+
+	result, ok = newInstallerResult(pod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+		StartedAt: started, FinishedAt: finished, ExitCode: 1, Message: `failed to copy: secrets "etcd-client-5" not found`,
+	}}))
+	if !ok || result.failure != failureFetch {
+		t.Errorf("unexpected result of a failed installer %#v", result)
+	}
+
+	slow := metav1.NewTime(started.Add(3 * time.Minute))
+	slowInstaller := pod(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+		StartedAt: started, FinishedAt: slow, ExitCode: 1,
+		Message: "failed to copy: open /etc/kubernetes/manifests/kube-apiserver-pod.yaml: no space left on device\n",
+	}})
+	slowInstaller.Annotations = map[string]string{
+		installercmd.StatusAnnotation: `{"revision":"5","nodeName":"master-0","error":"failed to copy: open /etc/kubernetes/manifests/kube-apiserver-pod.yaml: no space left on device","phases":{"fetchSecrets":150,"fetchConfigMaps":20.5,"checkCerts":0.1,"writeResources":2},"requests":{"secret":{"count":12,"errors":3,"totalSeconds":160,"maxSeconds":60}},"retries":3}`,
+	}
+	result, ok = newInstallerResult(slowInstaller)
+	if !ok || result.failure != failureWrite || result.status == nil || result.status.Phases["fetchSecrets"] != 150 || result.status.Retries != 3 {
+		t.Errorf("unexpected result of an installer with a status %#v", result)
+	}
+	expected := "The installer pod installer-5-master-0 on node master-0 ran for 3m0s, the slowest phases: fetchSecrets 2m30s, fetchConfigMaps 20.5s, writeResources 2s, 3 retried requests, 12 secret requests took up to 1m0s"
+	if message := slowInstallerMessage("installer-5-master-0", result); message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+}
+
+func TestRolloutDuration(t *testing.T) {
+	status := func(latest int32, current ...int32) *operatorv1.StaticPodOperatorStatus {
+		s := &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: latest}
+		for _, revision := range current {
+			s.NodeStatuses = append(s.NodeStatuses, operatorv1.NodeStatus{CurrentRevision: revision})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name      string
+		statuses  []*operatorv1.StaticPodOperatorStatus
+		rolledOut int32
+		observed  int
+	}{
+		{name: "rolled out before the first sync", statuses: []*operatorv1.StaticPodOperatorStatus{status(5, 5, 5)}, rolledOut: 5},
+		{name: "rolling out on the first sync", statuses: []*operatorv1.StaticPodOperatorStatus{status(5, 4, 5)}, rolledOut: 0},
+		{name: "rolled out after the first sync", statuses: []*operatorv1.StaticPodOperatorStatus{status(5, 4, 5), status(5, 5, 5)}, rolledOut: 5, observed: 1},
+		{name: "rolled out once", statuses: []*operatorv1.StaticPodOperatorStatus{status(5, 4, 5), status(5, 5, 5), status(5, 5, 5)}, rolledOut: 5, observed: 1},
+		{name: "next revision rolled out", statuses: []*operatorv1.StaticPodOperatorStatus{status(4, 4, 4), status(5, 4, 5), status(5, 5, 5)}, rolledOut: 5, observed: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := indexer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-kube-apiserver",
+				Name:              "revision-status-5",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+			}}); err != nil {
+				t.Fatal(err)
+			}
+			c := &InstallerMetricsController{configMapLister: corev1listers.NewConfigMapLister(indexer), now: time.Now, rolledOut: -1}
+
+			observed := 0
+			for _, s := range test.statuses {
+				duration, ok, err := c.rolloutDuration(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok {
+					observed++
+					if duration < 10*time.Minute {
+						t.Errorf("expected the rollout to take at least 10m, got %v", duration)
+					}
+				}
+			}
+
+			if c.rolledOut != test.rolledOut {
+				t.Errorf("expected revision %d to be rolled out, got %d", test.rolledOut, c.rolledOut)
+			}
+			if observed != test.observed {
+				t.Errorf("expected %d observed rollouts, got %d", test.observed, observed)
+			}
+		})
+	}
+}
+
+func TestRecordRevisions(t *testing.T) {
+	RegisterMetrics()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "openshift-kube-apiserver",
+		Name:              "revision-status-5",
+		CreationTimestamp: metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	c := &InstallerMetricsController{configMapLister: corev1listers.NewConfigMapLister(indexer)}
+
+	// master-2 was removed since the first sync
+	for _, nodeStatuses := range [][]operatorv1.NodeStatus{
+		{{NodeName: "master-0", CurrentRevision: 4}, {NodeName: "master-2", CurrentRevision: 4}},
+		{{NodeName: "master-0", CurrentRevision: 5}, {NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5}},
+	} {
+		if err := c.recordRevisions(&operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: nodeStatuses}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `
+# HELP openshift_kube_apiserver_operator_latest_available_revision [ALPHA] Report the latest revision of the kube-apiserver static pod.
+# TYPE openshift_kube_apiserver_operator_latest_available_revision gauge
+openshift_kube_apiserver_operator_latest_available_revision 5
+# HELP openshift_kube_apiserver_operator_latest_revision_created_timestamp_seconds [ALPHA] Report the creation time of the latest revision as unix timestamp, 0 if it is unknown.
+# TYPE openshift_kube_apiserver_operator_latest_revision_created_timestamp_seconds gauge
+openshift_kube_apiserver_operator_latest_revision_created_timestamp_seconds 1.6225488e+09
+# HELP openshift_kube_apiserver_operator_node_revision [ALPHA] Report the current and the target revision of the kube-apiserver static pod on each control plane node, the target is 0 while no installer runs.
+# TYPE openshift_kube_apiserver_operator_node_revision gauge
+openshift_kube_apiserver_operator_node_revision{node="master-0",type="current"} 5
+openshift_kube_apiserver_operator_node_revision{node="master-0",type="target"} 0
+openshift_kube_apiserver_operator_node_revision{node="master-1",type="current"} 4
+openshift_kube_apiserver_operator_node_revision{node="master-1",type="target"} 5
+# HELP openshift_kube_apiserver_operator_rollout_in_progress [ALPHA] Report 1 while the latest revision is not on all control plane nodes, 0 otherwise.
+# TYPE openshift_kube_apiserver_operator_rollout_in_progress gauge
+openshift_kube_apiserver_operator_rollout_in_progress 1
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(expected),
+		"openshift_kube_apiserver_operator_latest_available_revision",
+		"openshift_kube_apiserver_operator_latest_revision_created_timestamp_seconds",
+		"openshift_kube_apiserver_operator_node_revision",
+		"openshift_kube_apiserver_operator_rollout_in_progress",
+	); err != nil {
+		t.Error(err)
+	}
+}
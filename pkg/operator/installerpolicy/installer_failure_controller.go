@@ -0,0 +1,129 @@
+package installerpolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	InstallerFailuresConditionType = "InstallerFailures"
+
+	RetriableFailureReason = "RetriableFailure"
+	TerminalFailureReason  = "TerminalFailure"
+
+	// the node status reason of the installer controller for a failed installer pod
+	installerFailedReason = "InstallerFailed"
+)
+
+// retriableErrors are the parts of installer errors caused by the API or the network, a retry is expected to succeed.
+var retriableErrors = []string{
+	"connection refused",
+	"connection reset by peer",
+	"context deadline exceeded",
+	"i/o timeout",
+	"no route to host",
+	"TLS handshake timeout",
+	"timed out waiting for the condition",
+	"etcdserver: request timed out",
+	"the server is currently unable to handle the request",
+	"the server was unable to return a response in the time allotted",
+	"too many requests",
+}
+
+// installerFailureController reports the failed installers of the nodes in the InstallerFailures condition, telling
+// retriable failures of the API or the network from terminal ones, like a missing or invalid revision, that fail
+// every retry. Nodes the retry policy gave up on are terminal too.
+type installerFailureController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+}
+
+func NewInstallerFailureController(operatorClient v1helpers.StaticPodOperatorClient, eventRecorder events.Recorder) factory.Controller {
+	c := &installerFailureController{operatorClient: operatorClient}
+	return factory.New().WithInformers(operatorClient.Informer()).WithSync(syncmetrics.Instrument("InstallerFailureController", c.sync)).ToController("InstallerFailureController", eventRecorder.WithComponentSuffix("installer-failure-controller"))
+}
+
+func (c *installerFailureController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	installer, err := InstallerFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	cond := newInstallerFailuresCondition(status.NodeStatuses, installer.MaxAttempts)
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newInstallerFailuresCondition(nodeStatuses []operatorv1.NodeStatus, maxAttempts *int32) operatorv1.OperatorCondition {
+	var messages []string
+	terminal := false
+	for _, nodeStatus := range nodeStatuses {
+		if nodeStatus.LastFailedReason != installerFailedReason || nodeStatus.LastFailedRevision <= nodeStatus.CurrentRevision {
+			continue
+		}
+		failures := failedAttempts(&nodeStatus, nodeStatus.LastFailedRevision)
+		kind := "retriable"
+		switch {
+		case maxAttempts != nil && failures >= int(*maxAttempts):
+			kind = "gave up"
+			terminal = true
+		case !isRetriable(nodeStatus.LastFailedRevisionErrors):
+			kind = "terminal"
+			terminal = true
+		}
+		messages = append(messages, fmt.Sprintf("node %q failed to install revision %d %d times, %s: %s",
+			nodeStatus.NodeName, nodeStatus.LastFailedRevision, failures, kind, strings.Join(nodeStatus.LastFailedRevisionErrors, "; ")))
+	}
+	if len(messages) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   InstallerFailuresConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	sort.Strings(messages)
+	reason := RetriableFailureReason
+	if terminal {
+		reason = TerminalFailureReason
+	}
+	return operatorv1.OperatorCondition{
+		Type:    InstallerFailuresConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(messages, "\n"),
+	}
+}
+
+// isRetriable returns true if every error is a retriable one.
+func isRetriable(errs []string) bool {
+	if len(errs) == 0 {
+		return false
+	}
+	for _, err := range errs {
+		retriable := false
+		for _, retriableErr := range retriableErrors {
+			if strings.Contains(strings.ToLower(err), strings.ToLower(retriableErr)) {
+				retriable = true
+				break
+			}
+		}
+		if !retriable {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package installerpolicy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestNewInstallerFailuresCondition(t *testing.T) {
+	maxAttempts := int32(3)
+	retriable := operatorv1.NodeStatus{
+		NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: installerFailedReason, LastFailedCount: 1,
+		LastFailedRevisionErrors: []string{`installer: Get "https://172.30.0.1:443/api/v1/namespaces/openshift-kube-apiserver/configmaps/config-5": dial tcp 172.30.0.1:443: connect: connection refused`},
+	}
+	terminal := operatorv1.NodeStatus{
+		NodeName: "master-2", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: installerFailedReason, LastFailedCount: 1,
+		LastFailedRevisionErrors: []string{`installer: configmaps "config-5" not found`},
+	}
+	exhausted := retriable
+	exhausted.LastFailedCount = 3
+
+	tests := []struct {
+		name         string
+		nodeStatuses []operatorv1.NodeStatus
+		expected     operatorv1.OperatorCondition
+	}{
+		{
+			name: "no failures",
+			nodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 5, LastFailedRevision: 5, LastFailedReason: installerFailedReason, LastFailedCount: 1},
+			},
+			expected: operatorv1.OperatorCondition{Type: InstallerFailuresConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:         "retriable",
+			nodeStatuses: []operatorv1.NodeStatus{retriable},
+			expected: operatorv1.OperatorCondition{
+				Type:    InstallerFailuresConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  RetriableFailureReason,
+				Message: `node "master-1" failed to install revision 5 1 times, retriable: ` + retriable.LastFailedRevisionErrors[0],
+			},
+		},
+		{
+			name:         "terminal",
+			nodeStatuses: []operatorv1.NodeStatus{retriable, terminal},
+			expected: operatorv1.OperatorCondition{
+				Type:   InstallerFailuresConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: TerminalFailureReason,
+				Message: `node "master-1" failed to install revision 5 1 times, retriable: ` + retriable.LastFailedRevisionErrors[0] + "\n" +
+					`node "master-2" failed to install revision 5 1 times, terminal: installer: configmaps "config-5" not found`,
+			},
+		},
+		{
+			name:         "gave up",
+			nodeStatuses: []operatorv1.NodeStatus{exhausted},
+			expected: operatorv1.OperatorCondition{
+				Type:    InstallerFailuresConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  TerminalFailureReason,
+				Message: `node "master-1" failed to install revision 5 3 times, gave up: ` + retriable.LastFailedRevisionErrors[0],
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := newInstallerFailuresCondition(test.nodeStatuses, &maxAttempts)
+			if !cmp.Equal(test.expected, actual) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(test.expected, actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package installerpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// ApplyRetryPolicy returns an installer pod mutation that applies the retry policy of the operator config. After
+// maxAttempts failed attempts the installer pod is not created anymore, the installer controller reports the error.
+// A retryBackoff longer than the one of the installer controller delays the retry in a retry-backoff init container.
+func ApplyRetryPolicy(operatorClient v1helpers.StaticPodOperatorClient) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return applyRetryPolicy(operatorClient, time.Now)
+}
+
+func applyRetryPolicy(operatorClient v1helpers.StaticPodOperatorClient, now func() time.Time) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		installer, err := InstallerFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if len(installer.Timeout) > 0 {
+			pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--timeout-duration=%s", installer.Timeout))
+		}
+		if len(installer.PodReadyTimeout) > 0 {
+			pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, "--wait-for-pod-ready", fmt.Sprintf("--pod-ready-timeout=%s", installer.PodReadyTimeout))
+		}
+		if installer.MaxAttempts == nil && installer.RetryBackoff == nil {
+			return nil
+		}
+
+		_, status, _, err := operatorClient.GetStaticPodOperatorState()
+		if err != nil {
+			return err
+		}
+		var nodeStatus *operatorv1.NodeStatus
+		for i := range status.NodeStatuses {
+			if status.NodeStatuses[i].NodeName == nodeName {
+				nodeStatus = &status.NodeStatuses[i]
+			}
+		}
+		failures := failedAttempts(nodeStatus, revision)
+		if failures == 0 {
+			return nil
+		}
+		if installer.MaxAttempts != nil && failures >= int(*installer.MaxAttempts) {
+			return fmt.Errorf("giving up on revision %d on node %s after %d failed attempts, only a new revision is installed", revision, nodeName, failures)
+		}
+		if installer.RetryBackoff != nil && nodeStatus.LastFailedTime != nil {
+			delay, err := backoffDelay(*installer.RetryBackoff, failures)
+			if err != nil {
+				return err
+			}
+			if remaining := nodeStatus.LastFailedTime.Add(delay).Sub(now()); remaining > 0 {
+				installergate.AddSleepContainer(pod, "retry-backoff", remaining)
+			}
+		}
+		return nil
+	}
+}
+
+// ApplyPodSettings returns an installer pod mutation that applies the resources, the priority class, the tolerations,
+// the retention of old revisions on the node, the fetch strategy and the metrics textfile dir of the installer config
+// of the operator config. The installer writes its error to the termination message path of its container and its
+// status to the annotation of its pod.
+func ApplyPodSettings() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		installer, err := InstallerFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if installer.Resources != nil {
+			container := &pod.Spec.Containers[0]
+			for name, quantity := range installer.Resources.Requests {
+				if container.Resources.Requests == nil {
+					container.Resources.Requests = corev1.ResourceList{}
+				}
+				container.Resources.Requests[name] = quantity
+			}
+			for name, quantity := range installer.Resources.Limits {
+				if container.Resources.Limits == nil {
+					container.Resources.Limits = corev1.ResourceList{}
+				}
+				container.Resources.Limits[name] = quantity
+			}
+			// the template sets requests and limits to the same values, a request above the old limit raises it
+			for name, request := range container.Resources.Requests {
+				if limit, ok := container.Resources.Limits[name]; ok && limit.Cmp(request) < 0 {
+					if _, set := installer.Resources.Limits[name]; !set {
+						container.Resources.Limits[name] = request
+					}
+				}
+			}
+		}
+		if len(installer.PriorityClassName) > 0 {
+			pod.Spec.PriorityClassName = installer.PriorityClassName
+		}
+		if len(installer.Tolerations) > 0 {
+			pod.Spec.Tolerations = installer.Tolerations
+		}
+		if installer.KeepRevisions != nil {
+			pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--keep-revisions=%d", *installer.KeepRevisions))
+		}
+		if len(installer.MinFreeDisk) > 0 {
+			pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--min-free-disk=%s", installer.MinFreeDisk))
+		}
+		if installer.FetchStrategy == operatorconfig.InstallerFetchList {
+			pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--fetch-strategy=%s", installer.FetchStrategy))
+		}
+		terminationMessagePath := pod.Spec.Containers[0].TerminationMessagePath
+		if len(terminationMessagePath) == 0 {
+			terminationMessagePath = corev1.TerminationMessagePathDefault
+		}
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--termination-message-file=%s", terminationMessagePath), "--status-pod=$(POD_NAME)")
+		if len(installer.MetricsTextfileDir) > 0 {
+			addMetricsTextfileDir(pod, installer.MetricsTextfileDir)
+		}
+		return nil
+	}
+}
+
+// addMetricsTextfileDir mounts the textfile dir of the node-exporter on the node into the installer container and
+// makes the installer write its metrics to it.
+func addMetricsTextfileDir(pod *corev1.Pod, dir string) {
+	directoryOrCreate := corev1.HostPathDirectoryOrCreate
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name:         "metrics-textfile",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: dir, Type: &directoryOrCreate}},
+	})
+	installer := &pod.Spec.Containers[0]
+	installer.VolumeMounts = append(installer.VolumeMounts, corev1.VolumeMount{Name: "metrics-textfile", MountPath: dir})
+	installer.Args = append(installer.Args, fmt.Sprintf("--metrics-textfile-dir=%s", dir))
+}
+
+// failedAttempts counts the failed installers and the fallbacks of the startup monitor of the revision on the node.
+func failedAttempts(nodeStatus *operatorv1.NodeStatus, revision int32) int {
+	if nodeStatus == nil || nodeStatus.LastFailedRevision != revision {
+		return 0
+	}
+	return nodeStatus.LastFailedCount + nodeStatus.LastFallbackCount
+}
+
+// backoffDelay is the initial delay doubled with every further failure, capped by the max delay.
+func backoffDelay(backoff operatorconfig.InstallerRetryBackoff, failures int) (time.Duration, error) {
+	delay, err := time.ParseDuration(backoff.InitialDelay)
+	if err != nil {
+		return 0, err
+	}
+	maxDelay := 16 * delay
+	if len(backoff.MaxDelay) > 0 {
+		if maxDelay, err = time.ParseDuration(backoff.MaxDelay); err != nil {
+			return 0, err
+		}
+	}
+	for i := 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay, nil
+}
+
+// InstallerFromSpec returns the installer config observed from the operator config.
+func InstallerFromSpec(spec *operatorv1.StaticPodOperatorSpec) (operatorconfig.InstallerConfig, error) {
+	installer := operatorconfig.InstallerConfig{}
+	if len(spec.ObservedConfig.Raw) == 0 {
+		return installer, nil
+	}
+	observedConfig := map[string]interface{}{}
+	if err := json.Unmarshal(spec.ObservedConfig.Raw, &observedConfig); err != nil {
+		return installer, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
+	}
+	observedInstaller, found, err := unstructured.NestedMap(observedConfig, "installer")
+	if err != nil || !found {
+		return installer, err
+	}
+	raw, err := json.Marshal(observedInstaller)
+	if err != nil {
+		return installer, err
+	}
+	if err := json.Unmarshal(raw, &installer); err != nil {
+		return installer, fmt.Errorf("incorrect value of installer in the observed config: %v", err)
+	}
+	return installer, nil
+}
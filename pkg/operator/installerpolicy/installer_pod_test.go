@@ -0,0 +1,168 @@
+package installerpolicy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyRetryPolicy(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	failedAt := metav1.NewTime(now.Add(-time.Minute))
+	nodeStatuses := []operatorv1.NodeStatus{
+		{NodeName: "master-0", CurrentRevision: 5},
+		{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedCount: 2, LastFailedTime: &failedAt},
+	}
+
+	tests := []struct {
+		name           string
+		observedConfig string
+		nodeName       string
+		expectedArg    string
+		expectedSleep  string
+		expectError    bool
+	}{
+		{name: "no policy", nodeName: "master-1"},
+		{name: "timeout", observedConfig: `{"installer":{"timeout":"5m"}}`, nodeName: "master-0", expectedArg: "--timeout-duration=5m"},
+		{name: "pod ready timeout", observedConfig: `{"installer":{"podReadyTimeout":"10m"}}`, nodeName: "master-0", expectedArg: "--wait-for-pod-ready --pod-ready-timeout=10m"},
+		{name: "first attempt", observedConfig: `{"installer":{"maxAttempts":1,"retryBackoff":{"initialDelay":"1m"}}}`, nodeName: "master-0"},
+		{name: "attempts left", observedConfig: `{"installer":{"maxAttempts":3}}`, nodeName: "master-1"},
+		{name: "gave up", observedConfig: `{"installer":{"maxAttempts":2}}`, nodeName: "master-1", expectError: true},
+		{name: "backoff", observedConfig: `{"installer":{"retryBackoff":{"initialDelay":"2m"}}}`, nodeName: "master-1", expectedSleep: "180"},
+		{name: "capped backoff", observedConfig: `{"installer":{"retryBackoff":{"initialDelay":"2m","maxDelay":"3m"}}}`, nodeName: "master-1", expectedSleep: "120"},
+		{name: "backoff elapsed", observedConfig: `{"installer":{"retryBackoff":{"initialDelay":"10s","maxDelay":"30s"}}}`, nodeName: "master-1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			operatorClient := v1helpers.NewFakeStaticPodOperatorClient(operatorSpec, &operatorv1.StaticPodOperatorStatus{NodeStatuses: nodeStatuses}, nil, nil)
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "installer",
+				Image: "quay.io/openshift/cluster-kube-apiserver-operator",
+				Args:  []string{"-v=2", "--revision=5"},
+			}}}}
+
+			err := applyRetryPolicy(operatorClient, func() time.Time { return now })(pod, test.nodeName, operatorSpec, 5)
+			if test.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			args := strings.Join(pod.Spec.Containers[0].Args, " ")
+			if len(test.expectedArg) > 0 && !strings.Contains(args, test.expectedArg) {
+				t.Errorf("expected %s in the installer args, got %s", test.expectedArg, args)
+			}
+			if len(test.expectedSleep) == 0 {
+				if len(pod.Spec.InitContainers) > 0 {
+					t.Fatalf("expected the installer not to wait, got %v", pod.Spec.InitContainers)
+				}
+				return
+			}
+			if len(pod.Spec.InitContainers) != 1 || pod.Spec.InitContainers[0].Args[0] != test.expectedSleep {
+				t.Fatalf("expected the installer to wait %ss, got %v", test.expectedSleep, pod.Spec.InitContainers)
+			}
+		})
+	}
+}
+
+func TestApplyPodSettings(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"installer":{"resources":{"requests":{"memory":"400M"}},"priorityClassName":"openshift-user-critical","tolerations":[{"key":"node-role.kubernetes.io/master","operator":"Exists"}],"keepRevisions":3,"minFreeDisk":"1Gi","fetchStrategy":"List","metricsTextfileDir":"/var/lib/node-exporter/textfile"}}`)},
+	}}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		PriorityClassName: "system-node-critical",
+		Tolerations:       []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		Containers: []corev1.Container{{
+			Name: "installer",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m"), corev1.ResourceMemory: resource.MustParse("200M")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m"), corev1.ResourceMemory: resource.MustParse("200M")},
+			},
+		}},
+	}}
+
+	if err := ApplyPodSettings()(pod, "master-0", operatorSpec, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	resources := pod.Spec.Containers[0].Resources
+	if memory := resources.Requests[corev1.ResourceMemory]; memory.String() != "400M" {
+		t.Errorf("expected a memory request of 400M, got %s", memory.String())
+	}
+	if memory := resources.Limits[corev1.ResourceMemory]; memory.String() != "400M" {
+		t.Errorf("expected the memory limit to follow the request, got %s", memory.String())
+	}
+	if cpu := resources.Requests[corev1.ResourceCPU]; cpu.String() != "150m" {
+		t.Errorf("expected the cpu request to be kept, got %s", cpu.String())
+	}
+	if pod.Spec.PriorityClassName != "openshift-user-critical" {
+		t.Errorf("unexpected priority class %s", pod.Spec.PriorityClassName)
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "node-role.kubernetes.io/master" {
+		t.Errorf("unexpected tolerations %v", pod.Spec.Tolerations)
+	}
+	if expected := []string{"--keep-revisions=3", "--min-free-disk=1Gi", "--fetch-strategy=List", "--termination-message-file=/dev/termination-log", "--status-pod=$(POD_NAME)", "--metrics-textfile-dir=/var/lib/node-exporter/textfile"}; !reflect.DeepEqual(pod.Spec.Containers[0].Args, expected) {
+		t.Errorf("expected the args %v, got %v", expected, pod.Spec.Containers[0].Args)
+	}
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].HostPath.Path != "/var/lib/node-exporter/textfile" || len(pod.Spec.Containers[0].VolumeMounts) != 1 {
+		t.Errorf("expected the textfile dir to be mounted, got %v and %v", pod.Spec.Volumes, pod.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestApplySingleNodeFastPath(t *testing.T) {
+	tests := []struct {
+		name           string
+		observedConfig string
+		singleReplica  bool
+		expectFastPath bool
+	}{
+		{name: "fast path", observedConfig: `{"rollout":{"singleNodeFastPath":true}}`, singleReplica: true, expectFastPath: true},
+		{name: "not configured", singleReplica: true},
+		{name: "multi node", observedConfig: `{"rollout":{"singleNodeFastPath":true}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "wait-for-preflight", Command: []string{"cluster-kube-apiserver-operator", "wait-for-preflight"}, Args: []string{"--revision=5"}},
+					{Name: "retry-backoff", Command: []string{"sleep"}, Args: []string{"30"}},
+				},
+				Containers: []corev1.Container{{Name: "installer", Command: []string{"cluster-kube-apiserver-operator", "installer"}}},
+			}}
+
+			if err := ApplySingleNodeFastPath(test.singleReplica)(pod, "master-0", operatorSpec, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			command := strings.Join(pod.Spec.Containers[0].Command, " ")
+			waitArgs := strings.Join(pod.Spec.InitContainers[0].Args, " ")
+			if test.expectFastPath {
+				if command != "cluster-kube-apiserver-operator fast-installer" {
+					t.Errorf("expected the fast installer, got %s", command)
+				}
+				if waitArgs != "--revision=5 --interval=2s" {
+					t.Errorf("expected a short wait interval, got %s", waitArgs)
+				}
+			} else if command != "cluster-kube-apiserver-operator installer" || waitArgs != "--revision=5" {
+				t.Errorf("expected the pod to be unchanged, got %s and %s", command, waitArgs)
+			}
+			if sleep := strings.Join(pod.Spec.InitContainers[1].Args, " "); sleep != "30" {
+				t.Errorf("expected the retry backoff to be unchanged, got %s", sleep)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package installerpolicy
+
+import (
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+)
+
+// singleNodeWaitInterval is how often the wait init containers of the installer pods check whether they can proceed
+// on the fast path.
+const singleNodeWaitInterval = "--interval=2s"
+
+// ApplySingleNodeFastPath returns an installer pod mutation that shortens the installation of a revision on a
+// SingleReplica control plane when rollout.singleNodeFastPath of the operator config is set. The installer container
+// runs fast-installer, which verifies what it wrote before it reports success, and the wait init containers poll
+// faster. The installer controller keeps the bookkeeping of the revisions, a failed verification is a failed
+// installer. It must be the last mutation, after the ones that add init containers.
+func ApplySingleNodeFastPath(singleReplica bool) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		if !singleReplica {
+			return nil
+		}
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if !rollout.SingleNodeFastPath {
+			return nil
+		}
+		installer := &pod.Spec.Containers[0]
+		if len(installer.Command) == 2 && installer.Command[1] == "installer" {
+			installer.Command = []string{installer.Command[0], "fast-installer"}
+		}
+		for i := range pod.Spec.InitContainers {
+			container := &pod.Spec.InitContainers[i]
+			if len(container.Command) == 2 && strings.HasPrefix(container.Command[1], "wait-") {
+				container.Args = append(container.Args, singleNodeWaitInterval)
+			}
+		}
+		return nil
+	}
+}
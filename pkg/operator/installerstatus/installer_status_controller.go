@@ -0,0 +1,194 @@
+package installerstatus
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerhistory"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+// ConfigMapName is the configmap in the target namespace with the installer progress of every node, keyed by node
+// name.
+const ConfigMapName = "node-installer-status"
+
+// NodeInstallerStatus is the installer progress of a node.
+type NodeInstallerStatus struct {
+	CurrentRevision int32 `json:"currentRevision"`
+	TargetRevision  int32 `json:"targetRevision"`
+	// installer is the latest installer pod of the node, nil before the first one
+	Installer *installerhistory.Attempt `json:"installer,omitempty"`
+	// installingSince is when the first installer pod of the target revision was created, nil when the node is at its
+	// target revision
+	InstallingSince *metav1.Time `json:"installingSince,omitempty"`
+	// revisionReady is when the kube-apiserver pod of the current revision became Ready, nil when it is not Ready
+	RevisionReady *metav1.Time `json:"revisionReady,omitempty"`
+	// lastFailure is the last failed installation of the node
+	LastFailure *Failure `json:"lastFailure,omitempty"`
+}
+
+// Failure is a failed installation of a revision.
+type Failure struct {
+	Revision int32        `json:"revision"`
+	Time     *metav1.Time `json:"time,omitempty"`
+	// reason is the error class of the installer failure metrics, or the reason of the node status
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// InstallerStatusController aggregates the node statuses, the installer-history configmap and the kube-apiserver pods
+// into the node-installer-status configmap: per control plane node the current and target revision, the phase of the
+// latest installer pod, since when the target revision is being installed, when the current revision became Ready and
+// the last failure. It stores timestamps only, how long an installation runs is up to the reader, so that the
+// configmap only changes when the installation does.
+type InstallerStatusController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+	podLister       corev1listers.PodLister
+}
+
+func NewInstallerStatusController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &InstallerStatusController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		podLister:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+	).WithSync(syncmetrics.Instrument("InstallerStatusController", c.sync)).ToController("InstallerStatusController", eventRecorder.WithComponentSuffix("installer-status-controller"))
+}
+
+func (c *InstallerStatusController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	history := map[string]string{}
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(installerhistory.ConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		history = configMap.Data
+	}
+
+	pods, err := c.podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{"apiserver": "true"}))
+	if err != nil {
+		return err
+	}
+
+	data, err := newStatuses(status.NodeStatuses, history, pods)
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       data,
+	})
+	return err
+}
+
+// newStatuses returns the installer status of every node, encoded as JSON.
+func newStatuses(nodeStatuses []operatorv1.NodeStatus, history map[string]string, pods []*corev1.Pod) (map[string]string, error) {
+	data := map[string]string{}
+	for _, nodeStatus := range nodeStatuses {
+		var attempts []installerhistory.Attempt
+		if raw, ok := history[nodeStatus.NodeName]; ok {
+			// a record that does not decode is left out like a node without installers
+			if err := json.Unmarshal([]byte(raw), &attempts); err != nil {
+				attempts = nil
+			}
+		}
+		raw, err := json.Marshal(newStatus(nodeStatus, attempts, pods))
+		if err != nil {
+			return nil, err
+		}
+		data[nodeStatus.NodeName] = string(raw)
+	}
+	return data, nil
+}
+
+// newStatus returns the installer status of a node from its node status, its installation attempts ordered by
+// creation, and the kube-apiserver pods.
+func newStatus(nodeStatus operatorv1.NodeStatus, attempts []installerhistory.Attempt, pods []*corev1.Pod) NodeInstallerStatus {
+	status := NodeInstallerStatus{
+		CurrentRevision: nodeStatus.CurrentRevision,
+		TargetRevision:  nodeStatus.TargetRevision,
+	}
+
+	if len(attempts) > 0 {
+		latest := attempts[len(attempts)-1]
+		status.Installer = &latest
+	}
+	if nodeStatus.TargetRevision > 0 && nodeStatus.TargetRevision != nodeStatus.CurrentRevision {
+		for _, attempt := range attempts {
+			if attempt.Revision == nodeStatus.TargetRevision {
+				status.InstallingSince = attempt.Created.DeepCopy()
+				break
+			}
+		}
+	}
+
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].Outcome != installerhistory.OutcomeFailed {
+			continue
+		}
+		status.LastFailure = &Failure{
+			Revision: attempts[i].Revision,
+			Time:     attempts[i].Finished,
+			Reason:   attempts[i].ErrorClass,
+			Message:  attempts[i].Error,
+		}
+		if status.LastFailure.Time == nil {
+			status.LastFailure.Time = attempts[i].Created.DeepCopy()
+		}
+		break
+	}
+	// the history starts with the controller, the node status knows of older failures
+	if status.LastFailure == nil && nodeStatus.LastFailedRevision > 0 {
+		status.LastFailure = &Failure{
+			Revision: nodeStatus.LastFailedRevision,
+			Time:     nodeStatus.LastFailedTime,
+			Reason:   nodeStatus.LastFailedReason,
+			Message:  strings.Join(nodeStatus.LastFailedRevisionErrors, "; "),
+		}
+	}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeStatus.NodeName || pod.Labels["revision"] != strconv.Itoa(int(nodeStatus.CurrentRevision)) {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				status.RevisionReady = condition.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,80 @@
+package installerstatus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerhistory"
+)
+
+func TestNewStatuses(t *testing.T) {
+	created := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(created.Add(40 * time.Second))
+	retried := metav1.NewTime(created.Add(5 * time.Minute))
+	ready := metav1.NewTime(created.Add(-time.Hour))
+	failedBefore := metav1.NewTime(created.Add(-24 * time.Hour))
+
+	failed := installerhistory.Attempt{
+		Revision: 5, Pod: "installer-5-master-0", Created: created, Finished: &finished, Outcome: installerhistory.OutcomeFailed,
+		ErrorClass: "fetch", Error: `secrets "etcd-client-5" not found`,
+	}
+	retry := installerhistory.Attempt{Revision: 5, Pod: "installer-5-retry-1-master-0", Created: retried, Started: &retried, Outcome: installerhistory.OutcomeRunning}
+	succeeded := installerhistory.Attempt{Revision: 5, Pod: "installer-5-master-1", Created: created, Finished: &finished, Outcome: installerhistory.OutcomeSucceeded}
+	history := map[string]string{}
+	for node, attempts := range map[string][]installerhistory.Attempt{"master-0": {failed, retry}, "master-1": {succeeded}} {
+		raw, err := json.Marshal(attempts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history[node] = string(raw)
+	}
+	history["master-2"] = "not json"
+
+	pod := func(nodeName, revision string, readyStatus corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-" + nodeName, Labels: map[string]string{"apiserver": "true", "revision": revision}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus, LastTransitionTime: ready}}},
+		}
+	}
+
+	data, err := newStatuses(
+		[]operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5},
+			{NodeName: "master-1", CurrentRevision: 5},
+			{NodeName: "master-2", CurrentRevision: 3, LastFailedRevision: 2, LastFailedTime: &failedBefore, LastFailedReason: "InstallerFailed", LastFailedRevisionErrors: []string{"a", "b"}},
+		},
+		history,
+		[]*corev1.Pod{pod("master-0", "4", corev1.ConditionTrue), pod("master-1", "4", corev1.ConditionTrue), pod("master-2", "3", corev1.ConditionFalse)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]NodeInstallerStatus{
+		"master-0": {
+			CurrentRevision: 4, TargetRevision: 5, Installer: &retry, InstallingSince: &created, RevisionReady: &ready,
+			LastFailure: &Failure{Revision: 5, Time: &finished, Reason: "fetch", Message: `secrets "etcd-client-5" not found`},
+		},
+		// the pod of the current revision is not there yet
+		"master-1": {CurrentRevision: 5, Installer: &succeeded},
+		"master-2": {CurrentRevision: 3, LastFailure: &Failure{Revision: 2, Time: &failedBefore, Reason: "InstallerFailed", Message: "a; b"}},
+	}
+	actual := map[string]NodeInstallerStatus{}
+	for node, raw := range data {
+		var status NodeInstallerStatus
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			t.Fatal(err)
+		}
+		actual[node] = status
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected statuses:\n%s", diff)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -17,7 +18,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 )
 
 const (
@@ -29,6 +37,7 @@ const (
 	KubeletMinorVersionUnsupportedNextUpgradeReason = "KubeletMinorVersionUnsupportedNextUpgrade"
 	KubeletMinorVersionUnsupportedReason            = "KubeletMinorVersionUnsupported"
 	KubeletMinorVersionAheadReason                  = "KubeletMinorVersionAhead"
+	KubeletMinorVersionExemptReason                 = "KubeletMinorVersionExempt"
 )
 
 // KubeletVersionSkewController sets Upgradeable=False if the kubelet
@@ -39,6 +48,10 @@ const (
 //
 // For even OpenShift minor versions, kubelet versions 0, 1, or 2
 // minor versions behind the API server version are supported.
+//
+// The WarnOnly policy of the operator config reports these kubelets
+// without setting Upgradeable=False, and exempt nodes are left out
+// until their exemption ends.
 type KubeletVersionSkewController interface {
 	factory.Controller
 }
@@ -46,20 +59,30 @@ type KubeletVersionSkewController interface {
 func NewKubeletVersionSkewController(
 	operatorClient v1helpers.OperatorClient,
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient corev1client.ConfigMapsGetter,
 	recorder events.Recorder,
 ) *kubeletVersionSkewController {
 	openShiftVersion := semver.MustParse(status.VersionForOperatorFromEnv())
 	nextOpenShiftVersion := semver.Version{Major: openShiftVersion.Major, Minor: openShiftVersion.Minor + 1}
 	c := &kubeletVersionSkewController{
 		operatorClient:              operatorClient,
+		configMapClient:             configMapClient,
+		eventRecorder:               recorder.WithComponentSuffix("kubelet-version-skew-controller"),
 		nodeLister:                  kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Lister(),
+		configConfigMapLister:       kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		now:                         time.Now,
 		apiServerVersion:            semver.MustParse(status.VersionForOperandFromEnv()),
 		minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(openShiftVersion),
 		minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(nextOpenShiftVersion),
 	}
 	c.Controller = factory.New().
-		WithSync(c.sync).
-		WithInformers(kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Informer()).
+		WithSync(syncmetrics.Instrument("KubeletVersionSkewController", c.sync)).
+		WithInformers(
+			kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Informer(),
+			kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		).
+		// exemptions end without an event
+		ResyncEvery(resyncinterval.For("KubeletVersionSkewController", time.Minute)).
 		ToController("KubeletVersionSkewController", recorder.WithComponentSuffix("kubelet-version-skew-controller"))
 	return c
 }
@@ -78,13 +101,17 @@ func minSupportedKubeletSkewForOpenShiftVersion(v semver.Version) int {
 type kubeletVersionSkewController struct {
 	factory.Controller
 	operatorClient              v1helpers.OperatorClient
+	configMapClient             corev1client.ConfigMapsGetter
+	eventRecorder               events.Recorder
 	nodeLister                  corev1listers.NodeLister
+	configConfigMapLister       corev1listers.ConfigMapLister
+	now                         func() time.Time
 	apiServerVersion            semver.Version
 	minSupportedSkew            int
 	minSupportedSkewNextVersion int
 }
 
-func (c *kubeletVersionSkewController) sync(_ context.Context, _ factory.SyncContext) error {
+func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncContext) error {
 	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
 		return err
@@ -99,41 +126,58 @@ func (c *kubeletVersionSkewController) sync(_ context.Context, _ factory.SyncCon
 	}
 	sort.Sort(byName(nodes))
 
+	policy, exemptions, err := c.skewPolicy()
+	if err != nil {
+		return err
+	}
+
 	var errors nodeKubeletInfos
 	var skewedUnsupported nodeKubeletInfos
 	var skewedLimit nodeKubeletInfos
 	var skewedButOK nodeKubeletInfos
 	var synced nodeKubeletInfos
 	var unsupported nodeKubeletInfos
+	var exempt nodeKubeletInfos
+	var skews []NodeSkew
 
 	// for each node, check kubelet version
 	for _, node := range nodes {
 		kubeletVersion, err := nodeKubeletVersion(node)
 		if err != nil {
 			runtime.HandleError(fmt.Errorf("unable to determine kubelet version on node %s: %w", node.Name, err))
-			errors = append(errors, nodeKubeletInfo{node: node.Name, err: err})
+			skew := c.nodeSkew(node, nil, err)
+			if until, ok := exemptions[node.Name]; ok {
+				skew.ExemptUntil = until.UTC().Format(time.RFC3339)
+				exempt = append(exempt, nodeKubeletInfo{node: node.Name, err: err})
+			} else {
+				errors = append(errors, nodeKubeletInfo{node: node.Name, err: err})
+			}
+			skews = append(skews, skew)
 			continue
 		}
-		skew := int(kubeletVersion.Minor - c.apiServerVersion.Minor)
-		// Assume that an OpenShift minor version upgrade also bumps to the next kube minor version. Revisit
-		// this in the future if an OpenShift minor version upgrade ever skips or repeats a kube minor version.
-		skewNextVersion := skew - 1
-		switch {
-		case skew == 0:
-			// synced
-			synced = append(synced, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
-		case skew < c.minSupportedSkew:
-			// already in an unsupported state
-			skewedUnsupported = append(skewedUnsupported, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
-		case skewNextVersion < c.minSupportedSkewNextVersion:
-			// upgrading to next minor version of API server would result in an unsupported config
-			skewedLimit = append(skewedLimit, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
-		case skew < 0:
-			// behind, but upgrading to next minor version of API server is supported
-			skewedButOK = append(skewedButOK, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+		skew := c.nodeSkew(node, &kubeletVersion, nil)
+		info := nodeKubeletInfo{node: node.Name, version: &kubeletVersion}
+		if until, ok := exemptions[node.Name]; ok {
+			skew.ExemptUntil = until.UTC().Format(time.RFC3339)
+			skews = append(skews, skew)
+			if skew.Verdict != VerdictSynced {
+				exempt = append(exempt, info)
+			}
+			continue
+		}
+		skew.BlocksUpgrade = policy == operatorconfig.KubeletVersionSkewStrict && (skew.Verdict == VerdictUnsupported || skew.Verdict == VerdictUnsupportedNextUpgrade)
+		skews = append(skews, skew)
+		switch skew.Verdict {
+		case VerdictSynced:
+			synced = append(synced, info)
+		case VerdictUnsupported:
+			skewedUnsupported = append(skewedUnsupported, info)
+		case VerdictUnsupportedNextUpgrade:
+			skewedLimit = append(skewedLimit, info)
+		case VerdictSupportedNextUpgrade:
+			skewedButOK = append(skewedButOK, info)
 		default:
-			// kubelet version newer than api server version. possibly in the middle of a rollback.
-			unsupported = append(unsupported, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+			unsupported = append(unsupported, info)
 		}
 	}
 
@@ -184,6 +228,17 @@ func (c *kubeletVersionSkewController) sync(_ context.Context, _ factory.SyncCon
 		default:
 			condition.Message = fmt.Sprintf("Kubelet minor versions on %d nodes will not be supported in the next OpenShift minor version upgrade.", len(skewedLimit))
 		}
+	case len(exempt) > 0:
+		condition.Reason = KubeletMinorVersionExemptReason
+		condition.Status = operatorv1.ConditionTrue
+		switch len(exempt) {
+		case 1:
+			condition.Message = fmt.Sprintf("Kubelet minor version on node %s is not checked, the node is exempt from the kubelet version skew policy.", exempt.nodes())
+		case 2, 3:
+			condition.Message = fmt.Sprintf("Kubelet minor versions on nodes %s are not checked, the nodes are exempt from the kubelet version skew policy.", exempt.nodes())
+		default:
+			condition.Message = fmt.Sprintf("Kubelet minor versions on %d nodes are not checked, the nodes are exempt from the kubelet version skew policy.", len(exempt))
+		}
 	case len(skewedButOK) > 0:
 		condition.Reason = KubeletMinorVersionSupportedNextUpgradeReason
 		condition.Status = operatorv1.ConditionTrue
@@ -200,9 +255,43 @@ func (c *kubeletVersionSkewController) sync(_ context.Context, _ factory.SyncCon
 		condition.Status = operatorv1.ConditionTrue
 		condition.Message = "Kubelet and API server minor versions are synced."
 	}
+	if condition.Status == operatorv1.ConditionFalse && policy == operatorconfig.KubeletVersionSkewWarnOnly {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Message += " The kubelet version skew policy is WarnOnly, the upgrade is not blocked."
+	}
+
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+		return err
+	}
+	return c.reportNodeSkews(ctx, skews)
+}
 
-	_, _, err = v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(condition))
-	return err
+// skewPolicy returns the kubelet version skew policy of the operator config and the end of the exemptions that have
+// not ended yet, keyed by node. An invalid config falls back to the Strict policy without exemptions.
+func (c *kubeletVersionSkewController) skewPolicy() (operatorconfig.KubeletVersionSkewPolicy, map[string]time.Time, error) {
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return "", nil, err
+	}
+	config := operatorConfig.KubeletVersionSkew
+	if errs := operatorconfig.ValidateKubeletVersionSkew(config, field.NewPath("kubeletVersionSkew")); len(errs) > 0 {
+		c.eventRecorder.Warningf("InvalidConfig", "Enforcing the Strict kubelet version skew policy without exemptions: %v", errs.ToAggregate())
+		return operatorconfig.KubeletVersionSkewStrict, nil, nil
+	}
+	policy := config.Policy
+	if len(policy) == 0 {
+		policy = operatorconfig.KubeletVersionSkewStrict
+	}
+	now := c.now()
+	exemptions := map[string]time.Time{}
+	for _, exemption := range config.ExemptNodes {
+		// validated above
+		until, _ := time.Parse(time.RFC3339, exemption.Until)
+		if now.Before(until) {
+			exemptions[exemption.Node] = until
+		}
+	}
+	return policy, exemptions, nil
 }
 
 type nodeKubeletInfo struct {
@@ -1,15 +1,20 @@
 package kubeletversionskewcontroller
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/client-go/kubernetes/fake"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
@@ -31,6 +36,7 @@ func Test_kubeletVersionSkewController_Sync(t *testing.T) {
 		name             string
 		ocpVersion       string
 		kubeletVersions  []string
+		operatorConfig   string
 		expectedStatus   operatorv1.ConditionStatus
 		expectedReason   string
 		expectedMsgLines string
@@ -131,6 +137,42 @@ func Test_kubeletVersionSkewController_Sync(t *testing.T) {
 			expectedReason:   KubeletMinorVersionAheadReason,
 			expectedMsgLines: "Unsupported kubelet minor version (1.22.2) on node test002 is ahead of the target API server version (1.21.1).",
 		},
+		{
+			name:             "WarnOnly",
+			ocpVersion:       evenOpenShiftVersion,
+			kubeletVersions:  skewedKubeletVersions(0, -1, 0),
+			operatorConfig:   "kubeletVersionSkew:\n  policy: WarnOnly\n",
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionUnsupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet minor version (1.20.1) on node test001 will not be supported in the next OpenShift minor version upgrade. The kubelet version skew policy is WarnOnly, the upgrade is not blocked.",
+		},
+		{
+			name:             "Exempt",
+			ocpVersion:       evenOpenShiftVersion,
+			kubeletVersions:  skewedKubeletVersions(0, -1, -3),
+			operatorConfig:   "kubeletVersionSkew:\n  exemptNodes:\n  - node: test001\n    until: \"2021-11-30T00:00:00Z\"\n  - node: test002\n    until: \"2021-11-30T00:00:00Z\"\n",
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionExemptReason,
+			expectedMsgLines: "Kubelet minor versions on nodes test001 and test002 are not checked, the nodes are exempt from the kubelet version skew policy.",
+		},
+		{
+			name:             "ExemptionEnded",
+			ocpVersion:       evenOpenShiftVersion,
+			kubeletVersions:  skewedKubeletVersions(0, -1, 0),
+			operatorConfig:   "kubeletVersionSkew:\n  exemptNodes:\n  - node: test001\n    until: \"2021-10-30T00:00:00Z\"\n",
+			expectedStatus:   operatorv1.ConditionFalse,
+			expectedReason:   KubeletMinorVersionUnsupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet minor version (1.20.1) on node test001 will not be supported in the next OpenShift minor version upgrade.",
+		},
+		{
+			name:             "InvalidConfigIsStrict",
+			ocpVersion:       evenOpenShiftVersion,
+			kubeletVersions:  skewedKubeletVersions(0, -1, 0),
+			operatorConfig:   "kubeletVersionSkew:\n  policy: Ignore\n  exemptNodes:\n  - node: test001\n    until: \"2021-11-30T00:00:00Z\"\n",
+			expectedStatus:   operatorv1.ConditionFalse,
+			expectedReason:   KubeletMinorVersionUnsupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet minor version (1.20.1) on node test001 will not be supported in the next OpenShift minor version upgrade.",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -141,6 +183,11 @@ func Test_kubeletVersionSkewController_Sync(t *testing.T) {
 					Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kv}},
 				})
 			}
+			configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMaps.Add(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+				Data:       map[string]string{"config.yaml": tc.operatorConfig},
+			})
 			status := &operatorv1.StaticPodOperatorStatus{}
 			ocpVersion := semver.MustParse(tc.ocpVersion)
 			nextOpenShiftVersion := semver.Version{Major: ocpVersion.Major, Minor: ocpVersion.Minor + 1}
@@ -149,12 +196,16 @@ func Test_kubeletVersionSkewController_Sync(t *testing.T) {
 					&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
 					status, nil, nil,
 				),
+				configMapClient:             fake.NewSimpleClientset().CoreV1(),
+				eventRecorder:               events.NewInMemoryRecorder("test"),
 				nodeLister:                  corev1listers.NewNodeLister(indexer),
+				configConfigMapLister:       corev1listers.NewConfigMapLister(configMaps),
+				now:                         func() time.Time { return time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC) },
 				apiServerVersion:            semver.MustParse(apiServerVersion),
 				minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(ocpVersion),
 				minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(nextOpenShiftVersion),
 			}
-			err := c.sync(nil, nil)
+			err := c.sync(context.TODO(), nil)
 			if err != nil {
 				t.Fatalf("sync() unexpected err: %v", err)
 			}
@@ -178,3 +229,66 @@ func Test_kubeletVersionSkewController_Sync(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeSkew(t *testing.T) {
+	ocpVersion := semver.MustParse("4.8.0")
+	client := fake.NewSimpleClientset()
+	c := &kubeletVersionSkewController{
+		configMapClient:             client.CoreV1(),
+		eventRecorder:               events.NewInMemoryRecorder("test"),
+		apiServerVersion:            semver.MustParse("1.21.1"),
+		minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(ocpVersion),
+		minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(semver.Version{Major: 4, Minor: 9}),
+	}
+	node := func(name, kubeletVersion string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubeletVersion}},
+		}
+	}
+
+	var skews []NodeSkew
+	for name, kubeletVersion := range map[string]string{
+		"synced":      "v1.21.3",
+		"behind":      "v1.20.0",
+		"unsupported": "v1.18.0",
+		"ahead":       "v1.22.0",
+	} {
+		version, err := nodeKubeletVersion(node(name, kubeletVersion))
+		if err != nil {
+			t.Fatal(err)
+		}
+		skews = append(skews, c.nodeSkew(node(name, kubeletVersion), &version, nil))
+	}
+	_, err := nodeKubeletVersion(node("unknown", "invalid"))
+	skews = append(skews, c.nodeSkew(node("unknown", "invalid"), nil, err))
+
+	if err := c.reportNodeSkews(context.TODO(), skews); err != nil {
+		t.Fatal(err)
+	}
+	configMap, err := client.CoreV1().ConfigMaps("openshift-kube-apiserver-operator").Get(context.TODO(), ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := configMap.Data["synced"]; ok {
+		t.Errorf("expected the synced node not to be reported")
+	}
+	expected := map[string]NodeSkew{
+		"behind":      {Skew: -1, Verdict: VerdictUnsupportedNextUpgrade},
+		"unsupported": {Skew: -3, Verdict: VerdictUnsupported},
+		"ahead":       {Skew: 1, Verdict: VerdictAhead},
+		"unknown":     {Verdict: VerdictUnknown},
+	}
+	for name, e := range expected {
+		var skew NodeSkew
+		if err := json.Unmarshal([]byte(configMap.Data[name]), &skew); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if skew.Node != name || skew.Skew != e.Skew || skew.Verdict != e.Verdict || len(skew.Remediation) == 0 {
+			t.Errorf("%s: unexpected skew %+v", name, skew)
+		}
+		if skew.MinSupportedVersion != "1.19" || skew.MaxSupportedVersion != "1.21" || skew.MinSupportedVersionNextUpgrade != "1.21" {
+			t.Errorf("%s: unexpected supported range %+v", name, skew)
+		}
+	}
+}
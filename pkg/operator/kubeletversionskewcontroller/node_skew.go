@@ -0,0 +1,154 @@
+package kubeletversionskewcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the configmap in the operator namespace with the skew of every node whose kubelet minor version
+	// is not the one of the API server, keyed by node.
+	ConfigMapName = "kubelet-version-skew"
+
+	// VerdictSynced is a kubelet of the minor version of the API server.
+	VerdictSynced = "Synced"
+	// VerdictSupportedNextUpgrade is a kubelet behind the API server that the next OpenShift minor version supports.
+	VerdictSupportedNextUpgrade = "SupportedNextUpgrade"
+	// VerdictUnsupportedNextUpgrade is a kubelet that the next OpenShift minor version does not support, it blocks the
+	// upgrade.
+	VerdictUnsupportedNextUpgrade = "UnsupportedNextUpgrade"
+	// VerdictUnsupported is a kubelet too far behind the API server.
+	VerdictUnsupported = "Unsupported"
+	// VerdictAhead is a kubelet newer than the API server, e.g. in the middle of a rollback.
+	VerdictAhead = "Ahead"
+	// VerdictUnknown is a kubelet whose version cannot be parsed.
+	VerdictUnknown = "Unknown"
+)
+
+var (
+	registerMetrics sync.Once
+
+	kubeletVersionSkewGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_kubelet_version_skew",
+		Help: "Report the kubelet minor versions each node is behind (negative) or ahead of the API server, by verdict. Nodes of the minor version of the API server are not reported.",
+	}, []string{"node", "verdict"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(kubeletVersionSkewGauge)
+	})
+}
+
+// NodeSkew is the kubelet version skew of a node.
+type NodeSkew struct {
+	Node string `json:"node"`
+	// kubeletVersion is the version the kubelet reports
+	KubeletVersion string `json:"kubeletVersion"`
+	// skew is the number of minor versions the kubelet is behind (negative) or ahead of the API server
+	Skew int `json:"skew"`
+	// minSupportedVersion and maxSupportedVersion are the kubelet minor versions the API server supports
+	MinSupportedVersion string `json:"minSupportedVersion"`
+	MaxSupportedVersion string `json:"maxSupportedVersion"`
+	// minSupportedVersionNextUpgrade is the oldest kubelet minor version the next OpenShift minor version supports
+	MinSupportedVersionNextUpgrade string `json:"minSupportedVersionNextUpgrade"`
+	// verdict is Synced, SupportedNextUpgrade, UnsupportedNextUpgrade, Unsupported, Ahead or Unknown
+	Verdict string `json:"verdict"`
+	// remediation is what to do about the node, empty if nothing
+	Remediation string `json:"remediation,omitempty"`
+	// blocksUpgrade tells whether the node sets KubeletMinorVersionUpgradeable to false under the Strict policy
+	BlocksUpgrade bool `json:"blocksUpgrade"`
+	// exemptUntil is when the exemption of the node from the kubelet version skew policy ends, empty if not exempt
+	ExemptUntil string `json:"exemptUntil,omitempty"`
+}
+
+// verdictOf returns the verdict of a kubelet version.
+func (c *kubeletVersionSkewController) verdictOf(kubeletVersion semver.Version) string {
+	skew := int(kubeletVersion.Minor) - int(c.apiServerVersion.Minor)
+	// Assume that an OpenShift minor version upgrade also bumps to the next kube minor version. Revisit
+	// this in the future if an OpenShift minor version upgrade ever skips or repeats a kube minor version.
+	skewNextVersion := skew - 1
+	switch {
+	case skew == 0:
+		return VerdictSynced
+	case skew < c.minSupportedSkew:
+		// already in an unsupported state
+		return VerdictUnsupported
+	case skewNextVersion < c.minSupportedSkewNextVersion:
+		// upgrading to next minor version of API server would result in an unsupported config
+		return VerdictUnsupportedNextUpgrade
+	case skew < 0:
+		// behind, but upgrading to next minor version of API server is supported
+		return VerdictSupportedNextUpgrade
+	default:
+		// kubelet version newer than api server version. possibly in the middle of a rollback.
+		return VerdictAhead
+	}
+}
+
+// nodeSkew returns the skew of a node with the kubelet version, or the error parsing it.
+func (c *kubeletVersionSkewController) nodeSkew(node *corev1.Node, kubeletVersion *semver.Version, err error) NodeSkew {
+	skew := NodeSkew{
+		Node:                           node.Name,
+		KubeletVersion:                 node.Status.NodeInfo.KubeletVersion,
+		MinSupportedVersion:            c.minorVersion(c.minSupportedSkew),
+		MaxSupportedVersion:            c.minorVersion(0),
+		MinSupportedVersionNextUpgrade: c.minorVersion(1 + c.minSupportedSkewNextVersion),
+	}
+	if err != nil {
+		skew.Verdict = VerdictUnknown
+		skew.Remediation = fmt.Sprintf("Check the kubelet version the node reports in status.nodeInfo.kubeletVersion: %v", err)
+		return skew
+	}
+
+	skew.Skew = int(kubeletVersion.Minor) - int(c.apiServerVersion.Minor)
+	skew.Verdict = c.verdictOf(*kubeletVersion)
+	switch skew.Verdict {
+	case VerdictUnsupported:
+		skew.Remediation = fmt.Sprintf("Update the kubelet of the node to %s or later, the API server %v does not support it.", skew.MinSupportedVersion, c.apiServerVersion)
+	case VerdictUnsupportedNextUpgrade:
+		skew.Remediation = fmt.Sprintf("Update the kubelet of the node to %s or later before the next OpenShift minor version upgrade, e.g. by unpausing its machine config pool.", skew.MinSupportedVersionNextUpgrade)
+	case VerdictAhead:
+		skew.Remediation = fmt.Sprintf("Roll the kubelet of the node back to %s or finish the upgrade of the API server, the API server %v does not support newer kubelets.", skew.MaxSupportedVersion, c.apiServerVersion)
+	}
+	return skew
+}
+
+// minorVersion returns the minor version of the API server with an offset, e.g. 1.21 for -1 and an API server 1.22.
+func (c *kubeletVersionSkewController) minorVersion(offset int) string {
+	return fmt.Sprintf("%d.%d", c.apiServerVersion.Major, int(c.apiServerVersion.Minor)+offset)
+}
+
+// reportNodeSkews reports the nodes that are not synced in the metric and the configmap. The synced nodes are left
+// out, so that the configmap of a large cluster stays small.
+func (c *kubeletVersionSkewController) reportNodeSkews(ctx context.Context, skews []NodeSkew) error {
+	kubeletVersionSkewGauge.Reset()
+	data := map[string]string{}
+	for _, skew := range skews {
+		if skew.Verdict == VerdictSynced {
+			continue
+		}
+		kubeletVersionSkewGauge.WithLabelValues(skew.Node, skew.Verdict).Set(float64(skew.Skew))
+		raw, err := json.Marshal(skew)
+		if err != nil {
+			return err
+		}
+		data[skew.Node] = string(raw)
+	}
+	_, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, c.eventRecorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: ConfigMapName},
+		Data:       data,
+	})
+	return err
+}
@@ -0,0 +1,37 @@
+package maintenancewindow
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// WaitForMaintenanceWindow returns an installer pod mutation that holds back the installer pods while no maintenance
+// window is open. An init container waits until the rollout-maintenance-window configmap tells a window is open, the
+// installer controller keeps waiting for the pending installer pod meanwhile. An installer that runs when its window
+// closes finishes its node.
+func WaitForMaintenanceWindow() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if len(rollout.MaintenanceWindows) == 0 {
+			return nil
+		}
+		addWaitForMaintenanceWindowContainer(pod)
+		return nil
+	}
+}
+
+func addWaitForMaintenanceWindowContainer(pod *corev1.Pod) {
+	installergate.AddWaitContainer(pod, "wait-for-maintenance-window",
+		fmt.Sprintf("--namespace=%s", operatorclient.TargetNamespace),
+		fmt.Sprintf("--configmap=%s", ConfigMapName),
+	)
+}
@@ -0,0 +1,117 @@
+package maintenancewindow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace that tells the waiting installer pods whether a
+	// maintenance window is open.
+	ConfigMapName = "rollout-maintenance-window"
+	OpenKey       = "open"
+
+	PendingWindowConditionType = "PendingWindow"
+)
+
+// MaintenanceWindowController publishes whether one of the maintenance windows of the rollout in the operator config
+// is open to the rollout-maintenance-window configmap, which the installer pods wait for. The PendingWindow condition
+// names the nodes that wait for the next window to install the latest revision. Windows only hold back installer pods,
+// the certificates of the kube-apiserver are synced into the running pods without a revision and keep rotating.
+type MaintenanceWindowController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+
+	now func() time.Time
+}
+
+func NewMaintenanceWindowController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &MaintenanceWindowController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("MaintenanceWindowController", c.sync)).ResyncEvery(resyncinterval.For("MaintenanceWindowController", time.Minute)).ToController("MaintenanceWindowController", eventRecorder.WithComponentSuffix("maintenance-window-controller"))
+}
+
+func (c *MaintenanceWindowController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	open, next := true, time.Time{}
+	if len(rollout.MaintenanceWindows) > 0 {
+		if open, next, err = isOpen(rollout.MaintenanceWindows, c.now()); err != nil {
+			return err
+		}
+	}
+
+	_, changed, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       map[string]string{OpenKey: strconv.FormatBool(open)},
+	})
+	if err != nil {
+		return err
+	}
+	if changed && open {
+		syncCtx.Recorder().Eventf("MaintenanceWindowOpened", "new revisions are installed")
+	} else if changed {
+		syncCtx.Recorder().Eventf("MaintenanceWindowClosed", "new revisions are installed in the next maintenance window at %s", next.Format(time.RFC3339))
+	}
+
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(newPendingWindowCondition(open, next, status))); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newPendingWindowCondition(open bool, next time.Time, status *operatorv1.StaticPodOperatorStatus) operatorv1.OperatorCondition {
+	var waiting []string
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.CurrentRevision < status.LatestAvailableRevision {
+			waiting = append(waiting, fmt.Sprintf("%s (revision %d)", nodeStatus.NodeName, nodeStatus.CurrentRevision))
+		}
+	}
+	if open || len(waiting) == 0 {
+		return operatorv1.OperatorCondition{Type: PendingWindowConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+	}
+	sort.Strings(waiting)
+	return operatorv1.OperatorCondition{
+		Type:   PendingWindowConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "OutsideMaintenanceWindow",
+		Message: fmt.Sprintf("revision %d waits for the next maintenance window at %s to be installed on %s",
+			status.LatestAvailableRevision, next.Format(time.RFC3339), strings.Join(waiting, ", ")),
+	}
+}
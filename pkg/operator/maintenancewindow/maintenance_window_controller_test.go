@@ -0,0 +1,62 @@
+package maintenancewindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestNewPendingWindowCondition(t *testing.T) {
+	next := time.Date(2026, 10, 12, 22, 0, 0, 0, time.UTC)
+	rollingOut := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-2", CurrentRevision: 4},
+			{NodeName: "master-0", CurrentRevision: 5},
+			{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5},
+		},
+	}
+	rolledOut := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses:            []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}},
+	}
+
+	tests := []struct {
+		name     string
+		open     bool
+		status   *operatorv1.StaticPodOperatorStatus
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name:     "open window",
+			open:     true,
+			status:   rollingOut,
+			expected: operatorv1.OperatorCondition{Type: PendingWindowConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:     "closed window while rolled out",
+			status:   rolledOut,
+			expected: operatorv1.OperatorCondition{Type: PendingWindowConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:   "closed window during a rollout",
+			status: rollingOut,
+			expected: operatorv1.OperatorCondition{
+				Type:    PendingWindowConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "OutsideMaintenanceWindow",
+				Message: "revision 5 waits for the next maintenance window at 2026-10-12T22:00:00Z to be installed on master-1 (revision 4), master-2 (revision 4)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expected, newPendingWindowCondition(test.open, next, test.status)); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package maintenancewindow
+
+import (
+	"time"
+
+	"github.com/robfig/cron"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// isOpen returns whether now is within one of the windows and, if it is not, when the next window opens.
+func isOpen(windows []operatorconfig.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	now = now.UTC()
+	var next time.Time
+	for _, window := range windows {
+		schedule, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		duration, err := time.ParseDuration(window.Duration)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		// the first start after now-duration is the start of the window now is in, if it did start already
+		if start := schedule.Next(now.Add(-duration)); !start.After(now) {
+			return true, time.Time{}, nil
+		}
+		if start := schedule.Next(now); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return false, next, nil
+}
@@ -0,0 +1,53 @@
+package maintenancewindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestIsOpen(t *testing.T) {
+	// weekday evenings from 22:00 to 02:00 and saturdays
+	windows := []operatorconfig.MaintenanceWindow{
+		{Schedule: "0 22 * * 1-5", Duration: "4h"},
+		{Schedule: "0 0 * * 6", Duration: "24h"},
+	}
+
+	tests := []struct {
+		name         string
+		now          string
+		expectedOpen bool
+		expectedNext string
+	}{
+		{name: "monday noon", now: "2026-10-12T12:00:00Z", expectedNext: "2026-10-12T22:00:00Z"},
+		{name: "start of a window", now: "2026-10-12T22:00:00Z", expectedOpen: true},
+		{name: "window across midnight", now: "2026-10-13T01:30:00Z", expectedOpen: true},
+		{name: "end of a window", now: "2026-10-13T02:00:00Z", expectedNext: "2026-10-13T22:00:00Z"},
+		{name: "saturday", now: "2026-10-17T15:00:00Z", expectedOpen: true},
+		{name: "sunday", now: "2026-10-18T15:00:00Z", expectedNext: "2026-10-19T22:00:00Z"},
+		{name: "other time zone", now: "2026-10-12T23:30:00+02:00", expectedNext: "2026-10-12T22:00:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, test.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			open, next, err := isOpen(windows, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if open != test.expectedOpen {
+				t.Errorf("expected open %v, got %v", test.expectedOpen, open)
+			}
+			if open {
+				return
+			}
+			if next.Format(time.RFC3339) != test.expectedNext {
+				t.Errorf("expected the next window at %s, got %s", test.expectedNext, next.Format(time.RFC3339))
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package namedcertificatecontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	SecretNotFoundReason        = "SecretNotFound"
+	InvalidSecretReason         = "InvalidSecret"
+	InvalidCertificateReason    = "InvalidCertificate"
+	CertificateExpiredReason    = "CertificateExpired"
+	KeyMismatchReason           = "KeyMismatch"
+	NamesNotCoveredReason       = "NamesNotCovered"
+	InternalAPIServerNameReason = "InternalAPIServerName"
+)
+
+// conditionTypePattern matches the conditions of this controller, one per entry of spec.servingCerts.namedCertificates.
+var conditionTypePattern = regexp.MustCompile(`^NamedCertificate[0-9]{3}Degraded$`)
+
+// NamedCertificateController validates the secrets referenced by spec.servingCerts.namedCertificates of
+// apiserver/cluster and reports every named certificate in its own NamedCertificate<index>Degraded condition. The
+// index matches the user-serving-cert-<index> secret the certificate is synced to.
+type NamedCertificateController struct {
+	operatorClient       v1helpers.OperatorClient
+	apiServerLister      configv1listers.APIServerLister
+	infrastructureLister configv1listers.InfrastructureLister
+	secretLister         corev1listers.SecretLister
+
+	now func() time.Time
+}
+
+func NewNamedCertificateController(
+	operatorClient v1helpers.OperatorClient,
+	configInformers configv1informers.SharedInformerFactory,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &NamedCertificateController{
+		operatorClient:       operatorClient,
+		apiServerLister:      configInformers.Config().V1().APIServers().Lister(),
+		infrastructureLister: configInformers.Config().V1().Infrastructures().Lister(),
+		secretLister:         kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Lister(),
+		now:                  time.Now,
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		configInformers.Config().V1().APIServers().Informer(),
+		configInformers.Config().V1().Infrastructures().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+	).WithSync(syncmetrics.Instrument("NamedCertificateController", c.sync)).ResyncEvery(resyncinterval.For("NamedCertificateController", time.Hour)).ToController("NamedCertificateController", eventRecorder.WithComponentSuffix("named-certificate-controller"))
+}
+
+func (c *NamedCertificateController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	var namedCertificates []configv1.APIServerNamedServingCert
+	apiServer, err := c.apiServerLister.Get("cluster")
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		namedCertificates = apiServer.Spec.ServingCerts.NamedCertificates
+	}
+
+	var internalAPIServerHost string
+	infrastructure, err := c.infrastructureLister.Get("cluster")
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		if internalURL, err := url.Parse(infrastructure.Status.APIServerInternalURL); err == nil {
+			internalAPIServerHost = internalURL.Hostname()
+		}
+	}
+
+	var updateFuncs []v1helpers.UpdateStatusFunc
+	conditionTypes := map[string]bool{}
+	for index, namedCertificate := range namedCertificates {
+		cond := newNamedCertificateCondition(index, namedCertificate, c.secretLister, internalAPIServerHost, c.now())
+		conditionTypes[cond.Type] = true
+		updateFuncs = append(updateFuncs, v1helpers.UpdateConditionFn(cond))
+	}
+	// the conditions of removed named certificates are dropped
+	updateFuncs = append(updateFuncs, func(status *operatorv1.OperatorStatus) error {
+		for _, cond := range append([]operatorv1.OperatorCondition(nil), status.Conditions...) {
+			if conditionTypePattern.MatchString(cond.Type) && !conditionTypes[cond.Type] {
+				v1helpers.RemoveOperatorCondition(&status.Conditions, cond.Type)
+			}
+		}
+		return nil
+	})
+
+	_, _, err = v1helpers.UpdateStatus(c.operatorClient, updateFuncs...)
+	return err
+}
+
+// newNamedCertificateCondition validates the secret of a named certificate. The certificate has to parse, has to be
+// valid now, has to match the key and has to cover every name it is served for. Wildcard names like "*.example.com"
+// are only covered by the same wildcard SAN. Without names the certificate is served for its own SANs. None of the
+// names may take over the internal API server name, the kubelets only trust the operator managed certificate there.
+func newNamedCertificateCondition(index int, namedCertificate configv1.APIServerNamedServingCert, secretLister corev1listers.SecretLister, internalAPIServerHost string, now time.Time) operatorv1.OperatorCondition {
+	secretName := namedCertificate.ServingCertificate.Name
+	degraded := func(reason, messageFormat string, args ...interface{}) operatorv1.OperatorCondition {
+		return operatorv1.OperatorCondition{
+			Type:    conditionType(index),
+			Status:  operatorv1.ConditionTrue,
+			Reason:  reason,
+			Message: fmt.Sprintf("secret %s/%s: ", operatorclient.GlobalUserSpecifiedConfigNamespace, secretName) + fmt.Sprintf(messageFormat, args...),
+		}
+	}
+
+	secret, err := secretLister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(secretName)
+	if apierrors.IsNotFound(err) {
+		return degraded(SecretNotFoundReason, "not found")
+	}
+	if err != nil {
+		return degraded(SecretNotFoundReason, "%v", err)
+	}
+	certPEM, keyPEM := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return degraded(InvalidSecretReason, "%s and %s are required", corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	certs, err := crypto.CertsFromPEM(certPEM)
+	if err != nil {
+		return degraded(InvalidCertificateReason, "%v", err)
+	}
+	leaf := certs[0]
+	if now.After(leaf.NotAfter) {
+		return degraded(CertificateExpiredReason, "the certificate expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(leaf.NotBefore) {
+		return degraded(InvalidCertificateReason, "the certificate is not valid before %s", leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return degraded(KeyMismatchReason, "%v", err)
+	}
+
+	names := namedCertificate.Names
+	if len(names) == 0 {
+		names = append(append([]string(nil), leaf.DNSNames...), ipNames(leaf)...)
+	}
+	var uncovered []string
+	for _, name := range namedCertificate.Names {
+		if !covers(leaf, name) {
+			uncovered = append(uncovered, name)
+		}
+	}
+	if len(uncovered) > 0 {
+		return degraded(NamesNotCoveredReason, "the certificate does not cover %v, it covers %v", uncovered, append(append([]string(nil), leaf.DNSNames...), ipNames(leaf)...))
+	}
+	for _, name := range names {
+		if matches(name, internalAPIServerHost) {
+			return degraded(InternalAPIServerNameReason, "%s takes over the internal API server name %s", name, internalAPIServerHost)
+		}
+	}
+
+	var wildcards []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "*.") {
+			wildcards = append(wildcards, name)
+		}
+	}
+	message := fmt.Sprintf("secret %s/%s is served for %v", operatorclient.GlobalUserSpecifiedConfigNamespace, secretName, names)
+	if len(namedCertificate.Names) == 0 {
+		message += ", the names of the certificate"
+	}
+	if len(wildcards) > 0 {
+		message += fmt.Sprintf(", including every subdomain of %v", wildcards)
+	}
+	return operatorv1.OperatorCondition{
+		Type:    conditionType(index),
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: message,
+	}
+}
+
+func conditionType(index int) string {
+	return fmt.Sprintf("NamedCertificate%03dDegraded", index)
+}
+
+// covers returns true if the certificate is valid for the name. A wildcard name needs the same wildcard SAN, a wildcard
+// SAN of the certificate covers the names of its subdomains.
+func covers(cert *x509.Certificate, name string) bool {
+	if strings.HasPrefix(name, "*.") {
+		for _, dnsName := range cert.DNSNames {
+			if strings.EqualFold(dnsName, name) {
+				return true
+			}
+		}
+		return false
+	}
+	return cert.VerifyHostname(name) == nil
+}
+
+// matches returns true if the served name, which may be a wildcard, is used for the host.
+func matches(name, host string) bool {
+	if len(host) == 0 {
+		return false
+	}
+	if strings.HasPrefix(name, "*.") {
+		parts := strings.SplitN(host, ".", 2)
+		return len(parts) == 2 && strings.EqualFold(name[2:], parts[1])
+	}
+	return strings.EqualFold(name, host)
+}
+
+func ipNames(cert *x509.Certificate) []string {
+	var names []string
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
+}
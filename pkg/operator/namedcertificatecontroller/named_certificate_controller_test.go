@@ -0,0 +1,192 @@
+package namedcertificatecontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewNamedCertificateCondition(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	certPEM, keyPEM := newCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), "api.example.com", "*.apps.example.com")
+	expiredCertPEM, expiredKeyPEM := newCertificate(t, now.Add(-2*time.Hour), now.Add(-time.Hour), "api.example.com")
+	_, otherKeyPEM := newCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), "api.example.com")
+	internalCertPEM, internalKeyPEM := newCertificate(t, now.Add(-time.Hour), now.Add(time.Hour), "*.cluster.example.com")
+
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for name, data := range map[string][2][]byte{
+		"valid":    {certPEM, keyPEM},
+		"expired":  {expiredCertPEM, expiredKeyPEM},
+		"mismatch": {certPEM, otherKeyPEM},
+		"garbage":  {[]byte("FOO"), []byte("BAR")},
+		"no-key":   {certPEM, nil},
+		"internal": {internalCertPEM, internalKeyPEM},
+	} {
+		if err := secrets.Add(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: name},
+			Data:       map[string][]byte{"tls.crt": data[0], "tls.key": data[1]},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secretLister := corev1listers.NewSecretLister(secrets)
+
+	namedCertificate := func(secret string, names ...string) configv1.APIServerNamedServingCert {
+		return configv1.APIServerNamedServingCert{Names: names, ServingCertificate: configv1.SecretNameReference{Name: secret}}
+	}
+
+	tests := []struct {
+		name             string
+		namedCertificate configv1.APIServerNamedServingCert
+		expected         operatorv1.OperatorCondition
+	}{
+		{
+			name:             "valid with names",
+			namedCertificate: namedCertificate("valid", "api.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "AsExpected",
+				Message: "secret openshift-config/valid is served for [api.example.com]",
+			},
+		},
+		{
+			name:             "valid wildcard",
+			namedCertificate: namedCertificate("valid", "*.apps.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "AsExpected",
+				Message: "secret openshift-config/valid is served for [*.apps.example.com], including every subdomain of [*.apps.example.com]",
+			},
+		},
+		{
+			name:             "names of the certificate",
+			namedCertificate: namedCertificate("valid"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "AsExpected",
+				Message: "secret openshift-config/valid is served for [api.example.com *.apps.example.com], the names of the certificate, including every subdomain of [*.apps.example.com]",
+			},
+		},
+		{
+			name:             "subdomain covered by a wildcard SAN",
+			namedCertificate: namedCertificate("valid", "console.apps.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "AsExpected",
+				Message: "secret openshift-config/valid is served for [console.apps.example.com]",
+			},
+		},
+		{
+			name:             "wildcard name without a wildcard SAN",
+			namedCertificate: namedCertificate("valid", "*.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  NamesNotCoveredReason,
+				Message: "secret openshift-config/valid: the certificate does not cover [*.example.com], it covers [api.example.com *.apps.example.com]",
+			},
+		},
+		{
+			name:             "missing secret",
+			namedCertificate: namedCertificate("missing", "api.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  SecretNotFoundReason,
+				Message: "secret openshift-config/missing: not found",
+			},
+		},
+		{
+			name:             "missing key",
+			namedCertificate: namedCertificate("no-key", "api.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  InvalidSecretReason,
+				Message: "secret openshift-config/no-key: tls.crt and tls.key are required",
+			},
+		},
+		{
+			name:             "expired",
+			namedCertificate: namedCertificate("expired", "api.example.com"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  CertificateExpiredReason,
+				Message: "secret openshift-config/expired: the certificate expired at 2021-05-31T23:00:00Z",
+			},
+		},
+		{
+			name:             "internal API server name",
+			namedCertificate: namedCertificate("internal"),
+			expected: operatorv1.OperatorCondition{
+				Type:    "NamedCertificate002Degraded",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  InternalAPIServerNameReason,
+				Message: "secret openshift-config/internal: *.cluster.example.com takes over the internal API server name api-int.cluster.example.com",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := newNamedCertificateCondition(2, test.namedCertificate, secretLister, "api-int.cluster.example.com", now)
+			if !cmp.Equal(test.expected, actual) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(test.expected, actual))
+			}
+		})
+	}
+
+	for _, secret := range []string{"mismatch", "garbage"} {
+		t.Run(secret, func(t *testing.T) {
+			actual := newNamedCertificateCondition(2, namedCertificate(secret, "api.example.com"), secretLister, "api-int.cluster.example.com", now)
+			expectedReason := map[string]string{"mismatch": KeyMismatchReason, "garbage": InvalidCertificateReason}[secret]
+			if actual.Status != operatorv1.ConditionTrue || actual.Reason != expectedReason {
+				t.Fatalf("expected %s, got %v", expectedReason, actual)
+			}
+		})
+	}
+}
+
+func newCertificate(t *testing.T, notBefore, notAfter time.Time, dnsNames ...string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,39 @@
+package nodeexclusion
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// WaitWhileExcluded returns an installer pod mutation that holds back the installer pods of excluded nodes. An init
+// container waits until the rollout-node-exclusions configmap tells the exclusion of the node is removed or expired.
+// The installer controller installs one node at a time and keeps waiting for the pending installer pod meanwhile.
+func WaitWhileExcluded() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		for _, exclusion := range rollout.ExcludedNodes {
+			if exclusion.NodeName == nodeName {
+				addWaitWhileExcludedContainer(pod, nodeName)
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+func addWaitWhileExcludedContainer(pod *corev1.Pod, nodeName string) {
+	installergate.AddWaitContainer(pod, "wait-while-excluded",
+		fmt.Sprintf("--namespace=%s", operatorclient.TargetNamespace),
+		fmt.Sprintf("--configmap=%s", ConfigMapName),
+		fmt.Sprintf("--node=%s", nodeName),
+	)
+}
@@ -0,0 +1,190 @@
+package nodeexclusion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace that records since when and until when nodes are
+	// excluded from rollouts.
+	ConfigMapName = "rollout-node-exclusions"
+	ExclusionsKey = "exclusions"
+
+	RolloutNodeExcludedConditionType            = "RolloutNodeExcluded"
+	RolloutNodeExcludedUpgradeableConditionType = "RolloutNodeExcludedUpgradeable"
+
+	defaultExclusionDuration = 24 * time.Hour
+)
+
+// NodeExclusionController records when the excluded nodes of the rollout in the operator config were first observed in
+// the rollout-node-exclusions configmap, which the installer pods of the excluded nodes wait for. An exclusion expires
+// after its duration, the RolloutNodeExcluded condition warns about active and expired exclusions. Upgrades are not
+// allowed while a node is excluded because they would not be rolled out to it.
+type NodeExclusionController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+
+	now func() time.Time
+}
+
+func NewNodeExclusionController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &NodeExclusionController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		now:             time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("NodeExclusionController", c.sync)).ResyncEvery(resyncinterval.For("NodeExclusionController", time.Minute)).ToController("NodeExclusionController", eventRecorder.WithComponentSuffix("node-exclusion-controller"))
+}
+
+func (c *NodeExclusionController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]Record{}
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(ConfigMapName)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		if existing, err = ParseRecords(configMap.Data[ExclusionsKey]); err != nil {
+			// the records are rebuilt, the exclusions start over
+			syncCtx.Recorder().Warningf("NodeExclusionsReset", "failed to decode configmap %s/%s: %v", operatorclient.TargetNamespace, ConfigMapName, err)
+			existing = map[string]Record{}
+		}
+	}
+
+	records, err := newRecords(rollout.ExcludedNodes, existing, c.now())
+	if err != nil {
+		return err
+	}
+	for _, exclusion := range rollout.ExcludedNodes {
+		if _, ok := existing[exclusion.NodeName]; !ok {
+			syncCtx.Recorder().Warningf("NodeExcluded", "node %s is excluded from rollouts until %s", exclusion.NodeName, records[exclusion.NodeName].Until.Format(time.RFC3339))
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       map[string]string{ExclusionsKey: string(data)},
+	}); err != nil {
+		return err
+	}
+
+	excluded, upgradeable := newExcludedConditions(rollout.ExcludedNodes, records, status, c.now())
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(excluded), v1helpers.UpdateConditionFn(upgradeable)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newRecords keeps the start of the exclusions that are still configured and starts the new ones now. The end follows
+// the current duration, a changed duration applies from the original start.
+func newRecords(exclusions []operatorconfig.NodeExclusion, existing map[string]Record, now time.Time) (map[string]Record, error) {
+	records := map[string]Record{}
+	for _, exclusion := range exclusions {
+		duration := defaultExclusionDuration
+		if len(exclusion.Duration) > 0 {
+			var err error
+			if duration, err = time.ParseDuration(exclusion.Duration); err != nil {
+				return nil, err
+			}
+		}
+		since := now.UTC().Truncate(time.Second)
+		if record, ok := existing[exclusion.NodeName]; ok {
+			since = record.Since
+		}
+		records[exclusion.NodeName] = Record{Since: since, Until: since.Add(duration)}
+	}
+	return records, nil
+}
+
+func newExcludedConditions(exclusions []operatorconfig.NodeExclusion, records map[string]Record, status *operatorv1.StaticPodOperatorStatus, now time.Time) (operatorv1.OperatorCondition, operatorv1.OperatorCondition) {
+	revisions := map[string]int32{}
+	for _, nodeStatus := range status.NodeStatuses {
+		revisions[nodeStatus.NodeName] = nodeStatus.CurrentRevision
+	}
+
+	var active, expired []string
+	for _, exclusion := range exclusions {
+		record := records[exclusion.NodeName]
+		if !record.Active(now) {
+			expired = append(expired, fmt.Sprintf("the exclusion of node %q expired at %s, remove it from rollout.excludedNodes", exclusion.NodeName, record.Until.Format(time.RFC3339)))
+			continue
+		}
+		line := fmt.Sprintf("node %q is excluded from rollouts until %s", exclusion.NodeName, record.Until.Format(time.RFC3339))
+		if revision, ok := revisions[exclusion.NodeName]; ok && revision < status.LatestAvailableRevision {
+			line = fmt.Sprintf("%s, it runs revision %d of %d", line, revision, status.LatestAvailableRevision)
+		}
+		if len(exclusion.Reason) > 0 {
+			line = fmt.Sprintf("%s: %s", line, exclusion.Reason)
+		}
+		active = append(active, line)
+	}
+
+	upgradeable := operatorv1.OperatorCondition{Type: RolloutNodeExcludedUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: "AsExpected"}
+	switch {
+	case len(active) > 0:
+		upgradeable = operatorv1.OperatorCondition{
+			Type:    RolloutNodeExcludedUpgradeableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NodeExcluded",
+			Message: "nodes are excluded from rollouts, an upgrade would not be rolled out to them",
+		}
+		return operatorv1.OperatorCondition{
+			Type:    RolloutNodeExcludedConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NodeExcluded",
+			Message: strings.Join(append(active, expired...), "\n"),
+		}, upgradeable
+	case len(expired) > 0:
+		return operatorv1.OperatorCondition{
+			Type:    RolloutNodeExcludedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "ExclusionExpired",
+			Message: strings.Join(expired, "\n"),
+		}, upgradeable
+	default:
+		return operatorv1.OperatorCondition{Type: RolloutNodeExcludedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}, upgradeable
+	}
+}
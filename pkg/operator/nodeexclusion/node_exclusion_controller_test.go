@@ -0,0 +1,112 @@
+package nodeexclusion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestNewRecords(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour)
+
+	records, err := newRecords([]operatorconfig.NodeExclusion{
+		{NodeName: "master-0", Duration: "4h"},
+		{NodeName: "master-1"},
+	}, map[string]Record{
+		"master-0": {Since: since, Until: since.Add(2 * time.Hour)},
+		"master-2": {Since: since, Until: since.Add(2 * time.Hour)},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Record{
+		// the changed duration applies from the original start
+		"master-0": {Since: since, Until: since.Add(4 * time.Hour)},
+		"master-1": {Since: now, Until: now.Add(24 * time.Hour)},
+	}
+	if diff := cmp.Diff(expected, records); diff != "" {
+		t.Errorf("unexpected records (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewExcludedConditions(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	status := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 4},
+			{NodeName: "master-1", CurrentRevision: 5},
+		},
+	}
+	active := Record{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}
+	expired := Record{Since: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour)}
+	notUpgradeable := operatorv1.OperatorCondition{
+		Type:    RolloutNodeExcludedUpgradeableConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "NodeExcluded",
+		Message: "nodes are excluded from rollouts, an upgrade would not be rolled out to them",
+	}
+	upgradeable := operatorv1.OperatorCondition{Type: RolloutNodeExcludedUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: "AsExpected"}
+
+	tests := []struct {
+		name                string
+		exclusions          []operatorconfig.NodeExclusion
+		records             map[string]Record
+		expectedExcluded    operatorv1.OperatorCondition
+		expectedUpgradeable operatorv1.OperatorCondition
+	}{
+		{
+			name:                "no exclusions",
+			expectedExcluded:    operatorv1.OperatorCondition{Type: RolloutNodeExcludedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+			expectedUpgradeable: upgradeable,
+		},
+		{
+			name: "active and expired exclusions",
+			exclusions: []operatorconfig.NodeExclusion{
+				{NodeName: "master-0", Reason: "hardware repair"},
+				{NodeName: "master-1"},
+				{NodeName: "master-2"},
+			},
+			records: map[string]Record{"master-0": active, "master-1": active, "master-2": expired},
+			expectedExcluded: operatorv1.OperatorCondition{
+				Type:   RolloutNodeExcludedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: "NodeExcluded",
+				Message: `node "master-0" is excluded from rollouts until 2021-06-01T13:00:00Z, it runs revision 4 of 5: hardware repair
+node "master-1" is excluded from rollouts until 2021-06-01T13:00:00Z
+the exclusion of node "master-2" expired at 2021-06-01T11:00:00Z, remove it from rollout.excludedNodes`,
+			},
+			expectedUpgradeable: notUpgradeable,
+		},
+		{
+			name:       "expired exclusion",
+			exclusions: []operatorconfig.NodeExclusion{{NodeName: "master-0"}},
+			records:    map[string]Record{"master-0": expired},
+			expectedExcluded: operatorv1.OperatorCondition{
+				Type:    RolloutNodeExcludedConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "ExclusionExpired",
+				Message: `the exclusion of node "master-0" expired at 2021-06-01T11:00:00Z, remove it from rollout.excludedNodes`,
+			},
+			expectedUpgradeable: upgradeable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			excluded, upgradeable := newExcludedConditions(test.exclusions, test.records, status, now)
+			if diff := cmp.Diff(test.expectedExcluded, excluded); diff != "" {
+				t.Errorf("unexpected excluded condition (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectedUpgradeable, upgradeable); diff != "" {
+				t.Errorf("unexpected upgradeable condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
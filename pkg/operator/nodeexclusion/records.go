@@ -0,0 +1,29 @@
+package nodeexclusion
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Record is when the exclusion of a node was first observed and when it expires.
+type Record struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// Active returns whether the exclusion is not expired yet.
+func (r Record) Active(now time.Time) bool {
+	return now.Before(r.Until)
+}
+
+// ParseRecords decodes the records of the node exclusions configmap, keyed by node name.
+func ParseRecords(data string) (map[string]Record, error) {
+	records := map[string]Record{}
+	if len(data) == 0 {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
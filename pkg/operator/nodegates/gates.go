@@ -0,0 +1,86 @@
+package nodegates
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// Unmet returns the gates the node does not satisfy. A condition gate requires the condition to be True, an
+// annotation gate requires the annotation to be set and to have the value of the gate, if any.
+func Unmet(node *corev1.Node, gates []operatorconfig.NodeGate) []operatorconfig.NodeGate {
+	var unmet []operatorconfig.NodeGate
+	for _, gate := range gates {
+		if !met(node, gate) {
+			unmet = append(unmet, gate)
+		}
+	}
+	return unmet
+}
+
+func met(node *corev1.Node, gate operatorconfig.NodeGate) bool {
+	if len(gate.ConditionType) > 0 {
+		for _, condition := range node.Status.Conditions {
+			if string(condition.Type) == gate.ConditionType {
+				return condition.Status == corev1.ConditionTrue
+			}
+		}
+		return false
+	}
+	value, ok := node.Annotations[gate.Annotation]
+	if !ok {
+		return false
+	}
+	return len(gate.Value) == 0 || value == gate.Value
+}
+
+// Describe returns a human readable list of the gates.
+func Describe(gates []operatorconfig.NodeGate) string {
+	var descriptions []string
+	for _, gate := range gates {
+		switch {
+		case len(gate.ConditionType) > 0:
+			descriptions = append(descriptions, fmt.Sprintf("condition %s=True", gate.ConditionType))
+		case len(gate.Value) > 0:
+			descriptions = append(descriptions, fmt.Sprintf("annotation %s=%s", gate.Annotation, gate.Value))
+		default:
+			descriptions = append(descriptions, fmt.Sprintf("annotation %s", gate.Annotation))
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// ToArgs returns the --condition and --annotation flags of the wait-for-node-gates command for the gates.
+func ToArgs(gates []operatorconfig.NodeGate) []string {
+	var args []string
+	for _, gate := range gates {
+		switch {
+		case len(gate.ConditionType) > 0:
+			args = append(args, fmt.Sprintf("--condition=%s", gate.ConditionType))
+		case len(gate.Value) > 0:
+			args = append(args, fmt.Sprintf("--annotation=%s=%s", gate.Annotation, gate.Value))
+		default:
+			args = append(args, fmt.Sprintf("--annotation=%s", gate.Annotation))
+		}
+	}
+	return args
+}
+
+// FromFlags returns the gates of the values of the --condition and --annotation flags.
+func FromFlags(conditions, annotations []string) []operatorconfig.NodeGate {
+	var gates []operatorconfig.NodeGate
+	for _, condition := range conditions {
+		gates = append(gates, operatorconfig.NodeGate{ConditionType: condition})
+	}
+	for _, annotation := range annotations {
+		gate := operatorconfig.NodeGate{Annotation: annotation}
+		if parts := strings.SplitN(annotation, "=", 2); len(parts) == 2 {
+			gate.Annotation, gate.Value = parts[0], parts[1]
+		}
+		gates = append(gates, gate)
+	}
+	return gates
+}
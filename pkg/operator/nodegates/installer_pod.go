@@ -0,0 +1,32 @@
+package nodegates
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+)
+
+// WaitForNodeGates returns an installer pod mutation that holds back the installation of a revision on a node until
+// the node satisfies the node gates of the rollout config. An init container waits for the conditions and annotations
+// of the node, the installer controller keeps waiting for the pending installer pod meanwhile.
+func WaitForNodeGates() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if len(rollout.NodeGates) == 0 {
+			return nil
+		}
+		addWaitForNodeGatesContainer(pod, append([]string{fmt.Sprintf("--node=%s", nodeName)}, ToArgs(rollout.NodeGates)...))
+		return nil
+	}
+}
+
+func addWaitForNodeGatesContainer(pod *corev1.Pod, args []string) {
+	installergate.AddWaitContainer(pod, "wait-for-node-gates", args...)
+}
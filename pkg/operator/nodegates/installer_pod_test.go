@@ -0,0 +1,63 @@
+package nodegates
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitForNodeGates(t *testing.T) {
+	tests := []struct {
+		name           string
+		observedConfig string
+		expectedArgs   []string
+	}{
+		{name: "no rollout config"},
+		{name: "no node gates", observedConfig: `{"rollout":{"strategy":"Canary"}}`},
+		{
+			name:           "node gates",
+			observedConfig: `{"rollout":{"nodeGates":[{"conditionType":"MaintenanceReady"},{"annotation":"example.com/drained"},{"annotation":"example.com/etcd-learner-promoted","value":"true"}]}}`,
+			expectedArgs:   []string{"--node=master-0", "--condition=MaintenanceReady", "--annotation=example.com/drained", "--annotation=example.com/etcd-learner-promoted=true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:         "installer",
+				Image:        "quay.io/openshift/cluster-kube-apiserver-operator",
+				VolumeMounts: []corev1.VolumeMount{{Name: "kubelet-dir"}, {Name: "kube-api-access", MountPath: "/var/run/secrets/kubernetes.io/serviceaccount"}},
+			}}}}
+
+			if err := WaitForNodeGates()(pod, "master-0", operatorSpec, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.expectedArgs == nil {
+				if len(pod.Spec.InitContainers) > 0 {
+					t.Fatalf("expected the installer not to wait, got %v", pod.Spec.InitContainers)
+				}
+				return
+			}
+			if len(pod.Spec.InitContainers) != 1 {
+				t.Fatalf("expected one init container, got %v", pod.Spec.InitContainers)
+			}
+			container := pod.Spec.InitContainers[0]
+			if container.Image != pod.Spec.Containers[0].Image || container.Command[1] != "wait-for-node-gates" {
+				t.Errorf("unexpected init container %v", container)
+			}
+			if !reflect.DeepEqual(container.Args, test.expectedArgs) {
+				t.Errorf("expected args %v, got %v", test.expectedArgs, container.Args)
+			}
+			if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "kube-api-access" {
+				t.Errorf("expected only the service account token to be mounted, got %v", container.VolumeMounts)
+			}
+		})
+	}
+}
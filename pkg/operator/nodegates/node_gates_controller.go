@@ -0,0 +1,100 @@
+package nodegates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	RolloutNodeGatesConditionType = "RolloutNodeGates"
+
+	WaitingForNodeGatesReason = "WaitingForNodeGates"
+)
+
+// NodeGatesController reports the nodes that wait for the latest revision but do not satisfy the node gates of the
+// rollout config yet in the RolloutNodeGates condition, together with the gates they miss.
+type NodeGatesController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	nodeLister     corev1listers.NodeLister
+}
+
+func NewNodeGatesController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &NodeGatesController{
+		operatorClient: operatorClient,
+		nodeLister:     kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Lister(),
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Informer(),
+	).WithSync(syncmetrics.Instrument("NodeGatesController", c.sync)).ToController("NodeGatesController", eventRecorder.WithComponentSuffix("node-gates-controller"))
+}
+
+func (c *NodeGatesController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	cond, err := c.newNodeGatesCondition(rollout.NodeGates, status)
+	if err != nil {
+		return err
+	}
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *NodeGatesController) newNodeGatesCondition(gates []operatorconfig.NodeGate, status *operatorv1.StaticPodOperatorStatus) (operatorv1.OperatorCondition, error) {
+	var waiting []string
+	for _, nodeStatus := range status.NodeStatuses {
+		if len(gates) == 0 || nodeStatus.CurrentRevision >= status.LatestAvailableRevision {
+			continue
+		}
+		node, err := c.nodeLister.Get(nodeStatus.NodeName)
+		if apierrors.IsNotFound(err) {
+			node = &corev1.Node{}
+		} else if err != nil {
+			return operatorv1.OperatorCondition{}, err
+		}
+		if unmet := Unmet(node, gates); len(unmet) > 0 {
+			waiting = append(waiting, fmt.Sprintf("node %q waits for %s", nodeStatus.NodeName, Describe(unmet)))
+		}
+	}
+	if len(waiting) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   RolloutNodeGatesConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}, nil
+	}
+	sort.Strings(waiting)
+	return operatorv1.OperatorCondition{
+		Type:    RolloutNodeGatesConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  WaitingForNodeGatesReason,
+		Message: fmt.Sprintf("revision %d is not installed before the node gates are met:\n%s", status.LatestAvailableRevision, strings.Join(waiting, "\n")),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package nodegates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestNewNodeGatesCondition(t *testing.T) {
+	gates := []operatorconfig.NodeGate{
+		{ConditionType: "MaintenanceReady"},
+		{Annotation: "example.com/etcd-learner-promoted", Value: "true"},
+	}
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-0"},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "MaintenanceReady", Status: corev1.ConditionFalse}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-1", Annotations: map[string]string{"example.com/etcd-learner-promoted": "true"}},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "MaintenanceReady", Status: corev1.ConditionTrue}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-2", Annotations: map[string]string{"example.com/etcd-learner-promoted": "false"}},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "MaintenanceReady", Status: corev1.ConditionTrue}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		gates    []operatorconfig.NodeGate
+		status   *operatorv1.StaticPodOperatorStatus
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name: "no gates",
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 4},
+			}},
+			expected: operatorv1.OperatorCondition{Type: RolloutNodeGatesConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:  "rolled out",
+			gates: gates,
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 5},
+			}},
+			expected: operatorv1.OperatorCondition{Type: RolloutNodeGatesConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:  "waiting nodes",
+			gates: gates,
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 4},
+				{NodeName: "master-1", CurrentRevision: 4},
+				{NodeName: "master-2", CurrentRevision: 4},
+				{NodeName: "master-3", CurrentRevision: 4},
+			}},
+			expected: operatorv1.OperatorCondition{
+				Type:   RolloutNodeGatesConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: WaitingForNodeGatesReason,
+				Message: "revision 5 is not installed before the node gates are met:\n" +
+					`node "master-0" waits for condition MaintenanceReady=True, annotation example.com/etcd-learner-promoted=true` + "\n" +
+					`node "master-2" waits for annotation example.com/etcd-learner-promoted=true` + "\n" +
+					`node "master-3" waits for condition MaintenanceReady=True, annotation example.com/etcd-learner-promoted=true`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, node := range nodes {
+				if err := indexer.Add(node); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &NodeGatesController{nodeLister: corev1listers.NewNodeLister(indexer)}
+
+			cond, err := c.newNodeGatesCondition(test.gates, test.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expected, cond); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromFlags(t *testing.T) {
+	gates := []operatorconfig.NodeGate{
+		{ConditionType: "MaintenanceReady"},
+		{Annotation: "example.com/drained"},
+		{Annotation: "example.com/etcd-learner-promoted", Value: "true"},
+	}
+	var conditions, annotations []string
+	for _, arg := range ToArgs(gates) {
+		if strings.HasPrefix(arg, "--condition=") {
+			conditions = append(conditions, strings.TrimPrefix(arg, "--condition="))
+			continue
+		}
+		annotations = append(annotations, strings.TrimPrefix(arg, "--annotation="))
+	}
+	if diff := cmp.Diff(gates, FromFlags(conditions, annotations)); diff != "" {
+		t.Errorf("unexpected gates (-want +got):\n%s", diff)
+	}
+}
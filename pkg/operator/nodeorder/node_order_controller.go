@@ -0,0 +1,141 @@
+package nodeorder
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coordinationv1listers "k8s.io/client-go/listers/coordination/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+// leaderLeases are the leases in kube-system of the control plane components that talk to the kube-apiserver of
+// their own node. Restarting that kube-apiserver makes the leader lose its lease.
+var leaderLeases = []string{"kube-controller-manager", "kube-scheduler"}
+
+// NodeOrderController orders the node statuses of the operator status by the node order of the rollout in the
+// operator config. The installer controller starts with nodes that are not ready or failed, and otherwise installs a
+// new revision on the first node with the oldest revision in the node statuses, so their order is the rollout order
+// of the healthy nodes. The node statuses are only reordered while no node is installing a revision.
+type NodeOrderController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	leaseLister    coordinationv1listers.LeaseLister
+}
+
+func NewNodeOrderController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &NodeOrderController{
+		operatorClient: operatorClient,
+		leaseLister:    kubeInformersForNamespaces.InformersFor("kube-system").Coordination().V1().Leases().Lister(),
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor("kube-system").Coordination().V1().Leases().Informer(),
+	).WithSync(syncmetrics.Instrument("NodeOrderController", c.sync)).ResyncEvery(resyncinterval.For("NodeOrderController", time.Minute)).ToController("NodeOrderController", eventRecorder.WithComponentSuffix("node-order-controller"))
+}
+
+func (c *NodeOrderController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+	if rollout.NodeOrder == nil || len(rollout.NodeOrder.Type) == 0 || rollout.NodeOrder.Type == operatorconfig.NodeOrderUnavailableFirst {
+		return nil
+	}
+
+	leaders := sets.NewString()
+	if rollout.NodeOrder.Type == operatorconfig.NodeOrderLeaderLast {
+		for _, name := range leaderLeases {
+			lease, err := c.leaseLister.Leases("kube-system").Get(name)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if lease.Spec.HolderIdentity != nil {
+				// the holder identity is <hostname>_<uuid>
+				leaders.Insert(strings.SplitN(*lease.Spec.HolderIdentity, "_", 2)[0])
+			}
+		}
+	}
+
+	if !reorder(status.DeepCopy(), *rollout.NodeOrder, leaders) {
+		return nil
+	}
+	var order []string
+	_, updated, err := v1helpers.UpdateStaticPodStatus(c.operatorClient, func(status *operatorv1.StaticPodOperatorStatus) error {
+		if reorder(status, *rollout.NodeOrder, leaders) {
+			order = nodeNames(status.NodeStatuses)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if updated && len(order) > 0 {
+		syncCtx.Recorder().Eventf("NodeOrderChanged", "new revisions are installed on the nodes in the order %s", strings.Join(order, ", "))
+	}
+	return nil
+}
+
+// reorder sorts the node statuses by the node order and returns whether their order changed. Nothing changes while a
+// node installs a revision, the installer controller continues with the node after it.
+func reorder(status *operatorv1.StaticPodOperatorStatus, order operatorconfig.NodeOrderConfig, leaders sets.String) bool {
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.TargetRevision != 0 {
+			return false
+		}
+	}
+	rank := map[string]int{}
+	for i, node := range order.Nodes {
+		rank[node] = i - len(order.Nodes)
+	}
+	less := func(a, b string) bool {
+		switch {
+		case rank[a] != rank[b]:
+			return rank[a] < rank[b]
+		case leaders.Has(a) != leaders.Has(b):
+			return leaders.Has(b)
+		}
+		return a < b
+	}
+
+	before := nodeNames(status.NodeStatuses)
+	sort.SliceStable(status.NodeStatuses, func(i, j int) bool {
+		return less(status.NodeStatuses[i].NodeName, status.NodeStatuses[j].NodeName)
+	})
+	after := nodeNames(status.NodeStatuses)
+	for i := range before {
+		if before[i] != after[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeNames(nodeStatuses []operatorv1.NodeStatus) []string {
+	var names []string
+	for _, nodeStatus := range nodeStatuses {
+		names = append(names, nodeStatus.NodeName)
+	}
+	return names
+}
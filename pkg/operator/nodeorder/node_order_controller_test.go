@@ -0,0 +1,73 @@
+package nodeorder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestReorder(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeStatuses  []operatorv1.NodeStatus
+		order         operatorconfig.NodeOrderConfig
+		leaders       sets.String
+		expectedOrder []string
+		expectChanged bool
+	}{
+		{
+			name:          "alphabetical",
+			nodeStatuses:  []operatorv1.NodeStatus{{NodeName: "master-2"}, {NodeName: "master-0"}, {NodeName: "master-1"}},
+			order:         operatorconfig.NodeOrderConfig{Type: operatorconfig.NodeOrderAlphabetical},
+			expectedOrder: []string{"master-0", "master-1", "master-2"},
+			expectChanged: true,
+		},
+		{
+			name:          "already ordered",
+			nodeStatuses:  []operatorv1.NodeStatus{{NodeName: "master-0"}, {NodeName: "master-1"}, {NodeName: "master-2"}},
+			order:         operatorconfig.NodeOrderConfig{Type: operatorconfig.NodeOrderAlphabetical},
+			expectedOrder: []string{"master-0", "master-1", "master-2"},
+		},
+		{
+			name:          "leader last",
+			nodeStatuses:  []operatorv1.NodeStatus{{NodeName: "master-0"}, {NodeName: "master-1"}, {NodeName: "master-2"}},
+			order:         operatorconfig.NodeOrderConfig{Type: operatorconfig.NodeOrderLeaderLast},
+			leaders:       sets.NewString("master-0"),
+			expectedOrder: []string{"master-1", "master-2", "master-0"},
+			expectChanged: true,
+		},
+		{
+			name:          "explicit with unlisted nodes",
+			nodeStatuses:  []operatorv1.NodeStatus{{NodeName: "master-0"}, {NodeName: "master-3"}, {NodeName: "master-1"}, {NodeName: "master-2"}},
+			order:         operatorconfig.NodeOrderConfig{Type: operatorconfig.NodeOrderExplicit, Nodes: []string{"master-2", "master-4", "master-0"}},
+			expectedOrder: []string{"master-2", "master-0", "master-1", "master-3"},
+			expectChanged: true,
+		},
+		{
+			name:          "installing",
+			nodeStatuses:  []operatorv1.NodeStatus{{NodeName: "master-2", TargetRevision: 5}, {NodeName: "master-0"}, {NodeName: "master-1"}},
+			order:         operatorconfig.NodeOrderConfig{Type: operatorconfig.NodeOrderAlphabetical},
+			expectedOrder: []string{"master-2", "master-0", "master-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := &operatorv1.StaticPodOperatorStatus{NodeStatuses: test.nodeStatuses}
+			if test.leaders == nil {
+				test.leaders = sets.NewString()
+			}
+			changed := reorder(status, test.order, test.leaders)
+			if changed != test.expectChanged {
+				t.Errorf("expected changed %v, got %v", test.expectChanged, changed)
+			}
+			if diff := cmp.Diff(test.expectedOrder, nodeNames(status.NodeStatuses)); len(diff) > 0 {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package oidcdiscoverycontroller
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// discoveryDocument is the OIDC discovery document of the issuer, with the fields the kube-apiserver serves at
+// /.well-known/openid-configuration.
+type discoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is an RSA or EC public key as described in RFC 7517.
+type jsonWebKey struct {
+	Use       string `json:"use"`
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// renderDocuments returns the discovery document and the JWKS of the issuer for the PEM encoded public keys. Every
+// public key the kube-apiserver verifies tokens with is in the JWKS, so tokens signed with a previous or the next key of
+// a rotation are verified by the consumers as well. The keys are sorted by key ID to keep the JWKS stable.
+func renderDocuments(issuer, jwksURI string, publicKeysPEM []string) (discovery, jwks []byte, err error) {
+	keySet := jsonWebKeySet{Keys: []jsonWebKey{}}
+	algorithms := sets.NewString()
+	seen := sets.NewString()
+	for _, publicKeyPEM := range publicKeysPEM {
+		publicKeys, err := keyutil.ParsePublicKeysPEM([]byte(publicKeyPEM))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, publicKey := range publicKeys {
+			key, err := newJSONWebKey(publicKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			if seen.Has(key.KeyID) {
+				continue
+			}
+			seen.Insert(key.KeyID)
+			algorithms.Insert(key.Algorithm)
+			keySet.Keys = append(keySet.Keys, key)
+		}
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, nil, fmt.Errorf("no public keys")
+	}
+	sort.Slice(keySet.Keys, func(i, j int) bool { return keySet.Keys[i].KeyID < keySet.Keys[j].KeyID })
+
+	if len(jwksURI) == 0 {
+		jwksURI = strings.TrimSuffix(issuer, "/") + "/keys.json"
+	}
+	discovery, err = json.MarshalIndent(discoveryDocument{
+		Issuer:                           issuer,
+		JWKSURI:                          jwksURI,
+		ResponseTypesSupported:           []string{"id_token"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: algorithms.List(),
+	}, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	jwks, err = json.MarshalIndent(keySet, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return discovery, jwks, nil
+}
+
+// newJSONWebKey returns the JWK of an RSA or EC public key. The key ID is derived from the key like the kube-apiserver
+// derives the kid header of the tokens it signs.
+func newJSONWebKey(publicKey interface{}) (jsonWebKey, error) {
+	keyID, err := keyIDFromPublicKey(publicKey)
+	if err != nil {
+		return jsonWebKey{}, err
+	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jsonWebKey{
+			Use:       "sig",
+			KeyType:   "RSA",
+			KeyID:     keyID,
+			Algorithm: "RS256",
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		var algorithm string
+		switch key.Curve {
+		case elliptic.P256():
+			algorithm = "ES256"
+		case elliptic.P384():
+			algorithm = "ES384"
+		case elliptic.P521():
+			algorithm = "ES512"
+		default:
+			return jsonWebKey{}, fmt.Errorf("unsupported elliptic curve %s", key.Curve.Params().Name)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return jsonWebKey{
+			Use:       "sig",
+			KeyType:   "EC",
+			KeyID:     keyID,
+			Algorithm: algorithm,
+			Curve:     key.Curve.Params().Name,
+			X:         base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:         base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return jsonWebKey{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// keyIDFromPublicKey is the unpadded base64url encoded SHA-256 of the PKIX DER encoding of the public key.
+func keyIDFromPublicKey(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize the public key: %v", err)
+	}
+	hash := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
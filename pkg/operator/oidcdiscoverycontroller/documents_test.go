@@ -0,0 +1,109 @@
+package oidcdiscoverycontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func publicKeyPEM(t *testing.T, publicKey interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestRenderDocuments(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPEM, ecPEM := publicKeyPEM(t, &rsaKey.PublicKey), publicKeyPEM(t, &ecKey.PublicKey)
+
+	// the previous key is listed twice, once on its own and once in a bundle
+	discovery, jwks, err := renderDocuments("https://oidc.example.com/cluster/", "", []string{rsaPEM, ecPEM + rsaPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actualDiscovery discoveryDocument
+	if err := json.Unmarshal(discovery, &actualDiscovery); err != nil {
+		t.Fatal(err)
+	}
+	expectedDiscovery := discoveryDocument{
+		Issuer:                           "https://oidc.example.com/cluster/",
+		JWKSURI:                          "https://oidc.example.com/cluster/keys.json",
+		ResponseTypesSupported:           []string{"id_token"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{"ES256", "RS256"},
+	}
+	if diff := cmp.Diff(expectedDiscovery, actualDiscovery); len(diff) > 0 {
+		t.Errorf("unexpected discovery document: %s", diff)
+	}
+
+	var actualJWKS jsonWebKeySet
+	if err := json.Unmarshal(jwks, &actualJWKS); err != nil {
+		t.Fatal(err)
+	}
+	if len(actualJWKS.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %s", jwks)
+	}
+	if actualJWKS.Keys[0].KeyID > actualJWKS.Keys[1].KeyID {
+		t.Errorf("expected the keys to be sorted by key ID, got %s", jwks)
+	}
+	for _, key := range actualJWKS.Keys {
+		switch key.KeyType {
+		case "RSA":
+			der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+			if hash := sha256.Sum256(der); key.KeyID != base64.RawURLEncoding.EncodeToString(hash[:]) {
+				t.Errorf("unexpected key ID %s", key.KeyID)
+			}
+			n, _ := base64.RawURLEncoding.DecodeString(key.N)
+			e, _ := base64.RawURLEncoding.DecodeString(key.E)
+			if new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaKey.E) || key.Algorithm != "RS256" || key.Use != "sig" {
+				t.Errorf("unexpected RSA key %+v", key)
+			}
+		case "EC":
+			x, _ := base64.RawURLEncoding.DecodeString(key.X)
+			y, _ := base64.RawURLEncoding.DecodeString(key.Y)
+			if len(x) != 32 || len(y) != 32 || new(big.Int).SetBytes(x).Cmp(ecKey.X) != 0 || new(big.Int).SetBytes(y).Cmp(ecKey.Y) != 0 || key.Curve != "P-256" || key.Algorithm != "ES256" {
+				t.Errorf("unexpected EC key %+v", key)
+			}
+		default:
+			t.Errorf("unexpected key type %s", key.KeyType)
+		}
+	}
+
+	discovery, _, err = renderDocuments("https://oidc.example.com", "https://cdn.example.com/jwks", []string{rsaPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(discovery, &actualDiscovery); err != nil {
+		t.Fatal(err)
+	}
+	if actualDiscovery.JWKSURI != "https://cdn.example.com/jwks" || len(actualDiscovery.IDTokenSigningAlgValuesSupported) != 1 {
+		t.Errorf("unexpected discovery document %s", discovery)
+	}
+
+	if _, _, err := renderDocuments("https://oidc.example.com", "", nil); err == nil {
+		t.Error("expected an error without public keys")
+	}
+	if _, _, err := renderDocuments("https://oidc.example.com", "", []string{"garbage"}); err == nil {
+		t.Error("expected an error for an invalid public key")
+	}
+}
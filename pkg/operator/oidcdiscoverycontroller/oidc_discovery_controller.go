@@ -0,0 +1,287 @@
+package oidcdiscoverycontroller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/boundsatokensignercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// OIDCDiscoveryPublicationDegradedConditionType is true while the documents of the oidcDiscovery of the operator
+	// config cannot be rendered or published.
+	OIDCDiscoveryPublicationDegradedConditionType = "OIDCDiscoveryPublicationDegraded"
+
+	// ConfigMapName is the configmap in openshift-config-managed holding the published documents.
+	ConfigMapName = "oidc-discovery"
+	// DiscoveryDocumentKey and JWKSKey are the keys of the documents in the configmap and their paths below the URL of
+	// the endpoint.
+	DiscoveryDocumentKey = "openid-configuration"
+	JWKSKey              = "keys.json"
+
+	discoveryDocumentPath = ".well-known/openid-configuration"
+	caBundleKey           = "ca-bundle.crt"
+	uploadTimeout         = 30 * time.Second
+
+	// defaultServiceAccountIssuer is the issuer of config-overrides.yaml when authentication/cluster has none.
+	defaultServiceAccountIssuer = "https://kubernetes.default.svc"
+)
+
+// oidcDiscoveryController publishes the OIDC discovery document and the JWKS of the service account issuer to the
+// oidc-discovery configmap in openshift-config-managed and uploads them to the endpoint of the oidcDiscovery of the
+// operator config. The JWKS holds every public key of the bound-sa-token-signing-certs configmap, the signer only
+// rotates to a new key once it is there.
+type oidcDiscoveryController struct {
+	operatorClient         v1helpers.OperatorClient
+	configMapClient        corev1client.ConfigMapsGetter
+	configConfigMapLister  corev1listers.ConfigMapLister
+	configSecretLister     corev1listers.SecretLister
+	targetConfigMapLister  corev1listers.ConfigMapLister
+	managedConfigMapLister corev1listers.ConfigMapLister
+
+	// published are the documents last uploaded by URL, unchanged documents are not uploaded again
+	published map[string][]byte
+}
+
+func NewOIDCDiscoveryController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient corev1client.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &oidcDiscoveryController{
+		operatorClient:         operatorClient,
+		configMapClient:        configMapClient,
+		configConfigMapLister:  kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		configSecretLister:     kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Lister(),
+		targetConfigMapLister:  kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+		managedConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		published:              map[string][]byte{},
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("OIDCDiscoveryController", c.sync)).ResyncEvery(resyncinterval.For("OIDCDiscoveryController", 10*time.Minute)).ToController("OIDCDiscoveryController", eventRecorder.WithComponentSuffix("oidc-discovery-controller"))
+}
+
+func (c *oidcDiscoveryController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	reason, err := c.syncOIDCDiscovery(ctx, syncCtx.Recorder())
+
+	cond := operatorv1.OperatorCondition{
+		Type:   OIDCDiscoveryPublicationDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if err != nil {
+		cond.Status = operatorv1.ConditionTrue
+		cond.Reason = reason
+		cond.Message = err.Error()
+	}
+	if _, _, updateErr := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// syncOIDCDiscovery renders and publishes the documents. It returns the reason of the degraded condition with the
+// error.
+func (c *oidcDiscoveryController) syncOIDCDiscovery(ctx context.Context, recorder events.Recorder) (string, error) {
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return "InvalidConfig", err
+	}
+	config := operatorConfig.OIDCDiscovery
+	if errs := operatorconfig.ValidateOIDCDiscovery(config, field.NewPath("oidcDiscovery")); len(errs) > 0 {
+		return "InvalidConfig", fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+	if config == nil {
+		c.published = map[string][]byte{}
+		return "RemovalFailed", c.removeConfigMap(ctx, recorder)
+	}
+
+	spec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return "RenderFailed", err
+	}
+	issuer, err := serviceAccountIssuer(spec.ObservedConfig.Raw)
+	if err != nil {
+		return "RenderFailed", err
+	}
+	publicKeys, err := c.publicKeys()
+	if err != nil {
+		return "PublicKeysMissing", err
+	}
+	discovery, jwks, err := renderDocuments(issuer, config.JWKSURI, publicKeys)
+	if err != nil {
+		return "RenderFailed", fmt.Errorf("configmap %s/%s: %v", operatorclient.TargetNamespace, boundsatokensignercontroller.PublicKeyConfigMapName, err)
+	}
+
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: ConfigMapName},
+		Data: map[string]string{
+			DiscoveryDocumentKey: string(discovery),
+			JWKSKey:              string(jwks),
+		},
+	})
+	if err != nil {
+		return "ConfigMapUpdateFailed", err
+	}
+
+	if config.Endpoint == nil {
+		c.published = map[string][]byte{}
+		return "", nil
+	}
+	baseURL := strings.TrimSuffix(config.Endpoint.URL, "/")
+	// the JWKS is uploaded first, so that the discovery document never refers to keys that are not published yet
+	for _, document := range []struct {
+		url     string
+		content []byte
+	}{
+		{url: baseURL + "/" + JWKSKey, content: jwks},
+		{url: baseURL + "/" + discoveryDocumentPath, content: discovery},
+	} {
+		if bytes.Equal(c.published[document.url], document.content) {
+			continue
+		}
+		if err := c.upload(ctx, *config.Endpoint, document.url, document.content); err != nil {
+			return "UploadFailed", err
+		}
+		c.published[document.url] = document.content
+		recorder.Eventf("OIDCDiscoveryPublished", "Published %s", document.url)
+	}
+	return "", nil
+}
+
+// serviceAccountIssuer returns the first service-account-issuer of the observed config, the issuer of the tokens.
+func serviceAccountIssuer(observedConfig []byte) (string, error) {
+	if len(observedConfig) == 0 {
+		return defaultServiceAccountIssuer, nil
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(observedConfig, &config); err != nil {
+		return "", fmt.Errorf("unable to parse the observed config: %v", err)
+	}
+	issuers, _, err := unstructured.NestedStringSlice(config, "apiServerArguments", "service-account-issuer")
+	if err != nil {
+		return "", fmt.Errorf("unable to read the service account issuer from the observed config: %v", err)
+	}
+	if len(issuers) == 0 || len(issuers[0]) == 0 {
+		return defaultServiceAccountIssuer, nil
+	}
+	return issuers[0], nil
+}
+
+// publicKeys returns the public keys the kube-apiserver verifies bound service account tokens with, ordered by key.
+func (c *oidcDiscoveryController) publicKeys() ([]string, error) {
+	configMap, err := c.targetConfigMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(boundsatokensignercontroller.PublicKeyConfigMapName)
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s/%s: %v", operatorclient.TargetNamespace, boundsatokensignercontroller.PublicKeyConfigMapName, err)
+	}
+	var keys []string
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var publicKeys []string
+	for _, key := range keys {
+		publicKeys = append(publicKeys, configMap.Data[key])
+	}
+	return publicKeys, nil
+}
+
+func (c *oidcDiscoveryController) removeConfigMap(ctx context.Context, recorder events.Recorder) error {
+	_, err := c.managedConfigMapLister.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(ConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.configMapClient.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Delete(ctx, ConfigMapName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	recorder.Eventf("OIDCDiscoveryRemoved", "Removed configmap %s/%s", operatorclient.GlobalMachineSpecifiedConfigNamespace, ConfigMapName)
+	return nil
+}
+
+// upload PUTs the document to the URL with the headers of the headers secret, verifying the endpoint with the CAs of
+// the CA config map.
+func (c *oidcDiscoveryController) upload(ctx context.Context, endpoint operatorconfig.OIDCDiscoveryEndpoint, url string, content []byte) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(endpoint.CAConfigMap) > 0 {
+		caConfigMap, err := c.configConfigMapLister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(endpoint.CAConfigMap)
+		if err != nil {
+			return fmt.Errorf("CA configmap %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, endpoint.CAConfigMap, err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caConfigMap.Data[caBundleKey])) {
+			return fmt.Errorf("CA configmap %s/%s: no certificates in %s", operatorclient.GlobalUserSpecifiedConfigNamespace, endpoint.CAConfigMap, caBundleKey)
+		}
+	}
+	headers := http.Header{}
+	if len(endpoint.HeadersSecretName) > 0 {
+		secret, err := c.configSecretLister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(endpoint.HeadersSecretName)
+		if err != nil {
+			return fmt.Errorf("headers secret %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, endpoint.HeadersSecretName, err)
+		}
+		for name, value := range secret.Data {
+			headers.Set(name, string(value))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to upload %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unable to upload %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
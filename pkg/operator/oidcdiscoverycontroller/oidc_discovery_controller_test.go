@@ -0,0 +1,196 @@
+package oidcdiscoverycontroller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSync(t *testing.T) {
+	var lock sync.Mutex
+	uploads := map[string]string{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer upload-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		uploads[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorConfig := "oidcDiscovery:\n  endpoint:\n    url: " + server.URL + "/cluster/\n    headersSecretName: oidc-upload\n    caConfigMap: oidc-ca\n"
+	configConfigMaps := newIndexer(t,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"}, Data: map[string]string{"config.yaml": operatorConfig}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "oidc-ca"}, Data: map[string]string{"ca-bundle.crt": string(caPEM)}},
+	)
+	secrets := newIndexer(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "oidc-upload"},
+		Data:       map[string][]byte{"Authorization": []byte("Bearer upload-token")},
+	})
+	targetConfigMaps := newIndexer(t, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "bound-sa-token-signing-certs"},
+		Data:       map[string]string{"service-account-001.pub": publicKeyPEM(t, &rsaKey.PublicKey)},
+	})
+	managedConfigMaps := newIndexer(t)
+
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"service-account-issuer":["https://oidc.example.com/cluster"]}}`)},
+	}, &operatorv1.OperatorStatus{}, nil)
+	kubeClient := fake.NewSimpleClientset()
+	c := &oidcDiscoveryController{
+		operatorClient:         operatorClient,
+		configMapClient:        kubeClient.CoreV1(),
+		configConfigMapLister:  corev1listers.NewConfigMapLister(configConfigMaps),
+		configSecretLister:     corev1listers.NewSecretLister(secrets),
+		targetConfigMapLister:  corev1listers.NewConfigMapLister(targetConfigMaps),
+		managedConfigMapLister: corev1listers.NewConfigMapLister(managedConfigMaps),
+		published:              map[string][]byte{},
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	published, err := kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "oidc-discovery", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(published.Data["openid-configuration"], `"jwks_uri": "https://oidc.example.com/cluster/keys.json"`) || !strings.Contains(published.Data["keys.json"], `"kty": "RSA"`) {
+		t.Errorf("unexpected configmap %v", published.Data)
+	}
+	if uploads["/cluster/keys.json"] != published.Data["keys.json"] || uploads["/cluster/.well-known/openid-configuration"] != published.Data["openid-configuration"] {
+		t.Errorf("expected the documents of the configmap to be uploaded, got %v", uploads)
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionFalse, "AsExpected")
+
+	// unchanged documents are not uploaded again
+	uploads = map[string]string{}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 0 {
+		t.Errorf("expected no uploads, got %v", uploads)
+	}
+
+	// a new signing key is uploaded with the previous one
+	nextKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := getObject(t, targetConfigMaps, "openshift-kube-apiserver/bound-sa-token-signing-certs").(*corev1.ConfigMap)
+	keys.Data["service-account-002.pub"] = publicKeyPEM(t, &nextKey.PublicKey)
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 1 || strings.Count(uploads["/cluster/keys.json"], `"kid"`) != 2 {
+		t.Errorf("expected only the JWKS to be uploaded with both keys, got %v", uploads)
+	}
+
+	// a key that is listed twice does not change the JWKS
+	uploads = map[string]string{}
+	keys.Data["service-account-003.pub"] = publicKeyPEM(t, &rsaKey.PublicKey)
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 0 {
+		t.Errorf("expected no uploads, got %v", uploads)
+	}
+
+	// a failing upload degrades the operator
+	lastKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.Data["service-account-004.pub"] = publicKeyPEM(t, &lastKey.PublicKey)
+	getObject(t, secrets, "openshift-config/oidc-upload").(*corev1.Secret).Data["Authorization"] = []byte("Bearer expired-token")
+	if err := c.sync(context.TODO(), syncCtx); err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("expected the upload to fail, got %v", err)
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionTrue, "UploadFailed")
+
+	// removing the config removes the configmap
+	if err := managedConfigMaps.Add(published); err != nil {
+		t.Fatal(err)
+	}
+	getObject(t, configConfigMaps, "openshift-config/kube-apiserver-config").(*corev1.ConfigMap).Data["config.yaml"] = ""
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "oidc-discovery", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the configmap to be removed, got %v", err)
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionFalse, "AsExpected")
+}
+
+func TestServiceAccountIssuer(t *testing.T) {
+	for observedConfig, expected := range map[string]string{
+		``:   "https://kubernetes.default.svc",
+		`{}`: "https://kubernetes.default.svc",
+		`{"apiServerArguments":{"service-account-issuer":["https://a.example.com","https://b.example.com"]}}`: "https://a.example.com",
+	} {
+		actual, err := serviceAccountIssuer([]byte(observedConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf("expected %s for %q, got %s", expected, observedConfig, actual)
+		}
+	}
+}
+
+func newIndexer(t *testing.T, objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return indexer
+}
+
+func getObject(t *testing.T, indexer cache.Indexer, key string) interface{} {
+	obj, exists, err := indexer.GetByKey(key)
+	if err != nil || !exists {
+		t.Fatalf("%s not found: %v", key, err)
+	}
+	return obj
+}
+
+func assertCondition(t *testing.T, operatorClient v1helpers.OperatorClient, status operatorv1.ConditionStatus, reason string) {
+	t.Helper()
+	_, operatorStatus, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond := v1helpers.FindOperatorCondition(operatorStatus.Conditions, OIDCDiscoveryPublicationDegradedConditionType)
+	if cond == nil || cond.Status != status || cond.Reason != reason {
+		t.Errorf("expected %s with reason %s, got %+v", status, reason, cond)
+	}
+}
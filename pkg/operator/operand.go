@@ -0,0 +1,319 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	encryptiondeployer "github.com/openshift/library-go/pkg/operator/encryption/deployer"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/staticpod"
+	"github.com/openshift/library-go/pkg/operator/status"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditlogvolume"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/degradedreasons"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/deploymentcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerhistory"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installermetrics"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerpolicy"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installerstatus"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/kubeletversionskewcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/maintenancewindow"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodeexclusion"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodegates"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodekubeconfigcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodeorder"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/revisionlabels"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutdelay"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutpause"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutpreflight"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutprogress"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorfallback"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/staticpoddetection"
+)
+
+// Operand rolls out the revisions of the kube-apiserver. The reconciliation of the operator, the config observation,
+// the certificates and the encryption, is shared by all operands and only drives the revisions, so that a control
+// plane that runs the kube-apiserver elsewhere, e.g. a hosted control plane, reuses it with its own Operand instead
+// of a copy of the operator.
+type Operand interface {
+	// Controllers are the controllers of the rollout, they are run with the other controllers of the operator.
+	Controllers() []factory.Controller
+	// Start starts the controllers of the rollout that are not factory controllers.
+	Start(ctx context.Context)
+	// EncryptionNodeProvider tells the encryption controllers which revisions the kube-apiservers run, so that a new
+	// encryption config is only used once every kube-apiserver has it.
+	EncryptionNodeProvider() encryptiondeployer.MasterNodeProvider
+}
+
+// OperandInput holds the clients and informers of the operator an Operand is built with. The informers are started
+// after the Operand is built.
+type OperandInput struct {
+	OperatorClient             v1helpers.StaticPodOperatorClient
+	KubeClient                 kubernetes.Interface
+	DynamicClient              dynamic.Interface
+	ConfigClient               configv1client.Interface
+	KubeInformersForNamespaces v1helpers.KubeInformersForNamespaces
+	ConfigInformers            configv1informers.SharedInformerFactory
+	VersionRecorder            status.VersionGetter
+	EventRecorder              events.Recorder
+	// StatusEventRecorder collapses identical events, the static pod controllers whose conditions flap, e.g. of the
+	// installer and the static pod state, record with it
+	StatusEventRecorder events.Recorder
+}
+
+// NewOperandFunc builds the Operand the operator rolls out the revisions with.
+type NewOperandFunc func(ctx context.Context, input OperandInput) (Operand, error)
+
+// NewOperandForTopology builds the Operand of the control plane topology of infrastructure/cluster: static pods
+// installed on the master nodes, or the apiserver deployment for an External topology. The topology is read once.
+func NewOperandForTopology(ctx context.Context, input OperandInput) (Operand, error) {
+	topology, err := controlPlaneTopology(ctx, input.ConfigClient)
+	if err != nil {
+		return nil, err
+	}
+	if topology == configv1.ExternalTopologyMode {
+		klog.Infof("Control plane topology is %s, rendering the kube-apiserver as a deployment", configv1.ExternalTopologyMode)
+		return NewDeploymentOperand(input), nil
+	}
+	return NewStaticPodOperand(input, topology)
+}
+
+// controllerOperand is an Operand of a fixed set of controllers.
+type controllerOperand struct {
+	controllers  []factory.Controller
+	start        func(ctx context.Context)
+	nodeProvider encryptiondeployer.MasterNodeProvider
+}
+
+func (o *controllerOperand) Controllers() []factory.Controller {
+	return o.controllers
+}
+
+func (o *controllerOperand) Start(ctx context.Context) {
+	if o.start != nil {
+		o.start(ctx)
+	}
+}
+
+func (o *controllerOperand) EncryptionNodeProvider() encryptiondeployer.MasterNodeProvider {
+	return o.nodeProvider
+}
+
+// NewDeploymentOperand renders the pod of the latest revision as the apiserver deployment in openshift-kube-apiserver.
+func NewDeploymentOperand(input OperandInput) Operand {
+	operatorClient, kubeClient, kubeInformersForNamespaces := input.OperatorClient, input.KubeClient, input.KubeInformersForNamespaces
+	versionRecorder, eventRecorder := input.VersionRecorder, input.EventRecorder
+
+	return &controllerOperand{
+		controllers: []factory.Controller{
+			revisioncontroller.NewRevisionController(
+				operatorclient.TargetNamespace,
+				RevisionConfigMaps,
+				RevisionSecrets,
+				kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace),
+				revisioncontroller.StaticPodLatestRevisionClient{StaticPodOperatorClient: operatorClient},
+				kubeClient.CoreV1(),
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			deploymentcontroller.NewDeploymentController(
+				operatorclient.TargetNamespace,
+				deploymentcontroller.Resources{
+					RevisionConfigMaps: RevisionConfigMaps,
+					RevisionSecrets:    RevisionSecrets,
+					CertConfigMaps:     CertConfigMaps,
+					CertSecrets:        CertSecrets,
+				},
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.AppsV1(),
+				versionRecorder,
+				eventRecorder,
+			),
+		},
+		nodeProvider: encryptiondeployer.NewDeploymentNodeProvider(operatorclient.TargetNamespace, kubeInformersForNamespaces),
+	}
+}
+
+// NewStaticPodOperand installs the revisions as static pods on the master nodes through installer pods. The rollout of
+// a SingleReplica control plane topology neither waits between nodes nor for load balancers.
+func NewStaticPodOperand(input OperandInput, topology configv1.TopologyMode) (Operand, error) {
+	operatorClient, kubeClient, dynamicClient := input.OperatorClient, input.KubeClient, input.DynamicClient
+	kubeInformersForNamespaces, configInformers := input.KubeInformersForNamespaces, input.ConfigInformers
+	versionRecorder, eventRecorder := input.VersionRecorder, input.EventRecorder
+
+	singleReplica := topology == configv1.SingleReplicaTopologyMode
+	minReadyDuration := minReadyDurationForTopology(topology)
+
+	staticPodControllers, err := staticpod.NewBuilder(operatorClient, kubeClient, kubeInformersForNamespaces).
+		WithEvents(input.StatusEventRecorder).
+		WithCustomInstaller([]string{"cluster-kube-apiserver-operator", "installer"}, installerPodMutations(installerErrorInjector(operatorClient), canaryrollout.WaitForCanary(operatorClient), rolloutpause.WaitWhilePaused(), maintenancewindow.WaitForMaintenanceWindow(), nodegates.WaitForNodeGates(), rolloutpreflight.WaitForPreflightChecks(), nodeexclusion.WaitWhileExcluded(), rolloutdelay.DelayBetweenNodes(kubeInformersForNamespaces), installerpolicy.ApplyRetryPolicy(operatorClient), installerpolicy.ApplyPodSettings(), installerpolicy.ApplySingleNodeFastPath(singleReplica))).
+		WithPruning([]string{"cluster-kube-apiserver-operator", "prune"}, "kube-apiserver-pod").
+		WithRevisionedResources(operatorclient.TargetNamespace, "kube-apiserver", RevisionConfigMaps, RevisionSecrets).
+		WithUnrevisionedCerts("kube-apiserver-certs", CertConfigMaps, CertSecrets).
+		WithVersioning("kube-apiserver", versionRecorder).
+		WithMinReadyDuration(minReadyDuration).
+		WithStartupMonitor(startupmonitorreadiness.IsStartupMonitorEnabledFunction(configInformers.Config().V1().Infrastructures().Lister(), operatorClient), labels.Set{"apiserver": "true"}.AsSelector()).
+		ToControllers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &controllerOperand{
+		controllers: []factory.Controller{
+			nodekubeconfigcontroller.NewNodeKubeconfigController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient,
+				configInformers.Config().V1().Infrastructures(),
+				eventRecorder,
+			),
+			kubeletversionskewcontroller.NewKubeletVersionSkewController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			startupmonitorfallback.NewStartupMonitorFallbackController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			canaryrollout.NewCanaryRolloutController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			rolloutpause.NewRolloutPauseController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			rolloutprogress.NewRolloutProgressController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			installerpolicy.NewInstallerFailureController(
+				operatorClient,
+				eventRecorder,
+			),
+			nodegates.NewNodeGatesController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			auditlogvolume.NewAuditLogVolumeController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			maintenancewindow.NewMaintenanceWindowController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			rolloutpreflight.NewRolloutPreflightController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				dynamicClient,
+				CertSecrets,
+				eventRecorder,
+			),
+			staticpoddetection.NewMissingStaticPodController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				configInformers.Config().V1().Infrastructures(),
+				kubeClient.CoreV1(),
+				os.Getenv("OPERATOR_IMAGE"),
+				eventRecorder,
+			),
+			degradedreasons.NewDegradedReasonsController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				configInformers.Config().V1().Infrastructures(),
+				eventRecorder,
+			),
+			nodeexclusion.NewNodeExclusionController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			nodeorder.NewNodeOrderController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			installermetrics.NewInstallerMetricsController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				eventRecorder,
+			),
+			installerhistory.NewInstallerHistoryController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			installerstatus.NewInstallerStatusController(
+				operatorClient,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+			revisionlabels.NewRevisionLabelController(
+				operatorClient,
+				RevisionConfigMaps,
+				RevisionSecrets,
+				kubeInformersForNamespaces,
+				kubeClient.CoreV1(),
+				eventRecorder,
+			),
+		},
+		start:        staticPodControllers.Start,
+		nodeProvider: encryptiondeployer.StaticPodNodeProvider{OperatorClient: operatorClient},
+	}, nil
+}
+
+// minReadyDurationForTopology returns how long a new kube-apiserver has to be ready before the next node is updated.
+// It gives the load balancers time to notice the new kube-apiserver. A SingleReplica control plane has neither a
+// next node nor a load balancer in front of the kube-apiserver, so its rollout does not wait.
+func minReadyDurationForTopology(topology configv1.TopologyMode) time.Duration {
+	if topology == configv1.SingleReplicaTopologyMode {
+		return 0
+	}
+	return 30 * time.Second
+}
+
+// controlPlaneTopology returns the control plane topology of infrastructure/cluster, empty when it does not exist.
+func controlPlaneTopology(ctx context.Context, configClient configv1client.Interface) (configv1.TopologyMode, error) {
+	infra, err := configClient.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return infra.Status.ControlPlaneTopology, nil
+}
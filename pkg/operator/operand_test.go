@@ -0,0 +1,36 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	encryptiondeployer "github.com/openshift/library-go/pkg/operator/encryption/deployer"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/status"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestNewDeploymentOperand(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	input := OperandInput{
+		OperatorClient:             v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil),
+		KubeClient:                 kubeClient,
+		KubeInformersForNamespaces: v1helpers.NewKubeInformersForNamespaces(kubeClient, "", operatorclient.TargetNamespace),
+		VersionRecorder:            status.NewVersionGetter(),
+		EventRecorder:              events.NewInMemoryRecorder("test"),
+	}
+
+	operand := NewDeploymentOperand(input)
+	// a no-op, the deployment operand has no static pod controllers
+	operand.Start(context.TODO())
+	if len(operand.Controllers()) != 2 {
+		t.Errorf("expected the revision and the deployment controllers, got %d controllers", len(operand.Controllers()))
+	}
+	if _, ok := operand.EncryptionNodeProvider().(*encryptiondeployer.DeploymentNodeProvider); !ok {
+		t.Errorf("expected the nodes of the deployment for encryption, got %T", operand.EncryptionNodeProvider())
+	}
+}
@@ -0,0 +1,38 @@
+package operatorconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Fields returns the paths of the fields of the operator config this operator version honors, e.g. "rollout" and
+// "rollout.nodeOrder.type". The items of a list are marked by [], e.g. "rollout.nodeGates[].name". Fields of types that
+// are not defined in this package, like resource requirements, are not descended into.
+func Fields() []string {
+	return fieldPaths(reflect.TypeOf(KubeAPIServerOperatorConfig{}), "")
+}
+
+func fieldPaths(t reflect.Type, prefix string) []string {
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		path := prefix + name
+		paths = append(paths, path)
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice {
+			if fieldType.Kind() == reflect.Slice {
+				path += "[]"
+			}
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType.PkgPath() == t.PkgPath() {
+			paths = append(paths, fieldPaths(fieldType, path+".")...)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,126 @@
+package operatorconfig
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+)
+
+// kubeAPIServerOnlyFlags are the flags of the kube-apiserver of this release that are not registered by the generic
+// apiserver options, keyed by name. The value tells whether the flag accepts a list of values.
+var kubeAPIServerOnlyFlags = map[string]bool{
+	"advertise-address":                            false,
+	"allow-metric-labels":                          true,
+	"allow-privileged":                             false,
+	"anonymous-auth":                               false,
+	"api-audiences":                                true,
+	"apiserver-count":                              false,
+	"authentication-token-webhook-cache-ttl":       false,
+	"authentication-token-webhook-config-file":     false,
+	"authentication-token-webhook-version":         false,
+	"authorization-mode":                           true,
+	"authorization-policy-file":                    false,
+	"authorization-webhook-cache-authorized-ttl":   false,
+	"authorization-webhook-cache-unauthorized-ttl": false,
+	"authorization-webhook-config-file":            false,
+	"authorization-webhook-version":                false,
+	"client-ca-file":                               false,
+	"cloud-config":                                 false,
+	"cloud-provider":                               false,
+	"cloud-provider-gce-l7lb-src-cidrs":            false,
+	"cloud-provider-gce-lb-src-cidrs":              false,
+	"default-not-ready-toleration-seconds":         false,
+	"default-unreachable-toleration-seconds":       false,
+	"disable-admission-plugins":                    true,
+	"disabled-metrics":                             true,
+	"enable-aggregator-routing":                    false,
+	"enable-bootstrap-token-auth":                  false,
+	"enable-logs-handler":                          false,
+	"endpoint-reconciler-type":                     false,
+	"event-ttl":                                    false,
+	"experimental-logging-sanitization":            false,
+	"feature-gates":                                true,
+	"identity-lease-duration-seconds":              false,
+	"identity-lease-renew-interval-seconds":        false,
+	"insecure-port":                                false,
+	"kubelet-certificate-authority":                false,
+	"kubelet-client-certificate":                   false,
+	"kubelet-client-key":                           false,
+	"kubelet-port":                                 false,
+	"kubelet-preferred-address-types":              true,
+	"kubelet-read-only-port":                       false,
+	"kubelet-timeout":                              false,
+	"kubernetes-service-node-port":                 false,
+	"logging-format":                               false,
+	"master-service-namespace":                     false,
+	"max-connection-bytes-per-sec":                 false,
+	"oidc-ca-file":                                 false,
+	"oidc-client-id":                               false,
+	"oidc-groups-claim":                            false,
+	"oidc-groups-prefix":                           false,
+	"oidc-issuer-url":                              false,
+	"oidc-required-claim":                          true,
+	"oidc-signing-algs":                            true,
+	"oidc-username-claim":                          false,
+	"oidc-username-prefix":                         false,
+	"openshift-config":                             false,
+	"permit-address-sharing":                       false,
+	"permit-port-sharing":                          false,
+	"proxy-client-cert-file":                       false,
+	"proxy-client-key-file":                        false,
+	"requestheader-allowed-names":                  true,
+	"requestheader-client-ca-file":                 false,
+	"requestheader-extra-headers-prefix":           true,
+	"requestheader-group-headers":                  true,
+	"requestheader-username-headers":               true,
+	"service-account-extend-token-expiration":      false,
+	"service-account-issuer":                       true,
+	"service-account-jwks-uri":                     false,
+	"service-account-key-file":                     true,
+	"service-account-lookup":                       false,
+	"service-account-max-token-expiration":         false,
+	"service-account-signing-key-file":             false,
+	"service-cluster-ip-range":                     false,
+	"service-node-port-range":                      false,
+	"show-hidden-metrics-for-version":              false,
+	"shutdown-send-retry-after":                    false,
+	"token-auth-file":                              false,
+	"v":                                            false,
+	"vmodule":                                      true,
+}
+
+// KubeAPIServerFlags is the flag registry of the kube-apiserver of this release, keyed by flag name. The value
+// tells whether the flag accepts a list of values. The generic flags are taken from the compiled-in apiserver
+// options, so they follow the vendored apiserver version.
+var KubeAPIServerFlags = newKubeAPIServerFlags()
+
+func newKubeAPIServerFlags() map[string]bool {
+	fs := pflag.NewFlagSet("kube-apiserver", pflag.ContinueOnError)
+	genericoptions.NewServerRunOptions().AddUniversalFlags(fs)
+	genericoptions.NewEtcdOptions(storagebackend.NewDefaultConfig("", nil)).AddFlags(fs)
+	genericoptions.NewSecureServingOptions().AddFlags(fs)
+	genericoptions.NewAuditOptions().AddFlags(fs)
+	genericoptions.NewFeatureOptions().AddFlags(fs)
+	genericoptions.NewAPIEnablementOptions().AddFlags(fs)
+	genericoptions.NewAdmissionOptions().AddFlags(fs)
+	genericoptions.NewEgressSelectorOptions().AddFlags(fs)
+	genericoptions.NewTracingOptions().AddFlags(fs)
+
+	flags := map[string]bool{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags[f.Name] = isListFlag(f.Value.Type())
+	})
+	for name, list := range kubeAPIServerOnlyFlags {
+		flags[name] = list
+	}
+	return flags
+}
+
+// isListFlag tells whether a flag of the given pflag type can be given multiple values.
+func isListFlag(flagType string) bool {
+	return strings.HasSuffix(flagType, "Slice") || strings.HasSuffix(flagType, "Array") || sets.NewString("mapStringString", "mapStringBool", "namedCertKey").Has(flagType)
+}
@@ -0,0 +1,77 @@
+package operatorconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the name of the configmap in the openshift-config namespace holding the operator config.
+	ConfigMapName = "kube-apiserver-config"
+	// ConfigKey is the key of the configmap holding the yaml serialized KubeAPIServerOperatorConfig.
+	ConfigKey = "config.yaml"
+)
+
+// Get returns the operator config stored in openshift-config/kube-apiserver-config. A missing configmap
+// or key results in an empty config.
+func Get(lister corev1listers.ConfigMapLister) (*KubeAPIServerOperatorConfig, error) {
+	cm, err := lister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return &KubeAPIServerOperatorConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := Decode([]byte(cm.Data[ConfigKey]))
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, ConfigMapName, err)
+	}
+	return config, nil
+}
+
+// GetFromClient returns the operator config like Get, but reads the configmap from the API, e.g. before the
+// informers of the operator are started.
+func GetFromClient(ctx context.Context, client corev1client.ConfigMapsGetter) (*KubeAPIServerOperatorConfig, error) {
+	cm, err := client.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &KubeAPIServerOperatorConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := Decode([]byte(cm.Data[ConfigKey]))
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, ConfigMapName, err)
+	}
+	return config, nil
+}
+
+// Decode decodes the yaml or json serialized operator config. Unknown fields are rejected so that a
+// typo does not silently result in the default behaviour.
+func Decode(data []byte) (*KubeAPIServerOperatorConfig, error) {
+	config := &KubeAPIServerOperatorConfig{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return config, nil
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", ConfigKey, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %v", ConfigKey, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,1051 @@
+package operatorconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+)
+
+func TestGet(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		configMap      *corev1.ConfigMap
+		expectedConfig *KubeAPIServerOperatorConfig
+		expectedError  string
+	}{
+		{
+			name:           "no configmap",
+			expectedConfig: &KubeAPIServerOperatorConfig{},
+		},
+		{
+			name:           "empty configmap",
+			configMap:      newConfigMap(""),
+			expectedConfig: &KubeAPIServerOperatorConfig{},
+		},
+		{
+			name:           "yaml",
+			configMap:      newConfigMap("runtimeConfig:\n- storage.k8s.io/v1alpha1\n"),
+			expectedConfig: &KubeAPIServerOperatorConfig{RuntimeConfig: []string{"storage.k8s.io/v1alpha1"}},
+		},
+		{
+			name:           "json",
+			configMap:      newConfigMap(`{"runtimeConfig":["storage.k8s.io/v1alpha1"]}`),
+			expectedConfig: &KubeAPIServerOperatorConfig{RuntimeConfig: []string{"storage.k8s.io/v1alpha1"}},
+		},
+		{
+			name:          "unknown field",
+			configMap:     newConfigMap("runtimeConfigs:\n- storage.k8s.io/v1alpha1\n"),
+			expectedError: `unknown field "runtimeConfigs"`,
+		},
+		{
+			name:          "malformed",
+			configMap:     newConfigMap("runtimeConfig: ["),
+			expectedError: "unable to parse config.yaml",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if scenario.configMap != nil {
+				if err := indexer.Add(scenario.configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config, err := Get(corev1listers.NewConfigMapLister(indexer))
+			if len(scenario.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), scenario.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", scenario.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(scenario.expectedConfig, config) {
+				t.Fatalf("unexpected config, diff = %v", cmp.Diff(scenario.expectedConfig, config))
+			}
+		})
+	}
+}
+
+func TestValidateRuntimeConfig(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		runtimeConfig []string
+		expectedErrs  int
+	}{
+		{name: "empty"},
+		{name: "allowed", runtimeConfig: []string{"storage.k8s.io/v1alpha1", "flowcontrol.apiserver.k8s.io/v1alpha1"}},
+		{name: "not allowed", runtimeConfig: []string{"apiextensions.k8s.io/v1beta1"}, expectedErrs: 1},
+		{name: "with value", runtimeConfig: []string{"storage.k8s.io/v1alpha1=false"}, expectedErrs: 1},
+		{name: "duplicate", runtimeConfig: []string{"storage.k8s.io/v1alpha1", "storage.k8s.io/v1alpha1"}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateRuntimeConfig(scenario.runtimeConfig, field.NewPath("runtimeConfig"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		resources    map[string]corev1.ResourceRequirements
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{
+			name: "requests and limits",
+			resources: map[string]corev1.ResourceRequirements{
+				"kube-apiserver": {
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+				},
+				"kube-apiserver-check-endpoints": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5m")}},
+			},
+		},
+		{
+			name:         "unknown container",
+			resources:    map[string]corev1.ResourceRequirements{"audit-shipper": {}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "unsupported resource",
+			resources:    map[string]corev1.ResourceRequirements{"kube-apiserver": {Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")}}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "zero request",
+			resources:    map[string]corev1.ResourceRequirements{"kube-apiserver": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")}}},
+			expectedErrs: 1,
+		},
+		{
+			name: "limit below request",
+			resources: map[string]corev1.ResourceRequirements{"kube-apiserver": {
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateResources(scenario.resources, field.NewPath("resources"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestParseCPUSet(t *testing.T) {
+	scenarios := []struct {
+		cpus        string
+		expected    []int
+		expectError bool
+	}{
+		{cpus: "0", expected: []int{0}},
+		{cpus: "0-1,52-53", expected: []int{0, 1, 52, 53}},
+		{cpus: "3,1-2", expected: []int{1, 2, 3}},
+		{cpus: "", expectError: true},
+		{cpus: "2-1", expectError: true},
+		{cpus: "0-1,1", expectError: true},
+		{cpus: "a-b", expectError: true},
+		{cpus: "0,", expectError: true},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.cpus, func(t *testing.T) {
+			cpus, err := ParseCPUSet(scenario.cpus)
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(scenario.expected, cpus) {
+				t.Fatalf("unexpected cpus, diff = %v", cmp.Diff(scenario.expected, cpus))
+			}
+		})
+	}
+}
+
+func TestValidateSidecars(t *testing.T) {
+	shipper := corev1.Container{
+		Name:         "audit-shipper",
+		Image:        "quay.io/example/shipper:latest",
+		VolumeMounts: []corev1.VolumeMount{{Name: "audit-dir", MountPath: "/var/log/kube-apiserver"}, {Name: "buffer", MountPath: "/buffer"}},
+	}
+	buffer := corev1.Volume{Name: "buffer", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+
+	scenarios := []struct {
+		name         string
+		sidecars     SidecarConfig
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "audit log shipper", sidecars: SidecarConfig{Containers: []corev1.Container{shipper}, Volumes: []corev1.Volume{buffer}}},
+		{
+			name:         "container of the static pod",
+			sidecars:     SidecarConfig{Containers: []corev1.Container{{Name: "kube-apiserver", Image: "quay.io/example/shipper:latest"}}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "unknown volume",
+			sidecars:     SidecarConfig{Containers: []corev1.Container{shipper}},
+			expectedErrs: 1,
+		},
+		{
+			name: "volume of the static pod and unsupported source",
+			sidecars: SidecarConfig{Volumes: []corev1.Volume{{Name: "cert-dir", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "serving-cert"},
+			}}}},
+			expectedErrs: 2,
+		},
+		{
+			name: "port of the static pod",
+			sidecars: SidecarConfig{Containers: []corev1.Container{{
+				Name:  "proxy",
+				Image: "quay.io/example/proxy:latest",
+				Ports: []corev1.ContainerPort{{ContainerPort: 6443}},
+			}}},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateSidecars(scenario.sidecars, field.NewPath("sidecars"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		environment  map[string]string
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "valid", environment: map[string]string{"GOGC": "200", "GODEBUG": "gctrace=1,madvdontneed=1", "GOMAXPROCS": "8", "GOMEMLIMIT": "6GiB", "GOTRACEBACK": "all"}},
+		{name: "off", environment: map[string]string{"GOGC": "off", "GOMEMLIMIT": "off"}},
+		{name: "not allowed", environment: map[string]string{"HTTP_PROXY": "http://proxy:3128"}, expectedErrs: 1},
+		{name: "invalid values", environment: map[string]string{"GOGC": "-1", "GODEBUG": "gctrace", "GOMAXPROCS": "0", "GOMEMLIMIT": "6G", "GOTRACEBACK": "verbose"}, expectedErrs: 5},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateEnvironment(scenario.environment, field.NewPath("environment"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateShutdownDelayDuration(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		value        string
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "zero", value: "0s"},
+		{name: "valid", value: "1m30s"},
+		{name: "fraction of a second", value: "1500ms", expectedErrs: 1},
+		{name: "too long", value: "11m", expectedErrs: 1},
+		{name: "not a duration", value: "90", expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateShutdownDelayDuration(scenario.value, field.NewPath("shutdownDelayDuration"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateLoggingConfigOperatorControllers(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		controllers  map[string]operatorv1.LogLevel
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "valid", controllers: map[string]operatorv1.LogLevel{"certRotation": operatorv1.Debug, "installer": operatorv1.Normal}},
+		{name: "unknown controller", controllers: map[string]operatorv1.LogLevel{"certrotation": operatorv1.Debug}, expectedErrs: 1},
+		{name: "unknown level", controllers: map[string]operatorv1.LogLevel{"certRotation": "Verbose", "installer": ""}, expectedErrs: 2},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateLoggingConfig(LoggingConfig{OperatorControllers: scenario.controllers}, field.NewPath("logging"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateAuditPolicy(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       AuditPolicyConfig
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "custom rules", config: AuditPolicyConfig{CustomRulesConfigMap: "audit-custom-rules"}},
+		{name: "invalid config map name", config: AuditPolicyConfig{CustomRulesConfigMap: "Audit_Rules"}, expectedErrs: 1},
+		{
+			name: "overrides",
+			config: AuditPolicyConfig{Overrides: []AuditPolicyOverride{
+				{
+					Profile:    configv1.WriteRequestBodiesAuditProfileType,
+					Namespaces: []string{"openshift-*"},
+					Resources:  []AuditPolicyResources{{Group: "rbac.authorization.k8s.io"}, {Resources: []string{"secrets"}}},
+				},
+				{Profile: configv1.NoneAuditProfileType, Namespaces: []string{"noisy"}},
+				{Profile: configv1.AllRequestBodiesAuditProfileType, Resources: []AuditPolicyResources{{Resources: []string{"pods/exec", "*/status"}}}},
+			}},
+		},
+		{
+			name:         "override without profile",
+			config:       AuditPolicyConfig{Overrides: []AuditPolicyOverride{{Namespaces: []string{"noisy"}}}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "override without namespaces and resources",
+			config:       AuditPolicyConfig{Overrides: []AuditPolicyOverride{{Profile: configv1.NoneAuditProfileType}}},
+			expectedErrs: 1,
+		},
+		{
+			name: "invalid override namespaces",
+			config: AuditPolicyConfig{Overrides: []AuditPolicyOverride{
+				{Profile: configv1.DefaultAuditProfileType, Namespaces: []string{"*", "Openshift", "open*shift", "noisy", "noisy"}},
+			}},
+			expectedErrs: 4,
+		},
+		{
+			name: "invalid override resources",
+			config: AuditPolicyConfig{Overrides: []AuditPolicyOverride{
+				{Profile: configv1.DefaultAuditProfileType, Resources: []AuditPolicyResources{{Group: "RBAC"}, {Resources: []string{"Secrets", "pods/exec/x"}}, {}}},
+			}},
+			expectedErrs: 4,
+		},
+		{
+			name: "intents",
+			config: AuditPolicyConfig{Intents: []AuditPolicyIntent{
+				{Level: auditv1.LevelRequestResponse, Groups: []string{"system:cluster-admins"}},
+				{Level: auditv1.LevelRequest, Verbs: []string{"delete", "deletecollection"}, Resources: []AuditPolicyResources{{Group: "apps"}}},
+				{Level: auditv1.LevelNone, Users: []string{"system:serviceaccount:backup:velero"}, Namespaces: []string{"backup"}},
+			}},
+		},
+		{
+			name:         "intent without level and selectors",
+			config:       AuditPolicyConfig{Intents: []AuditPolicyIntent{{}}},
+			expectedErrs: 2,
+		},
+		{
+			name: "invalid intent selectors",
+			config: AuditPolicyConfig{Intents: []AuditPolicyIntent{
+				{Level: auditv1.LevelMetadata, Users: []string{"", "alice", "alice"}, Verbs: []string{"Delete"}, Resources: []AuditPolicyResources{{Resources: []string{"Secrets"}}}, Namespaces: []string{"openshift-*"}},
+			}},
+			expectedErrs: 5,
+		},
+		{
+			name: "exemptions",
+			config: AuditPolicyConfig{Exemptions: []AuditPolicyExemption{
+				{Users: []string{"system:serviceaccount:backup:velero"}},
+				{Groups: []string{"system:serviceaccounts:monitoring"}, Level: auditv1.LevelMetadata, Verbs: []string{"get", "list", "watch"}},
+			}},
+		},
+		{
+			name:         "exemption without users and groups",
+			config:       AuditPolicyConfig{Exemptions: []AuditPolicyExemption{{Level: auditv1.LevelNone}}},
+			expectedErrs: 1,
+		},
+		{
+			name: "invalid exemption",
+			config: AuditPolicyConfig{Exemptions: []AuditPolicyExemption{
+				{Users: []string{"alice", "alice"}, Groups: []string{"system:authenticated"}, Level: auditv1.LevelRequest, Verbs: []string{"List"}},
+			}},
+			expectedErrs: 4,
+		},
+		{name: "sampling", config: AuditPolicyConfig{Sampling: &AuditSamplingConfig{ReadLevel: auditv1.LevelNone}}},
+		{
+			name:         "sampling without a read level",
+			config:       AuditPolicyConfig{Sampling: &AuditSamplingConfig{}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "sampling with a read level above Metadata",
+			config:       AuditPolicyConfig{Sampling: &AuditSamplingConfig{ReadLevel: auditv1.LevelRequest}},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateAuditPolicy(scenario.config, field.NewPath("auditPolicy"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateAuditWebhook(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       *AuditWebhookConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "secret only", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook"}},
+		{
+			name: "batch",
+			config: &AuditWebhookConfig{
+				KubeConfigSecret: "audit-webhook",
+				Mode:             AuditWebhookBatch,
+				InitialBackoff:   "10s",
+				Batch: AuditWebhookBatchConfig{
+					BufferSize:    pointer.Int32(20000),
+					MaxSize:       pointer.Int32(400),
+					MaxWait:       "30s",
+					ThrottleQPS:   pointer.Int32(10),
+					ThrottleBurst: pointer.Int32(15),
+				},
+			},
+		},
+		{name: "blocking", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", Mode: AuditWebhookBlockingStrict}},
+		{name: "url", config: &AuditWebhookConfig{URL: "https://siem.example.com:8443/audit", CAConfigMap: "siem-ca", ClientCertSecret: "siem-client"}},
+		{name: "no secret", config: &AuditWebhookConfig{}, expectedErrs: 1},
+		{name: "secret and url", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", URL: "https://siem.example.com/audit"}, expectedErrs: 1},
+		{name: "ca with a secret", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", CAConfigMap: "siem-ca"}, expectedErrs: 1},
+		{name: "http url without client cert", config: &AuditWebhookConfig{URL: "http://siem.example.com/audit"}, expectedErrs: 2},
+		{name: "invalid ca name", config: &AuditWebhookConfig{URL: "https://siem.example.com/audit", CAConfigMap: "Siem_CA", ClientCertSecret: "siem-client"}, expectedErrs: 1},
+		{name: "invalid secret name", config: &AuditWebhookConfig{KubeConfigSecret: "Audit_Webhook"}, expectedErrs: 1},
+		{name: "unknown mode", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", Mode: "stream"}, expectedErrs: 1},
+		{name: "invalid backoff", config: &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", InitialBackoff: "1ms"}, expectedErrs: 1},
+		{
+			name:         "batch settings in blocking mode",
+			config:       &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", Mode: AuditWebhookBlocking, Batch: AuditWebhookBatchConfig{MaxWait: "5s"}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "batch larger than the buffer",
+			config:       &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", Batch: AuditWebhookBatchConfig{BufferSize: pointer.Int32(100), MaxSize: pointer.Int32(400)}},
+			expectedErrs: 1,
+		},
+		{
+			name:         "invalid throttle",
+			config:       &AuditWebhookConfig{KubeConfigSecret: "audit-webhook", Batch: AuditWebhookBatchConfig{ThrottleQPS: pointer.Int32(0), ThrottleBurst: pointer.Int32(-1)}},
+			expectedErrs: 2,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateAuditWebhook(scenario.config, field.NewPath("auditWebhook"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateServiceAccountSigningKey(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       *ServiceAccountSigningKeyConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "secret", config: &ServiceAccountSigningKeyConfig{SecretName: "bound-sa-signing-key"}},
+		{name: "no secret", config: &ServiceAccountSigningKeyConfig{}, expectedErrs: 1},
+		{name: "invalid secret name", config: &ServiceAccountSigningKeyConfig{SecretName: "Signing_Key"}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateServiceAccountSigningKey(scenario.config, field.NewPath("serviceAccountSigningKey"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateServiceAccountSigningKeyRotation(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       ServiceAccountSigningKeyRotationConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "valid", config: ServiceAccountSigningKeyRotationConfig{Interval: "2160h", ForceRotationReason: "CHG-1234", PropagationDelay: "2h"}},
+		{name: "no delay", config: ServiceAccountSigningKeyRotationConfig{PropagationDelay: "0s"}},
+		{name: "interval too short", config: ServiceAccountSigningKeyRotationConfig{Interval: "1h"}, expectedErrs: 1},
+		{name: "delay too long", config: ServiceAccountSigningKeyRotationConfig{PropagationDelay: "48h"}, expectedErrs: 1},
+		{name: "invalid durations", config: ServiceAccountSigningKeyRotationConfig{Interval: "90d", PropagationDelay: "-1h"}, expectedErrs: 2},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateServiceAccountSigningKeyRotation(scenario.config, field.NewPath("serviceAccountSigningKeyRotation"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateOIDCDiscovery(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       *OIDCDiscoveryConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "configmap only", config: &OIDCDiscoveryConfig{}},
+		{name: "endpoint", config: &OIDCDiscoveryConfig{JWKSURI: "https://oidc.example.com/cluster/keys.json", Endpoint: &OIDCDiscoveryEndpoint{URL: "https://oidc.example.com/cluster", HeadersSecretName: "oidc-upload", CAConfigMap: "oidc-ca"}}},
+		{name: "no url", config: &OIDCDiscoveryConfig{Endpoint: &OIDCDiscoveryEndpoint{}}, expectedErrs: 1},
+		{name: "http url", config: &OIDCDiscoveryConfig{Endpoint: &OIDCDiscoveryEndpoint{URL: "http://oidc.example.com"}}, expectedErrs: 1},
+		{name: "url with query", config: &OIDCDiscoveryConfig{Endpoint: &OIDCDiscoveryEndpoint{URL: "https://oidc.example.com/?token=secret"}}, expectedErrs: 1},
+		{name: "relative jwks uri", config: &OIDCDiscoveryConfig{JWKSURI: "/keys.json"}, expectedErrs: 1},
+		{name: "invalid names", config: &OIDCDiscoveryConfig{Endpoint: &OIDCDiscoveryEndpoint{URL: "https://oidc.example.com", HeadersSecretName: "Upload_Headers", CAConfigMap: "CA"}}, expectedErrs: 2},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateOIDCDiscovery(scenario.config, field.NewPath("oidcDiscovery"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateFeatureGates(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       FeatureGatesConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "valid", config: FeatureGatesConfig{AcknowledgedMigrations: []string{"IPv6DualStack", "CSIMigrationAWS"}}},
+		{name: "invalid name", config: FeatureGatesConfig{AcknowledgedMigrations: []string{"ipv6-dual-stack", ""}}, expectedErrs: 2},
+		{name: "duplicate", config: FeatureGatesConfig{AcknowledgedMigrations: []string{"IPv6DualStack", "IPv6DualStack"}}, expectedErrs: 1},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateFeatureGates(scenario.config, field.NewPath("featureGates"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateKubeletVersionSkew(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       KubeletVersionSkewConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "valid", config: KubeletVersionSkewConfig{Policy: KubeletVersionSkewWarnOnly, ExemptNodes: []KubeletVersionSkewExemption{{Node: "edge-1", Until: "2021-11-30T00:00:00Z", Reason: "CHG-1234"}}}},
+		{name: "unknown policy", config: KubeletVersionSkewConfig{Policy: "Ignore"}, expectedErrs: 1},
+		{name: "no node and no end", config: KubeletVersionSkewConfig{ExemptNodes: []KubeletVersionSkewExemption{{}}}, expectedErrs: 2},
+		{name: "invalid time", config: KubeletVersionSkewConfig{ExemptNodes: []KubeletVersionSkewExemption{{Node: "edge-1", Until: "2021-11-30"}}}, expectedErrs: 1},
+		{name: "duplicate node", config: KubeletVersionSkewConfig{ExemptNodes: []KubeletVersionSkewExemption{{Node: "edge-1", Until: "2021-11-30T00:00:00Z"}, {Node: "edge-1", Until: "2021-12-30T00:00:00Z"}}}, expectedErrs: 1},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateKubeletVersionSkew(scenario.config, field.NewPath("kubeletVersionSkew"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateResourceSync(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       ResourceSyncConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "valid", config: ResourceSyncConfig{
+			ConfigMaps: []ResourceSyncRule{{Source: "vault-ca"}, {Source: "proxy-ca", Destination: "user-proxy-ca-bundle", Keys: []string{"ca-bundle.crt"}}},
+			Secrets:    []ResourceSyncRule{{Source: "vault-ca"}},
+		}},
+		{name: "no source", config: ResourceSyncConfig{ConfigMaps: []ResourceSyncRule{{Destination: "user-ca"}}}, expectedErrs: 1},
+		{name: "operator managed destination", config: ResourceSyncConfig{Secrets: []ResourceSyncRule{{Source: "etcd-client", Destination: "etcd-client"}}}, expectedErrs: 1},
+		{name: "duplicate destination", config: ResourceSyncConfig{ConfigMaps: []ResourceSyncRule{{Source: "ca"}, {Source: "other-ca", Destination: "user-ca"}}}, expectedErrs: 1},
+		{name: "invalid names and keys", config: ResourceSyncConfig{ConfigMaps: []ResourceSyncRule{{Source: "Vault_CA", Keys: []string{"ca/bundle"}}}}, expectedErrs: 3},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateResourceSync(scenario.config, field.NewPath("resourceSync"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateAuditForwarder(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       *AuditForwarderConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "syslog", config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: "siem.example.com:6514"}},
+		{
+			name:   "tls",
+			config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: "[fd00::10]:6514", TLS: &AuditForwarderTLSConfig{CAConfigMap: "siem-ca"}},
+		},
+		{name: "unsupported protocol", config: &AuditForwarderConfig{Protocol: "OTLP", Endpoint: "collector:4317"}, expectedErrs: 1},
+		{name: "no endpoint", config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog}, expectedErrs: 1},
+		{name: "no port", config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: "siem.example.com"}, expectedErrs: 1},
+		{name: "no host", config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: ":6514"}, expectedErrs: 1},
+		{name: "invalid port", config: &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: "siem:syslog"}, expectedErrs: 1},
+		{
+			name:         "invalid ca config map",
+			config:       &AuditForwarderConfig{Protocol: AuditForwarderSyslog, Endpoint: "siem:6514", TLS: &AuditForwarderTLSConfig{CAConfigMap: "Siem_CA"}},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateAuditForwarder(scenario.config, field.NewPath("auditForwarder"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateOperandImage(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		image        string
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "tag", image: "quay.io/user/hyperkube:dev"},
+		{name: "registry port", image: "registry.local:5000/openshift/hyperkube:4.10-dev"},
+		{name: "digest", image: "quay.io/openshift/hyperkube@sha256:" + strings.Repeat("a", 64)},
+		{name: "no tag", image: "quay.io/user/hyperkube", expectedErrs: 1},
+		{name: "no registry", image: "hyperkube:dev", expectedErrs: 1},
+		{name: "short digest", image: "quay.io/user/hyperkube@sha256:abc", expectedErrs: 1},
+		{name: "whitespace", image: "quay.io/user/hyperkube:dev --v=10", expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateOperandImage(scenario.image, field.NewPath("operandImage"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateNonRoot(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       *NonRootConfig
+		expectedErrs int
+	}{
+		{name: "unset"},
+		{name: "uid", config: &NonRootConfig{UID: 1001}},
+		{name: "root", config: &NonRootConfig{UID: 0}, expectedErrs: 1},
+		{name: "negative", config: &NonRootConfig{UID: -1}, expectedErrs: 1},
+		{name: "out of range", config: &NonRootConfig{UID: 1 << 31}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateNonRoot(scenario.config, field.NewPath("nonRoot"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateSecurityContext(t *testing.T) {
+	profile := func(profileType corev1.SeccompProfileType, localhostProfile string) *corev1.SeccompProfile {
+		profile := &corev1.SeccompProfile{Type: profileType}
+		if len(localhostProfile) > 0 {
+			profile.LocalhostProfile = &localhostProfile
+		}
+		return profile
+	}
+
+	scenarios := []struct {
+		name         string
+		config       SecurityContextConfig
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{name: "runtime default", config: SecurityContextConfig{SeccompProfile: profile(corev1.SeccompProfileTypeRuntimeDefault, "")}},
+		{name: "localhost", config: SecurityContextConfig{SeccompProfile: profile(corev1.SeccompProfileTypeLocalhost, "profiles/kube-apiserver.json")}},
+		{name: "localhost without profile", config: SecurityContextConfig{SeccompProfile: profile(corev1.SeccompProfileTypeLocalhost, "")}, expectedErrs: 1},
+		{name: "localhost escaping the seccomp directory", config: SecurityContextConfig{SeccompProfile: profile(corev1.SeccompProfileTypeLocalhost, "../kube-apiserver.json")}, expectedErrs: 1},
+		{name: "profile without localhost", config: SecurityContextConfig{SeccompProfile: profile(corev1.SeccompProfileTypeRuntimeDefault, "kube-apiserver.json")}, expectedErrs: 1},
+		{name: "unknown type", config: SecurityContextConfig{SeccompProfile: profile("Strict", "")}, expectedErrs: 1},
+		{name: "selinux", config: SecurityContextConfig{SELinuxOptions: &corev1.SELinuxOptions{Type: "spc_t", Level: "s0:c123,c456"}}},
+		{name: "selinux level range", config: SecurityContextConfig{SELinuxOptions: &corev1.SELinuxOptions{Level: "s0-s0:c0.c1023"}}},
+		{name: "invalid selinux", config: SecurityContextConfig{SELinuxOptions: &corev1.SELinuxOptions{User: "system_u:object_r", Level: "c123"}}, expectedErrs: 2},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateSecurityContext(scenario.config, field.NewPath("securityContext"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateInsecureReadyz(t *testing.T) {
+	port := func(port int32) *int32 { return &port }
+
+	scenarios := []struct {
+		name         string
+		config       InsecureReadyzConfig
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "port", config: InsecureReadyzConfig{Port: port(16080)}},
+		{name: "default port", config: InsecureReadyzConfig{Port: port(6080)}},
+		{name: "disabled", config: InsecureReadyzConfig{Disabled: true}},
+		{name: "disabled with port", config: InsecureReadyzConfig{Port: port(16080), Disabled: true}, expectedErrs: 1},
+		{name: "privileged port", config: InsecureReadyzConfig{Port: port(80)}, expectedErrs: 1},
+		{name: "kube-apiserver port", config: InsecureReadyzConfig{Port: port(6443)}, expectedErrs: 1},
+		{name: "check-endpoints port", config: InsecureReadyzConfig{Port: port(17697)}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateInsecureReadyz(scenario.config, field.NewPath("insecureReadyz"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateStartupMonitor(t *testing.T) {
+	threshold := func(threshold int32) *int32 { return &threshold }
+
+	scenarios := []struct {
+		name         string
+		config       StartupMonitorConfig
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "enabled", config: StartupMonitorConfig{Mode: StartupMonitorEnabled, FallbackTimeout: "10m", ReadyzSuccessThreshold: threshold(5), ReadyzInterval: "10s"}},
+		{name: "disabled", config: StartupMonitorConfig{Mode: StartupMonitorDisabled}},
+		{name: "unknown mode", config: StartupMonitorConfig{Mode: "Auto"}, expectedErrs: 1},
+		{name: "short fallback timeout", config: StartupMonitorConfig{FallbackTimeout: "30s"}, expectedErrs: 1},
+		{name: "invalid readyz checks", config: StartupMonitorConfig{ReadyzSuccessThreshold: threshold(0), ReadyzInterval: "5"}, expectedErrs: 2},
+		{name: "readyz checks longer than the fallback timeout", config: StartupMonitorConfig{FallbackTimeout: "2m", ReadyzSuccessThreshold: threshold(4), ReadyzInterval: "30s"}, expectedErrs: 1},
+		{name: "crash loop threshold", config: StartupMonitorConfig{Mode: StartupMonitorEnabled, CrashLoopThreshold: threshold(3)}},
+		{name: "invalid crash loop threshold", config: StartupMonitorConfig{CrashLoopThreshold: threshold(0)}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateStartupMonitor(scenario.config, field.NewPath("startupMonitor"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateInstaller(t *testing.T) {
+	attempts := func(attempts int32) *int32 { return &attempts }
+
+	scenarios := []struct {
+		name         string
+		config       InstallerConfig
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "retry policy", config: InstallerConfig{MaxAttempts: attempts(5), Timeout: "5m", RetryBackoff: &InstallerRetryBackoff{InitialDelay: "1m", MaxDelay: "30m"}}},
+		{name: "initial delay only", config: InstallerConfig{RetryBackoff: &InstallerRetryBackoff{InitialDelay: "1m"}}},
+		{name: "no attempts", config: InstallerConfig{MaxAttempts: attempts(0)}, expectedErrs: 1},
+		{name: "short timeout", config: InstallerConfig{Timeout: "5s"}, expectedErrs: 1},
+		{name: "pod ready timeout", config: InstallerConfig{PodReadyTimeout: "10m"}},
+		{name: "short pod ready timeout", config: InstallerConfig{PodReadyTimeout: "30s"}, expectedErrs: 1},
+		{name: "retention", config: InstallerConfig{KeepRevisions: attempts(3), MinFreeDisk: "1Gi"}},
+		{name: "no kept revisions", config: InstallerConfig{KeepRevisions: attempts(0)}, expectedErrs: 1},
+		{name: "invalid min free disk", config: InstallerConfig{MinFreeDisk: "1 GB"}, expectedErrs: 1},
+		{name: "negative min free disk", config: InstallerConfig{MinFreeDisk: "-1Gi"}, expectedErrs: 1},
+		{name: "metrics textfile dir", config: InstallerConfig{MetricsTextfileDir: "/var/lib/node-exporter/textfile"}},
+		{name: "relative metrics textfile dir", config: InstallerConfig{MetricsTextfileDir: "textfile"}, expectedErrs: 1},
+		{name: "list fetch strategy", config: InstallerConfig{FetchStrategy: InstallerFetchList}},
+		{name: "unknown fetch strategy", config: InstallerConfig{FetchStrategy: "Watch"}, expectedErrs: 1},
+		{name: "missing initial delay", config: InstallerConfig{RetryBackoff: &InstallerRetryBackoff{MaxDelay: "30m"}}, expectedErrs: 1},
+		{name: "max delay shorter than the initial delay", config: InstallerConfig{RetryBackoff: &InstallerRetryBackoff{InitialDelay: "10m", MaxDelay: "1m"}}, expectedErrs: 1},
+		{name: "pod settings", config: InstallerConfig{
+			Resources:         &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("400M")}, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("400M")}},
+			PriorityClassName: "openshift-user-critical",
+			Tolerations:       []corev1.Toleration{{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		}},
+		{name: "limit below the request", config: InstallerConfig{Resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("400M")}, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("200M")}}}, expectedErrs: 1},
+		{name: "invalid priority class", config: InstallerConfig{PriorityClassName: "Critical"}, expectedErrs: 1},
+		{name: "invalid tolerations", config: InstallerConfig{Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpEqual, Value: "true"}, {Key: "dedicated", Operator: corev1.TolerationOpExists, Value: "infra", Effect: "Evict"}}}, expectedErrs: 3},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateInstaller(scenario.config, field.NewPath("installer"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateHostPathMounts(t *testing.T) {
+	kms := HostPathMount{Name: "kms", HostPath: "/var/run/kmsplugin", MountPath: "/var/run/kmsplugin", Type: corev1.HostPathDirectory}
+
+	scenarios := []struct {
+		name         string
+		mounts       []HostPathMount
+		expectedErrs int
+	}{
+		{name: "none"},
+		{name: "kms plugin socket", mounts: []HostPathMount{kms}},
+		{name: "hardware token", mounts: []HostPathMount{kms, {Name: "token", HostPath: "/dev-tokens/hsm0", MountPath: "/var/run/hsm0", Type: corev1.HostPathCharDev}}},
+		{name: "duplicate name", mounts: []HostPathMount{kms, {Name: "kms", HostPath: "/var/run/other", MountPath: "/var/run/other"}}, expectedErrs: 1},
+		{name: "static pod volume", mounts: []HostPathMount{{Name: "cert-dir", HostPath: "/var/run/kmsplugin", MountPath: "/var/run/kmsplugin"}}, expectedErrs: 1},
+		{name: "invalid name", mounts: []HostPathMount{{Name: "KMS", HostPath: "/var/run/kmsplugin", MountPath: "/var/run/kmsplugin"}}, expectedErrs: 1},
+		{name: "relative paths", mounts: []HostPathMount{{Name: "kms", HostPath: "var/run/kmsplugin", MountPath: "kmsplugin"}}, expectedErrs: 2},
+		{name: "unclean host path", mounts: []HostPathMount{{Name: "kms", HostPath: "/var/run/../lib/kubelet", MountPath: "/var/run/kmsplugin"}}, expectedErrs: 1},
+		{name: "top level directory", mounts: []HostPathMount{{Name: "etc", HostPath: "/etc", MountPath: "/host-etc/etc"}}, expectedErrs: 1},
+		{name: "managed host path", mounts: []HostPathMount{{Name: "certs", HostPath: "/etc/kubernetes/static-pod-resources", MountPath: "/var/run/certs"}}, expectedErrs: 1},
+		{name: "parent of a managed host path", mounts: []HostPathMount{{Name: "lib", HostPath: "/var/lib", MountPath: "/var/run/lib"}}, expectedErrs: 1},
+		{name: "shadows a mount of the container", mounts: []HostPathMount{{Name: "kms", HostPath: "/var/run/kmsplugin", MountPath: "/etc/kubernetes/static-pod-certs/kms"}}, expectedErrs: 1},
+		{name: "overlapping mount paths", mounts: []HostPathMount{kms, {Name: "socket", HostPath: "/var/run/socket", MountPath: "/var/run/kmsplugin/socket"}}, expectedErrs: 1},
+		{name: "unsupported type", mounts: []HostPathMount{{Name: "kms", HostPath: "/var/run/kmsplugin", MountPath: "/var/run/kmsplugin", Type: corev1.HostPathDirectoryOrCreate}}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateHostPathMounts(scenario.mounts, StaticPodVolumes, field.NewPath("hostPathMounts"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateRollout(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       RolloutConfig
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "serial", config: RolloutConfig{Strategy: RolloutSerial}},
+		{name: "canary", config: RolloutConfig{Strategy: RolloutCanary, CanarySoakPeriod: "15m"}},
+		{name: "unknown strategy", config: RolloutConfig{Strategy: "BlueGreen"}, expectedErrs: 1},
+		{name: "soak period without canary", config: RolloutConfig{CanarySoakPeriod: "15m"}, expectedErrs: 1},
+		{name: "short soak period", config: RolloutConfig{Strategy: RolloutCanary, CanarySoakPeriod: "10s"}, expectedErrs: 1},
+		{name: "node gates", config: RolloutConfig{NodeGates: []NodeGate{{ConditionType: "MaintenanceReady"}, {Annotation: "example.com/etcd-learner-promoted", Value: "true"}}}},
+		{name: "empty node gate", config: RolloutConfig{NodeGates: []NodeGate{{}}}, expectedErrs: 1},
+		{name: "node gate with condition and annotation", config: RolloutConfig{NodeGates: []NodeGate{{ConditionType: "MaintenanceReady", Annotation: "example.com/drained"}}}, expectedErrs: 1},
+		{name: "node gate condition with value", config: RolloutConfig{NodeGates: []NodeGate{{ConditionType: "MaintenanceReady", Value: "true"}}}, expectedErrs: 1},
+		{name: "invalid node gate annotation", config: RolloutConfig{NodeGates: []NodeGate{{Annotation: "example.com/not a key"}}}, expectedErrs: 1},
+		{name: "maintenance windows", config: RolloutConfig{MaintenanceWindows: []MaintenanceWindow{{Schedule: "0 22 * * 1-5", Duration: "4h"}, {Schedule: "@weekly", Duration: "24h"}}}},
+		{name: "invalid maintenance window schedule", config: RolloutConfig{MaintenanceWindows: []MaintenanceWindow{{Schedule: "0 25 * * *", Duration: "4h"}}}, expectedErrs: 1},
+		{name: "maintenance window interval", config: RolloutConfig{MaintenanceWindows: []MaintenanceWindow{{Schedule: "@every 6h", Duration: "1h"}}}, expectedErrs: 1},
+		{name: "empty maintenance window", config: RolloutConfig{MaintenanceWindows: []MaintenanceWindow{{}}}, expectedErrs: 2},
+		{name: "short maintenance window", config: RolloutConfig{MaintenanceWindows: []MaintenanceWindow{{Schedule: "@daily", Duration: "5m"}}}, expectedErrs: 1},
+		{name: "skip preflight checks", config: RolloutConfig{SkipPreflightChecks: []string{"EtcdQuorum", "Manifest"}}},
+		{name: "skip unknown preflight check", config: RolloutConfig{SkipPreflightChecks: []string{"Etcd"}}, expectedErrs: 1},
+		{name: "excluded node", config: RolloutConfig{ExcludedNodes: []NodeExclusion{{NodeName: "master-2", Reason: "disk replacement", Duration: "8h"}}}},
+		{name: "excluded node without name", config: RolloutConfig{ExcludedNodes: []NodeExclusion{{Duration: "8h"}}}, expectedErrs: 1},
+		{name: "node excluded twice", config: RolloutConfig{ExcludedNodes: []NodeExclusion{{NodeName: "master-2"}, {NodeName: "master-2"}}}, expectedErrs: 1},
+		{name: "long node exclusion", config: RolloutConfig{ExcludedNodes: []NodeExclusion{{NodeName: "master-2", Duration: "720h"}}}, expectedErrs: 1},
+		{name: "delay between nodes", config: RolloutConfig{DelayBetweenNodes: "10m"}},
+		{name: "long delay between nodes", config: RolloutConfig{DelayBetweenNodes: "3h"}, expectedErrs: 1},
+		{name: "negative delay between nodes", config: RolloutConfig{DelayBetweenNodes: "-1m"}, expectedErrs: 1},
+		{name: "alphabetical node order", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: NodeOrderAlphabetical}}},
+		{name: "explicit node order", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: NodeOrderExplicit, Nodes: []string{"master-2", "master-0"}}}},
+		{name: "unknown node order", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: "Random"}}, expectedErrs: 1},
+		{name: "explicit node order without nodes", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: NodeOrderExplicit}}, expectedErrs: 1},
+		{name: "nodes without explicit node order", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: NodeOrderLeaderLast, Nodes: []string{"master-0"}}}, expectedErrs: 1},
+		{name: "node ordered twice", config: RolloutConfig{NodeOrder: &NodeOrderConfig{Type: NodeOrderExplicit, Nodes: []string{"master-0", "master-0"}}}, expectedErrs: 1},
+		{name: "single node fast path", config: RolloutConfig{SingleNodeFastPath: true}},
+		{name: "single node fast path with canary", config: RolloutConfig{Strategy: RolloutCanary, SingleNodeFastPath: true}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateRollout(scenario.config, field.NewPath("rollout"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateStaticPodDetection(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       StaticPodDetectionConfig
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "slow bare metal", config: StaticPodDetectionConfig{MissingPodTimeout: "15m", FailingPodTimeout: "10m"}},
+		{name: "immediate failing pods", config: StaticPodDetectionConfig{FailingPodTimeout: "0s"}},
+		{name: "short missing pod timeout", config: StaticPodDetectionConfig{MissingPodTimeout: "10s"}, expectedErrs: 1},
+		{name: "long failing pod timeout", config: StaticPodDetectionConfig{FailingPodTimeout: "2h"}, expectedErrs: 1},
+		{name: "invalid duration", config: StaticPodDetectionConfig{MissingPodTimeout: "5"}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateStaticPodDetection(scenario.config, field.NewPath("staticPodDetection"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateConnectivityChecks(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		targets      []ConnectivityCheckTarget
+		classes      []ConnectivityCheckTargetClass
+		disabled     []string
+		retention    *ConnectivityCheckRetention
+		successLog   string
+		expectedErrs int
+	}{
+		{name: "default"},
+		{name: "oidc provider", targets: []ConnectivityCheckTarget{{Name: "oidc-provider", Endpoint: "sso.example.com:443"}}},
+		{name: "kms with client cert", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "[fd00::5]:5696", TLSClientCert: "kms-client"}}},
+		{name: "missing name", targets: []ConnectivityCheckTarget{{Endpoint: "sso.example.com:443"}}, expectedErrs: 1},
+		{name: "invalid name", targets: []ConnectivityCheckTarget{{Name: "OIDC.provider", Endpoint: "sso.example.com:443"}}, expectedErrs: 1},
+		{name: "duplicate name", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5:5696"}, {Name: "kms", Endpoint: "10.0.0.6:5696"}}, expectedErrs: 1},
+		{name: "missing endpoint", targets: []ConnectivityCheckTarget{{Name: "kms"}}, expectedErrs: 1},
+		{name: "missing port", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5"}}, expectedErrs: 1},
+		{name: "missing host", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: ":5696"}}, expectedErrs: 1},
+		{name: "invalid port", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5:70000"}}, expectedErrs: 1},
+		{name: "invalid client cert", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5:5696", TLSClientCert: "KMS_client"}}, expectedErrs: 1},
+		{name: "target settings", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5:5696", ConnectivityCheckSettings: ConnectivityCheckSettings{Interval: "1m", Timeout: "5s"}}}},
+		{name: "invalid target settings", targets: []ConnectivityCheckTarget{{Name: "kms", Endpoint: "10.0.0.5:5696", ConnectivityCheckSettings: ConnectivityCheckSettings{Interval: "100ms", Timeout: "5m"}}}, expectedErrs: 2},
+		{name: "class settings", classes: []ConnectivityCheckTargetClass{{Name: "load-balancer", ConnectivityCheckSettings: ConnectivityCheckSettings{Interval: "30s"}}, {Name: "additional", ConnectivityCheckSettings: ConnectivityCheckSettings{Timeout: "30s"}}}},
+		{name: "unknown class", classes: []ConnectivityCheckTargetClass{{Name: "kms", ConnectivityCheckSettings: ConnectivityCheckSettings{Interval: "30s"}}}, expectedErrs: 1},
+		{name: "duplicate class", classes: []ConnectivityCheckTargetClass{{Name: "etcd-server"}, {Name: "etcd-server"}}, expectedErrs: 1},
+		{name: "success log interval", successLog: "5m"},
+		{name: "invalid success log interval", successLog: "5h", expectedErrs: 1},
+		{name: "retention", retention: &ConnectivityCheckRetention{MaxOutages: 5, MaxLogEntries: 3, MaxAge: "168h", OutageCompactionGap: "1m"}},
+		{name: "invalid retention", retention: &ConnectivityCheckRetention{MaxOutages: 50, MaxLogEntries: -1, MaxAge: "1m", OutageCompactionGap: "soon"}, expectedErrs: 4},
+		{name: "http get", classes: []ConnectivityCheckTargetClass{{Name: "openshift-apiserver-endpoint", ConnectivityCheckSettings: ConnectivityCheckSettings{HTTPGet: &ConnectivityCheckHTTPGet{Path: "/readyz"}}}}},
+		{name: "http get with scheme and status codes", targets: []ConnectivityCheckTarget{{Name: "oidc", Endpoint: "sso.example.com:80", ConnectivityCheckSettings: ConnectivityCheckSettings{HTTPGet: &ConnectivityCheckHTTPGet{Path: "/healthz?verbose", Scheme: "HTTP", ExpectedStatusCodes: []int{200, 204}}}}}},
+		{name: "invalid http get", targets: []ConnectivityCheckTarget{{Name: "oidc", Endpoint: "sso.example.com:80", ConnectivityCheckSettings: ConnectivityCheckSettings{HTTPGet: &ConnectivityCheckHTTPGet{Path: "healthz", Scheme: "TCP", ExpectedStatusCodes: []int{200, 999}}}}}, expectedErrs: 3},
+		{name: "disabled targets", disabled: []string{"load-balancer-api-external", "openshift-apiserver-service"}},
+		{name: "invalid disabled targets", disabled: []string{"api-external", "load-balancer", "load-balancer"}, expectedErrs: 2},
+		{name: "invalid class interval", classes: []ConnectivityCheckTargetClass{{Name: "etcd-server", ConnectivityCheckSettings: ConnectivityCheckSettings{Interval: "often"}}}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateConnectivityChecks(ConnectivityChecksConfig{AdditionalTargets: scenario.targets, TargetClasses: scenario.classes, DisabledTargets: scenario.disabled, Retention: scenario.retention, SuccessLogInterval: scenario.successLog}, field.NewPath("connectivityChecks"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func newConfigMap(config string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: ConfigMapName},
+		Data:       map[string]string{ConfigKey: config},
+	}
+}
+
+func TestKubeAPIServerFlagsKnowDefaultConfig(t *testing.T) {
+	for _, asset := range []string{"assets/config/defaultconfig.yaml", "assets/config/config-overrides.yaml"} {
+		config := &kubecontrolplanev1.KubeAPIServerConfig{}
+		if err := yaml.Unmarshal(bindata.MustAsset(asset), config); err != nil {
+			t.Fatal(err)
+		}
+		for name := range config.APIServerArguments {
+			if _, ok := KubeAPIServerFlags[name]; !ok {
+				t.Errorf("%s: flag %q is missing from KubeAPIServerFlags", asset, name)
+			}
+		}
+	}
+}
+
+func TestValidateOperatorTuning(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		config       OperatorTuningConfig
+		expectedErrs int
+	}{
+		{name: "empty"},
+		{
+			name: "single node",
+			config: OperatorTuningConfig{
+				LeaderElection:  OperatorLeaderElectionConfig{LeaseDuration: "270s", RenewDeadline: "240s", RetryPeriod: "60s"},
+				ResyncIntervals: map[string]string{"TargetConfigController": "5m"},
+			},
+		},
+		{name: "lease duration too short", config: OperatorTuningConfig{LeaderElection: OperatorLeaderElectionConfig{LeaseDuration: "5s"}}, expectedErrs: 1},
+		{name: "renew deadline not shorter than the default lease duration", config: OperatorTuningConfig{LeaderElection: OperatorLeaderElectionConfig{RenewDeadline: "137s"}}, expectedErrs: 1},
+		{name: "retry period not shorter than the renew deadline", config: OperatorTuningConfig{LeaderElection: OperatorLeaderElectionConfig{RenewDeadline: "20s", RetryPeriod: "20s"}}, expectedErrs: 1},
+		{name: "resync interval too short", config: OperatorTuningConfig{ResyncIntervals: map[string]string{"TargetConfigController": "1s"}}, expectedErrs: 1},
+		{name: "resync interval not a duration", config: OperatorTuningConfig{ResyncIntervals: map[string]string{"TargetConfigController": "5"}}, expectedErrs: 1},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			errs := ValidateOperatorTuning(scenario.config, field.NewPath("operator"))
+			if len(errs) != scenario.expectedErrs {
+				t.Fatalf("expected %d errors, got %v", scenario.expectedErrs, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,1010 @@
+package operatorconfig
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// KubeAPIServerOperatorConfig holds the supported kube-apiserver settings that are not part of the
+// operator.openshift.io/v1 KubeAPIServer API. It is read from the config.yaml key of the
+// openshift-config/kube-apiserver-config configmap. Every field is optional, an empty value means
+// the operator defaults are used.
+type KubeAPIServerOperatorConfig struct {
+	// runtimeConfig lists alpha and beta API group versions to enable in addition to the default ones,
+	// e.g. "flowcontrol.apiserver.k8s.io/v1alpha1". Only group versions on the allowlist are accepted.
+	// Enabling any of them marks the cluster as not upgradeable.
+	RuntimeConfig []string `json:"runtimeConfig,omitempty"`
+
+	// kubeletPreferredAddressTypes is the ordered list of node address types the kube-apiserver uses to reach
+	// kubelets for logs, exec and port-forward. Valid values are Hostname, InternalDNS, InternalIP, ExternalDNS
+	// and ExternalIP. Defaults to InternalIP only, which is what the cluster proxy settings are built for.
+	KubeletPreferredAddressTypes []string `json:"kubeletPreferredAddressTypes,omitempty"`
+
+	// anonymousAuth controls whether anonymous requests to the kube-apiserver are allowed. Valid values are
+	// Enabled and Disabled, defaults to Enabled. Disabled is only honoured once the cluster finished bootstrapping
+	// and on platforms whose load balancers do not health check the kube-apiserver anonymously.
+	AnonymousAuth AnonymousAuthMode `json:"anonymousAuth,omitempty"`
+
+	// additionalAPIAudiences are accepted by the kube-apiserver for bound service account tokens in addition to
+	// the service account issuer, e.g. for external vaults or service meshes that request tokens for their own
+	// audience. The issuer is always the first audience.
+	AdditionalAPIAudiences []string `json:"additionalAPIAudiences,omitempty"`
+
+	// serviceAccountSigningKey replaces the keypair generated by the operator to sign bound service account tokens with
+	// the keypair of a secret, e.g. one that is shared by the clusters behind the same issuer or one that is escrowed.
+	// A new keypair only signs tokens once its public key is on all nodes, like a rotation of the generated keypair.
+	// Removing it rotates back to a generated keypair the same way.
+	ServiceAccountSigningKey *ServiceAccountSigningKeyConfig `json:"serviceAccountSigningKey,omitempty"`
+
+	// serviceAccountSigningKeyRotation rotates the keypair generated by the operator to sign bound service account
+	// tokens. A rotation distributes the new public key to all nodes first and only signs with the new private key
+	// once it was accepted everywhere for the propagation delay, the previous public keys stay accepted.
+	ServiceAccountSigningKeyRotation ServiceAccountSigningKeyRotationConfig `json:"serviceAccountSigningKeyRotation,omitempty"`
+
+	// oidcDiscovery publishes the OIDC discovery document and the JWKS of the service account issuer for consumers
+	// outside of the cluster, e.g. the IAM of a cloud that trusts the bound service account tokens of the cluster. The
+	// documents follow every rotation of the signing keys.
+	OIDCDiscovery *OIDCDiscoveryConfig `json:"oidcDiscovery,omitempty"`
+
+	// featureGates acknowledges the migration of the data of tech preview feature gates, so that they do not block
+	// updates. Without it, the cluster is not upgradeable while a feature gate is enabled that stores data its update
+	// could not handle.
+	FeatureGates FeatureGatesConfig `json:"featureGates,omitempty"`
+
+	// kubeletVersionSkew configures how kubelets whose minor version is too far behind the kube-apiserver are handled,
+	// e.g. for edge fleets whose nodes are updated slower than the control plane.
+	KubeletVersionSkew KubeletVersionSkewConfig `json:"kubeletVersionSkew,omitempty"`
+
+	// resourceSync copies additional configmaps and secrets from openshift-config to openshift-kube-apiserver, e.g.
+	// CA bundles or credentials for sidecars, and keeps them in sync. Removing a rule deletes its copy.
+	ResourceSync ResourceSyncConfig `json:"resourceSync,omitempty"`
+
+	// etcd tunes how the kube-apiserver checks and maintains its etcd storage, e.g. for slower disks or a remote etcd.
+	Etcd EtcdConfig `json:"etcd,omitempty"`
+
+	// apiServerArguments overrides arbitrary kube-apiserver flags, keyed by flag name without leading dashes.
+	// This is tech preview, it is only honoured with the TechPreviewNoUpgrade feature set. Unlike
+	// unsupportedConfigOverrides the flags are checked against the flags of the kube-apiserver of this release,
+	// flags managed by the operator are rejected and the applied overrides are recorded in the operator status.
+	APIServerArguments map[string][]string `json:"apiServerArguments,omitempty"`
+
+	// operandImage replaces the kube-apiserver image of the static pod, e.g. with a development build. This is tech
+	// preview, it is only honoured with the TechPreviewNoUpgrade feature set, and the cluster cannot be upgraded
+	// while it is set.
+	OperandImage string `json:"operandImage,omitempty"`
+
+	// logging sets the log verbosity per container of the kube-apiserver static pod.
+	Logging LoggingConfig `json:"logging,omitempty"`
+
+	// resources sets the cpu and memory requests and limits of the containers of the kube-apiserver static pod,
+	// keyed by container name. A listed container replaces the topology defaults of the operator for that
+	// container, resources that are not set keep the values of the pod template. Memory limits on the
+	// kube-apiserver container risk OOM kills under load and should only be used for small clusters.
+	Resources map[string]corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// reservedCPUs is the cpuset, e.g. "0-1,52-53", the kube-apiserver static pod is pinned to on SingleReplica
+	// control planes. It must match the reserved cpus of the performance profile so that the kube-apiserver stays
+	// off the isolated cpus. It is ignored on other topologies.
+	ReservedCPUs string `json:"reservedCPUs,omitempty"`
+
+	// sidecars are added to the kube-apiserver static pod, e.g. to ship the audit logs of the audit-dir volume.
+	Sidecars SidecarConfig `json:"sidecars,omitempty"`
+
+	// probes tunes the probes of the kube-apiserver container for slow hardware and nested virtualization.
+	Probes ProbesConfig `json:"probes,omitempty"`
+
+	// advertiseAddressSubnets selects the address the kube-apiserver advertises to the cluster on nodes with
+	// multiple interfaces. Every kube-apiserver advertises the first address of its node within the first of these
+	// CIDRs that matches one. Without a match, or by default, the node IP reported by the kubelet is advertised.
+	AdvertiseAddressSubnets []string `json:"advertiseAddressSubnets,omitempty"`
+
+	// environment sets Go runtime environment variables of the kube-apiserver container for performance tuning and
+	// debugging. Only the variables in AllowedEnvironment are accepted.
+	Environment map[string]string `json:"environment,omitempty"`
+
+	// auditLog sets the rotation and retention of the audit log. Unset values keep the defaults of the audit
+	// profile of apiserver/cluster.
+	AuditLog AuditLogConfig `json:"auditLog,omitempty"`
+
+	// auditWebhook sends the audit events to a webhook in addition to the audit log, e.g. to stream them to a SIEM.
+	AuditWebhook *AuditWebhookConfig `json:"auditWebhook,omitempty"`
+
+	// auditForwarder adds a sidecar to the kube-apiserver static pod that forwards the audit log of the node to a
+	// remote endpoint.
+	AuditForwarder *AuditForwarderConfig `json:"auditForwarder,omitempty"`
+
+	// auditPolicy extends the audit policy of the profile of apiserver/cluster with custom rules.
+	AuditPolicy AuditPolicyConfig `json:"auditPolicy,omitempty"`
+
+	// shutdownDelayDuration is how long a terminating kube-apiserver keeps serving so that load balancers can take
+	// it out of rotation, e.g. "90s". The graceful termination of the static pod is derived from it, it adds 60s for
+	// in-flight requests and 5s for the process to exit. Defaults to 70s, 210s on AWS and 0s on single node.
+	ShutdownDelayDuration string `json:"shutdownDelayDuration,omitempty"`
+
+	// nonRoot runs the kube-apiserver container and the sidecars of the static pod as a non-root user. This is tech
+	// preview, it is only honoured with the TechPreviewNoUpgrade feature set.
+	NonRoot *NonRootConfig `json:"nonRoot,omitempty"`
+
+	// securityContext sets the seccomp profile and the SELinux options of the kube-apiserver static pod, they apply
+	// to the kube-apiserver container and all sidecars.
+	SecurityContext SecurityContextConfig `json:"securityContext,omitempty"`
+
+	// profiling controls whether the kube-apiserver and the check-endpoints sidecar serve the pprof and debug flag
+	// endpoints. Valid values are Enabled and Disabled, defaults to Enabled. CIS benchmarks require Disabled.
+	Profiling ProfilingMode `json:"profiling,omitempty"`
+
+	// insecureReadyz configures the kube-apiserver-insecure-readyz sidecar, which serves /readyz and /livez of the
+	// kube-apiserver over plain HTTP for load balancers that cannot health check over TLS.
+	InsecureReadyz InsecureReadyzConfig `json:"insecureReadyz,omitempty"`
+
+	// startupMonitor configures the startup monitor. It watches every new revision of the static pod and falls back to
+	// the last known good revision when the new one does not become ready in time.
+	StartupMonitor StartupMonitorConfig `json:"startupMonitor,omitempty"`
+
+	// hostPathMounts mounts host paths of the control plane nodes read-only into the kube-apiserver container, e.g.
+	// the socket directory of a KMS plugin or the device of a hardware token.
+	HostPathMounts []HostPathMount `json:"hostPathMounts,omitempty"`
+
+	// rollout configures how new revisions of the static pod are rolled out to the control plane nodes.
+	Rollout RolloutConfig `json:"rollout,omitempty"`
+
+	// installer configures the installer pods that install new revisions on the control plane nodes.
+	Installer InstallerConfig `json:"installer,omitempty"`
+
+	// staticPodDetection configures how long the static pods of the kube-apiserver may be missing or failing before
+	// the operator reports them degraded.
+	StaticPodDetection StaticPodDetectionConfig `json:"staticPodDetection,omitempty"`
+
+	// connectivityChecks configures the connectivity checks of the check-endpoints sidecar of every kube-apiserver.
+	ConnectivityChecks ConnectivityChecksConfig `json:"connectivityChecks,omitempty"`
+
+	// operator tunes the leader election and the resyncs of the operator itself, e.g. on single node control planes
+	// where the defaults cause needless failovers or resyncs. It is read when the operator starts.
+	Operator OperatorTuningConfig `json:"operator,omitempty"`
+}
+
+// ServiceAccountSigningKeyConfig references the keypair that signs bound service account tokens.
+type ServiceAccountSigningKeyConfig struct {
+	// secretName is the name of a secret in openshift-config. Its service-account.key key holds the PEM encoded RSA
+	// private key of at least 2048 bits and its service-account.pub key the PEM encoded public key. The public key may
+	// be followed by further public keys whose tokens are accepted as well, e.g. of a previous keypair of the issuer.
+	SecretName string `json:"secretName"`
+}
+
+// ServiceAccountSigningKeyRotationConfig configures when the generated signing keypair is rotated. It does not apply
+// to the keypair of serviceAccountSigningKey.secretName.
+type ServiceAccountSigningKeyRotationConfig struct {
+	// interval rotates the keypair once it is older, e.g. "2160h". It must be at least 24h. Defaults to no periodic
+	// rotation.
+	Interval string `json:"interval,omitempty"`
+
+	// forceRotationReason rotates the keypair once whenever it changes, e.g. to the ID of the change request.
+	ForceRotationReason string `json:"forceRotationReason,omitempty"`
+
+	// propagationDelay is how long the public key of a new keypair has to be accepted by all kube-apiservers before
+	// the keypair signs tokens, so that projected tokens are refreshed and consumers that cache the JWKS of the issuer
+	// have picked the key up, e.g. "2h". Defaults to 1h for the rotations of interval and forceRotationReason, and to
+	// no delay for a keypair that is replaced otherwise. It can be at most 24h.
+	PropagationDelay string `json:"propagationDelay,omitempty"`
+}
+
+// OIDCDiscoveryConfig configures where the OIDC discovery document and the JWKS of the service account issuer are
+// published. They are always written to the oidc-discovery configmap in openshift-config-managed, from where an
+// external publisher can pick them up, and are uploaded to the endpoint when it is set. The issuer is the one of
+// authentication/cluster, it has to be the externally reachable URL the documents are served from.
+type OIDCDiscoveryConfig struct {
+	// jwksURI is the jwks_uri of the discovery document. Defaults to the issuer followed by /keys.json.
+	JWKSURI string `json:"jwksURI,omitempty"`
+
+	// endpoint uploads the documents with HTTP PUT whenever they change.
+	Endpoint *OIDCDiscoveryEndpoint `json:"endpoint,omitempty"`
+}
+
+// OIDCDiscoveryEndpoint is an HTTPS endpoint that accepts the documents with HTTP PUT, e.g. an S3 compatible bucket or
+// a web server.
+type OIDCDiscoveryEndpoint struct {
+	// url is the base URL of the documents. The discovery document is uploaded to <url>/.well-known/openid-configuration
+	// and the JWKS to <url>/keys.json.
+	URL string `json:"url"`
+
+	// headersSecretName is the name of a secret in openshift-config. Every key is an HTTP header sent with the uploads,
+	// e.g. Authorization.
+	HeadersSecretName string `json:"headersSecretName,omitempty"`
+
+	// caConfigMap is the name of a config map in openshift-config whose ca-bundle.crt key holds the CAs the
+	// certificate of the endpoint is verified with. Defaults to the system trust bundle.
+	CAConfigMap string `json:"caConfigMap,omitempty"`
+}
+
+// FeatureGatesConfig holds the acknowledgements of the feature gate analysis of the FeatureGatesUpgradeable condition.
+type FeatureGatesConfig struct {
+	// acknowledgedMigrations names the feature gates, e.g. "IPv6DualStack", whose data the cluster admin migrates
+	// before the gate is turned off or removed by an update, following the migration in the message of the
+	// FeatureGatesUpgradeable condition. Only gates whose data can be migrated can be acknowledged.
+	AcknowledgedMigrations []string `json:"acknowledgedMigrations,omitempty"`
+}
+
+// KubeletVersionSkewConfig holds the kubelet version skew policy and the nodes that are exempt from it.
+type KubeletVersionSkewConfig struct {
+	// policy is Strict or WarnOnly. Strict sets KubeletMinorVersionUpgradeable to false while a kubelet is not
+	// supported by the kube-apiserver or would not be by the next OpenShift minor version, which blocks the upgrade.
+	// WarnOnly reports the same nodes without blocking the upgrade. Defaults to Strict.
+	Policy KubeletVersionSkewPolicy `json:"policy,omitempty"`
+
+	// exemptNodes leaves nodes out of the policy until their exemption ends, e.g. while they wait for a maintenance
+	// window. Their skew is still reported.
+	ExemptNodes []KubeletVersionSkewExemption `json:"exemptNodes,omitempty"`
+}
+
+// KubeletVersionSkewPolicy is the value of the kubeletVersionSkew policy field.
+type KubeletVersionSkewPolicy string
+
+const (
+	// KubeletVersionSkewStrict blocks upgrades on kubelets that are or would become unsupported.
+	KubeletVersionSkewStrict KubeletVersionSkewPolicy = "Strict"
+	// KubeletVersionSkewWarnOnly reports kubelets that are or would become unsupported without blocking upgrades.
+	KubeletVersionSkewWarnOnly KubeletVersionSkewPolicy = "WarnOnly"
+)
+
+// KubeletVersionSkewExemption exempts a node from the kubelet version skew policy for a while.
+type KubeletVersionSkewExemption struct {
+	// node is the name of the node.
+	Node string `json:"node"`
+
+	// until is the RFC 3339 time the exemption ends, e.g. "2021-11-30T00:00:00Z".
+	Until string `json:"until"`
+
+	// reason tells why the node is exempt, e.g. the ID of the change request.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ResourceSyncConfig holds the sync rules of configmaps and secrets declared by the cluster admin.
+type ResourceSyncConfig struct {
+	// configMaps are the configmaps to sync.
+	ConfigMaps []ResourceSyncRule `json:"configMaps,omitempty"`
+
+	// secrets are the secrets to sync.
+	Secrets []ResourceSyncRule `json:"secrets,omitempty"`
+}
+
+// UserResourceSyncPrefix is the prefix of the names of the copies of the resource sync rules, so that they cannot
+// replace the configmaps and secrets the operator manages in openshift-kube-apiserver.
+const UserResourceSyncPrefix = "user-"
+
+// ResourceSyncRule copies a configmap or a secret of openshift-config to openshift-kube-apiserver. The copy follows the
+// changes of the source and is deleted with it.
+type ResourceSyncRule struct {
+	// source is the name of the configmap or secret in openshift-config.
+	Source string `json:"source"`
+
+	// destination is the name of the copy in openshift-kube-apiserver. It must start with "user-". Defaults to the
+	// source name with the "user-" prefix.
+	Destination string `json:"destination,omitempty"`
+
+	// keys limits the copy to these keys of the source. Defaults to all keys.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// DestinationName returns the name of the copy of the rule.
+func (r ResourceSyncRule) DestinationName() string {
+	if len(r.Destination) > 0 {
+		return r.Destination
+	}
+	return UserResourceSyncPrefix + r.Source
+}
+
+// OperatorTuningConfig holds the leader election durations and controller resync intervals of the operator. Every
+// value is a duration like "30s", an empty value keeps the default.
+type OperatorTuningConfig struct {
+	// leaderElection replaces the leader election durations of the operator, 137s, 107s and 26s by default.
+	LeaderElection OperatorLeaderElectionConfig `json:"leaderElection,omitempty"`
+
+	// resyncIntervals replaces how often controllers of the operator sync without a change, keyed by the controller
+	// name in the controller label of the sync metrics, between 10s and 1h.
+	ResyncIntervals map[string]string `json:"resyncIntervals,omitempty"`
+}
+
+// OperatorLeaderElectionConfig holds the leader election durations of the operator. The renew deadline has to be
+// shorter than the lease duration, and the retry period shorter than the renew deadline.
+type OperatorLeaderElectionConfig struct {
+	// leaseDuration is how long the other operator pods wait before they take over a lease that was not renewed,
+	// between 15s and 10m.
+	LeaseDuration string `json:"leaseDuration,omitempty"`
+	// renewDeadline is how long the leader retries to renew its lease before it gives up leading, between 10s and 10m.
+	RenewDeadline string `json:"renewDeadline,omitempty"`
+	// retryPeriod is how often the lease is tried to be acquired or renewed, between 2s and 2m.
+	RetryPeriod string `json:"retryPeriod,omitempty"`
+}
+
+// ConnectivityChecksConfig holds the connectivity check targets in addition to the ones the operator detects, etcd,
+// the openshift-apiserver and the api load balancers.
+type ConnectivityChecksConfig struct {
+	// additionalTargets are checked from every kube-apiserver like the detected targets, with outages recorded in the
+	// PodNetworkConnectivityCheck of the target, e.g. for an external OIDC provider, an audit webhook or a KMS.
+	AdditionalTargets []ConnectivityCheckTarget `json:"additionalTargets,omitempty"`
+
+	// targetClasses change the interval and the timeout of the checks of all targets of a class, e.g. to check the
+	// load balancers less often than etcd. The settings of an additional target take precedence over its class.
+	TargetClasses []ConnectivityCheckTargetClass `json:"targetClasses,omitempty"`
+
+	// disabledTargets stops checking targets that are unreachable on purpose, e.g. the api-external load balancer
+	// that is firewalled from the masters of a private cluster. An entry is a target class, e.g. "load-balancer", or a
+	// target as in the names of the checks, e.g. "load-balancer-api-external", which disables its DNS and IP family
+	// checks as well.
+	DisabledTargets []string `json:"disabledTargets,omitempty"`
+
+	// successLogInterval is the minimum time between two successes of the same kind that are recorded in the status of
+	// a PodNetworkConnectivityCheck while its target stays reachable, e.g. "5m". The status of reachable targets is
+	// then written once per interval, the pod_network_connectivity_check metrics have the result of every check.
+	// Defaults to recording every success.
+	SuccessLogInterval string `json:"successLogInterval,omitempty"`
+
+	// retention limits the history kept in the status of every PodNetworkConnectivityCheck. By default the latest 20
+	// outages and 10 successes and failures are kept.
+	Retention *ConnectivityCheckRetention `json:"retention,omitempty"`
+}
+
+// ConnectivityCheckRetention limits the outages and log entries in the status of the PodNetworkConnectivityChecks.
+type ConnectivityCheckRetention struct {
+	// maxOutages is the number of outages kept, between 1 and 20.
+	MaxOutages int `json:"maxOutages,omitempty"`
+
+	// maxLogEntries is the number of successes and failures kept each, between 1 and 10.
+	MaxLogEntries int `json:"maxLogEntries,omitempty"`
+
+	// maxAge prunes the outages that ended and the successes and failures that happened longer ago, e.g. "168h".
+	MaxAge string `json:"maxAge,omitempty"`
+
+	// outageCompactionGap merges an outage into the previous one if it started less than this after the previous one
+	// ended, e.g. "1m", so that a flapping target has one long outage instead of many short ones.
+	OutageCompactionGap string `json:"outageCompactionGap,omitempty"`
+}
+
+// Connectivity check target classes, the targets the operator detects and the additional targets.
+const (
+	ConnectivityCheckTargetClassEtcdServer                 = "etcd-server"
+	ConnectivityCheckTargetClassOpenShiftAPIServerService  = "openshift-apiserver-service"
+	ConnectivityCheckTargetClassOpenShiftAPIServerEndpoint = "openshift-apiserver-endpoint"
+	ConnectivityCheckTargetClassLoadBalancer               = "load-balancer"
+	ConnectivityCheckTargetClassAdditional                 = "additional"
+)
+
+// ConnectivityCheckTargetClasses are the valid names of target classes.
+var ConnectivityCheckTargetClasses = []string{
+	ConnectivityCheckTargetClassEtcdServer,
+	ConnectivityCheckTargetClassOpenShiftAPIServerService,
+	ConnectivityCheckTargetClassOpenShiftAPIServerEndpoint,
+	ConnectivityCheckTargetClassLoadBalancer,
+	ConnectivityCheckTargetClassAdditional,
+}
+
+// ConnectivityCheckTargetClass are the settings of the checks of a class of targets.
+type ConnectivityCheckTargetClass struct {
+	// name is one of etcd-server, openshift-apiserver-service, openshift-apiserver-endpoint, load-balancer or
+	// additional.
+	Name string `json:"name"`
+
+	ConnectivityCheckSettings `json:",inline"`
+}
+
+// ConnectivityCheckSettings tell how often and how long the check-endpoints sidecar checks a target.
+type ConnectivityCheckSettings struct {
+	// interval is the time between two checks of the target, e.g. "30s". Defaults to 1s.
+	Interval string `json:"interval,omitempty"`
+
+	// timeout is how long a check waits for the connection or the DNS lookup, e.g. "5s". Defaults to 10s.
+	Timeout string `json:"timeout,omitempty"`
+
+	// httpGet sends an HTTP GET request after the TCP connection is established and only counts the check as a
+	// success if the response has an expected status code, e.g. for the /readyz of the openshift-apiserver endpoints.
+	// A reachable target that responds with errors is an outage then.
+	HTTPGet *ConnectivityCheckHTTPGet `json:"httpGet,omitempty"`
+}
+
+// ConnectivityCheckHTTPGet is the HTTP GET request of a check.
+type ConnectivityCheckHTTPGet struct {
+	// path is the path of the request, e.g. "/readyz".
+	Path string `json:"path"`
+
+	// scheme is HTTP or HTTPS. Defaults to HTTPS, the certificate of the target is not verified.
+	Scheme string `json:"scheme,omitempty"`
+
+	// expectedStatusCodes are the status codes of a successful response, e.g. [200]. Defaults to any status code
+	// between 200 and 399.
+	ExpectedStatusCodes []int `json:"expectedStatusCodes,omitempty"`
+}
+
+// ConnectivityCheckTarget is an endpoint the check-endpoints sidecar connects to.
+type ConnectivityCheckTarget struct {
+	// name identifies the target in the name of its PodNetworkConnectivityCheck, e.g. "oidc-provider".
+	Name string `json:"name"`
+
+	// endpoint is the host:port the sidecar opens a TCP connection to, e.g. "sso.example.com:443". A TLS handshake
+	// follows on endpoints that speak TLS, its errors are ignored.
+	Endpoint string `json:"endpoint"`
+
+	// tlsClientCert is the name of a kubernetes.io/tls secret in openshift-kube-apiserver whose certificate is
+	// presented in the TLS handshake, for endpoints that require a client certificate.
+	TLSClientCert string `json:"tlsClientCert,omitempty"`
+
+	ConnectivityCheckSettings `json:",inline"`
+}
+
+// StaticPodDetectionConfig holds the detection timeouts for missing and failing static pods. The defaults depend on
+// the control plane topology.
+type StaticPodDetectionConfig struct {
+	// missingPodTimeout is how long the static pod of a revision may not show up on a node after its installer
+	// finished before MissingStaticPodDegraded is set, e.g. "10m" for slow booting bare metal nodes. Defaults to 5m,
+	// 2m on single node clusters.
+	MissingPodTimeout string `json:"missingPodTimeout,omitempty"`
+
+	// failingPodTimeout is how long StaticPodsDegraded, which is set as soon as a static pod is missing, waiting or
+	// terminated with an error, may be true before the cluster operator is degraded. Defaults to 2m, 1m on single
+	// node clusters.
+	FailingPodTimeout string `json:"failingPodTimeout,omitempty"`
+
+	// rewriteManifest rewrites the static pod manifest of a node once when the static pod of a revision did not show
+	// up within missingPodTimeout, for kubelets that missed the manifest written by the installer.
+	RewriteManifest bool `json:"rewriteManifest,omitempty"`
+}
+
+// InstallerConfig holds the retry policy and the pod settings of the installer pods.
+type InstallerConfig struct {
+	// maxAttempts is how often a revision is installed on a node before the operator gives up on it. Only a new
+	// revision is installed on the node then. Unlimited by default.
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+
+	// timeout is how long an installer retries reading the revision from the API on connection errors, e.g. "5m".
+	// Defaults to 2m.
+	Timeout string `json:"timeout,omitempty"`
+
+	// podReadyTimeout makes the installers wait for the kube-apiserver of the revision to become ready before they
+	// succeed, up to this long, e.g. "10m". A kube-apiserver that does not become ready fails the installer. Installers
+	// succeed once the revision is written by default.
+	PodReadyTimeout string `json:"podReadyTimeout,omitempty"`
+
+	// keepRevisions makes the installers remove the resource dirs of older revisions from the node before installing a
+	// revision. They keep this many revisions below the new one and the revision the kube-apiserver runs. Only the
+	// pruner removes old revisions by default.
+	KeepRevisions *int32 `json:"keepRevisions,omitempty"`
+
+	// minFreeDisk fails the installers when less disk space is available for the resource dir, e.g. "1Gi". It is
+	// checked after the old revisions are removed. Not checked by default.
+	MinFreeDisk string `json:"minFreeDisk,omitempty"`
+
+	// metricsTextfileDir makes the installers write the durations of their phases, their retries and the latencies of
+	// their requests to kube-apiserver-installer.prom in this textfile dir of the node-exporter on the node, e.g.
+	// "/var/lib/node-exporter/textfile". The installers always write them to their termination message.
+	MetricsTextfileDir string `json:"metricsTextfileDir,omitempty"`
+
+	// fetchStrategy is Get or List, defaults to Get. Get makes the installers get every secret and configmap of a
+	// revision with a request of its own. List makes them list the secrets and configmaps of the revision with two
+	// requests by the revision label that the operator puts on them, and only get the ones that are not labeled yet.
+	FetchStrategy InstallerFetchStrategy `json:"fetchStrategy,omitempty"`
+
+	// retryBackoff delays the retries of a failed installer, the delay doubles with every failure. It can only
+	// lengthen the delays of the installer controller, which start at 10s and grow by 1.5 up to 10m.
+	RetryBackoff *InstallerRetryBackoff `json:"retryBackoff,omitempty"`
+
+	// resources sets the cpu and memory requests and limits of the installer container. Resources that are not set
+	// keep the 150m cpu and 200M memory of the pod template.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// priorityClassName replaces the system-node-critical priority class of the installer pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// tolerations replace the toleration of every taint of the installer pods.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// InstallerFetchStrategy is how the installers fetch the secrets and configmaps of a revision.
+type InstallerFetchStrategy string
+
+const (
+	// InstallerFetchGet gets every secret and configmap with a request of its own.
+	InstallerFetchGet InstallerFetchStrategy = "Get"
+	// InstallerFetchList lists the secrets and configmaps by their revision label.
+	InstallerFetchList InstallerFetchStrategy = "List"
+)
+
+// InstallerRetryBackoff is the delay between the attempts to install a revision on a node.
+type InstallerRetryBackoff struct {
+	// initialDelay is the delay after the first failure, e.g. "1m".
+	InitialDelay string `json:"initialDelay"`
+
+	// maxDelay caps the delay, e.g. "30m". Defaults to the initial delay times 16.
+	MaxDelay string `json:"maxDelay,omitempty"`
+}
+
+// RolloutConfig holds the rollout strategy of new revisions.
+type RolloutConfig struct {
+	// strategy is Serial or Canary, defaults to Serial. Serial installs a new revision on one node after another.
+	// Canary installs it on the first node and verifies the health of that kube-apiserver for canarySoakPeriod
+	// before the other nodes follow. A canary that is not healthy stops the rollout until a new revision replaces it.
+	Strategy RolloutStrategy `json:"strategy,omitempty"`
+
+	// canarySoakPeriod is how long the kube-apiserver of the canary node has to stay ready without restarts, e.g.
+	// "15m". Defaults to 10m.
+	CanarySoakPeriod string `json:"canarySoakPeriod,omitempty"`
+
+	// paused stops the installation of new revisions on further nodes, an installation in progress finishes. New
+	// revisions are still created and rolled out once the rollout is resumed.
+	Paused bool `json:"paused,omitempty"`
+
+	// nodeGates hold back the installation of a new revision on a node until the node satisfies all of them. They
+	// order the rollout after other maintenance automation of the control plane nodes, like a drain controller.
+	NodeGates []NodeGate `json:"nodeGates,omitempty"`
+
+	// maintenanceWindows restrict the installation of new revisions to the windows, a revision created outside of
+	// them waits for the next window. Without windows new revisions are installed at any time.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// skipPreflightChecks are preflight checks that do not block the installation of new revisions, e.g. to fix a
+	// cluster with a new revision although a check fails. One of EtcdQuorum, EtcdMemberHealth, DiskPressure,
+	// CertificateExpiry and Manifest.
+	SkipPreflightChecks []string `json:"skipPreflightChecks,omitempty"`
+
+	// excludedNodes do not get new revisions installed, e.g. while a node is replaced or under hardware maintenance.
+	// An exclusion expires after its duration.
+	ExcludedNodes []NodeExclusion `json:"excludedNodes,omitempty"`
+
+	// delayBetweenNodes is how long the installation of a new revision on the next node waits after the kube-apiserver
+	// of the previous node became ready with it, e.g. "10m". Defaults to no delay.
+	DelayBetweenNodes string `json:"delayBetweenNodes,omitempty"`
+
+	// nodeOrder is the order in which the nodes get a new revision. Nodes that are not ready or failed to install a
+	// revision always come first, the order applies to the healthy nodes.
+	NodeOrder *NodeOrderConfig `json:"nodeOrder,omitempty"`
+
+	// singleNodeFastPath shortens the installation of new revisions on a SingleReplica control plane. The installer
+	// pod verifies the manifest and the resources it wrote before it reports success, and its init containers check
+	// whether they can proceed every 2s instead of every 10s. Ignored on control planes with more than one node.
+	SingleNodeFastPath bool `json:"singleNodeFastPath,omitempty"`
+}
+
+// NodeOrderConfig is the order in which the nodes get a new revision.
+type NodeOrderConfig struct {
+	// type is UnavailableFirst, Alphabetical, LeaderLast or Explicit, defaults to UnavailableFirst. UnavailableFirst
+	// keeps the order in which the nodes joined the control plane. Alphabetical orders by node name. LeaderLast
+	// installs on the nodes of the kube-controller-manager and kube-scheduler leaders last, so that their leases are
+	// not lost more than once. Explicit installs on the listed nodes first.
+	Type NodeOrderType `json:"type,omitempty"`
+
+	// nodes are the node names in the order of the Explicit type. Nodes that are not listed follow in alphabetical
+	// order.
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// NodeExclusion temporarily excludes a control plane node from the rollout of new revisions.
+type NodeExclusion struct {
+	// nodeName is the name of the excluded node.
+	NodeName string `json:"nodeName"`
+
+	// reason tells why the node is excluded, it is shown in the RolloutNodeExcluded condition.
+	Reason string `json:"reason,omitempty"`
+
+	// duration is how long the node is excluded after the operator first observed the exclusion, e.g. "8h". Defaults
+	// to 24h.
+	Duration string `json:"duration,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window during which new revisions are installed.
+type MaintenanceWindow struct {
+	// schedule is a cron expression of the start of the window in UTC, e.g. "0 22 * * 1-5" or "@daily".
+	Schedule string `json:"schedule"`
+
+	// duration is how long the window stays open after its start, e.g. "4h".
+	Duration string `json:"duration"`
+}
+
+// NodeGate is a node condition or a node annotation that is required before a new revision is installed on a node.
+type NodeGate struct {
+	// conditionType is the type of a node condition that has to be True, e.g. "MaintenanceReady".
+	ConditionType string `json:"conditionType,omitempty"`
+
+	// annotation is a node annotation that has to be set, e.g. "example.com/etcd-learner-promoted".
+	Annotation string `json:"annotation,omitempty"`
+
+	// value is the required value of the annotation, any value is accepted when empty.
+	Value string `json:"value,omitempty"`
+}
+
+// HostPathMount is a host path mounted read-only into the kube-apiserver container.
+type HostPathMount struct {
+	// name is the name of the volume in the static pod. It must not collide with the volumes of the static pod or of
+	// the sidecars.
+	Name string `json:"name"`
+
+	// hostPath is the absolute path on the node, e.g. "/var/run/kmsplugin". The directories the operator and the
+	// kubelet manage, like /etc/kubernetes, cannot be mounted.
+	HostPath string `json:"hostPath"`
+
+	// mountPath is the absolute path in the kube-apiserver container. It must not shadow the other mounts of the
+	// container.
+	MountPath string `json:"mountPath"`
+
+	// type is checked by the kubelet before the static pod starts. Valid values are Directory, File, Socket and
+	// CharDevice, by default the path is not checked.
+	Type corev1.HostPathType `json:"type,omitempty"`
+}
+
+// StartupMonitorConfig holds the settings of the startup monitor.
+type StartupMonitorConfig struct {
+	// mode is Enabled or Disabled. By default the startup monitor only runs on SingleReplica control planes, where
+	// a broken revision cannot be rolled back through the API.
+	Mode StartupMonitorMode `json:"mode,omitempty"`
+
+	// fallbackTimeout is how long a new revision has to become ready before the startup monitor falls back, e.g.
+	// "10m". Defaults to 300s.
+	FallbackTimeout string `json:"fallbackTimeout,omitempty"`
+
+	// readyzSuccessThreshold is how many consecutive /readyz checks a new revision has to pass, defaults to 3.
+	ReadyzSuccessThreshold *int32 `json:"readyzSuccessThreshold,omitempty"`
+
+	// readyzInterval is the time between these /readyz checks, defaults to 5s.
+	ReadyzInterval string `json:"readyzInterval,omitempty"`
+
+	// crashLoopThreshold is how often the kube-apiserver container of a new revision may restart. Once it restarted
+	// more often the revision is not considered ready anymore and the startup monitor falls back when the fallback
+	// timeout expires. By default restarts are tolerated as long as /readyz passes eventually.
+	CrashLoopThreshold *int32 `json:"crashLoopThreshold,omitempty"`
+}
+
+// InsecureReadyzConfig holds the settings of the kube-apiserver-insecure-readyz sidecar.
+type InsecureReadyzConfig struct {
+	// port is the host port the sidecar listens on, defaults to 6080. The load balancers that health check the
+	// sidecar have to be moved to the new port.
+	Port *int32 `json:"port,omitempty"`
+
+	// disabled removes the sidecar from the static pod. It is not applied on GCP, whose load balancers health check
+	// the sidecar, nor with anonymousAuth Disabled, the kubelet probes go through the sidecar then.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// SecurityContextConfig holds the pod level security settings of the kube-apiserver static pod. Privileged containers,
+// like the kube-apiserver container unless nonRoot is set, are not confined by either of them.
+type SecurityContextConfig struct {
+	// seccompProfile defaults to RuntimeDefault. Localhost profiles are read from the seccomp directory of the
+	// kubelet on the control plane nodes.
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+
+	// seLinuxOptions defaults to the label the container runtime assigns.
+	SELinuxOptions *corev1.SELinuxOptions `json:"seLinuxOptions,omitempty"`
+}
+
+// NonRootConfig holds the user the containers of the kube-apiserver static pod run as. The setup init container
+// stays privileged, it hands the resource-dir, cert-dir and audit-dir over to the user before the other containers
+// start. The ports of the static pod are above 1024, binding them needs no capability.
+type NonRootConfig struct {
+	// uid is the user and group id the containers run as. It must not be used by anything else on the control
+	// plane nodes, it owns the certificates and keys of the kube-apiserver.
+	UID int64 `json:"uid"`
+}
+
+// AuditPolicyConfig holds the rules that are merged into the audit policy of the profile of apiserver/cluster.
+type AuditPolicyConfig struct {
+	// customRulesConfigMap is the name of a config map in openshift-config whose policy.yaml key holds an
+	// audit.k8s.io/v1 Policy with rules, e.g. to log the request bodies of a single resource. The rules are inserted
+	// before the rules of the profile and take precedence over them, the rules that drop events and health checks
+	// still apply first. omitStages, which applies to the whole policy, is rejected.
+	CustomRulesConfigMap string `json:"customRulesConfigMap,omitempty"`
+
+	// overrides set another audit profile for the requests in some namespaces or to some resources, e.g.
+	// WriteRequestBodies for RBAC and secrets in openshift-* namespaces. The rules of their profiles are scoped to
+	// the namespaces and resources and inserted after the custom rules, in order, the first matching override wins.
+	Overrides []AuditPolicyOverride `json:"overrides,omitempty"`
+
+	// intents log the requests they match at a level, e.g. the request bodies of the requests of some groups or
+	// deletes of some resources. They are compiled into rules after the custom rules and before the overrides. The
+	// order of the intents does not matter, a request that matches several intents is logged at the highest of
+	// their levels. The bodies of secrets, tokens and OAuth clients are never logged, they are logged at Metadata.
+	Intents []AuditPolicyIntent `json:"intents,omitempty"`
+
+	// exemptions lower the audit level of the requests of some users or groups, e.g. a noisy backup service account.
+	// They are compiled into rules after the custom rules and before the intents, so an intent cannot raise the level
+	// of an exempted request.
+	Exemptions []AuditPolicyExemption `json:"exemptions,omitempty"`
+
+	// sampling reduces the audit events of read requests on busy clusters. Its rules are inserted after the
+	// overrides.
+	Sampling *AuditSamplingConfig `json:"sampling,omitempty"`
+
+	// acknowledgeNoneProfile accepts that the None audit profile of apiserver/cluster stops logging the requests that
+	// no custom rule or override matches, e.g. to measure the kube-apiserver without the audit overhead. Without it a
+	// None profile is not rolled out. The AuditDisabled condition is true while it applies.
+	AcknowledgeNoneProfile bool `json:"acknowledgeNoneProfile,omitempty"`
+}
+
+// AuditSamplingConfig lowers the audit level of read requests, which make up most of the audit events of a busy
+// cluster, while writes keep the level of the profile. An audit policy cannot select a percentage of the requests, the
+// audit webhook is rate limited by auditWebhook.batch.throttleQPS.
+type AuditSamplingConfig struct {
+	// readLevel is the audit level of get, list and watch requests: None or Metadata. Reads of security relevant
+	// resources, e.g. secrets, RBAC and OAuth tokens, are still logged at Metadata. It does not apply with the None
+	// profile.
+	ReadLevel auditv1.Level `json:"readLevel"`
+}
+
+// AuditPolicyOverride sets the audit profile of the requests that match its namespaces and resources. At least one
+// of them must be set, an override without either would replace the profile of apiserver/cluster.
+type AuditPolicyOverride struct {
+	// profile is the audit profile of the matching requests: None, Default, WriteRequestBodies or AllRequestBodies.
+	// The profile still never logs the bodies of secrets, config maps and token reviews.
+	Profile configv1.AuditProfileType `json:"profile"`
+
+	// namespaces limits the override to requests in these namespaces. A trailing * matches every namespace with the
+	// prefix, e.g. "openshift-*", it is expanded to the existing namespaces and a new matching namespace rolls out a
+	// new revision. Requests to cluster scoped resources never match an override with namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// resources limits the override to requests to these resources.
+	Resources []AuditPolicyResources `json:"resources,omitempty"`
+}
+
+// AuditPolicyIntent logs the requests that match all of its selectors at its level. At least one selector must be
+// set, an intent that matches every request would replace the profile of apiserver/cluster.
+type AuditPolicyIntent struct {
+	// level is None, Metadata, Request or RequestResponse.
+	Level auditv1.Level `json:"level"`
+
+	// users limits the intent to the requests of these users, e.g. "system:serviceaccount:backup:velero".
+	Users []string `json:"users,omitempty"`
+
+	// groups limits the intent to the requests of members of these groups.
+	Groups []string `json:"groups,omitempty"`
+
+	// verbs limits the intent to these verbs, e.g. "delete" or "create".
+	Verbs []string `json:"verbs,omitempty"`
+
+	// resources limits the intent to requests to these resources.
+	Resources []AuditPolicyResources `json:"resources,omitempty"`
+
+	// namespaces limits the intent to requests in these namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// AuditPolicyExemption logs the requests of its users and of the members of its groups at a reduced level. The
+// requests of everybody else keep their level.
+type AuditPolicyExemption struct {
+	// users are exempted users, e.g. "system:serviceaccount:backup:velero".
+	Users []string `json:"users,omitempty"`
+
+	// groups are exempted groups. system:authenticated and system:unauthenticated are rejected, they would exempt
+	// every request.
+	Groups []string `json:"groups,omitempty"`
+
+	// level is None, which leaves the requests out of the audit log, or Metadata. Defaults to None.
+	Level auditv1.Level `json:"level,omitempty"`
+
+	// verbs limits the exemption to these verbs, e.g. get, list and watch to keep logging the writes.
+	Verbs []string `json:"verbs,omitempty"`
+}
+
+// AuditPolicyResources selects resources of an API group.
+type AuditPolicyResources struct {
+	// group is the API group of the resources, empty for the core group.
+	Group string `json:"group"`
+
+	// resources are the resources of the group, e.g. "secrets" or "pods/exec". Empty matches every resource of the
+	// group.
+	Resources []string `json:"resources,omitempty"`
+}
+
+// AuditLogConfig holds the rotation and retention of the audit log of the kube-apiserver. The audit log takes up
+// to maxSize * (maxBackup + 1) MiB of the disk.
+type AuditLogConfig struct {
+	// maxSize is the size in MiB at which the audit log is rotated.
+	MaxSize *int32 `json:"maxSize,omitempty"`
+	// maxBackup is the number of rotated audit logs that are kept.
+	MaxBackup *int32 `json:"maxBackup,omitempty"`
+	// maxAge is the number of days rotated audit logs are kept, 0 keeps them regardless of their age.
+	MaxAge *int32 `json:"maxAge,omitempty"`
+	// minAvailablePercent is the share of the volume of the audit log that has to be available, less degrades the
+	// operator. Defaults to 10.
+	MinAvailablePercent *int32 `json:"minAvailablePercent,omitempty"`
+}
+
+// AuditWebhookMode is the strategy of the kube-apiserver for sending audit events to the webhook.
+type AuditWebhookMode string
+
+const (
+	// AuditWebhookBatch buffers the events and sends them asynchronously in batches, events are dropped when the
+	// buffer is full.
+	AuditWebhookBatch AuditWebhookMode = "batch"
+	// AuditWebhookBlocking sends every event while the request is served, a slow webhook slows down the requests.
+	AuditWebhookBlocking AuditWebhookMode = "blocking"
+	// AuditWebhookBlockingStrict is blocking and fails the request when its RequestReceived event cannot be sent.
+	AuditWebhookBlockingStrict AuditWebhookMode = "blocking-strict"
+)
+
+// AuditWebhookConfig holds the audit webhook backend of the kube-apiserver. Unset values keep the kube-apiserver
+// defaults.
+type AuditWebhookConfig struct {
+	// kubeConfigSecret is the name of a secret in openshift-config whose kubeConfig key holds the kubeconfig of the
+	// webhook, with a single cluster, user and context. Files cannot be referenced, their content must be inlined.
+	// Either kubeConfigSecret or url is set.
+	KubeConfigSecret string `json:"kubeConfigSecret,omitempty"`
+
+	// url is the https URL of the webhook, the operator renders the kubeconfig from it, caConfigMap and
+	// clientCertSecret.
+	URL string `json:"url,omitempty"`
+
+	// caConfigMap is the name of a config map in openshift-config whose ca-bundle.crt key holds the CAs the serving
+	// certificate of the url is verified with. Without it the system trust store of the kube-apiserver is used.
+	CAConfigMap string `json:"caConfigMap,omitempty"`
+
+	// clientCertSecret is the name of a kubernetes.io/tls secret in openshift-config with the client certificate and
+	// key the kube-apiserver authenticates to the url with. Required with url.
+	ClientCertSecret string `json:"clientCertSecret,omitempty"`
+
+	// mode is batch, blocking or blocking-strict. Defaults to batch.
+	Mode AuditWebhookMode `json:"mode,omitempty"`
+
+	// initialBackoff is how long to wait before retrying a failed request to the webhook, e.g. "10s".
+	InitialBackoff string `json:"initialBackoff,omitempty"`
+
+	// batch sets the buffering and throttling of the batch mode.
+	Batch AuditWebhookBatchConfig `json:"batch,omitempty"`
+}
+
+// AuditWebhookBatchConfig holds the buffering and throttling of the batch mode of the audit webhook.
+type AuditWebhookBatchConfig struct {
+	// bufferSize is the number of events buffered before they are batched, more are dropped.
+	BufferSize *int32 `json:"bufferSize,omitempty"`
+	// maxSize is the maximum number of events in a batch.
+	MaxSize *int32 `json:"maxSize,omitempty"`
+	// maxWait is how long to wait before sending a batch that is not full, e.g. "5s".
+	MaxWait string `json:"maxWait,omitempty"`
+	// throttleQPS is the maximum average number of batches sent per second.
+	ThrottleQPS *int32 `json:"throttleQPS,omitempty"`
+	// throttleBurst is the maximum number of batches sent at once when throttleQPS was not used up before.
+	ThrottleBurst *int32 `json:"throttleBurst,omitempty"`
+}
+
+// AuditForwarderProtocol is the protocol the audit forwarder sends the audit events with.
+type AuditForwarderProtocol string
+
+const (
+	// AuditForwarderSyslog sends every audit event as an RFC 5424 syslog message with octet-counted framing
+	// (RFC 6587) over TCP, the facility is log audit.
+	AuditForwarderSyslog AuditForwarderProtocol = "Syslog"
+)
+
+// AuditForwarderConfig holds the audit forwarder sidecar. The sidecar tails the audit log and only reads on when the
+// endpoint accepted the events, the audit log and its rotated files buffer the events while the endpoint is slow or
+// down.
+type AuditForwarderConfig struct {
+	// protocol is the protocol of the endpoint. Only Syslog is supported.
+	Protocol AuditForwarderProtocol `json:"protocol"`
+
+	// endpoint is the host:port the events are sent to.
+	Endpoint string `json:"endpoint"`
+
+	// tls connects to the endpoint with TLS when set.
+	TLS *AuditForwarderTLSConfig `json:"tls,omitempty"`
+}
+
+// AuditForwarderTLSConfig holds the TLS settings of the audit forwarder.
+type AuditForwarderTLSConfig struct {
+	// caConfigMap is the name of a config map in openshift-config whose ca-bundle.crt key holds the CAs the
+	// certificate of the endpoint is verified with. Defaults to the system trust bundle.
+	CAConfigMap string `json:"caConfigMap,omitempty"`
+}
+
+// ProbesConfig holds the timing of the probes of the kube-apiserver container. The startup probe is only added
+// when it is configured, it holds back the liveness probe until the kube-apiserver is live for the first time.
+type ProbesConfig struct {
+	Liveness  *ProbeConfig `json:"liveness,omitempty"`
+	Readiness *ProbeConfig `json:"readiness,omitempty"`
+	Startup   *ProbeConfig `json:"startup,omitempty"`
+}
+
+// ProbeConfig holds the timing of a probe, unset values keep the operator defaults.
+type ProbeConfig struct {
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
+}
+
+// SidecarConfig holds the containers and volumes added to the kube-apiserver static pod. The static pod is run by the
+// kubelet without the API, so only hostPath and emptyDir volumes are supported. The containers can mount these and
+// the resource-dir, cert-dir and audit-dir volumes of the static pod.
+type SidecarConfig struct {
+	Containers []corev1.Container `json:"containers,omitempty"`
+	Volumes    []corev1.Volume    `json:"volumes,omitempty"`
+}
+
+// LoggingConfig holds per container log levels. Valid values are Normal, Debug, Trace and TraceAll. An empty value
+// keeps spec.logLevel of the operator for the kube-apiserver container and the built-in default for the others.
+type LoggingConfig struct {
+	KubeAPIServer  operatorv1.LogLevel `json:"kubeAPIServer,omitempty"`
+	CertSyncer     operatorv1.LogLevel `json:"certSyncer,omitempty"`
+	CheckEndpoints operatorv1.LogLevel `json:"checkEndpoints,omitempty"`
+	InsecureReadyz operatorv1.LogLevel `json:"insecureReadyz,omitempty"`
+
+	// vmodule is passed to the kube-apiserver container for targeted debugging, e.g. "httplog=4,rest=6".
+	VModule string `json:"vmodule,omitempty"`
+
+	// operatorControllers sets the log level of controllers of the operator itself, e.g. certRotation: Debug, without
+	// raising spec.operatorLogLevel for all of them. The keys are in OperatorControllerSourceFiles. Changes apply
+	// without restarting the operator.
+	OperatorControllers map[string]operatorv1.LogLevel `json:"operatorControllers,omitempty"`
+}
+
+// EtcdConfig holds the etcd client settings of the kube-apiserver. Every value is a duration like "30s",
+// an empty value keeps the kube-apiserver default.
+type EtcdConfig struct {
+	// healthcheckTimeout is the timeout of the etcd checks of /healthz, /livez and /readyz, defaults to 2s.
+	// Raising it avoids false unready states when etcd answers slowly.
+	HealthcheckTimeout string `json:"healthcheckTimeout,omitempty"`
+
+	// compactionInterval is the interval of the compaction requests sent to etcd, defaults to 5m.
+	CompactionInterval string `json:"compactionInterval,omitempty"`
+
+	// countMetricPollPeriod is how often etcd is polled for the number of objects per resource, defaults to 1m.
+	// 0s disables the metric.
+	CountMetricPollPeriod string `json:"countMetricPollPeriod,omitempty"`
+
+	// dbMetricPollInterval is how often etcd is polled for the database size metric, defaults to 30s.
+	// 0s disables the metric.
+	DBMetricPollInterval string `json:"dbMetricPollInterval,omitempty"`
+}
+
+// AnonymousAuthMode is the value of the anonymousAuth field.
+type AnonymousAuthMode string
+
+const (
+	// AnonymousAuthEnabled lets unauthenticated requests through as system:anonymous, RBAC decides what they can access.
+	AnonymousAuthEnabled AnonymousAuthMode = "Enabled"
+	// AnonymousAuthDisabled rejects every unauthenticated request with 401.
+	AnonymousAuthDisabled AnonymousAuthMode = "Disabled"
+)
+
+// ProfilingMode is the value of the profiling field.
+type ProfilingMode string
+
+const (
+	// ProfilingEnabled serves /debug/pprof and /debug/flags to authorized users.
+	ProfilingEnabled ProfilingMode = "Enabled"
+	// ProfilingDisabled does not serve the debug endpoints at all.
+	ProfilingDisabled ProfilingMode = "Disabled"
+)
+
+// StartupMonitorMode is the value of the startupMonitor mode field.
+type StartupMonitorMode string
+
+const (
+	// StartupMonitorEnabled runs the startup monitor regardless of the control plane topology.
+	StartupMonitorEnabled StartupMonitorMode = "Enabled"
+	// StartupMonitorDisabled never runs the startup monitor, a broken revision stays in place.
+	StartupMonitorDisabled StartupMonitorMode = "Disabled"
+)
+
+// RolloutStrategy is the value of the rollout strategy field.
+type RolloutStrategy string
+
+const (
+	// RolloutSerial installs a new revision on one node after another.
+	RolloutSerial RolloutStrategy = "Serial"
+	// RolloutCanary installs a new revision on one node and verifies it before the other nodes follow.
+	RolloutCanary RolloutStrategy = "Canary"
+)
+
+// NodeOrderType is the value of the rollout nodeOrder type field.
+type NodeOrderType string
+
+const (
+	// NodeOrderUnavailableFirst keeps the order of the installer controller, unavailable nodes first and the others in
+	// the order they joined the control plane.
+	NodeOrderUnavailableFirst NodeOrderType = "UnavailableFirst"
+	// NodeOrderAlphabetical orders the nodes by name.
+	NodeOrderAlphabetical NodeOrderType = "Alphabetical"
+	// NodeOrderLeaderLast orders the nodes of the control plane leaders last.
+	NodeOrderLeaderLast NodeOrderType = "LeaderLast"
+	// NodeOrderExplicit orders the nodes as listed.
+	NodeOrderExplicit NodeOrderType = "Explicit"
+)
@@ -0,0 +1,1552 @@
+package operatorconfig
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/robfig/cron"
+)
+
+// AllowedRuntimeConfig are the group versions that can be enabled through runtimeConfig. The list is limited
+// to APIs that are disabled by default in the kube-apiserver and are known not to break the cluster when enabled.
+var AllowedRuntimeConfig = sets.NewString(
+	"flowcontrol.apiserver.k8s.io/v1alpha1",
+	"internal.apiserver.k8s.io/v1alpha1",
+	"node.k8s.io/v1alpha1",
+	"rbac.authorization.k8s.io/v1alpha1",
+	"scheduling.k8s.io/v1alpha1",
+	"storage.k8s.io/v1alpha1",
+)
+
+// PreflightChecks are the checks that have to pass before a new revision is installed on a node.
+var PreflightChecks = sets.NewString("EtcdQuorum", "EtcdMemberHealth", "DiskPressure", "CertificateExpiry", "Manifest")
+
+// ValidateRuntimeConfig validates the runtimeConfig field.
+func ValidateRuntimeConfig(runtimeConfig []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, groupVersion := range runtimeConfig {
+		switch {
+		case strings.Contains(groupVersion, "="):
+			errs = append(errs, field.Invalid(fldPath.Index(i), groupVersion, "must be a group version without a value, e.g. \"storage.k8s.io/v1alpha1\""))
+		case !AllowedRuntimeConfig.Has(groupVersion):
+			errs = append(errs, field.NotSupported(fldPath.Index(i), groupVersion, AllowedRuntimeConfig.List()))
+		case seen.Has(groupVersion):
+			errs = append(errs, field.Duplicate(fldPath.Index(i), groupVersion))
+		}
+		seen.Insert(groupVersion)
+	}
+	return errs
+}
+
+var supportedNodeAddressTypes = sets.NewString(
+	string(corev1.NodeHostName),
+	string(corev1.NodeInternalDNS),
+	string(corev1.NodeInternalIP),
+	string(corev1.NodeExternalDNS),
+	string(corev1.NodeExternalIP),
+)
+
+// ValidateKubeletPreferredAddressTypes validates the kubeletPreferredAddressTypes field.
+func ValidateKubeletPreferredAddressTypes(addressTypes []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, addressType := range addressTypes {
+		switch {
+		case !supportedNodeAddressTypes.Has(addressType):
+			errs = append(errs, field.NotSupported(fldPath.Index(i), addressType, supportedNodeAddressTypes.List()))
+		case seen.Has(addressType):
+			errs = append(errs, field.Duplicate(fldPath.Index(i), addressType))
+		}
+		seen.Insert(addressType)
+	}
+	return errs
+}
+
+var supportedAnonymousAuthModes = sets.NewString(string(AnonymousAuthEnabled), string(AnonymousAuthDisabled))
+
+// ValidateAnonymousAuth validates the anonymousAuth field.
+func ValidateAnonymousAuth(mode AnonymousAuthMode, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(mode) > 0 && !supportedAnonymousAuthModes.Has(string(mode)) {
+		errs = append(errs, field.NotSupported(fldPath, mode, supportedAnonymousAuthModes.List()))
+	}
+	return errs
+}
+
+var supportedProfilingModes = sets.NewString(string(ProfilingEnabled), string(ProfilingDisabled))
+
+// ValidateProfiling validates the profiling field.
+func ValidateProfiling(mode ProfilingMode, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(mode) > 0 && !supportedProfilingModes.Has(string(mode)) {
+		errs = append(errs, field.NotSupported(fldPath, mode, supportedProfilingModes.List()))
+	}
+	return errs
+}
+
+// ValidateAdditionalAPIAudiences validates the additionalAPIAudiences field.
+func ValidateAdditionalAPIAudiences(audiences []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, audience := range audiences {
+		switch {
+		case len(strings.TrimSpace(audience)) == 0:
+			errs = append(errs, field.Required(fldPath.Index(i), "must not be empty"))
+		case strings.ContainsAny(audience, ", \t\n"):
+			errs = append(errs, field.Invalid(fldPath.Index(i), audience, "must not contain commas or whitespace"))
+		case seen.Has(audience):
+			errs = append(errs, field.Duplicate(fldPath.Index(i), audience))
+		}
+		seen.Insert(audience)
+	}
+	return errs
+}
+
+// ValidateServiceAccountSigningKey validates the serviceAccountSigningKey field. The keypair itself is validated by the
+// bound service account token signer controller, which reads the secret.
+func ValidateServiceAccountSigningKey(config *ServiceAccountSigningKeyConfig, fldPath *field.Path) field.ErrorList {
+	if config == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if len(config.SecretName) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("secretName"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(config.SecretName) {
+			errs = append(errs, field.Invalid(fldPath.Child("secretName"), config.SecretName, msg))
+		}
+	}
+	return errs
+}
+
+// ValidateServiceAccountSigningKeyRotation validates the serviceAccountSigningKeyRotation field.
+func ValidateServiceAccountSigningKeyRotation(config ServiceAccountSigningKeyRotationConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateDuration(config.Interval, 24*time.Hour, 10*365*24*time.Hour, fldPath.Child("interval"))...)
+	errs = append(errs, validateDuration(config.PropagationDelay, 0, 24*time.Hour, fldPath.Child("propagationDelay"))...)
+	if len(config.ForceRotationReason) > 253 {
+		errs = append(errs, field.TooLong(fldPath.Child("forceRotationReason"), config.ForceRotationReason, 253))
+	}
+	return errs
+}
+
+// ValidateOIDCDiscovery validates the oidcDiscovery field. The URLs must be HTTPS without a query or a fragment.
+func ValidateOIDCDiscovery(config *OIDCDiscoveryConfig, fldPath *field.Path) field.ErrorList {
+	if config == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if len(config.JWKSURI) > 0 {
+		errs = append(errs, validateHTTPSURL(config.JWKSURI, fldPath.Child("jwksURI"))...)
+	}
+	if config.Endpoint == nil {
+		return errs
+	}
+	endpointPath := fldPath.Child("endpoint")
+	if len(config.Endpoint.URL) == 0 {
+		errs = append(errs, field.Required(endpointPath.Child("url"), ""))
+	} else {
+		errs = append(errs, validateHTTPSURL(config.Endpoint.URL, endpointPath.Child("url"))...)
+	}
+	if len(config.Endpoint.HeadersSecretName) > 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(config.Endpoint.HeadersSecretName) {
+			errs = append(errs, field.Invalid(endpointPath.Child("headersSecretName"), config.Endpoint.HeadersSecretName, msg))
+		}
+	}
+	if len(config.Endpoint.CAConfigMap) > 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(config.Endpoint.CAConfigMap) {
+			errs = append(errs, field.Invalid(endpointPath.Child("caConfigMap"), config.Endpoint.CAConfigMap, msg))
+		}
+	}
+	return errs
+}
+
+func validateHTTPSURL(value string, fldPath *field.Path) field.ErrorList {
+	u, err := url.Parse(value)
+	switch {
+	case err != nil:
+		return field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	case u.Scheme != "https" || len(u.Host) == 0:
+		return field.ErrorList{field.Invalid(fldPath, value, "must be an https URL with a host")}
+	case len(u.RawQuery) > 0 || len(u.Fragment) > 0 || u.User != nil:
+		return field.ErrorList{field.Invalid(fldPath, value, "must not have a query, a fragment or user info")}
+	}
+	return nil
+}
+
+// ValidateFeatureGates validates the featureGates field. The acknowledged migrations must be unique feature gate
+// names.
+func ValidateFeatureGates(config FeatureGatesConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, gate := range config.AcknowledgedMigrations {
+		gatePath := fldPath.Child("acknowledgedMigrations").Index(i)
+		switch {
+		case !featureGateNameRegexp.MatchString(gate):
+			errs = append(errs, field.Invalid(gatePath, gate, "must be a feature gate name like IPv6DualStack"))
+		case seen.Has(gate):
+			errs = append(errs, field.Duplicate(gatePath, gate))
+		}
+		seen.Insert(gate)
+	}
+	return errs
+}
+
+var featureGateNameRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+var supportedKubeletVersionSkewPolicies = sets.NewString(string(KubeletVersionSkewStrict), string(KubeletVersionSkewWarnOnly))
+
+// ValidateKubeletVersionSkew validates the kubeletVersionSkew field. A node can only be exempted once.
+func ValidateKubeletVersionSkew(config KubeletVersionSkewConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(config.Policy) > 0 && !supportedKubeletVersionSkewPolicies.Has(string(config.Policy)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("policy"), config.Policy, supportedKubeletVersionSkewPolicies.List()))
+	}
+	nodes := sets.NewString()
+	for i, exemption := range config.ExemptNodes {
+		exemptionPath := fldPath.Child("exemptNodes").Index(i)
+		switch {
+		case len(exemption.Node) == 0:
+			errs = append(errs, field.Required(exemptionPath.Child("node"), ""))
+		case nodes.Has(exemption.Node):
+			errs = append(errs, field.Duplicate(exemptionPath.Child("node"), exemption.Node))
+		default:
+			for _, msg := range validation.IsDNS1123Subdomain(exemption.Node) {
+				errs = append(errs, field.Invalid(exemptionPath.Child("node"), exemption.Node, msg))
+			}
+		}
+		nodes.Insert(exemption.Node)
+		if len(exemption.Until) == 0 {
+			errs = append(errs, field.Required(exemptionPath.Child("until"), "the exemption must end"))
+		} else if _, err := time.Parse(time.RFC3339, exemption.Until); err != nil {
+			errs = append(errs, field.Invalid(exemptionPath.Child("until"), exemption.Until, "must be an RFC 3339 time"))
+		}
+		if len(exemption.Reason) > 253 {
+			errs = append(errs, field.TooLong(exemptionPath.Child("reason"), exemption.Reason, 253))
+		}
+	}
+	return errs
+}
+
+// ValidateResourceSync validates the resourceSync field. Two rules of the same kind cannot have the same destination.
+func ValidateResourceSync(config ResourceSyncConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateResourceSyncRules(config.ConfigMaps, fldPath.Child("configMaps"))...)
+	errs = append(errs, validateResourceSyncRules(config.Secrets, fldPath.Child("secrets"))...)
+	return errs
+}
+
+func validateResourceSyncRules(rules []ResourceSyncRule, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	destinations := sets.NewString()
+	for i, rule := range rules {
+		rulePath := fldPath.Index(i)
+		if len(rule.Source) == 0 {
+			errs = append(errs, field.Required(rulePath.Child("source"), ""))
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(rule.Source) {
+			errs = append(errs, field.Invalid(rulePath.Child("source"), rule.Source, msg))
+		}
+		destination := rule.DestinationName()
+		switch {
+		case !strings.HasPrefix(destination, UserResourceSyncPrefix):
+			errs = append(errs, field.Invalid(rulePath.Child("destination"), destination, fmt.Sprintf("must start with %q", UserResourceSyncPrefix)))
+		case destinations.Has(destination):
+			errs = append(errs, field.Duplicate(rulePath.Child("destination"), destination))
+		default:
+			for _, msg := range validation.IsDNS1123Subdomain(destination) {
+				errs = append(errs, field.Invalid(rulePath.Child("destination"), destination, msg))
+			}
+		}
+		destinations.Insert(destination)
+		for j, key := range rule.Keys {
+			for _, msg := range validation.IsConfigMapKey(key) {
+				errs = append(errs, field.Invalid(rulePath.Child("keys").Index(j), key, msg))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateEtcdConfig validates the etcd field. Compaction cannot be disabled, etcd relies on the kube-apiserver
+// to compact its history.
+func ValidateEtcdConfig(config EtcdConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateDuration(config.HealthcheckTimeout, time.Second, time.Minute, fldPath.Child("healthcheckTimeout"))...)
+	errs = append(errs, validateDuration(config.CompactionInterval, time.Minute, time.Hour, fldPath.Child("compactionInterval"))...)
+	if config.CountMetricPollPeriod != "0s" {
+		errs = append(errs, validateDuration(config.CountMetricPollPeriod, time.Second, time.Hour, fldPath.Child("countMetricPollPeriod"))...)
+	}
+	if config.DBMetricPollInterval != "0s" {
+		errs = append(errs, validateDuration(config.DBMetricPollInterval, time.Second, time.Hour, fldPath.Child("dbMetricPollInterval"))...)
+	}
+	return errs
+}
+
+// validateDuration checks that a non-empty value is a duration between min and max.
+func validateDuration(value string, min, max time.Duration, fldPath *field.Path) field.ErrorList {
+	if len(value) == 0 {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	}
+	if duration < min || duration > max {
+		return field.ErrorList{field.Invalid(fldPath, value, fmt.Sprintf("must be between %v and %v", min, max))}
+	}
+	return nil
+}
+
+// ValidateAPIServerArguments validates the apiServerArguments field against KubeAPIServerFlags. The flags in
+// managed are set by the operator and cannot be overridden.
+func ValidateAPIServerArguments(args map[string][]string, managed sets.String, fldPath *field.Path) field.ErrorList {
+	var names []string
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs field.ErrorList
+	for _, name := range names {
+		values := args[name]
+		list, known := KubeAPIServerFlags[name]
+		switch {
+		case strings.HasPrefix(name, "-"):
+			errs = append(errs, field.Invalid(fldPath.Key(name), name, "must be a flag name without leading dashes"))
+		case !known:
+			errs = append(errs, field.Invalid(fldPath.Key(name), name, "unknown kube-apiserver flag"))
+		case managed.Has(name):
+			errs = append(errs, field.Forbidden(fldPath.Key(name), "the flag is managed by the operator"))
+		case len(values) == 0:
+			errs = append(errs, field.Required(fldPath.Key(name), "at least one value is required"))
+		case !list && len(values) > 1:
+			errs = append(errs, field.Invalid(fldPath.Key(name), values, "the flag accepts a single value"))
+		default:
+			seen := sets.NewString()
+			for i, value := range values {
+				if seen.Has(value) {
+					errs = append(errs, field.Duplicate(fldPath.Key(name).Index(i), value))
+				}
+				seen.Insert(value)
+			}
+		}
+	}
+	return errs
+}
+
+var (
+	supportedLogLevels = sets.NewString(string(operatorv1.Normal), string(operatorv1.Debug), string(operatorv1.Trace), string(operatorv1.TraceAll))
+	vmodulePattern     = regexp.MustCompile(`^[a-zA-Z0-9_*?./-]+=[0-9]+(,[a-zA-Z0-9_*?./-]+=[0-9]+)*$`)
+)
+
+// ValidateLoggingConfig validates the logging field.
+func ValidateLoggingConfig(config LoggingConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, level := range []struct {
+		name  string
+		value operatorv1.LogLevel
+	}{
+		{name: "kubeAPIServer", value: config.KubeAPIServer},
+		{name: "certSyncer", value: config.CertSyncer},
+		{name: "checkEndpoints", value: config.CheckEndpoints},
+		{name: "insecureReadyz", value: config.InsecureReadyz},
+	} {
+		if len(level.value) > 0 && !supportedLogLevels.Has(string(level.value)) {
+			errs = append(errs, field.NotSupported(fldPath.Child(level.name), level.value, supportedLogLevels.List()))
+		}
+	}
+	if len(config.VModule) > 0 && !vmodulePattern.MatchString(config.VModule) {
+		errs = append(errs, field.Invalid(fldPath.Child("vmodule"), config.VModule, "must be a comma separated list of pattern=N"))
+	}
+	for _, controller := range sets.StringKeySet(config.OperatorControllers).List() {
+		if _, ok := OperatorControllerSourceFiles[controller]; !ok {
+			errs = append(errs, field.NotSupported(fldPath.Child("operatorControllers"), controller, sets.StringKeySet(OperatorControllerSourceFiles).List()))
+			continue
+		}
+		if level := config.OperatorControllers[controller]; !supportedLogLevels.Has(string(level)) {
+			errs = append(errs, field.NotSupported(fldPath.Child("operatorControllers").Key(controller), level, supportedLogLevels.List()))
+		}
+	}
+	return errs
+}
+
+// OperatorControllerSourceFiles are the controllers of the operator whose log level can be set, with the names of
+// their source files without the .go extension. klog only selects log statements by their file, so a controller is
+// logged at its level through a vmodule pattern per file.
+var OperatorControllerSourceFiles = map[string][]string{
+	"certRotation":        {"cabundle", "client_cert_rotation_controller", "signer", "target", "certrotationcontroller", "dynamic_serving"},
+	"configObserver":      {"config_observer_controller", "observe_config_controller", "observed_config_history"},
+	"connectivityCheck":   {"connectivity_check_controller", "connectivity_outage_controller", "connectivity_summary_controller"},
+	"encryption":          {"condition_controller", "key_controller", "migration_controller", "state_controller", "encryption_config_controller"},
+	"installer":           {"installer_controller", "installer_state_controller"},
+	"node":                {"node_controller"},
+	"prune":               {"prune_controller"},
+	"resourceSync":        {"resourcesync_controller", "resourcesynccontroller"},
+	"revision":            {"revision_controller"},
+	"staticPodState":      {"staticpodstate_controller"},
+	"status":              {"status_controller"},
+	"targetConfig":        {"targetconfigcontroller", "force_redeployment"},
+	"terminationObserver": {"termination_observer", "late_connections", "disruption"},
+}
+
+// StaticPodContainers are the init containers and containers of the kube-apiserver static pod.
+var StaticPodContainers = sets.NewString(
+	"setup",
+	"kube-apiserver",
+	"kube-apiserver-cert-syncer",
+	"kube-apiserver-cert-regeneration-controller",
+	"kube-apiserver-insecure-readyz",
+	"kube-apiserver-check-endpoints",
+)
+
+var supportedResourceNames = sets.NewString(string(corev1.ResourceCPU), string(corev1.ResourceMemory))
+
+// ValidateResources validates the resources field. Only cpu and memory of the static pod containers can be set and
+// a limit must not be below the request of the same resource.
+func ValidateResources(resources map[string]corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for container, requirements := range resources {
+		containerPath := fldPath.Key(container)
+		if !StaticPodContainers.Has(container) {
+			errs = append(errs, field.NotSupported(fldPath, container, StaticPodContainers.List()))
+			continue
+		}
+		errs = append(errs, validateResourceRequirements(requirements, containerPath)...)
+	}
+	return errs
+}
+
+func validateResourceRequirements(requirements corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateResourceList(requirements.Requests, fldPath.Child("requests"))...)
+	errs = append(errs, validateResourceList(requirements.Limits, fldPath.Child("limits"))...)
+	for name, limit := range requirements.Limits {
+		if request, ok := requirements.Requests[name]; ok && limit.Cmp(request) < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("limits").Key(string(name)), limit.String(), fmt.Sprintf("must be greater than or equal to the request %s", request.String())))
+		}
+	}
+	return errs
+}
+
+func validateResourceList(resources corev1.ResourceList, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for name, quantity := range resources {
+		if !supportedResourceNames.Has(string(name)) {
+			errs = append(errs, field.NotSupported(fldPath, name, supportedResourceNames.List()))
+			continue
+		}
+		if quantity.Cmp(resource.Quantity{}) <= 0 {
+			errs = append(errs, field.Invalid(fldPath.Key(string(name)), quantity.String(), "must be greater than zero"))
+		}
+	}
+	return errs
+}
+
+// ValidateReservedCPUs validates the reservedCPUs field, a comma separated list of cpu ids and ranges in the
+// Linux cpuset list format.
+func ValidateReservedCPUs(cpus string, fldPath *field.Path) field.ErrorList {
+	if len(cpus) == 0 {
+		return nil
+	}
+	if _, err := ParseCPUSet(cpus); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, cpus, err.Error())}
+	}
+	return nil
+}
+
+// ParseCPUSet returns the sorted cpu ids of a cpuset in the Linux list format, e.g. "0-1,52-53".
+func ParseCPUSet(cpus string) ([]int, error) {
+	ids := map[int]bool{}
+	for _, item := range strings.Split(cpus, ",") {
+		bounds := strings.SplitN(item, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid cpu %q", item)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid cpu range %q", item)
+			}
+		}
+		for id := first; id <= last; id++ {
+			if ids[id] {
+				return nil, fmt.Errorf("cpu %d listed more than once", id)
+			}
+			ids[id] = true
+		}
+	}
+	var ret []int
+	for id := range ids {
+		ret = append(ret, id)
+	}
+	sort.Ints(ret)
+	return ret, nil
+}
+
+// StaticPodVolumes are the volumes of the kube-apiserver static pod that sidecars can mount.
+var StaticPodVolumes = sets.NewString("resource-dir", "cert-dir", "audit-dir")
+
+// staticPodPorts are the host ports used by the containers of the kube-apiserver static pod.
+var staticPodPorts = sets.NewInt32(6443, 6080, 17697)
+
+// ValidateSidecars validates the sidecars field. Sidecars must not collide with the containers, volumes and ports of
+// the static pod and can only use volumes a static pod can mount.
+func ValidateSidecars(sidecars SidecarConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	volumeNames := sets.NewString()
+	for i, volume := range sidecars.Volumes {
+		volumePath := fldPath.Child("volumes").Index(i)
+		switch {
+		case len(volume.Name) == 0:
+			errs = append(errs, field.Required(volumePath.Child("name"), ""))
+		case StaticPodVolumes.Has(volume.Name) || volumeNames.Has(volume.Name):
+			errs = append(errs, field.Duplicate(volumePath.Child("name"), volume.Name))
+		}
+		volumeNames.Insert(volume.Name)
+		if volume.HostPath == nil && volume.EmptyDir == nil {
+			errs = append(errs, field.Invalid(volumePath, volume.Name, "only hostPath and emptyDir volumes are supported"))
+		}
+	}
+	mountableVolumes := volumeNames.Union(StaticPodVolumes)
+
+	containerNames := sets.NewString()
+	for i, container := range sidecars.Containers {
+		containerPath := fldPath.Child("containers").Index(i)
+		switch {
+		case len(container.Name) == 0:
+			errs = append(errs, field.Required(containerPath.Child("name"), ""))
+		case StaticPodContainers.Has(container.Name) || containerNames.Has(container.Name):
+			errs = append(errs, field.Duplicate(containerPath.Child("name"), container.Name))
+		}
+		containerNames.Insert(container.Name)
+		if len(container.Image) == 0 {
+			errs = append(errs, field.Required(containerPath.Child("image"), ""))
+		}
+		for j, mount := range container.VolumeMounts {
+			if !mountableVolumes.Has(mount.Name) {
+				errs = append(errs, field.NotFound(containerPath.Child("volumeMounts").Index(j).Child("name"), mount.Name))
+			}
+		}
+		for j, port := range container.Ports {
+			if staticPodPorts.Has(port.ContainerPort) || staticPodPorts.Has(port.HostPort) {
+				errs = append(errs, field.Invalid(containerPath.Child("ports").Index(j), port.ContainerPort, "is used by the kube-apiserver static pod"))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateProbes validates the probes field.
+func ValidateProbes(probes ProbesConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateProbe(probes.Liveness, fldPath.Child("liveness"))...)
+	errs = append(errs, validateProbe(probes.Readiness, fldPath.Child("readiness"))...)
+	errs = append(errs, validateProbe(probes.Startup, fldPath.Child("startup"))...)
+	return errs
+}
+
+func validateProbe(probe *ProbeConfig, fldPath *field.Path) field.ErrorList {
+	if probe == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	errs = append(errs, validateRange(probe.InitialDelaySeconds, 0, 600, fldPath.Child("initialDelaySeconds"))...)
+	errs = append(errs, validateRange(probe.PeriodSeconds, 1, 300, fldPath.Child("periodSeconds"))...)
+	errs = append(errs, validateRange(probe.TimeoutSeconds, 1, 300, fldPath.Child("timeoutSeconds"))...)
+	errs = append(errs, validateRange(probe.FailureThreshold, 1, 100, fldPath.Child("failureThreshold"))...)
+	return errs
+}
+
+func validateRange(value *int32, min, max int32, fldPath *field.Path) field.ErrorList {
+	if value == nil || (*value >= min && *value <= max) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, *value, fmt.Sprintf("must be between %d and %d", min, max))}
+}
+
+// ValidateAdvertiseAddressSubnets validates the advertiseAddressSubnets field.
+func ValidateAdvertiseAddressSubnets(subnets []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		switch {
+		case err != nil:
+			errs = append(errs, field.Invalid(fldPath.Index(i), subnet, "must be a CIDR"))
+		case seen.Has(ipNet.String()):
+			errs = append(errs, field.Duplicate(fldPath.Index(i), subnet))
+		default:
+			seen.Insert(ipNet.String())
+		}
+	}
+	return errs
+}
+
+// AllowedEnvironment are the environment variables that can be set on the kube-apiserver container, with a check of
+// their value. They only tune the Go runtime.
+var AllowedEnvironment = map[string]func(value string) error{
+	"GOGC":        validateGOGC,
+	"GODEBUG":     validateGODEBUG,
+	"GOMAXPROCS":  validatePositiveInt,
+	"GOMEMLIMIT":  validateGOMEMLIMIT,
+	"GOTRACEBACK": validateGOTRACEBACK,
+}
+
+var (
+	godebugPattern    = regexp.MustCompile(`^[a-z0-9]+=[a-zA-Z0-9.]+(,[a-z0-9]+=[a-zA-Z0-9.]+)*$`)
+	gomemlimitPattern = regexp.MustCompile(`^[0-9]+(B|KiB|MiB|GiB|TiB)?$`)
+	gotracebackValues = sets.NewString("none", "single", "all", "system", "crash")
+)
+
+func validateGOGC(value string) error {
+	if value == "off" {
+		return nil
+	}
+	return validatePositiveInt(value)
+}
+
+func validateGODEBUG(value string) error {
+	if !godebugPattern.MatchString(value) {
+		return fmt.Errorf("must be a comma separated list of name=value")
+	}
+	return nil
+}
+
+func validatePositiveInt(value string) error {
+	if i, err := strconv.Atoi(value); err != nil || i <= 0 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func validateGOMEMLIMIT(value string) error {
+	if value != "off" && !gomemlimitPattern.MatchString(value) {
+		return fmt.Errorf("must be off or a number of bytes with an optional B, KiB, MiB, GiB or TiB suffix")
+	}
+	return nil
+}
+
+func validateGOTRACEBACK(value string) error {
+	if !gotracebackValues.Has(value) {
+		return fmt.Errorf("must be one of %s", strings.Join(gotracebackValues.List(), ", "))
+	}
+	return nil
+}
+
+// ValidateEnvironment validates the environment field.
+func ValidateEnvironment(environment map[string]string, fldPath *field.Path) field.ErrorList {
+	var allowed []string
+	for name := range AllowedEnvironment {
+		allowed = append(allowed, name)
+	}
+	sort.Strings(allowed)
+
+	var errs field.ErrorList
+	for name, value := range environment {
+		validate, ok := AllowedEnvironment[name]
+		if !ok {
+			errs = append(errs, field.NotSupported(fldPath, name, allowed))
+			continue
+		}
+		if err := validate(value); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(name), value, err.Error()))
+		}
+	}
+	return errs
+}
+
+// ValidateAuditLog validates the auditLog field. A maxBackup of 0 is rejected, the kube-apiserver would keep every
+// rotated audit log and fill up the disk.
+func ValidateAuditLog(config AuditLogConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateRange(config.MaxSize, 1, 10240, fldPath.Child("maxSize"))...)
+	errs = append(errs, validateRange(config.MaxBackup, 1, 1000, fldPath.Child("maxBackup"))...)
+	errs = append(errs, validateRange(config.MaxAge, 0, 3650, fldPath.Child("maxAge"))...)
+	errs = append(errs, validateRange(config.MinAvailablePercent, 1, 90, fldPath.Child("minAvailablePercent"))...)
+	return errs
+}
+
+var supportedAuditForwarderProtocols = sets.NewString(string(AuditForwarderSyslog))
+
+// ValidateAuditForwarder validates the auditForwarder field.
+func ValidateAuditForwarder(config *AuditForwarderConfig, fldPath *field.Path) field.ErrorList {
+	if config == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if !supportedAuditForwarderProtocols.Has(string(config.Protocol)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("protocol"), config.Protocol, supportedAuditForwarderProtocols.List()))
+	}
+	if len(config.Endpoint) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("endpoint"), ""))
+	} else if host, port, err := net.SplitHostPort(config.Endpoint); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("endpoint"), config.Endpoint, err.Error()))
+	} else if len(host) == 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("endpoint"), config.Endpoint, "must have a host"))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		errs = append(errs, field.Invalid(fldPath.Child("endpoint"), config.Endpoint, "must have a port between 1 and 65535"))
+	}
+	if config.TLS != nil && len(config.TLS.CAConfigMap) > 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(config.TLS.CAConfigMap) {
+			errs = append(errs, field.Invalid(fldPath.Child("tls", "caConfigMap"), config.TLS.CAConfigMap, msg))
+		}
+	}
+	return errs
+}
+
+var supportedAuditWebhookModes = sets.NewString(string(AuditWebhookBatch), string(AuditWebhookBlocking), string(AuditWebhookBlockingStrict))
+
+// ValidateAuditWebhook validates the auditWebhook field. The kubeconfig of the secret is validated by the config
+// observer, which reads the secret. The batch settings are rejected in the blocking modes, which do not batch.
+func ValidateAuditWebhook(config *AuditWebhookConfig, fldPath *field.Path) field.ErrorList {
+	if config == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	switch {
+	case len(config.KubeConfigSecret) > 0 && len(config.URL) > 0:
+		errs = append(errs, field.Forbidden(fldPath.Child("url"), "must not be set together with kubeConfigSecret"))
+	case len(config.KubeConfigSecret) > 0:
+		for _, msg := range validation.IsDNS1123Subdomain(config.KubeConfigSecret) {
+			errs = append(errs, field.Invalid(fldPath.Child("kubeConfigSecret"), config.KubeConfigSecret, msg))
+		}
+		if len(config.CAConfigMap) > 0 || len(config.ClientCertSecret) > 0 {
+			errs = append(errs, field.Forbidden(fldPath, "caConfigMap and clientCertSecret only apply to url, the kubeconfig of kubeConfigSecret holds its own"))
+		}
+	case len(config.URL) > 0:
+		if u, err := url.Parse(config.URL); err != nil || u.Scheme != "https" || len(u.Host) == 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("url"), config.URL, "must be an https URL with a host"))
+		}
+		if len(config.ClientCertSecret) == 0 {
+			errs = append(errs, field.Required(fldPath.Child("clientCertSecret"), "required with url"))
+		}
+		for _, name := range []struct {
+			path  *field.Path
+			value string
+		}{
+			{path: fldPath.Child("caConfigMap"), value: config.CAConfigMap},
+			{path: fldPath.Child("clientCertSecret"), value: config.ClientCertSecret},
+		} {
+			if len(name.value) == 0 {
+				continue
+			}
+			for _, msg := range validation.IsDNS1123Subdomain(name.value) {
+				errs = append(errs, field.Invalid(name.path, name.value, msg))
+			}
+		}
+	default:
+		errs = append(errs, field.Required(fldPath.Child("kubeConfigSecret"), "either kubeConfigSecret or url is required"))
+	}
+	if len(config.Mode) > 0 && !supportedAuditWebhookModes.Has(string(config.Mode)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("mode"), config.Mode, supportedAuditWebhookModes.List()))
+	}
+	errs = append(errs, validateDuration(config.InitialBackoff, 100*time.Millisecond, 10*time.Minute, fldPath.Child("initialBackoff"))...)
+
+	batchPath := fldPath.Child("batch")
+	if len(config.Mode) > 0 && config.Mode != AuditWebhookBatch && config.Batch != (AuditWebhookBatchConfig{}) {
+		errs = append(errs, field.Forbidden(batchPath, fmt.Sprintf("only applies to the %s mode", AuditWebhookBatch)))
+	}
+	errs = append(errs, validateRange(config.Batch.BufferSize, 1, 1000000, batchPath.Child("bufferSize"))...)
+	errs = append(errs, validateRange(config.Batch.MaxSize, 1, 10000, batchPath.Child("maxSize"))...)
+	errs = append(errs, validateDuration(config.Batch.MaxWait, 100*time.Millisecond, 10*time.Minute, batchPath.Child("maxWait"))...)
+	errs = append(errs, validateRange(config.Batch.ThrottleQPS, 1, 10000, batchPath.Child("throttleQPS"))...)
+	errs = append(errs, validateRange(config.Batch.ThrottleBurst, 1, 10000, batchPath.Child("throttleBurst"))...)
+	if config.Batch.BufferSize != nil && config.Batch.MaxSize != nil && *config.Batch.MaxSize > *config.Batch.BufferSize {
+		errs = append(errs, field.Invalid(batchPath.Child("maxSize"), *config.Batch.MaxSize, "must not be larger than bufferSize"))
+	}
+	return errs
+}
+
+// ValidateAuditPolicy validates the auditPolicy field. The custom rules themselves are validated by the audit policy
+// controller, which reads their config map.
+func ValidateAuditPolicy(config AuditPolicyConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(config.CustomRulesConfigMap) > 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(config.CustomRulesConfigMap) {
+			errs = append(errs, field.Invalid(fldPath.Child("customRulesConfigMap"), config.CustomRulesConfigMap, msg))
+		}
+	}
+	for i, override := range config.Overrides {
+		errs = append(errs, validateAuditPolicyOverride(override, fldPath.Child("overrides").Index(i))...)
+	}
+	for i, intent := range config.Intents {
+		errs = append(errs, validateAuditPolicyIntent(intent, fldPath.Child("intents").Index(i))...)
+	}
+	for i, exemption := range config.Exemptions {
+		errs = append(errs, validateAuditPolicyExemption(exemption, fldPath.Child("exemptions").Index(i))...)
+	}
+	if config.Sampling != nil && !supportedAuditSamplingReadLevels.Has(string(config.Sampling.ReadLevel)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("sampling", "readLevel"), config.Sampling.ReadLevel, supportedAuditSamplingReadLevels.List()))
+	}
+	return errs
+}
+
+// supportedAuditSamplingReadLevels are the levels that reduce the events of read requests of every profile, the
+// profiles log reads at Metadata at least.
+var supportedAuditSamplingReadLevels = sets.NewString(string(auditv1.LevelNone), string(auditv1.LevelMetadata))
+
+var supportedAuditProfiles = sets.NewString(
+	string(configv1.NoneAuditProfileType),
+	string(configv1.DefaultAuditProfileType),
+	string(configv1.WriteRequestBodiesAuditProfileType),
+	string(configv1.AllRequestBodiesAuditProfileType),
+)
+
+// auditResourceRegexp matches a resource or a subresource of audit policy rules, e.g. "pods/exec" or "*/status".
+var auditResourceRegexp = regexp.MustCompile(`^([a-z0-9.-]+|\*)(/([a-z0-9.-]+|\*))?$`)
+
+func validateAuditPolicyOverride(override AuditPolicyOverride, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if !supportedAuditProfiles.Has(string(override.Profile)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("profile"), override.Profile, supportedAuditProfiles.List()))
+	}
+	if len(override.Namespaces) == 0 && len(override.Resources) == 0 {
+		errs = append(errs, field.Required(fldPath, "namespaces or resources must be set, set the profile of apiserver/cluster to change the profile of every request"))
+	}
+
+	seen := sets.NewString()
+	for i, namespace := range override.Namespaces {
+		name := strings.TrimSuffix(namespace, "*")
+		if name != namespace && len(name) == 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, "must have a prefix before *"))
+		} else if name != namespace {
+			// a prefix may end in a dash, it does not have to be a valid name itself
+			for _, msg := range validation.IsDNS1123Label(name + "a") {
+				errs = append(errs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, msg))
+			}
+		} else {
+			for _, msg := range validation.IsDNS1123Label(name) {
+				errs = append(errs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, msg))
+			}
+		}
+		if seen.Has(namespace) {
+			errs = append(errs, field.Duplicate(fldPath.Child("namespaces").Index(i), namespace))
+		}
+		seen.Insert(namespace)
+	}
+
+	errs = append(errs, validateAuditPolicyResources(override.Resources, fldPath.Child("resources"))...)
+	return errs
+}
+
+func validateAuditPolicyResources(groupResources []AuditPolicyResources, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, resources := range groupResources {
+		if len(resources.Group) > 0 {
+			for _, msg := range validation.IsDNS1123Subdomain(resources.Group) {
+				errs = append(errs, field.Invalid(fldPath.Index(i).Child("group"), resources.Group, msg))
+			}
+		}
+		if seen.Has(resources.Group) {
+			errs = append(errs, field.Duplicate(fldPath.Index(i).Child("group"), resources.Group))
+		}
+		seen.Insert(resources.Group)
+		for j, resource := range resources.Resources {
+			if !auditResourceRegexp.MatchString(resource) {
+				errs = append(errs, field.Invalid(fldPath.Index(i).Child("resources").Index(j), resource, "must be a lowercase resource, optionally with a subresource, e.g. pods/exec"))
+			}
+		}
+	}
+	return errs
+}
+
+var supportedAuditLevels = sets.NewString(string(auditv1.LevelNone), string(auditv1.LevelMetadata), string(auditv1.LevelRequest), string(auditv1.LevelRequestResponse))
+
+// auditVerbRegexp matches a verb of the kube-apiserver authorizer, e.g. "deletecollection" or a custom verb like
+// "impersonate".
+var auditVerbRegexp = regexp.MustCompile(`^[a-z]+$`)
+
+func validateAuditPolicyIntent(intent AuditPolicyIntent, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if !supportedAuditLevels.Has(string(intent.Level)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("level"), intent.Level, supportedAuditLevels.List()))
+	}
+	if len(intent.Users) == 0 && len(intent.Groups) == 0 && len(intent.Verbs) == 0 && len(intent.Resources) == 0 && len(intent.Namespaces) == 0 {
+		errs = append(errs, field.Required(fldPath, "users, groups, verbs, resources or namespaces must be set, set the profile of apiserver/cluster to change the level of every request"))
+	}
+	errs = append(errs, validateAuditSubjects(intent.Users, intent.Groups, fldPath)...)
+	errs = append(errs, validateAuditVerbs(intent.Verbs, fldPath.Child("verbs"))...)
+	errs = append(errs, validateAuditPolicyResources(intent.Resources, fldPath.Child("resources"))...)
+	seen := sets.NewString()
+	for i, namespace := range intent.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace, msg))
+		}
+		if seen.Has(namespace) {
+			errs = append(errs, field.Duplicate(fldPath.Child("namespaces").Index(i), namespace))
+		}
+		seen.Insert(namespace)
+	}
+	return errs
+}
+
+// supportedAuditExemptionLevels are the levels below the ones that log request bodies.
+var supportedAuditExemptionLevels = sets.NewString(string(auditv1.LevelNone), string(auditv1.LevelMetadata))
+
+// unexemptableGroups are the groups every request is a member of.
+var unexemptableGroups = sets.NewString("system:authenticated", "system:unauthenticated")
+
+func validateAuditPolicyExemption(exemption AuditPolicyExemption, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(exemption.Level) > 0 && !supportedAuditExemptionLevels.Has(string(exemption.Level)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("level"), exemption.Level, supportedAuditExemptionLevels.List()))
+	}
+	if len(exemption.Users) == 0 && len(exemption.Groups) == 0 {
+		errs = append(errs, field.Required(fldPath, "users or groups must be set"))
+	}
+	errs = append(errs, validateAuditSubjects(exemption.Users, exemption.Groups, fldPath)...)
+	for i, group := range exemption.Groups {
+		if unexemptableGroups.Has(group) {
+			errs = append(errs, field.Invalid(fldPath.Child("groups").Index(i), group, "every request is a member, set the profile of apiserver/cluster to change the level of every request"))
+		}
+	}
+	errs = append(errs, validateAuditVerbs(exemption.Verbs, fldPath.Child("verbs"))...)
+	return errs
+}
+
+// validateAuditSubjects rejects empty and duplicate users and groups of audit policy rules.
+func validateAuditSubjects(users, groups []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{
+		{name: "users", values: users},
+		{name: "groups", values: groups},
+	} {
+		seen := sets.NewString()
+		for i, value := range list.values {
+			if len(value) == 0 {
+				errs = append(errs, field.Required(fldPath.Child(list.name).Index(i), ""))
+			}
+			if seen.Has(value) {
+				errs = append(errs, field.Duplicate(fldPath.Child(list.name).Index(i), value))
+			}
+			seen.Insert(value)
+		}
+	}
+	return errs
+}
+
+func validateAuditVerbs(verbs []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, verb := range verbs {
+		if !auditVerbRegexp.MatchString(verb) {
+			errs = append(errs, field.Invalid(fldPath.Index(i), verb, "must be a lowercase verb, e.g. delete"))
+		}
+		if seen.Has(verb) {
+			errs = append(errs, field.Duplicate(fldPath.Index(i), verb))
+		}
+		seen.Insert(verb)
+	}
+	return errs
+}
+
+// ValidateShutdownDelayDuration validates the shutdownDelayDuration field. The graceful termination of the static pod
+// is measured in whole seconds, so is the shutdown delay.
+func ValidateShutdownDelayDuration(value string, fldPath *field.Path) field.ErrorList {
+	if len(value) == 0 {
+		return nil
+	}
+	if errs := validateDuration(value, 0, 10*time.Minute, fldPath); len(errs) > 0 {
+		return errs
+	}
+	if d, _ := time.ParseDuration(value); d%time.Second != 0 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a whole number of seconds")}
+	}
+	return nil
+}
+
+// imagePullSpecPattern matches an image pull spec with a tag or a digest, e.g.
+// "quay.io/user/hyperkube:dev" or "quay.io/user/hyperkube@sha256:<64 hex digits>".
+var imagePullSpecPattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)+(:[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}|@sha256:[a-f0-9]{64})$`)
+
+// ValidateOperandImage validates the operandImage field.
+func ValidateOperandImage(image string, fldPath *field.Path) field.ErrorList {
+	if len(image) == 0 || imagePullSpecPattern.MatchString(image) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath, image, "must be an image pull spec with a registry and a tag or digest, e.g. \"quay.io/user/hyperkube:dev\"")}
+}
+
+// ValidateNonRoot validates the nonRoot field.
+func ValidateNonRoot(config *NonRootConfig, fldPath *field.Path) field.ErrorList {
+	if config == nil || (config.UID > 0 && config.UID <= math.MaxInt32) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(fldPath.Child("uid"), config.UID, fmt.Sprintf("must be between 1 and %d", math.MaxInt32))}
+}
+
+// seLinuxNamePattern matches the SELinux user, role and type of a label, seLinuxLevelPattern an MLS/MCS level like
+// "s0:c123,c456".
+var (
+	seLinuxNamePattern  = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
+	seLinuxLevelPattern = regexp.MustCompile(`^s[0-9]+(-s[0-9]+)?(:c[0-9]+([.,]c[0-9]+)*)?$`)
+)
+
+// ValidateSecurityContext validates the securityContext field.
+func ValidateSecurityContext(config SecurityContextConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if profile := config.SeccompProfile; profile != nil {
+		profilePath := fldPath.Child("seccompProfile")
+		switch profile.Type {
+		case corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+			if profile.LocalhostProfile != nil {
+				errs = append(errs, field.Forbidden(profilePath.Child("localhostProfile"), "only allowed with type Localhost"))
+			}
+		case corev1.SeccompProfileTypeLocalhost:
+			switch {
+			case profile.LocalhostProfile == nil || len(*profile.LocalhostProfile) == 0:
+				errs = append(errs, field.Required(profilePath.Child("localhostProfile"), ""))
+			case strings.HasPrefix(*profile.LocalhostProfile, "/") || sets.NewString(strings.Split(*profile.LocalhostProfile, "/")...).Has(".."):
+				errs = append(errs, field.Invalid(profilePath.Child("localhostProfile"), *profile.LocalhostProfile, "must be a relative path without .."))
+			}
+		default:
+			errs = append(errs, field.NotSupported(profilePath.Child("type"), profile.Type, []string{
+				string(corev1.SeccompProfileTypeRuntimeDefault), string(corev1.SeccompProfileTypeUnconfined), string(corev1.SeccompProfileTypeLocalhost),
+			}))
+		}
+	}
+
+	if options := config.SELinuxOptions; options != nil {
+		optionsPath := fldPath.Child("seLinuxOptions")
+		for name, value := range map[string]string{"user": options.User, "role": options.Role, "type": options.Type} {
+			if len(value) > 0 && !seLinuxNamePattern.MatchString(value) {
+				errs = append(errs, field.Invalid(optionsPath.Child(name), value, "must be an SELinux identifier"))
+			}
+		}
+		if len(options.Level) > 0 && !seLinuxLevelPattern.MatchString(options.Level) {
+			errs = append(errs, field.Invalid(optionsPath.Child("level"), options.Level, "must be an SELinux level, e.g. \"s0:c123,c456\""))
+		}
+	}
+
+	return errs
+}
+
+// ValidateInsecureReadyz validates the insecureReadyz field. The port must be unprivileged and must not collide with
+// the other ports of the static pod.
+func ValidateInsecureReadyz(config InsecureReadyzConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if config.Port == nil {
+		return errs
+	}
+	portPath := fldPath.Child("port")
+	switch port := *config.Port; {
+	case config.Disabled:
+		errs = append(errs, field.Forbidden(portPath, "must not be set when the sidecar is disabled"))
+	case port < 1024 || port > 65535:
+		errs = append(errs, field.Invalid(portPath, port, "must be between 1024 and 65535"))
+	case port != 6080 && staticPodPorts.Has(port):
+		errs = append(errs, field.Invalid(portPath, port, "is used by the kube-apiserver static pod"))
+	}
+	return errs
+}
+
+var supportedStartupMonitorModes = sets.NewString(string(StartupMonitorEnabled), string(StartupMonitorDisabled))
+
+// ValidateStartupMonitor validates the startupMonitor field. The fallback timeout has to leave the kube-apiserver
+// enough time to start, the readyz checks have to fit into it.
+func ValidateStartupMonitor(config StartupMonitorConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(config.Mode) > 0 && !supportedStartupMonitorModes.Has(string(config.Mode)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("mode"), config.Mode, supportedStartupMonitorModes.List()))
+	}
+	errs = append(errs, validateDuration(config.FallbackTimeout, 2*time.Minute, 30*time.Minute, fldPath.Child("fallbackTimeout"))...)
+	errs = append(errs, validateRange(config.ReadyzSuccessThreshold, 1, 20, fldPath.Child("readyzSuccessThreshold"))...)
+	errs = append(errs, validateDuration(config.ReadyzInterval, time.Second, time.Minute, fldPath.Child("readyzInterval"))...)
+	errs = append(errs, validateRange(config.CrashLoopThreshold, 1, 20, fldPath.Child("crashLoopThreshold"))...)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	fallbackTimeout, threshold, interval := 300*time.Second, int32(3), 5*time.Second
+	if len(config.FallbackTimeout) > 0 {
+		fallbackTimeout, _ = time.ParseDuration(config.FallbackTimeout)
+	}
+	if config.ReadyzSuccessThreshold != nil {
+		threshold = *config.ReadyzSuccessThreshold
+	}
+	if len(config.ReadyzInterval) > 0 {
+		interval, _ = time.ParseDuration(config.ReadyzInterval)
+	}
+	if checks := time.Duration(threshold) * interval; checks >= fallbackTimeout {
+		errs = append(errs, field.Invalid(fldPath.Child("readyzSuccessThreshold"), threshold, fmt.Sprintf("the readyz checks take %v, they must be shorter than the fallback timeout %v", checks, fallbackTimeout)))
+	}
+	return errs
+}
+
+var (
+	// deniedHostPaths cannot be mounted, nor anything below them. The operator and the kubelet manage the files
+	// below /etc/kubernetes and /var/lib/kubelet, etcd stores its data below /var/lib/etcd.
+	deniedHostPaths = []string{"/proc", "/sys", "/dev", "/etc/kubernetes", "/var/lib/kubelet", "/var/lib/etcd"}
+
+	// kubeAPIServerMountPaths are the mounts of the kube-apiserver container, the host path mounts must not shadow
+	// them. The trust bundle is copied to /tmp when the container runs as non-root.
+	kubeAPIServerMountPaths = []string{"/etc/kubernetes/static-pod-resources", "/etc/kubernetes/static-pod-certs", "/var/log/kube-apiserver", "/tmp"}
+
+	supportedHostPathTypes = sets.NewString(string(corev1.HostPathDirectory), string(corev1.HostPathFile), string(corev1.HostPathSocket), string(corev1.HostPathCharDev))
+)
+
+// ValidateHostPathMounts validates the hostPathMounts field. The volume names must not be in usedVolumes, the paths
+// must be absolute and clean, and must not reach into the files of the operator, the kubelet or the kube-apiserver
+// container.
+func ValidateHostPathMounts(mounts []HostPathMount, usedVolumes sets.String, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	names := sets.NewString()
+	mountPaths := sets.NewString()
+	for i, mount := range mounts {
+		mountFldPath := fldPath.Index(i)
+
+		switch {
+		case len(mount.Name) == 0:
+			errs = append(errs, field.Required(mountFldPath.Child("name"), ""))
+		case usedVolumes.Has(mount.Name) || names.Has(mount.Name):
+			errs = append(errs, field.Duplicate(mountFldPath.Child("name"), mount.Name))
+		default:
+			for _, msg := range validation.IsDNS1123Label(mount.Name) {
+				errs = append(errs, field.Invalid(mountFldPath.Child("name"), mount.Name, msg))
+			}
+		}
+		names.Insert(mount.Name)
+
+		if err := validateAbsolutePath(mount.HostPath); err != nil {
+			errs = append(errs, field.Invalid(mountFldPath.Child("hostPath"), mount.HostPath, err.Error()))
+		} else {
+			for _, denied := range deniedHostPaths {
+				if isSubPath(mount.HostPath, denied) || isSubPath(denied, mount.HostPath) {
+					errs = append(errs, field.Forbidden(mountFldPath.Child("hostPath"), fmt.Sprintf("must not overlap with %s", denied)))
+					break
+				}
+			}
+		}
+
+		if err := validateAbsolutePath(mount.MountPath); err != nil {
+			errs = append(errs, field.Invalid(mountFldPath.Child("mountPath"), mount.MountPath, err.Error()))
+		} else {
+			for _, used := range append(kubeAPIServerMountPaths, mountPaths.List()...) {
+				if isSubPath(mount.MountPath, used) || isSubPath(used, mount.MountPath) {
+					errs = append(errs, field.Forbidden(mountFldPath.Child("mountPath"), fmt.Sprintf("must not overlap with %s", used)))
+					break
+				}
+			}
+			mountPaths.Insert(mount.MountPath)
+		}
+
+		if len(mount.Type) > 0 && !supportedHostPathTypes.Has(string(mount.Type)) {
+			errs = append(errs, field.NotSupported(mountFldPath.Child("type"), mount.Type, supportedHostPathTypes.List()))
+		}
+	}
+	return errs
+}
+
+// validateAbsolutePath accepts clean absolute paths below a top level directory, e.g. "/var/run/kmsplugin".
+func validateAbsolutePath(value string) error {
+	switch {
+	case !path.IsAbs(value):
+		return fmt.Errorf("must be an absolute path")
+	case path.Clean(value) != value:
+		return fmt.Errorf("must be a clean path, e.g. %q", path.Clean(value))
+	case strings.Count(value, "/") < 2:
+		return fmt.Errorf("must be below a top level directory")
+	}
+	return nil
+}
+
+// isSubPath returns true if p is parent or below it.
+func isSubPath(p, parent string) bool {
+	return p == parent || strings.HasPrefix(p, parent+"/")
+}
+
+var supportedRolloutStrategies = sets.NewString(string(RolloutSerial), string(RolloutCanary))
+
+// ValidateRollout validates the rollout field.
+func ValidateRollout(config RolloutConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(config.Strategy) > 0 && !supportedRolloutStrategies.Has(string(config.Strategy)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("strategy"), config.Strategy, supportedRolloutStrategies.List()))
+	}
+	if len(config.CanarySoakPeriod) > 0 && config.Strategy != RolloutCanary {
+		errs = append(errs, field.Forbidden(fldPath.Child("canarySoakPeriod"), "only allowed with the Canary strategy"))
+	}
+	errs = append(errs, validateDuration(config.CanarySoakPeriod, time.Minute, 2*time.Hour, fldPath.Child("canarySoakPeriod"))...)
+	errs = append(errs, validateNodeGates(config.NodeGates, fldPath.Child("nodeGates"))...)
+	errs = append(errs, validateMaintenanceWindows(config.MaintenanceWindows, fldPath.Child("maintenanceWindows"))...)
+	for i, check := range config.SkipPreflightChecks {
+		if !PreflightChecks.Has(check) {
+			errs = append(errs, field.NotSupported(fldPath.Child("skipPreflightChecks").Index(i), check, PreflightChecks.List()))
+		}
+	}
+	errs = append(errs, validateNodeExclusions(config.ExcludedNodes, fldPath.Child("excludedNodes"))...)
+	errs = append(errs, validateDuration(config.DelayBetweenNodes, 0, 2*time.Hour, fldPath.Child("delayBetweenNodes"))...)
+	if config.NodeOrder != nil {
+		errs = append(errs, validateNodeOrder(*config.NodeOrder, fldPath.Child("nodeOrder"))...)
+	}
+	if config.SingleNodeFastPath && config.Strategy == RolloutCanary {
+		errs = append(errs, field.Forbidden(fldPath.Child("singleNodeFastPath"), "a single node has no canary to verify"))
+	}
+	return errs
+}
+
+var supportedNodeOrderTypes = sets.NewString(string(NodeOrderUnavailableFirst), string(NodeOrderAlphabetical), string(NodeOrderLeaderLast), string(NodeOrderExplicit))
+
+func validateNodeOrder(order NodeOrderConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(order.Type) > 0 && !supportedNodeOrderTypes.Has(string(order.Type)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("type"), order.Type, supportedNodeOrderTypes.List()))
+	}
+	switch {
+	case order.Type == NodeOrderExplicit && len(order.Nodes) == 0:
+		errs = append(errs, field.Required(fldPath.Child("nodes"), "the nodes are required with the Explicit type"))
+	case order.Type != NodeOrderExplicit && len(order.Nodes) > 0:
+		errs = append(errs, field.Forbidden(fldPath.Child("nodes"), "only allowed with the Explicit type"))
+	}
+	seen := sets.NewString()
+	for i, node := range order.Nodes {
+		idxPath := fldPath.Child("nodes").Index(i)
+		if seen.Has(node) {
+			errs = append(errs, field.Duplicate(idxPath, node))
+			continue
+		}
+		seen.Insert(node)
+		for _, msg := range validation.IsDNS1123Subdomain(node) {
+			errs = append(errs, field.Invalid(idxPath, node, msg))
+		}
+	}
+	return errs
+}
+
+func validateMaintenanceWindows(windows []MaintenanceWindow, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, window := range windows {
+		idxPath := fldPath.Index(i)
+		switch {
+		case len(window.Schedule) == 0:
+			errs = append(errs, field.Required(idxPath.Child("schedule"), "a cron expression is required"))
+		case strings.HasPrefix(window.Schedule, "@every"):
+			errs = append(errs, field.Invalid(idxPath.Child("schedule"), window.Schedule, "intervals are not supported, use a cron expression"))
+		default:
+			if _, err := cron.ParseStandard(window.Schedule); err != nil {
+				errs = append(errs, field.Invalid(idxPath.Child("schedule"), window.Schedule, err.Error()))
+			}
+		}
+		if len(window.Duration) == 0 {
+			errs = append(errs, field.Required(idxPath.Child("duration"), "the length of the window is required"))
+			continue
+		}
+		errs = append(errs, validateDuration(window.Duration, 15*time.Minute, 7*24*time.Hour, idxPath.Child("duration"))...)
+	}
+	return errs
+}
+
+func validateNodeExclusions(exclusions []NodeExclusion, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, exclusion := range exclusions {
+		idxPath := fldPath.Index(i)
+		switch {
+		case len(exclusion.NodeName) == 0:
+			errs = append(errs, field.Required(idxPath.Child("nodeName"), "the name of the excluded node is required"))
+		case seen.Has(exclusion.NodeName):
+			errs = append(errs, field.Duplicate(idxPath.Child("nodeName"), exclusion.NodeName))
+		default:
+			for _, msg := range validation.IsDNS1123Subdomain(exclusion.NodeName) {
+				errs = append(errs, field.Invalid(idxPath.Child("nodeName"), exclusion.NodeName, msg))
+			}
+		}
+		seen.Insert(exclusion.NodeName)
+		errs = append(errs, validateDuration(exclusion.Duration, 10*time.Minute, 7*24*time.Hour, idxPath.Child("duration"))...)
+	}
+	return errs
+}
+
+func validateNodeGates(gates []NodeGate, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(gates) > 10 {
+		errs = append(errs, field.TooMany(fldPath, len(gates), 10))
+	}
+	for i, gate := range gates {
+		idxPath := fldPath.Index(i)
+		switch {
+		case len(gate.ConditionType) > 0 && len(gate.Annotation) > 0:
+			errs = append(errs, field.Invalid(idxPath, gate, "conditionType and annotation are mutually exclusive"))
+		case len(gate.ConditionType) > 0:
+			if len(gate.Value) > 0 {
+				errs = append(errs, field.Forbidden(idxPath.Child("value"), "only allowed with an annotation"))
+			}
+			for _, msg := range validation.IsQualifiedName(gate.ConditionType) {
+				errs = append(errs, field.Invalid(idxPath.Child("conditionType"), gate.ConditionType, msg))
+			}
+		case len(gate.Annotation) > 0:
+			for _, msg := range validation.IsQualifiedName(gate.Annotation) {
+				errs = append(errs, field.Invalid(idxPath.Child("annotation"), gate.Annotation, msg))
+			}
+		default:
+			errs = append(errs, field.Required(idxPath, "either conditionType or annotation is required"))
+		}
+	}
+	return errs
+}
+
+var (
+	supportedTolerationOperators = sets.NewString(string(corev1.TolerationOpExists), string(corev1.TolerationOpEqual))
+	supportedTaintEffects        = sets.NewString(string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute))
+)
+
+func validateTolerations(tolerations []corev1.Toleration, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, toleration := range tolerations {
+		idxPath := fldPath.Index(i)
+		if len(toleration.Operator) > 0 && !supportedTolerationOperators.Has(string(toleration.Operator)) {
+			errs = append(errs, field.NotSupported(idxPath.Child("operator"), toleration.Operator, supportedTolerationOperators.List()))
+		}
+		if toleration.Operator == corev1.TolerationOpExists && len(toleration.Value) > 0 {
+			errs = append(errs, field.Invalid(idxPath.Child("value"), toleration.Value, "must be empty when the operator is Exists"))
+		}
+		if len(toleration.Key) == 0 && toleration.Operator != corev1.TolerationOpExists {
+			errs = append(errs, field.Invalid(idxPath.Child("operator"), toleration.Operator, "must be Exists when the key is empty"))
+		}
+		if len(toleration.Effect) > 0 && !supportedTaintEffects.Has(string(toleration.Effect)) {
+			errs = append(errs, field.NotSupported(idxPath.Child("effect"), toleration.Effect, supportedTaintEffects.List()))
+		}
+		if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+			errs = append(errs, field.Invalid(idxPath.Child("tolerationSeconds"), *toleration.TolerationSeconds, "only allowed with the NoExecute effect"))
+		}
+	}
+	return errs
+}
+
+var supportedInstallerFetchStrategies = sets.NewString(string(InstallerFetchGet), string(InstallerFetchList))
+
+// ValidateInstaller validates the installer field.
+func ValidateInstaller(config InstallerConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateRange(config.MaxAttempts, 1, 100, fldPath.Child("maxAttempts"))...)
+	errs = append(errs, validateDuration(config.Timeout, 30*time.Second, 30*time.Minute, fldPath.Child("timeout"))...)
+	errs = append(errs, validateDuration(config.PodReadyTimeout, time.Minute, 30*time.Minute, fldPath.Child("podReadyTimeout"))...)
+	errs = append(errs, validateRange(config.KeepRevisions, 1, 100, fldPath.Child("keepRevisions"))...)
+	if len(config.MinFreeDisk) > 0 {
+		if quantity, err := resource.ParseQuantity(config.MinFreeDisk); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("minFreeDisk"), config.MinFreeDisk, err.Error()))
+		} else if quantity.Sign() <= 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("minFreeDisk"), config.MinFreeDisk, "must be greater than zero"))
+		}
+	}
+	if len(config.FetchStrategy) > 0 && !supportedInstallerFetchStrategies.Has(string(config.FetchStrategy)) {
+		errs = append(errs, field.NotSupported(fldPath.Child("fetchStrategy"), config.FetchStrategy, supportedInstallerFetchStrategies.List()))
+	}
+	if len(config.MetricsTextfileDir) > 0 {
+		if err := validateAbsolutePath(config.MetricsTextfileDir); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("metricsTextfileDir"), config.MetricsTextfileDir, err.Error()))
+		}
+	}
+	if config.Resources != nil {
+		errs = append(errs, validateResourceRequirements(*config.Resources, fldPath.Child("resources"))...)
+	}
+	if len(config.PriorityClassName) > 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(config.PriorityClassName) {
+			errs = append(errs, field.Invalid(fldPath.Child("priorityClassName"), config.PriorityClassName, msg))
+		}
+	}
+	errs = append(errs, validateTolerations(config.Tolerations, fldPath.Child("tolerations"))...)
+	if config.RetryBackoff == nil {
+		return errs
+	}
+	backoffPath := fldPath.Child("retryBackoff")
+	if len(config.RetryBackoff.InitialDelay) == 0 {
+		return append(errs, field.Required(backoffPath.Child("initialDelay"), "the delay after the first failure is required"))
+	}
+	errs = append(errs, validateDuration(config.RetryBackoff.InitialDelay, 10*time.Second, time.Hour, backoffPath.Child("initialDelay"))...)
+	errs = append(errs, validateDuration(config.RetryBackoff.MaxDelay, 10*time.Second, 6*time.Hour, backoffPath.Child("maxDelay"))...)
+	if len(errs) > 0 || len(config.RetryBackoff.MaxDelay) == 0 {
+		return errs
+	}
+	initialDelay, _ := time.ParseDuration(config.RetryBackoff.InitialDelay)
+	maxDelay, _ := time.ParseDuration(config.RetryBackoff.MaxDelay)
+	if maxDelay < initialDelay {
+		errs = append(errs, field.Invalid(backoffPath.Child("maxDelay"), config.RetryBackoff.MaxDelay, "must not be shorter than the initial delay"))
+	}
+	return errs
+}
+
+// ValidateStaticPodDetection validates the staticPodDetection field.
+func ValidateStaticPodDetection(config StaticPodDetectionConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateDuration(config.MissingPodTimeout, 30*time.Second, time.Hour, fldPath.Child("missingPodTimeout"))...)
+	errs = append(errs, validateDuration(config.FailingPodTimeout, 0, time.Hour, fldPath.Child("failingPodTimeout"))...)
+	return errs
+}
+
+// ValidateConnectivityChecks validates the connectivityChecks field.
+func ValidateConnectivityChecks(config ConnectivityChecksConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	targetsPath := fldPath.Child("additionalTargets")
+	if len(config.AdditionalTargets) > 20 {
+		errs = append(errs, field.TooMany(targetsPath, len(config.AdditionalTargets), 20))
+	}
+	seen := sets.NewString()
+	for i, target := range config.AdditionalTargets {
+		idxPath := targetsPath.Index(i)
+		switch {
+		case len(target.Name) == 0:
+			errs = append(errs, field.Required(idxPath.Child("name"), "a name of the target is required"))
+		case seen.Has(target.Name):
+			errs = append(errs, field.Duplicate(idxPath.Child("name"), target.Name))
+		default:
+			for _, msg := range validation.IsDNS1123Label(target.Name) {
+				errs = append(errs, field.Invalid(idxPath.Child("name"), target.Name, msg))
+			}
+		}
+		seen.Insert(target.Name)
+		if len(target.Endpoint) == 0 {
+			errs = append(errs, field.Required(idxPath.Child("endpoint"), "a host:port is required"))
+		} else if host, port, err := net.SplitHostPort(target.Endpoint); err != nil {
+			errs = append(errs, field.Invalid(idxPath.Child("endpoint"), target.Endpoint, err.Error()))
+		} else if len(host) == 0 {
+			errs = append(errs, field.Invalid(idxPath.Child("endpoint"), target.Endpoint, "a host is required"))
+		} else if portNumber, err := strconv.Atoi(port); err != nil || validation.IsValidPortNum(portNumber) != nil {
+			errs = append(errs, field.Invalid(idxPath.Child("endpoint"), target.Endpoint, "must end with a port between 1 and 65535"))
+		}
+		if len(target.TLSClientCert) > 0 {
+			for _, msg := range validation.IsDNS1123Subdomain(target.TLSClientCert) {
+				errs = append(errs, field.Invalid(idxPath.Child("tlsClientCert"), target.TLSClientCert, msg))
+			}
+		}
+		errs = append(errs, validateConnectivityCheckSettings(target.ConnectivityCheckSettings, idxPath)...)
+	}
+
+	classesPath := fldPath.Child("targetClasses")
+	seenClasses := sets.NewString()
+	for i, class := range config.TargetClasses {
+		idxPath := classesPath.Index(i)
+		switch {
+		case !sets.NewString(ConnectivityCheckTargetClasses...).Has(class.Name):
+			errs = append(errs, field.NotSupported(idxPath.Child("name"), class.Name, ConnectivityCheckTargetClasses))
+		case seenClasses.Has(class.Name):
+			errs = append(errs, field.Duplicate(idxPath.Child("name"), class.Name))
+		}
+		seenClasses.Insert(class.Name)
+		errs = append(errs, validateConnectivityCheckSettings(class.ConnectivityCheckSettings, idxPath)...)
+	}
+
+	disabledPath := fldPath.Child("disabledTargets")
+	seenDisabled := sets.NewString()
+	for i, target := range config.DisabledTargets {
+		idxPath := disabledPath.Index(i)
+		var known bool
+		for _, class := range ConnectivityCheckTargetClasses {
+			known = known || target == class || strings.HasPrefix(target, class+"-")
+		}
+		switch {
+		case !known:
+			errs = append(errs, field.Invalid(idxPath, target, fmt.Sprintf("must be a target class or start with one of %s", strings.Join(ConnectivityCheckTargetClasses, ", "))))
+		case seenDisabled.Has(target):
+			errs = append(errs, field.Duplicate(idxPath, target))
+		}
+		seenDisabled.Insert(target)
+	}
+
+	errs = append(errs, validateDuration(config.SuccessLogInterval, time.Second, time.Hour, fldPath.Child("successLogInterval"))...)
+
+	if retention := config.Retention; retention != nil {
+		retentionPath := fldPath.Child("retention")
+		if retention.MaxOutages < 0 || retention.MaxOutages > 20 {
+			errs = append(errs, field.Invalid(retentionPath.Child("maxOutages"), retention.MaxOutages, "must be between 1 and 20"))
+		}
+		if retention.MaxLogEntries < 0 || retention.MaxLogEntries > 10 {
+			errs = append(errs, field.Invalid(retentionPath.Child("maxLogEntries"), retention.MaxLogEntries, "must be between 1 and 10"))
+		}
+		errs = append(errs, validateDuration(retention.MaxAge, time.Hour, 90*24*time.Hour, retentionPath.Child("maxAge"))...)
+		errs = append(errs, validateDuration(retention.OutageCompactionGap, time.Second, time.Hour, retentionPath.Child("outageCompactionGap"))...)
+	}
+	return errs
+}
+
+// validateConnectivityCheckSettings checks that the interval is between a second and an hour, the timeout between
+// a second and a minute and that the HTTP GET request is valid.
+func validateConnectivityCheckSettings(settings ConnectivityCheckSettings, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateDuration(settings.Interval, time.Second, time.Hour, fldPath.Child("interval"))...)
+	errs = append(errs, validateDuration(settings.Timeout, time.Second, time.Minute, fldPath.Child("timeout"))...)
+	if httpGet := settings.HTTPGet; httpGet != nil {
+		httpGetPath := fldPath.Child("httpGet")
+		if _, err := url.ParseRequestURI(httpGet.Path); err != nil || !strings.HasPrefix(httpGet.Path, "/") || strings.HasPrefix(httpGet.Path, "//") {
+			errs = append(errs, field.Invalid(httpGetPath.Child("path"), httpGet.Path, "must be an absolute path"))
+		}
+		if len(httpGet.Scheme) > 0 && httpGet.Scheme != "HTTP" && httpGet.Scheme != "HTTPS" {
+			errs = append(errs, field.NotSupported(httpGetPath.Child("scheme"), httpGet.Scheme, []string{"HTTP", "HTTPS"}))
+		}
+		for i, code := range httpGet.ExpectedStatusCodes {
+			if code < 100 || code > 599 {
+				errs = append(errs, field.Invalid(httpGetPath.Child("expectedStatusCodes").Index(i), code, "must be between 100 and 599"))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateOperatorTuning validates the operator field. The leader election durations are checked together with the
+// defaults of the ones that are not set.
+func ValidateOperatorTuning(config OperatorTuningConfig, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	leaderElectionPath := fldPath.Child("leaderElection")
+	errs = append(errs, validateDuration(config.LeaderElection.LeaseDuration, 15*time.Second, 10*time.Minute, leaderElectionPath.Child("leaseDuration"))...)
+	errs = append(errs, validateDuration(config.LeaderElection.RenewDeadline, 10*time.Second, 10*time.Minute, leaderElectionPath.Child("renewDeadline"))...)
+	errs = append(errs, validateDuration(config.LeaderElection.RetryPeriod, 2*time.Second, 2*time.Minute, leaderElectionPath.Child("retryPeriod"))...)
+	if len(errs) == 0 {
+		leaseDuration, renewDeadline, retryPeriod := LeaderElectionDurations(config.LeaderElection)
+		if renewDeadline >= leaseDuration {
+			errs = append(errs, field.Invalid(leaderElectionPath.Child("renewDeadline"), renewDeadline.String(), fmt.Sprintf("must be shorter than the lease duration %v", leaseDuration)))
+		}
+		if retryPeriod >= renewDeadline {
+			errs = append(errs, field.Invalid(leaderElectionPath.Child("retryPeriod"), retryPeriod.String(), fmt.Sprintf("must be shorter than the renew deadline %v", renewDeadline)))
+		}
+	}
+
+	for _, controller := range sets.StringKeySet(config.ResyncIntervals).List() {
+		errs = append(errs, validateDuration(config.ResyncIntervals[controller], 10*time.Second, time.Hour, fldPath.Child("resyncIntervals").Key(controller))...)
+	}
+	return errs
+}
+
+// LeaderElectionDurations returns the lease duration, renew deadline and retry period of a valid leader election
+// config, with the defaults of library-go for the ones that are not set.
+func LeaderElectionDurations(config OperatorLeaderElectionConfig) (leaseDuration, renewDeadline, retryPeriod time.Duration) {
+	leaseDuration, renewDeadline, retryPeriod = 137*time.Second, 107*time.Second, 26*time.Second
+	if len(config.LeaseDuration) > 0 {
+		leaseDuration, _ = time.ParseDuration(config.LeaseDuration)
+	}
+	if len(config.RenewDeadline) > 0 {
+		renewDeadline, _ = time.ParseDuration(config.RenewDeadline)
+	}
+	if len(config.RetryPeriod) > 0 {
+		retryPeriod, _ = time.ParseDuration(config.RetryPeriod)
+	}
+	return leaseDuration, renewDeadline, retryPeriod
+}
@@ -0,0 +1,147 @@
+package relatedobjects
+
+import (
+	"fmt"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// RelatedObjects lists the objects the operator currently manages in addition to the static related objects of the
+// clusteroperator, so that must-gather collects them without curating the static list: the revisioned configmaps and
+// secrets of the revisions in use, the encryption key secrets and the control plane nodes.
+type RelatedObjects struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+
+	configMapLister           corev1listers.ConfigMapLister
+	secretLister              corev1listers.SecretLister
+	encryptionKeySecretLister corev1listers.SecretLister
+	cachesToSync              []cache.InformerSynced
+
+	revisionConfigMaps []string
+	revisionSecrets    []string
+}
+
+// NewRelatedObjects returns the related objects of the revisions of the revisionConfigMaps and revisionSecrets of the
+// target namespace.
+func NewRelatedObjects(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	revisionConfigMaps, revisionSecrets []string,
+) *RelatedObjects {
+	targetInformers := kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1()
+	managedInformers := kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1()
+	return &RelatedObjects{
+		operatorClient:            operatorClient,
+		configMapLister:           targetInformers.ConfigMaps().Lister(),
+		secretLister:              targetInformers.Secrets().Lister(),
+		encryptionKeySecretLister: managedInformers.Secrets().Lister(),
+		cachesToSync: []cache.InformerSynced{
+			operatorClient.Informer().HasSynced,
+			targetInformers.ConfigMaps().Informer().HasSynced,
+			targetInformers.Secrets().Informer().HasSynced,
+			managedInformers.Secrets().Informer().HasSynced,
+		},
+		revisionConfigMaps: revisionConfigMaps,
+		revisionSecrets:    revisionSecrets,
+	}
+}
+
+// Get is the status.RelatedObjectsFunc of the clusteroperator. The related objects are not set until the informers
+// synced, the existing ones are kept until then.
+func (r *RelatedObjects) Get() (bool, []configv1.ObjectReference) {
+	for _, synced := range r.cachesToSync {
+		if !synced() {
+			return false, nil
+		}
+	}
+	objs, err := r.list()
+	if err != nil {
+		klog.Warningf("Unable to list the related objects: %v", err)
+		return false, nil
+	}
+	return true, objs
+}
+
+func (r *RelatedObjects) list() ([]configv1.ObjectReference, error) {
+	_, status, _, err := r.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []configv1.ObjectReference
+	for _, revision := range revisionsInUse(status.LatestAvailableRevision, status.NodeStatuses) {
+		for _, name := range r.revisionConfigMaps {
+			name = fmt.Sprintf("%s-%d", name, revision)
+			if _, err := r.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(name); apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			objs = append(objs, configv1.ObjectReference{Resource: "configmaps", Namespace: operatorclient.TargetNamespace, Name: name})
+		}
+		for _, name := range r.revisionSecrets {
+			name = fmt.Sprintf("%s-%d", name, revision)
+			if _, err := r.secretLister.Secrets(operatorclient.TargetNamespace).Get(name); apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			objs = append(objs, configv1.ObjectReference{Resource: "secrets", Namespace: operatorclient.TargetNamespace, Name: name})
+		}
+	}
+
+	keys, err := r.encryptionKeySecretLister.Secrets(operatorclient.GlobalMachineSpecifiedConfigNamespace).List(labels.SelectorFromSet(labels.Set{secrets.EncryptionKeySecretsLabel: operatorclient.TargetNamespace}))
+	if err != nil {
+		return nil, err
+	}
+	keyNames := sets.NewString()
+	for _, key := range keys {
+		keyNames.Insert(key.Name)
+	}
+	for _, name := range keyNames.List() {
+		objs = append(objs, configv1.ObjectReference{Resource: "secrets", Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: name})
+	}
+
+	nodeNames := sets.NewString()
+	for _, nodeStatus := range status.NodeStatuses {
+		nodeNames.Insert(nodeStatus.NodeName)
+	}
+	for _, name := range nodeNames.List() {
+		objs = append(objs, configv1.ObjectReference{Resource: "nodes", Name: name})
+	}
+	return objs, nil
+}
+
+// revisionsInUse returns the latest available revision and the current and target revisions of the nodes in
+// ascending order.
+func revisionsInUse(latestAvailableRevision int32, nodeStatuses []operatorv1.NodeStatus) []int32 {
+	revisions := map[int32]bool{}
+	if latestAvailableRevision > 0 {
+		revisions[latestAvailableRevision] = true
+	}
+	for _, nodeStatus := range nodeStatuses {
+		for _, revision := range []int32{nodeStatus.CurrentRevision, nodeStatus.TargetRevision} {
+			if revision > 0 {
+				revisions[revision] = true
+			}
+		}
+	}
+	var ret []int32
+	for revision := range revisions {
+		ret = append(ret, revision)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
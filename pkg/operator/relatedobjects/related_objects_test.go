@@ -0,0 +1,83 @@
+package relatedobjects
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/encryption/secrets"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestList(t *testing.T) {
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	targetSecrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	managedSecrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, name := range []string{"kube-apiserver-pod-3", "config-3", "kube-apiserver-pod-4", "config-4", "kube-apiserver-pod-5", "config-5", "kube-apiserver-pod-2"} {
+		if err := configMaps.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the optional secret only exists for revision 4
+	for _, name := range []string{"etcd-client-4", "encryption-config-4", "etcd-client-5"} {
+		if err := targetSecrets.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, component := range map[string]string{
+		"openshift-kube-apiserver-encryption-2": "openshift-kube-apiserver",
+		"openshift-kube-apiserver-encryption-1": "openshift-kube-apiserver",
+		"openshift-apiserver-encryption-1":      "openshift-apiserver",
+	} {
+		if err := managedSecrets.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-config-managed",
+			Name:      name,
+			Labels:    map[string]string{secrets.EncryptionKeySecretsLabel: component},
+		}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &RelatedObjects{
+		operatorClient: v1helpers.NewFakeStaticPodOperatorClient(nil, &operatorv1.StaticPodOperatorStatus{
+			LatestAvailableRevision: 5,
+			NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-1", CurrentRevision: 4},
+				{NodeName: "master-0", CurrentRevision: 3, TargetRevision: 4},
+			},
+		}, nil, nil),
+		configMapLister:           corev1listers.NewConfigMapLister(configMaps),
+		secretLister:              corev1listers.NewSecretLister(targetSecrets),
+		encryptionKeySecretLister: corev1listers.NewSecretLister(managedSecrets),
+		revisionConfigMaps:        []string{"kube-apiserver-pod", "config"},
+		revisionSecrets:           []string{"etcd-client", "encryption-config"},
+	}
+
+	objs, err := r.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []configv1.ObjectReference{
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-3"},
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "config-3"},
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-4"},
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "config-4"},
+		{Resource: "secrets", Namespace: "openshift-kube-apiserver", Name: "etcd-client-4"},
+		{Resource: "secrets", Namespace: "openshift-kube-apiserver", Name: "encryption-config-4"},
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-5"},
+		{Resource: "configmaps", Namespace: "openshift-kube-apiserver", Name: "config-5"},
+		{Resource: "secrets", Namespace: "openshift-kube-apiserver", Name: "etcd-client-5"},
+		{Resource: "secrets", Namespace: "openshift-config-managed", Name: "openshift-kube-apiserver-encryption-1"},
+		{Resource: "secrets", Namespace: "openshift-config-managed", Name: "openshift-kube-apiserver-encryption-2"},
+		{Resource: "nodes", Name: "master-0"},
+		{Resource: "nodes", Name: "master-1"},
+	}
+	if diff := cmp.Diff(expected, objs); diff != "" {
+		t.Errorf("unexpected related objects: %s", diff)
+	}
+}
@@ -0,0 +1,202 @@
+package resourcesynccontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// UserResourceSyncConfigMapName is the configmap in the operator namespace with the resource sync rules of the
+	// operator config that are applied, keyed by the kind and the name of the copy, e.g. "configmap.user-vault-ca".
+	UserResourceSyncConfigMapName = "user-resource-sync"
+
+	// UserResourceSyncDegradedConditionType is true while the resource sync rules of the operator config cannot be
+	// applied. Errors copying the resources are reported in ResourceSyncControllerDegraded.
+	UserResourceSyncDegradedConditionType = "UserResourceSyncDegraded"
+
+	configMapKind = "configmap"
+	secretKind    = "secret"
+)
+
+// partialResourceSyncer is the part of the ResourceSyncController of library-go that applies the rules.
+type partialResourceSyncer interface {
+	SyncPartialConfigMap(destination, source resourcesynccontroller.ResourceLocation, keys ...string) error
+	SyncPartialSecret(destination, source resourcesynccontroller.ResourceLocation, keys ...string) error
+}
+
+// userResourceSyncRule is an applied rule as recorded in the UserResourceSyncConfigMapName configmap.
+type userResourceSyncRule struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Keys        []string `json:"keys,omitempty"`
+}
+
+// UserResourceSyncController adds the configmap and secret sync rules of the resourceSync field of the operator
+// config to the resource sync controller. The applied rules are recorded in a configmap, so that the copies of the
+// rules that were removed while the operator was not running are deleted as well.
+type UserResourceSyncController struct {
+	operatorClient          v1helpers.OperatorClient
+	resourceSyncer          partialResourceSyncer
+	configMapClient         corev1client.ConfigMapsGetter
+	secretClient            corev1client.SecretsGetter
+	configConfigMapLister   corev1listers.ConfigMapLister
+	operatorConfigMapLister corev1listers.ConfigMapLister
+}
+
+func NewUserResourceSyncController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	resourceSyncer *resourcesynccontroller.ResourceSyncController,
+	configMapClient corev1client.ConfigMapsGetter,
+	secretClient corev1client.SecretsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &UserResourceSyncController{
+		operatorClient:          operatorClient,
+		resourceSyncer:          resourceSyncer,
+		configMapClient:         configMapClient,
+		secretClient:            secretClient,
+		configConfigMapLister:   kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Lister(),
+		operatorConfigMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Lister(),
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("UserResourceSyncController", c.sync)).ResyncEvery(resyncinterval.For("UserResourceSyncController", 10*time.Minute)).ToController("UserResourceSyncController", eventRecorder.WithComponentSuffix("user-resource-sync-controller"))
+}
+
+func (c *UserResourceSyncController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	reason, err := c.syncRules(ctx, syncCtx.Recorder())
+	cond := operatorv1.OperatorCondition{
+		Type:   UserResourceSyncDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if err != nil {
+		cond.Status = operatorv1.ConditionTrue
+		cond.Reason = reason
+		cond.Message = err.Error()
+	}
+	if _, _, updateErr := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// syncRules applies the rules of the operator config and removes the copies of the rules that are gone. It returns
+// the reason of the degraded condition with the error. An invalid config keeps the applied rules.
+func (c *UserResourceSyncController) syncRules(ctx context.Context, recorder events.Recorder) (string, error) {
+	operatorConfig, err := operatorconfig.Get(c.configConfigMapLister)
+	if err != nil {
+		return "InvalidConfig", err
+	}
+	config := operatorConfig.ResourceSync
+	if errs := operatorconfig.ValidateResourceSync(config, field.NewPath("resourceSync")); len(errs) > 0 {
+		return "InvalidConfig", fmt.Errorf("invalid operator config: %v", errs.ToAggregate())
+	}
+
+	applied, err := c.appliedRules()
+	if err != nil {
+		return "SyncFailed", err
+	}
+	desired := map[string]userResourceSyncRule{}
+	for kind, rules := range map[string][]operatorconfig.ResourceSyncRule{configMapKind: config.ConfigMaps, secretKind: config.Secrets} {
+		for _, rule := range rules {
+			desired[kind+"."+rule.DestinationName()] = userResourceSyncRule{Source: rule.Source, Destination: rule.DestinationName(), Keys: rule.Keys}
+		}
+	}
+
+	for _, key := range sets.StringKeySet(desired).List() {
+		rule := desired[key]
+		source := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: rule.Source}
+		destination := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: rule.Destination}
+		if strings.HasPrefix(key, configMapKind+".") {
+			err = c.resourceSyncer.SyncPartialConfigMap(destination, source, rule.Keys...)
+		} else {
+			err = c.resourceSyncer.SyncPartialSecret(destination, source, rule.Keys...)
+		}
+		if err != nil {
+			return "SyncFailed", err
+		}
+	}
+
+	for _, key := range sets.StringKeySet(applied).Difference(sets.StringKeySet(desired)).List() {
+		rule := applied[key]
+		destination := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: rule.Destination}
+		if strings.HasPrefix(key, configMapKind+".") {
+			err = c.resourceSyncer.SyncPartialConfigMap(destination, resourcesynccontroller.ResourceLocation{})
+			if err == nil {
+				err = c.configMapClient.ConfigMaps(destination.Namespace).Delete(ctx, destination.Name, metav1.DeleteOptions{})
+			}
+		} else {
+			err = c.resourceSyncer.SyncPartialSecret(destination, resourcesynccontroller.ResourceLocation{})
+			if err == nil {
+				err = c.secretClient.Secrets(destination.Namespace).Delete(ctx, destination.Name, metav1.DeleteOptions{})
+			}
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "RemovalFailed", err
+		}
+		recorder.Eventf("UserResourceSyncRuleRemoved", "Removed the %s %s/%s of a removed resource sync rule", strings.Split(key, ".")[0], destination.Namespace, destination.Name)
+	}
+
+	data := map[string]string{}
+	for key, rule := range desired {
+		raw, err := json.Marshal(rule)
+		if err != nil {
+			return "SyncFailed", err
+		}
+		data[key] = string(raw)
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: UserResourceSyncConfigMapName},
+		Data:       data,
+	}); err != nil {
+		return "SyncFailed", err
+	}
+	return "", nil
+}
+
+// appliedRules returns the rules recorded in the UserResourceSyncConfigMapName configmap.
+func (c *UserResourceSyncController) appliedRules() (map[string]userResourceSyncRule, error) {
+	configMap, err := c.operatorConfigMapLister.ConfigMaps(operatorclient.OperatorNamespace).Get(UserResourceSyncConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]userResourceSyncRule{}
+	for key, value := range configMap.Data {
+		var rule userResourceSyncRule
+		if err := json.Unmarshal([]byte(value), &rule); err != nil {
+			return nil, fmt.Errorf("invalid rule %s in configmap %s/%s: %w", key, configMap.Namespace, configMap.Name, err)
+		}
+		rules[key] = rule
+	}
+	return rules, nil
+}
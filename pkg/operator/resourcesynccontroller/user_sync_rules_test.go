@@ -0,0 +1,128 @@
+package resourcesynccontroller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeSyncer struct {
+	configMaps map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation
+	secrets    map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation
+}
+
+func (s *fakeSyncer) SyncPartialConfigMap(destination, source resourcesynccontroller.ResourceLocation, keys ...string) error {
+	s.configMaps[destination] = source
+	return nil
+}
+
+func (s *fakeSyncer) SyncPartialSecret(destination, source resourcesynccontroller.ResourceLocation, keys ...string) error {
+	s.secrets[destination] = source
+	return nil
+}
+
+func TestUserResourceSync(t *testing.T) {
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	operatorConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "kube-apiserver-config"},
+		Data: map[string]string{"config.yaml": `resourceSync:
+  configMaps:
+  - source: vault-ca
+  secrets:
+  - source: vault-token
+    destination: user-token
+    keys: [token]
+`},
+	}
+	if err := configMaps.Add(operatorConfig); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "user-token"}})
+	syncer := &fakeSyncer{configMaps: map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation{}, secrets: map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation{}}
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	c := &UserResourceSyncController{
+		operatorClient:          operatorClient,
+		resourceSyncer:          syncer,
+		configMapClient:         kubeClient.CoreV1(),
+		secretClient:            kubeClient.CoreV1(),
+		configConfigMapLister:   corev1listers.NewConfigMapLister(configMaps),
+		operatorConfigMapLister: corev1listers.NewConfigMapLister(configMaps),
+	}
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	expectedConfigMaps := map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation{
+		{Namespace: "openshift-kube-apiserver", Name: "user-vault-ca"}: {Namespace: "openshift-config", Name: "vault-ca"},
+	}
+	expectedSecrets := map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation{
+		{Namespace: "openshift-kube-apiserver", Name: "user-token"}: {Namespace: "openshift-config", Name: "vault-token"},
+	}
+	if !reflect.DeepEqual(expectedConfigMaps, syncer.configMaps) || !reflect.DeepEqual(expectedSecrets, syncer.secrets) {
+		t.Fatalf("unexpected rules %v %v", syncer.configMaps, syncer.secrets)
+	}
+	applied, err := kubeClient.CoreV1().ConfigMaps("openshift-kube-apiserver-operator").Get(context.TODO(), UserResourceSyncConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied.Data["secret.user-token"] != `{"source":"vault-token","destination":"user-token","keys":["token"]}` || len(applied.Data) != 2 {
+		t.Errorf("unexpected applied rules %v", applied.Data)
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionFalse, "AsExpected")
+
+	// an invalid config keeps the rules
+	operatorConfig.Data["config.yaml"] = "resourceSync:\n  secrets:\n  - source: etcd-client\n    destination: etcd-client\n"
+	if err := c.sync(context.TODO(), syncCtx); err == nil {
+		t.Fatal("expected an error for the invalid config")
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionTrue, "InvalidConfig")
+
+	// removing a rule deletes its copy, also after a restart of the operator
+	if err := configMaps.Add(applied); err != nil {
+		t.Fatal(err)
+	}
+	operatorConfig.Data["config.yaml"] = "resourceSync:\n  configMaps:\n  - source: vault-ca\n"
+	syncer.secrets = map[resourcesynccontroller.ResourceLocation]resourcesynccontroller.ResourceLocation{}
+	if err := c.sync(context.TODO(), syncCtx); err != nil {
+		t.Fatal(err)
+	}
+	if source := syncer.secrets[resourcesynccontroller.ResourceLocation{Namespace: "openshift-kube-apiserver", Name: "user-token"}]; source != (resourcesynccontroller.ResourceLocation{}) || len(syncer.secrets) != 1 {
+		t.Errorf("expected the secret rule to be replaced by a deletion, got %v", syncer.secrets)
+	}
+	if _, err := kubeClient.CoreV1().Secrets("openshift-kube-apiserver").Get(context.TODO(), "user-token", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the copy to be deleted, got %v", err)
+	}
+	applied, err = kubeClient.CoreV1().ConfigMaps("openshift-kube-apiserver-operator").Get(context.TODO(), UserResourceSyncConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := applied.Data["secret.user-token"]; ok || len(applied.Data) != 1 {
+		t.Errorf("unexpected applied rules %v", applied.Data)
+	}
+	assertCondition(t, operatorClient, operatorv1.ConditionFalse, "AsExpected")
+}
+
+func assertCondition(t *testing.T, operatorClient v1helpers.OperatorClient, status operatorv1.ConditionStatus, reason string) {
+	t.Helper()
+	_, operatorStatus, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond := v1helpers.FindOperatorCondition(operatorStatus.Conditions, UserResourceSyncDegradedConditionType)
+	if cond == nil || cond.Status != status || cond.Reason != reason {
+		t.Errorf("expected %s with reason %s, got %+v", status, reason, cond)
+	}
+}
@@ -0,0 +1,49 @@
+package resyncinterval
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	lock sync.Mutex
+	// intervals replace the default resync intervals, keyed by controller name
+	intervals = map[string]time.Duration{}
+	// used are the controllers that asked for their interval
+	used = sets.NewString()
+)
+
+// Set replaces the resync intervals of the controllers that are created afterwards, keyed by the controller name in
+// the controller label of the sync metrics. Intervals that do not parse are ignored, they are validated with the
+// operator config.
+func Set(configured map[string]string) {
+	lock.Lock()
+	defer lock.Unlock()
+	intervals = map[string]time.Duration{}
+	for controllerName, value := range configured {
+		if interval, err := time.ParseDuration(value); err == nil {
+			intervals[controllerName] = interval
+		}
+	}
+}
+
+// For returns the resync interval of a controller, the configured one or the default.
+func For(controllerName string, defaultInterval time.Duration) time.Duration {
+	lock.Lock()
+	defer lock.Unlock()
+	used.Insert(controllerName)
+	if interval, ok := intervals[controllerName]; ok {
+		return interval
+	}
+	return defaultInterval
+}
+
+// Unused returns the controllers with a configured interval that no controller asked for, e.g. names with a typo or
+// controllers of library-go whose interval cannot be replaced.
+func Unused() []string {
+	lock.Lock()
+	defer lock.Unlock()
+	return sets.StringKeySet(intervals).Difference(used).List()
+}
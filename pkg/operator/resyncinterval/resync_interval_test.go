@@ -0,0 +1,22 @@
+package resyncinterval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFor(t *testing.T) {
+	Set(map[string]string{"TargetConfigController": "5m", "TargetConfigControler": "5m", "NodeOrderController": "invalid"})
+	defer Set(nil)
+
+	if interval := For("TargetConfigController", time.Minute); interval != 5*time.Minute {
+		t.Errorf("expected the configured interval, got %v", interval)
+	}
+	if interval := For("NodeOrderController", time.Minute); interval != time.Minute {
+		t.Errorf("expected the default interval, got %v", interval)
+	}
+	if unused := Unused(); !reflect.DeepEqual(unused, []string{"TargetConfigControler"}) {
+		t.Errorf("expected the misspelled controller to be unused, got %v", unused)
+	}
+}
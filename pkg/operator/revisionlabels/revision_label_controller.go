@@ -0,0 +1,144 @@
+package revisionlabels
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	installercmd "github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/installer"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const revisionStatusPrefix = "revision-status-"
+
+// RevisionLabelController labels the configmaps and secrets of every revision with the revision, so that the
+// installers can list them with two requests instead of getting them one by one. The revision controller of
+// library-go copies them without labels of their own. The revision-status configmap of a revision is labeled last, it
+// tells the installers that the labels of the revision are complete. Revisions are only labeled once they are
+// available, the revision controller creates the revision-status configmap before it copies the others.
+type RevisionLabelController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	secretClient    coreclientv1.SecretsGetter
+	configMapLister corev1listers.ConfigMapLister
+	secretLister    corev1listers.SecretLister
+
+	configMaps []revisioncontroller.RevisionResource
+	secrets    []revisioncontroller.RevisionResource
+}
+
+func NewRevisionLabelController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	configMaps []revisioncontroller.RevisionResource,
+	secrets []revisioncontroller.RevisionResource,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	kubeClient coreclientv1.CoreV1Interface,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	informers := kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace)
+	c := &RevisionLabelController{
+		operatorClient:  operatorClient,
+		configMapClient: kubeClient,
+		secretClient:    kubeClient,
+		configMapLister: informers.Core().V1().ConfigMaps().Lister(),
+		secretLister:    informers.Core().V1().Secrets().Lister(),
+		configMaps:      configMaps,
+		secrets:         secrets,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		informers.Core().V1().ConfigMaps().Informer(),
+		informers.Core().V1().Secrets().Informer(),
+	).WithSync(syncmetrics.Instrument("RevisionLabelController", c.sync)).ToController("RevisionLabelController", eventRecorder.WithComponentSuffix("revision-label-controller"))
+}
+
+func (c *RevisionLabelController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	configMaps, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, configMap := range configMaps {
+		if !strings.HasPrefix(configMap.Name, revisionStatusPrefix) {
+			continue
+		}
+		revision := strings.TrimPrefix(configMap.Name, revisionStatusPrefix)
+		number, err := strconv.Atoi(revision)
+		if err != nil || number > int(status.LatestAvailableRevision) || configMap.Labels[installercmd.RevisionLabel] == revision {
+			continue
+		}
+		if err := c.labelRevision(ctx, revision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelRevision labels the configmaps and secrets of the revision, then its revision-status configmap.
+func (c *RevisionLabelController) labelRevision(ctx context.Context, revision string) error {
+	for _, resource := range c.secrets {
+		secret, err := c.secretLister.Secrets(operatorclient.TargetNamespace).Get(fmt.Sprintf("%s-%s", resource.Name, revision))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if secret.Labels[installercmd.RevisionLabel] == revision {
+			continue
+		}
+		secret = secret.DeepCopy()
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[installercmd.RevisionLabel] = revision
+		if _, err := c.secretClient.Secrets(operatorclient.TargetNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	// the revision-status configmap is labeled last
+	for _, name := range append(configMapNames(c.configMaps), strings.TrimSuffix(revisionStatusPrefix, "-")) {
+		configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(fmt.Sprintf("%s-%s", name, revision))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if configMap.Labels[installercmd.RevisionLabel] == revision {
+			continue
+		}
+		configMap = configMap.DeepCopy()
+		if configMap.Labels == nil {
+			configMap.Labels = map[string]string{}
+		}
+		configMap.Labels[installercmd.RevisionLabel] = revision
+		if _, err := c.configMapClient.ConfigMaps(operatorclient.TargetNamespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configMapNames(resources []revisioncontroller.RevisionResource) []string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return names
+}
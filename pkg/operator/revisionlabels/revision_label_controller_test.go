@@ -0,0 +1,80 @@
+package revisionlabels
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/revisioncontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestLabelRevisions(t *testing.T) {
+	configMap := func(name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name, Labels: labels}}
+	}
+	objects := []runtime.Object{
+		// revision 3 is labeled, revision 4 is not, revision 5 is still being created
+		configMap("revision-status-3", map[string]string{"revision": "3"}),
+		configMap("config-3", map[string]string{"revision": "3"}),
+		configMap("revision-status-4", nil),
+		configMap("config-4", map[string]string{"app": "kube-apiserver"}),
+		configMap("revision-status-5", nil),
+		configMap("config", nil),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "etcd-client-4"}},
+	}
+	client := fake.NewSimpleClientset(objects...)
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, object := range objects {
+		indexer := configMapIndexer
+		if _, ok := object.(*corev1.Secret); ok {
+			indexer = secretIndexer
+		}
+		if err := indexer.Add(object); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &RevisionLabelController{
+		operatorClient:  v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 4}, nil, nil),
+		configMapClient: client.CoreV1(),
+		secretClient:    client.CoreV1(),
+		configMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+		secretLister:    corev1listers.NewSecretLister(secretIndexer),
+		configMaps:      []revisioncontroller.RevisionResource{{Name: "config"}, {Name: "oauth-metadata", Optional: true}},
+		secrets:         []revisioncontroller.RevisionResource{{Name: "etcd-client"}},
+	}
+
+	if err := c.sync(context.TODO(), nil); err != nil {
+		t.Fatal(err)
+	}
+	var updated []string
+	for _, action := range client.Actions() {
+		if update, ok := action.(clienttesting.UpdateAction); ok {
+			object := update.GetObject().(metav1.Object)
+			if object.GetLabels()["revision"] != "4" {
+				t.Errorf("expected %s to be labeled with revision 4, got %v", object.GetName(), object.GetLabels())
+			}
+			updated = append(updated, object.GetName())
+		}
+	}
+	// the revision-status configmap is labeled last
+	if expected := "etcd-client-4 config-4 revision-status-4"; strings.Join(updated, " ") != expected {
+		t.Errorf("expected the updates %v, got %v", expected, updated)
+	}
+	configMap4, err := client.CoreV1().ConfigMaps("openshift-kube-apiserver").Get(context.TODO(), "config-4", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configMap4.Labels["app"] != "kube-apiserver" {
+		t.Errorf("expected the other labels to be kept, got %v", configMap4.Labels)
+	}
+}
@@ -0,0 +1,71 @@
+package rolloutdelay
+
+import (
+	"strconv"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// DelayBetweenNodes returns an installer pod mutation that applies the delay between nodes of the rollout config. When
+// the kube-apiserver of another node became ready with the revision less than the delay ago, a rollout-delay init
+// container sleeps for the rest of it. The installer controller keeps waiting for the pending installer pod meanwhile.
+func DelayBetweenNodes(kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return delayBetweenNodes(kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(), time.Now)
+}
+
+func delayBetweenNodes(podLister corev1listers.PodLister, now func() time.Time) func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if len(rollout.DelayBetweenNodes) == 0 {
+			return nil
+		}
+		delay, err := time.ParseDuration(rollout.DelayBetweenNodes)
+		if err != nil {
+			return err
+		}
+
+		ready, err := lastReady(podLister, nodeName, revision)
+		if err != nil || ready.IsZero() {
+			return err
+		}
+		if remaining := ready.Add(delay).Sub(now()); remaining > 0 {
+			installergate.AddSleepContainer(pod, "rollout-delay", remaining)
+		}
+		return nil
+	}
+}
+
+// lastReady returns when the kube-apiserver of another node last became ready with the revision, zero if none did.
+func lastReady(podLister corev1listers.PodLister, nodeName string, revision int32) (time.Time, error) {
+	pods, err := podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{
+		"apiserver": "true",
+		"revision":  strconv.Itoa(int(revision)),
+	}))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var ready time.Time
+	for _, pod := range pods {
+		if pod.Spec.NodeName == nodeName {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue && condition.LastTransitionTime.After(ready) {
+				ready = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	return ready, nil
+}
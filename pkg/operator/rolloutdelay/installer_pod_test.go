@@ -0,0 +1,99 @@
+package rolloutdelay
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDelayBetweenNodes(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	kubeAPIServer := func(nodeName, revision string, ready time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-kube-apiserver",
+				Name:      "kube-apiserver-" + nodeName,
+				Labels:    map[string]string{"apiserver": "true", "revision": revision},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(ready)},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		observedConfig string
+		pods           []*corev1.Pod
+		expectedSleep  string
+	}{
+		{
+			name: "no delay",
+			pods: []*corev1.Pod{kubeAPIServer("master-0", "5", now.Add(-time.Minute))},
+		},
+		{
+			name:           "first node",
+			observedConfig: `{"rollout":{"delayBetweenNodes":"10m"}}`,
+			pods:           []*corev1.Pod{kubeAPIServer("master-0", "4", now.Add(-time.Minute)), kubeAPIServer("master-1", "4", now.Add(-time.Hour))},
+		},
+		{
+			name:           "previous node ready recently",
+			observedConfig: `{"rollout":{"delayBetweenNodes":"10m"}}`,
+			pods:           []*corev1.Pod{kubeAPIServer("master-0", "5", now.Add(-time.Hour)), kubeAPIServer("master-2", "5", now.Add(-time.Minute)), kubeAPIServer("master-1", "4", now.Add(-time.Hour))},
+			expectedSleep:  "540",
+		},
+		{
+			name:           "delay elapsed",
+			observedConfig: `{"rollout":{"delayBetweenNodes":"10m"}}`,
+			pods:           []*corev1.Pod{kubeAPIServer("master-0", "5", now.Add(-time.Hour))},
+		},
+		{
+			name:           "own node ready recently",
+			observedConfig: `{"rollout":{"delayBetweenNodes":"10m"}}`,
+			pods:           []*corev1.Pod{kubeAPIServer("master-1", "5", now.Add(-time.Minute))},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, pod := range test.pods {
+				if err := indexer.Add(pod); err != nil {
+					t.Fatal(err)
+				}
+			}
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "installer",
+				Image: "quay.io/openshift/cluster-kube-apiserver-operator",
+			}}}}
+
+			if err := delayBetweenNodes(corev1listers.NewPodLister(indexer), func() time.Time { return now })(pod, "master-1", operatorSpec, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(test.expectedSleep) == 0 {
+				if len(pod.Spec.InitContainers) > 0 {
+					t.Fatalf("expected no delay, got %v", pod.Spec.InitContainers)
+				}
+				return
+			}
+			if len(pod.Spec.InitContainers) != 1 {
+				t.Fatalf("expected one init container, got %v", pod.Spec.InitContainers)
+			}
+			container := pod.Spec.InitContainers[0]
+			if container.Name != "rollout-delay" || container.Args[0] != test.expectedSleep {
+				t.Errorf("expected to sleep %ss, got %v", test.expectedSleep, container)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package rolloutpause
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// WaitWhilePaused returns an installer pod mutation that holds back the installer pods created while the rollout is
+// paused. An init container waits until the rollout-pause configmap tells the rollout is resumed, the installer
+// controller keeps waiting for the pending installer pod meanwhile. Installer pods that already run are not affected.
+func WaitWhilePaused() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		rollout, err := canaryrollout.RolloutFromSpec(operatorSpec)
+		if err != nil {
+			return err
+		}
+		if !rollout.Paused {
+			return nil
+		}
+		addWaitForResumeContainer(pod)
+		return nil
+	}
+}
+
+func addWaitForResumeContainer(pod *corev1.Pod) {
+	installergate.AddWaitContainer(pod, "wait-for-resume",
+		fmt.Sprintf("--namespace=%s", operatorclient.TargetNamespace),
+		fmt.Sprintf("--configmap=%s", ConfigMapName),
+	)
+}
@@ -0,0 +1,55 @@
+package rolloutpause
+
+import (
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitWhilePaused(t *testing.T) {
+	tests := []struct {
+		name           string
+		observedConfig string
+		expectWait     bool
+	}{
+		{name: "no rollout config"},
+		{name: "not paused", observedConfig: `{"rollout":{"strategy":"Canary"}}`},
+		{name: "paused", observedConfig: `{"rollout":{"paused":true}}`, expectWait: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:         "installer",
+				Image:        "quay.io/openshift/cluster-kube-apiserver-operator",
+				VolumeMounts: []corev1.VolumeMount{{Name: "kubelet-dir"}, {Name: "kube-api-access", MountPath: "/var/run/secrets/kubernetes.io/serviceaccount"}},
+			}}}}
+
+			if err := WaitWhilePaused()(pod, "master-0", operatorSpec, 5); err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.expectWait {
+				if len(pod.Spec.InitContainers) > 0 {
+					t.Fatalf("expected the installer not to wait, got %v", pod.Spec.InitContainers)
+				}
+				return
+			}
+			if len(pod.Spec.InitContainers) != 1 {
+				t.Fatalf("expected one init container, got %v", pod.Spec.InitContainers)
+			}
+			container := pod.Spec.InitContainers[0]
+			if container.Image != pod.Spec.Containers[0].Image || container.Command[1] != "wait-for-resume" {
+				t.Errorf("unexpected init container %v", container)
+			}
+			if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "kube-api-access" {
+				t.Errorf("expected only the service account token to be mounted, got %v", container.VolumeMounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package rolloutpause
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace that tells the waiting installer pods whether the
+	// rollout is paused.
+	ConfigMapName = "rollout-pause"
+	PausedKey     = "paused"
+
+	RolloutPausedConditionType            = "RolloutPaused"
+	RolloutPausedUpgradeableConditionType = "RolloutPausedUpgradeable"
+)
+
+// RolloutPauseController publishes the paused field of the rollout in the operator config to the rollout-pause
+// configmap, which the installer pods created while paused wait for. The RolloutPaused condition names the nodes that
+// wait for the latest revision, upgrades are not allowed while paused because they would not roll out.
+type RolloutPauseController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+}
+
+func NewRolloutPauseController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &RolloutPauseController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("RolloutPauseController", c.sync)).ToController("RolloutPauseController", eventRecorder.WithComponentSuffix("rollout-pause-controller"))
+}
+
+func (c *RolloutPauseController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	_, changed, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data:       map[string]string{PausedKey: strconv.FormatBool(rollout.Paused)},
+	})
+	if err != nil {
+		return err
+	}
+	if changed && rollout.Paused {
+		syncCtx.Recorder().Warningf("RolloutPaused", "rollouts of new revisions are paused")
+	} else if changed {
+		syncCtx.Recorder().Eventf("RolloutResumed", "rollouts of new revisions are resumed")
+	}
+
+	paused, upgradeable := newPausedConditions(rollout.Paused, status)
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(paused), v1helpers.UpdateConditionFn(upgradeable)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newPausedConditions(paused bool, status *operatorv1.StaticPodOperatorStatus) (operatorv1.OperatorCondition, operatorv1.OperatorCondition) {
+	if !paused {
+		return operatorv1.OperatorCondition{Type: RolloutPausedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+			operatorv1.OperatorCondition{Type: RolloutPausedUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: "AsExpected"}
+	}
+
+	message := "rollouts of new revisions are paused"
+	var waiting []string
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.CurrentRevision < status.LatestAvailableRevision {
+			waiting = append(waiting, fmt.Sprintf("%s (revision %d)", nodeStatus.NodeName, nodeStatus.CurrentRevision))
+		}
+	}
+	if len(waiting) > 0 {
+		sort.Strings(waiting)
+		message = fmt.Sprintf("%s, revision %d waits to be installed on %s", message, status.LatestAvailableRevision, strings.Join(waiting, ", "))
+	}
+	return operatorv1.OperatorCondition{Type: RolloutPausedConditionType, Status: operatorv1.ConditionTrue, Reason: "Paused", Message: message},
+		operatorv1.OperatorCondition{
+			Type:    RolloutPausedUpgradeableConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "RolloutPaused",
+			Message: "rollouts of new revisions are paused, an upgrade would not be rolled out",
+		}
+}
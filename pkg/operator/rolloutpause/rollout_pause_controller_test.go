@@ -0,0 +1,80 @@
+package rolloutpause
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestNewPausedConditions(t *testing.T) {
+	status := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-2", CurrentRevision: 4},
+			{NodeName: "master-0", CurrentRevision: 5},
+			{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		paused              bool
+		status              *operatorv1.StaticPodOperatorStatus
+		expectedPaused      operatorv1.OperatorCondition
+		expectedUpgradeable operatorv1.OperatorCondition
+	}{
+		{
+			name:                "not paused",
+			status:              status,
+			expectedPaused:      operatorv1.OperatorCondition{Type: RolloutPausedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+			expectedUpgradeable: operatorv1.OperatorCondition{Type: RolloutPausedUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: "AsExpected"},
+		},
+		{
+			name:   "paused during a rollout",
+			paused: true,
+			status: status,
+			expectedPaused: operatorv1.OperatorCondition{
+				Type:    RolloutPausedConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "Paused",
+				Message: "rollouts of new revisions are paused, revision 5 waits to be installed on master-1 (revision 4), master-2 (revision 4)",
+			},
+			expectedUpgradeable: operatorv1.OperatorCondition{
+				Type:    RolloutPausedUpgradeableConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "RolloutPaused",
+				Message: "rollouts of new revisions are paused, an upgrade would not be rolled out",
+			},
+		},
+		{
+			name:   "paused while rolled out",
+			paused: true,
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}}},
+			expectedPaused: operatorv1.OperatorCondition{
+				Type:    RolloutPausedConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "Paused",
+				Message: "rollouts of new revisions are paused",
+			},
+			expectedUpgradeable: operatorv1.OperatorCondition{
+				Type:    RolloutPausedUpgradeableConditionType,
+				Status:  operatorv1.ConditionFalse,
+				Reason:  "RolloutPaused",
+				Message: "rollouts of new revisions are paused, an upgrade would not be rolled out",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paused, upgradeable := newPausedConditions(test.paused, test.status)
+			if !cmp.Equal(test.expectedPaused, paused) {
+				t.Errorf("unexpected RolloutPaused condition, diff = %v", cmp.Diff(test.expectedPaused, paused))
+			}
+			if !cmp.Equal(test.expectedUpgradeable, upgradeable) {
+				t.Errorf("unexpected RolloutPausedUpgradeable condition, diff = %v", cmp.Diff(test.expectedUpgradeable, upgradeable))
+			}
+		})
+	}
+}
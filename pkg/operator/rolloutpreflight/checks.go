@@ -0,0 +1,275 @@
+package rolloutpreflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	checkEtcdQuorum        = "EtcdQuorum"
+	checkEtcdMemberHealth  = "EtcdMemberHealth"
+	checkDiskPressure      = "DiskPressure"
+	checkCertificateExpiry = "CertificateExpiry"
+	checkManifest          = "Manifest"
+
+	// certificates that expire within this time fail the CertificateExpiry check
+	certificateExpiryThreshold = 10 * time.Minute
+
+	// the condition of the etcd operator that is false when etcd lost its quorum
+	etcdMembersAvailableConditionType = "EtcdMembersAvailable"
+	// the condition of the etcd operator that is true when a member is unhealthy
+	etcdMembersDegradedConditionType = "EtcdMembersDegraded"
+)
+
+// checkReasons are the condition reasons of a single failed check.
+var checkReasons = map[string]string{
+	checkEtcdQuorum:        "EtcdQuorumLost",
+	checkEtcdMemberHealth:  "EtcdMembersUnhealthy",
+	checkDiskPressure:      "NodeDiskPressure",
+	checkCertificateExpiry: "CertificateExpiring",
+	checkManifest:          "InvalidManifest",
+}
+
+// preflightFailure is a failed check with a message naming the failing object.
+type preflightFailure struct {
+	check   string
+	message string
+}
+
+// preflightChecker runs the checks that have to pass before a revision is installed on a node.
+type preflightChecker struct {
+	nodeLister      corev1listers.NodeLister
+	secretLister    corev1listers.SecretLister
+	configMapLister corev1listers.ConfigMapLister
+	// etcdConditions returns the conditions of the etcd operator, nil if there is no etcd operator
+	etcdConditions func(ctx context.Context) ([]operatorv1.OperatorCondition, error)
+	certSecrets    []installer.UnrevisionedResource
+
+	now func() time.Time
+}
+
+// run returns the failures of all checks that are not skipped.
+func (c *preflightChecker) run(ctx context.Context, status *operatorv1.StaticPodOperatorStatus, skip sets.String) ([]preflightFailure, error) {
+	checks := []struct {
+		name  string
+		check func() ([]string, error)
+	}{
+		{name: checkEtcdQuorum, check: func() ([]string, error) { return c.checkEtcdQuorum(ctx) }},
+		{name: checkEtcdMemberHealth, check: func() ([]string, error) { return c.checkEtcdMemberHealth(ctx) }},
+		{name: checkDiskPressure, check: func() ([]string, error) { return c.checkDiskPressure(status) }},
+		{name: checkCertificateExpiry, check: c.checkCertificateExpiry},
+		{name: checkManifest, check: func() ([]string, error) { return c.checkManifest(status.LatestAvailableRevision) }},
+	}
+	var failures []preflightFailure
+	for _, check := range checks {
+		if skip.Has(check.name) {
+			continue
+		}
+		messages, err := check.check()
+		if err != nil {
+			return nil, fmt.Errorf("%s check: %v", check.name, err)
+		}
+		for _, message := range messages {
+			failures = append(failures, preflightFailure{check: check.name, message: message})
+		}
+	}
+	return failures, nil
+}
+
+// checkEtcdQuorum fails when the etcd operator reports that etcd lost its quorum. A missing etcd operator or condition
+// passes, a new revision must not wait for an etcd operator that does not exist.
+func (c *preflightChecker) checkEtcdQuorum(ctx context.Context) ([]string, error) {
+	conditions, err := c.etcdConditions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, condition := range conditions {
+		if condition.Type == etcdMembersAvailableConditionType && condition.Status == operatorv1.ConditionFalse {
+			return []string{fmt.Sprintf("etcd has no quorum: %s", condition.Message)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkEtcdMemberHealth fails when the etcd operator reports an unhealthy member. Etcd still has quorum, but one
+// more member lost while the kube-apiserver restarts can take down the API completely.
+func (c *preflightChecker) checkEtcdMemberHealth(ctx context.Context) ([]string, error) {
+	conditions, err := c.etcdConditions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, condition := range conditions {
+		if condition.Type == etcdMembersDegradedConditionType && condition.Status == operatorv1.ConditionTrue {
+			return []string{fmt.Sprintf("etcd has unhealthy members: %s", condition.Message)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkDiskPressure fails for every control plane node the kubelet reports disk pressure for. A new revision is copied
+// to the disk of the node and evictions would take down the kube-apiserver.
+func (c *preflightChecker) checkDiskPressure(status *operatorv1.StaticPodOperatorStatus) ([]string, error) {
+	var failures []string
+	for _, nodeStatus := range status.NodeStatuses {
+		node, err := c.nodeLister.Get(nodeStatus.NodeName)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeDiskPressure && condition.Status == corev1.ConditionTrue {
+				failures = append(failures, fmt.Sprintf("node %q has disk pressure: %s", node.Name, condition.Message))
+			}
+		}
+	}
+	return failures, nil
+}
+
+// checkCertificateExpiry fails for every certificate of the kube-apiserver that expires within minutes. The
+// certificates are synced into the running kube-apiserver, restarting it with a certificate that is about to expire
+// risks an outage until the next rotation.
+func (c *preflightChecker) checkCertificateExpiry() ([]string, error) {
+	var failures []string
+	for _, certSecret := range c.certSecrets {
+		secret, err := c.secretLister.Secrets(operatorclient.TargetNamespace).Get(certSecret.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		certPEM, ok := secret.Data[corev1.TLSCertKey]
+		if !ok {
+			continue
+		}
+		certs, err := certutil.ParseCertsPEM(certPEM)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("secret %s/%s has an invalid certificate: %v", secret.Namespace, secret.Name, err))
+			continue
+		}
+		for _, cert := range certs {
+			if cert.NotAfter.Sub(c.now()) < certificateExpiryThreshold {
+				failures = append(failures, fmt.Sprintf("certificate %q of secret %s/%s expires at %s", cert.Subject.CommonName, secret.Namespace, secret.Name, cert.NotAfter.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+	return failures, nil
+}
+
+// checkManifest fails when the static pod manifest or the config of the revision cannot be decoded or the pod is
+// invalid. The installer would write it to the nodes and the kubelet would not start it.
+func (c *preflightChecker) checkManifest(revision int32) ([]string, error) {
+	if revision == 0 {
+		return nil, nil
+	}
+	podYAML, failure, err := c.revisionData(fmt.Sprintf("kube-apiserver-pod-%d", revision), "pod.yaml")
+	if err != nil || len(failure) > 0 {
+		return failureList(failure), err
+	}
+	pod := &corev1.Pod{}
+	if err := decodeStrict(podYAML, pod); err != nil {
+		return []string{fmt.Sprintf("the manifest of revision %d cannot be decoded: %v", revision, err)}, nil
+	}
+	var failures []string
+	for _, msg := range validatePod(pod) {
+		failures = append(failures, fmt.Sprintf("the manifest of revision %d is invalid: %s", revision, msg))
+	}
+
+	configYAML, failure, err := c.revisionData(fmt.Sprintf("config-%d", revision), "config.yaml")
+	if err != nil || len(failure) > 0 {
+		return append(failures, failureList(failure)...), err
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(configYAML), &config); err != nil {
+		failures = append(failures, fmt.Sprintf("the config of revision %d cannot be decoded: %v", revision, err))
+	}
+	return failures, nil
+}
+
+func (c *preflightChecker) revisionData(configMapName, key string) (string, string, error) {
+	configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(configMapName)
+	if apierrors.IsNotFound(err) {
+		return "", fmt.Sprintf("configmap %s/%s is missing", operatorclient.TargetNamespace, configMapName), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	data, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Sprintf("configmap %s/%s has no %s", operatorclient.TargetNamespace, configMapName, key), nil
+	}
+	return data, "", nil
+}
+
+func failureList(failure string) []string {
+	if len(failure) == 0 {
+		return nil
+	}
+	return []string{failure}
+}
+
+// decodeStrict decodes the YAML and rejects unknown fields, which the kubelet would drop silently.
+func decodeStrict(data string, into interface{}) error {
+	raw, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(into)
+}
+
+// validatePod checks the parts of the kube-apiserver pod the kubelet needs to run it.
+func validatePod(pod *corev1.Pod) []string {
+	var errs []string
+	if pod.Namespace != operatorclient.TargetNamespace {
+		errs = append(errs, fmt.Sprintf("namespace %q is not %q", pod.Namespace, operatorclient.TargetNamespace))
+	}
+	volumes := sets.NewString()
+	for _, volume := range pod.Spec.Volumes {
+		volumes.Insert(volume.Name)
+	}
+	names := sets.NewString()
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		switch {
+		case len(container.Name) == 0:
+			errs = append(errs, "a container has no name")
+		case names.Has(container.Name):
+			errs = append(errs, fmt.Sprintf("container %q is duplicated", container.Name))
+		}
+		names.Insert(container.Name)
+		if len(container.Image) == 0 {
+			errs = append(errs, fmt.Sprintf("container %q has no image", container.Name))
+		}
+		for _, mount := range container.VolumeMounts {
+			if !volumes.Has(mount.Name) {
+				errs = append(errs, fmt.Sprintf("container %q mounts the missing volume %q", container.Name, mount.Name))
+			}
+		}
+		for _, port := range container.Ports {
+			if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+				errs = append(errs, fmt.Sprintf("container %q has the invalid port %d", container.Name, port.ContainerPort))
+			}
+		}
+	}
+	if !names.Has("kube-apiserver") {
+		errs = append(errs, "the kube-apiserver container is missing")
+	}
+	return errs
+}
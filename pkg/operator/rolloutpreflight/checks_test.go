@@ -0,0 +1,228 @@
+package rolloutpreflight
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const validPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-apiserver
+  namespace: openshift-kube-apiserver
+spec:
+  containers:
+  - name: kube-apiserver
+    image: quay.io/openshift/hyperkube
+    ports:
+    - containerPort: 6443
+    volumeMounts:
+    - name: resource-dir
+      mountPath: /etc/kubernetes/static-pod-resources
+  volumes:
+  - name: resource-dir
+    hostPath:
+      path: /etc/kubernetes/static-pod-resources/kube-apiserver-pod-5
+`
+
+func TestPreflightChecks(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	status := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 4},
+			{NodeName: "master-1", CurrentRevision: 4},
+		},
+	}
+	healthyNode := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse}}},
+		}
+	}
+	revisionConfigMaps := func(podYAML string) []*corev1.ConfigMap {
+		return []*corev1.ConfigMap{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-pod-5"}, Data: map[string]string{"pod.yaml": podYAML}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "config-5"}, Data: map[string]string{"config.yaml": `{"apiVersion":"kubecontrolplane.config.openshift.io/v1","kind":"KubeAPIServerConfig"}`}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		etcdConditions   []operatorv1.OperatorCondition
+		nodes            []*corev1.Node
+		secrets          []*corev1.Secret
+		configMaps       []*corev1.ConfigMap
+		skip             []string
+		expectedFailures []preflightFailure
+	}{
+		{
+			name:           "healthy",
+			etcdConditions: []operatorv1.OperatorCondition{{Type: "EtcdMembersAvailable", Status: operatorv1.ConditionTrue}},
+			nodes:          []*corev1.Node{healthyNode("master-0"), healthyNode("master-1")},
+			secrets:        []*corev1.Secret{newCertSecret(t, "localhost-serving-cert-certkey", now.Add(24*time.Hour))},
+			configMaps:     revisionConfigMaps(validPod),
+		},
+		{
+			name:           "etcd without quorum",
+			etcdConditions: []operatorv1.OperatorCondition{{Type: "EtcdMembersAvailable", Status: operatorv1.ConditionFalse, Message: "1 of 3 members are available"}},
+			configMaps:     revisionConfigMaps(validPod),
+			expectedFailures: []preflightFailure{
+				{check: checkEtcdQuorum, message: "etcd has no quorum: 1 of 3 members are available"},
+			},
+		},
+		{
+			name: "unhealthy etcd member",
+			etcdConditions: []operatorv1.OperatorCondition{
+				{Type: "EtcdMembersAvailable", Status: operatorv1.ConditionTrue, Message: "2 of 3 members are available"},
+				{Type: "EtcdMembersDegraded", Status: operatorv1.ConditionTrue, Message: "2 of 3 members are available, master-2 is unhealthy"},
+			},
+			configMaps: revisionConfigMaps(validPod),
+			expectedFailures: []preflightFailure{
+				{check: checkEtcdMemberHealth, message: "etcd has unhealthy members: 2 of 3 members are available, master-2 is unhealthy"},
+			},
+		},
+		{
+			name: "skipped etcd member health",
+			etcdConditions: []operatorv1.OperatorCondition{
+				{Type: "EtcdMembersDegraded", Status: operatorv1.ConditionTrue, Message: "2 of 3 members are available, master-2 is unhealthy"},
+			},
+			configMaps: revisionConfigMaps(validPod),
+			skip:       []string{"EtcdMemberHealth"},
+		},
+		{
+			name: "disk pressure",
+			nodes: []*corev1.Node{healthyNode("master-0"), {
+				ObjectMeta: metav1.ObjectMeta{Name: "master-1"},
+				Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Message: "kubelet has disk pressure"}}},
+			}},
+			configMaps: revisionConfigMaps(validPod),
+			expectedFailures: []preflightFailure{
+				{check: checkDiskPressure, message: `node "master-1" has disk pressure: kubelet has disk pressure`},
+			},
+		},
+		{
+			name:       "expiring certificate",
+			secrets:    []*corev1.Secret{newCertSecret(t, "localhost-serving-cert-certkey", now.Add(5*time.Minute))},
+			configMaps: revisionConfigMaps(validPod),
+			expectedFailures: []preflightFailure{
+				{check: checkCertificateExpiry, message: `certificate "localhost-serving-cert-certkey" of secret openshift-kube-apiserver/localhost-serving-cert-certkey expires at 2026-10-16T12:05:00Z`},
+			},
+		},
+		{
+			name:       "skipped check",
+			secrets:    []*corev1.Secret{newCertSecret(t, "localhost-serving-cert-certkey", now.Add(5*time.Minute))},
+			configMaps: revisionConfigMaps(validPod),
+			skip:       []string{checkCertificateExpiry},
+		},
+		{
+			name: "missing revision",
+			expectedFailures: []preflightFailure{
+				{check: checkManifest, message: "configmap openshift-kube-apiserver/kube-apiserver-pod-5 is missing"},
+			},
+		},
+		{
+			name:       "unknown field in the manifest",
+			configMaps: revisionConfigMaps(validPod + "  hostNetwrok: true\n"),
+			expectedFailures: []preflightFailure{
+				{check: checkManifest, message: `the manifest of revision 5 cannot be decoded: json: unknown field "hostNetwrok"`},
+			},
+		},
+		{
+			name: "invalid manifest",
+			configMaps: revisionConfigMaps(`apiVersion: v1
+kind: Pod
+metadata:
+  namespace: openshift-kube-apiserver
+spec:
+  containers:
+  - name: kube-apiserver
+    volumeMounts:
+    - name: resource-dir
+`),
+			expectedFailures: []preflightFailure{
+				{check: checkManifest, message: `the manifest of revision 5 is invalid: container "kube-apiserver" has no image`},
+				{check: checkManifest, message: `the manifest of revision 5 is invalid: container "kube-apiserver" mounts the missing volume "resource-dir"`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, node := range test.nodes {
+				if err := nodeIndexer.Add(node); err != nil {
+					t.Fatal(err)
+				}
+			}
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, secret := range test.secrets {
+				if err := secretIndexer.Add(secret); err != nil {
+					t.Fatal(err)
+				}
+			}
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, configMap := range test.configMaps {
+				if err := configMapIndexer.Add(configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+			checker := &preflightChecker{
+				nodeLister:      corev1listers.NewNodeLister(nodeIndexer),
+				secretLister:    corev1listers.NewSecretLister(secretIndexer),
+				configMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				etcdConditions: func(ctx context.Context) ([]operatorv1.OperatorCondition, error) {
+					return test.etcdConditions, nil
+				},
+				certSecrets: []installer.UnrevisionedResource{{Name: "localhost-serving-cert-certkey"}, {Name: "user-serving-cert", Optional: true}},
+				now:         func() time.Time { return now },
+			}
+
+			failures, err := checker.run(context.TODO(), status, sets.NewString(test.skip...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expectedFailures, failures, cmp.AllowUnexported(preflightFailure{})); diff != "" {
+				t.Errorf("unexpected failures (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func newCertSecret(t *testing.T, name string, notAfter time.Time) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-30 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name},
+		Data:       map[string][]byte{corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})},
+	}
+}
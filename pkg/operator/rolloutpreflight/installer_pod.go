@@ -0,0 +1,29 @@
+package rolloutpreflight
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installergate"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// WaitForPreflightChecks returns an installer pod mutation that holds back every installer pod until the preflight
+// checks passed for its revision or a later one. An init container waits until the rollout-preflight configmap tells
+// so, the installer controller keeps waiting for the pending installer pod meanwhile.
+func WaitForPreflightChecks() func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		addWaitForPreflightContainer(pod, revision)
+		return nil
+	}
+}
+
+func addWaitForPreflightContainer(pod *corev1.Pod, revision int32) {
+	installergate.AddWaitContainer(pod, "wait-for-preflight",
+		fmt.Sprintf("--namespace=%s", operatorclient.TargetNamespace),
+		fmt.Sprintf("--configmap=%s", ConfigMapName),
+		fmt.Sprintf("--revision=%d", revision),
+	)
+}
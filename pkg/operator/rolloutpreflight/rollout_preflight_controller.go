@@ -0,0 +1,159 @@
+package rolloutpreflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/canaryrollout"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the target namespace that tells the waiting installer pods whether the
+	// preflight checks of a revision passed.
+	ConfigMapName = "rollout-preflight"
+	RevisionKey   = "revision"
+	PassedKey     = "passed"
+
+	RolloutPreflightBlockedConditionType = "RolloutPreflightBlocked"
+)
+
+// RolloutPreflightController runs the preflight checks for the latest revision, etcd has quorum and healthy members, no
+// control plane node has disk pressure, no certificate of the kube-apiserver expires within minutes and the manifest of
+// the revision is valid. The result is published to the rollout-preflight configmap, which the installer pods wait for. The
+// RolloutPreflightBlocked condition lists the failed checks while nodes wait for the latest revision.
+type RolloutPreflightController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	configMapClient coreclientv1.ConfigMapsGetter
+	checker         *preflightChecker
+}
+
+func NewRolloutPreflightController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	dynamicClient dynamic.Interface,
+	certSecrets []installer.UnrevisionedResource,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	etcds := dynamicClient.Resource(operatorv1.GroupVersion.WithResource("etcds"))
+	c := &RolloutPreflightController{
+		operatorClient:  operatorClient,
+		configMapClient: configMapClient,
+		checker: &preflightChecker{
+			nodeLister:      kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Lister(),
+			secretLister:    kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Lister(),
+			configMapLister: kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Lister(),
+			etcdConditions: func(ctx context.Context) ([]operatorv1.OperatorCondition, error) {
+				obj, err := etcds.Get(ctx, "cluster", metav1.GetOptions{})
+				if apierrors.IsNotFound(err) {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				etcd := &operatorv1.Etcd{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), etcd); err != nil {
+					return nil, err
+				}
+				return etcd.Status.Conditions, nil
+			},
+			certSecrets: certSecrets,
+			now:         time.Now,
+		},
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor("").Core().V1().Nodes().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("RolloutPreflightController", c.sync)).ResyncEvery(resyncinterval.For("RolloutPreflightController", time.Minute)).ToController("RolloutPreflightController", eventRecorder.WithComponentSuffix("rollout-preflight-controller"))
+}
+
+func (c *RolloutPreflightController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	if status.LatestAvailableRevision == 0 {
+		return nil
+	}
+	rollout, err := canaryrollout.RolloutFromSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	failures, err := c.checker.run(ctx, status, sets.NewString(rollout.SkipPreflightChecks...))
+	if err != nil {
+		return err
+	}
+
+	_, changed, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: ConfigMapName},
+		Data: map[string]string{
+			RevisionKey: strconv.Itoa(int(status.LatestAvailableRevision)),
+			PassedKey:   strconv.FormatBool(len(failures) == 0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if changed && len(failures) > 0 {
+		syncCtx.Recorder().Warningf("RolloutPreflightFailed", "revision %d is not installed, %d preflight checks failed", status.LatestAvailableRevision, len(failures))
+	}
+
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(newPreflightCondition(failures, status))); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newPreflightCondition(failures []preflightFailure, status *operatorv1.StaticPodOperatorStatus) operatorv1.OperatorCondition {
+	waiting := false
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.CurrentRevision < status.LatestAvailableRevision {
+			waiting = true
+		}
+	}
+	if len(failures) == 0 || !waiting {
+		return operatorv1.OperatorCondition{Type: RolloutPreflightBlockedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"}
+	}
+
+	checks := sets.NewString()
+	var messages []string
+	for _, failure := range failures {
+		checks.Insert(failure.check)
+		messages = append(messages, fmt.Sprintf("%s: %s", failure.check, failure.message))
+	}
+	sort.Strings(messages)
+	reason := "PreflightChecksFailed"
+	if checks.Len() == 1 {
+		reason = checkReasons[checks.List()[0]]
+	}
+	return operatorv1.OperatorCondition{
+		Type:    RolloutPreflightBlockedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("revision %d is not installed until the preflight checks pass:\n%s", status.LatestAvailableRevision, strings.Join(messages, "\n")),
+	}
+}
@@ -0,0 +1,78 @@
+package rolloutpreflight
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestNewPreflightCondition(t *testing.T) {
+	rollingOut := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses:            []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}, {NodeName: "master-1", CurrentRevision: 4}},
+	}
+	rolledOut := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses:            []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 5}},
+	}
+
+	tests := []struct {
+		name     string
+		failures []preflightFailure
+		status   *operatorv1.StaticPodOperatorStatus
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name:     "passed",
+			status:   rollingOut,
+			expected: operatorv1.OperatorCondition{Type: RolloutPreflightBlockedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:     "failed while rolled out",
+			failures: []preflightFailure{{check: checkEtcdQuorum, message: "etcd has no quorum: 1 of 3 members are available"}},
+			status:   rolledOut,
+			expected: operatorv1.OperatorCondition{Type: RolloutPreflightBlockedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "one check failed",
+			failures: []preflightFailure{
+				{check: checkDiskPressure, message: `node "master-1" has disk pressure: kubelet has disk pressure`},
+				{check: checkDiskPressure, message: `node "master-0" has disk pressure: kubelet has disk pressure`},
+			},
+			status: rollingOut,
+			expected: operatorv1.OperatorCondition{
+				Type:   RolloutPreflightBlockedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: "NodeDiskPressure",
+				Message: "revision 5 is not installed until the preflight checks pass:\n" +
+					`DiskPressure: node "master-0" has disk pressure: kubelet has disk pressure` + "\n" +
+					`DiskPressure: node "master-1" has disk pressure: kubelet has disk pressure`,
+			},
+		},
+		{
+			name: "several checks failed",
+			failures: []preflightFailure{
+				{check: checkManifest, message: "configmap openshift-kube-apiserver/kube-apiserver-pod-5 is missing"},
+				{check: checkEtcdQuorum, message: "etcd has no quorum: 1 of 3 members are available"},
+			},
+			status: rollingOut,
+			expected: operatorv1.OperatorCondition{
+				Type:   RolloutPreflightBlockedConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: "PreflightChecksFailed",
+				Message: "revision 5 is not installed until the preflight checks pass:\n" +
+					"EtcdQuorum: etcd has no quorum: 1 of 3 members are available\n" +
+					"Manifest: configmap openshift-kube-apiserver/kube-apiserver-pod-5 is missing",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expected, newPreflightCondition(test.failures, test.status)); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package rolloutprogress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	RolloutProgressConditionType = "RolloutProgress"
+
+	// the phases of a node during the rollout of the latest revision
+	phaseWaiting    = "Waiting"
+	phaseInstalling = "Installing"
+	phaseVerifying  = "Verifying"
+	phaseDone       = "Done"
+)
+
+var phases = []string{phaseWaiting, phaseInstalling, phaseVerifying, phaseDone}
+
+var (
+	registerMetrics sync.Once
+
+	rolloutNodesGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_rollout_nodes",
+		Help: "Report the number of control plane nodes in each phase of the rollout of the latest revision.",
+	}, []string{"phase"})
+
+	rolloutNodeAttemptsGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_rollout_node_attempts",
+		Help: "Report the installation attempt of the latest revision on each control plane node.",
+	}, []string{"node"})
+
+	rolloutEstimatedCompletionGauge = metrics.NewGauge(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_rollout_estimated_completion_timestamp_seconds",
+		Help: "Report when the rollout of the latest revision is expected to complete, zero without an estimate.",
+	})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(rolloutNodesGauge)
+		legacyregistry.MustRegister(rolloutNodeAttemptsGauge)
+		legacyregistry.MustRegister(rolloutEstimatedCompletionGauge)
+	})
+}
+
+// nodeProgress is the progress of the latest revision on one node.
+type nodeProgress struct {
+	nodeName string
+	phase    string
+	attempt  int
+	// since is when the current attempt started, zero while the node waits for its turn
+	since time.Time
+	// started is when the first installer of the revision was created on the node, zero before
+	started time.Time
+}
+
+// rolloutProgress is the progress of the latest revision on all nodes.
+type rolloutProgress struct {
+	revision int32
+	nodes    []nodeProgress
+	// nodeDuration is the average time the nodes that are done took, zero before the first node is done
+	nodeDuration time.Duration
+	// estimatedCompletion is zero without a node duration
+	estimatedCompletion time.Time
+}
+
+// RolloutProgressController reports the phase, the attempt and the start of the installation of the latest revision
+// on every node and an estimated completion of the rollout in the RolloutProgress condition and as metrics. The node
+// statuses of the operator API are defined in openshift/api and cannot carry them.
+type RolloutProgressController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	podLister      corev1listers.PodLister
+
+	now func() time.Time
+}
+
+func NewRolloutProgressController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &RolloutProgressController{
+		operatorClient: operatorClient,
+		podLister:      kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		now:            time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+	).WithSync(syncmetrics.Instrument("RolloutProgressController", c.sync)).ResyncEvery(resyncinterval.For("RolloutProgressController", time.Minute)).ToController("RolloutProgressController", eventRecorder.WithComponentSuffix("rollout-progress-controller"))
+}
+
+func (c *RolloutProgressController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	progress, err := c.newRolloutProgress(status)
+	if err != nil {
+		return err
+	}
+	progress.record()
+
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(progress.condition(c.now()))); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *RolloutProgressController) newRolloutProgress(status *operatorv1.StaticPodOperatorStatus) (*rolloutProgress, error) {
+	progress := &rolloutProgress{revision: status.LatestAvailableRevision}
+	for _, nodeStatus := range status.NodeStatuses {
+		node := nodeProgress{nodeName: nodeStatus.NodeName, phase: phaseWaiting}
+		if nodeStatus.CurrentRevision >= status.LatestAvailableRevision {
+			node.phase = phaseDone
+		}
+
+		firstInstaller, err := c.installerPod(fmt.Sprintf("installer-%d-%s", status.LatestAvailableRevision, nodeStatus.NodeName))
+		if err != nil {
+			return nil, err
+		}
+		if firstInstaller != nil {
+			node.started = firstInstaller.CreationTimestamp.Time
+		}
+
+		if node.phase != phaseDone && nodeStatus.TargetRevision == status.LatestAvailableRevision {
+			node.attempt = 1
+			if nodeStatus.LastFailedRevision == nodeStatus.TargetRevision {
+				node.attempt += nodeStatus.LastFailedCount + nodeStatus.LastFallbackCount
+			}
+			name := fmt.Sprintf("installer-%d-retry-%d-%s", nodeStatus.TargetRevision, node.attempt-1, nodeStatus.NodeName)
+			if node.attempt == 1 {
+				name = fmt.Sprintf("installer-%d-%s", nodeStatus.TargetRevision, nodeStatus.NodeName)
+			}
+			installer, err := c.installerPod(name)
+			if err != nil {
+				return nil, err
+			}
+			node.phase, node.since = installerPhase(installer)
+		}
+		progress.nodes = append(progress.nodes, node)
+	}
+	progress.estimate(c.now())
+	return progress, nil
+}
+
+func (c *RolloutProgressController) installerPod(name string) (*corev1.Pod, error) {
+	pod, err := c.podLister.Pods(operatorclient.TargetNamespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return pod, err
+}
+
+// installerPhase maps the installer pod of the current attempt to the phase of its node. The pod is pending while
+// its init containers wait for the canary or for the rollout to be resumed. A failed installer waits for its retry.
+func installerPhase(installer *corev1.Pod) (string, time.Time) {
+	if installer == nil {
+		return phaseWaiting, time.Time{}
+	}
+	switch installer.Status.Phase {
+	case corev1.PodRunning:
+		return phaseInstalling, installer.CreationTimestamp.Time
+	case corev1.PodSucceeded:
+		return phaseVerifying, installer.CreationTimestamp.Time
+	default:
+		return phaseWaiting, installer.CreationTimestamp.Time
+	}
+}
+
+// estimate derives the time a node takes from the start of the first installers of consecutive nodes, the installer
+// controller only starts the next node once the previous one is done. The nodes that are left take that long each,
+// the node in progress counts from its start.
+func (p *rolloutProgress) estimate(now time.Time) {
+	var starts []time.Time
+	remaining := 0
+	var inProgress time.Time
+	for _, node := range p.nodes {
+		if !node.started.IsZero() {
+			starts = append(starts, node.started)
+		}
+		if node.phase == phaseDone {
+			continue
+		}
+		remaining++
+		if !node.started.IsZero() && (inProgress.IsZero() || node.started.After(inProgress)) {
+			inProgress = node.started
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	if len(starts) < 2 || remaining == 0 {
+		return
+	}
+	p.nodeDuration = starts[len(starts)-1].Sub(starts[0]) / time.Duration(len(starts)-1)
+
+	if inProgress.IsZero() {
+		p.estimatedCompletion = now.Add(p.nodeDuration * time.Duration(remaining))
+		return
+	}
+	p.estimatedCompletion = inProgress.Add(p.nodeDuration * time.Duration(remaining))
+	if p.estimatedCompletion.Before(now) {
+		// the node in progress takes longer than the nodes before it, it is at least done now
+		p.estimatedCompletion = now.Add(p.nodeDuration * time.Duration(remaining-1))
+	}
+}
+
+func (p *rolloutProgress) done() bool {
+	for _, node := range p.nodes {
+		if node.phase != phaseDone {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *rolloutProgress) record() {
+	counts := map[string]int{}
+	for _, node := range p.nodes {
+		counts[node.phase]++
+		rolloutNodeAttemptsGauge.WithLabelValues(node.nodeName).Set(float64(node.attempt))
+	}
+	for _, phase := range phases {
+		rolloutNodesGauge.WithLabelValues(phase).Set(float64(counts[phase]))
+	}
+	if p.estimatedCompletion.IsZero() {
+		rolloutEstimatedCompletionGauge.Set(0)
+		return
+	}
+	rolloutEstimatedCompletionGauge.Set(float64(p.estimatedCompletion.Unix()))
+}
+
+func (p *rolloutProgress) condition(now time.Time) operatorv1.OperatorCondition {
+	if p.done() {
+		return operatorv1.OperatorCondition{
+			Type:    RolloutProgressConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "AsExpected",
+			Message: fmt.Sprintf("revision %d is rolled out to all nodes", p.revision),
+		}
+	}
+
+	var lines []string
+	switch {
+	case p.estimatedCompletion.IsZero():
+		lines = append(lines, fmt.Sprintf("rolling out revision %d, no estimate before the first node is done", p.revision))
+	default:
+		lines = append(lines, fmt.Sprintf("rolling out revision %d, a node takes %v, expected to complete at %s", p.revision, p.nodeDuration.Round(time.Second), p.estimatedCompletion.UTC().Format(time.RFC3339)))
+	}
+	for _, node := range p.nodes {
+		line := fmt.Sprintf("%s: %s", node.nodeName, node.phase)
+		if node.attempt > 1 {
+			line = fmt.Sprintf("%s, attempt %d", line, node.attempt)
+		}
+		if !node.since.IsZero() {
+			elapsed := now.Sub(node.since)
+			line = fmt.Sprintf("%s, since %s (%v)", line, node.since.UTC().Format(time.RFC3339), elapsed.Round(time.Second))
+			if p.nodeDuration > 0 && elapsed > 2*p.nodeDuration {
+				line = fmt.Sprintf("%s, more than twice as long as the other nodes", line)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return operatorv1.OperatorCondition{
+		Type:    RolloutProgressConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "RollingOut",
+		Message: strings.Join(lines, "\n"),
+	}
+}
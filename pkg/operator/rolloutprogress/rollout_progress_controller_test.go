@@ -0,0 +1,108 @@
+package rolloutprogress
+
+import (
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRolloutProgress(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	installer := func(name string, created time.Duration, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name, CreationTimestamp: metav1.NewTime(now.Add(-created))},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		status           *operatorv1.StaticPodOperatorStatus
+		pods             []*corev1.Pod
+		expectedPhases   []string
+		expectedStatus   operatorv1.ConditionStatus
+		expectedMessage  string
+		expectedNodeTime time.Duration
+		expectCompletion time.Time
+	}{
+		{
+			name: "rolled out",
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 5},
+				{NodeName: "master-1", CurrentRevision: 5},
+			}},
+			expectedPhases:  []string{phaseDone, phaseDone},
+			expectedStatus:  operatorv1.ConditionFalse,
+			expectedMessage: "revision 5 is rolled out to all nodes",
+		},
+		{
+			name: "first node installing",
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5},
+				{NodeName: "master-1", CurrentRevision: 4},
+			}},
+			pods:           []*corev1.Pod{installer("installer-5-master-0", time.Minute, corev1.PodRunning)},
+			expectedPhases: []string{phaseInstalling, phaseWaiting},
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedMessage: "rolling out revision 5, no estimate before the first node is done\n" +
+				"master-0: Installing, since 2021-06-01T11:59:00Z (1m0s)\n" +
+				"master-1: Waiting",
+		},
+		{
+			name: "retried node after the first",
+			status: &operatorv1.StaticPodOperatorStatus{LatestAvailableRevision: 5, NodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 5},
+				{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedCount: 1},
+				{NodeName: "master-2", CurrentRevision: 4},
+			}},
+			pods: []*corev1.Pod{
+				installer("installer-5-master-0", 10*time.Minute, corev1.PodSucceeded),
+				installer("installer-5-master-1", 6*time.Minute, corev1.PodFailed),
+				installer("installer-5-retry-1-master-1", 2*time.Minute, corev1.PodSucceeded),
+			},
+			expectedPhases:   []string{phaseDone, phaseVerifying, phaseWaiting},
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedNodeTime: 4 * time.Minute,
+			expectCompletion: now.Add(2 * time.Minute),
+			expectedMessage: "rolling out revision 5, a node takes 4m0s, expected to complete at 2021-06-01T12:02:00Z\n" +
+				"master-0: Done\n" +
+				"master-1: Verifying, attempt 2, since 2021-06-01T11:58:00Z (2m0s)\n" +
+				"master-2: Waiting",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, pod := range test.pods {
+				if err := indexer.Add(pod); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &RolloutProgressController{podLister: corev1listers.NewPodLister(indexer), now: func() time.Time { return now }}
+
+			progress, err := c.newRolloutProgress(test.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, node := range progress.nodes {
+				if node.phase != test.expectedPhases[i] {
+					t.Errorf("node %s: expected phase %s, got %s", node.nodeName, test.expectedPhases[i], node.phase)
+				}
+			}
+			if progress.nodeDuration != test.expectedNodeTime || !progress.estimatedCompletion.Equal(test.expectCompletion) {
+				t.Errorf("unexpected estimate: a node takes %v, completion at %v", progress.nodeDuration, progress.estimatedCompletion)
+			}
+			cond := progress.condition(now)
+			if cond.Status != test.expectedStatus || cond.Message != test.expectedMessage {
+				t.Errorf("unexpected condition %s:\n%s", cond.Status, cond.Message)
+			}
+		})
+	}
+}
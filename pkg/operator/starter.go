@@ -12,23 +12,42 @@ import (
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
 	configv1informers "github.com/openshift/client-go/config/informers/externalversions"
 	operatorcontrolplaneclient "github.com/openshift/client-go/operatorcontrolplane/clientset/versioned"
+	operatorcontrolplaneinformers "github.com/openshift/client-go/operatorcontrolplane/informers/externalversions"
 	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/apiavailability"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditpolicycontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/auditwebhookcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/boundsatokensignercontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationtimeupgradeablecontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configmetrics"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/node"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/connectivitycheckcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/controllerloglevel"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/degradeddetails"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/degradedreasons"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/encryptionconfigcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/eventaggregation"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/featureupgradablecontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/healthsummary"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/installermetrics"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/kubeletversionskewcontroller"
-	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodekubeconfigcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/namedcertificatecontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/oidcdiscoverycontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/relatedobjects"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/rolloutprogress"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/staticpoddetection"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/targetconfigcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/terminationobserver"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/webhooksupportabilitycontroller"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
-	"github.com/openshift/library-go/pkg/operator/apiserver/controller/auditpolicy"
 	"github.com/openshift/library-go/pkg/operator/certrotation"
 	"github.com/openshift/library-go/pkg/operator/encryption"
 	"github.com/openshift/library-go/pkg/operator/encryption/controllers/migrators"
@@ -37,7 +56,6 @@ import (
 	"github.com/openshift/library-go/pkg/operator/genericoperatorclient"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/staleconditions"
-	"github.com/openshift/library-go/pkg/operator/staticpod"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/installer"
 	"github.com/openshift/library-go/pkg/operator/staticpod/controller/revision"
 	"github.com/openshift/library-go/pkg/operator/staticresourcecontroller"
@@ -51,16 +69,29 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	kubemigratorclient "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/clientset"
 	migrationv1alpha1informer "sigs.k8s.io/kube-storage-version-migrator/pkg/clients/informer"
 )
 
+// EncryptedResources are the resources the encryption controllers encrypt when encryption is enabled in the
+// apiserver.config.openshift.io/cluster object.
+var EncryptedResources = encryption.StaticEncryptionProvider{
+	schema.GroupResource{Group: "", Resource: "secrets"},
+	schema.GroupResource{Group: "", Resource: "configmaps"},
+}
+
 func RunOperator(ctx context.Context, controllerContext *controllercmd.ControllerContext) error {
+	return RunOperatorWithOperand(ctx, controllerContext, NewOperandForTopology)
+}
+
+// RunOperatorWithOperand runs the operator with the Operand of newOperand.
+func RunOperatorWithOperand(ctx context.Context, controllerContext *controllercmd.ControllerContext, newOperand NewOperandFunc) error {
 	// This kube client use protobuf, do not use it for CR
 	kubeClient, err := kubernetes.NewForConfig(controllerContext.ProtoKubeConfig)
 	if err != nil {
@@ -86,6 +117,16 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	if err != nil {
 		return err
 	}
+
+	// the resync intervals are read once, before the controllers are created. An invalid config keeps the defaults, the
+	// operator must not fail to start because of it.
+	if operatorConfig, err := operatorconfig.GetFromClient(ctx, kubeClient.CoreV1()); err != nil {
+		klog.Warningf("Unable to read the resync intervals of the operator config: %v", err)
+	} else if errs := operatorconfig.ValidateOperatorTuning(operatorConfig.Operator, field.NewPath("operator")); len(errs) > 0 {
+		klog.Warningf("Ignoring the invalid tunings of the operator config: %v", errs.ToAggregate())
+	} else {
+		resyncinterval.Set(operatorConfig.Operator.ResyncIntervals)
+	}
 	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(
 		kubeClient,
 		"",
@@ -98,6 +139,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		"openshift-apiserver",
 	)
 	configInformers := configv1informers.NewSharedInformerFactory(configClient, 10*time.Minute)
+	// for config.openshift.io resources without a typed client
+	configDynamicInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 10*time.Minute)
 	operatorClient, dynamicInformers, err := genericoperatorclient.NewStaticPodOperatorClient(controllerContext.KubeConfig, operatorv1.GroupVersion.WithResource("kubeapiservers"))
 	if err != nil {
 		return err
@@ -113,11 +156,33 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		return err
 	}
 
+	userResourceSyncController := resourcesynccontroller.NewUserResourceSyncController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		resourceSyncController,
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		controllerContext.EventRecorder,
+	)
+
+	// the worker latency profile is only observed when the release installs nodes.config.openshift.io, a CRD added later
+	// is picked up when the operator restarts
+	nodeConfigServed, err := node.NodeConfigServed(kubeClient.Discovery())
+	if err != nil {
+		return err
+	}
+	if !nodeConfigServed {
+		klog.Infof("%s.%s is not served, the worker latency profile is not observed", node.NodeConfigResource.Resource, node.NodeConfigResource.Group)
+	}
+
 	configObserver := configobservercontroller.NewConfigObserver(
 		operatorClient,
 		kubeInformersForNamespaces,
 		configInformers,
+		configDynamicInformers,
+		nodeConfigServed,
 		resourceSyncController,
+		kubeClient.CoreV1(),
 		controllerContext.EventRecorder,
 	)
 
@@ -141,6 +206,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 			"assets/kube-apiserver/check-endpoints-kubeconfig-cm.yaml",
 			"assets/kube-apiserver/check-endpoints-rolebinding-kube-system.yaml",
 			"assets/kube-apiserver/check-endpoints-rolebinding.yaml",
+			"assets/kube-apiserver/check-endpoints-role-audit-log-volume.yaml",
+			"assets/kube-apiserver/check-endpoints-rolebinding-audit-log-volume.yaml",
 			"assets/kube-apiserver/control-plane-node-kubeconfig-cm.yaml",
 			"assets/kube-apiserver/delegated-incluster-authentication-rolebinding.yaml",
 			"assets/kube-apiserver/localhost-recovery-client-crb.yaml",
@@ -154,6 +221,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 			"assets/alerts/cpu-utilization.yaml",
 			"assets/alerts/kube-apiserver-requests.yaml",
 			"assets/alerts/kube-apiserver-slos.yaml",
+			"assets/alerts/kube-apiserver-operator.yaml",
 		},
 		(&resourceapply.ClientHolder{}).
 			WithKubernetes(kubeClient).
@@ -175,13 +243,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
-	nodeKubeconfigController := nodekubeconfigcontroller.NewNodeKubeconfigController(
-		operatorClient,
-		kubeInformersForNamespaces,
-		kubeClient,
-		configInformers.Config().V1().Infrastructures(),
-		controllerContext.EventRecorder,
-	)
+	// collapses the status events of flapping conditions, see eventaggregation.DefaultWindow
+	statusEventRecorder := eventaggregation.NewRecorder(controllerContext.EventRecorder, eventaggregation.DefaultWindow)
 
 	apiextensionsInformers := apiextensionsinformers.NewSharedInformerFactory(apiextensionsClient, 10*time.Minute)
 	connectivityCheckController := connectivitycheckcontroller.NewKubeAPIServerConnectivityCheckController(
@@ -192,6 +255,26 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorcontrolplaneClient,
 		configInformers,
 		apiextensionsInformers,
+		statusEventRecorder,
+	)
+	operatorcontrolplaneInformers := operatorcontrolplaneinformers.NewSharedInformerFactoryWithOptions(operatorcontrolplaneClient, 10*time.Minute, operatorcontrolplaneinformers.WithNamespace(operatorclient.TargetNamespace))
+	connectivityOutageController := connectivitycheckcontroller.NewConnectivityOutageController(
+		operatorClient,
+		operatorcontrolplaneInformers,
+		statusEventRecorder,
+	)
+	connectivitySummaryController := connectivitycheckcontroller.NewConnectivitySummaryController(
+		kubeClient.CoreV1(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace),
+		operatorcontrolplaneInformers,
+		controllerContext.EventRecorder,
+	)
+	apiAvailabilityController := apiavailability.NewAPIAvailabilityController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		operatorcontrolplaneInformers,
+		kubeClient.CoreV1(),
+		kubeClient.Discovery().RESTClient(),
 		controllerContext.EventRecorder,
 	)
 
@@ -206,20 +289,37 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	}
 	versionRecorder.SetVersion("raw-internal", status.VersionForOperatorFromEnv())
 
-	staticPodControllers, err := staticpod.NewBuilder(operatorClient, kubeClient, kubeInformersForNamespaces).
-		WithEvents(controllerContext.EventRecorder).
-		WithCustomInstaller([]string{"cluster-kube-apiserver-operator", "installer"}, installerErrorInjector(operatorClient)).
-		WithPruning([]string{"cluster-kube-apiserver-operator", "prune"}, "kube-apiserver-pod").
-		WithRevisionedResources(operatorclient.TargetNamespace, "kube-apiserver", RevisionConfigMaps, RevisionSecrets).
-		WithUnrevisionedCerts("kube-apiserver-certs", CertConfigMaps, CertSecrets).
-		WithVersioning("kube-apiserver", versionRecorder).
-		WithMinReadyDuration(30*time.Second).
-		WithStartupMonitor(startupmonitorreadiness.IsStartupMonitorEnabledFunction(configInformers.Config().V1().Infrastructures().Lister(), operatorClient), labels.Set{"apiserver": "true"}.AsSelector()).
-		ToControllers()
+	// the operand rolls out the revisions, as static pods through installer pods on the nodes or, if the control
+	// plane is hosted outside of the cluster nodes, as a deployment
+	operand, err := newOperand(ctx, OperandInput{
+		OperatorClient:             operatorClient,
+		KubeClient:                 kubeClient,
+		DynamicClient:              dynamicClient,
+		ConfigClient:               configClient,
+		KubeInformersForNamespaces: kubeInformersForNamespaces,
+		ConfigInformers:            configInformers,
+		VersionRecorder:            versionRecorder,
+		EventRecorder:              controllerContext.EventRecorder,
+		StatusEventRecorder:        statusEventRecorder,
+	})
 	if err != nil {
 		return err
 	}
 
+	healthSummaryController := healthsummary.NewHealthSummaryController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		kubeClient.CoreV1(),
+		controllerContext.EventRecorder,
+	)
+	degradedDetailsController := degradeddetails.NewDegradedDetailsController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		configInformers.Config().V1().Infrastructures(),
+		kubeClient.CoreV1(),
+		controllerContext.EventRecorder,
+	)
+
 	clusterOperatorStatus := status.NewClusterOperatorStatusController(
 		"kube-apiserver",
 		[]configv1.ObjectReference{
@@ -240,8 +340,14 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configInformers.Config().V1().ClusterOperators(),
 		operatorClient,
 		versionRecorder,
-		controllerContext.EventRecorder,
-	)
+		statusEventRecorder,
+	).WithDegradedInertia(staticpoddetection.DegradedInertia(operatorClient, configInformers.Config().V1().Infrastructures().Lister()))
+	clusterOperatorStatus.WithRelatedObjectsFunc(relatedobjects.NewRelatedObjects(
+		operatorClient,
+		kubeInformersForNamespaces,
+		revisionResourceNames(RevisionConfigMaps),
+		revisionResourceNames(RevisionSecrets),
+	).Get)
 
 	certRotationScale, err := certrotation.GetCertRotationScale(kubeClient, operatorclient.GlobalUserSpecifiedConfigNamespace)
 	if err != nil {
@@ -260,8 +366,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		return err
 	}
 
-	staticPodNodeProvider := encryptiondeployer.StaticPodNodeProvider{OperatorClient: operatorClient}
-	deployer, err := encryptiondeployer.NewRevisionLabelPodDeployer("revision", operatorclient.TargetNamespace, kubeInformersForNamespaces, resourceSyncController, kubeClient.CoreV1(), kubeClient.CoreV1(), staticPodNodeProvider)
+	deployer, err := encryptiondeployer.NewRevisionLabelPodDeployer("revision", operatorclient.TargetNamespace, kubeInformersForNamespaces, resourceSyncController, kubeClient.CoreV1(), kubeClient.CoreV1(), operand.EncryptionNodeProvider())
 	if err != nil {
 		return err
 	}
@@ -272,10 +377,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	encryptionControllers, err := encryption.NewControllers(
 		operatorclient.TargetNamespace,
 		nil,
-		encryption.StaticEncryptionProvider{
-			schema.GroupResource{Group: "", Resource: "secrets"},
-			schema.GroupResource{Group: "", Resource: "configmaps"},
-		},
+		EncryptedResources,
 		deployer,
 		migrator,
 		operatorClient,
@@ -292,6 +394,35 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	featureUpgradeableController := featureupgradablecontroller.NewFeatureUpgradeableController(
 		operatorClient,
 		configInformers,
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		controllerContext.EventRecorder,
+	)
+
+	namedCertificateController := namedcertificatecontroller.NewNamedCertificateController(
+		operatorClient,
+		configInformers,
+		kubeInformersForNamespaces,
+		controllerContext.EventRecorder,
+	)
+
+	oidcDiscoveryController := oidcdiscoverycontroller.NewOIDCDiscoveryController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		kubeClient.CoreV1(),
+		controllerContext.EventRecorder,
+	)
+
+	webhookSupportabilityController := webhooksupportabilitycontroller.NewWebhookSupportabilityController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		apiextensionsInformers,
+		dynamicClient,
+		kubeClient.CoreV1(),
+		controllerContext.EventRecorder,
+	)
+
+	controllerLogLevelController := controllerloglevel.NewControllerLogLevelController(
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
 		controllerContext.EventRecorder,
 	)
 
@@ -304,6 +435,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	terminationObserver := terminationobserver.NewTerminationObserver(
 		operatorclient.TargetNamespace,
 		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace),
+		operatorClient,
+		kubeClient.CoreV1(),
 		kubeClient.CoreV1(),
 		controllerContext.EventRecorder,
 	)
@@ -315,14 +448,21 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
-	auditPolicyController := auditpolicy.NewAuditPolicyController(
+	auditPolicyController := auditpolicycontroller.NewAuditPolicyController(
 		operatorclient.TargetNamespace,
 		"kube-apiserver-audit-policies",
 		configInformers.Config().V1().APIServers().Lister(),
 		operatorClient,
 		kubeClient,
 		configInformers,
-		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace),
+		kubeInformersForNamespaces,
+		controllerContext.EventRecorder,
+	)
+
+	auditWebhookController := auditwebhookcontroller.NewAuditWebhookController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		kubeClient.CoreV1(),
 		controllerContext.EventRecorder,
 	)
 
@@ -336,9 +476,11 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
-	kubeletVersionSkewController := kubeletversionskewcontroller.NewKubeletVersionSkewController(
+	encryptionConfigController := encryptionconfigcontroller.NewEncryptionConfigController(
+		operatorclient.TargetNamespace,
 		operatorClient,
-		kubeInformersForNamespaces,
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps(),
 		controllerContext.EventRecorder,
 	)
 
@@ -348,35 +490,91 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	// register config metrics
 	configmetrics.Register(configInformers)
 
+	// register config observer metrics
+	configobservercontroller.RegisterMetrics()
+
+	// register rollout progress metrics
+	rolloutprogress.RegisterMetrics()
+
+	// register installer metrics
+	installermetrics.RegisterMetrics()
+
+	// register degraded reasons metrics
+	degradedreasons.RegisterMetrics()
+
+	// register API availability metrics
+	apiavailability.RegisterMetrics()
+
+	// register webhook supportability metrics
+	webhooksupportabilitycontroller.RegisterMetrics()
+
+	// register kubelet version skew metrics
+	kubeletversionskewcontroller.RegisterMetrics()
+
+	// register controller sync metrics
+	syncmetrics.RegisterMetrics()
+
 	kubeInformersForNamespaces.Start(ctx.Done())
 	configInformers.Start(ctx.Done())
 	dynamicInformers.Start(ctx.Done())
+	configDynamicInformers.Start(ctx.Done())
 	migrationInformer.Start(ctx.Done())
 	apiextensionsInformers.Start(ctx.Done())
+	operatorcontrolplaneInformers.Start(ctx.Done())
 
-	go staticPodControllers.Start(ctx)
+	go operand.Start(ctx)
+	for _, controller := range operand.Controllers() {
+		go controller.Run(ctx, 1)
+	}
 	go resourceSyncController.Run(ctx, 1)
+	go userResourceSyncController.Run(ctx, 1)
 	go staticResourceController.Run(ctx, 1)
 	go targetConfigReconciler.Run(ctx, 1)
-	go nodeKubeconfigController.Run(ctx, 1)
+	if unused := resyncinterval.Unused(); len(unused) > 0 {
+		klog.Warningf("Ignoring the resync intervals of unknown controllers: %v", unused)
+	}
+
 	go configObserver.Run(ctx, 1)
 	go clusterOperatorStatus.Run(ctx, 1)
 	go certRotationController.Run(ctx, 1)
 	go encryptionControllers.Run(ctx, 1)
+	go encryptionConfigController.Run(ctx, 1)
 	go featureUpgradeableController.Run(ctx, 1)
+	go namedCertificateController.Run(ctx, 1)
+	go oidcDiscoveryController.Run(ctx, 1)
+	go webhookSupportabilityController.Run(ctx, 1)
 	go certRotationTimeUpgradeableController.Run(ctx, 1)
 	go terminationObserver.Run(ctx, 1)
 	go eventWatcher.Run(ctx, 1)
 	go boundSATokenSignerController.Run(ctx, 1)
 	go auditPolicyController.Run(ctx, 1)
+	go auditWebhookController.Run(ctx, 1)
 	go staleConditionsController.Run(ctx, 1)
 	go connectivityCheckController.Run(ctx, 1)
-	go kubeletVersionSkewController.Run(ctx, 1)
+	go connectivityOutageController.Run(ctx, 1)
+	go connectivitySummaryController.Run(ctx, 1)
+	go apiAvailabilityController.Run(ctx, 1)
+	go healthSummaryController.Run(ctx, 1)
+	go degradedDetailsController.Run(ctx, 1)
+	go controllerLogLevelController.Run(ctx, 1)
+	go statusEventRecorder.Run(ctx)
 
 	<-ctx.Done()
 	return nil
 }
 
+// installerPodMutations applies the mutations to the installer pod in order, stopping at the first error.
+func installerPodMutations(mutations ...installer.InstallerPodMutationFunc) installer.InstallerPodMutationFunc {
+	return func(pod *corev1.Pod, nodeName string, operatorSpec *operatorv1.StaticPodOperatorSpec, revision int32) error {
+		for _, mutation := range mutations {
+			if err := mutation(pod, nodeName, operatorSpec, revision); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // installerErrorInjector mutates the given installer pod to fail or OOM depending on the propability (
 // - 0 <= unsupportedConfigOverrides.installerErrorInjection.failPropability <= 1.0: fail the pod (crash loop)
 // - 0 <= unsupportedConfigOverrides.installerErrorInjection.oomPropability <= 1.0: cause OOM due to 1 MB memory limits
@@ -456,6 +654,17 @@ var RevisionConfigMaps = []revision.RevisionResource{
 	{Name: "sa-token-signing-certs"},
 
 	{Name: "kube-apiserver-audit-policies"},
+	// CA bundle of the endpoint of the audit forwarder
+	{Name: "audit-forwarder-ca", Optional: true},
+}
+
+// revisionResourceNames returns the names of revisioned resources without the revision suffix.
+func revisionResourceNames(resources []revision.RevisionResource) []string {
+	var names []string
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return names
 }
 
 // RevisionSecrets is a list of secrets that are directly copied for the current values.  A different actor/controller modifies these.
@@ -470,6 +679,8 @@ var RevisionSecrets = []revision.RevisionResource{
 	{Name: "localhost-recovery-client-token"},
 
 	{Name: "webhook-authenticator", Optional: true},
+	// kubeconfig of the audit webhook
+	{Name: "audit-webhook", Optional: true},
 }
 
 var CertConfigMaps = []installer.UnrevisionedResource{
@@ -479,6 +690,9 @@ var CertConfigMaps = []installer.UnrevisionedResource{
 	// this is a copy of trusted-ca-bundle CM without the injection annotations
 	{Name: "trusted-ca-bundle", Optional: true},
 
+	// this is a copy of the image registry additional trusted CAs, appended to the system trust bundle
+	{Name: "image-additional-trusted-ca", Optional: true},
+
 	// kubeconfig that is a system:master.  this ensures a stable location
 	{Name: "control-plane-node-kubeconfig"},
 
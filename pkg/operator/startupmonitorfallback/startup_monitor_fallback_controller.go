@@ -0,0 +1,155 @@
+package startupmonitorfallback
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	StartupMonitorFallbackConditionType = "StartupMonitorFallback"
+	RolledBackConditionType             = "RolledBack"
+
+	FallbackToLastKnownGoodReason = "FallbackToLastKnownGood"
+	NoFallbackReason              = "NoFallback"
+	RolledBackReason              = "RolledBackToLastKnownGood"
+
+	// the node status reasons of the installer controller
+	installerFailedReason       = "InstallerFailed"
+	operandFailedFallbackReason = "OperandFailedFallback"
+
+	// repeatedInstallerFailures is how often an installer has to fail before a node counts as rolled back, single
+	// failures are retried and usually transient
+	repeatedInstallerFailures = 3
+
+	// the annotations the startup monitor sets on the static pod it restored
+	fallbackForRevisionAnnotation = "startup-monitor.static-pods.openshift.io/fallback-for-revision"
+	fallbackReasonAnnotation      = "startup-monitor.static-pods.openshift.io/fallback-reason"
+	fallbackMessageAnnotation     = "startup-monitor.static-pods.openshift.io/fallback-message"
+)
+
+// startupMonitorFallbackController reports through the StartupMonitorFallback condition which nodes the startup
+// monitor rolled back to the last known good revision, and why. The RolledBack condition additionally covers nodes
+// which stay on their previous revision because the installer of the new one failed repeatedly.
+type startupMonitorFallbackController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	podLister      corev1listers.PodLister
+}
+
+func NewStartupMonitorFallbackController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &startupMonitorFallbackController{
+		operatorClient: operatorClient,
+		podLister:      kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+	}
+	return factory.New().
+		WithSync(syncmetrics.Instrument("StartupMonitorFallbackController", c.sync)).
+		WithInformers(
+			operatorClient.Informer(),
+			kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+		).
+		ToController("StartupMonitorFallbackController", recorder.WithComponentSuffix("startup-monitor-fallback-controller"))
+}
+
+func (c *startupMonitorFallbackController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	pods, err := c.podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{"apiserver": "true"}))
+	if err != nil {
+		return err
+	}
+
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	var updateFuncs []v1helpers.UpdateStatusFunc
+	for _, cond := range []operatorv1.OperatorCondition{newFallbackCondition(pods), newRolledBackCondition(status.NodeStatuses)} {
+		if previous := v1helpers.FindOperatorCondition(status.Conditions, cond.Type); cond.Status == operatorv1.ConditionTrue && (previous == nil || previous.Status != operatorv1.ConditionTrue || previous.Message != cond.Message) {
+			syncCtx.Recorder().Warningf(cond.Type, cond.Message)
+		}
+		updateFuncs = append(updateFuncs, v1helpers.UpdateConditionFn(cond))
+	}
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, updateFuncs...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newFallbackCondition(pods []*corev1.Pod) operatorv1.OperatorCondition {
+	var messages []string
+	for _, pod := range pods {
+		failedRevision, ok := pod.Annotations[fallbackForRevisionAnnotation]
+		if !ok {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("node %q fell back from revision %s to revision %s: %s: %s",
+			pod.Spec.NodeName,
+			failedRevision,
+			pod.Labels["revision"],
+			pod.Annotations[fallbackReasonAnnotation],
+			pod.Annotations[fallbackMessageAnnotation],
+		))
+	}
+	if len(messages) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   StartupMonitorFallbackConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: NoFallbackReason,
+		}
+	}
+	sort.Strings(messages)
+	return operatorv1.OperatorCondition{
+		Type:    StartupMonitorFallbackConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  FallbackToLastKnownGoodReason,
+		Message: strings.Join(messages, "\n"),
+	}
+}
+
+// newRolledBackCondition is true while a node runs an older revision than the one which failed on it, either because
+// the startup monitor fell back to the last known good revision or because the installer failed repeatedly.
+func newRolledBackCondition(nodeStatuses []operatorv1.NodeStatus) operatorv1.OperatorCondition {
+	var messages []string
+	for _, nodeStatus := range nodeStatuses {
+		if nodeStatus.LastFailedRevision <= nodeStatus.CurrentRevision {
+			continue
+		}
+		switch {
+		case nodeStatus.LastFailedReason == operandFailedFallbackReason:
+			messages = append(messages, fmt.Sprintf("node %q rolled back from revision %d to revision %d: %s",
+				nodeStatus.NodeName, nodeStatus.LastFailedRevision, nodeStatus.CurrentRevision, strings.Join(nodeStatus.LastFailedRevisionErrors, "; ")))
+		case nodeStatus.LastFailedReason == installerFailedReason && nodeStatus.LastFailedCount >= repeatedInstallerFailures:
+			messages = append(messages, fmt.Sprintf("node %q stays on revision %d, installing revision %d failed %d times: %s",
+				nodeStatus.NodeName, nodeStatus.CurrentRevision, nodeStatus.LastFailedRevision, nodeStatus.LastFailedCount, strings.Join(nodeStatus.LastFailedRevisionErrors, "; ")))
+		}
+	}
+	if len(messages) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   RolledBackConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}
+	}
+	sort.Strings(messages)
+	return operatorv1.OperatorCondition{
+		Type:    RolledBackConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  RolledBackReason,
+		Message: strings.Join(messages, "\n"),
+	}
+}
@@ -0,0 +1,110 @@
+package startupmonitorfallback
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewFallbackCondition(t *testing.T) {
+	pod := func(node, revision string, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "kube-apiserver-" + node,
+				Labels:      map[string]string{"apiserver": "true", "revision": revision},
+				Annotations: annotations,
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	fallback := map[string]string{
+		fallbackForRevisionAnnotation: "5",
+		fallbackReasonAnnotation:      "SyncLoopRefresh",
+		fallbackMessageAnnotation:     "waiting for readyz timed out",
+	}
+
+	tests := []struct {
+		name     string
+		pods     []*corev1.Pod
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name: "no fallback",
+			pods: []*corev1.Pod{pod("master-0", "5", nil), pod("master-1", "5", nil)},
+			expected: operatorv1.OperatorCondition{
+				Type:   StartupMonitorFallbackConditionType,
+				Status: operatorv1.ConditionFalse,
+				Reason: NoFallbackReason,
+			},
+		},
+		{
+			name: "fallback on one node",
+			pods: []*corev1.Pod{pod("master-1", "5", nil), pod("master-0", "4", fallback)},
+			expected: operatorv1.OperatorCondition{
+				Type:    StartupMonitorFallbackConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  FallbackToLastKnownGoodReason,
+				Message: `node "master-0" fell back from revision 5 to revision 4: SyncLoopRefresh: waiting for readyz timed out`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := newFallbackCondition(test.pods)
+			if !cmp.Equal(test.expected, actual) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(test.expected, actual))
+			}
+		})
+	}
+}
+
+func TestNewRolledBackCondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeStatuses []operatorv1.NodeStatus
+		expected     operatorv1.OperatorCondition
+	}{
+		{
+			name: "rolled out",
+			nodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 5},
+				{NodeName: "master-1", CurrentRevision: 5, LastFailedRevision: 5, LastFailedReason: operandFailedFallbackReason},
+			},
+			expected: operatorv1.OperatorCondition{Type: RolledBackConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "single installer failure",
+			nodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: installerFailedReason, LastFailedCount: 1},
+			},
+			expected: operatorv1.OperatorCondition{Type: RolledBackConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "fallback and repeated installer failures",
+			nodeStatuses: []operatorv1.NodeStatus{
+				{NodeName: "master-1", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: installerFailedReason, LastFailedCount: 3, LastFailedRevisionErrors: []string{"installer: no space left on device"}},
+				{NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5, LastFailedRevision: 5, LastFailedReason: operandFailedFallbackReason, LastFailedRevisionErrors: []string{"CrashLooping: kube-apiserver container restarted 3 times"}},
+			},
+			expected: operatorv1.OperatorCondition{
+				Type:   RolledBackConditionType,
+				Status: operatorv1.ConditionTrue,
+				Reason: RolledBackReason,
+				Message: `node "master-0" rolled back from revision 5 to revision 4: CrashLooping: kube-apiserver container restarted 3 times` + "\n" +
+					`node "master-1" stays on revision 4, installing revision 5 failed 3 times: installer: no space left on device`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := newRolledBackCondition(test.nodeStatuses)
+			if !cmp.Equal(test.expected, actual) {
+				t.Fatalf("unexpected condition, diff = %v", cmp.Diff(test.expected, actual))
+			}
+		})
+	}
+}
@@ -10,11 +10,32 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 )
 
-// IsStartupMonitorEnabledFunction returns a function that determines if the startup monitor should be enabled on a cluster
+// IsStartupMonitorEnabledFunction returns a function that determines if the startup monitor should be enabled on a cluster.
+// An explicit startupMonitor.mode of the operator config wins over the topology default.
 func IsStartupMonitorEnabledFunction(infrastructureLister configlistersv1.InfrastructureLister, operatorClient v1helpers.StaticPodOperatorClient) func() (bool, error) {
 	return func() (bool, error) {
+		operatorSpec, _, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			return false, err
+		}
+		if len(operatorSpec.ObservedConfig.Raw) > 0 {
+			observedConfig := map[string]interface{}{}
+			if err := json.NewDecoder(bytes.NewBuffer(operatorSpec.ObservedConfig.Raw)).Decode(&observedConfig); err != nil {
+				return false, err
+			}
+			mode, _, _ := unstructured.NestedString(observedConfig, "startupMonitor", "mode")
+			switch operatorconfig.StartupMonitorMode(mode) {
+			case operatorconfig.StartupMonitorEnabled:
+				return true, nil
+			case operatorconfig.StartupMonitorDisabled:
+				return false, nil
+			}
+		}
+
 		infra, err := infrastructureLister.Get("cluster")
 		// we won't be without an infra for very long.  This means we're starting up very early in the process, so
 		// being able to detect that a rollback of a revision is needed isn't necessary since the stakes are low because
@@ -33,10 +54,6 @@ func IsStartupMonitorEnabledFunction(infrastructureLister configlistersv1.Infras
 		}
 
 		// for development and debugging
-		operatorSpec, _, _, err := operatorClient.GetOperatorState()
-		if err != nil {
-			return false, err
-		}
 		if len(operatorSpec.UnsupportedConfigOverrides.Raw) > 0 {
 			observedUnsupportedConfig := map[string]interface{}{}
 			if err := json.NewDecoder(bytes.NewBuffer(operatorSpec.UnsupportedConfigOverrides.Raw)).Decode(&observedUnsupportedConfig); err != nil {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 	"github.com/openshift/library-go/pkg/operator/staticpod/startupmonitor"
+	"github.com/spf13/pflag"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +40,16 @@ type KubeAPIReadinessChecker struct {
 	// currentNodeName holds the name of the node we are currently running on
 	// primarly introduced for easier testing on an HA cluster
 	currentNodeName string
+
+	// readyzSuccessThreshold is the number of consecutive successful readyz checks required
+	readyzSuccessThreshold int
+
+	// readyzInterval is the time between two readyz checks
+	readyzInterval time.Duration
+
+	// crashLoopThreshold is the number of kube-apiserver container restarts after which the revision is not
+	// considered ready anymore, zero disables the check
+	crashLoopThreshold int
 }
 
 var _ startupmonitor.ReadinessChecker = &KubeAPIReadinessChecker{}
@@ -48,10 +59,19 @@ var _ startupmonitor.WantsNodeName = &KubeAPIReadinessChecker{}
 // New creates a new Kube API readiness checker
 func New() *KubeAPIReadinessChecker {
 	return &KubeAPIReadinessChecker{
-		baseRawURL: "https://localhost:6443",
+		baseRawURL:             "https://localhost:6443",
+		readyzSuccessThreshold: 3,
+		readyzInterval:         5 * time.Second,
 	}
 }
 
+// AddFlags registers the flags of the readyz checks, they are set from the startupMonitor of the operator config
+func (ch *KubeAPIReadinessChecker) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&ch.readyzSuccessThreshold, "readyz-success-threshold", ch.readyzSuccessThreshold, "The number of consecutive successful readyz checks required before the revision is considered ready.")
+	fs.DurationVar(&ch.readyzInterval, "readyz-interval", ch.readyzInterval, "The interval between two readyz checks.")
+	fs.IntVar(&ch.crashLoopThreshold, "crash-loop-threshold", ch.crashLoopThreshold, "The number of kube-apiserver container restarts after which the revision is not considered ready anymore. Zero disables the check.")
+}
+
 // SetRestConfig called by startup monitor to provide a valid configuration for authN/authZ against Kube API server
 func (ch *KubeAPIReadinessChecker) SetRestConfig(config *rest.Config) {
 	ch.restConfig = config
@@ -98,11 +118,13 @@ func (ch *KubeAPIReadinessChecker) IsReady(ctx context.Context, revision int) (
 	// loop through a list of ordered checks for assessing Kube API readiness condition
 	for _, checkFn := range []func(context.Context) (bool, string, string){
 		//	TODO: watch /var/log/kube-apiserver/termination.log for the first start-up attempt (beware of the race of startup-monitor startup and kube-apiserver startup). Set Reason=NeverStartedUp when this times out.
-		//	TODO: watch /var/log/kube-apiserver/termination.log for more than one start-up attempt. Set Reason=CrashLooping if more than one is found and the monitor times out.
 
 		// checks if we are not dealing with the old kas
 		noOldRevisionPodExists(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), revision, ch.currentNodeName),
 
+		// check that the kas container of the new revision does not crash-loop
+		noCrashLoop(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), revision, ch.currentNodeName, ch.crashLoopThreshold),
+
 		// check kube-apiserver /healthz/etcd endpoint
 		goodHealthzEtcdEndpoint(ch.client, ch.baseRawURL),
 
@@ -110,7 +132,7 @@ func (ch *KubeAPIReadinessChecker) IsReady(ctx context.Context, revision int) (
 		goodHealthzEndpoint(ch.client, ch.baseRawURL),
 
 		// check kube-apiserver /readyz endpoint
-		goodReadyzEndpoint(ch.client, ch.baseRawURL, 3, 5*time.Second),
+		goodReadyzEndpoint(ch.client, ch.baseRawURL, ch.readyzSuccessThreshold, ch.readyzInterval),
 
 		// check if the kas pod is running at the expected revision
 		newRevisionPodExists(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), revision, ch.currentNodeName),
@@ -194,6 +216,39 @@ func noOldRevisionPodExists(podClient corev1client.PodInterface, monitorRevision
 	}
 }
 
+// noCrashLoop checks that the kube-apiserver container of the new revision restarted less than threshold times
+//
+// note that:
+// once the threshold is reached the check never passes again, the startup monitor falls back when its timeout expires
+func noCrashLoop(podClient corev1client.PodInterface, monitorRevision int, currentNodeName string, threshold int) func(context.Context) (bool, string, string) {
+	return func(ctx context.Context) (bool, string, string) {
+		if threshold <= 0 {
+			return true, "", ""
+		}
+		apiServerPods, err := podClient.List(ctx, metav1.ListOptions{LabelSelector: "apiserver=true"})
+		if err != nil {
+			// the other checks report the pod
+			return true, "", ""
+		}
+		for _, kasPod := range filterByNodeName(apiServerPods.Items, currentNodeName) {
+			if ready, _, _ := checkRevision(&kasPod, monitorRevision); !ready {
+				continue
+			}
+			for _, containerStatus := range kasPod.Status.ContainerStatuses {
+				if containerStatus.Name != "kube-apiserver" || int(containerStatus.RestartCount) < threshold {
+					continue
+				}
+				message := fmt.Sprintf("kube-apiserver container of static pod %s restarted %d times", kasPod.Name, containerStatus.RestartCount)
+				if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil && len(terminated.Message) > 0 {
+					message = fmt.Sprintf("%s, last termination: %s", message, terminated.Message)
+				}
+				return false, "CrashLooping", message
+			}
+		}
+		return true, "", ""
+	}
+}
+
 // checkRevisionOnPod checks if the kas pod is running at the expected revision
 //
 // strictMode controls whether a certain errors like: failing to get the pod or absence of the pod should be fatal
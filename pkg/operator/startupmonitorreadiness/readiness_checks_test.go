@@ -185,6 +185,69 @@ func TestNoOldRevisionPodExists(t *testing.T) {
 
 }
 
+func TestNoCrashLoop(t *testing.T) {
+	crashLooping := func(revision string, restarts int32) *corev1.Pod {
+		pod := newPod(corev1.PodRunning, corev1.ConditionFalse, revision, "kas", "master-1")
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:                 "kube-apiserver",
+			RestartCount:         restarts,
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "invalid flag"}},
+		}}
+		return pod
+	}
+
+	scenarios := []struct {
+		name    string
+		healthy bool
+		reason  string
+		msg     string
+
+		threshold      int
+		initialObjects []runtime.Object
+	}{
+		{
+			name:           "scenario 1: disabled",
+			healthy:        true,
+			initialObjects: []runtime.Object{crashLooping("3", 10)},
+		},
+
+		{
+			name:           "scenario 2: below the threshold",
+			healthy:        true,
+			threshold:      3,
+			initialObjects: []runtime.Object{crashLooping("3", 2)},
+		},
+
+		{
+			name:           "scenario 3: crash-looping",
+			healthy:        false,
+			reason:         "CrashLooping",
+			msg:            "kube-apiserver container of static pod kas restarted 3 times, last termination: invalid flag",
+			threshold:      3,
+			initialObjects: []runtime.Object{crashLooping("3", 3)},
+		},
+
+		{
+			name:           "scenario 4: old revision",
+			healthy:        true,
+			threshold:      3,
+			initialObjects: []runtime.Object{crashLooping("2", 3)},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			// test data
+			fakeKubeClient := fake.NewSimpleClientset(scenario.initialObjects...)
+
+			// act and validate
+			doCheckAndValidate(t, func() (bool, string, string) {
+				return noCrashLoop(fakeKubeClient.CoreV1().Pods("openshift-kube-apiserver"), 3, "master-1", scenario.threshold)(context.TODO())
+			}, scenario.healthy, scenario.reason, scenario.msg)
+		})
+	}
+}
+
 func TestNewRevisionPodExists(t *testing.T) {
 	scenarios := []struct {
 		name    string
@@ -0,0 +1,40 @@
+package staticpoddetection
+
+import (
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/condition"
+	"github.com/openshift/library-go/pkg/operator/status"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/klog/v2"
+)
+
+// defaultDegradedInertia is the inertia of the status controller of library-go for every degraded condition.
+const defaultDegradedInertia = 2 * time.Minute
+
+// DegradedInertia returns how long a degraded condition has to be true before the cluster operator is degraded. The
+// StaticPodsDegraded condition of the static pod state controller of library-go is set as soon as a static pod is
+// missing or failing, the failing pod timeout delays its effect. MissingStaticPodDegraded applies its timeout itself.
+func DegradedInertia(operatorClient v1helpers.StaticPodOperatorClient, infraLister configlistersv1.InfrastructureLister) status.Inertia {
+	return func(cond operatorv1.OperatorCondition) time.Duration {
+		switch cond.Type {
+		case condition.StaticPodsDegradedConditionType:
+			spec, _, _, err := operatorClient.GetStaticPodOperatorState()
+			if err != nil {
+				klog.Warningf("Failed to get the static pod detection timeouts: %v", err)
+				return defaultDegradedInertia
+			}
+			timeouts, err := timeoutsFromSpec(&spec.OperatorSpec, infraLister)
+			if err != nil {
+				klog.Warningf("Failed to get the static pod detection timeouts: %v", err)
+			}
+			return timeouts.failingPod
+		case MissingStaticPodDegradedConditionType:
+			return 0
+		default:
+			return defaultDegradedInertia
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package staticpoddetection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	MissingStaticPodDegradedConditionType = "MissingStaticPodDegraded"
+
+	// MissingStaticPodReason tells the installer wrote the manifest, but the kubelet did not start the static pod
+	MissingStaticPodReason = "KubeletNotObservingManifest"
+)
+
+// MissingStaticPodController reports the nodes whose kube-apiserver static pod of the revision being installed did not
+// show up within the missing pod timeout after the installer finished, in the MissingStaticPodDegraded condition. The
+// kubelet of a node that boots slowly can take minutes to start a new static pod, the timeout depends on the control
+// plane topology and is configurable. With rewriteManifest, a manifest-rewrite pod rewrites the manifest of the revision
+// once per node, the kubelet sees a new file like after the installer.
+type MissingStaticPodController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+	podClient      coreclientv1.PodsGetter
+	podLister      corev1listers.PodLister
+	infraLister    configlistersv1.InfrastructureLister
+
+	operatorImagePullSpec string
+
+	now func() time.Time
+}
+
+func NewMissingStaticPodController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	infraInformer configv1informers.InfrastructureInformer,
+	podClient coreclientv1.PodsGetter,
+	operatorImagePullSpec string,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &MissingStaticPodController{
+		operatorClient:        operatorClient,
+		podClient:             podClient,
+		podLister:             kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Lister(),
+		infraLister:           infraInformer.Lister(),
+		operatorImagePullSpec: operatorImagePullSpec,
+		now:                   time.Now,
+	}
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Pods().Informer(),
+		infraInformer.Informer(),
+	).WithSync(syncmetrics.Instrument("MissingStaticPodController", c.sync)).ResyncEvery(resyncinterval.For("MissingStaticPodController", 30*time.Second)).ToController("MissingStaticPodController", eventRecorder.WithComponentSuffix("missing-static-pod-controller"))
+}
+
+func (c *MissingStaticPodController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	timeouts, err := timeoutsFromSpec(&spec.OperatorSpec, c.infraLister)
+	if err != nil {
+		return err
+	}
+	detection, err := detectionFromSpec(&spec.OperatorSpec)
+	if err != nil {
+		return err
+	}
+
+	cond, missing, err := c.newMissingStaticPodCondition(status, timeouts.missingPod)
+	if err != nil {
+		return err
+	}
+	if _, _, err := v1helpers.UpdateStatus(c.operatorClient, v1helpers.UpdateConditionFn(cond)); err != nil {
+		return err
+	}
+
+	if !detection.RewriteManifest {
+		return nil
+	}
+	for _, nodeStatus := range missing {
+		if err := c.ensureManifestRewrite(ctx, syncCtx.Recorder(), nodeStatus.NodeName, nodeStatus.TargetRevision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureManifestRewrite creates the manifest-rewrite pod of the revision on the node, unless it exists already. The pod
+// copies the manifest the installer wrote for the revision to a hidden file, which the kubelet ignores, and renames it
+// to the static pod manifest. The kubelet is notified about a new file.
+func (c *MissingStaticPodController) ensureManifestRewrite(ctx context.Context, recorder events.Recorder, nodeName string, revision int32) error {
+	name := fmt.Sprintf("manifest-rewrite-%d-%s", revision, nodeName)
+	_, err := c.podLister.Pods(operatorclient.TargetNamespace).Get(name)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	_, err = c.podClient.Pods(operatorclient.TargetNamespace).Create(ctx, newManifestRewritePod(name, nodeName, revision, c.operatorImagePullSpec), metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	recorder.Warningf("StaticPodManifestRewritten", "the kubelet of node %s did not start the static pod of revision %d, rewriting its manifest", nodeName, revision)
+	return nil
+}
+
+func newManifestRewritePod(name, nodeName string, revision int32, image string) *corev1.Pod {
+	source := fmt.Sprintf("/etc/kubernetes/static-pod-resources/kube-apiserver-pod-%d/kube-apiserver-pod.yaml", revision)
+	privileged := true
+	var rootUser int64
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorclient.TargetNamespace,
+			Name:      name,
+			Labels:    map[string]string{"app": "manifest-rewrite"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:           nodeName,
+			ServiceAccountName: "installer-sa",
+			RestartPolicy:      corev1.RestartPolicyNever,
+			PriorityClassName:  "system-node-critical",
+			Tolerations:        []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    "manifest-rewrite",
+				Image:   image,
+				Command: []string{"/bin/bash", "-euxo", "pipefail", "-c"},
+				Args: []string{fmt.Sprintf(
+					"cp %s /etc/kubernetes/manifests/.kube-apiserver-pod.yaml.rewrite && mv -f /etc/kubernetes/manifests/.kube-apiserver-pod.yaml.rewrite /etc/kubernetes/manifests/kube-apiserver-pod.yaml",
+					source,
+				)},
+				SecurityContext:          &corev1.SecurityContext{Privileged: &privileged, RunAsUser: &rootUser},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				VolumeMounts:             []corev1.VolumeMount{{Name: "kubelet-dir", MountPath: "/etc/kubernetes/"}},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("5m"),
+						corev1.ResourceMemory: resource.MustParse("10Mi"),
+					},
+				},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "kubelet-dir",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc/kubernetes/"}},
+			}},
+		},
+	}
+}
+
+// newMissingStaticPodCondition returns the condition and the node statuses of the nodes whose static pod is missing.
+func (c *MissingStaticPodController) newMissingStaticPodCondition(status *operatorv1.StaticPodOperatorStatus, timeout time.Duration) (operatorv1.OperatorCondition, []operatorv1.NodeStatus, error) {
+	missingPods, err := MissingStaticPods(c.podLister, status, timeout, c.now())
+	if err != nil {
+		return operatorv1.OperatorCondition{}, nil, err
+	}
+	if len(missingPods) == 0 {
+		return operatorv1.OperatorCondition{
+			Type:   MissingStaticPodDegradedConditionType,
+			Status: operatorv1.ConditionFalse,
+			Reason: "AsExpected",
+		}, nil, nil
+	}
+
+	var missing []string
+	var missingNodes []operatorv1.NodeStatus
+	for _, missingPod := range missingPods {
+		missingNodes = append(missingNodes, missingPod.NodeStatus)
+		missing = append(missing, missingPod.Message)
+	}
+	sort.Strings(missing)
+	return operatorv1.OperatorCondition{
+		Type:    MissingStaticPodDegradedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  MissingStaticPodReason,
+		Message: fmt.Sprintf("the kubelet did not start the static pod within %v:\n%s", timeout, strings.Join(missing, "\n")),
+	}, missingNodes, nil
+}
+
+// MissingStaticPod is a node whose kubelet did not start the static pod of the revision being installed.
+type MissingStaticPod struct {
+	NodeStatus operatorv1.NodeStatus
+	// Message tells how long the static pod is missing and which revision the kubelet runs instead.
+	Message string
+}
+
+// MissingStaticPods returns the nodes, in the order of the node statuses, whose kube-apiserver static pod of the
+// revision being installed did not show up within the timeout after the installer finished.
+func MissingStaticPods(podLister corev1listers.PodLister, status *operatorv1.StaticPodOperatorStatus, timeout time.Duration, now time.Time) ([]MissingStaticPod, error) {
+	installers, err := podLister.Pods(operatorclient.TargetNamespace).List(labels.SelectorFromSet(labels.Set{"app": "installer"}))
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingStaticPod
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.TargetRevision <= nodeStatus.CurrentRevision {
+			continue
+		}
+		finished := installerFinished(installers, nodeStatus.NodeName, nodeStatus.TargetRevision)
+		if finished.IsZero() {
+			continue
+		}
+		waited := now.Sub(finished)
+		if waited <= timeout {
+			continue
+		}
+
+		found := "no static pod found"
+		pod, err := podLister.Pods(operatorclient.TargetNamespace).Get(fmt.Sprintf("kube-apiserver-%s", nodeStatus.NodeName))
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		case pod.Labels["revision"] == fmt.Sprintf("%d", nodeStatus.TargetRevision):
+			continue
+		default:
+			found = fmt.Sprintf("found revision %s", pod.Labels["revision"])
+		}
+		missing = append(missing, MissingStaticPod{
+			NodeStatus: nodeStatus,
+			Message: fmt.Sprintf("node %q: the static pod of revision %d did not show up %v after its installer finished, %s",
+				nodeStatus.NodeName, nodeStatus.TargetRevision, waited.Round(time.Second), found),
+		})
+	}
+	return missing, nil
+}
+
+// installerFinished returns when the last successful installer of the revision on the node finished, zero if none did.
+func installerFinished(installers []*corev1.Pod, nodeName string, revision int32) time.Time {
+	var finished time.Time
+	prefix := fmt.Sprintf("installer-%d-", revision)
+	for _, installer := range installers {
+		if installer.Spec.NodeName != nodeName || installer.Status.Phase != corev1.PodSucceeded || !strings.HasPrefix(installer.Name, prefix) {
+			continue
+		}
+		for _, containerStatus := range installer.Status.ContainerStatuses {
+			if terminated := containerStatus.State.Terminated; terminated != nil && terminated.FinishedAt.After(finished) {
+				finished = terminated.FinishedAt.Time
+			}
+		}
+	}
+	return finished
+}
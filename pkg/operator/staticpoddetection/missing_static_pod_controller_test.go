@@ -0,0 +1,188 @@
+package staticpoddetection
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewMissingStaticPodCondition(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	installer := func(name, nodeName string, phase corev1.PodPhase, finished time.Time) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: name, Labels: map[string]string{"app": "installer"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if !finished.IsZero() {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(finished)}}}}
+		}
+		return pod
+	}
+	staticPod := func(nodeName, revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-kube-apiserver", Name: "kube-apiserver-" + nodeName, Labels: map[string]string{"revision": revision}}}
+	}
+	status := &operatorv1.StaticPodOperatorStatus{
+		LatestAvailableRevision: 5,
+		NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 4, TargetRevision: 5},
+			{NodeName: "master-1", CurrentRevision: 4},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		pods     []*corev1.Pod
+		expected operatorv1.OperatorCondition
+	}{
+		{
+			name:     "installer running",
+			pods:     []*corev1.Pod{installer("installer-5-master-0", "master-0", corev1.PodRunning, time.Time{}), staticPod("master-0", "4")},
+			expected: operatorv1.OperatorCondition{Type: MissingStaticPodDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:     "within the timeout",
+			pods:     []*corev1.Pod{installer("installer-5-master-0", "master-0", corev1.PodSucceeded, now.Add(-4*time.Minute)), staticPod("master-0", "4")},
+			expected: operatorv1.OperatorCondition{Type: MissingStaticPodDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name:     "static pod showed up",
+			pods:     []*corev1.Pod{installer("installer-5-master-0", "master-0", corev1.PodSucceeded, now.Add(-10*time.Minute)), staticPod("master-0", "5")},
+			expected: operatorv1.OperatorCondition{Type: MissingStaticPodDegradedConditionType, Status: operatorv1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			name: "old revision after the timeout",
+			pods: []*corev1.Pod{
+				installer("installer-5-master-0", "master-0", corev1.PodFailed, now.Add(-20*time.Minute)),
+				installer("installer-5-retry-1-master-0", "master-0", corev1.PodSucceeded, now.Add(-6*time.Minute)),
+				staticPod("master-0", "4"),
+			},
+			expected: operatorv1.OperatorCondition{
+				Type:    MissingStaticPodDegradedConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  MissingStaticPodReason,
+				Message: "the kubelet did not start the static pod within 5m0s:\n" + `node "master-0": the static pod of revision 5 did not show up 6m0s after its installer finished, found revision 4`,
+			},
+		},
+		{
+			name: "missing after the timeout",
+			pods: []*corev1.Pod{installer("installer-5-master-0", "master-0", corev1.PodSucceeded, now.Add(-6*time.Minute))},
+			expected: operatorv1.OperatorCondition{
+				Type:    MissingStaticPodDegradedConditionType,
+				Status:  operatorv1.ConditionTrue,
+				Reason:  MissingStaticPodReason,
+				Message: "the kubelet did not start the static pod within 5m0s:\n" + `node "master-0": the static pod of revision 5 did not show up 6m0s after its installer finished, no static pod found`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, pod := range test.pods {
+				if err := indexer.Add(pod); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &MissingStaticPodController{podLister: corev1listers.NewPodLister(indexer), now: func() time.Time { return now }}
+
+			cond, missing, err := c.newMissingStaticPodCondition(status, 5*time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expected, cond); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+			if expectMissing := test.expected.Status == operatorv1.ConditionTrue; expectMissing != (len(missing) == 1 && missing[0].NodeName == "master-0") {
+				t.Errorf("unexpected missing nodes %v", missing)
+			}
+		})
+	}
+}
+
+func TestTimeoutsFromSpec(t *testing.T) {
+	tests := []struct {
+		name           string
+		topology       configv1.TopologyMode
+		observedConfig string
+		expected       detectionTimeouts
+	}{
+		{name: "highly available", topology: configv1.HighlyAvailableTopologyMode, expected: detectionTimeouts{missingPod: 5 * time.Minute, failingPod: 2 * time.Minute}},
+		{name: "single node", topology: configv1.SingleReplicaTopologyMode, expected: detectionTimeouts{missingPod: 2 * time.Minute, failingPod: time.Minute}},
+		{name: "unknown topology", expected: detectionTimeouts{missingPod: 5 * time.Minute, failingPod: 2 * time.Minute}},
+		{
+			name:           "configured",
+			topology:       configv1.SingleReplicaTopologyMode,
+			observedConfig: `{"staticPodDetection":{"missingPodTimeout":"15m"}}`,
+			expected:       detectionTimeouts{missingPod: 15 * time.Minute, failingPod: time.Minute},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := indexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{ControlPlaneTopology: test.topology},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			spec := &operatorv1.OperatorSpec{ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)}}
+
+			timeouts, err := timeoutsFromSpec(spec, configlistersv1.NewInfrastructureLister(indexer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timeouts != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, timeouts)
+			}
+		})
+	}
+}
+
+func TestEnsureManifestRewrite(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	kubeClient := fake.NewSimpleClientset()
+	c := &MissingStaticPodController{
+		podClient:             kubeClient.CoreV1(),
+		podLister:             corev1listers.NewPodLister(indexer),
+		operatorImagePullSpec: "quay.io/openshift/cluster-kube-apiserver-operator",
+	}
+	recorder := events.NewInMemoryRecorder("test")
+
+	if err := c.ensureManifestRewrite(context.TODO(), recorder, "master-0", 5); err != nil {
+		t.Fatal(err)
+	}
+	pod, err := kubeClient.CoreV1().Pods("openshift-kube-apiserver").Get(context.TODO(), "manifest-rewrite-5-master-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Spec.NodeName != "master-0" || !strings.Contains(pod.Spec.Containers[0].Args[0], "kube-apiserver-pod-5/kube-apiserver-pod.yaml") {
+		t.Errorf("unexpected manifest rewrite pod %v", pod.Spec)
+	}
+
+	// the pod of the revision is created once
+	if err := indexer.Add(pod); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient.ClearActions()
+	if err := c.ensureManifestRewrite(context.TODO(), recorder, "master-0", 5); err != nil {
+		t.Fatal(err)
+	}
+	if actions := kubeClient.Actions(); len(actions) > 0 {
+		t.Errorf("expected no further pod, got %v", actions)
+	}
+}
@@ -0,0 +1,88 @@
+package staticpoddetection
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// detectionTimeouts are the timeouts of the static pod detection after the defaults of the topology are applied.
+type detectionTimeouts struct {
+	missingPod time.Duration
+	failingPod time.Duration
+}
+
+// defaultTimeouts are the timeouts per control plane topology. Single node clusters detect failures faster, there is
+// no other kube-apiserver that serves meanwhile.
+var defaultTimeouts = map[configv1.TopologyMode]detectionTimeouts{
+	configv1.HighlyAvailableTopologyMode: {missingPod: 5 * time.Minute, failingPod: 2 * time.Minute},
+	configv1.SingleReplicaTopologyMode:   {missingPod: 2 * time.Minute, failingPod: time.Minute},
+}
+
+// timeoutsFromSpec returns the timeouts of the observed config, unset timeouts default to the ones of the control
+// plane topology of the infrastructure.
+func timeoutsFromSpec(spec *operatorv1.OperatorSpec, infraLister configlistersv1.InfrastructureLister) (detectionTimeouts, error) {
+	timeouts := defaultTimeouts[configv1.HighlyAvailableTopologyMode]
+	infra, err := infraLister.Get("cluster")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return timeouts, err
+	}
+	if infra != nil {
+		if topologyTimeouts, ok := defaultTimeouts[infra.Status.ControlPlaneTopology]; ok {
+			timeouts = topologyTimeouts
+		}
+	}
+
+	detection, err := detectionFromSpec(spec)
+	if err != nil {
+		return timeouts, err
+	}
+	if len(detection.MissingPodTimeout) > 0 {
+		if timeouts.missingPod, err = time.ParseDuration(detection.MissingPodTimeout); err != nil {
+			return timeouts, err
+		}
+	}
+	if len(detection.FailingPodTimeout) > 0 {
+		if timeouts.failingPod, err = time.ParseDuration(detection.FailingPodTimeout); err != nil {
+			return timeouts, err
+		}
+	}
+	return timeouts, nil
+}
+
+// MissingPodTimeout returns how long the kubelet may take to start a new static pod after its installer finished.
+func MissingPodTimeout(spec *operatorv1.OperatorSpec, infraLister configlistersv1.InfrastructureLister) (time.Duration, error) {
+	timeouts, err := timeoutsFromSpec(spec, infraLister)
+	return timeouts.missingPod, err
+}
+
+func detectionFromSpec(spec *operatorv1.OperatorSpec) (operatorconfig.StaticPodDetectionConfig, error) {
+	detection := operatorconfig.StaticPodDetectionConfig{}
+	if len(spec.ObservedConfig.Raw) == 0 {
+		return detection, nil
+	}
+	observedConfig := map[string]interface{}{}
+	if err := json.Unmarshal(spec.ObservedConfig.Raw, &observedConfig); err != nil {
+		return detection, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
+	}
+	observedDetection, found, err := unstructured.NestedMap(observedConfig, "staticPodDetection")
+	if err != nil || !found {
+		return detection, err
+	}
+	raw, err := json.Marshal(observedDetection)
+	if err != nil {
+		return detection, err
+	}
+	if err := json.Unmarshal(raw, &detection); err != nil {
+		return detection, fmt.Errorf("incorrect value of staticPodDetection in the observed config: %v", err)
+	}
+	return detection, nil
+}
@@ -0,0 +1,49 @@
+package syncmetrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	registerMetrics sync.Once
+
+	lastSuccessfulSyncGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_controller_last_successful_sync_timestamp_seconds",
+		Help: "Report the last time the sync of a controller of the operator succeeded.",
+	}, []string{"controller"})
+
+	syncErrorsCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_operator_controller_sync_errors_total",
+		Help: "Report the number of failed syncs of a controller of the operator.",
+	}, []string{"controller"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(lastSuccessfulSyncGauge)
+		legacyregistry.MustRegister(syncErrorsCounter)
+	})
+}
+
+// Instrument returns a sync that records the last success and the errors of sync under the name of the controller.
+// The name has to be the one given to ToController, the workqueue metrics of the controller carry it in the name
+// label. Requeues are no errors.
+func Instrument(controllerName string, sync factory.SyncFunc) factory.SyncFunc {
+	return func(ctx context.Context, syncCtx factory.SyncContext) error {
+		err := sync(ctx, syncCtx)
+		switch {
+		case err == nil:
+			lastSuccessfulSyncGauge.WithLabelValues(controllerName).Set(float64(time.Now().Unix()))
+		case !errors.Is(err, factory.SyntheticRequeueError):
+			syncErrorsCounter.WithLabelValues(controllerName).Inc()
+		}
+		return err
+	}
+}
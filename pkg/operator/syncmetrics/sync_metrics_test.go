@@ -0,0 +1,38 @@
+package syncmetrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestInstrument(t *testing.T) {
+	RegisterMetrics()
+
+	var syncErr error
+	sync := Instrument("TestController", func(context.Context, factory.SyncContext) error { return syncErr })
+
+	for _, err := range []error{fmt.Errorf("conflict"), factory.SyntheticRequeueError, fmt.Errorf("conflict")} {
+		syncErr = err
+		if err := sync(context.TODO(), nil); err != syncErr {
+			t.Fatalf("expected the error of the sync, got %v", err)
+		}
+	}
+	if errs, err := testutil.GetCounterMetricValue(syncErrorsCounter.WithLabelValues("TestController")); err != nil || errs != 2 {
+		t.Errorf("expected 2 errors without the requeue, got %v, %v", errs, err)
+	}
+	if last, err := testutil.GetGaugeMetricValue(lastSuccessfulSyncGauge.WithLabelValues("TestController")); err != nil || last != 0 {
+		t.Errorf("expected no successful sync, got %v, %v", last, err)
+	}
+
+	syncErr = nil
+	if err := sync(context.TODO(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := testutil.GetGaugeMetricValue(lastSuccessfulSyncGauge.WithLabelValues("TestController")); err != nil || last == 0 {
+		t.Errorf("expected the successful sync to be recorded, got %v, %v", last, err)
+	}
+}
@@ -0,0 +1,135 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	forceRedeploymentReasonKey = "forceRedeploymentReason"
+
+	// the annotations of the revision-status configmap of a forced revision
+	forceRedeploymentReasonAnnotation          = "kubeapiserver.operator.openshift.io/force-redeployment-reason"
+	coalescedForceRedeploymentReasonAnnotation = "kubeapiserver.operator.openshift.io/coalesced-force-redeployment-reason"
+)
+
+// coalesceForcedRedeployment keeps the forceRedeploymentReason of the latest revision in the required pod configmap
+// when a new reason would create a revision identical to it. Automation that sets a new reason over and over would
+// otherwise create a revision and restart every node each time. The reason of a forced revision and the last reason
+// that was coalesced into it are recorded on its revision-status configmap.
+func coalesceForcedRedeployment(ctx context.Context, lister corev1listers.ConfigMapLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, required *corev1.ConfigMap, status *operatorv1.StaticPodOperatorStatus) error {
+	if status == nil || status.LatestAvailableRevision == 0 {
+		return nil
+	}
+	revision := status.LatestAvailableRevision
+	latest, err := revisionConfigMap(lister, fmt.Sprintf("kube-apiserver-pod-%d", revision))
+	if err != nil || latest == nil {
+		return err
+	}
+	previous, err := revisionConfigMap(lister, fmt.Sprintf("kube-apiserver-pod-%d", revision-1))
+	if err != nil || previous == nil {
+		return err
+	}
+	revisionStatus, err := revisionConfigMap(lister, fmt.Sprintf("revision-status-%d", revision))
+	if err != nil || revisionStatus == nil {
+		return err
+	}
+
+	forcedReason, forced := forcedRedeploymentReason(latest.Data, previous.Data)
+	if !forced {
+		return nil
+	}
+	annotations := map[string]string{forceRedeploymentReasonAnnotation: forcedReason}
+	requested := required.Data[forceRedeploymentReasonKey]
+	if coalesce(required.Data, latest.Data, revisionStatus.Annotations[coalescedForceRedeploymentReasonAnnotation], rolledOut(status)) {
+		required.Data[forceRedeploymentReasonKey] = forcedReason
+		annotations[coalescedForceRedeploymentReasonAnnotation] = requested
+		if revisionStatus.Annotations[coalescedForceRedeploymentReasonAnnotation] != requested {
+			recorder.Eventf("ForcedRedeploymentCoalesced", "forced redeployment %q is coalesced into revision %d, which was forced by %q and has the same content", requested, revision, forcedReason)
+		}
+	}
+	return annotateRevisionStatus(ctx, client, revisionStatus, annotations)
+}
+
+// forcedRedeploymentReason returns the reason of the latest revision if it differs from the previous revision only in
+// the forceRedeploymentReason.
+func forcedRedeploymentReason(latest, previous map[string]string) (string, bool) {
+	if latest[forceRedeploymentReasonKey] == previous[forceRedeploymentReasonKey] {
+		return "", false
+	}
+	return latest[forceRedeploymentReasonKey], equalExceptForceRedeploymentReason(latest, previous)
+}
+
+// coalesce returns whether the requested reason is coalesced into the forced latest revision. The content has to be
+// the same, and either the latest revision is still rolling out, restarting the nodes anyway, or the reason was
+// coalesced into it before and must not create a revision once the rollout is done.
+func coalesce(required, latest map[string]string, coalescedReason string, rolledOut bool) bool {
+	requested := required[forceRedeploymentReasonKey]
+	if requested == latest[forceRedeploymentReasonKey] || !equalExceptForceRedeploymentReason(required, latest) {
+		return false
+	}
+	return !rolledOut || requested == coalescedReason
+}
+
+func equalExceptForceRedeploymentReason(a, b map[string]string) bool {
+	a, b = withoutForceRedeploymentReason(a), withoutForceRedeploymentReason(b)
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+func withoutForceRedeploymentReason(data map[string]string) map[string]string {
+	ret := map[string]string{}
+	for k, v := range data {
+		if k != forceRedeploymentReasonKey {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// rolledOut returns whether all nodes run the latest revision.
+func rolledOut(status *operatorv1.StaticPodOperatorStatus) bool {
+	for _, nodeStatus := range status.NodeStatuses {
+		if nodeStatus.CurrentRevision < status.LatestAvailableRevision {
+			return false
+		}
+	}
+	return true
+}
+
+func revisionConfigMap(lister corev1listers.ConfigMapLister, name string) (*corev1.ConfigMap, error) {
+	configMap, err := lister.ConfigMaps(operatorclient.TargetNamespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
+}
+
+func annotateRevisionStatus(ctx context.Context, client coreclientv1.ConfigMapsGetter, revisionStatus *corev1.ConfigMap, annotations map[string]string) error {
+	modified := false
+	updated := revisionStatus.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		if updated.Annotations[k] != v {
+			updated.Annotations[k] = v
+			modified = true
+		}
+	}
+	if !modified {
+		return nil
+	}
+	_, err := client.ConfigMaps(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
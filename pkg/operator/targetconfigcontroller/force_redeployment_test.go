@@ -0,0 +1,88 @@
+package targetconfigcontroller
+
+import (
+	"testing"
+)
+
+func TestForcedRedeploymentReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		latest         map[string]string
+		previous       map[string]string
+		expectedReason string
+		expectedForced bool
+	}{
+		{
+			name:     "same reason",
+			latest:   map[string]string{"pod.yaml": "b", "forceRedeploymentReason": "a"},
+			previous: map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "a"},
+		},
+		{
+			name:     "content and reason changed",
+			latest:   map[string]string{"pod.yaml": "b", "forceRedeploymentReason": "b"},
+			previous: map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "a"},
+		},
+		{
+			name:           "only the reason changed",
+			latest:         map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "b"},
+			previous:       map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "a"},
+			expectedReason: "b",
+			expectedForced: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, forced := forcedRedeploymentReason(test.latest, test.previous)
+			if forced != test.expectedForced || (forced && reason != test.expectedReason) {
+				t.Errorf("expected %q %v, got %q %v", test.expectedReason, test.expectedForced, reason, forced)
+			}
+		})
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	latest := map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "force-1"}
+
+	tests := []struct {
+		name            string
+		required        map[string]string
+		coalescedReason string
+		rolledOut       bool
+		expected        bool
+	}{
+		{
+			name:     "same reason",
+			required: map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "force-1"},
+		},
+		{
+			name:     "changed content",
+			required: map[string]string{"pod.yaml": "b", "forceRedeploymentReason": "force-2"},
+		},
+		{
+			name:     "new reason while rolling out",
+			required: map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "force-2"},
+			expected: true,
+		},
+		{
+			name:      "new reason after the rollout",
+			required:  map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "force-2"},
+			rolledOut: true,
+		},
+		{
+			name:            "coalesced reason after the rollout",
+			required:        map[string]string{"pod.yaml": "a", "forceRedeploymentReason": "force-2"},
+			coalescedReason: "force-2",
+			rolledOut:       true,
+			expected:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := coalesce(test.required, latest, test.coalescedReason, test.rolledOut); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@ import (
 	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/fips"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -31,10 +35,12 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/pointer"
 )
 
 type TargetConfigController struct {
@@ -45,6 +51,7 @@ type TargetConfigController struct {
 
 	kubeClient      kubernetes.Interface
 	configMapLister corev1listers.ConfigMapLister
+	secretLister    corev1listers.SecretLister
 
 	isStartupMonitorEnabledFn func() (bool, error)
 }
@@ -64,6 +71,7 @@ func NewTargetConfigController(
 		operatorClient:            operatorClient,
 		kubeClient:                kubeClient,
 		configMapLister:           kubeInformersForNamespaces.ConfigMapLister(),
+		secretLister:              kubeInformersForOpenshiftKubeAPIServerNamespace.Core().V1().Secrets().Lister(),
 		isStartupMonitorEnabledFn: isStartupMonitorEnabledFn,
 	}
 
@@ -76,11 +84,14 @@ func NewTargetConfigController(
 		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
-	).WithSync(c.sync).ResyncEvery(time.Minute).ToController("TargetConfigController", eventRecorder.WithComponentSuffix("target-config-controller"))
+	).WithBareInformers(
+		// the install-config of the cluster-config-v1 configmap tells whether the cluster is in FIPS mode
+		kubeInformersForNamespaces.InformersFor("kube-system").Core().V1().ConfigMaps().Informer(),
+	).WithSync(syncmetrics.Instrument("TargetConfigController", c.sync)).ResyncEvery(resyncinterval.For("TargetConfigController", time.Minute)).ToController("TargetConfigController", eventRecorder.WithComponentSuffix("target-config-controller"))
 }
 
 func (c TargetConfigController) sync(ctx context.Context, syncContext factory.SyncContext) error {
-	operatorSpec, _, _, err := c.operatorClient.GetStaticPodOperatorState()
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
 	if err != nil {
 		return err
 	}
@@ -103,7 +114,7 @@ func (c TargetConfigController) sync(ctx context.Context, syncContext factory.Sy
 		return err
 	}
 
-	requeue, err := createTargetConfig(ctx, c, syncContext.Recorder(), operatorSpec)
+	requeue, err := createTargetConfig(ctx, c, syncContext.Recorder(), operatorSpec, operatorStatus)
 	if err != nil {
 		return err
 	}
@@ -152,14 +163,28 @@ func isRequiredConfigPresent(config []byte) error {
 
 // createTargetConfig takes care of creation of valid resources in a fixed name.  These are inputs to other control loops.
 // returns whether or not requeue and if an error happened when updating status.  Normally it updates status itself.
-func createTargetConfig(ctx context.Context, c TargetConfigController, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (bool, error) {
+func createTargetConfig(ctx context.Context, c TargetConfigController, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, operatorStatus *operatorv1.StaticPodOperatorStatus) (bool, error) {
 	errors := []error{}
 
-	_, _, err := manageKubeAPIServerConfig(ctx, c.kubeClient.CoreV1(), recorder, operatorSpec)
+	fipsEnabled, err := fips.Enabled(c.configMapLister)
+	if err != nil {
+		errors = append(errors, err)
+	}
+	if fipsEnabled {
+		keyErrs, err := fips.ValidateSecretKeys(c.secretLister, operatorclient.TargetNamespace)
+		if err != nil {
+			errors = append(errors, err)
+		}
+		for _, keyErr := range keyErrs {
+			errors = append(errors, fmt.Errorf("FIPS: %v", keyErr))
+		}
+	}
+
+	_, _, err = manageKubeAPIServerConfig(ctx, c.kubeClient.CoreV1(), recorder, operatorSpec, fipsEnabled)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/config", err))
 	}
-	_, _, err = managePods(ctx, c.kubeClient.CoreV1(), c.isStartupMonitorEnabledFn, recorder, operatorSpec, c.targetImagePullSpec, c.operatorImagePullSpec)
+	_, _, err = managePods(ctx, c.kubeClient.CoreV1(), c.configMapLister, c.isStartupMonitorEnabledFn, recorder, operatorSpec, operatorStatus, c.targetImagePullSpec, c.operatorImagePullSpec)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/kube-apiserver-pod", err))
 	}
@@ -206,7 +231,9 @@ func createTargetConfig(ctx context.Context, c TargetConfigController, recorder
 	return false, nil
 }
 
-func manageKubeAPIServerConfig(ctx context.Context, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
+// manageKubeAPIServerConfig applies the config of the kube-apiserver. In FIPS mode a config whose TLS settings do not
+// work in FIPS mode is not applied, so that it is not rolled out to crash the kube-apiservers.
+func manageKubeAPIServerConfig(ctx context.Context, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, fipsEnabled bool) (*corev1.ConfigMap, bool, error) {
 	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-apiserver/cm.yaml"))
 	defaultConfig := bindata.MustAsset("assets/config/defaultconfig.yaml")
 	configOverrides := bindata.MustAsset("assets/config/config-overrides.yaml")
@@ -225,46 +252,82 @@ func manageKubeAPIServerConfig(ctx context.Context, client coreclientv1.ConfigMa
 	if err != nil {
 		return nil, false, err
 	}
+	if fipsEnabled {
+		config := &kubecontrolplanev1.KubeAPIServerConfig{}
+		if err := yaml.Unmarshal([]byte(requiredConfigMap.Data["config.yaml"]), config); err != nil {
+			return nil, false, err
+		}
+		if errs := fips.ValidateServingInfo(config.ServingInfo.ServingInfo); len(errs) > 0 {
+			return nil, false, fmt.Errorf("FIPS: %v", utilerrors.NewAggregate(errs))
+		}
+	}
 	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
 }
 
-func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, isStartupMonitorEnabledFn func() (bool, error), recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, imagePullSpec, operatorImagePullSpec string) (*corev1.ConfigMap, bool, error) {
-	appliedPodTemplate, err := manageTemplate(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), imagePullSpec, operatorImagePullSpec, operatorSpec)
+func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, lister corev1listers.ConfigMapLister, isStartupMonitorEnabledFn func() (bool, error), recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, operatorStatus *operatorv1.StaticPodOperatorStatus, imagePullSpec, operatorImagePullSpec string) (*corev1.ConfigMap, bool, error) {
+	// the unsupported config overrides apply to the knobs of the pod like to the kube-apiserver config
+	config, err := podConfigFromSpec(operatorSpec)
 	if err != nil {
 		return nil, false, err
 	}
-	required := resourceread.ReadPodV1OrDie([]byte(appliedPodTemplate))
 
-	var observedConfig map[string]interface{}
-	if err := yaml.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
-		return nil, false, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
+	// the tech preview operand image override of the operator config replaces the release image
+	if len(config.OperandImage) > 0 {
+		imagePullSpec = config.OperandImage
 	}
-	proxyConfig, _, err := unstructured.NestedStringMap(observedConfig, "targetconfigcontroller", "proxy")
+
+	appliedPodTemplate, err := manageTemplate(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), imagePullSpec, operatorImagePullSpec, operatorSpec.LogLevel, config)
 	if err != nil {
-		return nil, false, fmt.Errorf("couldn't get the proxy config from observedConfig: %v", err)
+		return nil, false, err
 	}
+	required := resourceread.ReadPodV1OrDie([]byte(appliedPodTemplate))
+
+	// the sidecars of the operator config are left alone, they may have to run as root
+	applyNonRoot(required, config.NonRoot)
 
-	proxyEnvVars := proxyMapToEnvVars(proxyConfig)
+	proxyEnvVars := mapToEnvVars(config.TargetConfigController.Proxy)
 	for i, container := range required.Spec.Containers {
 		required.Spec.Containers[i].Env = append(container.Env, proxyEnvVars...)
 	}
 
+	environmentEnvVars := mapToEnvVars(config.Environment)
+	for i, container := range required.Spec.Containers {
+		if container.Name == "kube-apiserver" {
+			required.Spec.Containers[i].Env = append(container.Env, environmentEnvVars...)
+		}
+	}
+
+	required.Spec.Containers = append(required.Spec.Containers, config.Sidecars.Containers...)
+	required.Spec.Volumes = append(required.Spec.Volumes, config.Sidecars.Volumes...)
+	applyHostPathMounts(required, config.HostPathMounts)
+	applySecurityContext(required, &config.SecurityContext)
+	if err := applyResources(required, config.Resources); err != nil {
+		return nil, false, err
+	}
+	applyProbes(required, &config.Probes)
+	if err := applyReservedCPUs(required, config.ReservedCPUs); err != nil {
+		return nil, false, err
+	}
+
 	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-apiserver/pod-cm.yaml"))
 	configMap.Data["pod.yaml"] = resourceread.WritePodV1OrDie(required)
-	configMap.Data["forceRedeploymentReason"] = operatorSpec.ForceRedeploymentReason
+	configMap.Data[forceRedeploymentReasonKey] = operatorSpec.ForceRedeploymentReason
 	configMap.Data["version"] = version.Get().String()
 
-	startupMonitorPodKey, optionalStartupMonitor, err := generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn, operatorSpec, operatorImagePullSpec)
+	startupMonitorPodKey, optionalStartupMonitor, err := generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn, operatorSpec, operatorImagePullSpec, &config.StartupMonitor)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to apply an optional pod due to %v", err)
 	}
 	if optionalStartupMonitor != nil {
 		configMap.Data[startupMonitorPodKey] = resourceread.WritePodV1OrDie(optionalStartupMonitor)
 	}
+	if err := coalesceForcedRedeployment(ctx, lister, client, recorder, configMap, operatorStatus); err != nil {
+		return nil, false, err
+	}
 	return resourceapply.ApplyConfigMap(ctx, client, recorder, configMap)
 }
 
-func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, error), operatorSpec *operatorv1.StaticPodOperatorSpec, operatorImagePullSpec string) (string, *corev1.Pod, error) {
+func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, error), operatorSpec *operatorv1.StaticPodOperatorSpec, operatorImagePullSpec string, startupMonitor *operatorconfig.StartupMonitorConfig) (string, *corev1.Pod, error) {
 	if enabled, err := isStartupMonitorEnabledFn(); err != nil {
 		return "", nil, err
 	} else if !enabled {
@@ -276,9 +339,34 @@ func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, e
 		return "", nil, err
 	}
 	required := resourceread.ReadPodV1OrDie([]byte(generatedStartupMonitorPodTemplate))
+	applyStartupMonitor(required, startupMonitor)
 	return "kube-apiserver-startup-monitor-pod.yaml", required, nil
 }
 
+// applyStartupMonitor passes the fallback timeout, the readyz checks and the crash loop threshold of the operator config to the startup monitor
+// container. The generated template hardcodes the default fallback timeout.
+func applyStartupMonitor(pod *corev1.Pod, startupMonitor *operatorconfig.StartupMonitorConfig) {
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if len(startupMonitor.FallbackTimeout) > 0 {
+			for j, arg := range container.Args {
+				if strings.HasPrefix(arg, "--fallback-timeout-duration=") {
+					container.Args[j] = fmt.Sprintf("--fallback-timeout-duration=%s", startupMonitor.FallbackTimeout)
+				}
+			}
+		}
+		if startupMonitor.ReadyzSuccessThreshold != nil {
+			container.Args = append(container.Args, fmt.Sprintf("--readyz-success-threshold=%d", *startupMonitor.ReadyzSuccessThreshold))
+		}
+		if len(startupMonitor.ReadyzInterval) > 0 {
+			container.Args = append(container.Args, fmt.Sprintf("--readyz-interval=%s", startupMonitor.ReadyzInterval))
+		}
+		if startupMonitor.CrashLoopThreshold != nil {
+			container.Args = append(container.Args, fmt.Sprintf("--crash-loop-threshold=%d", *startupMonitor.CrashLoopThreshold))
+		}
+	}
+}
+
 func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
 	requiredConfigMap, err := resourcesynccontroller.CombineCABundleConfigMaps(
 		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: "client-ca"},
@@ -397,13 +485,13 @@ func ensureLocalhostRecoverySAToken(ctx context.Context, client coreclientv1.Cor
 	return err
 }
 
-func proxyMapToEnvVars(proxyConfig map[string]string) []corev1.EnvVar {
-	if proxyConfig == nil {
+func mapToEnvVars(vars map[string]string) []corev1.EnvVar {
+	if vars == nil {
 		return nil
 	}
 
 	envVars := []corev1.EnvVar{}
-	for k, v := range proxyConfig {
+	for k, v := range vars {
 		envVars = append(envVars, corev1.EnvVar{Name: k, Value: v})
 	}
 
@@ -412,58 +500,340 @@ func proxyMapToEnvVars(proxyConfig map[string]string) []corev1.EnvVar {
 	return envVars
 }
 
-func gracefulTerminationDurationFromConfig(operatorSpec *operatorv1.StaticPodOperatorSpec) (int, error) {
-	var gracefulTerminationDurationPath = []string{"gracefulTerminationDuration"}
+// podConfig holds the knobs of the static pod in the observed config. It is decoded once from the observed config with
+// the unsupported config overrides applied on top of it, so that an override applies to every knob alike.
+type podConfig struct {
+	APIServerArguments struct {
+		AnonymousAuth         []string `json:"anonymous-auth"`
+		Profiling             []string `json:"profiling"`
+		ShutdownDelayDuration []string `json:"shutdown-delay-duration"`
+	} `json:"apiServerArguments"`
+	TargetConfigController struct {
+		Proxy map[string]string `json:"proxy"`
+	} `json:"targetconfigcontroller"`
+
+	OperandImage                string                                 `json:"operandImage"`
+	Environment                 map[string]string                      `json:"environment"`
+	GracefulTerminationDuration string                                 `json:"gracefulTerminationDuration"`
+	Logging                     map[string]string                      `json:"logging"`
+	AdvertiseAddressSubnets     []string                               `json:"advertiseAddressSubnets"`
+	InsecureReadyz              operatorconfig.InsecureReadyzConfig    `json:"insecureReadyz"`
+	AuditForwarder              *operatorconfig.AuditForwarderConfig   `json:"auditForwarder"`
+	NonRoot                     *operatorconfig.NonRootConfig          `json:"nonRoot"`
+	SecurityContext             operatorconfig.SecurityContextConfig   `json:"securityContext"`
+	Sidecars                    operatorconfig.SidecarConfig           `json:"sidecars"`
+	HostPathMounts              []operatorconfig.HostPathMount         `json:"hostPathMounts"`
+	Resources                   map[string]corev1.ResourceRequirements `json:"resources"`
+	Probes                      operatorconfig.ProbesConfig            `json:"probes"`
+	ReservedCPUs                string                                 `json:"reservedCPUs"`
+	StartupMonitor              operatorconfig.StartupMonitorConfig    `json:"startupMonitor"`
+}
 
-	mergedConfigs, err := resourcemerge.MergeProcessConfig(map[string]resourcemerge.MergeFunc{}, operatorSpec.ObservedConfig.Raw, operatorSpec.UnsupportedConfigOverrides.Raw)
+// podConfigFromSpec returns the knobs of the static pod of the observed config merged with the unsupported config
+// overrides.
+func podConfigFromSpec(operatorSpec *operatorv1.StaticPodOperatorSpec) (*podConfig, error) {
+	mergedConfig, err := resourcemerge.MergeProcessConfig(map[string]resourcemerge.MergeFunc{}, operatorSpec.ObservedConfig.Raw, operatorSpec.UnsupportedConfigOverrides.Raw)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	config := &podConfig{}
+	if len(mergedConfig) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(mergedConfig, config); err != nil {
+		return nil, fmt.Errorf("incorrect value in the observed config: %v", err)
+	}
+	return config, nil
+}
 
-	// read the watch termination from the observed configuration
-	observedConfig := map[string]interface{}{}
-	if err := json.NewDecoder(bytes.NewBuffer(mergedConfigs)).Decode(&observedConfig); err != nil {
-		return 0, err
+// gracefulTerminationDuration returns the graceful termination duration in seconds, zero if it is not set.
+func (c *podConfig) gracefulTerminationDuration() (int, error) {
+	if len(c.GracefulTerminationDuration) == 0 {
+		return 0, nil
 	}
-	observedGracefulTerminationDurationStr, _, err := unstructured.NestedString(observedConfig, gracefulTerminationDurationPath...)
+	gracefulTerminationDuration, err := strconv.Atoi(c.GracefulTerminationDuration)
 	if err != nil {
-		return 0, fmt.Errorf("unable to extract gracefulTerminationDuration from the observed config: %v, path = %v", err, gracefulTerminationDurationPath)
+		return 0, fmt.Errorf("incorrect value of watchTerminationDuration field in the observed config: %v", err)
 	}
-	if len(observedGracefulTerminationDurationStr) == 0 {
+	return gracefulTerminationDuration, nil
+}
+
+// shutdownDelayDuration returns the shutdown-delay-duration of the kube-apiserver, zero if it is not set.
+func (c *podConfig) shutdownDelayDuration() (time.Duration, error) {
+	if len(c.APIServerArguments.ShutdownDelayDuration) == 0 {
 		return 0, nil
 	}
-	observedGracefulTerminationDuration, err := strconv.Atoi(observedGracefulTerminationDurationStr)
+	shutdownDelayDuration, err := time.ParseDuration(c.APIServerArguments.ShutdownDelayDuration[0])
 	if err != nil {
-		return 0, fmt.Errorf("incorrect value of watchTerminationDuration field in the observed config: %v", err)
+		return 0, fmt.Errorf("incorrect value of shutdown-delay-duration in the observed config: %v", err)
+	}
+	return shutdownDelayDuration, nil
+}
+
+// anonymousAuthDisabled tells whether the kube-apiserver rejects anonymous requests, in which case the kubelet probes
+// have to go through the insecure-readyz sidecar that authenticates itself.
+func (c *podConfig) anonymousAuthDisabled() bool {
+	return len(c.APIServerArguments.AnonymousAuth) == 1 && c.APIServerArguments.AnonymousAuth[0] == "false"
+}
+
+// profilingDisabled tells whether the kube-apiserver does not serve the debug endpoints, in which case the
+// check-endpoints sidecar does not serve them either.
+func (c *podConfig) profilingDisabled() bool {
+	return len(c.APIServerArguments.Profiling) == 1 && c.APIServerArguments.Profiling[0] == "false"
+}
+
+// logLevelToVerbosity maps an operator log level to the klog verbosity, unknown levels map to Normal.
+func logLevelToVerbosity(logLevel operatorv1.LogLevel) int {
+	switch logLevel {
+	case operatorv1.Debug:
+		return 4
+	case operatorv1.Trace:
+		return 6
+	case operatorv1.TraceAll:
+		return 8
+	default:
+		return 2
+	}
+}
+
+// sidecarVerbosity returns the verbosity of a sidecar container, 0 if no log level was configured for it.
+func sidecarVerbosity(logLevel string) int {
+	if len(logLevel) == 0 {
+		return 0
+	}
+	return logLevelToVerbosity(operatorv1.LogLevel(logLevel))
+}
+
+// applyResources sets the given requests and limits on the containers of the pod, resources that are not given keep
+// the values of the pod template. It fails when a limit ends up below the request of the same resource.
+func applyResources(pod *corev1.Pod, resources map[string]corev1.ResourceRequirements) error {
+	apply := func(container *corev1.Container) error {
+		requirements, ok := resources[container.Name]
+		if !ok {
+			return nil
+		}
+		for name, quantity := range requirements.Requests {
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = quantity
+		}
+		for name, quantity := range requirements.Limits {
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = corev1.ResourceList{}
+			}
+			container.Resources.Limits[name] = quantity
+		}
+		for name, limit := range container.Resources.Limits {
+			if request, ok := container.Resources.Requests[name]; ok && limit.Cmp(request) < 0 {
+				return fmt.Errorf("the %s limit %s of container %q is below its request %s", name, limit.String(), container.Name, request.String())
+			}
+		}
+		return nil
+	}
+
+	for i := range pod.Spec.InitContainers {
+		if err := apply(&pod.Spec.InitContainers[i]); err != nil {
+			return err
+		}
 	}
+	for i := range pod.Spec.Containers {
+		if err := apply(&pod.Spec.Containers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyHostPathMounts adds a hostPath volume per mount to the static pod and mounts it read-only into the
+// kube-apiserver container.
+func applyHostPathMounts(pod *corev1.Pod, mounts []operatorconfig.HostPathMount) {
+	for _, mount := range mounts {
+		hostPathType := mount.Type
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: mount.Name,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: mount.HostPath, Type: &hostPathType},
+			},
+		})
+		for i := range pod.Spec.Containers {
+			if pod.Spec.Containers[i].Name != "kube-apiserver" {
+				continue
+			}
+			pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      mount.Name,
+				MountPath: mount.MountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+}
+
+// applyProbes sets the probe timings on the kube-apiserver container. The startup probe checks the same endpoint as
+// the liveness probe.
+func applyProbes(pod *corev1.Pod, probes *operatorconfig.ProbesConfig) {
+	apply := func(probe *corev1.Probe, config *operatorconfig.ProbeConfig) {
+		if config.InitialDelaySeconds != nil {
+			probe.InitialDelaySeconds = *config.InitialDelaySeconds
+		}
+		if config.PeriodSeconds != nil {
+			probe.PeriodSeconds = *config.PeriodSeconds
+		}
+		if config.TimeoutSeconds != nil {
+			probe.TimeoutSeconds = *config.TimeoutSeconds
+		}
+		if config.FailureThreshold != nil {
+			probe.FailureThreshold = *config.FailureThreshold
+		}
+	}
+
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if container.Name != "kube-apiserver" {
+			continue
+		}
+		if probes.Startup != nil && container.LivenessProbe != nil {
+			container.StartupProbe = &corev1.Probe{Handler: *container.LivenessProbe.Handler.DeepCopy()}
+			apply(container.StartupProbe, probes.Startup)
+		}
+		if probes.Liveness != nil && container.LivenessProbe != nil {
+			apply(container.LivenessProbe, probes.Liveness)
+		}
+		if probes.Readiness != nil && container.ReadinessProbe != nil {
+			apply(container.ReadinessProbe, probes.Readiness)
+		}
+	}
+}
 
-	return observedGracefulTerminationDuration, nil
+// applyNonRoot runs the containers of the pod as the non-root user. The privileged setup init container hands the
+// files over to the user, it keeps running as root.
+func applyNonRoot(pod *corev1.Pod, nonRoot *operatorconfig.NonRootConfig) {
+	if nonRoot == nil {
+		return
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].SecurityContext = &corev1.SecurityContext{
+			RunAsUser:                pointer.Int64(nonRoot.UID),
+			RunAsGroup:               pointer.Int64(nonRoot.UID),
+			RunAsNonRoot:             pointer.Bool(true),
+			AllowPrivilegeEscalation: pointer.Bool(false),
+		}
+	}
+}
+
+// applySecurityContext sets the seccomp profile and SELinux options on the pod, they apply to every container that
+// does not set its own.
+func applySecurityContext(pod *corev1.Pod, securityContext *operatorconfig.SecurityContextConfig) {
+	if securityContext.SeccompProfile == nil && securityContext.SELinuxOptions == nil {
+		return
+	}
+	if pod.Spec.SecurityContext == nil {
+		pod.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	pod.Spec.SecurityContext.SeccompProfile = securityContext.SeccompProfile
+	pod.Spec.SecurityContext.SELinuxOptions = securityContext.SELinuxOptions
+}
+
+// workloadResourcesAnnotationPrefix is the prefix of the per container annotations CRI-O reads the cpu shares and
+// cpuset of pods with the target.workload.openshift.io/management annotation from.
+const workloadResourcesAnnotationPrefix = "resources.workload.openshift.io/"
+
+type workloadResources struct {
+	CPUShares uint64 `json:"cpushares"`
+	CPUSet    string `json:"cpuset"`
+}
+
+// applyReservedCPUs pins the containers of the pod to the reserved cpus of a performance profile. The cpu requests
+// are converted to cpu shares the same way the kubelet does for workload partitioning.
+func applyReservedCPUs(pod *corev1.Pod, reservedCPUs string) error {
+	if len(reservedCPUs) == 0 {
+		return nil
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		// 1024 shares per cpu, 2 is the minimum the kernel accepts
+		shares := uint64(container.Resources.Requests.Cpu().MilliValue()) * 1024 / 1000
+		if shares < 2 {
+			shares = 2
+		}
+		value, err := json.Marshal(workloadResources{CPUShares: shares, CPUSet: reservedCPUs})
+		if err != nil {
+			return err
+		}
+		pod.Annotations[workloadResourcesAnnotationPrefix+container.Name] = string(value)
+	}
+	return nil
 }
 
 type kasTemplate struct {
 	Image                         string
 	OperatorImage                 string
 	Verbosity                     string
+	VModule                       string
+	CertSyncerVerbosity           int
+	CheckEndpointsVerbosity       int
+	InsecureReadyzVerbosity       int
 	GracefulTerminationDuration   int
+	PortReleaseWaitDuration       int
 	SetupContainerTimeoutDuration int
+	AnonymousAuthDisabled         bool
+	AdvertiseAddressSubnets       []string
+	NonRootUID                    int64
+	ProfilingDisabled             bool
+	InsecureReadyzPort            int32
+	InsecureReadyzDisabled        bool
+	AuditForwarder                *auditForwarderTemplate
 }
 
-func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullSpec string, operatorSpec *operatorv1.StaticPodOperatorSpec) (string, error) {
-	var verbosity string
-	switch operatorSpec.LogLevel {
-	case operatorv1.Normal:
-		verbosity = fmt.Sprintf(" -v=%d", 2)
-	case operatorv1.Debug:
-		verbosity = fmt.Sprintf(" -v=%d", 4)
-	case operatorv1.Trace:
-		verbosity = fmt.Sprintf(" -v=%d", 6)
-	case operatorv1.TraceAll:
-		verbosity = fmt.Sprintf(" -v=%d", 8)
-	default:
-		verbosity = fmt.Sprintf(" -v=%d", 2)
+// auditForwarderTemplate holds the arguments of the audit forwarder sidecar.
+type auditForwarderTemplate struct {
+	Protocol string
+	Endpoint string
+	TLS      bool
+	CAFile   string
+}
+
+// newAuditForwarderTemplate returns the arguments of the audit forwarder sidecar, nil if there is no audit forwarder.
+func newAuditForwarderTemplate(auditForwarder *operatorconfig.AuditForwarderConfig) *auditForwarderTemplate {
+	if auditForwarder == nil {
+		return nil
+	}
+	tmpl := &auditForwarderTemplate{
+		Protocol: strings.ToLower(string(auditForwarder.Protocol)),
+		Endpoint: auditForwarder.Endpoint,
+		TLS:      auditForwarder.TLS != nil,
+	}
+	if auditForwarder.TLS != nil && len(auditForwarder.TLS.CAConfigMap) > 0 {
+		// the observer syncs the config map into every revision
+		tmpl.CAFile = "/etc/kubernetes/static-pod-resources/configmaps/audit-forwarder-ca/ca-bundle.crt"
+	}
+	return tmpl
+}
+
+func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullSpec string, logLevel operatorv1.LogLevel, config *podConfig) (string, error) {
+	// the kube-apiserver log level of the operator config takes precedence over spec.logLevel
+	if len(config.Logging["kubeAPIServer"]) > 0 {
+		logLevel = operatorv1.LogLevel(config.Logging["kubeAPIServer"])
 	}
+	verbosity := fmt.Sprintf(" -v=%d", logLevelToVerbosity(logLevel))
 
-	gracefulTerminationDuration, err := gracefulTerminationDurationFromConfig(operatorSpec)
+	var vmodule string
+	if len(config.Logging["vmodule"]) > 0 {
+		// quoted, the patterns may contain shell globs
+		vmodule = fmt.Sprintf(" --vmodule='%s'", config.Logging["vmodule"])
+	}
+
+	checkEndpointsVerbosity := sidecarVerbosity(config.Logging["checkEndpoints"])
+	if checkEndpointsVerbosity == 0 {
+		checkEndpointsVerbosity = 2
+	}
+
+	gracefulTerminationDuration, err := config.gracefulTerminationDuration()
 	if err != nil {
 		return "", err
 	}
@@ -477,13 +847,56 @@ func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullS
 		gracefulTerminationDuration = 135
 	}
 
+	// the kubelet kills the kube-apiserver once the termination grace period is over, it must not do so before
+	// the shutdown delay has passed
+	shutdownDelayDuration, err := config.shutdownDelayDuration()
+	if err != nil {
+		return "", err
+	}
+	if time.Duration(gracefulTerminationDuration)*time.Second <= shutdownDelayDuration {
+		return "", fmt.Errorf("graceful termination duration %ds must be longer than the shutdown-delay-duration %s", gracefulTerminationDuration, shutdownDelayDuration)
+	}
+
+	anonymousAuthDisabled := config.anonymousAuthDisabled()
+	if config.InsecureReadyz.Disabled && anonymousAuthDisabled {
+		return "", fmt.Errorf("the insecure-readyz sidecar cannot be disabled while anonymous-auth is false, the kubelet probes go through it")
+	}
+	insecureReadyzPort := int32(6080)
+	if config.InsecureReadyz.Port != nil {
+		insecureReadyzPort = *config.InsecureReadyz.Port
+	}
+
+	var nonRootUID int64
+	if config.NonRoot != nil {
+		nonRootUID = config.NonRoot.UID
+	}
+
+	// in-flight requests cannot outlive the graceful termination of the old kube-apiserver, which is short on
+	// SingleReplica control planes
+	portReleaseWaitDuration := 65
+	if gracefulTerminationDuration < portReleaseWaitDuration {
+		portReleaseWaitDuration = gracefulTerminationDuration
+	}
+
 	tmplVal := kasTemplate{
 		Image:                       imagePullSpec,
 		OperatorImage:               operatorImagePullSpec,
 		Verbosity:                   verbosity,
+		VModule:                     vmodule,
+		CertSyncerVerbosity:         sidecarVerbosity(config.Logging["certSyncer"]),
+		CheckEndpointsVerbosity:     checkEndpointsVerbosity,
+		InsecureReadyzVerbosity:     sidecarVerbosity(config.Logging["insecureReadyz"]),
 		GracefulTerminationDuration: gracefulTerminationDuration,
-		// 80s for minimum-termination-duration (10s port wait, 65s to let pending requests finish after port has been freed) + 5s extra cri-o's graceful termination period
-		SetupContainerTimeoutDuration: gracefulTerminationDuration + 80 + 5,
+		PortReleaseWaitDuration:     portReleaseWaitDuration,
+		// minimum-termination-duration (10s port wait, up to 65s to let pending requests finish after port has been freed, 5s extra) + 5s extra cri-o's graceful termination period
+		SetupContainerTimeoutDuration: gracefulTerminationDuration + 15 + portReleaseWaitDuration + 5,
+		AnonymousAuthDisabled:         anonymousAuthDisabled,
+		AdvertiseAddressSubnets:       config.AdvertiseAddressSubnets,
+		NonRootUID:                    nonRootUID,
+		ProfilingDisabled:             config.profilingDisabled(),
+		InsecureReadyzPort:            insecureReadyzPort,
+		InsecureReadyzDisabled:        config.InsecureReadyz.Disabled,
+		AuditForwarder:                newAuditForwarderTemplate(config.AuditForwarder),
 	}
 	tmpl, err := template.New("kas").Parse(rawTemplate)
 	if err != nil {
@@ -1,13 +1,24 @@
 package targetconfigcontroller
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 )
 
 var codec = scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
@@ -142,13 +153,14 @@ func TestManageTemplate(t *testing.T) {
 		template     string
 		golden       string
 		operatorSpec *operatorv1.StaticPodOperatorSpec
+		expectError  bool
 	}{
 
 		// scenario 1
 		{
 			name:         "happy path: default values are applied",
-			template:     "{{.Image}}, {{.OperatorImage}}, {{.Verbosity}}, {{.GracefulTerminationDuration}}",
-			golden:       "CaptainAmerica, Piper,  -v=2, 135",
+			template:     "{{.Image}}, {{.OperatorImage}}, {{.Verbosity}}, {{.GracefulTerminationDuration}}, {{.PortReleaseWaitDuration}}, {{.SetupContainerTimeoutDuration}}",
+			golden:       "CaptainAmerica, Piper,  -v=2, 135, 65, 220",
 			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{}},
 		},
 
@@ -172,20 +184,126 @@ func TestManageTemplate(t *testing.T) {
 				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(configWithOverriddenWatchTerminationDuration)},
 			}},
 		},
+
+		// scenario 4
+		{
+			name:     "probes are authenticated by the sidecar when anonymous auth is disabled",
+			template: "{{.AnonymousAuthDisabled}}",
+			golden:   "true",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"anonymous-auth":["false"]}}`)},
+			}},
+		},
+
+		// scenario 5
+		{
+			name:     "sidecars keep their default verbosity without a logging config",
+			template: "{{.Verbosity}},{{.VModule}},{{.CertSyncerVerbosity}},{{.CheckEndpointsVerbosity}},{{.InsecureReadyzVerbosity}}",
+			golden:   " -v=4,,0,2,0",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				LogLevel: operatorv1.Debug,
+			}},
+		},
+
+		// scenario 6
+		{
+			name:     "per container log levels and vmodule from the observed config are applied",
+			template: "{{.Verbosity}},{{.VModule}},{{.CertSyncerVerbosity}},{{.CheckEndpointsVerbosity}},{{.InsecureReadyzVerbosity}}",
+			golden:   " -v=6, --vmodule='httplog=4,rest*=6',8,4,2",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				LogLevel:       operatorv1.Debug,
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"logging":{"kubeAPIServer":"Trace","certSyncer":"TraceAll","checkEndpoints":"Debug","insecureReadyz":"Normal","vmodule":"httplog=4,rest*=6"}}`)},
+			}},
+		},
+
+		// scenario 7
+		{
+			name:     "single replica control planes wait for in-flight requests no longer than the graceful termination duration",
+			template: "{{.GracefulTerminationDuration}}, {{.PortReleaseWaitDuration}}, {{.SetupContainerTimeoutDuration}}",
+			golden:   "15, 15, 50",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"gracefulTerminationDuration":"15"}`)},
+			}},
+		},
+
+		// scenario 8
+		{
+			name:     "advertise address subnets from the observed config are applied",
+			template: "{{range .AdvertiseAddressSubnets}}{{.}} {{end}}",
+			golden:   "192.168.10.0/24 fd00:10::/64 ",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"advertiseAddressSubnets":["192.168.10.0/24","fd00:10::/64"]}`)},
+			}},
+		},
+
+		// scenario 9
+		{
+			name:     "a graceful termination duration longer than the shutdown delay is accepted",
+			template: "{{.GracefulTerminationDuration}}",
+			golden:   "155",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"gracefulTerminationDuration":"155","apiServerArguments":{"shutdown-delay-duration":["90s"]}}`)},
+			}},
+		},
+
+		// scenario 10
+		{
+			name:     "a graceful termination duration not longer than the shutdown delay is rejected",
+			template: "{{.GracefulTerminationDuration}}",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig:             runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"shutdown-delay-duration":["210s"]}}`)},
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"gracefulTerminationDuration":"135"}`)},
+			}},
+			expectError: true,
+		},
+
+		// scenario 11
+		{
+			name:     "the sidecars stop serving debug endpoints when the kube-apiserver does",
+			template: "{{.ProfilingDisabled}}",
+			golden:   "true",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"profiling":["false"]}}`)},
+			}},
+		},
+
+		// scenario 12
+		{
+			name:         "the insecure-readyz sidecar listens on 6080 by default",
+			template:     "{{.InsecureReadyzPort}},{{.InsecureReadyzDisabled}}",
+			golden:       "6080,false",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{},
+		},
+
+		// scenario 13
+		{
+			name:     "the insecure-readyz port from the observed config is applied",
+			template: "{{.InsecureReadyzPort}},{{.InsecureReadyzDisabled}}",
+			golden:   "16080,false",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"insecureReadyz":{"port":16080}}`)},
+			}},
+		},
+
+		// scenario 14
+		{
+			name:     "the insecure-readyz sidecar cannot be disabled while the probes go through it",
+			template: "{{.InsecureReadyzDisabled}}",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"anonymous-auth":["false"]},"insecureReadyz":{"disabled":true}}`)},
+			}},
+			expectError: true,
+		},
 	}
 
 	for _, scenario := range scenarios {
 		t.Run(scenario.name, func(t *testing.T) {
 			// act
-			appliedTemplate, err := manageTemplate(
-				scenario.template,
-				"CaptainAmerica",
-				"Piper",
-				scenario.operatorSpec)
+			appliedTemplate, err := manageTemplateForSpec(scenario.template, scenario.operatorSpec)
 
 			// validate
-			if err != nil {
-				t.Fatal(err)
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
 			}
 
 			if appliedTemplate != scenario.golden {
@@ -194,3 +312,419 @@ func TestManageTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyResources(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		observedConfig string
+		expected       corev1.ResourceRequirements
+		expectError    bool
+	}{
+		{
+			name:           "template defaults",
+			observedConfig: `{}`,
+			expected: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("265m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		},
+		{
+			name:           "observed resources are merged into the template",
+			observedConfig: `{"resources":{"kube-apiserver":{"requests":{"cpu":"100m"},"limits":{"memory":"8Gi"}}}}`,
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+			},
+		},
+		{
+			name:           "limit below the template request",
+			observedConfig: `{"resources":{"kube-apiserver":{"limits":{"memory":"512Mi"}}}}`,
+			expectError:    true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(scenario.observedConfig)},
+			}}
+			appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), operatorSpec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+			config, err := podConfigFromSpec(operatorSpec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = applyResources(pod, config.Resources)
+			if scenario.expectError != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if scenario.expectError {
+				return
+			}
+			for _, container := range pod.Spec.Containers {
+				if container.Name != "kube-apiserver" {
+					continue
+				}
+				if !equality.Semantic.DeepEqual(scenario.expected, container.Resources) {
+					t.Fatalf("expected resources %v, got %v", scenario.expected, container.Resources)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyReservedCPUs(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "setup"}},
+		Containers: []corev1.Container{{
+			Name:      "kube-apiserver",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("265m")}},
+		}},
+	}}
+
+	if err := applyReservedCPUs(pod, "0-1,52-53"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"resources.workload.openshift.io/setup":          `{"cpushares":2,"cpuset":"0-1,52-53"}`,
+		"resources.workload.openshift.io/kube-apiserver": `{"cpushares":271,"cpuset":"0-1,52-53"}`,
+	}
+	if !equality.Semantic.DeepEqual(expected, pod.Annotations) {
+		t.Fatalf("expected annotations %v, got %v", expected, pod.Annotations)
+	}
+}
+
+// TestStaticPodNamesKnownToOperatorConfig makes sure the validation of the operator config knows the containers and
+// volumes of the pod template that resources and sidecars refer to.
+func TestStaticPodNamesKnownToOperatorConfig(t *testing.T) {
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), &operatorv1.StaticPodOperatorSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+	containers := sets.NewString()
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		containers.Insert(container.Name)
+	}
+	if !containers.Equal(operatorconfig.StaticPodContainers) {
+		t.Errorf("expected containers %v, got %v", operatorconfig.StaticPodContainers.List(), containers.List())
+	}
+
+	volumes := sets.NewString()
+	for _, volume := range pod.Spec.Volumes {
+		volumes.Insert(volume.Name)
+	}
+	if !volumes.Equal(operatorconfig.StaticPodVolumes) {
+		t.Errorf("expected volumes %v, got %v", operatorconfig.StaticPodVolumes.List(), volumes.List())
+	}
+}
+
+func TestSidecarsFromConfig(t *testing.T) {
+	config := mustPodConfig(t, `{"sidecars":{"containers":[{"name":"audit-shipper","image":"quay.io/example/shipper:latest"}],"volumes":[{"name":"buffer","emptyDir":{}}]}}`)
+	sidecars := config.Sidecars
+
+	expected := operatorconfig.SidecarConfig{
+		Containers: []corev1.Container{{Name: "audit-shipper", Image: "quay.io/example/shipper:latest"}},
+		Volumes:    []corev1.Volume{{Name: "buffer", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+	}
+	if !equality.Semantic.DeepEqual(expected, sidecars) {
+		t.Fatalf("expected sidecars %v, got %v", expected, sidecars)
+	}
+}
+
+func TestApplyProbes(t *testing.T) {
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), &operatorv1.StaticPodOperatorSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+	config := mustPodConfig(t, `{"probes":{"liveness":{"failureThreshold":6},"startup":{"periodSeconds":10,"failureThreshold":30}}}`)
+	applyProbes(pod, &config.Probes)
+
+	container := pod.Spec.Containers[0]
+	if container.Name != "kube-apiserver" {
+		t.Fatalf("expected the kube-apiserver container first, got %s", container.Name)
+	}
+	if container.LivenessProbe.FailureThreshold != 6 || container.LivenessProbe.InitialDelaySeconds != 45 {
+		t.Errorf("unexpected liveness probe %v", container.LivenessProbe)
+	}
+	if container.ReadinessProbe.InitialDelaySeconds != 10 || container.ReadinessProbe.FailureThreshold != 0 {
+		t.Errorf("unexpected readiness probe %v", container.ReadinessProbe)
+	}
+	if container.StartupProbe == nil || container.StartupProbe.HTTPGet.Path != "livez" || container.StartupProbe.FailureThreshold != 30 || container.StartupProbe.PeriodSeconds != 10 {
+		t.Errorf("unexpected startup probe %v", container.StartupProbe)
+	}
+}
+
+func TestApplyNonRoot(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"nonRoot":{"uid":1001}}`)},
+	}}
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+	config, err := podConfigFromSpec(operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyNonRoot(pod, config.NonRoot)
+
+	setup := pod.Spec.InitContainers[0]
+	if setup.SecurityContext == nil || setup.SecurityContext.Privileged == nil || !*setup.SecurityContext.Privileged {
+		t.Errorf("expected the setup container to stay privileged, got %v", setup.SecurityContext)
+	}
+	if !strings.Contains(setup.Args[0], "-exec chown 1001:1001 {} +") {
+		t.Errorf("expected the setup container to hand the files over, got %s", setup.Args[0])
+	}
+	if len(setup.VolumeMounts) != 3 {
+		t.Errorf("expected the setup container to mount the resource-dir, cert-dir and audit-dir, got %v", setup.VolumeMounts)
+	}
+	for _, container := range pod.Spec.Containers {
+		securityContext := container.SecurityContext
+		if securityContext == nil || securityContext.Privileged != nil || *securityContext.RunAsUser != 1001 || *securityContext.RunAsGroup != 1001 || !*securityContext.RunAsNonRoot || *securityContext.AllowPrivilegeEscalation {
+			t.Errorf("container %s: unexpected security context %v", container.Name, securityContext)
+		}
+	}
+	if !strings.Contains(pod.Spec.Containers[0].Args[0], `export SSL_CERT_FILE="${TRUST_BUNDLE}"`) {
+		t.Errorf("expected the kube-apiserver to read the trust bundle from SSL_CERT_FILE, got %s", pod.Spec.Containers[0].Args[0])
+	}
+}
+
+func TestApplySecurityContext(t *testing.T) {
+	pod := &corev1.Pod{}
+	applySecurityContext(pod, &operatorconfig.SecurityContextConfig{})
+	if pod.Spec.SecurityContext != nil {
+		t.Fatalf("expected no security context without an observed config, got %v", pod.Spec.SecurityContext)
+	}
+
+	config := mustPodConfig(t, `{"securityContext":{"seccompProfile":{"type":"RuntimeDefault"},"seLinuxOptions":{"level":"s0:c1,c2"}}}`)
+	applySecurityContext(pod, &config.SecurityContext)
+
+	expected := &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		SELinuxOptions: &corev1.SELinuxOptions{Level: "s0:c1,c2"},
+	}
+	if !equality.Semantic.DeepEqual(expected, pod.Spec.SecurityContext) {
+		t.Fatalf("expected security context %v, got %v", expected, pod.Spec.SecurityContext)
+	}
+}
+
+func TestInsecureReadyzDisabled(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"insecureReadyz":{"disabled":true}}`)},
+	}}
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "kube-apiserver-insecure-readyz" {
+			t.Fatalf("expected the insecure-readyz sidecar to be removed")
+		}
+	}
+	if len(pod.Spec.Containers) != 4 {
+		t.Errorf("expected the other containers to be kept, got %d", len(pod.Spec.Containers))
+	}
+}
+
+func TestAuditForwarder(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"auditForwarder":{"protocol":"Syslog","endpoint":"siem.example.com:6514","tls":{"caConfigMap":"siem-ca"}}}`)},
+	}}
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+
+	var forwarder *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == "kube-apiserver-audit-forwarder" {
+			forwarder = &pod.Spec.Containers[i]
+		}
+	}
+	if forwarder == nil {
+		t.Fatalf("expected the audit forwarder sidecar")
+	}
+	if forwarder.Image != "Piper" {
+		t.Errorf("expected the operator image, got %q", forwarder.Image)
+	}
+	expectedArgs := []string{
+		"--audit-log=/var/log/kube-apiserver/audit.log",
+		"--position-file=/var/log/kube-apiserver/audit-forwarder.position",
+		"--protocol=syslog",
+		"--endpoint=siem.example.com:6514",
+		"--hostname=$(NODE_NAME)",
+		"--tls",
+		"--ca-file=/etc/kubernetes/static-pod-resources/configmaps/audit-forwarder-ca/ca-bundle.crt",
+	}
+	if !equality.Semantic.DeepEqual(expectedArgs, forwarder.Args) {
+		t.Errorf("expected args %v, got %v", expectedArgs, forwarder.Args)
+	}
+	if len(forwarder.VolumeMounts) != 2 {
+		t.Errorf("expected the audit-dir and resource-dir mounts, got %v", forwarder.VolumeMounts)
+	}
+
+	// without the operator config there is no sidecar
+	appliedTemplate, err = manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), &operatorv1.StaticPodOperatorSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, container := range resourceread.ReadPodV1OrDie([]byte(appliedTemplate)).Spec.Containers {
+		if container.Name == "kube-apiserver-audit-forwarder" {
+			t.Fatalf("expected no audit forwarder sidecar")
+		}
+	}
+}
+
+func TestApplyStartupMonitor(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"startupMonitor":{"mode":"Enabled","fallbackTimeout":"10m","readyzSuccessThreshold":5,"readyzInterval":"10s","crashLoopThreshold":3}}`)},
+	}}
+	config, err := podConfigFromSpec(operatorSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, pod, err := generateOptionalStartupMonitorPod(func() (bool, error) { return true, nil }, operatorSpec, "Piper", &config.StartupMonitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := strings.Join(pod.Spec.Containers[0].Args, " ")
+	for _, expected := range []string{"--fallback-timeout-duration=10m", "--readyz-success-threshold=5", "--readyz-interval=10s", "--crash-loop-threshold=3"} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("expected %s in the startup monitor args, got %s", expected, args)
+		}
+	}
+	if strings.Contains(args, "--fallback-timeout-duration=300s") {
+		t.Errorf("expected the default fallback timeout to be replaced, got %s", args)
+	}
+}
+
+func TestApplyHostPathMounts(t *testing.T) {
+	config := mustPodConfig(t, `{"hostPathMounts":[{"name":"kms","hostPath":"/var/run/kmsplugin","mountPath":"/var/run/kmsplugin","type":"Directory"}]}`)
+	appliedTemplate, err := manageTemplateForSpec(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), &operatorv1.StaticPodOperatorSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(appliedTemplate))
+	volumes := len(pod.Spec.Volumes)
+
+	applyHostPathMounts(pod, config.HostPathMounts)
+
+	if len(pod.Spec.Volumes) != volumes+1 {
+		t.Fatalf("expected one more volume, got %v", pod.Spec.Volumes)
+	}
+	volume := pod.Spec.Volumes[volumes]
+	if volume.Name != "kms" || volume.HostPath == nil || volume.HostPath.Path != "/var/run/kmsplugin" || *volume.HostPath.Type != corev1.HostPathDirectory {
+		t.Errorf("unexpected volume %v", volume)
+	}
+	for _, container := range pod.Spec.Containers {
+		var found bool
+		for _, mount := range container.VolumeMounts {
+			if mount.Name != "kms" {
+				continue
+			}
+			found = true
+			if mount.MountPath != "/var/run/kmsplugin" || !mount.ReadOnly {
+				t.Errorf("container %s: unexpected mount %v", container.Name, mount)
+			}
+		}
+		if found != (container.Name == "kube-apiserver") {
+			t.Errorf("container %s: expected the host path to be mounted into the kube-apiserver container only", container.Name)
+		}
+	}
+}
+
+// TestManagePodsUnsupportedConfigOverrides makes sure the unsupported config overrides apply to every knob of the pod,
+// not only to some of them.
+func TestManagePodsUnsupportedConfigOverrides(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{"reservedCPUs":"0-1","probes":{"liveness":{"failureThreshold":3}}}`)},
+		UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{
+			"operandImage": "quay.io/example/kube-apiserver:test",
+			"environment": {"GODEBUG": "x509sha1=1"},
+			"reservedCPUs": "2-3",
+			"probes": {"liveness": {"failureThreshold": 9}},
+			"securityContext": {"seccompProfile": {"type": "RuntimeDefault"}},
+			"hostPathMounts": [{"name": "kms", "hostPath": "/var/run/kmsplugin", "mountPath": "/var/run/kmsplugin", "type": "Directory"}],
+			"sidecars": {"containers": [{"name": "audit-shipper", "image": "quay.io/example/shipper:latest"}]},
+			"nonRoot": {"uid": 1001},
+			"logging": {"kubeAPIServer": "Debug"}
+		}`)},
+	}}
+	client := fake.NewSimpleClientset()
+	configMap, _, err := managePods(context.TODO(), client.CoreV1(), nil, func() (bool, error) { return false, nil }, events.NewInMemoryRecorder(t.Name()), operatorSpec, nil, "CaptainAmerica", "Piper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := resourceread.ReadPodV1OrDie([]byte(configMap.Data["pod.yaml"]))
+
+	kubeAPIServer := pod.Spec.Containers[0]
+	if kubeAPIServer.Image != "quay.io/example/kube-apiserver:test" {
+		t.Errorf("expected the operand image of the overrides, got %s", kubeAPIServer.Image)
+	}
+	if !strings.Contains(kubeAPIServer.Args[0], "-v=4") {
+		t.Errorf("expected the log level of the overrides, got %s", kubeAPIServer.Args[0])
+	}
+	var env []string
+	for _, e := range kubeAPIServer.Env {
+		env = append(env, e.Name+"="+e.Value)
+	}
+	if !sets.NewString(env...).Has("GODEBUG=x509sha1=1") {
+		t.Errorf("expected the environment of the overrides, got %v", env)
+	}
+	if kubeAPIServer.LivenessProbe.FailureThreshold != 9 {
+		t.Errorf("expected the probes of the overrides, got %v", kubeAPIServer.LivenessProbe)
+	}
+	if kubeAPIServer.SecurityContext == nil || kubeAPIServer.SecurityContext.RunAsUser == nil || *kubeAPIServer.SecurityContext.RunAsUser != 1001 {
+		t.Errorf("expected the non-root user of the overrides, got %v", kubeAPIServer.SecurityContext)
+	}
+	if pod.Spec.SecurityContext == nil || pod.Spec.SecurityContext.SeccompProfile == nil {
+		t.Errorf("expected the security context of the overrides, got %v", pod.Spec.SecurityContext)
+	}
+	if volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]; volume.Name != "kms" {
+		t.Errorf("expected the host path mounts of the overrides, got %v", volume)
+	}
+	if sidecar := pod.Spec.Containers[len(pod.Spec.Containers)-1]; sidecar.Name != "audit-shipper" {
+		t.Errorf("expected the sidecars of the overrides, got %s", sidecar.Name)
+	}
+	if cpuset := pod.Annotations[workloadResourcesAnnotationPrefix+"kube-apiserver"]; !strings.Contains(cpuset, `"cpuset":"2-3"`) {
+		t.Errorf("expected the reserved cpus of the overrides, got %s", cpuset)
+	}
+}
+
+// manageTemplateForSpec applies the template with the pod config of the operator spec.
+func manageTemplateForSpec(rawTemplate string, operatorSpec *operatorv1.StaticPodOperatorSpec) (string, error) {
+	config, err := podConfigFromSpec(operatorSpec)
+	if err != nil {
+		return "", err
+	}
+	return manageTemplate(rawTemplate, "CaptainAmerica", "Piper", operatorSpec.LogLevel, config)
+}
+
+func mustPodConfig(t *testing.T, observedConfig string) *podConfig {
+	config, err := podConfigFromSpec(&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(observedConfig)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
@@ -0,0 +1,255 @@
+package terminationobserver
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// DisruptionConfigMapName is the configmap in the operator namespace with the disruption of the last revisions,
+	// keyed by revision.
+	DisruptionConfigMapName = "rollout-disruption"
+
+	// maxReportedRevisions is how many revisions are kept in the report
+	maxReportedRevisions = 10
+
+	// NonGracefulTerminationReason is recorded by the watch-termination wrapper of the kube-apiserver when it finds
+	// that the previous kube-apiserver ended without finishing its graceful termination.
+	NonGracefulTerminationReason = "NonGracefulTermination"
+	LateConnectionsReason        = "LateConnections"
+	stoppedServingReason         = "TerminationStoppedServing"
+	gracefulTerminationReason    = "TerminationGracefulTerminationFinished"
+)
+
+var (
+	apiServerTerminationOutcomeCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_termination_outcome_count",
+		Help: "Report the terminations of each API server instance that finished gracefully or did not",
+	}, []string{"name", "outcome"})
+
+	apiServerTerminationDrainHistogram = metrics.NewHistogram(&metrics.HistogramOpts{
+		Name:    "openshift_kube_apiserver_termination_drain_duration_seconds",
+		Help:    "Report the time API server instances took to process their in-flight requests after they stopped listening",
+		Buckets: []float64{1, 5, 10, 20, 30, 45, 60, 90, 120},
+	})
+)
+
+// RevisionDisruption is how disruptive the terminations of kube-apiservers were while a revision rolled out, or was the
+// revision of the nodes.
+type RevisionDisruption struct {
+	// terminations are the kube-apiservers that were replaced
+	Terminations int `json:"terminations"`
+	// graceful are the kube-apiservers that processed their in-flight requests before they ended
+	Graceful int `json:"graceful"`
+	// nonGraceful are the kube-apiservers that ended before they processed their in-flight requests
+	NonGraceful int `json:"nonGraceful"`
+	// lateConnections are the kube-apiservers that received connections late in their graceful termination, a sign of
+	// a load balancer that does not notice the kube-apiserver is unready
+	LateConnections int `json:"lateConnections"`
+	// maxDrainSeconds is the longest time a kube-apiserver took to process its in-flight requests after it stopped
+	// listening
+	MaxDrainSeconds float64 `json:"maxDrainSeconds,omitempty"`
+	// lastTermination is when the last kube-apiserver was replaced
+	LastTermination *metav1.Time `json:"lastTermination,omitempty"`
+}
+
+// add adds the disruption of other to d.
+func (d *RevisionDisruption) add(other RevisionDisruption) {
+	d.Terminations += other.Terminations
+	d.Graceful += other.Graceful
+	d.NonGraceful += other.NonGraceful
+	d.LateConnections += other.LateConnections
+	if other.MaxDrainSeconds > d.MaxDrainSeconds {
+		d.MaxDrainSeconds = other.MaxDrainSeconds
+	}
+	if other.LastTermination != nil && (d.LastTermination == nil || d.LastTermination.Before(other.LastTermination)) {
+		d.LastTermination = other.LastTermination
+	}
+}
+
+// revisionOf returns the revision a kube-apiserver pod is terminated for: the revision its node is updated to, or the
+// current revision of the node when it is not updated, e.g. when it rebooted. Zero if the node is unknown.
+func revisionOf(podName string, nodeStatuses []operatorv1.NodeStatus) int32 {
+	for _, nodeStatus := range nodeStatuses {
+		if "kube-apiserver-"+nodeStatus.NodeName != podName {
+			continue
+		}
+		if nodeStatus.TargetRevision > 0 {
+			return nodeStatus.TargetRevision
+		}
+		return nodeStatus.CurrentRevision
+	}
+	return 0
+}
+
+// observeDisruption records an event of a kube-apiserver in the metrics and the disruption of its revision. It returns
+// whether the disruption changed and has to be written.
+func (c *TerminationObserver) observeDisruption(event *corev1.Event) bool {
+	podName := event.InvolvedObject.Name
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return false
+	}
+	revision := revisionOf(podName, status.NodeStatuses)
+
+	c.Lock()
+	defer c.Unlock()
+	switch event.Reason {
+	case stoppedServingReason:
+		c.stoppedServingTime[podName] = event.LastTimestamp.Time
+		return false
+	case gracefulTerminationReason:
+		apiServerTerminationOutcomeCounter.WithLabelValues(podName, "graceful").Inc()
+		disruption := c.disruptionOf(revision)
+		disruption.Graceful++
+		if stoppedServing, ok := c.stoppedServingTime[podName]; ok && !event.LastTimestamp.Time.Before(stoppedServing) {
+			drain := event.LastTimestamp.Time.Sub(stoppedServing).Seconds()
+			apiServerTerminationDrainHistogram.Observe(drain)
+			if drain > disruption.MaxDrainSeconds {
+				disruption.MaxDrainSeconds = drain
+			}
+			delete(c.stoppedServingTime, podName)
+		}
+	case NonGracefulTerminationReason:
+		apiServerTerminationOutcomeCounter.WithLabelValues(podName, "non-graceful").Inc()
+		c.disruptionOf(revision).NonGraceful++
+	case LateConnectionsReason:
+		c.disruptionOf(revision).LateConnections++
+	default:
+		return false
+	}
+	c.disruptionChanged = true
+	return true
+}
+
+// observeTermination records the replacement of a kube-apiserver pod in the disruption of its revision. The caller
+// holds the lock.
+func (c *TerminationObserver) observeTermination(pod *corev1.Pod, nodeStatuses []operatorv1.NodeStatus) {
+	disruption := c.disruptionOf(revisionOf(pod.Name, nodeStatuses))
+	disruption.Terminations++
+	at := pod.CreationTimestamp.DeepCopy()
+	if disruption.LastTermination == nil || disruption.LastTermination.Before(at) {
+		disruption.LastTermination = at
+	}
+	c.disruptionChanged = true
+}
+
+// disruptionOf returns the disruption of a revision, the caller holds the lock.
+func (c *TerminationObserver) disruptionOf(revision int32) *RevisionDisruption {
+	disruption, ok := c.disruptions[revision]
+	if !ok {
+		disruption = &RevisionDisruption{}
+		c.disruptions[revision] = disruption
+	}
+	return disruption
+}
+
+// syncDisruptionReport writes the disruption of the last revisions to the rollout-disruption configmap. The report of
+// the previous operator process is added to the observed disruption once.
+func (c *TerminationObserver) syncDisruptionReport(ctx context.Context) error {
+	c.Lock()
+	defer c.Unlock()
+	if !c.disruptionChanged && c.reportLoaded {
+		return nil
+	}
+
+	if !c.reportLoaded {
+		existing, err := c.configMapsGetter.ConfigMaps(operatorclient.OperatorNamespace).Get(ctx, DisruptionConfigMapName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return err
+		default:
+			mergeDisruptionReport(c.disruptions, existing.Data)
+		}
+		c.reportLoaded = true
+	}
+
+	data, err := newDisruptionReport(c.disruptions)
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapsGetter, c.eventRecorder, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: DisruptionConfigMapName},
+		Data:       data,
+	}); err != nil {
+		return err
+	}
+	c.disruptionChanged = false
+	return nil
+}
+
+// mergeDisruptionReport adds the disruption of a report to the observed disruption. Entries that do not decode are
+// dropped.
+func mergeDisruptionReport(disruptions map[int32]*RevisionDisruption, data map[string]string) {
+	for key, raw := range data {
+		revision, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		var recorded RevisionDisruption
+		if err := json.Unmarshal([]byte(raw), &recorded); err != nil {
+			continue
+		}
+		disruption, ok := disruptions[int32(revision)]
+		if !ok {
+			disruption = &RevisionDisruption{}
+			disruptions[int32(revision)] = disruption
+		}
+		disruption.add(recorded)
+	}
+}
+
+// newDisruptionReport returns the disruption of the last revisions, keyed by revision, and forgets the older ones.
+// Terminations of pods on unknown nodes are not reported.
+func newDisruptionReport(disruptions map[int32]*RevisionDisruption) (map[string]string, error) {
+	delete(disruptions, 0)
+	var revisions []int32
+	for revision := range disruptions {
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i] > revisions[j] })
+	if len(revisions) > maxReportedRevisions {
+		for _, revision := range revisions[maxReportedRevisions:] {
+			delete(disruptions, revision)
+		}
+		revisions = revisions[:maxReportedRevisions]
+	}
+
+	data := map[string]string{}
+	for _, revision := range revisions {
+		raw, err := json.Marshal(disruptions[revision])
+		if err != nil {
+			return nil, err
+		}
+		data[strconv.Itoa(int(revision))] = string(raw)
+	}
+	return data, nil
+}
+
+// isDisruptionEvent returns true for the events of a kube-apiserver that tell how disruptive its termination was.
+func isDisruptionEvent(event *corev1.Event) bool {
+	switch event.Reason {
+	case stoppedServingReason, gracefulTerminationReason, NonGracefulTerminationReason, LateConnectionsReason:
+		return true
+	}
+	return false
+}
+
+// isRecentEvent returns true for events that happened after the observer started. The events the informer lists
+// when the operator starts were observed by the previous operator process already.
+func isRecentEvent(event *corev1.Event, started time.Time) bool {
+	return !event.LastTimestamp.Time.Before(started)
+}
@@ -0,0 +1,117 @@
+package terminationobserver
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRevisionOf(t *testing.T) {
+	nodeStatuses := []operatorv1.NodeStatus{
+		{NodeName: "master-0", CurrentRevision: 3, TargetRevision: 4},
+		{NodeName: "master-1", CurrentRevision: 3},
+	}
+	for podName, expected := range map[string]int32{
+		"kube-apiserver-master-0": 4,
+		"kube-apiserver-master-1": 3,
+		"kube-apiserver-master-2": 0,
+	} {
+		if revision := revisionOf(podName, nodeStatuses); revision != expected {
+			t.Errorf("%s: expected revision %d, got %d", podName, expected, revision)
+		}
+	}
+}
+
+func TestObserveDisruption(t *testing.T) {
+	stoppedServing := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := func(podName, reason string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Name: podName},
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+	c := &TerminationObserver{
+		operatorClient: v1helpers.NewFakeStaticPodOperatorClient(nil, &operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{
+			{NodeName: "master-0", CurrentRevision: 3, TargetRevision: 4},
+			{NodeName: "master-1", CurrentRevision: 4},
+		}}, nil, nil),
+		stoppedServingTime: map[string]time.Time{},
+		disruptions:        map[int32]*RevisionDisruption{},
+	}
+
+	if c.observeDisruption(event("kube-apiserver-master-0", stoppedServingReason, stoppedServing)) {
+		t.Errorf("expected the stopped serving event not to change the disruption")
+	}
+	for _, e := range []*corev1.Event{
+		event("kube-apiserver-master-0", LateConnectionsReason, stoppedServing.Add(10*time.Second)),
+		event("kube-apiserver-master-0", gracefulTerminationReason, stoppedServing.Add(70*time.Second)),
+		event("kube-apiserver-master-1", NonGracefulTerminationReason, stoppedServing),
+		event("kube-apiserver-master-2", NonGracefulTerminationReason, stoppedServing),
+	} {
+		if !c.observeDisruption(e) {
+			t.Errorf("expected %s of %s to change the disruption", e.Reason, e.InvolvedObject.Name)
+		}
+	}
+	if c.observeDisruption(event("kube-apiserver-master-0", "TerminationStart", stoppedServing)) {
+		t.Errorf("expected other events not to change the disruption")
+	}
+
+	expected := map[int32]*RevisionDisruption{
+		4: {Graceful: 1, NonGraceful: 1, LateConnections: 1, MaxDrainSeconds: 70},
+		0: {NonGraceful: 1},
+	}
+	if diff := cmp.Diff(expected, c.disruptions); diff != "" {
+		t.Errorf("unexpected disruption: %s", diff)
+	}
+	if _, ok := c.stoppedServingTime["kube-apiserver-master-0"]; ok {
+		t.Errorf("expected the stopped serving time to be forgotten after the graceful termination")
+	}
+}
+
+func TestDisruptionReport(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	disruptions := map[int32]*RevisionDisruption{
+		0: {Terminations: 1},
+		7: {Terminations: 1, Graceful: 1, MaxDrainSeconds: 20, LastTermination: &later},
+	}
+	for revision := int32(8); revision < 8+maxReportedRevisions; revision++ {
+		disruptions[revision] = &RevisionDisruption{Terminations: 3}
+	}
+	recorded, err := json.Marshal(RevisionDisruption{Terminations: 2, Graceful: 1, NonGraceful: 1, MaxDrainSeconds: 30, LastTermination: &earlier})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mergeDisruptionReport(disruptions, map[string]string{"7": string(recorded), "8": "invalid", "latest": string(recorded)})
+	expected := RevisionDisruption{Terminations: 3, Graceful: 2, NonGraceful: 1, MaxDrainSeconds: 30, LastTermination: &later}
+	if diff := cmp.Diff(&expected, disruptions[7]); diff != "" {
+		t.Errorf("unexpected merged disruption: %s", diff)
+	}
+
+	data, err := newDisruptionReport(disruptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != maxReportedRevisions {
+		t.Errorf("expected %d revisions, got %d", maxReportedRevisions, len(data))
+	}
+	for _, revision := range []string{"0", "7"} {
+		if _, ok := data[revision]; ok {
+			t.Errorf("expected revision %s not to be reported", revision)
+		}
+	}
+	if _, ok := disruptions[7]; ok {
+		t.Errorf("expected revision 7 to be forgotten")
+	}
+	if data["17"] != `{"terminations":3,"graceful":0,"nonGraceful":0,"lateConnections":0}` {
+		t.Errorf("unexpected report of revision 17: %s", data["17"])
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
 var (
@@ -39,16 +40,28 @@ var (
 // new revision or the pod is evicted or removed, the static pods are not reporting the terminating state back
 // to API server, but they only change the creationTimestamp.
 // We need to capture the termination events produced by the pods that we no longer see.
+//
+// The terminations and their events are also summarized per revision in the rollout-disruption configmap, so that the
+// disruption of every rollout can be compared.
 type TerminationObserver struct {
 	targetNamespace string
 
-	podsGetter corev1client.PodsGetter
+	operatorClient   v1helpers.StaticPodOperatorClient
+	podsGetter       corev1client.PodsGetter
+	configMapsGetter corev1client.ConfigMapsGetter
 
 	cachesToSync  []cache.InformerSynced
 	queue         workqueue.RateLimitingInterface
 	eventRecorder events.Recorder
+	// started is when the observer was created, earlier events were observed by the previous operator process
+	started time.Time
 
 	apiServerTerminationTime map[string]time.Time
+	// stoppedServingTime is when an API server stopped listening, until its graceful termination finished
+	stoppedServingTime map[string]time.Time
+	disruptions        map[int32]*RevisionDisruption
+	disruptionChanged  bool
+	reportLoaded       bool
 	sync.RWMutex
 }
 
@@ -71,21 +84,30 @@ func RegisterMetrics() {
 		legacyregistry.MustRegister(apiServerTerminationEventGauge)
 		legacyregistry.MustRegister(apiServerTerminationCounter)
 		legacyregistry.MustRegister(apiServerLateConnectionsCounter)
+		legacyregistry.MustRegister(apiServerTerminationOutcomeCounter)
+		legacyregistry.MustRegister(apiServerTerminationDrainHistogram)
 	})
 }
 
 func NewTerminationObserver(
 	targetNamespace string,
 	kubeInformersForTargetNamespace informers.SharedInformerFactory,
+	operatorClient v1helpers.StaticPodOperatorClient,
 	podsGetter corev1client.PodsGetter,
+	configMapsGetter corev1client.ConfigMapsGetter,
 	eventRecorder events.Recorder,
 ) *TerminationObserver {
 	c := &TerminationObserver{
 		targetNamespace:          targetNamespace,
+		operatorClient:           operatorClient,
 		podsGetter:               podsGetter,
+		configMapsGetter:         configMapsGetter,
 		eventRecorder:            eventRecorder.WithComponentSuffix("termination-observer"),
 		queue:                    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "TerminationObserver"),
+		started:                  time.Now(),
 		apiServerTerminationTime: map[string]time.Time{},
+		stoppedServingTime:       map[string]time.Time{},
+		disruptions:              map[int32]*RevisionDisruption{},
 	}
 
 	kubeInformersForTargetNamespace.Core().V1().Pods().Informer().AddEventHandler(c.eventHandler())
@@ -98,10 +120,21 @@ func NewTerminationObserver(
 }
 
 func (c *TerminationObserver) sync(ctx context.Context) error {
+	if err := c.observeTerminations(ctx); err != nil {
+		return err
+	}
+	return c.syncDisruptionReport(ctx)
+}
+
+func (c *TerminationObserver) observeTerminations(ctx context.Context) error {
 	podList, err := c.podsGetter.Pods(c.targetNamespace).List(ctx, metav1.ListOptions{LabelSelector: "app=openshift-kube-apiserver"})
 	if err != nil {
 		return fmt.Errorf("unable to list pods in %q namespace: %v", c.targetNamespace, err)
 	}
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
 
 	c.Lock()
 	defer c.Unlock()
@@ -124,6 +157,7 @@ func (c *TerminationObserver) sync(ctx context.Context) error {
 
 			// increase the "termination" counter for this API server.
 			apiServerTerminationCounter.WithLabelValues(pod.Name).Inc()
+			c.observeTermination(&pod, status.NodeStatuses)
 
 			// record the current pod creationTimestamp as "termination" timestamp for the previous API server.
 			c.apiServerTerminationTime[pod.Name] = pod.CreationTimestamp.Time
@@ -219,6 +253,9 @@ func (c *TerminationObserver) terminationEventRecorder() cache.ResourceEventHand
 			if !isApiServerEvent(event, c.apiServerNames()) {
 				return
 			}
+			if isDisruptionEvent(event) && isRecentEvent(event, c.started) && c.observeDisruption(event) {
+				c.queue.Add(controllerWorkQueueKey)
+			}
 			if !isTerminationEvent(event) {
 				return
 			}
@@ -0,0 +1,166 @@
+package webhooksupportabilitycontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	HighRiskPriority   = "High"
+	MediumRiskPriority = "Medium"
+	LowRiskPriority    = "Low"
+
+	// FailClosedOnCriticalResourcesRisk is a webhook with failurePolicy Fail called for cluster-critical resources.
+	// When it is down, nodes, pods or the credentials of the cluster cannot be changed.
+	FailClosedOnCriticalResourcesRisk = "FailClosedOnCriticalResources"
+	// LatencyNearTimeoutRisk is a webhook whose latency is at least half of its timeout.
+	LatencyNearTimeoutRisk = "LatencyNearTimeout"
+	// BrokenRisk is a broken webhook with failurePolicy Fail, the requests it is called for fail.
+	BrokenRisk = "Broken"
+
+	// latencyRiskRatio is the part of the timeout the latency of a webhook may take before it is risky. The latency
+	// only covers connecting and the TLS handshake, the webhook has to answer in the rest of the time.
+	latencyRiskRatio = 0.5
+)
+
+// criticalResources are the resources by group the cluster cannot run without changing. They are what nodes, the
+// control plane and the operators need to write to keep working.
+var criticalResources = map[string]sets.String{
+	"":                          sets.NewString("pods", "nodes", "namespaces", "configmaps", "secrets", "serviceaccounts", "services", "endpoints"),
+	"coordination.k8s.io":       sets.NewString("leases"),
+	"authentication.k8s.io":     sets.NewString("tokenreviews"),
+	"authorization.k8s.io":      sets.NewString("subjectaccessreviews"),
+	"rbac.authorization.k8s.io": sets.NewString("roles", "rolebindings", "clusterroles", "clusterrolebindings"),
+	"certificates.k8s.io":       sets.NewString("certificatesigningrequests"),
+}
+
+// WebhookRisk is an admission or conversion webhook that can fail or slow down requests to the cluster.
+type WebhookRisk struct {
+	// Priority is High, Medium or Low.
+	Priority string `json:"priority"`
+	// Kind is MutatingWebhookConfiguration, ValidatingWebhookConfiguration or CustomResourceDefinition.
+	Kind string `json:"kind"`
+	// Configuration is the name of the webhook configuration or of the CRD.
+	Configuration string `json:"configuration"`
+	// Name is the name of the webhook in the configuration, empty for the conversion webhook of a CRD.
+	Name string `json:"name,omitempty"`
+	// Risks are FailClosedOnCriticalResources, LatencyNearTimeout and Broken.
+	Risks []string `json:"risks"`
+	// CriticalResources are the cluster-critical resources, as resource.group, a webhook that fails closed is called for.
+	CriticalResources []string `json:"criticalResources,omitempty"`
+	// Message describes the risks.
+	Message string `json:"message"`
+}
+
+// analyzeRisks returns the risky webhooks, the highest priority first and otherwise in the order of the webhooks. A
+// webhook that fails closed on cluster-critical resources has a high priority when it is also broken or slow, the
+// requests for these resources fail or time out already, and medium otherwise. A slow webhook that is not called for
+// cluster-critical resources has a low priority. The namespace and object selectors of a webhook are not considered.
+func analyzeRisks(webhooks []webhook, statuses []WebhookStatus) []WebhookRisk {
+	risks := []WebhookRisk{}
+	for i, w := range webhooks {
+		status := statuses[i]
+		risk := WebhookRisk{Kind: status.Kind, Configuration: status.Configuration, Name: status.Name}
+		var messages []string
+		failClosed := status.FailurePolicy == string(admissionregistrationv1.Fail)
+		if failClosed {
+			risk.CriticalResources = matchCriticalResources(w.rules)
+		}
+		if len(risk.CriticalResources) > 0 {
+			risk.Risks = append(risk.Risks, FailClosedOnCriticalResourcesRisk)
+			messages = append(messages, fmt.Sprintf("fails closed on %s", strings.Join(risk.CriticalResources, ", ")))
+		}
+		timeout := time.Duration(status.TimeoutSeconds) * time.Second
+		if status.Latency != nil && float64(status.Latency.Duration) >= latencyRiskRatio*float64(timeout) {
+			risk.Risks = append(risk.Risks, LatencyNearTimeoutRisk)
+			messages = append(messages, fmt.Sprintf("latency %s of timeout %s", status.Latency.Duration.Round(time.Millisecond), timeout))
+		}
+		if failClosed && status.Reason != asExpectedReason {
+			risk.Risks = append(risk.Risks, BrokenRisk)
+			messages = append(messages, fmt.Sprintf("broken: %s", status.Reason))
+		}
+
+		critical := len(risk.CriticalResources) > 0
+		switch {
+		case critical && len(risk.Risks) > 1:
+			risk.Priority = HighRiskPriority
+		case critical:
+			risk.Priority = MediumRiskPriority
+		case sets.NewString(risk.Risks...).Has(LatencyNearTimeoutRisk):
+			risk.Priority = LowRiskPriority
+		default:
+			// broken webhooks that are not called for critical resources are reported by the configuration error conditions
+			continue
+		}
+		risk.Message = strings.Join(messages, "; ")
+		risks = append(risks, risk)
+	}
+	sort.SliceStable(risks, func(i, j int) bool { return priorityOrder(risks[i].Priority) < priorityOrder(risks[j].Priority) })
+	return risks
+}
+
+func priorityOrder(priority string) int {
+	switch priority {
+	case HighRiskPriority:
+		return 0
+	case MediumRiskPriority:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matchCriticalResources returns the cluster-critical resources the rules match, as resource.group, sorted, or nil.
+func matchCriticalResources(rules []admissionregistrationv1.RuleWithOperations) []string {
+	matched := sets.NewString()
+	for _, rule := range rules {
+		for group, resources := range criticalResources {
+			if !matchesAny(rule.APIGroups, group) {
+				continue
+			}
+			for _, resource := range resources.List() {
+				if matchesAny(rule.Resources, resource) {
+					matched.Insert(strings.TrimSuffix(resource+"."+group, "."))
+				}
+			}
+		}
+	}
+	if matched.Len() == 0 {
+		return nil
+	}
+	return matched.List()
+}
+
+// matchesAny is true when the values contain the value, * or */*. Subresources like pods/* are not the resource.
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == "*/*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// newRiskCondition lists the risky webhooks one per line, the riskiest first. The reason is the highest priority.
+func newRiskCondition(risks []WebhookRisk) operatorv1.OperatorCondition {
+	if len(risks) == 0 {
+		return operatorv1.OperatorCondition{Type: AdmissionWebhookRiskDetectedConditionType, Status: operatorv1.ConditionFalse, Reason: asExpectedReason}
+	}
+	var lines []string
+	for _, risk := range risks {
+		status := WebhookStatus{Kind: risk.Kind, Configuration: risk.Configuration, Name: risk.Name}
+		lines = append(lines, fmt.Sprintf("%s: %s: %s", risk.Priority, describe(status), risk.Message))
+	}
+	return operatorv1.OperatorCondition{
+		Type:    AdmissionWebhookRiskDetectedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  risks[0].Priority + "Risk",
+		Message: strings.Join(lines, "\n"),
+	}
+}
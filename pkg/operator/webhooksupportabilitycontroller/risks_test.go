@@ -0,0 +1,96 @@
+package webhooksupportabilitycontroller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func rule(groups []string, resources ...string) admissionregistrationv1.RuleWithOperations {
+	return admissionregistrationv1.RuleWithOperations{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll},
+		Rule:       admissionregistrationv1.Rule{APIGroups: groups, APIVersions: []string{"*"}, Resources: resources},
+	}
+}
+
+func TestMatchCriticalResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []admissionregistrationv1.RuleWithOperations
+		expected []string
+	}{
+		{name: "none", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{"example.com"}, "widgets")}},
+		{name: "pods", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{""}, "pods", "persistentvolumeclaims")}, expected: []string{"pods"}},
+		{name: "subresources only", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{""}, "pods/exec", "pods/*")}},
+		{name: "group mismatch", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{"apps"}, "pods")}},
+		{name: "leases and secrets", rules: []admissionregistrationv1.RuleWithOperations{
+			rule([]string{"coordination.k8s.io"}, "leases"),
+			rule([]string{""}, "secrets"),
+		}, expected: []string{"leases.coordination.k8s.io", "secrets"}},
+		{name: "wildcard resources", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{"rbac.authorization.k8s.io"}, "*/*")},
+			expected: []string{"clusterrolebindings.rbac.authorization.k8s.io", "clusterroles.rbac.authorization.k8s.io", "rolebindings.rbac.authorization.k8s.io", "roles.rbac.authorization.k8s.io"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := matchCriticalResources(test.rules); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+	if actual := matchCriticalResources([]admissionregistrationv1.RuleWithOperations{rule([]string{"*"}, "*")}); len(actual) != 16 {
+		t.Errorf("expected every critical resource to match *, got %v", actual)
+	}
+}
+
+func TestAnalyzeRisks(t *testing.T) {
+	ignore := admissionregistrationv1.Ignore
+	podRules := []admissionregistrationv1.RuleWithOperations{rule([]string{""}, "pods")}
+	latency := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	webhooks := []webhook{
+		{kind: mutatingWebhookConfigurationKind, configuration: "a", name: "fast-pods", rules: podRules},
+		{kind: validatingWebhookConfigurationKind, configuration: "b", name: "slow-widgets", rules: []admissionregistrationv1.RuleWithOperations{rule([]string{"example.com"}, "widgets")}},
+		{kind: validatingWebhookConfigurationKind, configuration: "c", name: "broken-pods", rules: podRules},
+		{kind: validatingWebhookConfigurationKind, configuration: "d", name: "ignored-pods", rules: podRules, failurePolicy: &ignore},
+		{kind: validatingWebhookConfigurationKind, configuration: "e", name: "broken-widgets"},
+		{kind: mutatingWebhookConfigurationKind, configuration: "f", name: "slow-pods", rules: podRules},
+	}
+	statuses := []WebhookStatus{
+		{Kind: mutatingWebhookConfigurationKind, Configuration: "a", Name: "fast-pods", FailurePolicy: "Fail", TimeoutSeconds: 10, Latency: latency(10 * time.Millisecond), Reason: asExpectedReason},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "b", Name: "slow-widgets", FailurePolicy: "Fail", TimeoutSeconds: 2, Latency: latency(time.Second), Reason: asExpectedReason},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "c", Name: "broken-pods", FailurePolicy: "Fail", TimeoutSeconds: 10, Reason: UnreachableReason},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "d", Name: "ignored-pods", FailurePolicy: "Ignore", TimeoutSeconds: 10, Reason: UnreachableReason},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "e", Name: "broken-widgets", FailurePolicy: "Fail", TimeoutSeconds: 10, Reason: UnreachableReason},
+		{Kind: mutatingWebhookConfigurationKind, Configuration: "f", Name: "slow-pods", FailurePolicy: "Fail", TimeoutSeconds: 5, Latency: latency(4200 * time.Millisecond), Reason: asExpectedReason},
+	}
+
+	expected := []WebhookRisk{
+		{Priority: HighRiskPriority, Kind: validatingWebhookConfigurationKind, Configuration: "c", Name: "broken-pods",
+			Risks: []string{FailClosedOnCriticalResourcesRisk, BrokenRisk}, CriticalResources: []string{"pods"}, Message: "fails closed on pods; broken: Unreachable"},
+		{Priority: HighRiskPriority, Kind: mutatingWebhookConfigurationKind, Configuration: "f", Name: "slow-pods",
+			Risks: []string{FailClosedOnCriticalResourcesRisk, LatencyNearTimeoutRisk}, CriticalResources: []string{"pods"}, Message: "fails closed on pods; latency 4.2s of timeout 5s"},
+		{Priority: MediumRiskPriority, Kind: mutatingWebhookConfigurationKind, Configuration: "a", Name: "fast-pods",
+			Risks: []string{FailClosedOnCriticalResourcesRisk}, CriticalResources: []string{"pods"}, Message: "fails closed on pods"},
+		{Priority: LowRiskPriority, Kind: validatingWebhookConfigurationKind, Configuration: "b", Name: "slow-widgets",
+			Risks: []string{LatencyNearTimeoutRisk}, Message: "latency 1s of timeout 2s"},
+	}
+	risks := analyzeRisks(webhooks, statuses)
+	if !reflect.DeepEqual(risks, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, risks)
+	}
+
+	cond := newRiskCondition(risks)
+	expectedMessage := `High: webhook "broken-pods" of validatingwebhookconfiguration "c": fails closed on pods; broken: Unreachable` + "\n" +
+		`High: webhook "slow-pods" of mutatingwebhookconfiguration "f": fails closed on pods; latency 4.2s of timeout 5s` + "\n" +
+		`Medium: webhook "fast-pods" of mutatingwebhookconfiguration "a": fails closed on pods` + "\n" +
+		`Low: webhook "slow-widgets" of validatingwebhookconfiguration "b": latency 1s of timeout 2s`
+	if cond.Status != operatorv1.ConditionTrue || cond.Reason != "HighRisk" || cond.Message != expectedMessage {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+	if cond := newRiskCondition(analyzeRisks(nil, nil)); cond.Status != operatorv1.ConditionFalse || cond.Reason != asExpectedReason {
+		t.Errorf("expected no risk, got %+v", cond)
+	}
+}
@@ -0,0 +1,217 @@
+package webhooksupportabilitycontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	// unavailableThreshold is how long an aggregated API has to be unavailable before it blocks upgrades, so that the
+	// rollout of its server does not.
+	unavailableThreshold = 5 * time.Minute
+)
+
+// apiServicesResource are the aggregated APIs. The operator has no typed client of them.
+var apiServicesResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+var (
+	registerMetrics sync.Once
+
+	webhookErrorGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_webhook_error",
+		Help: "Report 1 for every admission or CRD conversion webhook that does not work, by the reason.",
+	}, []string{"kind", "configuration", "webhook", "reason"})
+
+	webhookLatencyGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_webhook_latency_seconds",
+		Help: "Report how long connecting to an admission or CRD conversion webhook and the TLS handshake took.",
+	}, []string{"kind", "configuration", "webhook"})
+
+	apiServiceUnavailableGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_operator_apiservice_unavailable",
+		Help: "Report 1 for every aggregated API that is unavailable, by the reason.",
+	}, []string{"apiservice", "reason"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(webhookErrorGauge)
+		legacyregistry.MustRegister(webhookLatencyGauge)
+		legacyregistry.MustRegister(apiServiceUnavailableGauge)
+	})
+}
+
+// APIServiceStatus is the availability of an aggregated API.
+type APIServiceStatus struct {
+	// Name is the name of the APIService, e.g. v1.packages.operators.coreos.com.
+	Name string `json:"name"`
+	// Service is the namespace/name:port of the service of the aggregated API.
+	Service string `json:"service"`
+	// Reason is AsExpected for an available API, otherwise the reason of its Available condition.
+	Reason string `json:"reason"`
+	// Message is the message of the Available condition of an unavailable API.
+	Message string `json:"message,omitempty"`
+	// UnavailableSince is when the API became unavailable.
+	UnavailableSince *metav1.Time `json:"unavailableSince,omitempty"`
+}
+
+// apiService is the part of an apiregistration.k8s.io/v1 APIService that is checked.
+type apiService struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		// Service is nil for the APIs served by the kube-apiserver itself.
+		Service *struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+			Port      *int32 `json:"port"`
+		} `json:"service"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []struct {
+			Type               string      `json:"type"`
+			Status             string      `json:"status"`
+			LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+			Reason             string      `json:"reason"`
+			Message            string      `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// conversionWebhook returns the conversion webhook of a CRD that converts by webhook.
+func conversionWebhook(crd *apiextensionsv1.CustomResourceDefinition) (webhook, bool) {
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+		return webhook{}, false
+	}
+	clientConfig := conversion.Webhook.ClientConfig
+	w := webhook{
+		kind:          customResourceDefinitionKind,
+		configuration: crd.Name,
+		clientConfig: admissionregistrationv1.WebhookClientConfig{
+			URL:      clientConfig.URL,
+			CABundle: clientConfig.CABundle,
+		},
+	}
+	if service := clientConfig.Service; service != nil {
+		w.clientConfig.Service = &admissionregistrationv1.ServiceReference{Namespace: service.Namespace, Name: service.Name, Path: service.Path, Port: service.Port}
+	}
+	return w, true
+}
+
+// checkAPIServices returns the availability of the aggregated APIs, sorted by name. APIs served by the kube-apiserver
+// itself are skipped.
+func checkAPIServices(items []unstructured.Unstructured) ([]APIServiceStatus, error) {
+	statuses := []APIServiceStatus{}
+	for _, item := range items {
+		apiService := &apiService{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), apiService); err != nil {
+			return nil, fmt.Errorf("apiservice %s: %v", item.GetName(), err)
+		}
+		if apiService.Spec.Service == nil {
+			continue
+		}
+		service := apiService.Spec.Service
+		port := int32(443)
+		if service.Port != nil {
+			port = *service.Port
+		}
+		status := APIServiceStatus{
+			Name:    apiService.Name,
+			Service: fmt.Sprintf("%s/%s:%d", service.Namespace, service.Name, port),
+			Reason:  "Unknown",
+			Message: "the Available condition is missing",
+		}
+		for _, cond := range apiService.Status.Conditions {
+			if cond.Type != "Available" {
+				continue
+			}
+			if cond.Status == "True" {
+				status.Reason, status.Message = asExpectedReason, ""
+				break
+			}
+			status.Reason, status.Message = cond.Reason, cond.Message
+			if len(status.Reason) == 0 {
+				status.Reason = "Unavailable"
+			}
+			if !cond.LastTransitionTime.IsZero() {
+				status.UnavailableSince = &cond.LastTransitionTime
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// newConversionWebhooksUpgradeableCondition is false while a CRD conversion webhook does not work. The storage
+// migration of an upgrade cannot read or write the resources of its CRD.
+func newConversionWebhooksUpgradeableCondition(statuses []WebhookStatus) operatorv1.OperatorCondition {
+	var broken []string
+	for _, status := range statuses {
+		if status.Kind == customResourceDefinitionKind && status.Reason != asExpectedReason {
+			broken = append(broken, status.Configuration)
+		}
+	}
+	if len(broken) == 0 {
+		return operatorv1.OperatorCondition{Type: CRDConversionWebhooksUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: asExpectedReason}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    CRDConversionWebhooksUpgradeableConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "ConversionWebhookBroken",
+		Message: fmt.Sprintf("the conversion webhooks of the CRDs %s do not work, see %s", strings.Join(broken, ", "), CRDConversionWebhookConfigurationErrorConditionType),
+	}
+}
+
+// newAPIServicesUpgradeableCondition is false while an aggregated API is unavailable for longer than the threshold.
+// Discovery fails for the whole cluster while one of them is unavailable.
+func newAPIServicesUpgradeableCondition(statuses []APIServiceStatus, now time.Time) operatorv1.OperatorCondition {
+	var lines []string
+	for _, status := range statuses {
+		if status.Reason == asExpectedReason || (status.UnavailableSince != nil && now.Sub(status.UnavailableSince.Time) < unavailableThreshold) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("apiservice %s of service %s is unavailable: %s: %s", status.Name, status.Service, status.Reason, status.Message))
+	}
+	if len(lines) == 0 {
+		return operatorv1.OperatorCondition{Type: AggregatedAPIServicesUpgradeableConditionType, Status: operatorv1.ConditionTrue, Reason: asExpectedReason}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    AggregatedAPIServicesUpgradeableConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "APIServiceUnavailable",
+		Message: strings.Join(lines, "\n"),
+	}
+}
+
+func updateMetrics(statuses []WebhookStatus, apiServiceStatuses []APIServiceStatus) {
+	webhookErrorGauge.Reset()
+	webhookLatencyGauge.Reset()
+	for _, status := range statuses {
+		if status.Reason != asExpectedReason {
+			webhookErrorGauge.WithLabelValues(status.Kind, status.Configuration, status.Name, status.Reason).Set(1)
+		}
+		if status.Latency != nil {
+			webhookLatencyGauge.WithLabelValues(status.Kind, status.Configuration, status.Name).Set(status.Latency.Seconds())
+		}
+	}
+	apiServiceUnavailableGauge.Reset()
+	for _, status := range apiServiceStatuses {
+		if status.Reason != asExpectedReason {
+			apiServiceUnavailableGauge.WithLabelValues(status.Name, status.Reason).Set(1)
+		}
+	}
+}
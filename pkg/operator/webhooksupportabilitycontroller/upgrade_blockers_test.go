@@ -0,0 +1,136 @@
+package webhooksupportabilitycontroller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConversionWebhook(t *testing.T) {
+	port := int32(8443)
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service:  &apiextensionsv1.ServiceReference{Namespace: "example", Name: "converter", Port: &port},
+						CABundle: []byte("bundle"),
+					},
+				},
+			},
+		},
+	}
+	w, ok := conversionWebhook(crd)
+	if !ok {
+		t.Fatal("expected a conversion webhook")
+	}
+	if w.kind != customResourceDefinitionKind || w.configuration != "widgets.example.com" || len(w.name) != 0 {
+		t.Errorf("unexpected webhook %+v", w)
+	}
+	if s := w.clientConfig.Service; s == nil || s.Namespace != "example" || s.Name != "converter" || *s.Port != 8443 || string(w.clientConfig.CABundle) != "bundle" {
+		t.Errorf("unexpected client config %+v", w.clientConfig)
+	}
+	if describe(checkStatusOf(w)) != `conversion webhook of customresourcedefinition "widgets.example.com"` {
+		t.Errorf("unexpected description %q", describe(checkStatusOf(w)))
+	}
+
+	crd.Spec.Conversion.Strategy = apiextensionsv1.NoneConverter
+	if _, ok := conversionWebhook(crd); ok {
+		t.Error("expected no conversion webhook for the None strategy")
+	}
+	crd.Spec.Conversion = nil
+	if _, ok := conversionWebhook(crd); ok {
+		t.Error("expected no conversion webhook without a conversion")
+	}
+}
+
+func checkStatusOf(w webhook) WebhookStatus {
+	return WebhookStatus{Kind: w.kind, Configuration: w.configuration, Name: w.name}
+}
+
+func newAPIService(name string, service map[string]interface{}, conditions ...interface{}) unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{},
+		"status":     map[string]interface{}{"conditions": conditions},
+	}
+	if service != nil {
+		obj["spec"] = map[string]interface{}{"service": service}
+	}
+	return unstructured.Unstructured{Object: obj}
+}
+
+func TestCheckAPIServices(t *testing.T) {
+	unavailableSince := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []unstructured.Unstructured{
+		newAPIService("v1.packages.operators.coreos.com",
+			map[string]interface{}{"namespace": "openshift-operator-lifecycle-manager", "name": "packageserver-service", "port": int64(5443)},
+			map[string]interface{}{"type": "Available", "status": "False", "reason": "FailedDiscoveryCheck", "message": "no response", "lastTransitionTime": unavailableSince.Format(time.RFC3339)},
+		),
+		newAPIService("v1.apps", nil, map[string]interface{}{"type": "Available", "status": "True"}),
+		newAPIService("v1.metrics.k8s.io",
+			map[string]interface{}{"namespace": "openshift-monitoring", "name": "prometheus-adapter"},
+			map[string]interface{}{"type": "Available", "status": "True", "reason": "Passed"},
+		),
+		newAPIService("v1beta1.custom.metrics.k8s.io", map[string]interface{}{"namespace": "example", "name": "adapter"}),
+	}
+
+	statuses, err := checkAPIServices(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expected the local APIService to be skipped, got %+v", statuses)
+	}
+	if s := statuses[0]; s.Name != "v1.metrics.k8s.io" || s.Service != "openshift-monitoring/prometheus-adapter:443" || s.Reason != asExpectedReason {
+		t.Errorf("unexpected status %+v", s)
+	}
+	if s := statuses[1]; s.Name != "v1.packages.operators.coreos.com" || s.Service != "openshift-operator-lifecycle-manager/packageserver-service:5443" ||
+		s.Reason != "FailedDiscoveryCheck" || s.Message != "no response" || s.UnavailableSince == nil || !s.UnavailableSince.Time.Equal(unavailableSince) {
+		t.Errorf("unexpected status %+v", s)
+	}
+	if s := statuses[2]; s.Reason != "Unknown" || s.UnavailableSince != nil {
+		t.Errorf("expected an APIService without an Available condition to be unknown, got %+v", s)
+	}
+
+	if cond := newAPIServicesUpgradeableCondition(statuses, unavailableSince.Add(time.Minute)); cond.Status != operatorv1.ConditionFalse ||
+		strings.Contains(cond.Message, "packages") || !strings.Contains(cond.Message, "apiservice v1beta1.custom.metrics.k8s.io of service example/adapter:443 is unavailable: Unknown") {
+		t.Errorf("expected only the APIService without a transition time to block upgrades, got %+v", cond)
+	}
+	cond := newAPIServicesUpgradeableCondition(statuses, unavailableSince.Add(unavailableThreshold))
+	if cond.Status != operatorv1.ConditionFalse || cond.Reason != "APIServiceUnavailable" || len(strings.Split(cond.Message, "\n")) != 2 ||
+		!strings.Contains(cond.Message, "apiservice v1.packages.operators.coreos.com of service openshift-operator-lifecycle-manager/packageserver-service:5443 is unavailable: FailedDiscoveryCheck: no response") {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+	if cond := newAPIServicesUpgradeableCondition(statuses[:1], unavailableSince); cond.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected available APIServices not to block upgrades, got %+v", cond)
+	}
+}
+
+func TestNewConversionWebhooksUpgradeableCondition(t *testing.T) {
+	statuses := []WebhookStatus{
+		{Kind: validatingWebhookConfigurationKind, Configuration: "a", Name: "one", Reason: UnreachableReason},
+		{Kind: customResourceDefinitionKind, Configuration: "widgets.example.com", Reason: asExpectedReason},
+	}
+	if cond := newConversionWebhooksUpgradeableCondition(statuses); cond.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected admission webhooks not to block upgrades, got %+v", cond)
+	}
+
+	statuses = append(statuses,
+		WebhookStatus{Kind: customResourceDefinitionKind, Configuration: "gadgets.example.com", Reason: UnreachableReason},
+		WebhookStatus{Kind: customResourceDefinitionKind, Configuration: "gizmos.example.com", Reason: CABundleMismatchReason},
+	)
+	cond := newConversionWebhooksUpgradeableCondition(statuses)
+	if cond.Status != operatorv1.ConditionFalse || cond.Reason != "ConversionWebhookBroken" || !strings.Contains(cond.Message, "gadgets.example.com, gizmos.example.com") {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+}
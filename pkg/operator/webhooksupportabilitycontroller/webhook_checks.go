@@ -0,0 +1,167 @@
+package webhooksupportabilitycontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	asExpectedReason                = "AsExpected"
+	InvalidCABundleReason           = "InvalidCABundle"
+	CABundleExpiredReason           = "CABundleExpired"
+	CABundleMismatchReason          = "CABundleMismatch"
+	ServingCertificateInvalidReason = "ServingCertificateInvalid"
+	ServiceNotFoundReason           = "ServiceNotFound"
+	InvalidURLReason                = "InvalidURL"
+	UnreachableReason               = "Unreachable"
+
+	// dialTimeout is how long the TLS handshake with a webhook may take. It is the timeout of conversion webhooks, the
+	// longest a webhook is waited for.
+	dialTimeout = conversionWebhookTimeoutSeconds * time.Second
+
+	// defaultTimeoutSeconds is the timeout of an admission webhook without timeoutSeconds.
+	defaultTimeoutSeconds = 10
+	// conversionWebhookTimeoutSeconds is the timeout of the kube-apiserver for conversion webhooks, it cannot be set.
+	conversionWebhookTimeoutSeconds = 30
+)
+
+// dialFunc completes a TLS handshake with the address.
+type dialFunc func(ctx context.Context, address string, config *tls.Config) error
+
+func dialTLS(ctx context.Context, address string, config *tls.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	conn, err := (&tls.Dialer{Config: config}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checkWebhook checks the caBundle of the webhook and that it serves a certificate the caBundle trusts for the name
+// the kube-apiserver calls it by. A caBundle has to parse and at least one of its certificates has to be valid. Without
+// a caBundle the system trust roots are used, like the kube-apiserver does. A service has to exist and to have the port
+// of the webhook.
+func checkWebhook(ctx context.Context, w webhook, serviceLister corev1listers.ServiceLister, dial dialFunc, now time.Time) WebhookStatus {
+	status := WebhookStatus{
+		Kind:           w.kind,
+		Configuration:  w.configuration,
+		Name:           w.name,
+		FailurePolicy:  string(admissionregistrationv1.Fail),
+		TimeoutSeconds: defaultTimeoutSeconds,
+		Reason:         asExpectedReason,
+	}
+	if w.failurePolicy != nil {
+		status.FailurePolicy = string(*w.failurePolicy)
+	}
+	switch {
+	case w.kind == customResourceDefinitionKind:
+		status.TimeoutSeconds = conversionWebhookTimeoutSeconds
+	case w.timeoutSeconds != nil:
+		status.TimeoutSeconds = *w.timeoutSeconds
+	}
+	broken := func(reason, messageFormat string, args ...interface{}) WebhookStatus {
+		status.Reason = reason
+		status.Message = fmt.Sprintf(messageFormat, args...)
+		return status
+	}
+
+	var rootCAs *x509.CertPool
+	if len(w.clientConfig.CABundle) > 0 {
+		certs, err := crypto.CertsFromPEM(w.clientConfig.CABundle)
+		if err != nil {
+			return broken(InvalidCABundleReason, "the caBundle is invalid: %v", err)
+		}
+		rootCAs = x509.NewCertPool()
+		var expiry time.Time
+		for _, cert := range certs {
+			rootCAs.AddCert(cert)
+			if cert.NotAfter.After(expiry) {
+				expiry = cert.NotAfter
+			}
+		}
+		status.CABundleExpiry = &metav1.Time{Time: expiry}
+		if now.After(expiry) {
+			return broken(CABundleExpiredReason, "every certificate of the caBundle expired, the last one at %s", expiry.UTC().Format(time.RFC3339))
+		}
+	}
+
+	var address, serverName string
+	switch {
+	case w.clientConfig.Service != nil:
+		service := w.clientConfig.Service
+		port := int32(443)
+		if service.Port != nil {
+			port = *service.Port
+		}
+		status.Service = fmt.Sprintf("%s/%s:%d", service.Namespace, service.Name, port)
+		existing, err := serviceLister.Services(service.Namespace).Get(service.Name)
+		if apierrors.IsNotFound(err) {
+			return broken(ServiceNotFoundReason, "service %s/%s not found", service.Namespace, service.Name)
+		}
+		if err != nil {
+			return broken(ServiceNotFoundReason, "service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+		hasPort := false
+		for _, servicePort := range existing.Spec.Ports {
+			hasPort = hasPort || servicePort.Port == port
+		}
+		if !hasPort {
+			return broken(ServiceNotFoundReason, "service %s/%s has no port %d", service.Namespace, service.Name, port)
+		}
+		serverName = fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+		address = net.JoinHostPort(serverName, strconv.Itoa(int(port)))
+	case w.clientConfig.URL != nil:
+		status.URL = *w.clientConfig.URL
+		u, err := url.Parse(*w.clientConfig.URL)
+		if err != nil {
+			return broken(InvalidURLReason, "%v", err)
+		}
+		if u.Scheme != "https" {
+			return broken(InvalidURLReason, "the scheme of %s must be https", status.URL)
+		}
+		serverName = u.Hostname()
+		port := u.Port()
+		if len(port) == 0 {
+			port = "443"
+		}
+		address = net.JoinHostPort(serverName, port)
+	default:
+		return broken(InvalidURLReason, "neither a service nor a URL is set")
+	}
+
+	start := time.Now()
+	err := dial(ctx, address, &tls.Config{RootCAs: rootCAs, ServerName: serverName})
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalidErr x509.CertificateInvalidError
+	switch {
+	case err == nil:
+		status.Latency = &metav1.Duration{Duration: time.Since(start)}
+		return status
+	case errors.As(err, &unknownAuthorityErr):
+		if rootCAs == nil {
+			return broken(CABundleMismatchReason, "the serving certificate of %s is not signed by the system trust roots and there is no caBundle", address)
+		}
+		return broken(CABundleMismatchReason, "the serving certificate of %s is not signed by the caBundle", address)
+	case errors.As(err, &hostnameErr):
+		return broken(ServingCertificateInvalidReason, "the serving certificate of %s is not valid for %s: %v", address, serverName, err)
+	case errors.As(err, &certificateInvalidErr):
+		return broken(ServingCertificateInvalidReason, "the serving certificate of %s is invalid: %v", address, err)
+	default:
+		return broken(UnreachableReason, "%v", err)
+	}
+}
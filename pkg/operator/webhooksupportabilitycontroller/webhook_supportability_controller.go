@@ -0,0 +1,298 @@
+package webhooksupportabilitycontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	apiextensionsv1listers "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	admissionregistrationv1listers "k8s.io/client-go/listers/admissionregistration/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resyncinterval"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/syncmetrics"
+)
+
+const (
+	// ConfigMapName is the configmap in the operator namespace with the status of every admission webhook.
+	ConfigMapName = "webhook-supportability"
+	// ConfigMapKey is the key of the configmap with the JSON list of the webhook statuses.
+	ConfigMapKey = "webhooks"
+	// APIServicesConfigMapKey is the key of the configmap with the JSON list of the statuses of the aggregated APIs.
+	APIServicesConfigMapKey = "apiservices"
+	// RisksConfigMapKey is the key of the configmap with the JSON list of the risky webhooks, the riskiest first.
+	RisksConfigMapKey = "risks"
+
+	MutatingAdmissionWebhookConfigurationErrorConditionType   = "MutatingAdmissionWebhookConfigurationError"
+	ValidatingAdmissionWebhookConfigurationErrorConditionType = "ValidatingAdmissionWebhookConfigurationError"
+	CRDConversionWebhookConfigurationErrorConditionType       = "CRDConversionWebhookConfigurationError"
+	CRDConversionWebhooksUpgradeableConditionType             = "CRDConversionWebhooksUpgradeable"
+	AggregatedAPIServicesUpgradeableConditionType             = "AggregatedAPIServicesUpgradeable"
+	AdmissionWebhookRiskDetectedConditionType                 = "AdmissionWebhookRiskDetected"
+
+	mutatingWebhookConfigurationKind   = "MutatingWebhookConfiguration"
+	validatingWebhookConfigurationKind = "ValidatingWebhookConfiguration"
+	customResourceDefinitionKind       = "CustomResourceDefinition"
+
+	// multipleReasons is the reason of a condition whose broken webhooks fail for different reasons.
+	multipleReasons = "MultipleReasons"
+)
+
+// WebhookStatus is the result of the checks of an admission webhook.
+type WebhookStatus struct {
+	// Kind is MutatingWebhookConfiguration, ValidatingWebhookConfiguration or CustomResourceDefinition.
+	Kind string `json:"kind"`
+	// Configuration is the name of the webhook configuration or of the CRD.
+	Configuration string `json:"configuration"`
+	// Name is the name of the webhook in the configuration, empty for the conversion webhook of a CRD.
+	Name string `json:"name,omitempty"`
+	// Service is the namespace/name:port of the service of the webhook, empty for a webhook called by URL.
+	Service string `json:"service,omitempty"`
+	// URL is the URL of a webhook that is not called through a service.
+	URL string `json:"url,omitempty"`
+	// FailurePolicy is what happens to a request when the webhook fails, Fail or Ignore.
+	FailurePolicy string `json:"failurePolicy"`
+	// TimeoutSeconds is how long the kube-apiserver waits for the webhook.
+	TimeoutSeconds int32 `json:"timeoutSeconds"`
+	// Latency is how long connecting to the webhook and the TLS handshake took, empty when it failed.
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// CABundleExpiry is when the last certificate of the caBundle expires, empty without a caBundle.
+	CABundleExpiry *metav1.Time `json:"caBundleExpiry,omitempty"`
+	// Reason is AsExpected for a working webhook, otherwise why it does not work.
+	Reason string `json:"reason"`
+	// Message tells what does not work, empty for a working webhook.
+	Message string `json:"message,omitempty"`
+}
+
+// webhook is the part of a mutating, validating or conversion webhook that is checked.
+type webhook struct {
+	kind          string
+	configuration string
+	name          string
+	clientConfig  admissionregistrationv1.WebhookClientConfig
+	failurePolicy *admissionregistrationv1.FailurePolicyType
+	// timeoutSeconds is nil for the default timeout.
+	timeoutSeconds *int32
+	// rules are the requests the admission webhook is called for, empty for a conversion webhook.
+	rules []admissionregistrationv1.RuleWithOperations
+}
+
+// WebhookSupportabilityController checks every mutating and validating admission webhook and every CRD conversion
+// webhook of the cluster: its caBundle has to parse and must not be expired, its service has to exist and it has to
+// serve a certificate the caBundle trusts for its name. It also checks that the aggregated APIs are available. The
+// status of every webhook and aggregated API is published in the webhook-supportability configmap of the operator
+// namespace, and the broken webhooks are listed one per line in the MutatingAdmissionWebhookConfigurationError,
+// ValidatingAdmissionWebhookConfigurationError and CRDConversionWebhookConfigurationError conditions. These conditions
+// do not degrade the operator, the webhooks are owned by others, but they point the owners at their broken webhook.
+// Broken conversion webhooks and unavailable aggregated APIs fail the discovery and the storage migration of an
+// upgrade, so they also make the operator not upgradeable. Webhooks that fail closed on cluster-critical resources or
+// are slow compared to their timeout are listed in the AdmissionWebhookRiskDetected condition, the riskiest first.
+type WebhookSupportabilityController struct {
+	operatorClient                       v1helpers.OperatorClient
+	mutatingWebhookConfigurationLister   admissionregistrationv1listers.MutatingWebhookConfigurationLister
+	validatingWebhookConfigurationLister admissionregistrationv1listers.ValidatingWebhookConfigurationLister
+	crdLister                            apiextensionsv1listers.CustomResourceDefinitionLister
+	serviceLister                        corev1listers.ServiceLister
+	apiServiceClient                     dynamic.NamespaceableResourceInterface
+	configMapClient                      coreclientv1.ConfigMapsGetter
+
+	dial dialFunc
+	now  func() time.Time
+}
+
+func NewWebhookSupportabilityController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	apiextensionsInformers apiextensionsinformers.SharedInformerFactory,
+	dynamicClient dynamic.Interface,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	kubeInformers := kubeInformersForNamespaces.InformersFor("")
+	c := &WebhookSupportabilityController{
+		operatorClient:                       operatorClient,
+		mutatingWebhookConfigurationLister:   kubeInformers.Admissionregistration().V1().MutatingWebhookConfigurations().Lister(),
+		validatingWebhookConfigurationLister: kubeInformers.Admissionregistration().V1().ValidatingWebhookConfigurations().Lister(),
+		crdLister:                            apiextensionsInformers.Apiextensions().V1().CustomResourceDefinitions().Lister(),
+		serviceLister:                        kubeInformers.Core().V1().Services().Lister(),
+		apiServiceClient:                     dynamicClient.Resource(apiServicesResource),
+		configMapClient:                      configMapClient,
+		dial:                                 dialTLS,
+		now:                                  time.Now,
+	}
+	return factory.New().WithInformers(
+		kubeInformers.Admissionregistration().V1().MutatingWebhookConfigurations().Informer(),
+		kubeInformers.Admissionregistration().V1().ValidatingWebhookConfigurations().Informer(),
+	).WithBareInformers(
+		// every change of a service or a CRD of the cluster is not worth dialing all webhooks, the resync catches up
+		kubeInformers.Core().V1().Services().Informer(),
+		apiextensionsInformers.Apiextensions().V1().CustomResourceDefinitions().Informer(),
+	).WithSync(syncmetrics.Instrument("WebhookSupportabilityController", c.sync)).ResyncEvery(resyncinterval.For("WebhookSupportabilityController", 10*time.Minute)).ToController("WebhookSupportabilityController", eventRecorder.WithComponentSuffix("webhook-supportability-controller"))
+}
+
+func (c *WebhookSupportabilityController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	webhooks, err := c.listWebhooks()
+	if err != nil {
+		return err
+	}
+
+	statuses := make([]WebhookStatus, len(webhooks))
+	var wg sync.WaitGroup
+	for i := range webhooks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = checkWebhook(ctx, webhooks[i], c.serviceLister, c.dial, c.now())
+		}(i)
+	}
+	wg.Wait()
+
+	apiServices, err := c.apiServiceClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	apiServiceStatuses, err := checkAPIServices(apiServices.Items)
+	if err != nil {
+		return err
+	}
+	risks := analyzeRisks(webhooks, statuses)
+	updateMetrics(statuses, apiServiceStatuses)
+
+	raw, err := json.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	rawAPIServices, err := json.Marshal(apiServiceStatuses)
+	if err != nil {
+		return err
+	}
+	rawRisks, err := json.Marshal(risks)
+	if err != nil {
+		return err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.OperatorNamespace, Name: ConfigMapName},
+		Data:       map[string]string{ConfigMapKey: string(raw), APIServicesConfigMapKey: string(rawAPIServices), RisksConfigMapKey: string(rawRisks)},
+	}); err != nil {
+		return err
+	}
+
+	_, _, err = v1helpers.UpdateStatus(c.operatorClient,
+		v1helpers.UpdateConditionFn(newWebhookCondition(MutatingAdmissionWebhookConfigurationErrorConditionType, mutatingWebhookConfigurationKind, statuses)),
+		v1helpers.UpdateConditionFn(newWebhookCondition(ValidatingAdmissionWebhookConfigurationErrorConditionType, validatingWebhookConfigurationKind, statuses)),
+		v1helpers.UpdateConditionFn(newWebhookCondition(CRDConversionWebhookConfigurationErrorConditionType, customResourceDefinitionKind, statuses)),
+		v1helpers.UpdateConditionFn(newConversionWebhooksUpgradeableCondition(statuses)),
+		v1helpers.UpdateConditionFn(newAPIServicesUpgradeableCondition(apiServiceStatuses, c.now())),
+		v1helpers.UpdateConditionFn(newRiskCondition(risks)),
+	)
+	return err
+}
+
+// listWebhooks returns the mutating webhooks, the validating webhooks and then the conversion webhooks, sorted by
+// configuration and in the order of the configuration.
+func (c *WebhookSupportabilityController) listWebhooks() ([]webhook, error) {
+	var webhooks []webhook
+	mutatingConfigurations, err := c.mutatingWebhookConfigurationLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mutatingConfigurations, func(i, j int) bool { return mutatingConfigurations[i].Name < mutatingConfigurations[j].Name })
+	for _, configuration := range mutatingConfigurations {
+		for _, w := range configuration.Webhooks {
+			webhooks = append(webhooks, webhook{
+				kind:           mutatingWebhookConfigurationKind,
+				configuration:  configuration.Name,
+				name:           w.Name,
+				clientConfig:   w.ClientConfig,
+				failurePolicy:  w.FailurePolicy,
+				timeoutSeconds: w.TimeoutSeconds,
+				rules:          w.Rules,
+			})
+		}
+	}
+
+	validatingConfigurations, err := c.validatingWebhookConfigurationLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(validatingConfigurations, func(i, j int) bool { return validatingConfigurations[i].Name < validatingConfigurations[j].Name })
+	for _, configuration := range validatingConfigurations {
+		for _, w := range configuration.Webhooks {
+			webhooks = append(webhooks, webhook{
+				kind:           validatingWebhookConfigurationKind,
+				configuration:  configuration.Name,
+				name:           w.Name,
+				clientConfig:   w.ClientConfig,
+				failurePolicy:  w.FailurePolicy,
+				timeoutSeconds: w.TimeoutSeconds,
+				rules:          w.Rules,
+			})
+		}
+	}
+
+	crds, err := c.crdLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
+	for _, crd := range crds {
+		if w, ok := conversionWebhook(crd); ok {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+// newWebhookCondition lists the broken webhooks of the kind one per line. The reason is the reason of the broken
+// webhooks if they share it.
+func newWebhookCondition(conditionType, kind string, statuses []WebhookStatus) operatorv1.OperatorCondition {
+	var lines []string
+	reason := ""
+	for _, status := range statuses {
+		if status.Kind != kind || status.Reason == asExpectedReason {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", describe(status), status.Message))
+		switch reason {
+		case "":
+			reason = status.Reason
+		case status.Reason:
+		default:
+			reason = multipleReasons
+		}
+	}
+	if len(lines) == 0 {
+		return operatorv1.OperatorCondition{Type: conditionType, Status: operatorv1.ConditionFalse, Reason: asExpectedReason}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(lines, "\n"),
+	}
+}
+
+// describe names the webhook of the status for the owner of its configuration.
+func describe(status WebhookStatus) string {
+	if len(status.Name) == 0 {
+		return fmt.Sprintf("conversion webhook of %s %q", strings.ToLower(status.Kind), status.Configuration)
+	}
+	return fmt.Sprintf("webhook %q of %s %q", status.Name, strings.ToLower(status.Kind), status.Configuration)
+}
@@ -0,0 +1,239 @@
+package webhooksupportabilitycontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newCA(t *testing.T, name string, lifetime time.Duration) *crypto.CA {
+	t.Helper()
+	config, err := crypto.MakeSelfSignedCAConfigForDuration(name, lifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+}
+
+func caBundle(t *testing.T, ca *crypto.CA) []byte {
+	t.Helper()
+	certPEM, _, err := ca.Config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+// serve serves the certificate of the hostnames signed by the CA until the test ends and returns its address.
+func serve(t *testing.T, ca *crypto.CA, hostnames ...string) string {
+	t.Helper()
+	serverCert, err := ca.MakeServerCertForDuration(sets.NewString(hostnames...), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err := serverCert.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCheckWebhook(t *testing.T) {
+	now := time.Now()
+	ca := newCA(t, "webhook-signer", time.Hour)
+	otherCA := newCA(t, "other-signer", time.Hour)
+	expiredCA := newCA(t, "expired-signer", time.Hour)
+
+	// every service address is dialed at the webhook serving a certificate for its service name, the URL webhook at
+	// one serving a certificate for another name
+	addresses := map[string]string{
+		"webhook.example.svc:443": serve(t, ca, "webhook.example.svc"),
+		"webhook.example.com:443": serve(t, ca, "other.example.com"),
+	}
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses["down.example.svc:8443"] = closed.Addr().String()
+	closed.Close()
+	dial := func(ctx context.Context, address string, config *tls.Config) error {
+		return dialTLS(ctx, addresses[address], config)
+	}
+
+	services := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for name, port := range map[string]int32{"webhook": 443, "down": 8443} {
+		if err := services.Add(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "example", Name: name},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: port}}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	serviceLister := corev1listers.NewServiceLister(services)
+
+	port := func(port int32) *int32 { return &port }
+	serviceWebhook := func(name string, port *int32, caBundle []byte) webhook {
+		return webhook{
+			kind:          validatingWebhookConfigurationKind,
+			configuration: "example",
+			name:          "pods.example.com",
+			clientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service:  &admissionregistrationv1.ServiceReference{Namespace: "example", Name: name, Port: port},
+				CABundle: caBundle,
+			},
+		}
+	}
+	urlWebhook := func(url string) webhook {
+		return webhook{
+			kind:          mutatingWebhookConfigurationKind,
+			configuration: "example",
+			name:          "pods.example.com",
+			clientConfig:  admissionregistrationv1.WebhookClientConfig{URL: &url, CABundle: caBundle(t, ca)},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		webhook         webhook
+		now             time.Time
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "working",
+			webhook:        serviceWebhook("webhook", nil, caBundle(t, ca)),
+			expectedReason: asExpectedReason,
+		},
+		{
+			name:           "previous CA in the bundle",
+			webhook:        serviceWebhook("webhook", port(443), append(caBundle(t, otherCA), caBundle(t, ca)...)),
+			expectedReason: asExpectedReason,
+		},
+		{
+			name:            "garbage caBundle",
+			webhook:         serviceWebhook("webhook", nil, []byte("garbage")),
+			expectedReason:  InvalidCABundleReason,
+			expectedMessage: "the caBundle is invalid",
+		},
+		{
+			name:            "expired caBundle",
+			webhook:         serviceWebhook("webhook", nil, caBundle(t, expiredCA)),
+			now:             now.Add(2 * time.Hour),
+			expectedReason:  CABundleExpiredReason,
+			expectedMessage: "every certificate of the caBundle expired",
+		},
+		{
+			name:            "caBundle of another CA",
+			webhook:         serviceWebhook("webhook", nil, caBundle(t, otherCA)),
+			expectedReason:  CABundleMismatchReason,
+			expectedMessage: "the serving certificate of webhook.example.svc:443 is not signed by the caBundle",
+		},
+		{
+			name:            "missing service",
+			webhook:         serviceWebhook("missing", nil, caBundle(t, ca)),
+			expectedReason:  ServiceNotFoundReason,
+			expectedMessage: "service example/missing not found",
+		},
+		{
+			name:            "missing port",
+			webhook:         serviceWebhook("webhook", port(8443), caBundle(t, ca)),
+			expectedReason:  ServiceNotFoundReason,
+			expectedMessage: "service example/webhook has no port 8443",
+		},
+		{
+			name:           "unreachable",
+			webhook:        serviceWebhook("down", port(8443), caBundle(t, ca)),
+			expectedReason: UnreachableReason,
+		},
+		{
+			name:            "serving certificate for another name",
+			webhook:         urlWebhook("https://webhook.example.com/validate"),
+			expectedReason:  ServingCertificateInvalidReason,
+			expectedMessage: "is not valid for webhook.example.com",
+		},
+		{
+			name:            "plain HTTP",
+			webhook:         urlWebhook("http://webhook.example.com/validate"),
+			expectedReason:  InvalidURLReason,
+			expectedMessage: "the scheme of http://webhook.example.com/validate must be https",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkTime := test.now
+			if checkTime.IsZero() {
+				checkTime = now
+			}
+			status := checkWebhook(context.TODO(), test.webhook, serviceLister, dial, checkTime)
+			if status.Reason != test.expectedReason || !strings.Contains(status.Message, test.expectedMessage) {
+				t.Errorf("expected %s %q, got %s %q", test.expectedReason, test.expectedMessage, status.Reason, status.Message)
+			}
+			if status.FailurePolicy != "Fail" {
+				t.Errorf("expected the default failure policy, got %q", status.FailurePolicy)
+			}
+			if status.TimeoutSeconds != defaultTimeoutSeconds {
+				t.Errorf("expected the default timeout, got %d", status.TimeoutSeconds)
+			}
+			if (status.Latency != nil) != (status.Reason == asExpectedReason) {
+				t.Errorf("expected a latency only for a working webhook, got %v", status.Latency)
+			}
+		})
+	}
+}
+
+func TestNewWebhookCondition(t *testing.T) {
+	statuses := []WebhookStatus{
+		{Kind: mutatingWebhookConfigurationKind, Configuration: "a", Name: "one", Reason: asExpectedReason},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "a", Name: "one", Reason: ServiceNotFoundReason, Message: "service example/missing not found"},
+		{Kind: validatingWebhookConfigurationKind, Configuration: "b", Name: "two", Reason: CABundleExpiredReason, Message: "expired"},
+	}
+
+	if cond := newWebhookCondition(MutatingAdmissionWebhookConfigurationErrorConditionType, mutatingWebhookConfigurationKind, statuses); cond.Status != operatorv1.ConditionFalse || cond.Reason != asExpectedReason {
+		t.Errorf("expected the mutating webhooks to be as expected, got %+v", cond)
+	}
+
+	cond := newWebhookCondition(ValidatingAdmissionWebhookConfigurationErrorConditionType, validatingWebhookConfigurationKind, statuses)
+	expectedMessage := `webhook "one" of validatingwebhookconfiguration "a": service example/missing not found` + "\n" +
+		`webhook "two" of validatingwebhookconfiguration "b": expired`
+	if cond.Status != operatorv1.ConditionTrue || cond.Reason != multipleReasons || cond.Message != expectedMessage {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+
+	cond = newWebhookCondition(ValidatingAdmissionWebhookConfigurationErrorConditionType, validatingWebhookConfigurationKind, statuses[:2])
+	if cond.Reason != ServiceNotFoundReason {
+		t.Errorf("expected the reason of the only broken webhook, got %+v", cond)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"text/template"
 	"time"
 
@@ -37,13 +38,22 @@ const (
 	RecoveryPodAsset              = "assets/kube-apiserver/recovery-pod.yaml"
 	RecoveryConfigAsset           = "assets/kube-apiserver/recovery-config.yaml"
 	RecoveryEncryptionConfigAsset = "assets/kube-apiserver/recovery-encryption-config.yaml"
+
+	// RevisionConfigPath is the config of the kube-apiserver in the resource dir of a revision.
+	RevisionConfigPath = "configmaps/config/config.yaml"
+	// DefaultEtcdServer is the etcd server of the recovery apiserver if the config of the revision has none.
+	DefaultEtcdServer = "https://localhost:2379"
 )
 
 type Apiserver struct {
 	PodManifestDir        string
 	ResourceDirPath       string
 	StaticPodResourcesDir string
-	recoveryResourcesDir  string
+	// RevisionDir is the resource dir of a revision on the node, e.g. the latest one when every kube-apiserver is down.
+	// The recovery apiserver is created from its pod manifest and config instead of the one in PodManifestDir.
+	RevisionDir          string
+	recoveryResourcesDir string
+	etcdServers          []string
 
 	kubeApiserverStaticPod *corev1.Pod
 	restConfig             *rest.Config
@@ -177,21 +187,64 @@ func (s *Apiserver) recoveryPod() (*corev1.Pod, error) {
 	return recoveryPod, nil
 }
 
+func (s *Apiserver) recoveryConfig() ([]byte, error) {
+	recoveryConfigTemplateBytes, err := bindata.Asset(RecoveryConfigAsset)
+	if err != nil {
+		return nil, fmt.Errorf("fail to find internal recovery config asset %q: %v", RecoveryConfigAsset, err)
+	}
+
+	t, err := template.New("recovery-config-template").Parse(string(recoveryConfigTemplateBytes))
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse internal recovery config template %q: %v", RecoveryConfigAsset, err)
+	}
+
+	etcdServers := s.etcdServers
+	if len(etcdServers) == 0 {
+		etcdServers = []string{DefaultEtcdServer}
+	}
+
+	recoveryConfigBuffer := bytes.NewBuffer(nil)
+	err = t.Execute(recoveryConfigBuffer, struct {
+		EtcdServers []string
+	}{
+		EtcdServers: etcdServers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to execute internal recovery config template %q: %v", RecoveryConfigAsset, err)
+	}
+
+	return recoveryConfigBuffer.Bytes(), nil
+}
+
 func (s *Apiserver) Create() error {
 	kubeApiserverManifestPath := s.KubeApiserverManifestPath()
+	if len(s.RevisionDir) > 0 {
+		kubeApiserverManifestPath = filepath.Join(s.RevisionDir, KubeApiserverStaticPodFileName)
+	}
 	var err error
 	s.kubeApiserverStaticPod, err = ReadManifestToV1Pod(kubeApiserverManifestPath)
 	if err != nil {
 		return fmt.Errorf("failed to read kube-apiserver pod manifest at %q: %v", kubeApiserverManifestPath, err)
 	}
 
-	s.ResourceDirPath, err = GetVolumeHostPathPath("resource-dir", s.kubeApiserverStaticPod.Spec.Volumes)
+	if len(s.RevisionDir) > 0 {
+		s.ResourceDirPath = s.RevisionDir
+	} else {
+		s.ResourceDirPath, err = GetVolumeHostPathPath("resource-dir", s.kubeApiserverStaticPod.Spec.Volumes)
+		if err != nil {
+			return fmt.Errorf("failed to find resource-dir: %v", err)
+		}
+	}
+
+	// Connect to the etcd servers of the revision rather than assuming a local etcd member
+	s.etcdServers, err = EtcdServers(filepath.Join(s.ResourceDirPath, RevisionConfigPath))
 	if err != nil {
-		return fmt.Errorf("failed to find resource-dir: %v", err)
+		return err
 	}
+	klog.Infof("Recovery apiserver will connect to etcd at %s", strings.Join(s.etcdServers, ","))
 
 	s.recoveryResourcesDir = filepath.Join(s.StaticPodResourcesDir, "recovery-kube-apiserver-pod")
-	err = os.Mkdir(s.recoveryResourcesDir, 755)
+	err = os.Mkdir(s.recoveryResourcesDir, 0755)
 	if err != nil {
 		if os.IsExist(err) {
 			klog.Errorf("Recovery dir %q already exist. Please use `recovery-apiserver destroy` command or remove the dir manually.", s.recoveryResourcesDir)
@@ -229,13 +282,13 @@ func (s *Apiserver) Create() error {
 	}
 
 	// Create config for recovery apiserver
-	recoveryConfigBytes, err := bindata.Asset(RecoveryConfigAsset)
+	recoveryConfigBytes, err := s.recoveryConfig()
 	if err != nil {
-		return fmt.Errorf("fail to find internal recovery config asset %q: %v", RecoveryConfigAsset, err)
+		return err
 	}
 
 	recoveryConfigPath := filepath.Join(s.recoveryResourcesDir, RecoveryCofigFileName)
-	err = ioutil.WriteFile(recoveryConfigPath, recoveryConfigBytes, 644)
+	err = ioutil.WriteFile(recoveryConfigPath, recoveryConfigBytes, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write recovery config %q: %v", recoveryConfigPath, err)
 	}
@@ -258,7 +311,7 @@ func (s *Apiserver) Create() error {
 		}
 
 		recoveryEncryptionConfigPath := filepath.Join(s.recoveryResourcesDir, RecoveryEncryptionCofigFileName)
-		err = ioutil.WriteFile(recoveryEncryptionConfigPath, recoveryEncryptionConfigBytes, 644)
+		err = ioutil.WriteFile(recoveryEncryptionConfigPath, recoveryEncryptionConfigBytes, 0644)
 		if err != nil {
 			return fmt.Errorf("failed to write recovery encryption config %q: %v", recoveryEncryptionConfigPath, err)
 		}
@@ -275,7 +328,7 @@ func (s *Apiserver) Create() error {
 	}
 
 	recoveryPodManifestPath := filepath.Join(s.PodManifestDir, RecoveryPodFileName)
-	err = ioutil.WriteFile(recoveryPodManifestPath, recoveryPodBytes, 644)
+	err = ioutil.WriteFile(recoveryPodManifestPath, recoveryPodBytes, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write recovery pod manifest %q: %v", recoveryPodManifestPath, err)
 	}
@@ -302,6 +355,9 @@ func (s *Apiserver) Create() error {
 	}
 
 	clientCertBytes, clientKeyBytes, err := clientCert.GetPEMBytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode client certificate: %v", err)
+	}
 
 	s.restConfig = &rest.Config{
 		Host: "https://localhost:7443",
@@ -323,7 +379,7 @@ func (s *Apiserver) Create() error {
 	}
 
 	kubeconfigPath := filepath.Join(s.recoveryResourcesDir, AdminKubeconfigFileName)
-	err = ioutil.WriteFile(kubeconfigPath, kubeconfigBytes, 600)
+	err = ioutil.WriteFile(kubeconfigPath, kubeconfigBytes, 0600)
 	if err != nil {
 		return fmt.Errorf("failed to write kubeconfig %q: %v", kubeconfigPath, err)
 	}
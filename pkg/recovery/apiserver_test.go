@@ -1,7 +1,11 @@
 package recovery
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -137,3 +141,68 @@ func TestApiserverRecoveryPod(t *testing.T) {
 		})
 	}
 }
+
+func TestApiserverCreateFromRevision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifestDir := filepath.Join(dir, "manifests")
+	resourcesDir := filepath.Join(dir, "static-pod-resources")
+	revisionDir := filepath.Join(resourcesDir, "kube-apiserver-pod-3")
+	for path, content := range map[string]string{
+		"kube-apiserver-pod-2/kube-apiserver-pod.yaml":                  "",
+		"kube-apiserver-pod-3/kube-apiserver-pod.yaml":                  "apiVersion: v1\nkind: Pod\nspec:\n  containers:\n  - name: kube-apiserver\n    image: hyperkube:latest\n",
+		"kube-apiserver-pod-3/" + RevisionConfigPath:                    "apiServerArguments:\n  etcd-servers:\n  - https://10.0.0.1:2379\n  - https://10.0.0.2:2379\n",
+		"kube-apiserver-pod-3/secrets/etcd-client/tls.key":              "key",
+		"kube-apiserver-pod-3/secrets/etcd-client/tls.crt":              "crt",
+		"kube-apiserver-pod-3/configmaps/etcd-serving-ca/ca-bundle.crt": "ca",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(resourcesDir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(resourcesDir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(manifestDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := LatestRevisionDir(resourcesDir)
+	if err != nil || latest != revisionDir {
+		t.Fatalf("expected the latest revision dir %q, got %q, %v", revisionDir, latest, err)
+	}
+
+	apiserver := &Apiserver{PodManifestDir: manifestDir, StaticPodResourcesDir: resourcesDir, RevisionDir: latest}
+	if err := apiserver.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	recoveryConfig, err := ioutil.ReadFile(filepath.Join(apiserver.GetRecoveryResourcesDir(), RecoveryCofigFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(recoveryConfig), "  urls:\n  - \"https://10.0.0.1:2379\"\n  - \"https://10.0.0.2:2379\"\n") {
+		t.Errorf("expected the etcd servers of the revision in the recovery config:\n%s", recoveryConfig)
+	}
+	for _, path := range []string{
+		filepath.Join(manifestDir, RecoveryPodFileName),
+		filepath.Join(apiserver.GetRecoveryResourcesDir(), AdminKubeconfigFileName),
+		filepath.Join(apiserver.GetRecoveryResourcesDir(), "etcd-client.crt"),
+		filepath.Join(apiserver.GetRecoveryResourcesDir(), RecoveryEncryptionCofigFileName),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %q to be created: %v", path, err)
+		}
+	}
+
+	if err := apiserver.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(apiserver.GetRecoveryResourcesDir()); !os.IsNotExist(err) {
+		t.Errorf("expected the recovery resources dir to be removed, got %v", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -15,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/klog/v2"
 )
 
@@ -122,3 +126,62 @@ func EnsureFileContent(filePath string, data []byte) error {
 
 	return nil
 }
+
+var revisionDirRegexp = regexp.MustCompile(`^kube-apiserver-pod-([0-9]+)$`)
+
+// LatestRevisionDir returns the resource dir of the highest revision of the kube-apiserver in the static pod
+// resources dir of a node.
+func LatestRevisionDir(staticPodResourcesDir string) (string, error) {
+	entries, err := ioutil.ReadDir(staticPodResourcesDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read dir %q: %v", staticPodResourcesDir, err)
+	}
+
+	latest, latestDir := -1, ""
+	for _, entry := range entries {
+		match := revisionDirRegexp.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || match == nil {
+			continue
+		}
+		revision, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		if revision > latest {
+			latest, latestDir = revision, filepath.Join(staticPodResourcesDir, entry.Name())
+		}
+	}
+	if latest < 0 {
+		return "", fmt.Errorf("no kube-apiserver revision found in %q", staticPodResourcesDir)
+	}
+
+	return latestDir, nil
+}
+
+// EtcdServers returns the etcd servers of the kube-apiserver config at configPath: the etcd-servers argument, or the
+// storageConfig urls of older configs. It returns nil if the config has none.
+func EtcdServers(configPath string) ([]string, error) {
+	configBytes, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kube-apiserver config %q: %v", configPath, err)
+	}
+
+	config := struct {
+		APIServerArguments map[string][]string `json:"apiServerArguments"`
+		StorageConfig      struct {
+			URLs []string `json:"urls"`
+		} `json:"storageConfig"`
+	}{}
+	if err := yaml.Unmarshal(configBytes, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode kube-apiserver config %q: %v", configPath, err)
+	}
+
+	if servers := config.APIServerArguments["etcd-servers"]; len(servers) > 0 {
+		return servers, nil
+	}
+	if len(config.StorageConfig.URLs) > 0 {
+		return config.StorageConfig.URLs, nil
+	}
+
+	return nil, nil
+}